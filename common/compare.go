@@ -0,0 +1,168 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package common
+
+import (
+	"github.com/shopspring/decimal"
+	"strings"
+)
+
+// 数据校验 checksum 下推
+// 上下游统一 MD5 哈希（Oracle STANDARD_HASH / MySQL MD5），行哈希前 8 位十六进制转数值求和聚合
+// 不使用 ORA_HASH 与 BIT_XOR(CRC32)，ORA_HASH 与 MySQL CRC32 算法不同上下游结果无法对比，BIT_XOR 重复行成对抵消
+// Oracle STANDARD_HASH 需要 oracle 12c 及以上
+const OracleStandardHashDBVersion = "12.1"
+
+// 数据校验 checksum 下推行字段拼接分隔符
+// 上下游统一以分隔符开头拼接字段哈希值，避免单字段 NULL 导致整行哈希为 NULL
+const CompareChecksumSeparator = "|"
+
+// 数据校验 checksum 下推字段哈希分组折叠数
+// 单字段 MD5 十六进制 32 位加分隔符，100 个字段拼接 3300 字节，低于 Oracle VARCHAR2 4000 字节上限
+const CompareChecksumFoldColumns = 100
+
+// 数据校验差异修复状态
+const (
+	CompareRepairStatusFixed     = "FIXED"
+	CompareRepairStatusDifferent = "DIFFERENT"
+	CompareRepairStatusFailed    = "FAILED"
+)
+
+// 数据校验 chunk 数据不一致错误详情，用于区分 chunk 数据差异与校验执行失败
+const CompareChunkDiffError = "schema table data chunk isn't equal"
+
+// 数据校验汇总报告文件名前缀，输出 JSON 以及 HTML 两种格式
+const CompareSummaryReportPrefix = "compare_report_"
+
+// FoldChecksumColumns 字段哈希表达式按 CompareChecksumFoldColumns 分组拼接并再次哈希，直至字段数不超过分组数
+// 上下游以相同分组方式折叠，保证行 checksum 计算结构一致，且单次拼接长度不超过 Oracle VARCHAR2 上限
+func FoldChecksumColumns(cols []string, concat func([]string) string, hash func(string) string) []string {
+	for len(cols) > CompareChecksumFoldColumns {
+		var folded []string
+		for i := 0; i < len(cols); i += CompareChecksumFoldColumns {
+			end := i + CompareChecksumFoldColumns
+			if end > len(cols) {
+				end = len(cols)
+			}
+			folded = append(folded, hash(concat(cols[i:end])))
+		}
+		cols = folded
+	}
+	return cols
+}
+
+// CompareEpsilonEqual 数值字段容差对比，字段值按 ABS(a-b) <= epsilon 判断是否一致
+// epsilon 为 0、NULL 或者非数值字段值按字符串对比，字段值单引号包裹统一去除后转换数值
+func CompareEpsilonEqual(a, b string, epsilon float64) bool {
+	if a == b {
+		return true
+	}
+	if epsilon <= 0 || a == "NULL" || b == "NULL" {
+		return false
+	}
+	da, err := decimal.NewFromString(strings.Trim(a, "'"))
+	if err != nil {
+		return false
+	}
+	db, err := decimal.NewFromString(strings.Trim(b, "'"))
+	if err != nil {
+		return false
+	}
+	return da.Sub(db).Abs().LessThanOrEqual(decimal.NewFromFloat(epsilon))
+}
+
+// CompareEpsilonEnabled 是否存在配置 numeric-epsilon 容差字段
+// 容差在数据行拉取后对比，数据库端 checksum 无法容差，存在容差字段的表不使用 checksum 下推
+func CompareEpsilonEnabled(epsilons []float64) bool {
+	for _, e := range epsilons {
+		if e > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// CompareEpsilonRowsEqual 数据行逐字段容差对比，epsilons 与字段顺序一致
+func CompareEpsilonRowsEqual(a, b []string, epsilons []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		var epsilon float64
+		if i < len(epsilons) {
+			epsilon = epsilons[i]
+		}
+		if !CompareEpsilonEqual(a[i], b[i], epsilon) {
+			return false
+		}
+	}
+	return true
+}
+
+// FilterCompareEpsilonRows 整行集合对比差异数据行，上下游数据行非容差字段相同且容差字段差值不超过容差视为一致并成对剔除
+// 返回剩余上游以及下游差异数据行下标，不存在容差字段直接返回全部下标
+func FilterCompareEpsilonRows(sourceRows, targetRows [][]string, epsilons []float64) ([]int, []int) {
+	var sourceIdx, targetIdx []int
+	if !CompareEpsilonEnabled(epsilons) {
+		for i := range sourceRows {
+			sourceIdx = append(sourceIdx, i)
+		}
+		for i := range targetRows {
+			targetIdx = append(targetIdx, i)
+		}
+		return sourceIdx, targetIdx
+	}
+
+	// 下游数据行按非容差字段值分组
+	rowKey := func(row []string) string {
+		var values []string
+		for i, v := range row {
+			if i < len(epsilons) && epsilons[i] > 0 {
+				continue
+			}
+			values = append(values, v)
+		}
+		return strings.Join(values, ",")
+	}
+	targetGroups := make(map[string][]int)
+	for i, row := range targetRows {
+		k := rowKey(row)
+		targetGroups[k] = append(targetGroups[k], i)
+	}
+
+	matched := make(map[int]struct{})
+	for i, row := range sourceRows {
+		k := rowKey(row)
+		found := false
+		for j, idx := range targetGroups[k] {
+			if CompareEpsilonRowsEqual(row, targetRows[idx], epsilons) {
+				matched[idx] = struct{}{}
+				targetGroups[k] = append(targetGroups[k][:j], targetGroups[k][j+1:]...)
+				found = true
+				break
+			}
+		}
+		if !found {
+			sourceIdx = append(sourceIdx, i)
+		}
+	}
+	for i := range targetRows {
+		if _, ok := matched[i]; !ok {
+			targetIdx = append(targetIdx, i)
+		}
+	}
+	return sourceIdx, targetIdx
+}
@@ -0,0 +1,148 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package common
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestFoldChecksumColumns(t *testing.T) {
+	concat := func(cols []string) string { return "C(" + strings.Join(cols, ",") + ")" }
+	hash := func(expr string) string { return "H" + expr }
+
+	genCols := func(n int) []string {
+		var cols []string
+		for i := 0; i < n; i++ {
+			cols = append(cols, strconv.Itoa(i))
+		}
+		return cols
+	}
+
+	cases := []struct {
+		name    string
+		columns int
+		want    int
+	}{
+		{name: "single column", columns: 1, want: 1},
+		{name: "fold boundary", columns: CompareChecksumFoldColumns, want: CompareChecksumFoldColumns},
+		{name: "one more than boundary", columns: CompareChecksumFoldColumns + 1, want: 2},
+		{name: "two groups", columns: CompareChecksumFoldColumns * 2, want: 2},
+		{name: "fold twice", columns: CompareChecksumFoldColumns*CompareChecksumFoldColumns + 1, want: 2},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := FoldChecksumColumns(genCols(c.columns), concat, hash)
+			if len(got) != c.want {
+				t.Fatalf("fold %d columns got %d expressions, want %d", c.columns, len(got), c.want)
+			}
+		})
+	}
+
+	got := FoldChecksumColumns(genCols(CompareChecksumFoldColumns+1), concat, hash)
+	if got[1] != "HC(100)" {
+		t.Fatalf("last group got %q, want %q", got[1], "HC(100)")
+	}
+}
+
+func TestStringOracleCharacterSet(t *testing.T) {
+	cases := []struct {
+		characterSet string
+		want         string
+	}{
+		{characterSet: "AMERICAN_AMERICA.AL32UTF8", want: "AL32UTF8"},
+		{characterSet: "SIMPLIFIED CHINESE_CHINA.zhs16gbk", want: "ZHS16GBK"},
+		{characterSet: "AL32UTF8", want: "AL32UTF8"},
+		{characterSet: "", want: ""},
+	}
+	for _, c := range cases {
+		if got := StringOracleCharacterSet(c.characterSet); got != c.want {
+			t.Errorf("StringOracleCharacterSet(%q) = %q, want %q", c.characterSet, got, c.want)
+		}
+	}
+}
+
+func TestCompareEpsilonEqual(t *testing.T) {
+	cases := []struct {
+		a, b    string
+		epsilon float64
+		want    bool
+	}{
+		{a: "1.5", b: "1.5", epsilon: 0, want: true},
+		{a: "1.5", b: "1.50001", epsilon: 0, want: false},
+		{a: "1.5", b: "1.5004", epsilon: 0.001, want: true},
+		{a: "'1.5'", b: "'1.501'", epsilon: 0.001, want: true},
+		{a: "1.5", b: "1.5011", epsilon: 0.001, want: false},
+		{a: "1.4996", b: "1.5004", epsilon: 0.001, want: true},
+		{a: "-0.0004", b: "0.0004", epsilon: 0.001, want: true},
+		{a: "NULL", b: "0", epsilon: 0.001, want: false},
+		{a: "'abc'", b: "'abd'", epsilon: 1, want: false},
+	}
+	for _, c := range cases {
+		if got := CompareEpsilonEqual(c.a, c.b, c.epsilon); got != c.want {
+			t.Fatalf("compare [%s] [%s] epsilon %v got %v, want %v", c.a, c.b, c.epsilon, got, c.want)
+		}
+	}
+}
+
+func TestFilterCompareEpsilonRows(t *testing.T) {
+	sourceRows := [][]string{
+		{"1", "'a'", "'1.0001'"},
+		{"2", "'b'", "'2.5'"},
+		{"3", "'c'", "'3'"},
+	}
+	targetRows := [][]string{
+		{"1", "'a'", "'1.0003'"},
+		{"2", "'b'", "'2.6'"},
+		{"4", "'d'", "'4'"},
+	}
+	cases := []struct {
+		name       string
+		epsilons   []float64
+		wantSource []int
+		wantTarget []int
+	}{
+		{name: "without epsilon", epsilons: []float64{0, 0, 0}, wantSource: []int{0, 1, 2}, wantTarget: []int{0, 1, 2}},
+		{name: "epsilon", epsilons: []float64{0, 0, 0.001}, wantSource: []int{1, 2}, wantTarget: []int{1, 2}},
+		{name: "epsilon covers all", epsilons: []float64{0, 0, 0.5}, wantSource: []int{2}, wantTarget: []int{2}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			sourceIdx, targetIdx := FilterCompareEpsilonRows(sourceRows, targetRows, c.epsilons)
+			if !reflect.DeepEqual(sourceIdx, c.wantSource) || !reflect.DeepEqual(targetIdx, c.wantTarget) {
+				t.Fatalf("filter rows got (%v, %v), want (%v, %v)", sourceIdx, targetIdx, c.wantSource, c.wantTarget)
+			}
+		})
+	}
+}
+
+func TestCompareEpsilonEnabled(t *testing.T) {
+	cases := []struct {
+		epsilons []float64
+		want     bool
+	}{
+		{epsilons: nil, want: false},
+		{epsilons: []float64{0, 0}, want: false},
+		{epsilons: []float64{0, 0.01}, want: true},
+	}
+	for _, c := range cases {
+		if got := CompareEpsilonEnabled(c.epsilons); got != c.want {
+			t.Fatalf("CompareEpsilonEnabled(%v) got %v, want %v", c.epsilons, got, c.want)
+		}
+	}
+}
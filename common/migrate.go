@@ -15,6 +15,8 @@ limitations under the License.
 */
 package common
 
+import "time"
+
 // 数据全量/实时同步 Oracle 版本要求
 // 要求 oracle 11g 及以上
 const RequireOracleDBVersion = "11"
@@ -36,3 +38,12 @@ const (
 // 当值 == 0 启用 filterOracleIncrRecord 大于或者等于逻辑
 // 当值 == 1 启用 filterOracleIncrRecord 大于逻辑，避免已被消费得日志一直被重复消费
 var MigrateCurrentResetFlag = 0
+
+// 增量同步暂停应用标志，用于快照一致性校验获取一致时间点
+// 数据校验设置 REQUEST，增量同步日志文件应用间隙确认设置 PAUSED 并暂停应用，数据校验获取时间点后清空恢复应用
+// 暂停标志租约超时，数据校验异常退出未清空标志，增量同步超过租约自动清空恢复应用
+const (
+	MigrateSyncFenceRequest = "REQUEST"
+	MigrateSyncFencePaused  = "PAUSED"
+	MigrateSyncFenceLease   = 15 * time.Minute
+)
@@ -24,8 +24,16 @@ const (
 	MySQLCheckConsVersion = "8.0.15"
 	// MySQL 表达式索引版本 > 8.0.0
 	MySQLExpressionIndexVersion = "8.0.0"
+	// MySQL 函数索引（functional key parts）版本 >= 8.0.13
+	MySQLFunctionIndexVersion = "8.0.13"
+	// MySQL 不可见列版本 >= 8.0.23，TiDB 不支持
+	MySQLInvisibleColumnVersion = "8.0.23"
+	// TiDB 表达式索引版本 >= 6.1.0，且函数需在 tidb_allow_function_for_expression_index 范围内
+	TiDBExpressionIndexVersion = "6.1.0"
 	// MySQL 版本分隔符号
 	MySQLVersionDelimiter = "-"
+	// TiDB 版本分隔符号，例如: 5.7.25-TiDB-v6.5.0
+	TiDBVersionDelimiter = "-TiDB-v"
 	// MySQL 字符集
 	MySQLCharacterSet = "UTF8MB4"
 
@@ -33,12 +41,21 @@ const (
 	// 需要 oracle 12.2g 及以上
 	OracleTableColumnCollationDBVersion = "12.2"
 
+	// 允许 Oracle 自增列 identity column
+	// 需要 oracle 12c 及以上
+	OracleIdentityColumnDBVersion = "12.1"
+
 	// Oracle 用户、表、字段默认使用 DB 排序规则
 	OracleUserTableColumnDefaultCollation = "USING_NLS_COMP"
 
 	// CSV 字符集判断
-	UTF8CharacterSetCSV = "UTF8"
-	GBKCharacterSetCSV  = "GBK"
+	UTF8CharacterSetCSV     = "UTF8"
+	GBKCharacterSetCSV      = "GBK"
+	GB18030CharacterSetCSV  = "GB18030"
+	Latin1CharacterSetCSV   = "LATIN1"
+	ShiftJISCharacterSetCSV = "SJIS"
+	EUCKRCharacterSetCSV    = "EUCKR"
+	Big5CharacterSetCSV     = "BIG5"
 
 	// Struct JSON 格式化 -> Check 阶段
 	JSONColumns      = "COLUMN"
@@ -57,8 +74,41 @@ const (
 	TiDBClusteredIndexIntOnlyValue = "INT_ONLY"
 	TiDBClusteredIndexONValue      = "ON"
 	TiDBClusteredIndexOFFValue     = "OFF"
+
+	// Oracle identity column 转换策略
+	ReverseIdentityPolicyAutoIncrement = "AUTO-INCREMENT"
+	ReverseIdentityPolicyAutoRandom    = "AUTO-RANDOM"
+	ReverseIdentityPolicyNone          = "NONE"
+
+	// 超长标识符处理策略，mysql/tidb 标识符最大长度 64
+	ReverseIdentifierPolicyNone    = "NONE"
+	ReverseIdentifierPolicyShorten = "SHORTEN"
+	MySQLIdentifierMaxLength       = 64
+
+	// TiDB 序列取值范围
+	TiDBSequenceMaxValue = "9223372036854775806"
+	TiDBSequenceMinValue = "-9223372036854775807"
+)
+
+/*
+O2P Oracle Reverse PostgreSQL
+*/
+const (
+	// PostgreSQL 标识符最大长度 63 字节，超长标识符静默截断
+	PostgresIdentifierMaxLength = 63
+	// PostgreSQL identity column 版本 >= 10
+	PostgresIdentityColumnVersion = "10"
+	// PostgreSQL generated column 版本 >= 12，仅支持 STORED
+	PostgresGeneratedColumnVersion = "12"
+
+	// PostgreSQL 序列取值范围 bigint
+	PostgresSequenceMaxValue = "9223372036854775807"
+	PostgresSequenceMinValue = "-9223372036854775808"
 )
 
+// TiDB 表达式索引默认允许函数
+var TiDBExpressionIndexFunctions = []string{"LOWER", "UPPER", "MD5", "REVERSE", "VITESS_HASH", "TIDB_SHARD"}
+
 // alter-primary-key = fase 主键整型数据类型列表
 var TiDBIntegerPrimaryKeyList = []string{"TINYINT", "SMALLINT", "INT", "BIGINT", "DECIMAL"}
 
@@ -91,10 +141,29 @@ var OracleDBCharacterSetMap = map[string]string{
 
 // ORACLE 字符集映射规则
 var OracleDBCSVCharacterSetMap = map[string]string{
-	"AL32UTF8":  "UTF8",
-	"UTF8":      "UTF8",
-	"ZHT16BIG5": "UTF8MB4",
-	"ZHS16GBK":  "GBK",
+	"AL32UTF8":       "UTF8",
+	"UTF8":           "UTF8",
+	"ZHT16BIG5":      "BIG5",
+	"ZHT16MSWIN950":  "BIG5",
+	"ZHS16GBK":       "GBK",
+	"ZHS16CGB231280": "GBK",
+	"ZHS32GB18030":   "GB18030",
+	"WE8ISO8859P1":   "LATIN1",
+	"JA16SJIS":       "SJIS",
+	"JA16SJISTILDE":  "SJIS",
+	"KO16KSC5601":    "EUCKR",
+	"KO16MSWIN949":   "EUCKR",
+}
+
+// CSV 字符集对应 MySQL LOAD DATA 字符集
+var CSVCharacterSetMySQLMap = map[string]string{
+	UTF8CharacterSetCSV:     "utf8mb4",
+	GBKCharacterSetCSV:      "gbk",
+	GB18030CharacterSetCSV:  "gb18030",
+	Latin1CharacterSetCSV:   "latin1",
+	ShiftJISCharacterSetCSV: "sjis",
+	EUCKRCharacterSetCSV:    "euckr",
+	Big5CharacterSetCSV:     "big5",
 }
 
 /*
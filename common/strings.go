@@ -18,8 +18,14 @@ package common
 import (
 	"bytes"
 	"fmt"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/korean"
 	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
 	"golang.org/x/text/transform"
+	"hash/crc32"
 	"io/ioutil"
 	"os"
 	"reflect"
@@ -192,6 +198,76 @@ func StringUPPER(str string) string {
 	return strings.ToUpper(str)
 }
 
+// 字段名映射，不存在映射规则返回原字段名
+func StringColumnNameRule(columnName string, columnNameRule map[string]string) string {
+	if val, ok := columnNameRule[strings.ToUpper(columnName)]; ok {
+		return val
+	}
+	return columnName
+}
+
+// 逗号分隔字段列表字段名映射
+func StringColumnListRule(columnList string, columnNameRule map[string]string) string {
+	if len(columnNameRule) == 0 {
+		return columnList
+	}
+	var columns []string
+	for _, col := range strings.Split(columnList, ",") {
+		columns = append(columns, StringColumnNameRule(strings.TrimSpace(col), columnNameRule))
+	}
+	return strings.Join(columns, ",")
+}
+
+// Oracle NLS_LANG 格式 LANGUAGE_TERRITORY.CHARSET 截取字符集，不存在分隔符返回原值
+func StringOracleCharacterSet(characterSet string) string {
+	if idx := strings.LastIndex(characterSet, "."); idx >= 0 {
+		return strings.ToUpper(characterSet[idx+1:])
+	}
+	return strings.ToUpper(characterSet)
+}
+
+// 数据行字段值拼接字符串按逗号拆分，单引号包裹字符值内逗号以及反斜杠转义字符不拆分
+func SplitRowValues(row string) []string {
+	var (
+		values  []string
+		start   int
+		inQuote bool
+		escaped bool
+	)
+	for i := 0; i < len(row); i++ {
+		switch {
+		case escaped:
+			escaped = false
+		case row[i] == '\\' && inQuote:
+			escaped = true
+		case row[i] == '\'':
+			inQuote = !inQuote
+		case row[i] == ',' && !inQuote:
+			values = append(values, row[start:i])
+			start = i + 1
+		}
+	}
+	return append(values, row[start:])
+}
+
+// 标识符超长截断，保留前缀并追加原标识符 crc32 哈希后缀，同一标识符截断结果确定
+func StringShortenIdentifier(identifier string, maxLength int) string {
+	runes := []rune(identifier)
+	if len(runes) <= maxLength {
+		return identifier
+	}
+	suffix := fmt.Sprintf("_%08X", crc32.ChecksumIEEE([]byte(identifier)))
+	return StringsBuilder(string(runes[:maxLength-len(suffix)]), suffix)
+}
+
+// 标识符按超长标识符处理策略转换
+func StringIdentifierPolicy(identifier, policy string) string {
+	if strings.EqualFold(policy, ReverseIdentifierPolicyShorten) {
+		return StringShortenIdentifier(identifier, MySQLIdentifierMaxLength)
+	}
+	return identifier
+}
+
 // 字符串 JOIN
 func StringJOIN(strs []string, strPrefix, strSuffix, joinS string) string {
 	var tmpStr []string
@@ -397,6 +473,79 @@ func Utf8ToGbk(s []byte) ([]byte, error) {
 	return d, nil
 }
 
+// CSV 输出字符集对应编码，UTF8 无需转换返回 nil
+func CSVCharsetEncoding(charset string) (encoding.Encoding, error) {
+	switch strings.ToUpper(charset) {
+	case UTF8CharacterSetCSV:
+		return nil, nil
+	case GBKCharacterSetCSV:
+		return simplifiedchinese.GBK, nil
+	case GB18030CharacterSetCSV:
+		return simplifiedchinese.GB18030, nil
+	case Latin1CharacterSetCSV:
+		return charmap.ISO8859_1, nil
+	case ShiftJISCharacterSetCSV:
+		return japanese.ShiftJIS, nil
+	case EUCKRCharacterSetCSV:
+		return korean.EUCKR, nil
+	case Big5CharacterSetCSV:
+		return traditionalchinese.Big5, nil
+	default:
+		return nil, fmt.Errorf("csv charset [%s] isn't support", charset)
+	}
+}
+
+// UTF-8 转目标字符集，无法编码字符按策略处理：REPLACE 替换为 '?'，SKIP 丢弃，FAIL 报错
+// 返回转换结果以及替换/丢弃字符数
+func Utf8ToCharset(s []byte, enc encoding.Encoding, policy string) ([]byte, int64, error) {
+	// UTF8 原样输出
+	if enc == nil {
+		return s, 0, nil
+	}
+	// 快速路径，全部字符可正常编码
+	if utf8.Valid(s) {
+		if d, err := enc.NewEncoder().Bytes(s); err == nil {
+			return d, 0, nil
+		}
+	}
+
+	// 逐字符处理
+	var (
+		buf      bytes.Buffer
+		replaced int64
+	)
+	encoder := enc.NewEncoder()
+	for len(s) > 0 {
+		r, size := utf8.DecodeRune(s)
+
+		var (
+			out []byte
+			err error
+		)
+		if r == utf8.RuneError && size == 1 {
+			err = fmt.Errorf("invalid utf8 byte [%#x]", s[0])
+		} else {
+			out, err = encoder.Bytes(s[:size])
+		}
+
+		if err != nil {
+			switch strings.ToUpper(policy) {
+			case CSVCharsetErrorPolicySkip:
+				replaced++
+			case CSVCharsetErrorPolicyReplace:
+				buf.WriteByte('?')
+				replaced++
+			default:
+				return nil, replaced, fmt.Errorf("character [%q] can't be encoded: %v", s[:size], err)
+			}
+		} else {
+			buf.Write(out)
+		}
+		s = s[size:]
+	}
+	return buf.Bytes(), replaced, nil
+}
+
 // 如果存在特殊字符，直接在特殊字符前添加\
 /**
 判断是否为字母： unicode.IsLetter(v)
@@ -466,3 +615,21 @@ func PathExist(path string) error {
 	}
 	return err
 }
+
+// IsOracleMySQLCollationMatch oracle 字段排序规则与 mysql 字段排序规则是否按 OracleCollationMap 映射一致
+func IsOracleMySQLCollationMatch(oracleCollation, mysqlCollation string) bool {
+	collation, ok := OracleCollationMap[strings.ToUpper(oracleCollation)]
+	return ok && strings.EqualFold(collation, mysqlCollation)
+}
+
+// IsOracleBinaryCollation oracle 排序规则是否按字节比较
+func IsOracleBinaryCollation(collation string) bool {
+	collation = strings.ToUpper(collation)
+	return collation == "BINARY" || collation == "BINARY_CS"
+}
+
+// IsMySQLBinaryCollation mysql 排序规则是否按字节比较
+func IsMySQLBinaryCollation(collation string) bool {
+	collation = strings.ToLower(collation)
+	return collation == "binary" || strings.HasSuffix(collation, "_bin")
+}
@@ -0,0 +1,154 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package common
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitRowValues(t *testing.T) {
+	cases := []struct {
+		name string
+		row  string
+		want []string
+	}{
+		{name: "numbers and null", row: `1,NULL,3.5`, want: []string{`1`, `NULL`, `3.5`}},
+		{name: "escaped comma in string", row: `1,'a\,b',2`, want: []string{`1`, `'a\,b'`, `2`}},
+		{name: "escaped quote in string", row: `'it\'s',NULL`, want: []string{`'it\'s'`, `NULL`}},
+		{name: "escaped backslash before quote", row: `'a\\',2`, want: []string{`'a\\'`, `2`}},
+		{name: "single value", row: `'x'`, want: []string{`'x'`}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := SplitRowValues(c.row); !reflect.DeepEqual(got, c.want) {
+				t.Fatalf("SplitRowValues(%q) = %q, want %q", c.row, got, c.want)
+			}
+		})
+	}
+}
+
+func TestSplitRowValuesFormatted(t *testing.T) {
+	// 与数据行格式化转义保持一致，字段值内逗号以及单引号不影响拆分
+	values := []string{"1", "'" + SpecialLettersUsingMySQL([]byte("a,b'c d")) + "'", "NULL"}
+	if got := SplitRowValues(strings.Join(values, ",")); !reflect.DeepEqual(got, values) {
+		t.Fatalf("SplitRowValues got %q, want %q", got, values)
+	}
+}
+
+func TestOracleMySQLCollation(t *testing.T) {
+	cases := []struct {
+		name   string
+		oracle string
+		mysql  string
+		match  bool
+		binary bool
+	}{
+		{name: "binary and bin", oracle: "BINARY", mysql: "utf8mb4_bin", match: true, binary: true},
+		{name: "binary_cs and bin", oracle: "binary_cs", mysql: "UTF8MB4_BIN", match: true, binary: true},
+		{name: "binary and binary charset", oracle: "BINARY", mysql: "binary", match: false, binary: true},
+		{name: "binary_ai and general_ci", oracle: "BINARY_AI", mysql: "utf8mb4_general_ci", match: true, binary: false},
+		{name: "binary and general_ci", oracle: "BINARY", mysql: "utf8mb4_general_ci", match: false, binary: false},
+		{name: "unknown oracle collation", oracle: "USING_NLS_COMP", mysql: "utf8mb4_bin", match: false, binary: false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := IsOracleMySQLCollationMatch(c.oracle, c.mysql); got != c.match {
+				t.Fatalf("IsOracleMySQLCollationMatch(%q, %q) = %v, want %v", c.oracle, c.mysql, got, c.match)
+			}
+			if got := IsOracleBinaryCollation(c.oracle) && IsMySQLBinaryCollation(c.mysql); got != c.binary {
+				t.Fatalf("binary collation (%q, %q) = %v, want %v", c.oracle, c.mysql, got, c.binary)
+			}
+		})
+	}
+}
+
+func TestCSVCharsetEncoding(t *testing.T) {
+	cases := []struct {
+		charset string
+		nilEnc  bool
+		wantErr bool
+	}{
+		{charset: "utf8", nilEnc: true},
+		{charset: "GBK"},
+		{charset: "GB18030"},
+		{charset: "LATIN1"},
+		{charset: "SJIS"},
+		{charset: "EUCKR"},
+		{charset: "BIG5"},
+		{charset: "UTF16", wantErr: true},
+		{charset: "", wantErr: true},
+	}
+	for _, c := range cases {
+		enc, err := CSVCharsetEncoding(c.charset)
+		if (err != nil) != c.wantErr {
+			t.Fatalf("CSVCharsetEncoding(%q) error got %v, want error %v", c.charset, err, c.wantErr)
+		}
+		if !c.wantErr && (enc == nil) != c.nilEnc {
+			t.Fatalf("CSVCharsetEncoding(%q) encoding got %v, want nil %v", c.charset, enc, c.nilEnc)
+		}
+	}
+}
+
+func TestUtf8ToCharset(t *testing.T) {
+	latin1, err := CSVCharsetEncoding(Latin1CharacterSetCSV)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gbk, err := CSVCharsetEncoding(GBKCharacterSetCSV)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		name         string
+		charset      string
+		raw          []byte
+		policy       string
+		want         []byte
+		wantReplaced int64
+		wantErr      bool
+	}{
+		{name: "utf8 passthrough", charset: UTF8CharacterSetCSV, raw: []byte("a中b"), policy: CSVCharsetErrorPolicyFail, want: []byte("a中b")},
+		{name: "gbk encodable", charset: GBKCharacterSetCSV, raw: []byte("a中b"), policy: CSVCharsetErrorPolicyFail, want: []byte{'a', 0xd6, 0xd0, 'b'}},
+		{name: "latin1 unencodable fail", charset: Latin1CharacterSetCSV, raw: []byte("a中b"), policy: CSVCharsetErrorPolicyFail, wantErr: true},
+		{name: "latin1 unencodable replace", charset: Latin1CharacterSetCSV, raw: []byte("a中b中"), policy: CSVCharsetErrorPolicyReplace, want: []byte("a?b?"), wantReplaced: 2},
+		{name: "latin1 unencodable skip", charset: Latin1CharacterSetCSV, raw: []byte("a中b"), policy: CSVCharsetErrorPolicySkip, want: []byte("ab"), wantReplaced: 1},
+		{name: "invalid utf8 replace", charset: GBKCharacterSetCSV, raw: []byte{'a', 0xff, 'b'}, policy: CSVCharsetErrorPolicyReplace, want: []byte("a?b"), wantReplaced: 1},
+		{name: "invalid utf8 fail", charset: GBKCharacterSetCSV, raw: []byte{'a', 0xff, 'b'}, policy: CSVCharsetErrorPolicyFail, wantErr: true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			enc := latin1
+			switch c.charset {
+			case UTF8CharacterSetCSV:
+				enc = nil
+			case GBKCharacterSetCSV:
+				enc = gbk
+			}
+			got, replaced, err := Utf8ToCharset(c.raw, enc, c.policy)
+			if (err != nil) != c.wantErr {
+				t.Fatalf("Utf8ToCharset error got %v, want error %v", err, c.wantErr)
+			}
+			if c.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(got, c.want) || replaced != c.wantReplaced {
+				t.Fatalf("Utf8ToCharset got (%q, %d), want (%q, %d)", got, replaced, c.want, c.wantReplaced)
+			}
+		})
+	}
+}
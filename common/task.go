@@ -30,14 +30,16 @@ const ChannelBufferSize = 1024
 
 // 任务模式
 const (
-	TaskModePrepare = "PREPARE"
-	TaskModeAssess  = "ASSESS"
-	TaskModeReverse = "REVERSE"
-	TaskModeCheck   = "CHECK"
-	TaskModeCompare = "COMPARE"
-	TaskModeCSV     = "CSV"
-	TaskModeFull    = "FULL"
-	TaskModeAll     = "ALL"
+	TaskModePrepare   = "PREPARE"
+	TaskModeAssess    = "ASSESS"
+	TaskModeReverse   = "REVERSE"
+	TaskModeCheck     = "CHECK"
+	TaskModeCompare   = "COMPARE"
+	TaskModeCSV       = "CSV"
+	TaskModeCSVImport = "CSV-IMPORT"
+	TaskModeFull      = "FULL"
+	TaskModeAll       = "ALL"
+	TaskModeSequence  = "SEQUENCE"
 )
 
 // 任务状态
@@ -61,4 +63,43 @@ const (
 	DatabaseTypeOracle = "ORACLE"
 	DatabaseTypeTiDB   = "TIDB"
 	DatabaseTypeMySQL  = "MYSQL"
+	// PostgreSQL 仅支持 oracle -> postgres reverse
+	DatabaseTypePostgres = "POSTGRES"
+)
+
+// CSV 导出目录布局
+const (
+	// 默认布局 ${output-dir}/${source_schema}/${source_table}/${target_schema}.${target_table}.${seq}.csv
+	CSVExportLayoutDefault = "DEFAULT"
+	// TiDB Lightning / Dumpling 兼容布局，${output-dir} 下平铺 schema 文件、数据文件以及 metadata 文件
+	CSVExportLayoutLightning = "LIGHTNING"
+	// Lightning 布局 metadata 文件名
+	CSVLightningMetadataFile = "metadata"
+	// 导出清单文件名，记录各数据文件行数，用于 csv-import 校验
+	CSVManifestFile = "transferdb-manifest.jsonl"
+)
+
+// CSV 模式数据文件输出格式
+const (
+	CSVOutputFormatCSV     = "CSV"
+	CSVOutputFormatParquet = "PARQUET"
+)
+
+// CSV 字段值输出
+const (
+	// NULL 默认输出标识
+	CSVNullValueDefault = "NULL"
+	// RAW/BLOB 二进制编码方式
+	CSVBinaryEncodingHex    = "HEX"
+	CSVBinaryEncodingBase64 = "BASE64"
+	// LOB 溢出文件目录后缀以及文件后缀
+	CSVLOBSpillDirSuffix  = "_lob"
+	CSVLOBSpillFileSuffix = ".lob"
+)
+
+// CSV 字符集无法编码字符处理策略
+const (
+	CSVCharsetErrorPolicyFail    = "FAIL"
+	CSVCharsetErrorPolicyReplace = "REPLACE"
+	CSVCharsetErrorPolicySkip    = "SKIP"
 )
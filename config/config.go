@@ -21,29 +21,31 @@ import (
 	"fmt"
 	"github.com/wentaojin/transferdb/common"
 	"os"
+	"strings"
 
 	"github.com/BurntSushi/toml"
 )
 
 // 程序配置文件
 type Config struct {
-	*flag.FlagSet `json:"-"`
-	AppConfig     AppConfig     `toml:"app" json:"app"`
-	ReverseConfig ReverseConfig `toml:"reverse" json:"reverse"`
-	CheckConfig   CheckConfig   `toml:"check" json:"check"`
-	FullConfig    FullConfig    `toml:"full" json:"full"`
-	CSVConfig     CSVConfig     `toml:"csv" json:"csv"`
-	AllConfig     AllConfig     `toml:"all" json:"all"`
-	OracleConfig  OracleConfig  `toml:"oracle" json:"oracle"`
-	MySQLConfig   MySQLConfig   `toml:"mysql" json:"mysql"`
-	MetaConfig    MetaConfig    `toml:"meta" json:"meta"`
-	LogConfig     LogConfig     `toml:"log" json:"log"`
-	DiffConfig    DiffConfig    `toml:"compare" json:"compare"`
-	ConfigFile    string        `json:"config-file"`
-	PrintVersion  bool
-	TaskMode      string `json:"task-mode"`
-	DBTypeS       string `json:"db-type-s"`
-	DBTypeT       string `json:"db-type-t"`
+	*flag.FlagSet  `json:"-"`
+	AppConfig      AppConfig      `toml:"app" json:"app"`
+	ReverseConfig  ReverseConfig  `toml:"reverse" json:"reverse"`
+	CheckConfig    CheckConfig    `toml:"check" json:"check"`
+	FullConfig     FullConfig     `toml:"full" json:"full"`
+	CSVConfig      CSVConfig      `toml:"csv" json:"csv"`
+	AllConfig      AllConfig      `toml:"all" json:"all"`
+	OracleConfig   OracleConfig   `toml:"oracle" json:"oracle"`
+	MySQLConfig    MySQLConfig    `toml:"mysql" json:"mysql"`
+	PostgresConfig PostgresConfig `toml:"postgres" json:"postgres"`
+	MetaConfig     MetaConfig     `toml:"meta" json:"meta"`
+	LogConfig      LogConfig      `toml:"log" json:"log"`
+	DiffConfig     DiffConfig     `toml:"compare" json:"compare"`
+	ConfigFile     string         `json:"config-file"`
+	PrintVersion   bool
+	TaskMode       string `json:"task-mode"`
+	DBTypeS        string `json:"db-type-s"`
+	DBTypeT        string `json:"db-type-t"`
 }
 
 type AppConfig struct {
@@ -56,17 +58,32 @@ type DiffConfig struct {
 	ChunkSize         int           `toml:"chunk-size" json:"chunk-size"`
 	DiffThreads       int           `toml:"diff-threads" json:"diff-threads"`
 	OnlyCheckRows     bool          `toml:"only-check-rows" json:"only-check-rows"`
+	ChecksumFirst     bool          `toml:"checksum-first" json:"checksum-first"`
+	SnapshotCompare   bool          `toml:"snapshot-compare" json:"snapshot-compare"`
+	SamplePercent     float64       `toml:"sample-percent" json:"sample-percent"`
+	SampleBlock       bool          `toml:"sample-block" json:"sample-block"`
+	SampleEscalate    bool          `toml:"sample-escalate" json:"sample-escalate"`
 	EnableCheckpoint  bool          `toml:"enable-checkpoint" json:"enable-checkpoint"`
 	IgnoreStructCheck bool          `toml:"ignore-struct-check" json:"ignore-struct-check"`
 	FixSqlDir         string        `toml:"fix-sql-dir" json:"fix-sql-dir"`
+	Repair            bool          `toml:"repair" json:"repair"`
+	RepairDryRun      bool          `toml:"repair-dry-run" json:"repair-dry-run"`
+	RepairBatchSize   int           `toml:"repair-batch-size" json:"repair-batch-size"`
+	Recheck           bool          `toml:"recheck" json:"recheck"`
+	RecheckBefore     string        `toml:"recheck-before" json:"recheck-before"`
 	TableConfig       []TableConfig `toml:"table-config" json:"table-config"`
 }
 
 type ReverseConfig struct {
-	ReverseThreads   int    `toml:"reverse-threads" json:"reverse-threads"`
-	DirectWrite      bool   `toml:"direct-write" json:"direct-write"`
-	DDLReverseDir    string `toml:"ddl-reverse-dir" json:"ddl-reverse-dir"`
-	DDLCompatibleDir string `toml:"ddl-compatible-dir" json:"ddl-compatible-dir"`
+	ReverseThreads     int    `toml:"reverse-threads" json:"reverse-threads"`
+	DirectWrite        bool   `toml:"direct-write" json:"direct-write"`
+	DDLReverseDir      string `toml:"ddl-reverse-dir" json:"ddl-reverse-dir"`
+	DDLCompatibleDir   string `toml:"ddl-compatible-dir" json:"ddl-compatible-dir"`
+	IdentityPolicy     string `toml:"identity-policy" json:"identity-policy"`
+	ForeignKeyPostLoad bool   `toml:"foreign-key-post-load" json:"foreign-key-post-load"`
+	IdentifierPolicy   string `toml:"identifier-policy" json:"identifier-policy"`
+	DiffExistingTable  bool   `toml:"diff-existing-table" json:"diff-existing-table"`
+	IntervalPartitions int    `toml:"interval-partitions" json:"interval-partitions"`
 }
 
 type CheckConfig struct {
@@ -75,24 +92,42 @@ type CheckConfig struct {
 }
 
 type TableConfig struct {
-	SourceTable string `toml:"source-table" json:"source-table"`
-	IndexFields string `toml:"index-fields" json:"index-fields"`
-	Range       string `toml:"range" json:"range"`
+	SourceTable string       `toml:"source-table" json:"source-table"`
+	IndexFields string       `toml:"index-fields" json:"index-fields"`
+	Range       string       `toml:"range" json:"range"`
+	ColumnRules []ColumnRule `toml:"column-rules" json:"column-rules"`
+}
+
+type ColumnRule struct {
+	ColumnName         string  `toml:"column-name" json:"column-name"`
+	Ignore             bool    `toml:"ignore" json:"ignore"`
+	Trim               bool    `toml:"trim" json:"trim"`
+	CaseInsensitive    bool    `toml:"case-insensitive" json:"case-insensitive"`
+	NumericEpsilon     float64 `toml:"numeric-epsilon" json:"numeric-epsilon"`
+	TimestampPrecision int     `toml:"timestamp-precision" json:"timestamp-precision"`
+	TimestampUTC       bool    `toml:"timestamp-utc" json:"timestamp-utc"`
 }
 
 type CSVConfig struct {
-	Header           bool   `toml:"header" json:"header"`
-	Separator        string `toml:"separator" json:"separator"`
-	Terminator       string `toml:"terminator" json:"terminator"`
-	Delimiter        string `toml:"delimiter" json:"delimiter"`
-	EscapeBackslash  bool   `toml:"escape-backslash" json:"escape-backslash"`
-	Charset          string `toml:"charset" json:"charset"`
-	Rows             int    `toml:"rows" json:"rows"`
-	OutputDir        string `toml:"output-dir" json:"output-dir"`
-	TaskThreads      int    `toml:"task-threads" json:"task-threads"`
-	TableThreads     int    `toml:"table-threads" json:"table-threads"`
-	SQLThreads       int    `toml:"sql-threads" json:"sql-threads"`
-	EnableCheckpoint bool   `toml:"enable-checkpoint" json:"enable-checkpoint"`
+	Header             bool   `toml:"header" json:"header"`
+	Separator          string `toml:"separator" json:"separator"`
+	Terminator         string `toml:"terminator" json:"terminator"`
+	Delimiter          string `toml:"delimiter" json:"delimiter"`
+	EscapeBackslash    bool   `toml:"escape-backslash" json:"escape-backslash"`
+	Charset            string `toml:"charset" json:"charset"`
+	Rows               int    `toml:"rows" json:"rows"`
+	OutputDir          string `toml:"output-dir" json:"output-dir"`
+	TaskThreads        int    `toml:"task-threads" json:"task-threads"`
+	TableThreads       int    `toml:"table-threads" json:"table-threads"`
+	SQLThreads         int    `toml:"sql-threads" json:"sql-threads"`
+	EnableCheckpoint   bool   `toml:"enable-checkpoint" json:"enable-checkpoint"`
+	ExportLayout       string `toml:"export-layout" json:"export-layout"`
+	OutputFormat       string `toml:"output-format" json:"output-format"`
+	NullValue          string `toml:"null-value" json:"null-value"`
+	KeepEmptyString    bool   `toml:"keep-empty-string" json:"keep-empty-string"`
+	LOBSpillThreshold  int    `toml:"lob-spill-threshold" json:"lob-spill-threshold"`
+	BinaryEncoding     string `toml:"binary-encoding" json:"binary-encoding"`
+	CharsetErrorPolicy string `toml:"charset-error-policy" json:"charset-error-policy"`
 }
 
 type FullConfig struct {
@@ -102,6 +137,7 @@ type FullConfig struct {
 	SQLThreads       int  `toml:"sql-threads" json:"sql-threads"`
 	ApplyThreads     int  `toml:"apply-threads" json:"apply-threads"`
 	EnableCheckpoint bool `toml:"enable-checkpoint" json:"enable-checkpoint"`
+	SyncSequence     bool `toml:"sync-sequence" json:"sync-sequence"`
 }
 
 type AllConfig struct {
@@ -129,16 +165,28 @@ type OracleConfig struct {
 }
 
 type MySQLConfig struct {
-	DBType        string `toml:"db-type" json:"db-type"`
+	DBType        string   `toml:"db-type" json:"db-type"`
+	Username      string   `toml:"username" json:"username"`
+	Password      string   `toml:"password" json:"password"`
+	Host          string   `toml:"host" json:"host"`
+	Port          int      `toml:"port" json:"port"`
+	ConnectParams string   `toml:"connect-params" json:"connect-params"`
+	MetaSchema    string   `toml:"meta-schema" json:"meta-schema"`
+	SchemaName    string   `toml:"schema-name" json:"schema-name"`
+	IncludeTable  []string `toml:"include-table" json:"include-table"`
+	ExcludeTable  []string `toml:"exclude-table" json:"exclude-table"`
+	TableOption   string   `toml:"table-option" json:"table-option"`
+	Overwrite     bool     `toml:"overwrite" json:"overwrite"`
+}
+
+type PostgresConfig struct {
 	Username      string `toml:"username" json:"username"`
 	Password      string `toml:"password" json:"password"`
 	Host          string `toml:"host" json:"host"`
 	Port          int    `toml:"port" json:"port"`
+	DBName        string `toml:"db-name" json:"db-name"`
 	ConnectParams string `toml:"connect-params" json:"connect-params"`
-	MetaSchema    string `toml:"meta-schema" json:"meta-schema"`
 	SchemaName    string `toml:"schema-name" json:"schema-name"`
-	TableOption   string `toml:"table-option" json:"table-option"`
-	Overwrite     bool   `toml:"overwrite" json:"overwrite"`
 }
 
 type MetaConfig struct {
@@ -168,7 +216,7 @@ func NewConfig() *Config {
 	}
 	fs.BoolVar(&cfg.PrintVersion, "V", false, "print version information and exit")
 	fs.StringVar(&cfg.ConfigFile, "config", "./config.toml", "path to the configuration file")
-	fs.StringVar(&cfg.TaskMode, "mode", "", "specify the program running mode: [prepare assess reverse full csv all check compare]")
+	fs.StringVar(&cfg.TaskMode, "mode", "", "specify the program running mode: [prepare assess reverse full csv csv-import all sequence check compare]")
 	fs.StringVar(&cfg.DBTypeS, "source", "oracle", "specify the source db type")
 	fs.StringVar(&cfg.DBTypeT, "target", "mysql", "specify the target db type")
 	return cfg
@@ -217,6 +265,47 @@ func (c *Config) AdjustConfig() {
 	c.OracleConfig.SchemaName = common.StringUPPER(c.OracleConfig.SchemaName)
 	c.OracleConfig.PDBName = common.StringUPPER(c.OracleConfig.PDBName)
 	c.MySQLConfig.SchemaName = common.StringUPPER(c.MySQLConfig.SchemaName)
+	// postgres schema 保留配置原值，目标端标识符由 reverse o2p genIdentifier 统一转换小写引用
+	c.ReverseConfig.IdentityPolicy = common.StringUPPER(c.ReverseConfig.IdentityPolicy)
+	if c.ReverseConfig.IdentityPolicy == "" {
+		c.ReverseConfig.IdentityPolicy = common.ReverseIdentityPolicyAutoIncrement
+	}
+	c.ReverseConfig.IdentifierPolicy = common.StringUPPER(c.ReverseConfig.IdentifierPolicy)
+	if c.ReverseConfig.IdentifierPolicy == "" {
+		c.ReverseConfig.IdentifierPolicy = common.ReverseIdentifierPolicyNone
+	}
+	c.CSVConfig.ExportLayout = common.StringUPPER(c.CSVConfig.ExportLayout)
+	if c.CSVConfig.ExportLayout == "" {
+		c.CSVConfig.ExportLayout = common.CSVExportLayoutDefault
+	}
+	c.CSVConfig.OutputFormat = common.StringUPPER(c.CSVConfig.OutputFormat)
+	if c.CSVConfig.OutputFormat == "" {
+		c.CSVConfig.OutputFormat = common.CSVOutputFormatCSV
+	}
+}
+
+// GetColumnRule 获取表字段数据校验规则，字段规则优先于表级 "*" 规则
+func (d DiffConfig) GetColumnRule(tableName, columnName string) ColumnRule {
+	var tableRule, columnRule *ColumnRule
+	for _, tableCfg := range d.TableConfig {
+		if !strings.EqualFold(tableName, tableCfg.SourceTable) {
+			continue
+		}
+		for i, rule := range tableCfg.ColumnRules {
+			if rule.ColumnName == "*" {
+				tableRule = &tableCfg.ColumnRules[i]
+			} else if strings.EqualFold(rule.ColumnName, columnName) {
+				columnRule = &tableCfg.ColumnRules[i]
+			}
+		}
+	}
+	if columnRule != nil {
+		return *columnRule
+	}
+	if tableRule != nil {
+		return *tableRule
+	}
+	return ColumnRule{}
 }
 
 func (c *Config) String() string {
@@ -1347,3 +1347,20 @@ func (rw *BuildinDatatypeRule) InitT2OBuildinDatatypeRule(ctx context.Context) e
 	})
 	return rw.DB(ctx).Clauses(clause.Insert{Modifier: "IGNORE"}).Create(buildinDataTypeR).Error
 }
+
+func (rw *BuildinDatatypeRule) InitO2PBuildinDatatypeRule(ctx context.Context) error {
+	var buildinDataTypeR []*BuildinDatatypeRule
+	/*
+		O2P Build-IN Compatible Rule
+	*/
+	// oracle column datatype name
+	for datatypeS, datatypeT := range common.BuildInOracleO2PDatatypeNameMap {
+		buildinDataTypeR = append(buildinDataTypeR, &BuildinDatatypeRule{
+			DBTypeS:       common.DatabaseTypeOracle,
+			DBTypeT:       common.DatabaseTypePostgres,
+			DatatypeNameS: datatypeS,
+			DatatypeNameT: datatypeT,
+		})
+	}
+	return rw.DB(ctx).Clauses(clause.Insert{Modifier: "IGNORE"}).Create(buildinDataTypeR).Error
+}
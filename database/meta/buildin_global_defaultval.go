@@ -137,3 +137,22 @@ func (rw *BuildinGlobalDefaultval) InitMT2OBuildinGlobalDefaultValue(ctx context
 	})
 	return rw.DB(ctx).Clauses(clause.Insert{Modifier: "IGNORE"}).Create(buildinColumDefaultvals).Error
 }
+
+func (rw *BuildinGlobalDefaultval) InitO2PBuildinGlobalDefaultValue(ctx context.Context) error {
+	var buildinColumDefaultvals []*BuildinGlobalDefaultval
+
+	buildinColumDefaultvals = append(buildinColumDefaultvals, &BuildinGlobalDefaultval{
+		DBTypeS:       common.DatabaseTypeOracle,
+		DBTypeT:       common.DatabaseTypePostgres,
+		DefaultValueS: common.BuildInOracleColumnDefaultValueSysdate,
+		DefaultValueT: common.BuildInOracleO2PColumnDefaultValueMap[common.BuildInOracleColumnDefaultValueSysdate],
+	})
+
+	buildinColumDefaultvals = append(buildinColumDefaultvals, &BuildinGlobalDefaultval{
+		DBTypeS:       common.DatabaseTypeOracle,
+		DBTypeT:       common.DatabaseTypePostgres,
+		DefaultValueS: common.BuildInOracleColumnDefaultValueSYSGUID,
+		DefaultValueT: common.BuildInOracleO2PColumnDefaultValueMap[common.BuildInOracleColumnDefaultValueSYSGUID],
+	})
+	return rw.DB(ctx).Clauses(clause.Insert{Modifier: "IGNORE"}).Create(buildinColumDefaultvals).Error
+}
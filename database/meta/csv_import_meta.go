@@ -0,0 +1,134 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package meta
+
+import (
+	"context"
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"gorm.io/gorm"
+)
+
+// CSV 导入元数据表
+type CSVImportMeta struct {
+	ID           uint   `gorm:"primary_key;autoIncrement;comment:'自增编号'" json:"id"`
+	DBTypeS      string `gorm:"type:varchar(30);index:idx_dbtype_st_file,unique;comment:'源数据库类型'" json:"db_type_s"`
+	DBTypeT      string `gorm:"type:varchar(30);index:idx_dbtype_st_file,unique;comment:'目标数据库类型'" json:"db_type_t"`
+	SchemaNameS  string `gorm:"type:varchar(100);not null;comment:'源端 schema'" json:"schema_name_s"`
+	TableNameS   string `gorm:"type:varchar(100);not null;comment:'源端表名'" json:"table_name_s"`
+	SchemaNameT  string `gorm:"type:varchar(100);not null;index:idx_dbtype_st_file,unique;comment:'目标端 schema'" json:"schema_name_t"`
+	TableNameT   string `gorm:"type:varchar(100);not null;comment:'目标端表名'" json:"table_name_t"`
+	CSVFile      string `gorm:"type:varchar(300);not null;index:idx_dbtype_st_file,unique;comment:'csv 文件名（相对 output-dir）'" json:"csv_file"`
+	TaskMode     string `gorm:"type:varchar(30);not null;index:idx_dbtype_st_file,unique;comment:'任务模式'" json:"task_mode"`
+	TaskStatus   string `gorm:"type:varchar(30);not null;comment:'导入状态,only waiting,running,success,failed'" json:"task_status"`
+	RowsManifest int64  `gorm:"comment:'导出清单记录行数'" json:"rows_manifest"`
+	RowsImported int64  `gorm:"comment:'实际导入行数'" json:"rows_imported"`
+	InfoDetail   string `gorm:"type:text;comment:'信息详情'" json:"info_detail"`
+	ErrorDetail  string `gorm:"type:text;comment:'错误详情'" json:"error_detail"`
+	*BaseModel
+}
+
+func NewCSVImportMetaModel(m *Meta) *CSVImportMeta {
+	return &CSVImportMeta{
+		BaseModel: &BaseModel{
+			Meta: m,
+		},
+	}
+}
+
+func (rw *CSVImportMeta) ParseSchemaTable() (string, error) {
+	stmt := &gorm.Statement{DB: rw.GormDB}
+	err := stmt.Parse(rw)
+	if err != nil {
+		return "", fmt.Errorf("parse struct [CSVImportMeta] get table_name failed: %v", err)
+	}
+	return stmt.Schema.Table, nil
+}
+
+func (rw *CSVImportMeta) CreateCSVImportMeta(ctx context.Context, createS *CSVImportMeta) error {
+	table, err := rw.ParseSchemaTable()
+	if err != nil {
+		return err
+	}
+	if err = rw.DB(ctx).Create(createS).Error; err != nil {
+		return fmt.Errorf("create table [%s] record failed: %v", table, err)
+	}
+	return nil
+}
+
+func (rw *CSVImportMeta) DetailCSVImportMeta(ctx context.Context, detailS *CSVImportMeta) ([]CSVImportMeta, error) {
+	var dsMetas []CSVImportMeta
+	table, err := rw.ParseSchemaTable()
+	if err != nil {
+		return dsMetas, err
+	}
+	if err = rw.DB(ctx).Where(detailS).Find(&dsMetas).Error; err != nil {
+		return dsMetas, fmt.Errorf("detail table [%s] record failed: %v", table, err)
+	}
+	return dsMetas, nil
+}
+
+func (rw *CSVImportMeta) UpdateCSVImportMeta(ctx context.Context, updateS *CSVImportMeta, updates map[string]interface{}) error {
+	table, err := rw.ParseSchemaTable()
+	if err != nil {
+		return err
+	}
+	if err = rw.DB(ctx).Model(CSVImportMeta{}).
+		Where("db_type_s = ? AND db_type_t = ? AND schema_name_t = ? AND csv_file = ? AND task_mode = ?",
+			common.StringUPPER(updateS.DBTypeS),
+			common.StringUPPER(updateS.DBTypeT),
+			common.StringUPPER(updateS.SchemaNameT),
+			updateS.CSVFile,
+			common.StringUPPER(updateS.TaskMode)).
+		Updates(updates).Error; err != nil {
+		return fmt.Errorf("update table [%s] record failed: %v", table, err)
+	}
+	return nil
+}
+
+func (rw *CSVImportMeta) DeleteCSVImportMetaBySchema(ctx context.Context, deleteS *CSVImportMeta) error {
+	table, err := rw.ParseSchemaTable()
+	if err != nil {
+		return err
+	}
+	if err = rw.DB(ctx).Where("db_type_s = ? AND db_type_t = ? AND schema_name_t = ? AND task_mode = ?",
+		common.StringUPPER(deleteS.DBTypeS),
+		common.StringUPPER(deleteS.DBTypeT),
+		common.StringUPPER(deleteS.SchemaNameT),
+		common.StringUPPER(deleteS.TaskMode)).Delete(&CSVImportMeta{}).Error; err != nil {
+		return fmt.Errorf("delete table [%s] record failed: %v", table, err)
+	}
+	return nil
+}
+
+func (rw *CSVImportMeta) CountsCSVImportMetaByTaskStatus(ctx context.Context, countS *CSVImportMeta) (int64, error) {
+	var totals int64
+	table, err := rw.ParseSchemaTable()
+	if err != nil {
+		return totals, err
+	}
+	if err = rw.DB(ctx).Model(&CSVImportMeta{}).
+		Where("db_type_s = ? AND db_type_t = ? AND schema_name_t = ? AND task_mode = ? AND task_status = ?",
+			common.StringUPPER(countS.DBTypeS),
+			common.StringUPPER(countS.DBTypeT),
+			common.StringUPPER(countS.SchemaNameT),
+			common.StringUPPER(countS.TaskMode),
+			countS.TaskStatus).
+		Count(&totals).Error; err != nil {
+		return totals, fmt.Errorf("get table [%s] counts failed: %v", table, err)
+	}
+	return totals, nil
+}
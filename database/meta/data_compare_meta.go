@@ -17,29 +17,43 @@ package meta
 
 import (
 	"context"
+	"crypto/md5"
+	"encoding/hex"
 	"fmt"
 	"github.com/wentaojin/transferdb/common"
 	"gorm.io/gorm"
+	"time"
 )
 
 // 数据校验元数据表
 type DataCompareMeta struct {
-	ID            uint   `gorm:"primary_key;autoIncrement;comment:'自增编号'" json:"id"`
-	DBTypeS       string `gorm:"type:varchar(30);index:idx_dbtype_st_obj,unique;comment:'源数据库类型'" json:"db_type_s"`
-	DBTypeT       string `gorm:"type:varchar(30);index:idx_dbtype_st_obj,unique;comment:'目标数据库类型'" json:"db_type_t"`
-	SchemaNameS   string `gorm:"type:varchar(100);not null;index:idx_dbtype_st_obj,unique;comment:'源端 schema'" json:"schema_name_s"`
-	TableNameS    string `gorm:"type:varchar(100);not null;index:idx_dbtype_st_obj,unique;comment:'源端表名'" json:"table_name_s"`
-	ColumnDetailS string `gorm:"type:text;comment:'源端查询字段信息'" json:"column_detail_s"`
-	SchemaNameT   string `gorm:"type:varchar(100);not null;comment:'目标端 schema'" json:"schema_name_t"`
-	TableNameT    string `gorm:"type:varchar(100);not null;comment:'目标端表名'" json:"table_name_t"`
-	ColumnDetailT string `gorm:"type:text;comment:'目标端查询字段信息'" json:"column_detail_t"`
-	WhereColumn   string `gorm:"comment:'查询类型字段列'" json:"where_column"`
-	WhereRange    string `gorm:"type:varchar(300);not null;index:idx_dbtype_st_obj,unique;comment:'查询 where 条件'" json:"where_range"`
-	TaskMode      string `gorm:"type:varchar(30);not null;index:idx_dbtype_st_obj,unique;comment:'任务模式'" json:"task_mode"`
-	TaskStatus    string `gorm:"type:varchar(30);not null;comment:'数据对比状态,only waiting,success,failed'" json:"task_status"`
-	IsPartition   string `gorm:"comment:'是否是分区表'" json:"is_partition"` // 同步转换统一转换成非分区表，此处只做标志
-	InfoDetail    string `gorm:"type:text;not null;comment:'信息详情'" json:"info_detail"`
-	ErrorDetail   string `gorm:"type:text;not null;comment:'错误详情'" json:"error_detail"`
+	ID            uint       `gorm:"primary_key;autoIncrement;comment:'自增编号'" json:"id"`
+	DBTypeS       string     `gorm:"type:varchar(30);index:idx_dbtype_st_obj,unique;comment:'源数据库类型'" json:"db_type_s"`
+	DBTypeT       string     `gorm:"type:varchar(30);index:idx_dbtype_st_obj,unique;comment:'目标数据库类型'" json:"db_type_t"`
+	SchemaNameS   string     `gorm:"type:varchar(100);not null;index:idx_dbtype_st_obj,unique;comment:'源端 schema'" json:"schema_name_s"`
+	TableNameS    string     `gorm:"type:varchar(100);not null;index:idx_dbtype_st_obj,unique;comment:'源端表名'" json:"table_name_s"`
+	ColumnDetailS string     `gorm:"type:text;comment:'源端查询字段信息'" json:"column_detail_s"`
+	SchemaNameT   string     `gorm:"type:varchar(100);not null;comment:'目标端 schema'" json:"schema_name_t"`
+	TableNameT    string     `gorm:"type:varchar(100);not null;comment:'目标端表名'" json:"table_name_t"`
+	ColumnDetailT string     `gorm:"type:text;comment:'目标端查询字段信息'" json:"column_detail_t"`
+	WhereColumn   string     `gorm:"comment:'查询类型字段列'" json:"where_column"`
+	WhereRange    string     `gorm:"type:text;not null;comment:'查询 where 条件'" json:"where_range"`
+	WhereRangeMD5 string     `gorm:"type:varchar(32);not null;index:idx_dbtype_st_obj,unique;comment:'查询 where 条件 md5，where 条件长度不受索引限制'" json:"where_range_md5"`
+	WhereRangeT   string     `gorm:"type:text;comment:'目标端查询 where 条件，为空与源端一致'" json:"where_range_t"`
+	TaskMode      string     `gorm:"type:varchar(30);not null;index:idx_dbtype_st_obj,unique;comment:'任务模式'" json:"task_mode"`
+	TaskStatus    string     `gorm:"type:varchar(30);not null;comment:'数据对比状态,only waiting,success,failed'" json:"task_status"`
+	IsPartition   string     `gorm:"comment:'是否是分区表'" json:"is_partition"` // 同步转换统一转换成非分区表，此处只做标志
+	InfoDetail    string     `gorm:"type:text;not null;comment:'信息详情'" json:"info_detail"`
+	ErrorDetail   string     `gorm:"type:text;not null;comment:'错误详情'" json:"error_detail"`
+	RepairStatus  string     `gorm:"type:varchar(30);comment:'差异修复状态,only fixed,different,failed'" json:"repair_status"`
+	RepairDetail  string     `gorm:"type:text;comment:'差异修复详情'" json:"repair_detail"`
+	ChunkRowsS    int64      `gorm:"comment:'源端 chunk 数据行数'" json:"chunk_rows_s"`
+	ChunkRowsT    int64      `gorm:"comment:'目标端 chunk 数据行数'" json:"chunk_rows_t"`
+	SampleRows    int64      `gorm:"comment:'抽样校验数据行数'" json:"sample_rows"`
+	SampleDiff    int64      `gorm:"comment:'抽样校验差异行数'" json:"sample_diff"`
+	SampleSummary string     `gorm:"type:varchar(300);comment:'抽样校验置信度统计'" json:"sample_summary"`
+	RecheckStatus string     `gorm:"type:varchar(30);comment:'差异复检重置前 chunk 状态，复检完成清空'" json:"recheck_status"`
+	RecheckTime   *time.Time `gorm:"type:datetime(3);comment:'差异复检重置前 chunk 更新时间，复检时间窗口判断依据，复检完成清空'" json:"recheck_time"`
 	*BaseModel
 }
 
@@ -51,6 +65,38 @@ func NewDataCompareMetaModel(m *Meta) *DataCompareMeta {
 	}
 }
 
+// BeforeCreate where 条件 md5 作为 chunk 唯一索引字段
+func (rw *DataCompareMeta) BeforeCreate(db *gorm.DB) (err error) {
+	rw.WhereRangeMD5 = genWhereRangeMD5(rw.WhereRange)
+	return rw.BaseModel.BeforeCreate(db)
+}
+
+func genWhereRangeMD5(whereRange string) string {
+	sum := md5.Sum([]byte(whereRange))
+	return hex.EncodeToString(sum[:])
+}
+
+// migrateDataCompareMetaWhereRange 历史版本 where_range 字段 varchar(300) 属于唯一索引，字段调整 text 前删除唯一索引并回填 where_range_md5
+// 与 genWhereRangeMD5 一致，以 utf8mb4 编码计算 md5
+func migrateDataCompareMetaWhereRange(db *gorm.DB) error {
+	m := db.Migrator()
+	if !m.HasTable(&DataCompareMeta{}) || m.HasColumn(&DataCompareMeta{}, "WhereRangeMD5") {
+		return nil
+	}
+	if m.HasIndex(&DataCompareMeta{}, "idx_dbtype_st_obj") {
+		if err := m.DropIndex(&DataCompareMeta{}, "idx_dbtype_st_obj"); err != nil {
+			return fmt.Errorf("drop table [data_compare_meta] index [idx_dbtype_st_obj] failed: %v", err)
+		}
+	}
+	if err := m.AddColumn(&DataCompareMeta{}, "WhereRangeMD5"); err != nil {
+		return fmt.Errorf("add table [data_compare_meta] column [where_range_md5] failed: %v", err)
+	}
+	if err := db.Exec("UPDATE data_compare_meta SET where_range_md5 = MD5(where_range)").Error; err != nil {
+		return fmt.Errorf("update table [data_compare_meta] column [where_range_md5] failed: %v", err)
+	}
+	return nil
+}
+
 func (rw *DataCompareMeta) ParseSchemaTable() (string, error) {
 	stmt := &gorm.Statement{DB: rw.GormDB}
 	err := stmt.Parse(rw)
@@ -106,19 +152,35 @@ func (rw *DataCompareMeta) TruncateDataCompareMeta(ctx context.Context) error {
 	return nil
 }
 
+func (rw *DataCompareMeta) DeleteDataCompareMetaByTable(ctx context.Context, deleteS *DataCompareMeta) error {
+	table, err := rw.ParseSchemaTable()
+	if err != nil {
+		return err
+	}
+	if err = rw.DB(ctx).Where("db_type_s = ? AND db_type_t = ? AND schema_name_s = ? AND table_name_s = ? AND task_mode = ?",
+		common.StringUPPER(deleteS.DBTypeS),
+		common.StringUPPER(deleteS.DBTypeT),
+		common.StringUPPER(deleteS.SchemaNameS),
+		common.StringUPPER(deleteS.TableNameS),
+		common.StringUPPER(deleteS.TaskMode)).Delete(&DataCompareMeta{}).Error; err != nil {
+		return fmt.Errorf("delete table [%s] record failed: %v", table, err)
+	}
+	return nil
+}
+
 func (rw *DataCompareMeta) UpdateDataCompareMeta(ctx context.Context, deleteS *DataCompareMeta, updates map[string]interface{}) error {
 	table, err := rw.ParseSchemaTable()
 	if err != nil {
 		return err
 	}
 	if err = rw.DB(ctx).Model(DataCompareMeta{}).
-		Where("db_type_s = ? AND db_type_t = ? AND schema_name_s = ? AND table_name_s = ? AND task_mode = ? AND where_range = ?",
+		Where("db_type_s = ? AND db_type_t = ? AND schema_name_s = ? AND table_name_s = ? AND task_mode = ? AND where_range_md5 = ?",
 			common.StringUPPER(deleteS.DBTypeS),
 			common.StringUPPER(deleteS.DBTypeT),
 			common.StringUPPER(deleteS.SchemaNameS),
 			common.StringUPPER(deleteS.TableNameS),
 			common.StringUPPER(deleteS.TaskMode),
-			deleteS.WhereRange).
+			genWhereRangeMD5(deleteS.WhereRange)).
 		Updates(updates).Error; err != nil {
 		return fmt.Errorf("update table [%s] record failed: %v", table, err)
 	}
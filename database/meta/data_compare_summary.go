@@ -0,0 +1,106 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package meta
+
+import (
+	"context"
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"gorm.io/gorm"
+	"time"
+)
+
+// 数据校验表级汇总元数据表
+// 表数据校验完成清理 data_compare_meta chunk 记录前汇总写入，用于数据校验报告统计
+type DataCompareSummary struct {
+	ID             uint      `gorm:"primary_key;autoIncrement;comment:'自增编号'" json:"id"`
+	DBTypeS        string    `gorm:"type:varchar(30);index:idx_dbtype_st_obj,unique;comment:'源数据库类型'" json:"db_type_s"`
+	DBTypeT        string    `gorm:"type:varchar(30);index:idx_dbtype_st_obj,unique;comment:'目标数据库类型'" json:"db_type_t"`
+	SchemaNameS    string    `gorm:"type:varchar(100);not null;index:idx_dbtype_st_obj,unique;comment:'源端 schema'" json:"schema_name_s"`
+	TableNameS     string    `gorm:"type:varchar(100);not null;index:idx_dbtype_st_obj,unique;comment:'源端表名'" json:"table_name_s"`
+	SchemaNameT    string    `gorm:"type:varchar(100);not null;comment:'目标端 schema'" json:"schema_name_t"`
+	TableNameT     string    `gorm:"type:varchar(100);not null;comment:'目标端表名'" json:"table_name_t"`
+	TaskMode       string    `gorm:"type:varchar(30);not null;index:idx_dbtype_st_obj,unique;comment:'任务模式'" json:"task_mode"`
+	ChunkTotals    int64     `gorm:"comment:'chunk 总数'" json:"chunk_totals"`
+	ChunkEqual     int64     `gorm:"comment:'数据一致 chunk 数'" json:"chunk_equal"`
+	ChunkDifferent int64     `gorm:"comment:'数据不一致 chunk 数'" json:"chunk_different"`
+	ChunkFailed    int64     `gorm:"comment:'对比失败 chunk 数'" json:"chunk_failed"`
+	ChunkFixed     int64     `gorm:"comment:'差异修复 chunk 数'" json:"chunk_fixed"`
+	RowsS          int64     `gorm:"comment:'源端数据行数'" json:"rows_s"`
+	RowsT          int64     `gorm:"comment:'目标端数据行数'" json:"rows_t"`
+	SampleRows     int64     `gorm:"comment:'抽样校验数据行数'" json:"sample_rows"`
+	SampleDiff     int64     `gorm:"comment:'抽样校验差异行数'" json:"sample_diff"`
+	CompareBegin   time.Time `gorm:"type:datetime(3);comment:'chunk 最早创建时间'" json:"compare_begin"`
+	CompareEnd     time.Time `gorm:"type:datetime(3);comment:'chunk 最晚更新时间'" json:"compare_end"`
+	*BaseModel
+}
+
+func NewDataCompareSummaryModel(m *Meta) *DataCompareSummary {
+	return &DataCompareSummary{
+		BaseModel: &BaseModel{
+			Meta: m,
+		},
+	}
+}
+
+func (rw *DataCompareSummary) ParseSchemaTable() (string, error) {
+	stmt := &gorm.Statement{DB: rw.GormDB}
+	err := stmt.Parse(rw)
+	if err != nil {
+		return "", fmt.Errorf("parse struct [DataCompareSummary] get table_name failed: %v", err)
+	}
+	return stmt.Schema.Table, nil
+}
+
+func (rw *DataCompareSummary) DetailDataCompareSummary(ctx context.Context, detailS *DataCompareSummary) ([]DataCompareSummary, error) {
+	var dsMetas []DataCompareSummary
+	table, err := rw.ParseSchemaTable()
+	if err != nil {
+		return dsMetas, err
+	}
+	if err = rw.DB(ctx).Where(detailS).Find(&dsMetas).Error; err != nil {
+		return dsMetas, fmt.Errorf("detail table [%s] record failed: %v", table, err)
+	}
+	return dsMetas, nil
+}
+
+func (rw *DataCompareSummary) DeleteDataCompareSummaryByTable(ctx context.Context, deleteS *DataCompareSummary) error {
+	table, err := rw.ParseSchemaTable()
+	if err != nil {
+		return err
+	}
+	if err = rw.DB(ctx).Where("db_type_s = ? AND db_type_t = ? AND schema_name_s = ? AND table_name_s = ? AND task_mode = ?",
+		common.StringUPPER(deleteS.DBTypeS),
+		common.StringUPPER(deleteS.DBTypeT),
+		common.StringUPPER(deleteS.SchemaNameS),
+		common.StringUPPER(deleteS.TableNameS),
+		common.StringUPPER(deleteS.TaskMode)).Delete(&DataCompareSummary{}).Error; err != nil {
+		return fmt.Errorf("delete table [%s] record failed: %v", table, err)
+	}
+	return nil
+}
+
+func (rw *DataCompareSummary) TruncateDataCompareSummary(ctx context.Context) error {
+	table, err := rw.ParseSchemaTable()
+	if err != nil {
+		return err
+	}
+	err = rw.DB(ctx).Exec(fmt.Sprintf("TRUNCATE TABLE %s", table)).Error
+	if err != nil {
+		return fmt.Errorf("truncate table [%s] record failed: %v", table, err)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package meta
+
+import (
+	"context"
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"gorm.io/gorm"
+)
+
+// 函数索引下游 stored generated column 记录
+// 下游不支持表达式索引时 reverse 新增 generated column，上游不存在对应字段，check 等模式对比字段时忽略
+type IndexGeneratedColumn struct {
+	ID          uint   `gorm:"primary_key;autoIncrement;comment:'自增编号'" json:"id"`
+	DBTypeS     string `gorm:"type:varchar(30);index:idx_dbtype_st_column,unique;comment:'源数据库类型'" json:"db_type_s"`
+	DBTypeT     string `gorm:"type:varchar(30);index:idx_dbtype_st_column,unique;comment:'目标数据库类型'" json:"db_type_t"`
+	SchemaNameS string `gorm:"type:varchar(100);not null;index:idx_dbtype_st_column,unique;comment:'源端库 schema'" json:"schema_name_s"`
+	TableNameS  string `gorm:"type:varchar(200);not null;index:idx_dbtype_st_column,unique;comment:'源端表名'" json:"table_name_s"`
+	IndexNameS  string `gorm:"type:varchar(200);not null;comment:'源端函数索引名'" json:"index_name_s"`
+	SchemaNameT string `gorm:"type:varchar(100);not null;comment:'目标端库 schema'" json:"schema_name_t"`
+	TableNameT  string `gorm:"type:varchar(200);not null;comment:'目标端表名'" json:"table_name_t"`
+	ColumnNameT string `gorm:"type:varchar(200);not null;index:idx_dbtype_st_column,unique;comment:'目标端 generated column 字段名'" json:"column_name_t"`
+	ColumnExpr  string `gorm:"type:text;comment:'generated column 表达式'" json:"column_expr"`
+	*BaseModel
+}
+
+func NewIndexGeneratedColumnModel(m *Meta) *IndexGeneratedColumn {
+	return &IndexGeneratedColumn{BaseModel: &BaseModel{
+		Meta: m,
+	}}
+}
+
+func (rw *IndexGeneratedColumn) ParseSchemaTable() (string, error) {
+	stmt := &gorm.Statement{DB: rw.GormDB}
+	err := stmt.Parse(rw)
+	if err != nil {
+		return "", fmt.Errorf("parse struct [IndexGeneratedColumn] get table_name failed: %v", err)
+	}
+	return stmt.Schema.Table, nil
+}
+
+// ResetTableIndexGeneratedColumn 清理表历史记录并写入当前 reverse 生成的 generated column 记录
+func (rw *IndexGeneratedColumn) ResetTableIndexGeneratedColumn(ctx context.Context, deleteS *IndexGeneratedColumn, createS []IndexGeneratedColumn) error {
+	table, err := rw.ParseSchemaTable()
+	if err != nil {
+		return err
+	}
+	txn := rw.DB(ctx).Begin()
+	if err = txn.Where("db_type_s = ? AND db_type_t = ? AND schema_name_s = ? AND table_name_s = ?",
+		common.StringUPPER(deleteS.DBTypeS),
+		common.StringUPPER(deleteS.DBTypeT),
+		common.StringUPPER(deleteS.SchemaNameS),
+		common.StringUPPER(deleteS.TableNameS)).Delete(&IndexGeneratedColumn{}).Error; err != nil {
+		txn.Rollback()
+		return fmt.Errorf("delete table [%s] record failed: %v", table, err)
+	}
+	if len(createS) > 0 {
+		if err = txn.Create(createS).Error; err != nil {
+			txn.Rollback()
+			return fmt.Errorf("create table [%s] record failed: %v", table, err)
+		}
+	}
+	txn.Commit()
+	return nil
+}
+
+// DetailSchemaIndexGeneratedColumn 获取 schema 函数索引 generated column，KEY 源端表名，VALUE 目标端字段名集合
+func (rw *IndexGeneratedColumn) DetailSchemaIndexGeneratedColumn(ctx context.Context, detailS *IndexGeneratedColumn) (map[string]map[string]struct{}, error) {
+	generatedColumns := make(map[string]map[string]struct{})
+	table, err := rw.ParseSchemaTable()
+	if err != nil {
+		return generatedColumns, err
+	}
+
+	var records []IndexGeneratedColumn
+	tx := rw.DB(ctx).Where("db_type_s = ? AND db_type_t = ? AND schema_name_s = ?",
+		common.StringUPPER(detailS.DBTypeS),
+		common.StringUPPER(detailS.DBTypeT),
+		common.StringUPPER(detailS.SchemaNameS))
+	if !common.IsEmptyString(detailS.TableNameS) {
+		tx = tx.Where("table_name_s = ?", common.StringUPPER(detailS.TableNameS))
+	}
+	if err = tx.Find(&records).Error; err != nil {
+		return generatedColumns, fmt.Errorf("detail table [%s] record failed: %v", table, err)
+	}
+	for _, rc := range records {
+		tableName := common.StringUPPER(rc.TableNameS)
+		if _, ok := generatedColumns[tableName]; !ok {
+			generatedColumns[tableName] = make(map[string]struct{})
+		}
+		generatedColumns[tableName][common.StringUPPER(rc.ColumnNameT)] = struct{}{}
+	}
+	return generatedColumns, nil
+}
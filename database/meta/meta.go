@@ -98,11 +98,15 @@ func (m *Meta) DB(ctx context.Context) *gorm.DB {
 }
 
 func (m *Meta) MigrateTables() (err error) {
+	if err = migrateDataCompareMetaWhereRange(m.GormDB); err != nil {
+		return err
+	}
 	return m.migrateStream(
 		new(ColumnDatatypeRule),
 		new(TableDatatypeRule),
 		new(SchemaDatatypeRule),
 		new(DataCompareMeta),
+		new(DataCompareSummary),
 		new(WaitSyncMeta),
 		new(FullSyncMeta),
 		new(IncrSyncMeta),
@@ -112,6 +116,9 @@ func (m *Meta) MigrateTables() (err error) {
 		new(BuildinObjectCompatible),
 		new(BuildinDatatypeRule),
 		new(TableNameRule),
+		new(ColumnNameRule),
+		new(IndexGeneratedColumn),
+		new(CSVImportMeta),
 	)
 }
 
@@ -124,6 +131,10 @@ func (m *Meta) InitDefaultValue(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+	err = NewBuildinGlobalDefaultvalModel(m).InitO2PBuildinGlobalDefaultValue(ctx)
+	if err != nil {
+		return err
+	}
 	err = NewBuildinObjectCompatibleModel(m).InitO2MBuildinObjectCompatible(ctx)
 	if err != nil {
 		return err
@@ -140,6 +151,10 @@ func (m *Meta) InitDefaultValue(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+	err = NewBuildinDatatypeRuleModel(m).InitO2PBuildinDatatypeRule(ctx)
+	if err != nil {
+		return err
+	}
 	err = NewBuildinDatatypeRuleModel(m).InitM2OBuildinDatatypeRule(ctx)
 	if err != nil {
 		return err
@@ -0,0 +1,125 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package meta
+
+import (
+	"context"
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 上下游数据表字段名映射规则，用于规避目标端关键字以及字段名长度限制
+type ColumnNameRule struct {
+	ID          uint   `gorm:"primary_key;autoIncrement;comment:'自增编号'" json:"id"`
+	DBTypeS     string `gorm:"type:varchar(30);index:idx_dbtype_st_map,unique;index:idx_dbtype_st_column,unique;comment:'源数据库类型'" json:"db_type_s"`
+	DBTypeT     string `gorm:"type:varchar(30);index:idx_dbtype_st_map,unique;index:idx_dbtype_st_column,unique;comment:'目标数据库类型'" json:"db_type_t"`
+	SchemaNameS string `gorm:"type:varchar(100);not null;index:idx_dbtype_st_map,unique;index:idx_dbtype_st_column,unique;comment:'源端库 schema'" json:"schema_name_s"`
+	TableNameS  string `gorm:"type:varchar(200);not null;index:idx_dbtype_st_map,unique;index:idx_dbtype_st_column,unique;comment:'源端表名'" json:"table_name_s"`
+	ColumnNameS string `gorm:"type:varchar(200);not null;index:idx_dbtype_st_map,unique;comment:'源端表字段列名'" json:"column_name_s"`
+	ColumnNameT string `gorm:"type:varchar(200);not null;index:idx_dbtype_st_column,unique;comment:'目标表字段列名'" json:"column_name_t"`
+	*BaseModel
+}
+
+func NewColumnNameRuleModel(m *Meta) *ColumnNameRule {
+	return &ColumnNameRule{BaseModel: &BaseModel{
+		Meta: m,
+	}}
+}
+
+func (rw *ColumnNameRule) ParseSchemaTable() (string, error) {
+	stmt := &gorm.Statement{DB: rw.GormDB}
+	err := stmt.Parse(rw)
+	if err != nil {
+		return "", fmt.Errorf("parse struct [ColumnNameRule] get table_name failed: %v", err)
+	}
+	return stmt.Schema.Table, nil
+}
+
+// DetailColumnNameRule 获取 schema 字段名映射规则，TableNameS 为空获取 schema 全部表
+// BatchCreateColumnNameRule 批量写入字段名映射规则，已存在源端字段规则忽略，以自定义规则优先
+func (rw *ColumnNameRule) BatchCreateColumnNameRule(ctx context.Context, createS []ColumnNameRule) error {
+	table, err := rw.ParseSchemaTable()
+	if err != nil {
+		return err
+	}
+	if len(createS) == 0 {
+		return nil
+	}
+	if err = rw.DB(ctx).Clauses(clause.Insert{Modifier: "IGNORE"}).Create(createS).Error; err != nil {
+		return fmt.Errorf("batch create table [%s] record failed: %v", table, err)
+	}
+	return nil
+}
+
+func (rw *ColumnNameRule) DetailColumnNameRule(ctx context.Context, detailS *ColumnNameRule) ([]ColumnNameRule, error) {
+	var columnRuleMap []ColumnNameRule
+
+	table, err := rw.ParseSchemaTable()
+	if err != nil {
+		return nil, err
+	}
+
+	tx := rw.DB(ctx).Where("UPPER(db_type_s) = ? AND UPPER(db_type_t) = ? AND UPPER(schema_name_s) = ?",
+		common.StringUPPER(detailS.DBTypeS),
+		common.StringUPPER(detailS.DBTypeT),
+		common.StringUPPER(detailS.SchemaNameS))
+	if !common.IsEmptyString(detailS.TableNameS) {
+		tx = tx.Where("UPPER(table_name_s) = ?", common.StringUPPER(detailS.TableNameS))
+	}
+	if err = tx.Find(&columnRuleMap).Error; err != nil {
+		return columnRuleMap, fmt.Errorf("detail table [%s] record failed: %v", table, err)
+	}
+	return columnRuleMap, nil
+}
+
+// DetailTableColumnNameRule 获取单表字段名映射规则，KEY 源端字段名，VALUE 目标端字段名
+func (rw *ColumnNameRule) DetailTableColumnNameRule(ctx context.Context, detailS *ColumnNameRule) (map[string]string, error) {
+	columnNameRule := make(map[string]string)
+	if common.IsEmptyString(detailS.TableNameS) {
+		return columnNameRule, fmt.Errorf("detail column name rule table_name_s can't be null")
+	}
+	columnRules, err := rw.DetailColumnNameRule(ctx, detailS)
+	if err != nil {
+		return columnNameRule, err
+	}
+	for _, cr := range columnRules {
+		columnNameRule[common.StringUPPER(cr.ColumnNameS)] = common.StringUPPER(cr.ColumnNameT)
+	}
+	return columnNameRule, nil
+}
+
+// DetailSchemaColumnNameRule 获取 schema 字段名映射规则，KEY 源端表名，VALUE 源端字段名 -> 目标端字段名
+func (rw *ColumnNameRule) DetailSchemaColumnNameRule(ctx context.Context, detailS *ColumnNameRule) (map[string]map[string]string, error) {
+	tableColumnNameRule := make(map[string]map[string]string)
+	columnRules, err := rw.DetailColumnNameRule(ctx, &ColumnNameRule{
+		DBTypeS:     detailS.DBTypeS,
+		DBTypeT:     detailS.DBTypeT,
+		SchemaNameS: detailS.SchemaNameS,
+	})
+	if err != nil {
+		return tableColumnNameRule, err
+	}
+	for _, cr := range columnRules {
+		tableName := common.StringUPPER(cr.TableNameS)
+		if _, ok := tableColumnNameRule[tableName]; !ok {
+			tableColumnNameRule[tableName] = make(map[string]string)
+		}
+		tableColumnNameRule[tableName][common.StringUPPER(cr.ColumnNameS)] = common.StringUPPER(cr.ColumnNameT)
+	}
+	return tableColumnNameRule, nil
+}
@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"github.com/wentaojin/transferdb/common"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // 上下游数据表名字映射规则
@@ -28,7 +29,7 @@ type TableNameRule struct {
 	DBTypeS     string `gorm:"type:varchar(30);index:idx_dbtype_st_map,unique;index:idx_dbtype_st_table,unique;comment:'源数据库类型'" json:"db_type_s"`
 	DBTypeT     string `gorm:"type:varchar(30);index:idx_dbtype_st_map,unique;index:idx_dbtype_st_table,unique;comment:'目标数据库类型'" json:"db_type_t"`
 	SchemaNameS string `gorm:"type:varchar(100);not null;index:idx_dbtype_st_map,unique;index:idx_dbtype_st_table,unique;comment:'源端库 schema'" json:"schema_name_s"`
-	TableNameS  string `gorm:"type:varchar(100);not null;index:idx_dbtype_st_map,unique;index:idx_dbtype_st_table,unique;comment:'源端表名'" json:"table_name_s"`
+	TableNameS  string `gorm:"type:varchar(200);not null;index:idx_dbtype_st_map,unique;index:idx_dbtype_st_table,unique;comment:'源端表名'" json:"table_name_s"`
 	SchemaNameT string `gorm:"type:varchar(100);not null;index:idx_dbtype_st_map,unique;comment:'目标库 schema'" json:"schema_name_t"`
 	TableNameT  string `gorm:"type:varchar(100);not null;comment:'目标表名'" json:"table_name_t"`
 	*BaseModel
@@ -49,6 +50,21 @@ func (rw *TableNameRule) ParseSchemaTable() (string, error) {
 	return stmt.Schema.Table, nil
 }
 
+// BatchCreateTableNameRule 批量写入表名映射规则，已存在源端表规则忽略，以自定义规则优先
+func (rw *TableNameRule) BatchCreateTableNameRule(ctx context.Context, createS []TableNameRule) error {
+	table, err := rw.ParseSchemaTable()
+	if err != nil {
+		return err
+	}
+	if len(createS) == 0 {
+		return nil
+	}
+	if err = rw.DB(ctx).Clauses(clause.Insert{Modifier: "IGNORE"}).Create(createS).Error; err != nil {
+		return fmt.Errorf("batch create table [%s] record failed: %v", table, err)
+	}
+	return nil
+}
+
 func (rw *TableNameRule) DetailTableNameRule(ctx context.Context, detailS *TableNameRule) ([]TableNameRule, error) {
 	var tableRuleMap []TableNameRule
 
@@ -39,6 +39,7 @@ type FullSyncMeta struct {
 	TaskMode      string `gorm:"type:varchar(30);not null;index:idx_dbtype_st_map,unique;index:idx_schema_mode;comment:'任务模式'" json:"task_mode"`
 	TaskStatus    string `gorm:"type:varchar(30);not null;comment:'任务 chunk 状态'" json:"task_status"`
 	CSVFile       string `gorm:"type:varchar(300);comment:'csv 文件名'" json:"csv_file"`
+	ReplacedChars int64  `gorm:"comment:'csv 字符集转换替换或丢弃字符数'" json:"replaced_chars"`
 	IsPartition   string `gorm:"type:varchar(10);comment:'是否是分区表'" json:"is_partition"` // 同步转换统一转换成非分区表，此处只做标志
 	InfoDetail    string `gorm:"type:text;not null;comment:'信息详情'" json:"info_detail"`
 	ErrorDetail   string `gorm:"type:text;not null;comment:'错误详情'" json:"error_detail"`
@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"github.com/wentaojin/transferdb/common"
 	"gorm.io/gorm"
+	"time"
 )
 
 // 增量同步元数据表
@@ -34,6 +35,9 @@ type IncrSyncMeta struct {
 	GlobalScnS  uint64 `gorm:"comment:'源端全局 SCN'" json:"global_scn_s"`
 	TableScnS   uint64 `gorm:"comment:'源端表同步 SCN'" json:"table_scn_s"`
 	IsPartition string `gorm:"type:varchar(10);comment:'是否是分区表'" json:"is_partition"` // 同步转换统一转换成非分区表，此处只做标志
+	SyncFence   string `gorm:"type:varchar(10);comment:'快照一致性校验暂停应用标志'" json:"sync_fence"`
+	// 暂停应用标志租约到期时间，超过租约视为数据校验异常退出遗留标志
+	SyncFenceExpire *time.Time `gorm:"type:datetime(3);comment:'快照一致性校验暂停应用标志租约到期时间'" json:"sync_fence_expire"`
 	*BaseModel
 }
 
@@ -153,3 +157,30 @@ func (rw *IncrSyncMeta) UpdateIncrSyncMeta(ctx context.Context, detailS *IncrSyn
 	}
 	return nil
 }
+
+// UpdateIncrSyncMetaFenceBySchema 更新 schema 增量同步暂停应用标志，fenceFrom 为空不限制原标志
+// 设置 REQUEST 同时设置租约到期时间，清空标志同时清空租约，确认 PAUSED 保持原租约
+func (rw *IncrSyncMeta) UpdateIncrSyncMetaFenceBySchema(ctx context.Context, detailS *IncrSyncMeta, fenceFrom, fenceTo string) error {
+	table, err := rw.ParseSchemaTable()
+	if err != nil {
+		return err
+	}
+	query := rw.DB(ctx).Model(&IncrSyncMeta{}).Where("db_type_s = ? AND db_type_t = ? AND schema_name_s = ?",
+		common.StringUPPER(detailS.DBTypeS),
+		common.StringUPPER(detailS.DBTypeT),
+		common.StringUPPER(detailS.SchemaNameS))
+	if fenceFrom != "" {
+		query = query.Where("sync_fence = ?", fenceFrom)
+	}
+	values := map[string]interface{}{"sync_fence": fenceTo}
+	switch fenceTo {
+	case common.MigrateSyncFenceRequest:
+		values["sync_fence_expire"] = time.Now().Add(common.MigrateSyncFenceLease)
+	case "":
+		values["sync_fence_expire"] = nil
+	}
+	if err = query.Updates(values).Error; err != nil {
+		return fmt.Errorf("update table [%s] column [sync_fence] failed: %v", table, err)
+	}
+	return nil
+}
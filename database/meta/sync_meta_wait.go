@@ -36,6 +36,7 @@ type WaitSyncMeta struct {
 	ChunkSuccessNums int64  `gorm:"comment:'全量任务 full_sync_meta 执行成功 chunk 数'" json:"chunk_success_nums"`
 	ChunkFailedNums  int64  `gorm:"comment:'全量任务 full_sync_meta 执行失败 chunk 数'" json:"chunk_failed_nums"`
 	IsPartition      string `gorm:"type:varchar(10);comment:'是否是分区表'" json:"is_partition"` // 同步转换统一转换成非分区表，此处只做标志
+	ReplacedChars    int64  `gorm:"comment:'csv 任务字符集转换替换或丢弃字符数'" json:"replaced_chars"`
 	*BaseModel
 }
 
@@ -53,10 +53,28 @@ func (rw *Transaction) CreateErrorDetailAndUpdateWaitSyncMetaTaskStatus(ctx cont
 	return nil
 }
 
-func (rw *Transaction) DeleteTableDataCompareMetaAndUpdateWaitSyncMeta(ctx context.Context, deleteS *DataCompareMeta, updateS *WaitSyncMeta) error {
+// DeleteTableDataCompareMetaAndUpdateWaitSyncMeta 清理表数据校验记录，保留差异修复记录
+// summaryS 不为空时清理前写入表级汇总记录，用于数据校验报告统计已清理 chunk
+func (rw *Transaction) DeleteTableDataCompareMetaAndUpdateWaitSyncMeta(ctx context.Context, deleteS *DataCompareMeta, summaryS *DataCompareSummary, updateS *WaitSyncMeta) error {
 	txn := rw.DB(ctx).Begin()
+	if summaryS != nil {
+		if err := txn.Where("db_type_s = ? AND db_type_t = ? AND schema_name_s = ? AND table_name_s = ? AND task_mode = ?",
+			common.StringUPPER(summaryS.DBTypeS),
+			common.StringUPPER(summaryS.DBTypeT),
+			common.StringUPPER(summaryS.SchemaNameS),
+			common.StringUPPER(summaryS.TableNameS),
+			summaryS.TaskMode).
+			Delete(&DataCompareSummary{}).Error; err != nil {
+			txn.Rollback()
+			return fmt.Errorf("delete table [data_compare_summary] record failed: %v", err)
+		}
+		if err := txn.Create(summaryS).Error; err != nil {
+			txn.Rollback()
+			return fmt.Errorf("create table [data_compare_summary] record failed: %v", err)
+		}
+	}
 	if err := txn.Model(DataCompareMeta{}).
-		Where("db_type_s = ? AND db_type_t = ? AND schema_name_s = ? AND table_name_s = ? AND task_mode = ?",
+		Where("db_type_s = ? AND db_type_t = ? AND schema_name_s = ? AND table_name_s = ? AND task_mode = ? AND (repair_status IS NULL OR repair_status = '')",
 			common.StringUPPER(deleteS.DBTypeS),
 			common.StringUPPER(deleteS.DBTypeT),
 			common.StringUPPER(deleteS.SchemaNameS),
@@ -106,6 +124,7 @@ func (rw *Transaction) DeleteTableFullSyncMetaAndUpdateWaitSyncMeta(ctx context.
 			"TaskStatus":       updateS.TaskStatus,
 			"ChunkSuccessNums": updateS.ChunkSuccessNums,
 			"ChunkFailedNums":  updateS.ChunkFailedNums,
+			"ReplacedChars":    updateS.ReplacedChars,
 		}).Error; err != nil {
 		return fmt.Errorf("delete table [wait_sync_meta] record failed: %v", err)
 	}
@@ -73,6 +73,25 @@ func (m *MySQL) GetMySQLTableColumn(schemaName, tableName string) ([]map[string]
 	return res, nil
 }
 
+// GetMySQLTableGeneratedColumn 获取表 generated column 字段名（大写），表不存在 exist 返回 false
+func (m *MySQL) GetMySQLTableGeneratedColumn(schemaName, tableName string) (bool, map[string]struct{}, error) {
+	generatedColumns := make(map[string]struct{})
+	_, res, err := Query(m.Ctx, m.MySQLDB, fmt.Sprintf(`SELECT COLUMN_NAME,
+		IFNULL(EXTRA,'') EXTRA
+ FROM information_schema.COLUMNS
+ WHERE UPPER(TABLE_SCHEMA) = UPPER('%s')
+   AND UPPER(TABLE_NAME) = UPPER('%s')`, schemaName, tableName))
+	if err != nil {
+		return false, generatedColumns, err
+	}
+	for _, r := range res {
+		if strings.Contains(strings.ToUpper(r["EXTRA"]), "GENERATED") {
+			generatedColumns[strings.ToUpper(r["COLUMN_NAME"])] = struct{}{}
+		}
+	}
+	return len(res) > 0, generatedColumns, nil
+}
+
 func (m *MySQL) GetMySQLTableColumnComment(schemaName, tableName string) ([]map[string]string, error) {
 	var (
 		res []map[string]string
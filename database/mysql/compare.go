@@ -57,6 +57,52 @@ func (m *MySQL) GetMySQLTableActualRows(mysqlQuery string) (int64, error) {
 	return rowsCount, nil
 }
 
+func (m *MySQL) IsIntegerColumnTYPE(schemaName, tableName, columnName string) (bool, error) {
+	_, res, err := Query(m.Ctx, m.MySQLDB, fmt.Sprintf(`SELECT COLUMN_NAME,DATA_TYPE FROM INFORMATION_SCHEMA.COLUMNS WHERE UPPER(TABLE_SCHEMA) = UPPER('%s') AND UPPER(TABLE_NAME) = UPPER('%s') AND UPPER(COLUMN_NAME) = UPPER('%s')`,
+		schemaName, tableName, columnName))
+	if err != nil {
+		return false, err
+	}
+	if len(res) != 1 {
+		return false, fmt.Errorf("mysql table [%s.%s] column [%s] isn't exist or query result is multiple, please check again", schemaName, tableName, columnName)
+	}
+	switch strings.ToUpper(res[0]["DATA_TYPE"]) {
+	case "TINYINT", "SMALLINT", "MEDIUMINT", "INT", "INTEGER", "BIGINT":
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+func (m *MySQL) GetMySQLTableRowsByStatistics(schemaName, tableName string) (int, error) {
+	_, res, err := Query(m.Ctx, m.MySQLDB, fmt.Sprintf(`SELECT IFNULL(TABLE_ROWS,0) AS TABLE_ROWS FROM INFORMATION_SCHEMA.TABLES WHERE UPPER(TABLE_SCHEMA) = UPPER('%s') AND UPPER(TABLE_NAME) = UPPER('%s')`,
+		schemaName, tableName))
+	if err != nil {
+		return 0, err
+	}
+	if len(res) != 1 {
+		return 0, fmt.Errorf("get mysql schema table [%s.%s] rows by statistics falied, results: [%v]", schemaName, tableName, res)
+	}
+	numRows, err := strconv.Atoi(res[0]["TABLE_ROWS"])
+	if err != nil {
+		return 0, fmt.Errorf("get mysql schema table [%s.%s] rows [%s] by statistics strconv.Atoi falied: %v", schemaName, tableName, res[0]["TABLE_ROWS"], err)
+	}
+	return numRows, nil
+}
+
+// GetMySQLTableColumnBoundary 获取整型字段最小值以及最大值，表数据为空返回 NULLABLE
+func (m *MySQL) GetMySQLTableColumnBoundary(schemaName, tableName, columnName string) (string, string, error) {
+	querySQL := fmt.Sprintf("SELECT MIN(%s) AS MIN_ID, MAX(%s) AS MAX_ID FROM %s.%s", columnName, columnName, schemaName, tableName)
+	_, res, err := Query(m.Ctx, m.MySQLDB, querySQL)
+	if err != nil {
+		return "", "", err
+	}
+	if len(res) != 1 {
+		return "", "", fmt.Errorf("mysql sql [%v] results [%v] isn't one row", querySQL, res)
+	}
+	return res[0]["MIN_ID"], res[0]["MAX_ID"], nil
+}
+
 func (m *MySQL) GetMySQLDataRowStrings(querySQL string) ([]string, *strset.Set, uint32, error) {
 	var (
 		cols     []string
@@ -112,85 +158,9 @@ func (m *MySQL) GetMySQLDataRowStrings(querySQL string) ([]string, *strset.Set,
 			return cols, stringSet, crc32Value, fmt.Errorf("general sql [%v] query rows.Scan failed: [%v]", querySQL, err.Error())
 		}
 
-		for i, raw := range rawResult {
-			// ORACLE/MySQL 空字符串以及 NULL 统一NULL处理，忽略 MySQL 空字符串与 NULL 区别
-			if raw == nil {
-				rowsTMP = append(rowsTMP, fmt.Sprintf("%v", `NULL`))
-			} else if string(raw) == "" {
-				rowsTMP = append(rowsTMP, fmt.Sprintf("%v", `NULL`))
-			} else {
-				switch columnTypes[i] {
-				case "int8":
-					r, err := common.StrconvIntBitSize(string(raw), 8)
-					if err != nil {
-						return cols, stringSet, crc32Value, err
-					}
-					rowsTMP = append(rowsTMP, fmt.Sprintf("%v", r))
-				case "int16":
-					r, err := common.StrconvIntBitSize(string(raw), 16)
-					if err != nil {
-						return cols, stringSet, crc32Value, err
-					}
-					rowsTMP = append(rowsTMP, fmt.Sprintf("%v", r))
-				case "int32", "sql.NullInt32":
-					r, err := common.StrconvIntBitSize(string(raw), 32)
-					if err != nil {
-						return cols, stringSet, crc32Value, err
-					}
-					rowsTMP = append(rowsTMP, fmt.Sprintf("%v", r))
-				case "int64", "sql.NullInt64":
-					r, err := common.StrconvIntBitSize(string(raw), 64)
-					if err != nil {
-						return cols, stringSet, crc32Value, err
-					}
-					rowsTMP = append(rowsTMP, fmt.Sprintf("%v", r))
-				case "uint8":
-					r, err := common.StrconvUintBitSize(string(raw), 8)
-					if err != nil {
-						return cols, stringSet, crc32Value, err
-					}
-					rowsTMP = append(rowsTMP, fmt.Sprintf("%v", r))
-				case "uint16":
-					r, err := common.StrconvUintBitSize(string(raw), 16)
-					if err != nil {
-						return cols, stringSet, crc32Value, err
-					}
-					rowsTMP = append(rowsTMP, fmt.Sprintf("%v", r))
-				case "uint32":
-					r, err := common.StrconvUintBitSize(string(raw), 32)
-					if err != nil {
-						return cols, stringSet, crc32Value, err
-					}
-					rowsTMP = append(rowsTMP, fmt.Sprintf("%v", r))
-				case "uint64":
-					r, err := common.StrconvUintBitSize(string(raw), 64)
-					if err != nil {
-						return cols, stringSet, crc32Value, err
-					}
-					rowsTMP = append(rowsTMP, fmt.Sprintf("%v", r))
-				case "float32":
-					r, err := common.StrconvFloatBitSize(string(raw), 32)
-					if err != nil {
-						return cols, stringSet, crc32Value, err
-					}
-					rowsTMP = append(rowsTMP, fmt.Sprintf("%v", r))
-				case "float64", "sql.NullFloat64":
-					r, err := common.StrconvFloatBitSize(string(raw), 64)
-					if err != nil {
-						return cols, stringSet, crc32Value, err
-					}
-					rowsTMP = append(rowsTMP, fmt.Sprintf("%v", r))
-				case "rune":
-					r, err := common.StrconvRune(string(raw))
-					if err != nil {
-						return cols, stringSet, crc32Value, err
-					}
-					rowsTMP = append(rowsTMP, fmt.Sprintf("%v", r))
-				default:
-					// 特殊字符
-					rowsTMP = append(rowsTMP, fmt.Sprintf("'%v'", common.SpecialLettersUsingMySQL(raw)))
-				}
-			}
+		rowsTMP, err = FormatMySQLDataRowValues(columnTypes, rawResult)
+		if err != nil {
+			return cols, stringSet, crc32Value, err
 		}
 
 		rowS := exstrings.Join(rowsTMP, ",")
@@ -199,8 +169,6 @@ func (m *MySQL) GetMySQLDataRowStrings(querySQL string) ([]string, *strset.Set,
 		crc32SUM = atomic.AddUint32(&crc32Value, crc32.ChecksumIEEE([]byte(rowS)))
 		stringSet.Add(rowS)
 
-		// 数组清空
-		rowsTMP = rowsTMP[0:0]
 	}
 
 	if err = rows.Err(); err != nil {
@@ -209,3 +177,187 @@ func (m *MySQL) GetMySQLDataRowStrings(querySQL string) ([]string, *strset.Set,
 
 	return cols, stringSet, crc32SUM, err
 }
+
+// GetMySQLDataChunkChecksum 以 chunk 查询语句为子查询，数据库端计算 chunk 数据行数以及聚合 checksum
+// 单字段先计算 MD5，字段哈希按与 Oracle 端相同分组方式拼接后再计算行 MD5，chunk checksum 为行 checksum 前 8 位十六进制转数值求和
+// 字段值 NULL 以及空字符串统一空哈希，与 Oracle 空字符串即 NULL 保持一致，二进制字段按原始字节计算哈希与 Oracle RAW 保持一致
+func (m *MySQL) GetMySQLDataChunkChecksum(querySQL string) (int64, string, error) {
+	rows, err := m.MySQLDB.QueryContext(m.Ctx, common.StringsBuilder("SELECT * FROM (", querySQL, ") T WHERE 1 = 0"))
+	if err != nil {
+		return 0, "", fmt.Errorf("general sql [%v] query failed: [%v]", querySQL, err.Error())
+	}
+	colTypes, err := rows.ColumnTypes()
+	if err != nil {
+		rows.Close()
+		return 0, "", err
+	}
+	if err = rows.Close(); err != nil {
+		return 0, "", err
+	}
+
+	var hashCols []string
+	for _, ct := range colTypes {
+		// LENGTH 判断 NULL 以及空值，避免数值字段与空字符串隐式转换比较
+		colName := common.StringsBuilder("`", ct.Name(), "`")
+		hashCols = append(hashCols, common.StringsBuilder("IF(LENGTH(", colName, ") > 0,", mysqlChecksumHash(colName), ",'')"))
+	}
+	hashCols = common.FoldChecksumColumns(hashCols, mysqlChecksumConcat, mysqlChecksumHash)
+
+	checksumSQL := common.StringsBuilder(`SELECT COUNT(1) AS ROWS_COUNT, IFNULL(SUM(CAST(CONV(SUBSTRING(`,
+		mysqlChecksumHash(mysqlChecksumConcat(hashCols)), `,1,8),16,10) AS UNSIGNED)),0) AS CHECKSUM FROM (`, querySQL, `) T`)
+
+	_, res, err := Query(m.Ctx, m.MySQLDB, checksumSQL)
+	if err != nil {
+		return 0, "", err
+	}
+	if len(res) != 1 {
+		return 0, "", fmt.Errorf("mysql checksum sql [%v] results [%v] isn't one row", checksumSQL, res)
+	}
+	rowsCount, err := strconv.ParseInt(res[0]["ROWS_COUNT"], 10, 64)
+	if err != nil {
+		return rowsCount, "", fmt.Errorf("error on FUNC GetMySQLDataChunkChecksum failed: %v", err)
+	}
+	return rowsCount, res[0]["CHECKSUM"], nil
+}
+
+// mysqlChecksumHash 字段或者字段拼接表达式 MD5 十六进制，统一大写与 Oracle RAWTOHEX 保持一致
+func mysqlChecksumHash(expr string) string {
+	return common.StringsBuilder("UPPER(MD5(", expr, "))")
+}
+
+// mysqlChecksumConcat 字段哈希表达式以分隔符开头拼接
+func mysqlChecksumConcat(cols []string) string {
+	return common.StringsBuilder("CONCAT_WS('", common.CompareChecksumSeparator, "','',", strings.Join(cols, ","), ")")
+}
+
+// FormatMySQLDataRowValues 数据行字段值统一格式化，用于数据对比 CRC32 计算、差异对比以及修复 SQL 生成
+// 空字符串以及 NULL 统一 NULL 处理，数字按扫描类型格式化，字符特殊字符转义并以单引号包裹
+func FormatMySQLDataRowValues(columnTypes []string, rawResult [][]byte) ([]string, error) {
+	var rowsTMP []string
+	for i, raw := range rawResult {
+		// ORACLE/MySQL 空字符串以及 NULL 统一NULL处理，忽略 MySQL 空字符串与 NULL 区别
+		if raw == nil {
+			rowsTMP = append(rowsTMP, fmt.Sprintf("%v", `NULL`))
+		} else if string(raw) == "" {
+			rowsTMP = append(rowsTMP, fmt.Sprintf("%v", `NULL`))
+		} else {
+			switch columnTypes[i] {
+			case "int8":
+				r, err := common.StrconvIntBitSize(string(raw), 8)
+				if err != nil {
+					return rowsTMP, err
+				}
+				rowsTMP = append(rowsTMP, fmt.Sprintf("%v", r))
+			case "int16":
+				r, err := common.StrconvIntBitSize(string(raw), 16)
+				if err != nil {
+					return rowsTMP, err
+				}
+				rowsTMP = append(rowsTMP, fmt.Sprintf("%v", r))
+			case "int32", "sql.NullInt32":
+				r, err := common.StrconvIntBitSize(string(raw), 32)
+				if err != nil {
+					return rowsTMP, err
+				}
+				rowsTMP = append(rowsTMP, fmt.Sprintf("%v", r))
+			case "int64", "sql.NullInt64":
+				r, err := common.StrconvIntBitSize(string(raw), 64)
+				if err != nil {
+					return rowsTMP, err
+				}
+				rowsTMP = append(rowsTMP, fmt.Sprintf("%v", r))
+			case "uint8":
+				r, err := common.StrconvUintBitSize(string(raw), 8)
+				if err != nil {
+					return rowsTMP, err
+				}
+				rowsTMP = append(rowsTMP, fmt.Sprintf("%v", r))
+			case "uint16":
+				r, err := common.StrconvUintBitSize(string(raw), 16)
+				if err != nil {
+					return rowsTMP, err
+				}
+				rowsTMP = append(rowsTMP, fmt.Sprintf("%v", r))
+			case "uint32":
+				r, err := common.StrconvUintBitSize(string(raw), 32)
+				if err != nil {
+					return rowsTMP, err
+				}
+				rowsTMP = append(rowsTMP, fmt.Sprintf("%v", r))
+			case "uint64":
+				r, err := common.StrconvUintBitSize(string(raw), 64)
+				if err != nil {
+					return rowsTMP, err
+				}
+				rowsTMP = append(rowsTMP, fmt.Sprintf("%v", r))
+			case "float32":
+				r, err := common.StrconvFloatBitSize(string(raw), 32)
+				if err != nil {
+					return rowsTMP, err
+				}
+				rowsTMP = append(rowsTMP, fmt.Sprintf("%v", r))
+			case "float64", "sql.NullFloat64":
+				r, err := common.StrconvFloatBitSize(string(raw), 64)
+				if err != nil {
+					return rowsTMP, err
+				}
+				rowsTMP = append(rowsTMP, fmt.Sprintf("%v", r))
+			case "rune":
+				r, err := common.StrconvRune(string(raw))
+				if err != nil {
+					return rowsTMP, err
+				}
+				rowsTMP = append(rowsTMP, fmt.Sprintf("%v", r))
+			default:
+				// 特殊字符
+				rowsTMP = append(rowsTMP, fmt.Sprintf("'%v'", common.SpecialLettersUsingMySQL(raw)))
+			}
+		}
+	}
+	return rowsTMP, nil
+}
+
+// ApplyMySQLFixSQL 按批次事务执行差异修复 SQL，单批次失败回滚并返回
+func (m *MySQL) ApplyMySQLFixSQL(fixSQLs []string, batchSize int) error {
+	if batchSize <= 0 {
+		batchSize = len(fixSQLs)
+	}
+	for start := 0; start < len(fixSQLs); start += batchSize {
+		end := start + batchSize
+		if end > len(fixSQLs) {
+			end = len(fixSQLs)
+		}
+		txn, err := m.MySQLDB.BeginTx(m.Ctx, nil)
+		if err != nil {
+			return fmt.Errorf("mysql fix sql begin transaction failed: %v", err)
+		}
+		for _, s := range fixSQLs[start:end] {
+			if _, err = txn.ExecContext(m.Ctx, s); err != nil {
+				if rbErr := txn.Rollback(); rbErr != nil {
+					return fmt.Errorf("mysql fix sql [%v] exec failed: %v, rollback failed: %v", s, err, rbErr)
+				}
+				return fmt.Errorf("mysql fix sql [%v] exec failed: %v", s, err)
+			}
+		}
+		if err = txn.Commit(); err != nil {
+			return fmt.Errorf("mysql fix sql commit transaction failed: %v", err)
+		}
+	}
+	return nil
+}
+
+// GetTiDBCurrentTSO 获取 TiDB 当前 TSO，用于 tidb_snapshot 快照读
+func (m *MySQL) GetTiDBCurrentTSO() (uint64, error) {
+	_, res, err := Query(m.Ctx, m.MySQLDB, "SHOW MASTER STATUS")
+	if err != nil {
+		return 0, err
+	}
+	if len(res) != 1 {
+		return 0, fmt.Errorf("get tidb current tso failed, results: [%v]", res)
+	}
+	tso, err := common.StrconvUintBitSize(res[0]["Position"], 64)
+	if err != nil {
+		return 0, fmt.Errorf("get tidb current tso [%s] strconv failed: %v", res[0]["Position"], err)
+	}
+	return tso, nil
+}
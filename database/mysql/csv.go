@@ -0,0 +1,38 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package mysql
+
+import (
+	"fmt"
+	gomysql "github.com/go-sql-driver/mysql"
+)
+
+// LoadMySQLTableData 通过 LOAD DATA LOCAL INFILE 导入 csv 文件，返回导入行数
+// loadSQL 中 INFILE 文件需与 csvFile 一致，执行期间注册文件白名单
+func (m *MySQL) LoadMySQLTableData(csvFile, loadSQL string) (int64, error) {
+	gomysql.RegisterLocalFile(csvFile)
+	defer gomysql.DeregisterLocalFile(csvFile)
+
+	res, err := m.MySQLDB.ExecContext(m.Ctx, loadSQL)
+	if err != nil {
+		return 0, fmt.Errorf("load data sql [%v] exec failed: %v", loadSQL, err)
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("load data sql [%v] get rows affected failed: %v", loadSQL, err)
+	}
+	return rows, nil
+}
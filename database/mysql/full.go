@@ -18,6 +18,7 @@ package mysql
 import (
 	"fmt"
 	"go.uber.org/zap"
+	"strings"
 )
 
 func (m *MySQL) TruncateMySQLTable(targetSchema string, targetTable string) error {
@@ -39,3 +40,38 @@ func (m *MySQL) WriteMySQLTable(sql string) error {
 	}
 	return nil
 }
+
+func (m *MySQL) GetTiDBSchemaSequence(schemaName string) ([]string, error) {
+	_, res, err := Query(m.Ctx, m.MySQLDB, fmt.Sprintf(`SELECT UPPER(SEQUENCE_NAME) AS SEQUENCE_NAME FROM INFORMATION_SCHEMA.SEQUENCES WHERE UPPER(SEQUENCE_SCHEMA) = UPPER('%s')`, schemaName))
+	if err != nil {
+		return nil, err
+	}
+	var sequences []string
+	for _, r := range res {
+		sequences = append(sequences, r["SEQUENCE_NAME"])
+	}
+	return sequences, nil
+}
+
+// SetTiDBSequenceValue 推进 tidb 序列，setval 小于当前值时不生效
+func (m *MySQL) SetTiDBSequenceValue(schemaName, sequenceName, value string) error {
+	_, err := m.MySQLDB.ExecContext(m.Ctx, fmt.Sprintf("SELECT SETVAL(`%s`.`%s`, %s)", schemaName, sequenceName, value))
+	if err != nil {
+		return fmt.Errorf("setval tidb schema [%v] sequence [%v] value [%v] failed: %v", schemaName, sequenceName, value, err)
+	}
+	return nil
+}
+
+// RebaseMySQLTableAutoIncrement 推进表自增值，mysql/tidb 小于当前值时均不生效
+// tidb auto_random 字段通过 AUTO_RANDOM_BASE 推进
+func (m *MySQL) RebaseMySQLTableAutoIncrement(schemaName, tableName, value string, autoRandom bool) error {
+	option := "AUTO_INCREMENT"
+	if autoRandom {
+		option = "AUTO_RANDOM_BASE"
+	}
+	_, err := m.MySQLDB.ExecContext(m.Ctx, fmt.Sprintf("ALTER TABLE `%s`.`%s` %s = %s", schemaName, tableName, option, value))
+	if err != nil {
+		return fmt.Errorf("rebase mysql schema [%v] table [%v] %s [%v] failed: %v", schemaName, tableName, strings.ToLower(option), value, err)
+	}
+	return nil
+}
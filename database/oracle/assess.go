@@ -420,8 +420,8 @@ func (o *Oracle) GetOracleSchemaTableColumnNameLengthOver64(schemaName []string)
 }
 
 func (o *Oracle) GetOracleSchemaTableIndexNameLengthOver64(schemaName []string) ([]map[string]string, error) {
-	querySQL := fmt.Sprintf(`SELECT INDEX_OWNER,TABLE_NAME,INDEX_NAME,LENGTH(COLUMN_NAME) LENGTH_OVER
- FROM DBA_IND_COLUMNS WHERE TABLE_OWNER IN (%s) AND LENGTH(COLUMN_NAME) > 64 ORDER BY INDEX_OWNER,TABLE_NAME,INDEX_NAME`, strings.Join(schemaName, ","))
+	querySQL := fmt.Sprintf(`SELECT OWNER INDEX_OWNER,TABLE_NAME,INDEX_NAME,LENGTH(INDEX_NAME) LENGTH_OVER
+ FROM DBA_INDEXES WHERE TABLE_OWNER IN (%s) AND LENGTH(INDEX_NAME) > 64 ORDER BY OWNER,TABLE_NAME,INDEX_NAME`, strings.Join(schemaName, ","))
 
 	_, res, err := Query(o.Ctx, o.OracleDB, querySQL)
 	if err != nil {
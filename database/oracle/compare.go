@@ -193,67 +193,9 @@ func (o *Oracle) GetOracleDataRowStrings(querySQL string) ([]string, *strset.Set
 			return cols, stringSet, crc32Value, fmt.Errorf("general sql [%v] query rows.Scan failed: [%v]", querySQL, err.Error())
 		}
 
-		for i, raw := range rawResult {
-			// ORACLE/MySQL 空字符串以及 NULL 统一NULL处理，忽略 MySQL 空字符串与 NULL 区别
-			if raw == nil {
-				rowsTMP = append(rowsTMP, fmt.Sprintf("%v", `NULL`))
-			} else if string(raw) == "" {
-				rowsTMP = append(rowsTMP, fmt.Sprintf("%v", `NULL`))
-			} else {
-				switch columnTypes[i] {
-				case "int64":
-					r, err := common.StrconvIntBitSize(string(raw), 64)
-					if err != nil {
-						return cols, stringSet, crc32Value, err
-					}
-					rowsTMP = append(rowsTMP, fmt.Sprintf("%v", r))
-				case "uint64":
-					r, err := common.StrconvUintBitSize(string(raw), 64)
-					if err != nil {
-						return cols, stringSet, crc32Value, err
-					}
-					rowsTMP = append(rowsTMP, fmt.Sprintf("%v", r))
-				case "float32":
-					r, err := common.StrconvFloatBitSize(string(raw), 32)
-					if err != nil {
-						return cols, stringSet, crc32Value, err
-					}
-					rowsTMP = append(rowsTMP, fmt.Sprintf("%v", r))
-				case "float64":
-					r, err := common.StrconvFloatBitSize(string(raw), 64)
-					if err != nil {
-						return cols, stringSet, crc32Value, err
-					}
-					rowsTMP = append(rowsTMP, fmt.Sprintf("%v", r))
-				case "rune":
-					r, err := common.StrconvRune(string(raw))
-					if err != nil {
-						return cols, stringSet, crc32Value, err
-					}
-					rowsTMP = append(rowsTMP, fmt.Sprintf("%v", r))
-				case "godror.Number":
-					r, err := decimal.NewFromString(string(raw))
-					if err != nil {
-						return cols, stringSet, crc32Value, err
-					}
-					if r.IsInteger() {
-						si, err := common.StrconvIntBitSize(string(raw), 64)
-						if err != nil {
-							return cols, stringSet, crc32Value, err
-						}
-						rowsTMP = append(rowsTMP, fmt.Sprintf("%v", si))
-					} else {
-						rf, err := common.StrconvFloatBitSize(string(raw), 64)
-						if err != nil {
-							return cols, stringSet, crc32Value, err
-						}
-						rowsTMP = append(rowsTMP, fmt.Sprintf("%v", rf))
-					}
-				default:
-					// 特殊字符
-					rowsTMP = append(rowsTMP, fmt.Sprintf("'%v'", common.SpecialLettersUsingMySQL(raw)))
-				}
-			}
+		rowsTMP, err = FormatOracleDataRowValues(columnTypes, rawResult)
+		if err != nil {
+			return cols, stringSet, crc32Value, err
 		}
 
 		rowS := exstrings.Join(rowsTMP, ",")
@@ -262,8 +204,6 @@ func (o *Oracle) GetOracleDataRowStrings(querySQL string) ([]string, *strset.Set
 		crc32SUM = atomic.AddUint32(&crc32Value, crc32.ChecksumIEEE([]byte(rowS)))
 		stringSet.Add(rowS)
 
-		// 数组清空
-		rowsTMP = rowsTMP[0:0]
 	}
 
 	if err = rows.Err(); err != nil {
@@ -272,3 +212,137 @@ func (o *Oracle) GetOracleDataRowStrings(querySQL string) ([]string, *strset.Set
 
 	return cols, stringSet, crc32SUM, err
 }
+
+// GetOracleDataChunkChecksum 以 chunk 查询语句为子查询，数据库端计算 chunk 数据行数以及聚合 checksum
+// 子查询字段为数据行对比相同的规范化字段表达式（数值、时间格式化以及字段校验规则），checksum 与数据行对比口径一致
+// 单字段先计算 MD5，字段哈希拼接后再计算行 MD5，避免宽表字段值拼接超出 VARCHAR2 4000 字节上限
+// 行 checksum 取 MD5 前 8 位十六进制转数值，chunk checksum 为行 checksum 求和
+// 字段值 NULL 与空字符串统一空哈希，与数据行对比 NULL/空字符串统一处理保持一致，RAW 字段按原始字节计算哈希
+func (o *Oracle) GetOracleDataChunkChecksum(querySQL string) (int64, string, error) {
+	rows, err := o.OracleDB.QueryContext(o.Ctx, common.StringsBuilder("SELECT * FROM (", querySQL, ") WHERE 1 = 0"))
+	if err != nil {
+		return 0, "", fmt.Errorf("general sql [%v] query failed: [%v]", querySQL, err.Error())
+	}
+	colTypes, err := rows.ColumnTypes()
+	if err != nil {
+		rows.Close()
+		return 0, "", err
+	}
+	if err = rows.Close(); err != nil {
+		return 0, "", err
+	}
+
+	var hashCols []string
+	for _, ct := range colTypes {
+		colName := common.StringsBuilder(`"`, ct.Name(), `"`)
+		switch strings.ToUpper(ct.DatabaseTypeName()) {
+		case "CLOB", "NCLOB", "BLOB", "BFILE", "LONG", "LONG RAW":
+			return 0, "", fmt.Errorf("general sql [%v] column [%s] datatype [%s] isn't support checksum", querySQL, ct.Name(), ct.DatabaseTypeName())
+		case "RAW":
+			hashCols = append(hashCols, common.StringsBuilder(`NVL2(`, colName, `,`, oracleChecksumHash(colName), `,NULL)`))
+		default:
+			// TO_CHAR 统一转换数据库字符集，NVARCHAR2 等国家字符集字段按数据库字符集字节计算哈希
+			hashCols = append(hashCols, common.StringsBuilder(`NVL2(`, colName, `,`, oracleChecksumHash(common.StringsBuilder(`TO_CHAR(`, colName, `)`)), `,NULL)`))
+		}
+	}
+	hashCols = common.FoldChecksumColumns(hashCols, oracleChecksumConcat, oracleChecksumHash)
+
+	checksumSQL := common.StringsBuilder(`SELECT COUNT(1) AS ROWS_COUNT, NVL(SUM(TO_NUMBER(SUBSTR(`,
+		oracleChecksumHash(oracleChecksumConcat(hashCols)), `,1,8),'XXXXXXXX')),0) AS CHECKSUM FROM (`, querySQL, `)`)
+
+	_, res, err := Query(o.Ctx, o.OracleDB, checksumSQL)
+	if err != nil {
+		return 0, "", err
+	}
+	if len(res) != 1 {
+		return 0, "", fmt.Errorf("oracle checksum sql [%v] results [%v] isn't one row", checksumSQL, res)
+	}
+	rowsCount, err := strconv.ParseInt(res[0]["ROWS_COUNT"], 10, 64)
+	if err != nil {
+		return rowsCount, "", fmt.Errorf("error on FUNC GetOracleDataChunkChecksum failed: %v", err)
+	}
+	return rowsCount, res[0]["CHECKSUM"], nil
+}
+
+// oracleChecksumHash 字段或者字段拼接表达式 MD5 十六进制（大写）
+func oracleChecksumHash(expr string) string {
+	return common.StringsBuilder(`RAWTOHEX(STANDARD_HASH(`, expr, `, 'MD5'))`)
+}
+
+// oracleChecksumConcat 字段哈希表达式以分隔符开头拼接
+func oracleChecksumConcat(cols []string) string {
+	var concatCols []string
+	for _, c := range cols {
+		concatCols = append(concatCols, common.StringsBuilder(`'`, common.CompareChecksumSeparator, `' || `, c))
+	}
+	return strings.Join(concatCols, " || ")
+}
+
+// FormatOracleDataRowValues 数据行字段值统一格式化，用于数据对比 CRC32 计算、差异对比以及修复 SQL 生成
+// 空字符串以及 NULL 统一 NULL 处理，数字按扫描类型格式化，字符特殊字符转义并以单引号包裹
+func FormatOracleDataRowValues(columnTypes []string, rawResult [][]byte) ([]string, error) {
+	var rowsTMP []string
+	for i, raw := range rawResult {
+		// ORACLE/MySQL 空字符串以及 NULL 统一NULL处理，忽略 MySQL 空字符串与 NULL 区别
+		if raw == nil {
+			rowsTMP = append(rowsTMP, fmt.Sprintf("%v", `NULL`))
+		} else if string(raw) == "" {
+			rowsTMP = append(rowsTMP, fmt.Sprintf("%v", `NULL`))
+		} else {
+			switch columnTypes[i] {
+			case "int64":
+				r, err := common.StrconvIntBitSize(string(raw), 64)
+				if err != nil {
+					return rowsTMP, err
+				}
+				rowsTMP = append(rowsTMP, fmt.Sprintf("%v", r))
+			case "uint64":
+				r, err := common.StrconvUintBitSize(string(raw), 64)
+				if err != nil {
+					return rowsTMP, err
+				}
+				rowsTMP = append(rowsTMP, fmt.Sprintf("%v", r))
+			case "float32":
+				r, err := common.StrconvFloatBitSize(string(raw), 32)
+				if err != nil {
+					return rowsTMP, err
+				}
+				rowsTMP = append(rowsTMP, fmt.Sprintf("%v", r))
+			case "float64":
+				r, err := common.StrconvFloatBitSize(string(raw), 64)
+				if err != nil {
+					return rowsTMP, err
+				}
+				rowsTMP = append(rowsTMP, fmt.Sprintf("%v", r))
+			case "rune":
+				r, err := common.StrconvRune(string(raw))
+				if err != nil {
+					return rowsTMP, err
+				}
+				rowsTMP = append(rowsTMP, fmt.Sprintf("%v", r))
+			case "godror.Number":
+				r, err := decimal.NewFromString(string(raw))
+				if err != nil {
+					return rowsTMP, err
+				}
+				if r.IsInteger() {
+					si, err := common.StrconvIntBitSize(string(raw), 64)
+					if err != nil {
+						return rowsTMP, err
+					}
+					rowsTMP = append(rowsTMP, fmt.Sprintf("%v", si))
+				} else {
+					rf, err := common.StrconvFloatBitSize(string(raw), 64)
+					if err != nil {
+						return rowsTMP, err
+					}
+					rowsTMP = append(rowsTMP, fmt.Sprintf("%v", rf))
+				}
+			default:
+				// 特殊字符
+				rowsTMP = append(rowsTMP, fmt.Sprintf("'%v'", common.SpecialLettersUsingMySQL(raw)))
+			}
+		}
+	}
+	return rowsTMP, nil
+}
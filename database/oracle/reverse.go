@@ -313,7 +313,8 @@ func (o *Oracle) GetOracleSchemaTableNormalIndex(schemaName string, tableName st
 	temp.ITYP_OWNER,
 	temp.ITYP_NAME,
 	temp.PARAMETERS,
-	LISTAGG ( temp.COLUMN_NAME, ',' ) WITHIN GROUP ( ORDER BY temp.COLUMN_POSITION ) AS COLUMN_LIST 
+	LISTAGG ( temp.COLUMN_NAME, ',' ) WITHIN GROUP ( ORDER BY temp.COLUMN_POSITION ) AS COLUMN_LIST,
+	LISTAGG ( temp.DESCEND, ',' ) WITHIN GROUP ( ORDER BY temp.COLUMN_POSITION ) AS DESCEND_LIST 
 FROM
 	(
 SELECT
@@ -358,7 +359,8 @@ WHERE
 xs.INDEX_OWNER = T.INDEX_OWNER
 	AND xs.INDEX_NAME = T.INDEX_NAME
 AND xs.COLUMN_POSITION = T.COLUMN_POSITION)) COLUMN_NAME,
-		T.COLUMN_POSITION
+		T.COLUMN_POSITION,
+		T.DESCEND
 	FROM
 		DBA_IND_COLUMNS T,
 		DBA_INDEXES I 
@@ -403,7 +405,8 @@ func (o *Oracle) GetOracleSchemaTableUniqueIndex(schemaName string, tableName st
 	temp.ITYP_OWNER,
 	temp.ITYP_NAME,
 	temp.PARAMETERS,
-	LISTAGG ( temp.COLUMN_NAME, ',' ) WITHIN GROUP ( ORDER BY temp.COLUMN_POSITION ) AS COLUMN_LIST 
+	LISTAGG ( temp.COLUMN_NAME, ',' ) WITHIN GROUP ( ORDER BY temp.COLUMN_POSITION ) AS COLUMN_LIST,
+	LISTAGG ( temp.DESCEND, ',' ) WITHIN GROUP ( ORDER BY temp.COLUMN_POSITION ) AS DESCEND_LIST 
 FROM
 	(
 SELECT
@@ -448,7 +451,8 @@ WHERE
 xs.INDEX_OWNER = T.INDEX_OWNER
 	AND xs.INDEX_NAME = T.INDEX_NAME
 AND xs.COLUMN_POSITION = T.COLUMN_POSITION)) COLUMN_NAME,
-		T.COLUMN_POSITION
+		T.COLUMN_POSITION,
+		T.DESCEND
 FROM
 	DBA_INDEXES I,
 	DBA_IND_COLUMNS T 
@@ -543,14 +547,18 @@ func (o *Oracle) GetOracleSchemaTableColumn(schemaName string, tableName string,
 		t.NULLABLE,
 	    t.DATA_DEFAULT,
 		DECODE(t.COLLATION,'USING_NLS_COMP',(SELECT VALUE from NLS_DATABASE_PARAMETERS WHERE PARAMETER = 'NLS_COMP'),t.COLLATION) COLLATION,
+	    t.VIRTUAL_COLUMN,
+	    t.HIDDEN_COLUMN,
 	    c.COMMENTS
-	from dba_tab_columns t, dba_col_comments c
+	from dba_tab_cols t, dba_col_comments c
 	where t.table_name = c.table_name
 	and t.column_name = c.column_name
 	and t.owner = c.owner
 	and upper(t.owner) = upper('%s')
 	and upper(t.table_name) = upper('%s')
-	order by t.COLUMN_ID`,
+	-- 排除系统生成隐藏列（函数索引、扩展统计信息等），保留用户不可见列
+	and (t.hidden_column = 'NO' or t.user_generated = 'YES')
+	order by t.COLUMN_ID, t.INTERNAL_COLUMN_ID`,
 			strings.ToUpper(schemaName),
 			strings.ToUpper(tableName))
 	} else {
@@ -563,14 +571,18 @@ func (o *Oracle) GetOracleSchemaTableColumn(schemaName string, tableName string,
 	    DECODE(NVL(TO_CHAR(t.DATA_SCALE),'*'),'*','127',TO_CHAR(t.DATA_SCALE)) AS DATA_SCALE,
 		t.NULLABLE,
 	    t.DATA_DEFAULT,
+	    t.VIRTUAL_COLUMN,
+	    t.HIDDEN_COLUMN,
 	    c.COMMENTS
-	from dba_tab_columns t, dba_col_comments c
+	from dba_tab_cols t, dba_col_comments c
 	where t.table_name = c.table_name
 	and t.column_name = c.column_name
 	and t.owner = c.owner
 	and upper(t.owner) = upper('%s')
 	and upper(t.table_name) = upper('%s')
-	order by t.COLUMN_ID`,
+	-- 排除系统生成隐藏列（函数索引、扩展统计信息等），保留用户不可见列
+	and (t.hidden_column = 'NO' or t.user_generated = 'YES')
+	order by t.COLUMN_ID, t.INTERNAL_COLUMN_ID`,
 			strings.ToUpper(schemaName),
 			strings.ToUpper(tableName))
 	}
@@ -666,3 +678,206 @@ func (o *Oracle) WriteOracleTable(sql string) error {
 	}
 	return nil
 }
+
+func (o *Oracle) GetOracleSchemaTablePartitionKey(schemaName, tableName string) ([]map[string]string, error) {
+	_, res, err := Query(o.Ctx, o.OracleDB, fmt.Sprintf(`SELECT pt.PARTITIONING_TYPE,
+       pt.SUBPARTITIONING_TYPE,
+       pt.DEF_SUBPARTITION_COUNT,
+       NVL(pt.INTERVAL, 'NONE') AS PARTITION_INTERVAL,
+       (SELECT LISTAGG(pkc.COLUMN_NAME, ',') WITHIN GROUP (ORDER BY pkc.COLUMN_POSITION)
+          FROM DBA_PART_KEY_COLUMNS pkc
+         WHERE pkc.OWNER = pt.OWNER
+           AND pkc.NAME = pt.TABLE_NAME
+           AND pkc.OBJECT_TYPE = 'TABLE') AS PARTITION_EXPRESS,
+       NVL((SELECT LISTAGG(skc.COLUMN_NAME, ',') WITHIN GROUP (ORDER BY skc.COLUMN_POSITION)
+          FROM DBA_SUBPART_KEY_COLUMNS skc
+         WHERE skc.OWNER = pt.OWNER
+           AND skc.NAME = pt.TABLE_NAME
+           AND skc.OBJECT_TYPE = 'TABLE'), 'NONE') AS SUBPARTITION_EXPRESS
+  FROM DBA_PART_TABLES pt
+ WHERE UPPER(pt.OWNER) = UPPER('%s')
+   AND UPPER(pt.TABLE_NAME) = UPPER('%s')`, schemaName, tableName))
+	if err != nil {
+		return res, err
+	}
+	return res, nil
+}
+
+func (o *Oracle) GetOracleSchemaTablePartitionDetail(schemaName, tableName string) ([]map[string]string, error) {
+	// HIGH_VALUE 为 LONG 类型，INTERVAL 分区已创建分区同样存在于 DBA_TAB_PARTITIONS
+	_, res, err := Query(o.Ctx, o.OracleDB, fmt.Sprintf(`SELECT PARTITION_NAME,
+       PARTITION_POSITION,
+       HIGH_VALUE,
+       SUBPARTITION_COUNT,
+       INTERVAL
+  FROM DBA_TAB_PARTITIONS
+ WHERE UPPER(TABLE_OWNER) = UPPER('%s')
+   AND UPPER(TABLE_NAME) = UPPER('%s')
+ ORDER BY PARTITION_POSITION`, schemaName, tableName))
+	if err != nil {
+		return res, err
+	}
+	return res, nil
+}
+
+func (o *Oracle) GetOracleSchemaSequence(schemaName string) ([]map[string]string, error) {
+	// 排除 identity column 系统序列 ISEQ$$_
+	_, res, err := Query(o.Ctx, o.OracleDB, fmt.Sprintf(`SELECT SEQUENCE_NAME,
+       TO_CHAR(MIN_VALUE) AS MIN_VALUE,
+       TO_CHAR(MAX_VALUE) AS MAX_VALUE,
+       TO_CHAR(INCREMENT_BY) AS INCREMENT_BY,
+       CYCLE_FLAG,
+       ORDER_FLAG,
+       TO_CHAR(CACHE_SIZE) AS CACHE_SIZE,
+       TO_CHAR(LAST_NUMBER) AS LAST_NUMBER
+  FROM DBA_SEQUENCES
+ WHERE UPPER(SEQUENCE_OWNER) = UPPER('%s')
+   AND SEQUENCE_NAME NOT LIKE 'ISEQ$$%%'
+ ORDER BY SEQUENCE_NAME`, schemaName))
+	if err != nil {
+		return res, err
+	}
+	return res, nil
+}
+
+func (o *Oracle) GetOracleSchemaIdentityColumn(schemaName string) ([]map[string]string, error) {
+	// oracle 12c 及以上 identity column，LAST_NUMBER 为对应系统序列下一个待分配值
+	_, res, err := Query(o.Ctx, o.OracleDB, fmt.Sprintf(`SELECT ic.TABLE_NAME,
+       ic.COLUMN_NAME,
+       ic.GENERATION_TYPE,
+       ic.SEQUENCE_NAME,
+       TO_CHAR(s.INCREMENT_BY) AS INCREMENT_BY,
+       TO_CHAR(s.LAST_NUMBER) AS LAST_NUMBER
+  FROM DBA_TAB_IDENTITY_COLS ic,
+       DBA_SEQUENCES s
+ WHERE ic.OWNER = s.SEQUENCE_OWNER
+   AND ic.SEQUENCE_NAME = s.SEQUENCE_NAME
+   AND UPPER(ic.OWNER) = UPPER('%s')`, schemaName))
+	if err != nil {
+		return res, err
+	}
+	return res, nil
+}
+
+func (o *Oracle) GetOracleSchemaTableIdentityColumn(schemaName, tableName string) ([]map[string]string, error) {
+	_, res, err := Query(o.Ctx, o.OracleDB, fmt.Sprintf(`SELECT COLUMN_NAME,
+       GENERATION_TYPE,
+       SEQUENCE_NAME
+  FROM DBA_TAB_IDENTITY_COLS
+ WHERE UPPER(OWNER) = UPPER('%s')
+   AND UPPER(TABLE_NAME) = UPPER('%s')`, schemaName, tableName))
+	if err != nil {
+		return res, err
+	}
+	return res, nil
+}
+
+func (o *Oracle) GetOracleSchemaView(schemaName string) ([]map[string]string, error) {
+	// TEXT 为 LONG 类型
+	_, res, err := Query(o.Ctx, o.OracleDB, fmt.Sprintf(`SELECT VIEW_NAME,
+       TEXT
+  FROM DBA_VIEWS
+ WHERE UPPER(OWNER) = UPPER('%s')
+ ORDER BY VIEW_NAME`, schemaName))
+	if err != nil {
+		return res, err
+	}
+	return res, nil
+}
+
+func (o *Oracle) GetOracleSchemaViewColumn(schemaName string) ([]map[string]string, error) {
+	_, res, err := Query(o.Ctx, o.OracleDB, fmt.Sprintf(`SELECT c.TABLE_NAME,
+       c.COLUMN_NAME
+  FROM DBA_TAB_COLUMNS c,
+       DBA_VIEWS v
+ WHERE c.OWNER = v.OWNER
+   AND c.TABLE_NAME = v.VIEW_NAME
+   AND UPPER(v.OWNER) = UPPER('%s')
+ ORDER BY c.TABLE_NAME, c.COLUMN_ID`, schemaName))
+	if err != nil {
+		return res, err
+	}
+	return res, nil
+}
+
+func (o *Oracle) GetOracleSchemaViewDependency(schemaName string) ([]map[string]string, error) {
+	// 同 schema 视图之间依赖关系，NAME 依赖 REFERENCED_NAME
+	_, res, err := Query(o.Ctx, o.OracleDB, fmt.Sprintf(`SELECT NAME,
+       REFERENCED_NAME
+  FROM DBA_DEPENDENCIES
+ WHERE UPPER(OWNER) = UPPER('%s')
+   AND TYPE = 'VIEW'
+   AND REFERENCED_OWNER = OWNER
+   AND REFERENCED_TYPE = 'VIEW'`, schemaName))
+	if err != nil {
+		return res, err
+	}
+	return res, nil
+}
+
+func (o *Oracle) GetOracleSchemaForeignKeyRelation(schemaName string) ([]map[string]string, error) {
+	// 同 schema 外键父子表关系，TABLE_NAME 子表依赖 R_TABLE_NAME 父表
+	_, res, err := Query(o.Ctx, o.OracleDB, fmt.Sprintf(`SELECT c.TABLE_NAME,
+       r.TABLE_NAME R_TABLE_NAME,
+       c.CONSTRAINT_NAME
+  FROM DBA_CONSTRAINTS c,
+       DBA_CONSTRAINTS r
+ WHERE c.R_OWNER = r.OWNER
+   AND c.R_CONSTRAINT_NAME = r.CONSTRAINT_NAME
+   AND c.OWNER = r.OWNER
+   AND c.CONSTRAINT_TYPE = 'R'
+   AND c.STATUS = 'ENABLED'
+   AND UPPER(c.OWNER) = UPPER('%s')`, schemaName))
+	if err != nil {
+		return res, err
+	}
+	return res, nil
+}
+
+func (o *Oracle) GetOracleSchemaTableColumnName(schemaName string) ([]map[string]string, error) {
+	// 仅表字段，不包含视图字段
+	_, res, err := Query(o.Ctx, o.OracleDB, fmt.Sprintf(`SELECT c.TABLE_NAME,
+       c.COLUMN_NAME
+  FROM DBA_TAB_COLUMNS c,
+       DBA_TABLES t
+ WHERE c.OWNER = t.OWNER
+   AND c.TABLE_NAME = t.TABLE_NAME
+   AND UPPER(t.OWNER) = UPPER('%s')
+ ORDER BY c.TABLE_NAME, c.COLUMN_ID`, schemaName))
+	if err != nil {
+		return res, err
+	}
+	return res, nil
+}
+
+func (o *Oracle) GetOracleSchemaTableIndexName(schemaName string) ([]map[string]string, error) {
+	// 索引以及唯一约束名称，唯一约束与其索引同名 UNION 去重
+	_, res, err := Query(o.Ctx, o.OracleDB, fmt.Sprintf(`SELECT TABLE_NAME,
+       INDEX_NAME
+  FROM DBA_INDEXES
+ WHERE UPPER(TABLE_OWNER) = UPPER('%s')
+   AND INDEX_TYPE <> 'LOB'
+UNION
+SELECT TABLE_NAME,
+       CONSTRAINT_NAME AS INDEX_NAME
+  FROM DBA_CONSTRAINTS
+ WHERE UPPER(OWNER) = UPPER('%s')
+   AND CONSTRAINT_TYPE = 'U'`, schemaName, schemaName))
+	if err != nil {
+		return res, err
+	}
+	return res, nil
+}
+
+func (o *Oracle) GetOracleSchemaTablePartitionName(schemaName string) ([]map[string]string, error) {
+	// 一级分区名称，子分区 mysql 按 HASH 子分区数生成无需名称
+	_, res, err := Query(o.Ctx, o.OracleDB, fmt.Sprintf(`SELECT TABLE_NAME,
+       PARTITION_NAME
+  FROM DBA_TAB_PARTITIONS
+ WHERE UPPER(TABLE_OWNER) = UPPER('%s')
+ ORDER BY TABLE_NAME, PARTITION_POSITION`, schemaName))
+	if err != nil {
+		return res, err
+	}
+	return res, nil
+}
@@ -19,6 +19,10 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	_ "github.com/lib/pq"
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/config"
+	"net/url"
 	"strings"
 )
 
@@ -27,21 +31,29 @@ type Postgres struct {
 	PGDB *sql.DB
 }
 
-func NewPostgresEngine(ctx context.Context, dbUser, dbPassword, ipAddr, dbPort, dbName string) (*Postgres, error) {
-	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
-		ipAddr, dbPort, dbUser, dbPassword, dbName)
-	db, err := sql.Open("ora", dsn) // this does not really open a new connection
+func NewPostgresDBEngine(ctx context.Context, pgCfg config.PostgresConfig) (*Postgres, error) {
+	dsn := &url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(pgCfg.Username, pgCfg.Password),
+		Host:     fmt.Sprintf("%s:%d", pgCfg.Host, pgCfg.Port),
+		Path:     pgCfg.DBName,
+		RawQuery: pgCfg.ConnectParams,
+	}
+	pgDB, err := sql.Open("postgres", dsn.String())
 	if err != nil {
-		return nil, fmt.Errorf("error on initializing database connection: %s", err.Error())
+		return nil, fmt.Errorf("error on open postgres database connection [%v]: %v", pgCfg.DBName, err)
 	}
-	db.SetMaxIdleConns(100)
 
-	err = db.Ping() // This DOES open a connection if necessary. This makes sure the database is accessible
-	if err != nil {
-		return nil, fmt.Errorf("error on opening database connection: %s", err.Error())
+	pgDB.SetMaxIdleConns(common.MySQLMaxIdleConn)
+	pgDB.SetMaxOpenConns(common.MySQLMaxConn)
+	pgDB.SetConnMaxLifetime(common.MySQLConnMaxLifeTime)
+	pgDB.SetConnMaxIdleTime(common.MySQLConnMaxIdleTime)
+
+	if err = pgDB.Ping(); err != nil {
+		return nil, fmt.Errorf("error on ping postgres database connection [%v]: %v", pgCfg.DBName, err)
 	}
 
-	return &Postgres{Ctx: ctx, PGDB: db}, nil
+	return &Postgres{Ctx: ctx, PGDB: pgDB}, nil
 }
 
 func Query(ctx context.Context, db *sql.DB, querySQL string) ([]string, []map[string]string, error) {
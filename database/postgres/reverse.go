@@ -0,0 +1,41 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package postgres
+
+import (
+	"fmt"
+	"strings"
+)
+
+// GetPostgresDBVersion 返回数据库版本号，例如: 15.4 (Debian 15.4-1.pgdg120+1) -> 15.4
+func (p *Postgres) GetPostgresDBVersion() (string, error) {
+	_, res, err := Query(p.Ctx, p.PGDB, `SHOW server_version`)
+	if err != nil {
+		return "", err
+	}
+	if len(res) == 0 {
+		return "", fmt.Errorf("postgres db version isn't exist")
+	}
+	return strings.Fields(res[0]["server_version"])[0], nil
+}
+
+func (p *Postgres) WritePostgresTable(sql string) error {
+	_, err := p.PGDB.ExecContext(p.Ctx, sql)
+	if err != nil {
+		return fmt.Errorf("source schema table sql [%v] write failed: %v", sql, err)
+	}
+	return nil
+}
@@ -92,6 +92,16 @@ func (r *Check) Check() error {
 		}
 	}
 
+	// 获取字段名自定义规则
+	columnNameRuleMap, err := meta.NewColumnNameRuleModel(r.metaDB).DetailSchemaColumnNameRule(r.ctx, &meta.ColumnNameRule{
+		DBTypeS:     r.cfg.DBTypeS,
+		DBTypeT:     r.cfg.DBTypeT,
+		SchemaNameS: r.cfg.OracleConfig.SchemaName,
+	})
+	if err != nil {
+		return err
+	}
+
 	// 判断下游数据库是否存在 oracle 表
 	mysqlTables, err := r.mysql.GetMySQLTable(r.cfg.MySQLConfig.SchemaName)
 	if err != nil {
@@ -269,10 +279,20 @@ func (r *Check) Check() error {
 			zap.String("cost", finishTime.Sub(beginTime).String()))
 	}
 
+	// 获取 reverse 函数索引 generated column 记录，上游不存在对应字段，对比忽略
+	indexGeneratedColumnMap, err := meta.NewIndexGeneratedColumnModel(r.metaDB).DetailSchemaIndexGeneratedColumn(r.ctx, &meta.IndexGeneratedColumn{
+		DBTypeS:     r.cfg.DBTypeS,
+		DBTypeT:     r.cfg.DBTypeT,
+		SchemaNameS: r.cfg.OracleConfig.SchemaName,
+	})
+	if err != nil {
+		return err
+	}
+
 	// 任务检查表
 	tasks := GenCheckTaskTable(r.cfg.OracleConfig.SchemaName, r.cfg.MySQLConfig.SchemaName, oracleDBCharacterSet,
 		nlsSort, nlsComp, oracleTableCollation, oracleSchemaCollation, oracleDBCollation,
-		r.cfg.MySQLConfig.DBType, r.oracle, r.mysql, sourceTableNameRuleMap, waitSyncMetas)
+		r.cfg.MySQLConfig.DBType, r.oracle, r.mysql, sourceTableNameRuleMap, columnNameRuleMap, indexGeneratedColumnMap, r.cfg.ReverseConfig.IdentifierPolicy, waitSyncMetas)
 
 	err = common.PathExist(r.cfg.CheckConfig.CheckSQLDir)
 	if err != nil {
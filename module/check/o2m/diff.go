@@ -181,8 +181,8 @@ func (c *Diff) CheckColumnCharacterSetAndCollation() string {
 				} else {
 					oracleCharacterSet = strings.ToUpper(common.OracleDBCharacterSetMap[c.OracleTableINFO.Columns[strings.ToUpper(mysqlColName)].CharacterSet])
 				}
-				if mysqlColInfo.CharacterSet != oracleCharacterSet || mysqlColInfo.Collation !=
-					strings.ToUpper(common.OracleCollationMap[c.OracleTableINFO.Columns[strings.ToUpper(mysqlColName)].Collation]) {
+				if mysqlColInfo.CharacterSet != oracleCharacterSet ||
+					!common.IsOracleMySQLCollationMatch(c.OracleTableINFO.Columns[strings.ToUpper(mysqlColName)].Collation, mysqlColInfo.Collation) {
 					tableColumnsMap[mysqlColName] = mysqlColInfo
 				}
 			}
@@ -326,12 +326,14 @@ func (c *Diff) CheckPrimaryAndUniqueKey() (string, error) {
 		zap.String("table pk and uk constraint check", fmt.Sprintf("%s.%s", c.OracleTableINFO.SchemaName, c.OracleTableINFO.TableName)),
 		zap.String("oracle struct", c.OracleTableINFO.String(common.JSONPUConstraint)),
 		zap.String("mysql struct", c.MySQLTableINFO.String(common.JSONPUConstraint)))
-	// 函数 utils.DiffStructArray 都忽略 structA 空，但 structB 存在情况
-	addDiffPU, _, isOK := common.DiffStructArray(c.OracleTableINFO.PUConstraints, c.MySQLTableINFO.PUConstraints)
 
 	var builder strings.Builder
 
-	if len(addDiffPU) != 0 && !isOK {
+	addDiffPU, err := c.diffPrimaryAndUniqueKey()
+	if err != nil {
+		return builder.String(), err
+	}
+	if len(addDiffPU) != 0 {
 		builder.WriteString("/*\n")
 		builder.WriteString(" oracle and mysql table primary key and unique key\n")
 
@@ -344,24 +346,39 @@ func (c *Diff) CheckPrimaryAndUniqueKey() (string, error) {
 		builder.WriteString(fmt.Sprintf("%v\n", t.Render()))
 
 		builder.WriteString("*/\n")
+		for _, pu := range addDiffPU {
+			builder.WriteString(c.genPrimaryAndUniqueKeySQL(pu))
+		}
+	}
+	return builder.String(), nil
+}
+
+// diffPrimaryAndUniqueKey 上游存在、下游不存在的主键/唯一约束
+func (c *Diff) diffPrimaryAndUniqueKey() ([]ConstraintPUKey, error) {
+	// 函数 utils.DiffStructArray 都忽略 structA 空，但 structB 存在情况
+	addDiffPU, _, isOK := common.DiffStructArray(c.OracleTableINFO.PUConstraints, c.MySQLTableINFO.PUConstraints)
+
+	var puKeys []ConstraintPUKey
+	if len(addDiffPU) != 0 && !isOK {
 		for _, pu := range addDiffPU {
 			value, ok := pu.(ConstraintPUKey)
-			if ok {
-				switch value.ConstraintType {
-				case "PK":
-					builder.WriteString(fmt.Sprintf("ALTER TABLE %s.%s ADD PRIMARY KEY(%s);\n", c.MySQLTableINFO.SchemaName, c.MySQLTableINFO.TableName, value.ConstraintColumn))
-					continue
-				case "UK":
-					builder.WriteString(fmt.Sprintf("ALTER TABLE %s.%s ADD UNIQUE(%s);\n", c.MySQLTableINFO.SchemaName, c.MySQLTableINFO.TableName, value.ConstraintColumn))
-					continue
-				default:
-					return builder.String(), fmt.Errorf("table constraint primary and unique key diff failed: not support type [%s]", value.ConstraintType)
-				}
+			if !ok {
+				return puKeys, fmt.Errorf("oracle table [%s] constraint primary and unique key [%v] assert ConstraintPUKey failed, type: [%v]", c.OracleTableINFO.TableName, pu, reflect.TypeOf(pu))
 			}
-			return builder.String(), fmt.Errorf("oracle table [%s] constraint primary and unique key [%v] assert ConstraintPUKey failed, type: [%v]", c.OracleTableINFO.TableName, pu, reflect.TypeOf(pu))
+			if value.ConstraintType != "PK" && value.ConstraintType != "UK" {
+				return puKeys, fmt.Errorf("table constraint primary and unique key diff failed: not support type [%s]", value.ConstraintType)
+			}
+			puKeys = append(puKeys, value)
 		}
 	}
-	return builder.String(), nil
+	return puKeys, nil
+}
+
+func (c *Diff) genPrimaryAndUniqueKeySQL(value ConstraintPUKey) string {
+	if value.ConstraintType == "PK" {
+		return fmt.Sprintf("ALTER TABLE %s.%s ADD PRIMARY KEY(%s);\n", c.MySQLTableINFO.SchemaName, c.MySQLTableINFO.TableName, value.ConstraintColumn)
+	}
+	return fmt.Sprintf("ALTER TABLE %s.%s ADD UNIQUE(%s);\n", c.MySQLTableINFO.SchemaName, c.MySQLTableINFO.TableName, value.ConstraintColumn)
 }
 
 func (c *Diff) CheckForeignKey() (string, error) {
@@ -463,83 +480,13 @@ func (c *Diff) CheckIndex() (string, error) {
 		zap.String("mysql struct", c.MySQLTableINFO.String(common.JSONIndex)))
 
 	var builder strings.Builder
-	var createIndexSQL []string
-	addDiffIndex, _, isOK := common.DiffStructArray(c.OracleTableINFO.Indexes, c.MySQLTableINFO.Indexes)
-	if len(addDiffIndex) != 0 && !isOK {
-		for _, idx := range addDiffIndex {
-			value, ok := idx.(Index)
-			if ok {
-				if value.Uniqueness == "UNIQUE" && value.IndexType == "NORMAL" {
-					// 考虑 MySQL 索引类型 BTREE，额外判断处理
-					var equalArray []interface{}
-					for _, mysqlIndexInfo := range c.MySQLTableINFO.Indexes {
-						if reflect.DeepEqual(value.IndexInfo, mysqlIndexInfo.IndexInfo) {
-							equalArray = append(equalArray, value.IndexInfo)
-						}
-					}
-					if len(equalArray) == 0 {
-						createIndexSQL = append(createIndexSQL, fmt.Sprintf("CREATE UNIQUE INDEX %s ON %s.%s (%s);\n",
-							value.IndexName, c.MySQLTableINFO.SchemaName, c.MySQLTableINFO.TableName, value.IndexColumn))
-					}
-					continue
-				}
-				if value.Uniqueness == "UNIQUE" && value.IndexType == "FUNCTION-BASED NORMAL" {
-					// 考虑 MySQL 索引类型 BTREE，额外判断处理
-					var equalArray []interface{}
-					for _, mysqlIndexInfo := range c.MySQLTableINFO.Indexes {
-						if reflect.DeepEqual(value.IndexInfo, mysqlIndexInfo.IndexInfo) {
-							equalArray = append(equalArray, value.IndexInfo)
-						}
-					}
-					if len(equalArray) == 0 {
-						createIndexSQL = append(createIndexSQL, fmt.Sprintf("CREATE UNIQUE INDEX %s ON %s.%s (%s);\n",
-							value.IndexName, c.MySQLTableINFO.SchemaName, c.MySQLTableINFO.TableName, value.IndexColumn))
-					}
-					continue
-				}
-				if value.Uniqueness == "NONUNIQUE" && value.IndexType == "NORMAL" {
-					// 考虑 MySQL 索引类型 BTREE，额外判断处理
-					var equalArray []interface{}
-					for _, mysqlIndexInfo := range c.MySQLTableINFO.Indexes {
-						if reflect.DeepEqual(value.IndexInfo, mysqlIndexInfo.IndexInfo) {
-							equalArray = append(equalArray, value.IndexInfo)
-						}
-					}
-					if len(equalArray) == 0 {
-						createIndexSQL = append(createIndexSQL, fmt.Sprintf("CREATE INDEX %s ON %s.%s (%s);\n",
-							value.IndexName, c.MySQLTableINFO.SchemaName, c.MySQLTableINFO.TableName, value.IndexColumn))
-					}
-					continue
-				}
-				if value.Uniqueness == "NONUNIQUE" && value.IndexType == "BITMAP" {
-					createIndexSQL = append(createIndexSQL, fmt.Sprintf("CREATE BITMAP INDEX %s ON %s.%s (%s);\n",
-						value.IndexName, c.MySQLTableINFO.SchemaName, c.MySQLTableINFO.TableName, value.IndexColumn))
-					continue
-				}
-				if value.Uniqueness == "NONUNIQUE" && value.IndexType == "FUNCTION-BASED NORMAL" {
-					createIndexSQL = append(createIndexSQL, fmt.Sprintf("CREATE INDEX %s ON %s.%s (%s);\n",
-						value.IndexName, c.MySQLTableINFO.SchemaName, c.MySQLTableINFO.TableName, value.IndexColumn))
-					continue
-				}
-				if value.Uniqueness == "NONUNIQUE" && value.IndexType == "FUNCTION-BASED BITMAP" {
-					createIndexSQL = append(createIndexSQL, fmt.Sprintf("CREATE BITMAP INDEX %s ON %s.%s (%s);\n",
-						value.IndexName, c.MySQLTableINFO.SchemaName, c.MySQLTableINFO.TableName, value.IndexColumn))
-					continue
-				}
-				if value.Uniqueness == "NONUNIQUE" && value.IndexType == "DOMAIN" {
-					createIndexSQL = append(createIndexSQL,
-						fmt.Sprintf("CREATE INDEX %s ON %s.%s (%s) INDEXTYPE IS %s.%s PARAMETERS ('%s');\n",
-							value.IndexName, c.MySQLTableINFO.SchemaName, c.MySQLTableINFO.TableName, value.IndexColumn,
-							value.DomainIndexOwner, value.DomainIndexName, value.DomainParameters))
-					continue
-				}
-				return builder.String(), fmt.Errorf("oracle table [%s] diff failed, not support index: [%v]", c.OracleTableINFO.TableName, value)
-			}
-			return builder.String(), fmt.Errorf("oracle table [%s] index [%v] assert Index failed, type: [%v]", c.OracleTableINFO.TableName, idx, reflect.TypeOf(idx))
-		}
+
+	addDiffIndex, err := c.diffIndex()
+	if err != nil {
+		return builder.String(), err
 	}
 
-	if len(createIndexSQL) != 0 {
+	if len(addDiffIndex) != 0 {
 		builder.WriteString("/*\n")
 		builder.WriteString(" oracle and mysql table indexes\n")
 
@@ -552,14 +499,69 @@ func (c *Diff) CheckIndex() (string, error) {
 		builder.WriteString(fmt.Sprintf("%v\n", t.Render()))
 
 		builder.WriteString("*/\n")
-		for _, indexSQL := range createIndexSQL {
-			builder.WriteString(indexSQL)
+		for _, idx := range addDiffIndex {
+			builder.WriteString(c.genIndexSQL(idx))
 		}
 	}
 
 	return builder.String(), nil
 }
 
+// diffIndex 上游存在、下游不存在的索引
+// 考虑 MySQL 索引类型 BTREE，NORMAL 以及 UNIQUE FUNCTION-BASED NORMAL 索引额外判断索引字段是否存在
+func (c *Diff) diffIndex() ([]Index, error) {
+	var indexes []Index
+	addDiffIndex, _, isOK := common.DiffStructArray(c.OracleTableINFO.Indexes, c.MySQLTableINFO.Indexes)
+	if len(addDiffIndex) != 0 && !isOK {
+		for _, idx := range addDiffIndex {
+			value, ok := idx.(Index)
+			if !ok {
+				return indexes, fmt.Errorf("oracle table [%s] index [%v] assert Index failed, type: [%v]", c.OracleTableINFO.TableName, idx, reflect.TypeOf(idx))
+			}
+			switch {
+			case value.Uniqueness == "UNIQUE" && value.IndexType == "NORMAL",
+				value.Uniqueness == "UNIQUE" && value.IndexType == "FUNCTION-BASED NORMAL",
+				value.Uniqueness == "NONUNIQUE" && value.IndexType == "NORMAL":
+				var equalArray []interface{}
+				for _, mysqlIndexInfo := range c.MySQLTableINFO.Indexes {
+					if reflect.DeepEqual(value.IndexInfo, mysqlIndexInfo.IndexInfo) {
+						equalArray = append(equalArray, value.IndexInfo)
+					}
+				}
+				if len(equalArray) == 0 {
+					indexes = append(indexes, value)
+				}
+			case value.Uniqueness == "NONUNIQUE" && value.IndexType == "BITMAP",
+				value.Uniqueness == "NONUNIQUE" && value.IndexType == "FUNCTION-BASED NORMAL",
+				value.Uniqueness == "NONUNIQUE" && value.IndexType == "FUNCTION-BASED BITMAP",
+				value.Uniqueness == "NONUNIQUE" && value.IndexType == "DOMAIN":
+				indexes = append(indexes, value)
+			default:
+				return indexes, fmt.Errorf("oracle table [%s] diff failed, not support index: [%v]", c.OracleTableINFO.TableName, value)
+			}
+		}
+	}
+	return indexes, nil
+}
+
+func (c *Diff) genIndexSQL(value Index) string {
+	switch {
+	case value.Uniqueness == "UNIQUE":
+		return fmt.Sprintf("CREATE UNIQUE INDEX %s ON %s.%s (%s);\n",
+			value.IndexName, c.MySQLTableINFO.SchemaName, c.MySQLTableINFO.TableName, value.IndexColumn)
+	case value.IndexType == "BITMAP" || value.IndexType == "FUNCTION-BASED BITMAP":
+		return fmt.Sprintf("CREATE BITMAP INDEX %s ON %s.%s (%s);\n",
+			value.IndexName, c.MySQLTableINFO.SchemaName, c.MySQLTableINFO.TableName, value.IndexColumn)
+	case value.IndexType == "DOMAIN":
+		return fmt.Sprintf("CREATE INDEX %s ON %s.%s (%s) INDEXTYPE IS %s.%s PARAMETERS ('%s');\n",
+			value.IndexName, c.MySQLTableINFO.SchemaName, c.MySQLTableINFO.TableName, value.IndexColumn,
+			value.DomainIndexOwner, value.DomainIndexName, value.DomainParameters)
+	default:
+		return fmt.Sprintf("CREATE INDEX %s ON %s.%s (%s);\n",
+			value.IndexName, c.MySQLTableINFO.SchemaName, c.MySQLTableINFO.TableName, value.IndexColumn)
+	}
+}
+
 func (c *Diff) CheckPartitionTable() (string, error) {
 	// 分区表检查
 	var builder strings.Builder
@@ -746,6 +748,118 @@ func (c *Diff) Writer(f *check.File) error {
 	return nil
 }
 
+// GenConvergeSQL reverse 模式下游表已存在，以上游 oracle 表结构为基准生成收敛语句
+// 新增字段、字段修改、缺失主键/唯一约束以及 NORMAL 索引输出 ALTER 语句，不删除下游数据
+// 下游多余字段、字符集排序规则、外键、检查约束、分区以及不兼容索引输出不兼容项，需人工处理
+func (c *Diff) GenConvergeSQL() (string, string, error) {
+	var (
+		alterBuilder strings.Builder
+		compBuilder  strings.Builder
+	)
+
+	counts, err := c.CheckColumnCounts()
+	if err != nil {
+		return alterBuilder.String(), compBuilder.String(), err
+	}
+	alterBuilder.WriteString(counts)
+
+	column, err := c.CheckColumn()
+	if err != nil {
+		return alterBuilder.String(), compBuilder.String(), err
+	}
+	alterBuilder.WriteString(column)
+
+	// 下游已存在主键，主键不一致不做变更
+	mysqlPK := false
+	for _, pu := range c.MySQLTableINFO.PUConstraints {
+		if pu.ConstraintType == "PK" {
+			mysqlPK = true
+		}
+	}
+	addDiffPU, err := c.diffPrimaryAndUniqueKey()
+	if err != nil {
+		return alterBuilder.String(), compBuilder.String(), err
+	}
+	addDiffIndex, err := c.diffIndex()
+	if err != nil {
+		return alterBuilder.String(), compBuilder.String(), err
+	}
+
+	var (
+		alterSQL []string
+		compSQL  []string
+	)
+	for _, pu := range addDiffPU {
+		if pu.ConstraintType == "PK" && mysqlPK {
+			compSQL = append(compSQL, c.genPrimaryAndUniqueKeySQL(pu))
+			continue
+		}
+		alterSQL = append(alterSQL, c.genPrimaryAndUniqueKeySQL(pu))
+	}
+	for _, idx := range addDiffIndex {
+		if idx.IndexType == "NORMAL" {
+			alterSQL = append(alterSQL, c.genIndexSQL(idx))
+			continue
+		}
+		compSQL = append(compSQL, c.genIndexSQL(idx))
+	}
+
+	if len(alterSQL) > 0 {
+		alterBuilder.WriteString("/*\n")
+		alterBuilder.WriteString(" oracle and mysql table key and indexes\n")
+
+		t := table.NewWriter()
+		t.SetStyle(table.StyleLight)
+		t.AppendHeader(table.Row{"TABLE", "KEY AND INDEXES", "SUGGEST"})
+		t.AppendRows([]table.Row{
+			{c.OracleTableINFO.TableName, "Oracle And Mysql Different", "Create Table Key And Index"},
+		})
+		alterBuilder.WriteString(fmt.Sprintf("%v\n", t.Render()))
+
+		alterBuilder.WriteString("*/\n")
+		alterBuilder.WriteString(strings.Join(alterSQL, ""))
+	}
+	if len(compSQL) > 0 {
+		compBuilder.WriteString("/*\n")
+		compBuilder.WriteString(" oracle and mysql table key and indexes maybe mysql has compatibility, skip\n")
+
+		t := table.NewWriter()
+		t.SetStyle(table.StyleLight)
+		t.AppendHeader(table.Row{"TABLE", "KEY AND INDEXES", "SUGGEST"})
+		t.AppendRows([]table.Row{
+			{c.OracleTableINFO.TableName, "Oracle And Mysql Different", "Manual Process Key And Index"},
+		})
+		compBuilder.WriteString(fmt.Sprintf("%v\n", t.Render()))
+
+		compBuilder.WriteString("*/\n")
+		compBuilder.WriteString(strings.Join(compSQL, ""))
+	}
+
+	compBuilder.WriteString(c.CheckPartitionTableType())
+	compBuilder.WriteString(c.CheckTableCharacterSetAndCollation())
+	compBuilder.WriteString(c.CheckColumnCharacterSetAndCollation())
+
+	foreignKey, err := c.CheckForeignKey()
+	if err != nil {
+		return alterBuilder.String(), compBuilder.String(), err
+	}
+	compBuilder.WriteString(foreignKey)
+
+	checkKey, err := c.CheckCheckKey()
+	if err != nil {
+		return alterBuilder.String(), compBuilder.String(), err
+	}
+	compBuilder.WriteString(checkKey)
+
+	partitionTable, err := c.CheckPartitionTable()
+	if err != nil {
+		return alterBuilder.String(), compBuilder.String(), err
+	}
+	compBuilder.WriteString(partitionTable)
+
+	return alterBuilder.String(), compBuilder.String(), nil
+}
+
 func (c *Diff) String() string {
 	jsonStr, _ := json.Marshal(c)
 	return string(jsonStr)
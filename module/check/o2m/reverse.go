@@ -0,0 +1,86 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package o2m
+
+import (
+	"context"
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/database/meta"
+	reverseO2M "github.com/wentaojin/transferdb/module/reverse/o2m"
+	"reflect"
+)
+
+// Reverse reverse 模式下游表已存在，复用表结构对比生成收敛语句
+type Reverse struct {
+	Ctx     context.Context
+	DBTypeS string
+	DBTypeT string
+	MetaDB  *meta.Meta
+}
+
+func NewReverseDiffer(ctx context.Context, dbTypeS, dbTypeT string, metaDB *meta.Meta) *Reverse {
+	return &Reverse{
+		Ctx:     ctx,
+		DBTypeS: dbTypeS,
+		DBTypeT: dbTypeT,
+		MetaDB:  metaDB,
+	}
+}
+
+func (r *Reverse) Diff(t interface{}) (string, string, error) {
+	tbl, ok := t.(*reverseO2M.Table)
+	if !ok {
+		return "", "", fmt.Errorf("reverse table [%v] assert Table failed, type: [%v]", t, reflect.TypeOf(t))
+	}
+	task := &Task{
+		SourceSchemaName:      tbl.SourceSchemaName,
+		TargetSchemaName:      tbl.TargetSchemaName,
+		SourceTableName:       tbl.SourceTableName,
+		TargetTableName:       tbl.TargetTableName,
+		TargetDBType:          tbl.TargetDBType,
+		SourceDBCharacterSet:  tbl.SourceDBCharacterSet,
+		SourceDBNLSSort:       tbl.SourceDBNLSSort,
+		SourceDBNLSComp:       tbl.SourceDBNLSComp,
+		SourceDBCollation:     tbl.OracleCollation,
+		SourceTableCollation:  tbl.SourceTableCollation,
+		SourceSchemaCollation: tbl.SourceSchemaCollation,
+		ColumnNameRule:        tbl.SchemaColumnNameRule,
+		IdentifierPolicy:      tbl.IdentifierPolicy,
+		Oracle:                tbl.Oracle,
+		MySQL:                 tbl.MySQL,
+	}
+	indexGeneratedColumnMap, err := meta.NewIndexGeneratedColumnModel(r.MetaDB).DetailSchemaIndexGeneratedColumn(r.Ctx, &meta.IndexGeneratedColumn{
+		DBTypeS:     r.DBTypeS,
+		DBTypeT:     r.DBTypeT,
+		SchemaNameS: tbl.SourceSchemaName,
+		TableNameS:  tbl.SourceTableName,
+	})
+	if err != nil {
+		return "", "", err
+	}
+	task.IndexGeneratedColumns = indexGeneratedColumnMap[common.StringUPPER(tbl.SourceTableName)]
+	oracleTableInfo, err := task.GenOracleTable()
+	if err != nil {
+		return "", "", err
+	}
+	mysqlTableInfo, mysqlDBVersion, err := task.GenMySQLTable()
+	if err != nil {
+		return "", "", err
+	}
+	return NewChecker(r.Ctx, oracleTableInfo, mysqlTableInfo,
+		r.DBTypeS, r.DBTypeT, mysqlDBVersion, task.TargetDBType, r.MetaDB).GenConvergeSQL()
+}
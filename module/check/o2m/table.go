@@ -128,7 +128,7 @@ func (c *Column) String() string {
 */
 
 func NewOracleTableINFO(schemaName, tableName string, oracle *oracle.Oracle, sourceCharacterSet, nlsComp string,
-	sourceTableCollation, sourceSchemaCollation string, oracleCollation bool) (*Table, error) {
+	sourceTableCollation, sourceSchemaCollation string, oracleCollation bool, columnNameRule map[string]map[string]string, identifierPolicy string) (*Table, error) {
 	oraTable := &Table{
 		SchemaName: schemaName,
 		TableName:  tableName,
@@ -189,9 +189,46 @@ func NewOracleTableINFO(schemaName, tableName string, oracle *oracle.Oracle, sou
 	oraTable.CheckConstraints = ckConstraints
 	oraTable.IsPartition = isPart
 	oraTable.Partitions = parts
+
+	// 字段名映射规则，oracle 字段名转换为目标端字段名对比
+	oraTable.genColumnNameRule(schemaName, columnNameRule)
+
+	// 索引名称与 reverse 超长标识符处理策略保持一致
+	for i := range oraTable.Indexes {
+		oraTable.Indexes[i].IndexName = common.StringIdentifierPolicy(oraTable.Indexes[i].IndexName, identifierPolicy)
+	}
 	return oraTable, nil
 }
 
+func (t *Table) genColumnNameRule(schemaName string, columnNameRule map[string]map[string]string) {
+	tableRule := columnNameRule[common.StringUPPER(t.TableName)]
+	if len(tableRule) > 0 {
+		columns := make(map[string]Column, len(t.Columns))
+		for columnName, column := range t.Columns {
+			columns[common.StringColumnNameRule(columnName, tableRule)] = column
+		}
+		t.Columns = columns
+
+		for i := range t.Indexes {
+			t.Indexes[i].IndexColumn = common.StringColumnListRule(t.Indexes[i].IndexColumn, tableRule)
+		}
+		for i := range t.PUConstraints {
+			t.PUConstraints[i].ConstraintColumn = common.StringColumnListRule(t.PUConstraints[i].ConstraintColumn, tableRule)
+		}
+		for i := range t.Partitions {
+			t.Partitions[i].PartitionKey = common.StringColumnListRule(t.Partitions[i].PartitionKey, tableRule)
+			t.Partitions[i].SubPartitionKey = common.StringColumnListRule(t.Partitions[i].SubPartitionKey, tableRule)
+		}
+	}
+	for i := range t.ForeignConstraints {
+		t.ForeignConstraints[i].ColumnName = common.StringColumnListRule(t.ForeignConstraints[i].ColumnName, tableRule)
+		if strings.EqualFold(t.ForeignConstraints[i].ReferencedTableSchema, schemaName) {
+			t.ForeignConstraints[i].ReferencedColumnName = common.StringColumnListRule(t.ForeignConstraints[i].ReferencedColumnName,
+				columnNameRule[common.StringUPPER(t.ForeignConstraints[i].ReferencedTableName)])
+		}
+	}
+}
+
 func GetOracleTableColumn(schemaName, tableName string, oracle *oracle.Oracle, sourceDBCharacterSet, nlsComp string,
 	sourceTableCollation string, sourceSchemaCollation string, oraCollation bool) (map[string]Column, string, error) {
 	columnInfo, err := oracle.GetOracleSchemaTableColumn(schemaName, tableName, oraCollation)
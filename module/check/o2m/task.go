@@ -36,13 +36,18 @@ type Task struct {
 	SourceTableCollation  string `json:"source_table_collation"`
 	SourceSchemaCollation string `json:"source_schema_collation"`
 
+	ColumnNameRule   map[string]map[string]string `json:"column_name_rule"`
+	IdentifierPolicy string                       `json:"identifier_policy"`
+	// 函数索引 generated column 目标端字段名，上游不存在对应字段，对比忽略
+	IndexGeneratedColumns map[string]struct{} `json:"index_generated_columns"`
+
 	Oracle *oracle.Oracle `json:"-"`
 	MySQL  *mysql.MySQL   `json:"-"`
 }
 
 func GenCheckTaskTable(sourceSchemaName, targetSchemaName, sourceDBCharacterSet, nlsSort, nlsComp string,
 	sourceTableCollation map[string]string, sourceSchemaCollation string,
-	sourceDBCollation bool, targetDBType string, oracle *oracle.Oracle, mysql *mysql.MySQL, tableNameRule map[string]string, waitSyncMetas []meta.WaitSyncMeta) []*Task {
+	sourceDBCollation bool, targetDBType string, oracle *oracle.Oracle, mysql *mysql.MySQL, tableNameRule map[string]string, columnNameRule map[string]map[string]string, indexGeneratedColumn map[string]map[string]struct{}, identifierPolicy string, waitSyncMetas []meta.WaitSyncMeta) []*Task {
 	var tasks []*Task
 	for _, t := range waitSyncMetas {
 		// 库名、表名规则
@@ -64,6 +69,9 @@ func GenCheckTaskTable(sourceSchemaName, targetSchemaName, sourceDBCharacterSet,
 			SourceTableCollation:  sourceTableCollation[t.TableNameS],
 			SourceSchemaCollation: sourceSchemaCollation,
 			TargetDBType:          targetDBType,
+			ColumnNameRule:        columnNameRule,
+			IdentifierPolicy:      identifierPolicy,
+			IndexGeneratedColumns: indexGeneratedColumn[common.StringUPPER(t.TableNameS)],
 			Oracle:                oracle,
 			MySQL:                 mysql,
 		})
@@ -72,7 +80,7 @@ func GenCheckTaskTable(sourceSchemaName, targetSchemaName, sourceDBCharacterSet,
 }
 
 func (t *Task) GenOracleTable() (*Table, error) {
-	info, err := NewOracleTableINFO(t.SourceSchemaName, t.SourceTableName, t.Oracle, t.SourceDBCharacterSet, t.SourceDBNLSComp, t.SourceTableCollation, t.SourceSchemaCollation, t.SourceDBCollation)
+	info, err := NewOracleTableINFO(t.SourceSchemaName, t.SourceTableName, t.Oracle, t.SourceDBCharacterSet, t.SourceDBNLSComp, t.SourceTableCollation, t.SourceSchemaCollation, t.SourceDBCollation, t.ColumnNameRule, t.IdentifierPolicy)
 	if err != nil {
 		return info, err
 	}
@@ -84,6 +92,11 @@ func (t *Task) GenMySQLTable() (*Table, string, error) {
 	if err != nil {
 		return info, version, err
 	}
+	for col := range info.Columns {
+		if _, ok := t.IndexGeneratedColumns[common.StringUPPER(col)]; ok {
+			delete(info.Columns, col)
+		}
+	}
 	return info, version, nil
 }
 
@@ -0,0 +1,35 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package compare
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SplitFixColumns 查询字段为对比字段与修复字段拼接，两部分字段名以及顺序一致，返回对比字段个数
+func SplitFixColumns(cols []string) (int, error) {
+	width := len(cols) / 2
+	if len(cols)%2 != 0 {
+		return 0, fmt.Errorf("compare columns and fix columns [%v] counts aren't match", cols)
+	}
+	for i := 0; i < width; i++ {
+		if !strings.EqualFold(cols[i], cols[width+i]) {
+			return 0, fmt.Errorf("compare column [%s] and fix column [%s] aren't match", cols[i], cols[width+i])
+		}
+	}
+	return width, nil
+}
@@ -17,7 +17,11 @@ package compare
 
 import (
 	"bufio"
+	"fmt"
+	"io"
 	"os"
+	"strconv"
+	"strings"
 	"sync"
 )
 
@@ -44,6 +48,26 @@ func (f *File) CWriteString(s string) (nn int, err error) {
 	return f.CWriter.WriteString(s)
 }
 
+// CWriteSpool chunk 差异报告以及暂存修复 SQL 整体写入输出文件，保证单 chunk 修复 SQL 连续不与其他 chunk 交错
+func (f *File) CWriteSpool(report string, spool *Spool) error {
+	f.Mutex.Lock()
+	defer f.Mutex.Unlock()
+	if _, err := f.CWriter.WriteString(report); err != nil {
+		return err
+	}
+	if spool == nil || spool.file == nil {
+		return nil
+	}
+	if err := spool.writer.Flush(); err != nil {
+		return err
+	}
+	if _, err := spool.file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	_, err := io.Copy(f.CWriter, spool.file)
+	return err
+}
+
 func (f *File) initOutFile(checkFile string) error {
 	outCheckFile, err := os.OpenFile(checkFile, os.O_WRONLY|os.O_CREATE|os.O_APPEND|os.O_TRUNC, 0666)
 	if err != nil {
@@ -66,3 +90,165 @@ func (f *File) Close() error {
 	}
 	return nil
 }
+
+// Spool chunk 修复 SQL 暂存文件，数据行对比过程中逐条写入，避免修复 SQL 全部驻留内存
+// 差异自动修复另行暂存修复 SQL，按批次读取下游执行
+type Spool struct {
+	dir       string
+	file      *os.File
+	writer    *bufio.Writer
+	keepSQL   bool
+	sqlFile   *os.File
+	sqlWriter *bufio.Writer
+	Counts    int64
+}
+
+// NewSpool 暂存文件首次写入时创建，dir 为空使用系统临时目录，keepSQL 开启单独暂存修复 SQL 用于 ReadSQL 读取
+func NewSpool(dir string, keepSQL bool) *Spool {
+	return &Spool{dir: dir, keepSQL: keepSQL}
+}
+
+// WriteString 写入注释等原始内容
+func (s *Spool) WriteString(str string) error {
+	if s.file == nil {
+		file, err := os.CreateTemp(s.dir, "compare_*.sql.tmp")
+		if err != nil {
+			return err
+		}
+		s.file, s.writer = file, bufio.NewWriter(file)
+	}
+	_, err := s.writer.WriteString(str)
+	return err
+}
+
+// WriteSQL 写入单条修复 SQL
+func (s *Spool) WriteSQL(sql string) error {
+	if err := s.WriteString(sql); err != nil {
+		return err
+	}
+	if err := s.WriteString(";\n"); err != nil {
+		return err
+	}
+	if s.keepSQL {
+		if err := s.writeKeepSQL(sql); err != nil {
+			return err
+		}
+	}
+	s.Counts++
+	return nil
+}
+
+// writeKeepSQL 修复 SQL 以长度前缀写入，SQL 字段值可能包含换行符以及分号
+func (s *Spool) writeKeepSQL(sql string) error {
+	if s.sqlFile == nil {
+		file, err := os.CreateTemp(s.dir, "compare_*.repair.tmp")
+		if err != nil {
+			return err
+		}
+		s.sqlFile, s.sqlWriter = file, bufio.NewWriter(file)
+	}
+	if _, err := s.sqlWriter.WriteString(strconv.Itoa(len(sql))); err != nil {
+		return err
+	}
+	if err := s.sqlWriter.WriteByte('\n'); err != nil {
+		return err
+	}
+	_, err := s.sqlWriter.WriteString(sql)
+	return err
+}
+
+// ReadSQL 按批次读取暂存修复 SQL，单批次最多 batchSize 条，内存仅保留当前批次
+func (s *Spool) ReadSQL(batchSize int, fn func(sqls []string) error) error {
+	if !s.keepSQL {
+		return fmt.Errorf("spool fix sql isn't kept, please enable keep sql")
+	}
+	if s.sqlFile == nil {
+		return nil
+	}
+	if batchSize <= 0 {
+		batchSize = 1
+	}
+	if err := s.sqlWriter.Flush(); err != nil {
+		return err
+	}
+	if _, err := s.sqlFile.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	// 读取完成恢复写入位置
+	defer s.sqlFile.Seek(0, io.SeekEnd)
+
+	reader := bufio.NewReader(s.sqlFile)
+	batch := make([]string, 0, batchSize)
+	for {
+		line, err := reader.ReadString('\n')
+		if err == io.EOF && line == "" {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("read spool fix sql length failed: %v", err)
+		}
+		size, err := strconv.Atoi(strings.TrimSuffix(line, "\n"))
+		if err != nil {
+			return fmt.Errorf("parse spool fix sql length [%s] failed: %v", line, err)
+		}
+		buf := make([]byte, size)
+		if _, err = io.ReadFull(reader, buf); err != nil {
+			return fmt.Errorf("read spool fix sql failed: %v", err)
+		}
+		batch = append(batch, string(buf))
+		if len(batch) == batchSize {
+			if err = fn(batch); err != nil {
+				return err
+			}
+			batch = batch[:0]
+		}
+	}
+	if len(batch) > 0 {
+		return fn(batch)
+	}
+	return nil
+}
+
+// Reset 清空暂存内容，用于对比失败回退其他对比方式
+func (s *Spool) Reset() error {
+	s.Counts = 0
+	if err := resetSpoolFile(s.file, s.writer); err != nil {
+		return err
+	}
+	return resetSpoolFile(s.sqlFile, s.sqlWriter)
+}
+
+// Close 关闭并删除暂存文件
+func (s *Spool) Close() error {
+	err := closeSpoolFile(s.file)
+	s.file, s.writer = nil, nil
+	if sqlErr := closeSpoolFile(s.sqlFile); err == nil {
+		err = sqlErr
+	}
+	s.sqlFile, s.sqlWriter = nil, nil
+	return err
+}
+
+func resetSpoolFile(file *os.File, writer *bufio.Writer) error {
+	if file == nil {
+		return nil
+	}
+	writer.Reset(file)
+	if err := file.Truncate(0); err != nil {
+		return err
+	}
+	_, err := file.Seek(0, io.SeekStart)
+	return err
+}
+
+func closeSpoolFile(file *os.File) error {
+	if file == nil {
+		return nil
+	}
+	name := file.Name()
+	err := file.Close()
+	if rmErr := os.Remove(name); err == nil {
+		err = rmErr
+	}
+	return err
+}
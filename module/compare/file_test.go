@@ -0,0 +1,180 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package compare
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFileCWriteSpool(t *testing.T) {
+	dir := t.TempDir()
+	checkFile := filepath.Join(dir, "compare.sql")
+	f, err := NewWriter(checkFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name   string
+		report string
+		reset  bool
+		sqls   []string
+		want   string
+		counts int64
+	}{
+		{name: "report without spool", report: "/* equal */\n", want: "/* equal */\n"},
+		{name: "report with fix sql", report: "/* diff */\n", sqls: []string{"DELETE FROM T WHERE ID=1", "INSERT INTO T (ID) VALUES (2)"},
+			want: "/* diff */\nDELETE FROM T WHERE ID=1;\nINSERT INTO T (ID) VALUES (2);\n", counts: 2},
+		{name: "spool reset before write", report: "/* reset */\n", reset: true, sqls: []string{"UPDATE T SET C=1 WHERE ID=3"},
+			want: "/* reset */\nUPDATE T SET C=1 WHERE ID=3;\n", counts: 1},
+	}
+
+	var want string
+	for _, c := range cases {
+		spool := NewSpool(dir, false)
+		if c.reset {
+			if err = spool.WriteSQL("DELETE FROM T WHERE ID=0"); err != nil {
+				t.Fatal(err)
+			}
+			if err = spool.Reset(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		for _, s := range c.sqls {
+			if err = spool.WriteSQL(s); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if spool.Counts != c.counts {
+			t.Errorf("%s: spool counts got %d, want %d", c.name, spool.Counts, c.counts)
+		}
+		if err = f.CWriteSpool(c.report, spool); err != nil {
+			t.Fatal(err)
+		}
+		if err = spool.Close(); err != nil {
+			t.Fatal(err)
+		}
+		want += c.want
+	}
+	if err = f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := os.ReadFile(checkFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Fatalf("check file got %q, want %q", string(got), want)
+	}
+
+	// 暂存文件关闭后删除
+	tmpFiles, err := filepath.Glob(filepath.Join(dir, "compare_*.sql.tmp"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tmpFiles) != 0 {
+		t.Fatalf("spool files %v aren't removed", tmpFiles)
+	}
+}
+
+func TestSpoolReadSQL(t *testing.T) {
+	sqls := []string{
+		"DELETE FROM T WHERE ID=1",
+		"INSERT INTO T (ID,C) VALUES (2,'a;\nb')",
+		"UPDATE T SET C='\n' WHERE ID=3",
+		"INSERT INTO T (ID) VALUES (4)",
+		"INSERT INTO T (ID) VALUES (5)",
+	}
+	cases := []struct {
+		name      string
+		batchSize int
+		want      [][]string
+	}{
+		{name: "batch size 2", batchSize: 2, want: [][]string{sqls[0:2], sqls[2:4], sqls[4:5]}},
+		{name: "batch size larger than sql counts", batchSize: 10, want: [][]string{sqls}},
+		{name: "batch size 0 read one by one", batchSize: 0, want: [][]string{sqls[0:1], sqls[1:2], sqls[2:3], sqls[3:4], sqls[4:5]}},
+	}
+
+	dir := t.TempDir()
+	spool := NewSpool(dir, true)
+	defer spool.Close()
+	if err := spool.WriteString("/* diff */\n"); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range sqls {
+		if err := spool.WriteSQL(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var got [][]string
+			if err := spool.ReadSQL(c.batchSize, func(batch []string) error {
+				got = append(got, append([]string(nil), batch...))
+				return nil
+			}); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, c.want) {
+				t.Fatalf("read sql got %q, want %q", got, c.want)
+			}
+		})
+	}
+
+	// 读取后继续写入追加
+	if err := spool.WriteSQL("DELETE FROM T WHERE ID=6"); err != nil {
+		t.Fatal(err)
+	}
+	var counts int
+	if err := spool.ReadSQL(100, func(batch []string) error {
+		counts += len(batch)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if counts != len(sqls)+1 {
+		t.Fatalf("read sql counts got %d, want %d", counts, len(sqls)+1)
+	}
+
+	// 重置后无修复 SQL
+	if err := spool.Reset(); err != nil {
+		t.Fatal(err)
+	}
+	if err := spool.ReadSQL(100, func(batch []string) error {
+		t.Fatalf("reset spool read sql %q", batch)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := NewSpool(dir, false).ReadSQL(100, func([]string) error { return nil }); err == nil {
+		t.Fatal("spool without keep sql read should be failed")
+	}
+	if err := spool.Close(); err != nil {
+		t.Fatal(err)
+	}
+	tmpFiles, err := filepath.Glob(filepath.Join(dir, "compare_*.tmp"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tmpFiles) != 0 {
+		t.Fatalf("spool files %v aren't removed", tmpFiles)
+	}
+}
@@ -22,16 +22,18 @@ type Processor interface {
 }
 
 type Chunker interface {
-	CustomTableConfig() (customColumn string, customRange string, err error)
+	CustomTableConfig() (customColumn string, customKeys []string, customRange string, err error)
 	Split() error
 }
 
 type Reporter interface {
 	GenDBQuery() (oracleQuery string, mysqlQuery string)
+	GenDBChecksumQuery() (oracleQuery string, mysqlQuery string)
 	CheckOracleRows(oracleQuery string) (int64, error)
 	CheckMySQLRows(mysqlQuery string) (int64, error)
 	ReportCheckRows() (string, error)
 	ReportCheckCRC32() (string, error)
+	ReportCheckChecksum() (bool, error)
 	Report() (string, error)
 }
 
@@ -0,0 +1,254 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package m2o
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/config"
+	"github.com/wentaojin/transferdb/database/meta"
+	"github.com/wentaojin/transferdb/database/mysql"
+	"github.com/wentaojin/transferdb/database/oracle"
+	"go.uber.org/zap"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// Chunk 数据对比，以上游 MySQL 表切分
+type Chunk struct {
+	Ctx              context.Context `json:"-"`
+	ChunkID          int             `json:"chunk_id"`
+	SourceTable      string          `json:"source_table"`
+	TargetTable      string          `json:"target_table"`
+	IsPartition      string          `json:"is_partition"`
+	SourceColumnInfo string          `json:"source_column_info"`
+	TargetColumnInfo string          `json:"target_column_info"`
+	WhereColumn      string          `json:"where_column"`
+	WhereRange       string          `json:"where_range"` // chunk split need
+	Cfg              *config.Config  `json:"-"`
+	Oracle           *oracle.Oracle  `json:"-"`
+	MySQL            *mysql.MySQL    `json:"-"`
+	MetaDB           *meta.Meta      `json:"-"`
+}
+
+func NewChunk(ctx context.Context, cfg *config.Config, oracle *oracle.Oracle, mysql *mysql.MySQL, metaDB *meta.Meta,
+	chunkID int, sourceTable, targetTable string, isPartition string, sourceColumnInfo, targetColumnInfo string,
+	whereColumn string) *Chunk {
+	return &Chunk{
+		Ctx:              ctx,
+		ChunkID:          chunkID,
+		SourceTable:      sourceTable,
+		TargetTable:      targetTable,
+		IsPartition:      isPartition,
+		SourceColumnInfo: sourceColumnInfo,
+		TargetColumnInfo: targetColumnInfo,
+		WhereColumn:      whereColumn,
+		Oracle:           oracle,
+		MySQL:            mysql,
+		MetaDB:           metaDB,
+		Cfg:              cfg,
+	}
+}
+
+// CustomTableConfig 配置文件自定义切分，indexFields 仅支持单个整型字段
+func (c *Chunk) CustomTableConfig() (customColumn string, customKeys []string, customRange string, err error) {
+	for _, tableCfg := range c.Cfg.DiffConfig.TableConfig {
+		if strings.EqualFold(c.SourceTable, tableCfg.SourceTable) {
+			// 同张表如果同时存在 indexFields 以及 Range，那么 Range 优先级 > indexFields
+			if tableCfg.Range != "" {
+				customRange = tableCfg.Range
+				return customColumn, customKeys, customRange, nil
+			}
+			if tableCfg.IndexFields != "" {
+				isInteger, err := c.MySQL.IsIntegerColumnTYPE(c.Cfg.MySQLConfig.SchemaName, c.SourceTable, tableCfg.IndexFields)
+				if err != nil || !isInteger {
+					zap.L().Warn("compare table config index filed isn't single integer data type",
+						zap.String("table", tableCfg.SourceTable),
+						zap.String("index filed", tableCfg.IndexFields))
+					return customColumn, customKeys, customRange, fmt.Errorf("config file index-filed isn't single integer type, error: %v", err)
+				}
+				customColumn = tableCfg.IndexFields
+				return customColumn, customKeys, customRange, nil
+			}
+		}
+	}
+	return customColumn, customKeys, customRange, nil
+}
+
+func (c *Chunk) Split() error {
+	startTime := time.Now()
+
+	// 配置文件参数优先级
+	// onlyCheckRows > configRange > configIndexFiled > DBFilter Integer Column > 整表
+	if c.Cfg.DiffConfig.OnlyCheckRows {
+		c.SourceColumnInfo = "COUNT(1)"
+		c.TargetColumnInfo = "COUNT(1)"
+		c.WhereColumn = ""
+		return c.createSingleChunk("1 = 1")
+	}
+
+	customColumn, _, customRange, err := c.CustomTableConfig()
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(customRange, "") {
+		c.WhereColumn = ""
+		return c.createSingleChunk(customRange)
+	}
+	if !strings.EqualFold(customColumn, "") {
+		c.WhereColumn = customColumn
+	}
+
+	tableRowsByStatistics, err := c.MySQL.GetMySQLTableRowsByStatistics(c.Cfg.MySQLConfig.SchemaName, c.SourceTable)
+	if err != nil {
+		return err
+	}
+	// 统计信息数据行数 0 或者不存在整型切分字段，直接全表扫
+	if tableRowsByStatistics == 0 || strings.EqualFold(c.WhereColumn, "") {
+		zap.L().Warn("get mysql table rows",
+			zap.String("schema", c.Cfg.MySQLConfig.SchemaName),
+			zap.String("table", c.SourceTable),
+			zap.String("where column", c.WhereColumn),
+			zap.String("where", "1 = 1"),
+			zap.Int("statistics rows", tableRowsByStatistics))
+		c.WhereColumn = ""
+		return c.createSingleChunk("1 = 1")
+	}
+
+	minID, maxID, err := c.MySQL.GetMySQLTableColumnBoundary(c.Cfg.MySQLConfig.SchemaName, c.SourceTable, c.WhereColumn)
+	if err != nil {
+		return err
+	}
+	if strings.EqualFold(minID, "NULLABLE") || strings.EqualFold(maxID, "NULLABLE") {
+		zap.L().Warn("get mysql table integer column boundary",
+			zap.String("schema", c.Cfg.MySQLConfig.SchemaName),
+			zap.String("table", c.SourceTable),
+			zap.String("where column", c.WhereColumn),
+			zap.String("where", "1 = 1"))
+		c.WhereColumn = ""
+		return c.createSingleChunk("1 = 1")
+	}
+
+	startID, ok := new(big.Int).SetString(minID, 10)
+	if !ok {
+		return fmt.Errorf("mysql table [%s.%s] column [%s] min value [%s] isn't integer", c.Cfg.MySQLConfig.SchemaName, c.SourceTable, c.WhereColumn, minID)
+	}
+	endID, ok := new(big.Int).SetString(maxID, 10)
+	if !ok {
+		return fmt.Errorf("mysql table [%s.%s] column [%s] max value [%s] isn't integer", c.Cfg.MySQLConfig.SchemaName, c.SourceTable, c.WhereColumn, maxID)
+	}
+	chunkSize := big.NewInt(int64(c.Cfg.DiffConfig.ChunkSize))
+	if chunkSize.Sign() <= 0 {
+		return fmt.Errorf("compare config chunk-size [%d] need be greater than 0", c.Cfg.DiffConfig.ChunkSize)
+	}
+
+	// 整型字段按 chunk-size 值区间切分，与 oracle DBMS_PARALLEL_EXECUTE 按 number 字段切分一致
+	// 防止上游数据少，下游数据多超上游数据边界，追加最小值以下以及最大值以上区间
+	var whereRanges []string
+	whereRanges = append(whereRanges, common.StringsBuilder(c.WhereColumn, " < ", startID.String()))
+	for lower := new(big.Int).Set(startID); lower.Cmp(endID) <= 0; {
+		upper := new(big.Int).Add(lower, chunkSize)
+		if upper.Cmp(endID) > 0 {
+			whereRanges = append(whereRanges, common.StringsBuilder(c.WhereColumn, " >= ", lower.String(), " AND ", c.WhereColumn, " <= ", endID.String()))
+		} else {
+			whereRanges = append(whereRanges, common.StringsBuilder(c.WhereColumn, " >= ", lower.String(), " AND ", c.WhereColumn, " < ", upper.String()))
+		}
+		lower = upper
+	}
+	whereRanges = append(whereRanges, common.StringsBuilder(c.WhereColumn, " > ", endID.String()))
+
+	var fullMetas []meta.DataCompareMeta
+	for _, whereRange := range whereRanges {
+		fullMetas = append(fullMetas, meta.DataCompareMeta{
+			DBTypeS:       c.Cfg.DBTypeS,
+			DBTypeT:       c.Cfg.DBTypeT,
+			SchemaNameS:   common.StringUPPER(c.Cfg.MySQLConfig.SchemaName),
+			TableNameS:    common.StringUPPER(c.SourceTable),
+			SchemaNameT:   common.StringUPPER(c.Cfg.OracleConfig.SchemaName),
+			TableNameT:    common.StringUPPER(c.TargetTable),
+			ColumnDetailS: c.SourceColumnInfo,
+			ColumnDetailT: c.TargetColumnInfo,
+			WhereRange:    whereRange,
+			WhereColumn:   c.WhereColumn,
+			IsPartition:   c.IsPartition,
+			TaskMode:      c.Cfg.TaskMode,
+			TaskStatus:    common.TaskStatusWaiting})
+	}
+
+	// 元数据库信息 batch 写入
+	err = meta.NewCommonModel(c.MetaDB).BatchCreateDataCompareMetaAndUpdateWaitSyncMeta(c.Ctx,
+		fullMetas, c.Cfg.AppConfig.InsertBatchSize, &meta.WaitSyncMeta{
+			DBTypeS:          c.Cfg.DBTypeS,
+			DBTypeT:          c.Cfg.DBTypeT,
+			SchemaNameS:      common.StringUPPER(c.Cfg.MySQLConfig.SchemaName),
+			TableNameS:       common.StringUPPER(c.SourceTable),
+			TaskMode:         c.Cfg.TaskMode,
+			GlobalScnS:       common.TaskTableDefaultSourceGlobalSCN,
+			ChunkTotalNums:   int64(len(fullMetas)),
+			ChunkSuccessNums: 0,
+			ChunkFailedNums:  0,
+			IsPartition:      c.IsPartition,
+		})
+	if err != nil {
+		return fmt.Errorf("create table [%s.%s] data_compare_meta [batch size] failed: %v", c.Cfg.MySQLConfig.SchemaName, c.SourceTable, err)
+	}
+
+	zap.L().Info("pre split mysql and oracle table chunk finished",
+		zap.String("schema", c.Cfg.MySQLConfig.SchemaName),
+		zap.String("table", c.SourceTable),
+		zap.Int("chunks", len(fullMetas)),
+		zap.String("cost", time.Now().Sub(startTime).String()))
+	return nil
+}
+
+// createSingleChunk 整表或者自定义 range 单 chunk
+func (c *Chunk) createSingleChunk(whereRange string) error {
+	c.WhereRange = whereRange
+	return meta.NewCommonModel(c.MetaDB).CreateDataCompareMetaAndUpdateWaitSyncMeta(c.Ctx, &meta.DataCompareMeta{
+		DBTypeS:       c.Cfg.DBTypeS,
+		DBTypeT:       c.Cfg.DBTypeT,
+		SchemaNameS:   common.StringUPPER(c.Cfg.MySQLConfig.SchemaName),
+		TableNameS:    common.StringUPPER(c.SourceTable),
+		ColumnDetailS: c.SourceColumnInfo,
+		SchemaNameT:   common.StringUPPER(c.Cfg.OracleConfig.SchemaName),
+		TableNameT:    common.StringUPPER(c.TargetTable),
+		ColumnDetailT: c.TargetColumnInfo,
+		WhereColumn:   c.WhereColumn,
+		WhereRange:    c.WhereRange,
+		TaskMode:      c.Cfg.TaskMode,
+		TaskStatus:    common.TaskStatusWaiting,
+		IsPartition:   c.IsPartition,
+	}, &meta.WaitSyncMeta{
+		DBTypeS:          c.Cfg.DBTypeS,
+		DBTypeT:          c.Cfg.DBTypeT,
+		SchemaNameS:      common.StringUPPER(c.Cfg.MySQLConfig.SchemaName),
+		TableNameS:       common.StringUPPER(c.SourceTable),
+		TaskMode:         c.Cfg.TaskMode,
+		GlobalScnS:       common.TaskTableDefaultSourceGlobalSCN,
+		ChunkTotalNums:   1,
+		ChunkSuccessNums: 0,
+		ChunkFailedNums:  0,
+		IsPartition:      c.IsPartition,
+	})
+}
+
+func (c *Chunk) String() string {
+	jsonByte, _ := json.Marshal(c)
+	return string(jsonByte)
+}
@@ -0,0 +1,673 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package m2o
+
+import (
+	"context"
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/config"
+	"github.com/wentaojin/transferdb/database/meta"
+	"github.com/wentaojin/transferdb/database/mysql"
+	"github.com/wentaojin/transferdb/database/oracle"
+	"github.com/wentaojin/transferdb/module/compare"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+type M2O struct {
+	ctx    context.Context
+	cfg    *config.Config
+	mysql  *mysql.MySQL
+	oracle *oracle.Oracle
+	metaDB *meta.Meta
+	// checksum 下推是否生效
+	checksumFirst bool
+	// 上游表名大写与实际表名映射，上游表名区分大小写
+	sourceTables map[string]string
+}
+
+func NewCompare(ctx context.Context, cfg *config.Config) (*M2O, error) {
+	mysqlDB, err := mysql.NewMySQLDBEngine(ctx, cfg.MySQLConfig)
+	if err != nil {
+		return nil, err
+	}
+	oracleDB, err := oracle.NewOracleDBEngine(ctx, cfg.OracleConfig)
+	if err != nil {
+		return nil, err
+	}
+	metaDB, err := meta.NewMetaDBEngine(ctx, cfg.MetaConfig, cfg.AppConfig.SlowlogThreshold)
+	if err != nil {
+		return nil, err
+	}
+	return &M2O{
+		ctx:          ctx,
+		cfg:          cfg,
+		mysql:        mysqlDB,
+		oracle:       oracleDB,
+		metaDB:       metaDB,
+		sourceTables: make(map[string]string),
+	}, nil
+}
+
+func (r *M2O) NewCompare() error {
+	startTime := time.Now()
+	zap.L().Info("diff table mysql to oracle start",
+		zap.String("schema", r.cfg.MySQLConfig.SchemaName))
+
+	if err := r.prepareCompareEnv(); err != nil {
+		return err
+	}
+
+	// 获取上游待校验表列表
+	exporters, err := filterCFGTable(r.cfg, r.mysql)
+	if err != nil {
+		return err
+	}
+	if len(exporters) == 0 {
+		zap.L().Warn("there are no table objects in the mysql schema",
+			zap.String("schema", r.cfg.MySQLConfig.SchemaName))
+		return nil
+	}
+	var upperTables []string
+	for _, t := range exporters {
+		r.sourceTables[common.StringUPPER(t)] = t
+		upperTables = append(upperTables, common.StringUPPER(t))
+	}
+
+	sourceSchema := common.StringUPPER(r.cfg.MySQLConfig.SchemaName)
+
+	// 关于全量断点恢复
+	if !r.cfg.DiffConfig.EnableCheckpoint {
+		err = meta.NewDataCompareMetaModel(r.metaDB).TruncateDataCompareMeta(r.ctx)
+		if err != nil {
+			return err
+		}
+		err = meta.NewDataCompareSummaryModel(r.metaDB).TruncateDataCompareSummary(r.ctx)
+		if err != nil {
+			return err
+		}
+		for _, tableName := range upperTables {
+			err = meta.NewWaitSyncMetaModel(r.metaDB).DeleteWaitSyncMeta(r.ctx, &meta.WaitSyncMeta{
+				DBTypeS:     r.cfg.DBTypeS,
+				DBTypeT:     r.cfg.DBTypeT,
+				SchemaNameS: sourceSchema,
+				TableNameS:  tableName,
+				TaskMode:    r.cfg.TaskMode,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	// 清理非当前任务 SUCCESS 表元数据记录 wait_sync_meta (用于统计 SUCCESS 准备)
+	tablesByMeta, err := meta.NewWaitSyncMetaModel(r.metaDB).DetailWaitSyncMetaSuccessTables(r.ctx, &meta.WaitSyncMeta{
+		DBTypeS:     r.cfg.DBTypeS,
+		DBTypeT:     r.cfg.DBTypeT,
+		SchemaNameS: sourceSchema,
+		TaskMode:    r.cfg.TaskMode,
+		TaskStatus:  common.TaskStatusSuccess,
+	})
+	if err != nil {
+		return err
+	}
+	clearTables := common.FilterDifferenceStringItems(tablesByMeta, upperTables)
+	if len(clearTables) > 0 {
+		err = meta.NewWaitSyncMetaModel(r.metaDB).DeleteWaitSyncMetaSuccessTables(r.ctx, &meta.WaitSyncMeta{
+			DBTypeS:     r.cfg.DBTypeS,
+			DBTypeT:     r.cfg.DBTypeT,
+			SchemaNameS: sourceSchema,
+			TaskMode:    r.cfg.TaskMode,
+			TaskStatus:  common.TaskStatusSuccess,
+		}, clearTables)
+		if err != nil {
+			return err
+		}
+	}
+
+	// 判断 [wait_sync_meta] 是否存在错误记录，是否可进行 COMPARE
+	errTotals, err := meta.NewWaitSyncMetaModel(r.metaDB).CountsErrWaitSyncMetaBySchema(r.ctx, &meta.WaitSyncMeta{
+		DBTypeS:     r.cfg.DBTypeS,
+		DBTypeT:     r.cfg.DBTypeT,
+		SchemaNameS: sourceSchema,
+		TaskMode:    r.cfg.TaskMode,
+		TaskStatus:  common.TaskStatusFailed,
+	})
+	if err != nil {
+		return err
+	}
+	if errTotals > 0 {
+		return fmt.Errorf(`compare schema [%s] mode [%s] table task failed: meta table [wait_sync_meta] exist failed error, please: firstly check meta table [wait_sync_meta] and [data_compare_meta] log record; secondly if need resume, update meta table [wait_sync_meta] column [task_status] table status RUNNING (Need UPPER); finally rerunning`, sourceSchema, r.cfg.TaskMode)
+	}
+
+	// 判断并记录待校验表列表
+	for _, tableName := range upperTables {
+		waitSyncMetas, err := meta.NewWaitSyncMetaModel(r.metaDB).DetailWaitSyncMeta(r.ctx, &meta.WaitSyncMeta{
+			DBTypeS:     r.cfg.DBTypeS,
+			DBTypeT:     r.cfg.DBTypeT,
+			SchemaNameS: sourceSchema,
+			TableNameS:  tableName,
+			TaskMode:    r.cfg.TaskMode,
+		})
+		if err != nil {
+			return err
+		}
+		if len(waitSyncMetas) == 0 {
+			err = meta.NewWaitSyncMetaModel(r.metaDB).CreateWaitSyncMeta(r.ctx, &meta.WaitSyncMeta{
+				DBTypeS:        r.cfg.DBTypeS,
+				DBTypeT:        r.cfg.DBTypeT,
+				SchemaNameS:    sourceSchema,
+				TableNameS:     tableName,
+				TaskMode:       r.cfg.TaskMode,
+				TaskStatus:     common.TaskStatusWaiting,
+				GlobalScnS:     common.TaskTableDefaultSourceGlobalSCN,
+				ChunkTotalNums: common.TaskTableDefaultSplitChunkNums,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	// 获取等待校验以及未校验完成的表列表
+	var waitSyncTables, partSyncTables, panicTables []string
+	waitSyncMetas, err := meta.NewWaitSyncMetaModel(r.metaDB).DetailWaitSyncMeta(r.ctx, &meta.WaitSyncMeta{
+		DBTypeS:        r.cfg.DBTypeS,
+		DBTypeT:        r.cfg.DBTypeT,
+		SchemaNameS:    sourceSchema,
+		TaskMode:       r.cfg.TaskMode,
+		TaskStatus:     common.TaskStatusWaiting,
+		GlobalScnS:     common.TaskTableDefaultSourceGlobalSCN,
+		ChunkTotalNums: common.TaskTableDefaultSplitChunkNums,
+	})
+	if err != nil {
+		return err
+	}
+	for _, t := range waitSyncMetas {
+		waitSyncTables = append(waitSyncTables, common.StringUPPER(t.TableNameS))
+	}
+
+	partWaitSyncMetas, err := meta.NewWaitSyncMetaModel(r.metaDB).QueryWaitSyncMetaByPartTask(r.ctx, &meta.WaitSyncMeta{
+		DBTypeS:     r.cfg.DBTypeS,
+		DBTypeT:     r.cfg.DBTypeT,
+		SchemaNameS: sourceSchema,
+		TaskMode:    r.cfg.TaskMode,
+		TaskStatus:  common.TaskStatusRunning,
+	})
+	if err != nil {
+		return err
+	}
+	for _, t := range partWaitSyncMetas {
+		// 判断 running 状态表 chunk 数是否一致，一致可断点续传
+		chunkCounts, err := meta.NewDataCompareMetaModel(r.metaDB).CountsDataCompareMetaByTaskTable(r.ctx, &meta.DataCompareMeta{
+			DBTypeS:     t.DBTypeS,
+			DBTypeT:     t.DBTypeT,
+			SchemaNameS: t.SchemaNameS,
+			TableNameS:  t.TableNameS,
+			TaskMode:    t.TaskMode,
+		})
+		if err != nil {
+			return err
+		}
+		if chunkCounts != t.ChunkTotalNums {
+			panicTables = append(panicTables, common.StringUPPER(t.TableNameS))
+		} else {
+			partSyncTables = append(partSyncTables, common.StringUPPER(t.TableNameS))
+		}
+	}
+	if len(panicTables) > 0 {
+		zap.L().Error("all mysql table data compare error",
+			zap.String("schema", r.cfg.MySQLConfig.SchemaName),
+			zap.String("cost", time.Now().Sub(startTime).String()),
+			zap.Int("part sync tables", len(partSyncTables)),
+			zap.Strings("panic tables", panicTables))
+		return fmt.Errorf("checkpoint isn't consistent, can't be resume, please reruning [enable-checkpoint = fase]")
+	}
+
+	// 表名自定义规则
+	tableNameRules, err := meta.NewTableNameRuleModel(r.metaDB).DetailTableNameRule(r.ctx, &meta.TableNameRule{
+		DBTypeS:     r.cfg.DBTypeS,
+		DBTypeT:     r.cfg.DBTypeT,
+		SchemaNameS: r.cfg.MySQLConfig.SchemaName,
+		SchemaNameT: r.cfg.OracleConfig.SchemaName,
+	})
+	if err != nil {
+		return err
+	}
+	tableNameRuleMap := make(map[string]string)
+	for _, tr := range tableNameRules {
+		tableNameRuleMap[common.StringUPPER(tr.TableNameS)] = common.StringUPPER(tr.TableNameT)
+	}
+
+	partTableTasks := NewCompareTableTask(r.ctx, r.cfg, r.actualTables(partSyncTables), r.mysql, r.oracle, tableNameRuleMap)
+	waitTableTasks := NewCompareTableTask(r.ctx, r.cfg, r.actualTables(waitSyncTables), r.mysql, r.oracle, tableNameRuleMap)
+
+	// 判断下游是否存在 oracle 表
+	oracleTables, err := r.oracle.GetOracleSchemaTable(common.StringUPPER(r.cfg.OracleConfig.SchemaName))
+	if err != nil {
+		return err
+	}
+	var targetTables []string
+	for _, t := range append(partTableTasks, waitTableTasks...) {
+		targetTables = append(targetTables, t.targetTableName)
+	}
+	diffItems := common.FilterDifferenceStringItems(targetTables, oracleTables)
+	if len(diffItems) != 0 {
+		return fmt.Errorf("table [%v] target db isn't exists, please create table", diffItems)
+	}
+
+	// 数据对比
+	err = common.PathExist(r.cfg.DiffConfig.FixSqlDir)
+	if err != nil {
+		return err
+	}
+	checkFile := filepath.Join(r.cfg.DiffConfig.FixSqlDir, fmt.Sprintf("compare_%s.sql", r.cfg.MySQLConfig.SchemaName))
+	f, err := compare.NewWriter(checkFile)
+	if err != nil {
+		return err
+	}
+
+	// 优先存在断点的表校验
+	// partTableTask -> waitTableTasks
+	if len(partTableTasks) > 0 {
+		err = r.comparePartTableTasks(f, partTableTasks)
+		if err != nil {
+			return err
+		}
+	}
+	if len(waitTableTasks) > 0 {
+		err = r.compareWaitTableTasks(f, waitTableTasks)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err = f.Close(); err != nil {
+		return err
+	}
+
+	// 任务详情
+	succTotals, err := meta.NewWaitSyncMetaModel(r.metaDB).DetailWaitSyncMeta(r.ctx, &meta.WaitSyncMeta{
+		DBTypeS:     r.cfg.DBTypeS,
+		DBTypeT:     r.cfg.DBTypeT,
+		SchemaNameS: sourceSchema,
+		TaskMode:    r.cfg.TaskMode,
+		TaskStatus:  common.TaskStatusSuccess,
+	})
+	if err != nil {
+		return err
+	}
+	failedTotals, err := meta.NewWaitSyncMetaModel(r.metaDB).DetailWaitSyncMeta(r.ctx, &meta.WaitSyncMeta{
+		DBTypeS:     r.cfg.DBTypeS,
+		DBTypeT:     r.cfg.DBTypeT,
+		SchemaNameS: sourceSchema,
+		TaskMode:    r.cfg.TaskMode,
+		TaskStatus:  common.TaskStatusFailed,
+	})
+	if err != nil {
+		return err
+	}
+
+	// 数据校验汇总报告
+	summary, err := compare.GenCompareSummary(r.ctx, r.metaDB, r.cfg.DBTypeS, r.cfg.DBTypeT, sourceSchema, r.cfg.OracleConfig.SchemaName, r.cfg.TaskMode, startTime, checkFile)
+	if err != nil {
+		return err
+	}
+	if err = compare.WriteCompareSummary(summary, r.cfg.DiffConfig.FixSqlDir); err != nil {
+		return err
+	}
+
+	zap.L().Info("compare", zap.String("fix sql file output", checkFile))
+	if len(failedTotals) == 0 {
+		zap.L().Info("compare table mysql to oracle finished",
+			zap.Int("table totals", len(exporters)),
+			zap.Int("table success", len(succTotals)),
+			zap.Int("table failed", len(failedTotals)),
+			zap.String("cost", time.Now().Sub(startTime).String()))
+	} else {
+		zap.L().Warn("compare table mysql to oracle finished",
+			zap.Int("table totals", len(exporters)),
+			zap.Int("table success", len(succTotals)),
+			zap.Int("table failed", len(failedTotals)),
+			zap.String("failed tips", "failed detail, please see table [data_compare_meta]"),
+			zap.String("cost", time.Now().Sub(startTime).String()))
+	}
+	return nil
+}
+
+// prepareCompareEnv 校验 oracle 版本以及字符集确定 checksum 下推，mysql -> oracle 暂不支持的校验参数直接报错
+func (r *M2O) prepareCompareEnv() error {
+	if r.cfg.DiffConfig.SamplePercent != 0 || r.cfg.DiffConfig.SnapshotCompare || r.cfg.DiffConfig.Repair || r.cfg.DiffConfig.Recheck {
+		return fmt.Errorf("compare config sample-percent, snapshot-compare, repair and recheck aren't support mysql to oracle, please disable")
+	}
+	// 字段名映射规则暂不支持，上下游按相同字段名对比，存在规则直接报错避免误判差异
+	columnNameRules, err := meta.NewColumnNameRuleModel(r.metaDB).DetailColumnNameRule(r.ctx, &meta.ColumnNameRule{
+		DBTypeS:     r.cfg.DBTypeS,
+		DBTypeT:     r.cfg.DBTypeT,
+		SchemaNameS: r.cfg.MySQLConfig.SchemaName,
+	})
+	if err != nil {
+		return err
+	}
+	if len(columnNameRules) > 0 {
+		return fmt.Errorf("mysql schema [%s] column name rules aren't support mysql to oracle compare, please delete meta table [column_name_rule] records", r.cfg.MySQLConfig.SchemaName)
+	}
+
+	oraDBVersion, err := r.oracle.GetOracleDBVersion()
+	if err != nil {
+		return err
+	}
+	oracleDBCharacterSet, err := r.oracle.GetOracleDBCharacterSet()
+	if err != nil {
+		return err
+	}
+	// checksum 下推要求 oracle 12c 及以上 STANDARD_HASH，且数据库字符集与上游 utf8mb4 字节一致
+	if r.cfg.DiffConfig.ChecksumFirst && !r.cfg.DiffConfig.OnlyCheckRows {
+		oraCharset := common.StringOracleCharacterSet(oracleDBCharacterSet)
+		if common.VersionOrdinal(oraDBVersion) >= common.VersionOrdinal(common.OracleStandardHashDBVersion) &&
+			(strings.EqualFold(oraCharset, common.BuildInOracleCharacterSetAL32UTF8) || strings.EqualFold(oraCharset, "UTF8")) {
+			r.checksumFirst = true
+		} else {
+			zap.L().Warn("oracle db version or character set isn't support checksum first, fallback data rows compare",
+				zap.String("db version", oraDBVersion),
+				zap.String("db character", oracleDBCharacterSet))
+		}
+	}
+	zap.L().Info("get oracle db character and version finished",
+		zap.String("schema", r.cfg.OracleConfig.SchemaName),
+		zap.String("db version", oraDBVersion),
+		zap.String("db character", oracleDBCharacterSet),
+		zap.Bool("checksum first", r.checksumFirst))
+	return nil
+}
+
+// actualTables 上游表名大写转换实际表名
+func (r *M2O) actualTables(upperTables []string) []string {
+	var tables []string
+	for _, t := range upperTables {
+		if val, ok := r.sourceTables[t]; ok {
+			tables = append(tables, val)
+		} else {
+			tables = append(tables, t)
+		}
+	}
+	return tables
+}
+
+func (r *M2O) comparePartTableTasks(f *compare.File, partTableTasks []*Task) error {
+	sourceSchema := common.StringUPPER(r.cfg.MySQLConfig.SchemaName)
+	for _, task := range partTableTasks {
+		diffStartTime := time.Now()
+		tableNameS := common.StringUPPER(task.sourceTableName)
+
+		err := meta.NewWaitSyncMetaModel(r.metaDB).UpdateWaitSyncMeta(r.ctx, &meta.WaitSyncMeta{
+			DBTypeS:     r.cfg.DBTypeS,
+			DBTypeT:     r.cfg.DBTypeT,
+			SchemaNameS: sourceSchema,
+			TableNameS:  tableNameS,
+			TaskMode:    r.cfg.TaskMode,
+		}, map[string]interface{}{
+			"TaskStatus": common.TaskStatusRunning,
+		})
+		if err != nil {
+			return err
+		}
+
+		var waitCompareMetas []meta.DataCompareMeta
+		for _, status := range []string{common.TaskStatusWaiting, common.TaskStatusFailed} {
+			compareMetas, err := meta.NewDataCompareMetaModel(r.metaDB).DetailDataCompareMeta(r.ctx, &meta.DataCompareMeta{
+				DBTypeS:     r.cfg.DBTypeS,
+				DBTypeT:     r.cfg.DBTypeT,
+				SchemaNameS: sourceSchema,
+				TableNameS:  tableNameS,
+				TaskMode:    r.cfg.TaskMode,
+				TaskStatus:  status,
+			})
+			if err != nil {
+				return err
+			}
+			waitCompareMetas = append(waitCompareMetas, compareMetas...)
+		}
+
+		// 数据行格式化以及 oracle 修复 SQL 字面量字段类别，数值字段 numeric-epsilon 容差
+		// 存在容差字段数据库端 checksum 无法容差，不使用 checksum 下推
+		// 修复 SQL 以不应用字段校验规则的修复字段原始值生成，数据行以主键/唯一键定位
+		var (
+			columnKinds            []string
+			columnEpsilons         []float64
+			fixColumnS, fixColumnT string
+			fixKeyIndex            []int
+			fixByKey               bool
+		)
+		checksumFirst := r.checksumFirst
+		if !r.cfg.DiffConfig.OnlyCheckRows {
+			columnKinds, err = task.FilterDBColumnKind()
+			if err != nil {
+				return err
+			}
+			fixColumnS, fixColumnT, err = task.AdjustDBFixColumn()
+			if err != nil {
+				return err
+			}
+			fixKeyIndex, fixByKey, err = task.FilterDBFixKeyIndex()
+			if err != nil {
+				return err
+			}
+			columnEpsilons, err = task.FilterDBColumnEpsilon()
+			if err != nil {
+				return err
+			}
+			if checksumFirst && common.CompareEpsilonEnabled(columnEpsilons) {
+				checksumFirst = false
+				zap.L().Warn("mysql table column numeric-epsilon is set, disable checksum first, fallback data rows compare",
+					zap.String("schema", r.cfg.MySQLConfig.SchemaName),
+					zap.String("table", task.sourceTableName))
+			}
+		}
+
+		g := &errgroup.Group{}
+		g.SetLimit(r.cfg.DiffConfig.DiffThreads)
+
+		for _, compareMeta := range waitCompareMetas {
+			newReport := NewReport(compareMeta, r.cfg.MySQLConfig.SchemaName, task.sourceTableName, r.mysql, r.oracle,
+				r.cfg.DiffConfig.OnlyCheckRows, checksumFirst, columnKinds)
+			newReport.ColumnEpsilons = columnEpsilons
+			newReport.FixColumnS, newReport.FixColumnT = fixColumnS, fixColumnT
+			newReport.FixKeyIndex, newReport.FixByKey = fixKeyIndex, fixByKey
+			g.Go(func() error {
+				updates := map[string]interface{}{
+					"TaskStatus": common.TaskStatusSuccess,
+				}
+				report, err := IReport(newReport)
+				switch {
+				case err != nil:
+					// error skip, continue
+					updates["TaskStatus"] = common.TaskStatusFailed
+					updates["InfoDetail"] = newReport.String()
+					updates["ErrorDetail"] = err.Error()
+				case !strings.EqualFold(report, ""):
+					errMsg := common.CompareChunkDiffError
+					if _, err = f.CWriteString(report); err != nil {
+						errMsg = fmt.Sprintf("fix sql file write failed: %v", err.Error())
+					}
+					updates["TaskStatus"] = common.TaskStatusFailed
+					updates["InfoDetail"] = newReport.String()
+					updates["ErrorDetail"] = errMsg
+				}
+				updates["ChunkRowsS"] = newReport.MySQLRows
+				updates["ChunkRowsT"] = newReport.OracleRows
+
+				return meta.NewDataCompareMetaModel(r.metaDB).UpdateDataCompareMeta(r.ctx, &meta.DataCompareMeta{
+					DBTypeS:     newReport.DataCompareMeta.DBTypeS,
+					DBTypeT:     newReport.DataCompareMeta.DBTypeT,
+					SchemaNameS: newReport.DataCompareMeta.SchemaNameS,
+					TableNameS:  newReport.DataCompareMeta.TableNameS,
+					TaskMode:    newReport.DataCompareMeta.TaskMode,
+					WhereRange:  newReport.DataCompareMeta.WhereRange,
+				}, updates)
+			})
+		}
+
+		if err = g.Wait(); err != nil {
+			return fmt.Errorf("compare table task failed, update table [data_compare_meta] failed: %v", err)
+		}
+
+		// 更新 wait_sync_meta 记录
+		failedTotalErrs, err := meta.NewDataCompareMetaModel(r.metaDB).CountsErrorDataCompareMeta(r.ctx, &meta.DataCompareMeta{
+			DBTypeS:     r.cfg.DBTypeS,
+			DBTypeT:     r.cfg.DBTypeT,
+			SchemaNameS: sourceSchema,
+			TableNameS:  tableNameS,
+			TaskMode:    r.cfg.TaskMode,
+			TaskStatus:  common.TaskStatusFailed,
+		})
+		if err != nil {
+			return fmt.Errorf("get meta table [data_compare_meta] counts failed, error: %v", err)
+		}
+		successTotalErrs, err := meta.NewDataCompareMetaModel(r.metaDB).CountsErrorDataCompareMeta(r.ctx, &meta.DataCompareMeta{
+			DBTypeS:     r.cfg.DBTypeS,
+			DBTypeT:     r.cfg.DBTypeT,
+			SchemaNameS: sourceSchema,
+			TableNameS:  tableNameS,
+			TaskMode:    r.cfg.TaskMode,
+			TaskStatus:  common.TaskStatusSuccess,
+		})
+		if err != nil {
+			return fmt.Errorf("get meta table [data_compare_meta] counts failed, error: %v", err)
+		}
+
+		// 不存在错误，汇总 data_compare_meta 记录写入 data_compare_summary，清理 data_compare_meta 记录, 更新 wait_sync_meta 记录
+		if failedTotalErrs == 0 {
+			tableSummary, err := compare.GenCompareTableSummary(r.ctx, r.metaDB, r.cfg.DBTypeS, r.cfg.DBTypeT, sourceSchema, tableNameS, r.cfg.TaskMode)
+			if err != nil {
+				return err
+			}
+			err = meta.NewCommonModel(r.metaDB).DeleteTableDataCompareMetaAndUpdateWaitSyncMeta(r.ctx,
+				&meta.DataCompareMeta{
+					DBTypeS:     r.cfg.DBTypeS,
+					DBTypeT:     r.cfg.DBTypeT,
+					SchemaNameS: sourceSchema,
+					TableNameS:  tableNameS,
+					TaskMode:    r.cfg.TaskMode,
+				}, tableSummary, &meta.WaitSyncMeta{
+					DBTypeS:          r.cfg.DBTypeS,
+					DBTypeT:          r.cfg.DBTypeT,
+					SchemaNameS:      sourceSchema,
+					TableNameS:       tableNameS,
+					TaskMode:         r.cfg.TaskMode,
+					TaskStatus:       common.TaskStatusSuccess,
+					ChunkSuccessNums: successTotalErrs,
+					ChunkFailedNums:  0,
+				})
+			if err != nil {
+				return err
+			}
+			zap.L().Info("diff single table mysql to oracle finished",
+				zap.String("schema", r.cfg.MySQLConfig.SchemaName),
+				zap.String("table", task.sourceTableName),
+				zap.String("cost", time.Now().Sub(diffStartTime).String()))
+			continue
+		}
+
+		// 若存在错误，修改表状态，skip 清理，统一忽略，最后显示
+		err = meta.NewWaitSyncMetaModel(r.metaDB).UpdateWaitSyncMeta(r.ctx, &meta.WaitSyncMeta{
+			DBTypeS:     r.cfg.DBTypeS,
+			DBTypeT:     r.cfg.DBTypeT,
+			SchemaNameS: sourceSchema,
+			TableNameS:  tableNameS,
+			TaskMode:    r.cfg.TaskMode,
+		}, map[string]interface{}{
+			"TaskStatus":       common.TaskStatusFailed,
+			"ChunkSuccessNums": successTotalErrs,
+			"ChunkFailedNums":  failedTotalErrs,
+		})
+		if err != nil {
+			return err
+		}
+		zap.L().Warn("update mysql [wait_sync_meta] meta",
+			zap.String("schema", r.cfg.MySQLConfig.SchemaName),
+			zap.String("table", task.sourceTableName),
+			zap.String("mode", r.cfg.TaskMode),
+			zap.String("updated", "table check exist error, skip"),
+			zap.String("cost", time.Now().Sub(diffStartTime).String()))
+	}
+	return nil
+}
+
+func (r *M2O) compareWaitTableTasks(f *compare.File, waitTableTasks []*Task) error {
+	var chunks []*Chunk
+	for cid, task := range waitTableTasks {
+		err := meta.NewDataCompareMetaModel(r.metaDB).DeleteDataCompareMetaByTable(r.ctx, &meta.DataCompareMeta{
+			DBTypeS:     r.cfg.DBTypeS,
+			DBTypeT:     r.cfg.DBTypeT,
+			SchemaNameS: common.StringUPPER(r.cfg.MySQLConfig.SchemaName),
+			TableNameS:  common.StringUPPER(task.sourceTableName),
+			TaskMode:    r.cfg.TaskMode,
+		})
+		if err != nil {
+			return err
+		}
+		err = meta.NewDataCompareSummaryModel(r.metaDB).DeleteDataCompareSummaryByTable(r.ctx, &meta.DataCompareSummary{
+			DBTypeS:     r.cfg.DBTypeS,
+			DBTypeT:     r.cfg.DBTypeT,
+			SchemaNameS: common.StringUPPER(r.cfg.MySQLConfig.SchemaName),
+			TableNameS:  common.StringUPPER(task.sourceTableName),
+			TaskMode:    r.cfg.TaskMode,
+		})
+		if err != nil {
+			return err
+		}
+		sourceColumnInfo, targetColumnInfo, err := task.AdjustDBSelectColumn()
+		if err != nil {
+			return err
+		}
+		// 整型切分字段不存在，整表单 chunk 对比
+		whereColumn, err := task.FilterDBWhereColumn()
+		if err != nil {
+			return err
+		}
+		if strings.EqualFold(whereColumn, "") {
+			zap.L().Warn("mysql table pk/uk integer column isn't exist, compare whole table by single chunk",
+				zap.String("schema", r.cfg.MySQLConfig.SchemaName),
+				zap.String("table", task.sourceTableName))
+		}
+		isPartition, err := task.IsPartitionTable()
+		if err != nil {
+			return err
+		}
+		chunks = append(chunks, NewChunk(r.ctx, r.cfg, r.oracle, r.mysql, r.metaDB,
+			cid, task.sourceTableName, task.targetTableName, isPartition, sourceColumnInfo, targetColumnInfo, whereColumn))
+	}
+
+	// chunk split
+	g := &errgroup.Group{}
+	g.SetLimit(r.cfg.DiffConfig.DiffThreads)
+	for _, chunk := range chunks {
+		c := chunk
+		g.Go(func() error {
+			return IChunker(c)
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return err
+	}
+
+	return r.comparePartTableTasks(f, waitTableTasks)
+}
@@ -0,0 +1,89 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package m2o
+
+import (
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/config"
+	"github.com/wentaojin/transferdb/database/mysql"
+	"github.com/wentaojin/transferdb/filter"
+	"go.uber.org/zap"
+	"time"
+)
+
+func filterCFGTable(cfg *config.Config, mysql *mysql.MySQL) ([]string, error) {
+	startTime := time.Now()
+	var (
+		exporterTableSlice []string
+		excludeTables      []string
+	)
+	ok, err := mysql.IsExistMySQLSchema(cfg.MySQLConfig.SchemaName)
+	if err != nil {
+		return []string{}, err
+	}
+	if !ok {
+		return []string{}, fmt.Errorf("filter cfg mysql schema [%v] tables isn't exists", cfg.MySQLConfig.SchemaName)
+	}
+
+	// 获取 mysql 所有数据表
+	allTables, err := mysql.GetMySQLNormalTable(cfg.MySQLConfig.SchemaName)
+	if err != nil {
+		return allTables, err
+	}
+
+	switch {
+	case len(cfg.MySQLConfig.IncludeTable) != 0 && len(cfg.MySQLConfig.ExcludeTable) == 0:
+		f, err := filter.Parse(cfg.MySQLConfig.IncludeTable)
+		if err != nil {
+			return exporterTableSlice, fmt.Errorf("mysql config params include-table parse failed: %v", err)
+		}
+		for _, t := range allTables {
+			if f.MatchTable(t) {
+				exporterTableSlice = append(exporterTableSlice, t)
+			}
+		}
+	case len(cfg.MySQLConfig.IncludeTable) == 0 && len(cfg.MySQLConfig.ExcludeTable) != 0:
+		f, err := filter.Parse(cfg.MySQLConfig.ExcludeTable)
+		if err != nil {
+			return exporterTableSlice, fmt.Errorf("mysql config params exclude-table parse failed: %v", err)
+		}
+		for _, t := range allTables {
+			if f.MatchTable(t) {
+				excludeTables = append(excludeTables, t)
+			}
+		}
+		exporterTableSlice = common.FilterDifferenceStringItems(allTables, excludeTables)
+	case len(cfg.MySQLConfig.IncludeTable) == 0 && len(cfg.MySQLConfig.ExcludeTable) == 0:
+		exporterTableSlice = allTables
+	default:
+		return exporterTableSlice, fmt.Errorf("mysql config params include-table/exclude-table cannot exist at the same time")
+	}
+
+	if len(exporterTableSlice) == 0 && (len(cfg.MySQLConfig.IncludeTable) != 0 || len(cfg.MySQLConfig.ExcludeTable) != 0) {
+		return exporterTableSlice, fmt.Errorf("exporter tables aren't exist, please check mysql config params include-table/exclude-table")
+	}
+
+	endTime := time.Now()
+	zap.L().Info("get mysql to oracle all tables",
+		zap.String("schema", cfg.MySQLConfig.SchemaName),
+		zap.Strings("exporter tables list", exporterTableSlice),
+		zap.Int("include table counts", len(exporterTableSlice)),
+		zap.Int("exclude table counts", len(excludeTables)),
+		zap.Int("all table counts", len(allTables)),
+		zap.String("cost", endTime.Sub(startTime).String()))
+	return exporterTableSlice, nil
+}
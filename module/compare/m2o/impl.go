@@ -0,0 +1,34 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package m2o
+
+import "github.com/wentaojin/transferdb/module/compare"
+
+func IChunker(c compare.Chunker) error {
+	err := c.Split()
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func IReport(r compare.Reporter) (string, error) {
+	resp, err := r.Report()
+	if err != nil {
+		return resp, err
+	}
+	return resp, nil
+}
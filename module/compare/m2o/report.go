@@ -0,0 +1,548 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package m2o
+
+import (
+	"context"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/shopspring/decimal"
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/database/meta"
+	"github.com/wentaojin/transferdb/database/mysql"
+	"github.com/wentaojin/transferdb/database/oracle"
+	"github.com/wentaojin/transferdb/module/compare"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+	"hash/crc32"
+	"sort"
+	"strings"
+)
+
+// DBSummary 数据行格式化值，以格式化数据行字符串为键，重复数据行按出现次数计数
+type DBSummary struct {
+	Columns   []string
+	Rows      int64
+	RowCounts map[string]int
+	RowValues map[string][]string
+	// FixRows 对比数据行对应修复字段值，重复数据行按读取顺序逐行记录
+	FixRows  map[string][][]string
+	Crc32Val uint32
+}
+
+type Report struct {
+	DataCompareMeta meta.DataCompareMeta `json:"data_compare_meta"`
+	SourceSchema    string               `json:"source_schema"`
+	SourceTable     string               `json:"source_table"`
+	Mysql           *mysql.MySQL         `json:"-"`
+	Oracle          *oracle.Oracle       `json:"-"`
+	OnlyCheckRows   bool                 `json:"only_check_rows"`
+	ChecksumFirst   bool                 `json:"checksum_first"`
+	ColumnKinds     []string             `json:"column_kinds"`
+	MySQLRows       int64                `json:"mysql_rows"`
+	OracleRows      int64                `json:"oracle_rows"`
+	// 对比字段 numeric-epsilon 容差，与 ColumnKinds 字段顺序一致，数据行对比按 ABS(a-b) <= epsilon 判断
+	ColumnEpsilons []float64 `json:"column_epsilons"`
+	// 修复 SQL 数据行定位字段下标，FixByKey 为 true 代表主键/唯一键字段，否则为非 LOB 字段整行定位
+	FixKeyIndex []int `json:"fix_key_index"`
+	FixByKey    bool  `json:"fix_by_key"`
+	// 修复字段，字段以及顺序与对比字段一致，不应用字段校验规则
+	FixColumnS string `json:"-"`
+	FixColumnT string `json:"-"`
+}
+
+func NewReport(dataCompareMeta meta.DataCompareMeta, sourceSchema, sourceTable string, mysql *mysql.MySQL, oracle *oracle.Oracle, onlyCheckRows, checksumFirst bool, columnKinds []string) *Report {
+	return &Report{
+		DataCompareMeta: dataCompareMeta,
+		SourceSchema:    sourceSchema,
+		SourceTable:     sourceTable,
+		Mysql:           mysql,
+		Oracle:          oracle,
+		OnlyCheckRows:   onlyCheckRows,
+		ChecksumFirst:   checksumFirst,
+		ColumnKinds:     columnKinds,
+	}
+}
+
+// MySQLTableName 上游库名、表名区分大小写，以上游实际库名、表名查询
+func (r *Report) MySQLTableName() string {
+	return common.StringsBuilder(r.SourceSchema, ".", r.SourceTable)
+}
+
+// OracleTableName 下游 oracle 表
+func (r *Report) OracleTableName() string {
+	return common.StringsBuilder(r.DataCompareMeta.SchemaNameT, ".", r.DataCompareMeta.TableNameT)
+}
+
+func (r *Report) GenDBQuery() (oracleQuery string, mysqlQuery string) {
+	oracleQuery = common.StringsBuilder(
+		"SELECT ", r.DataCompareMeta.ColumnDetailT, " FROM ", r.OracleTableName(), " WHERE ", r.DataCompareMeta.WhereRange)
+	mysqlQuery = common.StringsBuilder(
+		"SELECT ", r.DataCompareMeta.ColumnDetailS, " FROM ", r.MySQLTableName(), " WHERE ", r.DataCompareMeta.WhereRange)
+	return
+}
+
+// GenDBFixQuery 数据行对比查询追加修复字段，用于差异数据行生成修复 SQL
+func (r *Report) GenDBFixQuery() (oracleQuery string, mysqlQuery string) {
+	oracleQuery = common.StringsBuilder(
+		"SELECT ", r.oracleSelectColumn(), " FROM ", r.OracleTableName(), " WHERE ", r.DataCompareMeta.WhereRange)
+	mysqlQuery = common.StringsBuilder(
+		"SELECT ", r.mysqlSelectColumn(), " FROM ", r.MySQLTableName(), " WHERE ", r.DataCompareMeta.WhereRange)
+	return
+}
+
+// mysqlWithFix 字段校验规则改变源端对比字段值，查询需追加修复字段
+func (r *Report) mysqlWithFix() bool {
+	return r.FixColumnS != "" && !strings.EqualFold(r.FixColumnS, r.DataCompareMeta.ColumnDetailS)
+}
+
+// oracleWithFix 字段校验规则改变目标端对比字段值，查询需追加修复字段
+func (r *Report) oracleWithFix() bool {
+	return r.FixColumnT != "" && !strings.EqualFold(r.FixColumnT, r.DataCompareMeta.ColumnDetailT)
+}
+
+// mysqlSelectColumn 源端对比字段，需要时追加修复字段
+func (r *Report) mysqlSelectColumn() string {
+	if r.mysqlWithFix() {
+		return common.StringsBuilder(r.DataCompareMeta.ColumnDetailS, ",", r.FixColumnS)
+	}
+	return r.DataCompareMeta.ColumnDetailS
+}
+
+// oracleSelectColumn 目标端对比字段，需要时追加修复字段
+func (r *Report) oracleSelectColumn() string {
+	if r.oracleWithFix() {
+		return common.StringsBuilder(r.DataCompareMeta.ColumnDetailT, ",", r.FixColumnT)
+	}
+	return r.DataCompareMeta.ColumnDetailT
+}
+
+// GenDBChecksumQuery chunk checksum 子查询，无需排序
+func (r *Report) GenDBChecksumQuery() (oracleQuery string, mysqlQuery string) {
+	return r.GenDBQuery()
+}
+
+func (r *Report) CheckOracleRows(oracleQuery string) (int64, error) {
+	rows, err := r.Oracle.GetOracleTableActualRows(oracleQuery)
+	if err != nil {
+		return rows, err
+	}
+	return rows, nil
+}
+
+func (r *Report) CheckMySQLRows(mysqlQuery string) (int64, error) {
+	rows, err := r.Mysql.GetMySQLTableActualRows(mysqlQuery)
+	if err != nil {
+		return rows, err
+	}
+	return rows, nil
+}
+
+func (r *Report) ReportCheckRows() (string, error) {
+	oracleQuery, mysqlQuery := r.GenDBQuery()
+	g := &errgroup.Group{}
+
+	var mysqlRows, oracleRows int64
+	g.Go(func() error {
+		rows, err := r.CheckMySQLRows(mysqlQuery)
+		if err != nil {
+			return err
+		}
+		mysqlRows = rows
+		return nil
+	})
+	g.Go(func() error {
+		rows, err := r.CheckOracleRows(oracleQuery)
+		if err != nil {
+			return err
+		}
+		oracleRows = rows
+		return nil
+	})
+	if err := g.Wait(); err != nil {
+		return "", err
+	}
+	r.MySQLRows, r.OracleRows = mysqlRows, oracleRows
+
+	if mysqlRows == oracleRows {
+		zap.L().Info("mysql table chunk diff equal",
+			zap.String("mysql schema", r.DataCompareMeta.SchemaNameS),
+			zap.String("oracle schema", r.DataCompareMeta.SchemaNameT),
+			zap.String("mysql table", r.DataCompareMeta.TableNameS),
+			zap.String("oracle table", r.DataCompareMeta.TableNameT),
+			zap.Int64("mysql rows count", mysqlRows),
+			zap.Int64("oracle rows count", oracleRows),
+			zap.String("mysql sql", mysqlQuery),
+			zap.String("oracle sql", oracleQuery))
+		return "", nil
+	}
+
+	zap.L().Info("mysql table chunk diff isn't equal",
+		zap.String("mysql schema", r.DataCompareMeta.SchemaNameS),
+		zap.String("oracle schema", r.DataCompareMeta.SchemaNameT),
+		zap.String("mysql table", r.DataCompareMeta.TableNameS),
+		zap.String("oracle table", r.DataCompareMeta.TableNameT),
+		zap.Int64("mysql rows count", mysqlRows),
+		zap.Int64("oracle rows count", oracleRows),
+		zap.String("mysql sql", mysqlQuery),
+		zap.String("oracle sql", oracleQuery))
+
+	sw := table.NewWriter()
+	sw.SetStyle(table.StyleLight)
+	sw.AppendHeader(table.Row{"SOURCE TABLE", "SOURCE SQL", "SOURCE COUNTS", "TARGET TABLE", "TARGET SQL", "TARGET TABLE COUNTS", "RANGE"})
+	sw.AppendRows([]table.Row{
+		{
+			r.MySQLTableName(),
+			mysqlQuery,
+			mysqlRows,
+			r.OracleTableName(),
+			oracleQuery,
+			oracleRows,
+			r.DataCompareMeta.WhereRange,
+		},
+	})
+
+	return fmt.Sprintf("/* \n\tmysql and oracle table range [%s] data rows aren't equal\n", r.DataCompareMeta.WhereRange) + sw.Render() + "\n*/\n", nil
+}
+
+// ReportCheckCRC32 上下游数据行格式化后计算 CRC32 对比，不一致按数据行多重集合差异输出 oracle 修复 SQL
+// 上游多出数据行 INSERT 下游，下游多出数据行 DELETE 下游，重复数据行按出现次数差异输出
+// 修复 SQL 字段值以修复字段原始值为准，DELETE 以主键/唯一键定位，无可用键以非 LOB 字段整行定位且单次仅删除一行
+func (r *Report) ReportCheckCRC32() (string, error) {
+	oracleQuery, mysqlQuery := r.GenDBFixQuery()
+	g := &errgroup.Group{}
+
+	var mysqlReport, oracleReport DBSummary
+	g.Go(func() error {
+		summary, err := r.getDataRowSummary(r.Mysql.Ctx, r.Mysql.MySQLDB, mysqlQuery, r.mysqlWithFix())
+		if err != nil {
+			return fmt.Errorf("get mysql data row strings failed: %v", err)
+		}
+		mysqlReport = summary
+		return nil
+	})
+	g.Go(func() error {
+		summary, err := r.getDataRowSummary(r.Oracle.Ctx, r.Oracle.OracleDB, oracleQuery, r.oracleWithFix())
+		if err != nil {
+			return fmt.Errorf("get oracle data row strings failed: %v", err)
+		}
+		oracleReport = summary
+		return nil
+	})
+	if err := g.Wait(); err != nil {
+		return "", err
+	}
+	r.MySQLRows, r.OracleRows = mysqlReport.Rows, oracleReport.Rows
+
+	if mysqlReport.Crc32Val == oracleReport.Crc32Val && r.MySQLRows == r.OracleRows {
+		zap.L().Info("mysql table chunk diff equal",
+			zap.String("mysql schema", r.DataCompareMeta.SchemaNameS),
+			zap.String("oracle schema", r.DataCompareMeta.SchemaNameT),
+			zap.String("mysql table", r.DataCompareMeta.TableNameS),
+			zap.String("oracle table", r.DataCompareMeta.TableNameT),
+			zap.Uint32("mysql crc32 values", mysqlReport.Crc32Val),
+			zap.Uint32("oracle crc32 values", oracleReport.Crc32Val),
+			zap.String("mysql sql", mysqlQuery),
+			zap.String("oracle sql", oracleQuery))
+		return "", nil
+	}
+
+	zap.L().Info("mysql table chunk diff isn't equal",
+		zap.String("mysql schema", r.DataCompareMeta.SchemaNameS),
+		zap.String("oracle schema", r.DataCompareMeta.SchemaNameT),
+		zap.String("mysql table", r.DataCompareMeta.TableNameS),
+		zap.String("oracle table", r.DataCompareMeta.TableNameT),
+		zap.Uint32("mysql crc32 values", mysqlReport.Crc32Val),
+		zap.Uint32("oracle crc32 values", oracleReport.Crc32Val),
+		zap.String("mysql sql", mysqlQuery),
+		zap.String("oracle sql", oracleQuery))
+
+	if len(mysqlReport.Columns) != len(oracleReport.Columns) || len(oracleReport.Columns) != len(r.ColumnKinds) {
+		return "", fmt.Errorf("mysql column counts [%d], oracle column counts [%d] and column kinds [%d] aren't equal",
+			len(mysqlReport.Columns), len(oracleReport.Columns), len(r.ColumnKinds))
+	}
+
+	targetMore := diffRowCounts(oracleReport.RowCounts, mysqlReport.RowCounts)
+	sourceMore := diffRowCounts(mysqlReport.RowCounts, oracleReport.RowCounts)
+
+	// 数值字段 numeric-epsilon 容差范围内差异数据行成对剔除
+	sourceMore, targetMore = r.filterEpsilonRows(mysqlReport, oracleReport, sourceMore, targetMore)
+	if len(sourceMore) == 0 && len(targetMore) == 0 {
+		zap.L().Info("mysql table chunk diff equal within numeric epsilon",
+			zap.String("mysql schema", r.DataCompareMeta.SchemaNameS),
+			zap.String("oracle schema", r.DataCompareMeta.SchemaNameT),
+			zap.String("mysql table", r.DataCompareMeta.TableNameS),
+			zap.String("oracle table", r.DataCompareMeta.TableNameT))
+		return "", nil
+	}
+
+	sw := table.NewWriter()
+	sw.SetStyle(table.StyleLight)
+	sw.AppendHeader(table.Row{"DATABASE", "DATA COUNTS SQL", "CRC32"})
+	sw.AppendRows([]table.Row{
+		{"MySQL",
+			common.StringsBuilder("SELECT COUNT(1) FROM ", r.MySQLTableName(), " WHERE ", r.DataCompareMeta.WhereRange),
+			mysqlReport.Crc32Val},
+		{"ORACLE",
+			common.StringsBuilder("SELECT COUNT(1) FROM ", r.OracleTableName(), " WHERE ", r.DataCompareMeta.WhereRange),
+			oracleReport.Crc32Val},
+	})
+	counts := sw.Render()
+
+	var fixSQL strings.Builder
+	// 下游数据多，oracle 以主键/唯一键条件删除，无可用键整行条件删除且限制单行
+	if len(targetMore) > 0 {
+		fixSQL.WriteString("/*\n")
+		fixSQL.WriteString(fmt.Sprintf(" oracle table [%s] chunk [%s] data rows are more \n", r.OracleTableName(), r.DataCompareMeta.WhereRange))
+		fixSQL.WriteString(fmt.Sprintf("%v\n", counts))
+		fixSQL.WriteString("*/\n")
+		deletePrefix := common.StringsBuilder("DELETE FROM ", r.OracleTableName(), " WHERE ")
+		fixRows := oracleReport.fixRowIter()
+		for _, k := range targetMore {
+			whereCond := genOracleKeyWhereCond(oracleReport.Columns, r.ColumnKinds, r.FixKeyIndex, fixRows(k))
+			if !r.FixByKey {
+				whereCond = common.StringsBuilder(whereCond, " AND ROWNUM <= 1")
+			}
+			fixSQL.WriteString(common.StringsBuilder(deletePrefix, whereCond, ";\n"))
+		}
+	}
+
+	// 上游数据多，oracle 插入上游数据行
+	if len(sourceMore) > 0 {
+		fixSQL.WriteString("/*\n")
+		fixSQL.WriteString(fmt.Sprintf(" oracle table [%s] chunk [%s] data rows are less \n", r.OracleTableName(), r.DataCompareMeta.WhereRange))
+		fixSQL.WriteString(fmt.Sprintf("%v\n", counts))
+		fixSQL.WriteString("*/\n")
+		insertPrefix := common.StringsBuilder("INSERT INTO ", r.OracleTableName(), " (", strings.Join(oracleReport.Columns, ","), ") VALUES (")
+		fixRows := mysqlReport.fixRowIter()
+		for _, k := range sourceMore {
+			var values []string
+			for i, v := range fixRows(k) {
+				values = append(values, genOracleLiteral(r.ColumnKinds[i], v))
+			}
+			fixSQL.WriteString(common.StringsBuilder(insertPrefix, strings.Join(values, ","), ");\n"))
+		}
+	}
+	return fixSQL.String(), nil
+}
+
+// filterEpsilonRows 上下游差异数据行按 numeric-epsilon 容差配对，容差范围内数据行视为一致剔除
+func (r *Report) filterEpsilonRows(mysqlReport, oracleReport DBSummary, sourceMore, targetMore []string) ([]string, []string) {
+	sourceRows := make([][]string, 0, len(sourceMore))
+	for _, k := range sourceMore {
+		sourceRows = append(sourceRows, mysqlReport.RowValues[k])
+	}
+	targetRows := make([][]string, 0, len(targetMore))
+	for _, k := range targetMore {
+		targetRows = append(targetRows, oracleReport.RowValues[k])
+	}
+	sourceIdx, targetIdx := common.FilterCompareEpsilonRows(sourceRows, targetRows, r.ColumnEpsilons)
+
+	sourceRemain := make([]string, 0, len(sourceIdx))
+	for _, i := range sourceIdx {
+		sourceRemain = append(sourceRemain, sourceMore[i])
+	}
+	targetRemain := make([]string, 0, len(targetIdx))
+	for _, i := range targetIdx {
+		targetRemain = append(targetRemain, targetMore[i])
+	}
+	return sourceRemain, targetRemain
+}
+
+// ReportCheckChecksum 上下游数据库端计算 chunk checksum，返回 chunk 数据是否一致
+func (r *Report) ReportCheckChecksum() (bool, error) {
+	oracleQuery, mysqlQuery := r.GenDBChecksumQuery()
+	g := &errgroup.Group{}
+
+	var (
+		mysqlRows, oracleRows         int64
+		mysqlChecksum, oracleChecksum string
+	)
+	g.Go(func() error {
+		rows, checksum, err := r.Mysql.GetMySQLDataChunkChecksum(mysqlQuery)
+		if err != nil {
+			return fmt.Errorf("get mysql data chunk checksum failed: %v", err)
+		}
+		mysqlRows, mysqlChecksum = rows, checksum
+		return nil
+	})
+	g.Go(func() error {
+		rows, checksum, err := r.Oracle.GetOracleDataChunkChecksum(oracleQuery)
+		if err != nil {
+			return fmt.Errorf("get oracle data chunk checksum failed: %v", err)
+		}
+		oracleRows, oracleChecksum = rows, checksum
+		return nil
+	})
+	if err := g.Wait(); err != nil {
+		return false, err
+	}
+
+	mysqlDecimal, err := decimal.NewFromString(mysqlChecksum)
+	if err != nil {
+		return false, fmt.Errorf("mysql checksum [%s] decimal convert failed: %v", mysqlChecksum, err)
+	}
+	oraDecimal, err := decimal.NewFromString(oracleChecksum)
+	if err != nil {
+		return false, fmt.Errorf("oracle checksum [%s] decimal convert failed: %v", oracleChecksum, err)
+	}
+
+	isEqual := mysqlRows == oracleRows && mysqlDecimal.Equal(oraDecimal)
+	r.MySQLRows, r.OracleRows = mysqlRows, oracleRows
+
+	zap.L().Info("mysql table chunk checksum diff",
+		zap.String("mysql schema", r.DataCompareMeta.SchemaNameS),
+		zap.String("oracle schema", r.DataCompareMeta.SchemaNameT),
+		zap.String("mysql table", r.DataCompareMeta.TableNameS),
+		zap.String("oracle table", r.DataCompareMeta.TableNameT),
+		zap.String("range", r.DataCompareMeta.WhereRange),
+		zap.Int64("mysql rows count", mysqlRows),
+		zap.Int64("oracle rows count", oracleRows),
+		zap.String("mysql checksum", mysqlChecksum),
+		zap.String("oracle checksum", oracleChecksum),
+		zap.Bool("equal", isEqual))
+	return isEqual, nil
+}
+
+// Report mysql -> oracle 仅支持数据行数以及数据行 CRC32 对比（可选 checksum 下推），不支持排序键 merge 对比以及抽样校验
+func (r *Report) Report() (string, error) {
+	if r.OnlyCheckRows {
+		return r.ReportCheckRows()
+	}
+	// checksum 一致直接返回，不一致或者计算失败拉取数据行对比输出差异
+	if r.ChecksumFirst {
+		isEqual, err := r.ReportCheckChecksum()
+		if err != nil {
+			zap.L().Warn("mysql table chunk checksum failed, fallback data rows compare",
+				zap.String("mysql schema", r.DataCompareMeta.SchemaNameS),
+				zap.String("mysql table", r.DataCompareMeta.TableNameS),
+				zap.String("range", r.DataCompareMeta.WhereRange),
+				zap.Error(err))
+		} else if isEqual {
+			return "", nil
+		}
+	}
+	return r.ReportCheckCRC32()
+}
+
+// getDataRowSummary 数据行字段值统一格式化，上下游格式化规则一致
+// 空字符串以及 NULL 统一 NULL 处理，二进制字段十六进制输出，其他字段 oracle 特殊字符转义并以单引号包裹
+// withFix 查询字段为对比字段与修复字段拼接，对比字段计算 CRC32 以及数据行计数，修复字段用于生成修复 SQL
+func (r *Report) getDataRowSummary(ctx context.Context, db *sql.DB, querySQL string, withFix bool) (DBSummary, error) {
+	summary := DBSummary{
+		RowCounts: make(map[string]int),
+		RowValues: make(map[string][]string),
+		FixRows:   make(map[string][][]string),
+	}
+
+	rows, err := db.QueryContext(ctx, querySQL)
+	if err != nil {
+		return summary, fmt.Errorf("general sql [%v] query failed: [%v]", querySQL, err.Error())
+	}
+	defer rows.Close()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return summary, fmt.Errorf("general sql [%v] query rows.Columns failed: [%v]", querySQL, err.Error())
+	}
+	width := len(cols)
+	if withFix {
+		width, err = compare.SplitFixColumns(cols)
+		if err != nil {
+			return summary, err
+		}
+	}
+	if width != len(r.ColumnKinds) {
+		return summary, fmt.Errorf("general sql [%v] column counts [%d] and column kinds [%d] aren't equal", querySQL, width, len(r.ColumnKinds))
+	}
+	for _, c := range cols[:width] {
+		summary.Columns = append(summary.Columns, common.StringUPPER(c))
+	}
+
+	rawResult := make([][]byte, len(cols))
+	scans := make([]interface{}, len(cols))
+	for i := range rawResult {
+		scans[i] = &rawResult[i]
+	}
+
+	for rows.Next() {
+		if err = rows.Scan(scans...); err != nil {
+			return summary, fmt.Errorf("general sql [%v] query rows.Scan failed: [%v]", querySQL, err.Error())
+		}
+		values := formatDataRowValues(r.ColumnKinds, rawResult)
+		rowS := strings.Join(values[:width], ",")
+		summary.Crc32Val += crc32.ChecksumIEEE([]byte(rowS))
+		summary.Rows++
+		summary.RowCounts[rowS]++
+		summary.RowValues[rowS] = values[:width]
+		summary.FixRows[rowS] = append(summary.FixRows[rowS], values[len(cols)-width:])
+	}
+
+	if err = rows.Err(); err != nil {
+		return summary, fmt.Errorf("general sql [%v] query rows.Next failed: [%v]", querySQL, err.Error())
+	}
+	return summary, nil
+}
+
+// formatDataRowValues 数据行字段值格式化，修复字段与对比字段字段类别顺序一致
+func formatDataRowValues(kinds []string, rawResult [][]byte) []string {
+	values := make([]string, len(rawResult))
+	for i, raw := range rawResult {
+		switch {
+		case raw == nil || len(raw) == 0:
+			values[i] = "NULL"
+		case kinds[i%len(kinds)] == columnKindBinary:
+			values[i] = common.StringsBuilder("'", strings.ToUpper(hex.EncodeToString(raw)), "'")
+		default:
+			values[i] = common.StringsBuilder("'", common.SpecialLettersUsingOracle(raw), "'")
+		}
+	}
+	return values
+}
+
+// fixRowIter 按对比数据行依次返回修复字段值，重复数据行按读取顺序逐行返回
+func (d DBSummary) fixRowIter() func(row string) []string {
+	offsets := make(map[string]int)
+	return func(row string) []string {
+		fixRows := d.FixRows[row]
+		idx := offsets[row]
+		if idx >= len(fixRows) {
+			idx = len(fixRows) - 1
+		}
+		offsets[row]++
+		return fixRows[idx]
+	}
+}
+
+// diffRowCounts more 相对 less 多出的数据行，重复数据行按次数差值重复输出，按数据行排序
+func diffRowCounts(more, less map[string]int) []string {
+	var rows []string
+	for k, c := range more {
+		for i := less[k]; i < c; i++ {
+			rows = append(rows, k)
+		}
+	}
+	sort.Strings(rows)
+	return rows
+}
+
+func (r *Report) String() string {
+	jsonStr, _ := json.Marshal(r)
+	return string(jsonStr)
+}
@@ -0,0 +1,194 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package m2o
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"github.com/wentaojin/transferdb/database/meta"
+	"github.com/wentaojin/transferdb/database/mysql"
+	"github.com/wentaojin/transferdb/database/oracle"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// reportTestDriver 内存数据行驱动，模拟上下游查询结果，所有字段以 []byte 返回
+type reportTestDriver struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (d *reportTestDriver) Open(string) (driver.Conn, error) { return &reportTestConn{d: d}, nil }
+
+type reportTestConn struct{ d *reportTestDriver }
+
+func (c *reportTestConn) Prepare(string) (driver.Stmt, error) { return &reportTestStmt{d: c.d}, nil }
+func (c *reportTestConn) Close() error                        { return nil }
+func (c *reportTestConn) Begin() (driver.Tx, error)           { return nil, driver.ErrSkip }
+
+type reportTestStmt struct{ d *reportTestDriver }
+
+func (s *reportTestStmt) Close() error                               { return nil }
+func (s *reportTestStmt) NumInput() int                              { return 0 }
+func (s *reportTestStmt) Exec([]driver.Value) (driver.Result, error) { return nil, driver.ErrSkip }
+func (s *reportTestStmt) Query([]driver.Value) (driver.Rows, error) {
+	return &reportTestRows{d: s.d}, nil
+}
+
+type reportTestRows struct {
+	d   *reportTestDriver
+	pos int
+}
+
+func (r *reportTestRows) Columns() []string { return r.d.columns }
+func (r *reportTestRows) Close() error      { return nil }
+func (r *reportTestRows) Next(dest []driver.Value) error {
+	if r.pos >= len(r.d.rows) {
+		return io.EOF
+	}
+	copy(dest, r.d.rows[r.pos])
+	r.pos++
+	return nil
+}
+
+func openReportTestDB(t *testing.T, name string, d *reportTestDriver) *sql.DB {
+	sql.Register(name, d)
+	db, err := sql.Open(name, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func testRow(values ...string) []driver.Value {
+	row := make([]driver.Value, len(values))
+	for i, v := range values {
+		if v != "NULL" {
+			row[i] = []byte(v)
+		}
+	}
+	return row
+}
+
+func TestReportCheckCRC32FixSQL(t *testing.T) {
+	columns := []string{"ID", "NAME", "DOC", "ID", "NAME", "DOC"}
+	// 上游 NAME 字段 trim 规则对比一致，修复 SQL 以原始值 'a ' 为准；ID 2 上游重复两行，下游一行
+	mysqlDB := openReportTestDB(t, "m2o_report_mysql", &reportTestDriver{
+		columns: columns,
+		rows: [][]driver.Value{
+			testRow("1", "a", "x", "1", "a ", "x"),
+			testRow("2", "b", "y", "2", "b ", "y"),
+			testRow("2", "b", "y", "2", "b ", "y"),
+		},
+	})
+	oracleDB := openReportTestDB(t, "m2o_report_oracle", &reportTestDriver{
+		columns: columns,
+		rows: [][]driver.Value{
+			testRow("1", "a", "x", "1", "a", "x"),
+			testRow("2", "b", "y", "2", "b ", "y"),
+			testRow("3", "c", "z", "3", "c ", "z"),
+		},
+	})
+
+	newTestReport := func(fixKeyIndex []int, fixByKey bool) *Report {
+		r := NewReport(meta.DataCompareMeta{
+			SchemaNameT:   "MARVIN",
+			TableNameT:    "T",
+			WhereRange:    "1 = 1",
+			ColumnDetailS: "ID,IFNULL(TRIM(NAME),'') AS NAME,DOC",
+			ColumnDetailT: "ID,NVL(TRIM(NAME),'') AS NAME,DOC",
+		}, "marvin", "t",
+			&mysql.MySQL{Ctx: context.Background(), MySQLDB: mysqlDB},
+			&oracle.Oracle{Ctx: context.Background(), OracleDB: oracleDB},
+			false, false, []string{columnKindNumber, columnKindCharacter, columnKindCharacter})
+		r.FixColumnS, r.FixColumnT = "ID,IFNULL(NAME,'') AS NAME,DOC", "ID,NVL(NAME,'') AS NAME,DOC"
+		r.FixKeyIndex, r.FixByKey = fixKeyIndex, fixByKey
+		return r
+	}
+
+	cases := []struct {
+		name        string
+		fixKeyIndex []int
+		fixByKey    bool
+		wantDelete  string
+	}{
+		{
+			name: "delete by primary key", fixKeyIndex: []int{0}, fixByKey: true,
+			wantDelete: "DELETE FROM MARVIN.T WHERE ID = '3';\n",
+		},
+		{
+			name: "delete by non lob columns limit one row", fixKeyIndex: []int{0, 1}, fixByKey: false,
+			wantDelete: "DELETE FROM MARVIN.T WHERE ID = '3' AND NAME = 'c ' AND ROWNUM <= 1;\n",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r := newTestReport(c.fixKeyIndex, c.fixByKey)
+			fixSQL, err := r.ReportCheckCRC32()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r.MySQLRows != 3 || r.OracleRows != 3 {
+				t.Fatalf("rows got mysql %d oracle %d, want 3 and 3", r.MySQLRows, r.OracleRows)
+			}
+			if !strings.Contains(fixSQL, c.wantDelete) {
+				t.Fatalf("fix sql missing delete %q:\n%s", c.wantDelete, fixSQL)
+			}
+			wantInsert := "INSERT INTO MARVIN.T (ID,NAME,DOC) VALUES ('2','b ','y');\n"
+			if strings.Count(fixSQL, "INSERT INTO") != 1 || !strings.Contains(fixSQL, wantInsert) {
+				t.Fatalf("fix sql want single insert %q:\n%s", wantInsert, fixSQL)
+			}
+			if strings.Count(fixSQL, "DELETE FROM") != 1 {
+				t.Fatalf("fix sql want single delete:\n%s", fixSQL)
+			}
+		})
+	}
+}
+
+func TestDiffRowCounts(t *testing.T) {
+	more := map[string]int{"a": 3, "b": 1, "c": 1}
+	less := map[string]int{"a": 1, "b": 1, "d": 2}
+	if got, want := diffRowCounts(more, less), []string{"a", "a", "c"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("diffRowCounts got %v, want %v", got, want)
+	}
+	if got := diffRowCounts(less, less); len(got) != 0 {
+		t.Fatalf("diffRowCounts equal counts got %v, want empty", got)
+	}
+}
+
+func TestGenOracleKeyWhereCond(t *testing.T) {
+	cols := []string{"ID", "CREATED", "PIC", "NOTE"}
+	kinds := []string{columnKindNumber, columnKindDatetime, columnKindBinary, columnKindCharacter}
+	values := []string{"'1'", "'2022-01-02 03:04:05.123456'", "'0AFF'", "NULL"}
+
+	cases := []struct {
+		keyIndex []int
+		want     string
+	}{
+		{keyIndex: []int{0}, want: "ID = '1'"},
+		{keyIndex: []int{1, 3}, want: "CREATED = TO_TIMESTAMP('2022-01-02 03:04:05.123456','YYYY-MM-DD HH24:MI:SS.FF') AND NOTE IS NULL"},
+		{keyIndex: []int{2}, want: "PIC = HEXTORAW('0AFF')"},
+	}
+	for _, c := range cases {
+		if got := genOracleKeyWhereCond(cols, kinds, c.keyIndex, values); got != c.want {
+			t.Errorf("genOracleKeyWhereCond(%v) got %q, want %q", c.keyIndex, got, c.want)
+		}
+	}
+}
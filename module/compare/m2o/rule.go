@@ -0,0 +1,111 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package m2o
+
+import (
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/config"
+	"strconv"
+	"strings"
+)
+
+// 时间字段格式化长度 yyyy-MM-dd HH24:mi:ss
+const compareTimestampSecondLength = 19
+
+// 字段值格式化类别，用于数据行格式化以及 oracle 修复 SQL 字面量生成
+const (
+	columnKindNumber    = "NUMBER"
+	columnKindCharacter = "CHARACTER"
+	columnKindDate      = "DATE"
+	columnKindDatetime  = "DATETIME"
+	columnKindBinary    = "BINARY"
+	columnKindOther     = "OTHER"
+)
+
+// genNumberColumn 数值字段查询格式化，上游 mysql 去除末尾无效 0，下游 oracle 补齐小数点前 0
+// numeric-epsilon 容差不在查询中处理，数据行对比按 ABS(a-b) <= epsilon 判断
+func genNumberColumn(colName string) (string, string) {
+	return common.StringsBuilder("CAST(0 + CAST(", colName, " AS CHAR) AS CHAR)"),
+		common.StringsBuilder("DECODE(SUBSTR(", colName, ",1,1),'.','0' || ", colName, ",", colName, ")")
+}
+
+// genCharacterColumn 字符字段查询格式化，trim 去除首尾空格，case-insensitive 统一大写
+func genCharacterColumn(colName string, rule config.ColumnRule) (string, string) {
+	sourceCol, targetCol := colName, colName
+	if rule.Trim {
+		sourceCol = common.StringsBuilder("TRIM(", sourceCol, ")")
+		targetCol = common.StringsBuilder("TRIM(", targetCol, ")")
+	}
+	if rule.CaseInsensitive {
+		sourceCol = common.StringsBuilder("UPPER(", sourceCol, ")")
+		targetCol = common.StringsBuilder("UPPER(", targetCol, ")")
+	}
+	return common.StringsBuilder("IFNULL(", sourceCol, ",'')"), common.StringsBuilder("NVL(", targetCol, ",'')")
+}
+
+// genDatetimeColumn 时间字段查询格式化，timestamp-precision 上下游截取相同小数秒位数，timestamp-utc 统一转换 UTC 时间
+func genDatetimeColumn(colName, dataType string, rule config.ColumnRule) (string, string) {
+	sourceCol, targetCol := colName, colName
+	if rule.TimestampUTC && strings.EqualFold(dataType, "TIMESTAMP") {
+		sourceCol = common.StringsBuilder("CONVERT_TZ(", colName, ",@@SESSION.TIME_ZONE,'+00:00')")
+		targetCol = common.StringsBuilder("SYS_EXTRACT_UTC(", colName, ")")
+	}
+
+	precision := rule.TimestampPrecision
+	if precision > 6 {
+		precision = 6
+	}
+	if precision <= 0 {
+		return common.StringsBuilder("DATE_FORMAT(", sourceCol, ",'%Y-%m-%d %H:%i:%s')"),
+			common.StringsBuilder("TO_CHAR(", targetCol, ",'yyyy-MM-dd HH24:mi:ss')")
+	}
+	length := strconv.Itoa(compareTimestampSecondLength + 1 + precision)
+	return common.StringsBuilder("LEFT(DATE_FORMAT(", sourceCol, ",'%Y-%m-%d %H:%i:%s.%f'),", length, ")"),
+		common.StringsBuilder("SUBSTR(TO_CHAR(CAST(", targetCol, " AS TIMESTAMP),'yyyy-MM-dd HH24:mi:ss.FF6'),1,", length, ")")
+}
+
+// genOracleLiteral 格式化字段值转换 oracle 修复 SQL 字面量，时间字段 TO_DATE/TO_TIMESTAMP 显式转换，二进制字段 HEXTORAW 转换
+func genOracleLiteral(kind, value string) string {
+	if value == "NULL" {
+		return value
+	}
+	switch kind {
+	case columnKindDate:
+		return common.StringsBuilder("TO_DATE(", value, ",'YYYY-MM-DD')")
+	case columnKindDatetime:
+		if len(value) > compareTimestampSecondLength+2 {
+			return common.StringsBuilder("TO_TIMESTAMP(", value, ",'YYYY-MM-DD HH24:MI:SS.FF')")
+		}
+		return common.StringsBuilder("TO_DATE(", value, ",'YYYY-MM-DD HH24:MI:SS')")
+	case columnKindBinary:
+		return common.StringsBuilder("HEXTORAW(", value, ")")
+	default:
+		return value
+	}
+}
+
+// genOracleKeyWhereCond 数据行定位字段 oracle 等值条件，NULL 值以 IS NULL 判断
+func genOracleKeyWhereCond(cols, kinds []string, keyIndex []int, values []string) string {
+	var whereCond []string
+	for _, idx := range keyIndex {
+		if values[idx] == "NULL" {
+			whereCond = append(whereCond, common.StringsBuilder(cols[idx], " IS NULL"))
+			continue
+		}
+		whereCond = append(whereCond, common.StringsBuilder(cols[idx], " = ", genOracleLiteral(kinds[idx], values[idx])))
+	}
+	return strings.Join(whereCond, " AND ")
+}
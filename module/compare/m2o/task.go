@@ -0,0 +1,320 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package m2o
+
+import (
+	"context"
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/config"
+	"github.com/wentaojin/transferdb/database/mysql"
+	"github.com/wentaojin/transferdb/database/oracle"
+	"strings"
+)
+
+type Task struct {
+	ctx             context.Context
+	cfg             *config.Config
+	sourceTableName string
+	targetTableName string
+	mysql           *mysql.MySQL
+	oracle          *oracle.Oracle
+}
+
+func NewCompareTableTask(ctx context.Context, cfg *config.Config, compareTables []string, mysql *mysql.MySQL, oracle *oracle.Oracle, tableNameRule map[string]string) []*Task {
+	var tasks []*Task
+	for _, table := range compareTables {
+		// 库名、表名规则
+		var targetTableName string
+		if val, ok := tableNameRule[common.StringUPPER(table)]; ok {
+			targetTableName = val
+		} else {
+			targetTableName = common.StringUPPER(table)
+		}
+		tasks = append(tasks, &Task{
+			ctx:             ctx,
+			cfg:             cfg,
+			sourceTableName: table,
+			targetTableName: targetTableName,
+			mysql:           mysql,
+			oracle:          oracle,
+		})
+	}
+	return tasks
+}
+
+// 字段查询以 MySQL 字段为主
+// 上下游字段统一格式化字符输出，数据行格式化与 oracle 修复 SQL 字面量生成按字段类别处理
+// 配置文件字段数据校验规则上下游统一处理
+func (t *Task) AdjustDBSelectColumn() (sourceColumnInfo string, targetColumnInfo string, err error) {
+	sourceColumnInfos, targetColumnInfos, _, _, err := t.genSelectColumn(true)
+	if err != nil {
+		return sourceColumnInfo, targetColumnInfo, err
+	}
+	return strings.Join(sourceColumnInfos, ","), strings.Join(targetColumnInfos, ","), nil
+}
+
+// AdjustDBFixColumn 修复 SQL 字段查询，字段以及顺序与 AdjustDBSelectColumn 一致，不应用 trim/case-insensitive/timestamp 等字段校验规则
+// 时间字段保留微秒精度，修复 SQL 字段值以原始字段值为准
+func (t *Task) AdjustDBFixColumn() (sourceColumnInfo string, targetColumnInfo string, err error) {
+	sourceColumnInfos, targetColumnInfos, _, _, err := t.genSelectColumn(false)
+	if err != nil {
+		return sourceColumnInfo, targetColumnInfo, err
+	}
+	return strings.Join(sourceColumnInfos, ","), strings.Join(targetColumnInfos, ","), nil
+}
+
+// FilterDBColumnKind 查询字段格式化类别，与 AdjustDBSelectColumn 查询字段顺序一致
+func (t *Task) FilterDBColumnKind() ([]string, error) {
+	_, _, columnKinds, _, err := t.genSelectColumn(true)
+	if err != nil {
+		return nil, err
+	}
+	return columnKinds, nil
+}
+
+// FilterDBColumnEpsilon 查询字段 numeric-epsilon 容差，与 AdjustDBSelectColumn 查询字段顺序一致，非数值字段以及未配置容差字段为 0
+func (t *Task) FilterDBColumnEpsilon() ([]float64, error) {
+	_, _, _, columnEpsilons, err := t.genSelectColumn(true)
+	if err != nil {
+		return nil, err
+	}
+	return columnEpsilons, nil
+}
+
+// FilterDBFixKeyIndex 修复 SQL 数据行定位字段下标，与 AdjustDBSelectColumn 查询字段顺序一致
+// 优先主键，其次唯一键/唯一索引，键字段全部参与对比返回键字段下标以及 true
+// 否则返回非 LOB 字段下标以及 false，以整行等值条件定位数据行
+func (t *Task) FilterDBFixKeyIndex() ([]int, bool, error) {
+	columnInfo, err := t.compareColumns()
+	if err != nil {
+		return nil, false, err
+	}
+	columnIndex := make(map[string]int, len(columnInfo))
+	for i, colsInfo := range columnInfo {
+		columnIndex[common.StringUPPER(colsInfo["COLUMN_NAME"])] = i
+	}
+
+	puConstraints, err := t.uniqueConstraints()
+	if err != nil {
+		return nil, false, err
+	}
+	for _, pu := range puConstraints {
+		var keyIndex []int
+		for _, col := range strings.Split(pu, ",") {
+			idx, ok := columnIndex[common.StringUPPER(col)]
+			if !ok {
+				keyIndex = nil
+				break
+			}
+			keyIndex = append(keyIndex, idx)
+		}
+		if len(keyIndex) > 0 {
+			return keyIndex, true, nil
+		}
+	}
+
+	var rowIndex []int
+	for i, colsInfo := range columnInfo {
+		if !isLOBColumn(colsInfo["DATA_TYPE"]) {
+			rowIndex = append(rowIndex, i)
+		}
+	}
+	if len(rowIndex) == 0 {
+		return nil, false, fmt.Errorf("mysql schema [%s] table [%s] compare columns are all lob columns and pk/uk columns are ignored, fix sql can't locate data rows", t.cfg.MySQLConfig.SchemaName, t.sourceTableName)
+	}
+	return rowIndex, false, nil
+}
+
+// compareColumns 参与对比字段，配置 ignore 字段规则的字段不参与对比
+func (t *Task) compareColumns() ([]map[string]string, error) {
+	columnInfo, err := t.mysql.GetMySQLTableColumn(t.cfg.MySQLConfig.SchemaName, t.sourceTableName)
+	if err != nil {
+		return nil, err
+	}
+	var columns []map[string]string
+	for _, colsInfo := range columnInfo {
+		if t.cfg.DiffConfig.GetColumnRule(t.sourceTableName, colsInfo["COLUMN_NAME"]).Ignore {
+			continue
+		}
+		columns = append(columns, colsInfo)
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("mysql schema [%s] table [%s] compare columns are all ignored, please check config column-rules", t.cfg.MySQLConfig.SchemaName, t.sourceTableName)
+	}
+	return columns, nil
+}
+
+// genSelectColumn applyRule 为 false 生成修复字段，不应用字段校验规则
+func (t *Task) genSelectColumn(applyRule bool) ([]string, []string, []string, []float64, error) {
+	var (
+		sourceColumnInfos, targetColumnInfos, columnKinds []string
+		columnEpsilons                                    []float64
+	)
+
+	columnInfo, err := t.compareColumns()
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	for _, colsInfo := range columnInfo {
+		colName := colsInfo["COLUMN_NAME"]
+		rule := t.cfg.DiffConfig.GetColumnRule(t.sourceTableName, colName)
+		if !applyRule {
+			rule = config.ColumnRule{TimestampPrecision: 6}
+		}
+
+		var (
+			sourceCol, targetCol, kind string
+			epsilon                    float64
+		)
+		switch strings.ToUpper(colsInfo["DATA_TYPE"]) {
+		// 数字
+		case "TINYINT", "SMALLINT", "MEDIUMINT", "INT", "INTEGER", "BIGINT", "DECIMAL", "NUMERIC", "FLOAT", "DOUBLE", "REAL":
+			sourceCol, targetCol = genNumberColumn(colName)
+			kind = columnKindNumber
+			epsilon = rule.NumericEpsilon
+		// 字符
+		case "CHAR", "VARCHAR", "TINYTEXT", "TEXT", "MEDIUMTEXT", "LONGTEXT", "ENUM", "SET", "JSON":
+			sourceCol, targetCol = genCharacterColumn(colName, rule)
+			kind = columnKindCharacter
+		// 二进制，数据行格式化十六进制输出
+		case "BINARY", "VARBINARY", "TINYBLOB", "BLOB", "MEDIUMBLOB", "LONGBLOB":
+			sourceCol, targetCol = colName, colName
+			kind = columnKindBinary
+		// 时间
+		case "DATE":
+			sourceCol = common.StringsBuilder("DATE_FORMAT(", colName, ",'%Y-%m-%d')")
+			targetCol = common.StringsBuilder("TO_CHAR(", colName, ",'yyyy-MM-dd')")
+			kind = columnKindDate
+		case "DATETIME", "TIMESTAMP":
+			sourceCol, targetCol = genDatetimeColumn(colName, strings.ToUpper(colsInfo["DATA_TYPE"]), rule)
+			kind = columnKindDatetime
+		// 默认其他类型
+		default:
+			sourceCol, targetCol = colName, colName
+			kind = columnKindOther
+		}
+		if strings.EqualFold(sourceCol, colName) {
+			sourceColumnInfos = append(sourceColumnInfos, colName)
+		} else {
+			sourceColumnInfos = append(sourceColumnInfos, common.StringsBuilder(sourceCol, " AS ", colName))
+		}
+		if strings.EqualFold(targetCol, colName) {
+			targetColumnInfos = append(targetColumnInfos, colName)
+		} else {
+			targetColumnInfos = append(targetColumnInfos, common.StringsBuilder(targetCol, " AS ", colName))
+		}
+		columnKinds = append(columnKinds, kind)
+		columnEpsilons = append(columnEpsilons, epsilon)
+	}
+	return sourceColumnInfos, targetColumnInfos, columnKinds, columnEpsilons, nil
+}
+
+// isLOBColumn mysql 大字段类型，对应 oracle CLOB/BLOB 字段无法等值比较
+func isLOBColumn(dataType string) bool {
+	switch strings.ToUpper(dataType) {
+	case "TINYTEXT", "TEXT", "MEDIUMTEXT", "LONGTEXT", "JSON", "TINYBLOB", "BLOB", "MEDIUMBLOB", "LONGBLOB":
+		return true
+	default:
+		return false
+	}
+}
+
+// 筛选整型字段以及判断表是否存在主键/唯一键/唯一索引
+// 第一优先级任意取某个主键/唯一键单列整型字段
+// 第二优先级取某个唯一索引单列整型字段
+// 第三优先级取联合主键/唯一键/唯一索引引导整型字段
+// 如果表不存在主键/唯一键/唯一索引则报错，存在但没有整型引导字段返回空，整表单 chunk 对比
+func (t *Task) FilterDBWhereColumn() (string, error) {
+	columnInfo, err := t.mysql.GetMySQLTableColumn(t.cfg.MySQLConfig.SchemaName, t.sourceTableName)
+	if err != nil {
+		return "", err
+	}
+
+	var integerColumns []string
+	for _, colsInfo := range columnInfo {
+		switch strings.ToUpper(colsInfo["DATA_TYPE"]) {
+		case "TINYINT", "SMALLINT", "MEDIUMINT", "INT", "INTEGER", "BIGINT":
+			integerColumns = append(integerColumns, strings.ToUpper(colsInfo["COLUMN_NAME"]))
+		}
+	}
+
+	puConstraints, err := t.uniqueConstraints()
+	if err != nil {
+		return "", err
+	}
+	if len(puConstraints) == 0 {
+		return "", fmt.Errorf("mysql schema [%s] table [%s] pk/uk/unique index isn't exist, it's not support, please skip", t.cfg.MySQLConfig.SchemaName, t.sourceTableName)
+	}
+
+	for _, pu := range puConstraints {
+		cols := strings.Split(pu, ",")
+		if len(cols) == 1 && common.IsContainString(integerColumns, strings.ToUpper(cols[0])) {
+			return cols[0], nil
+		}
+	}
+	for _, pu := range puConstraints {
+		cols := strings.Split(pu, ",")
+		if common.IsContainString(integerColumns, strings.ToUpper(cols[0])) {
+			return cols[0], nil
+		}
+	}
+	return "", nil
+}
+
+// uniqueConstraints 主键、唯一键以及唯一索引字段列表，按主键、唯一键、唯一索引顺序
+func (t *Task) uniqueConstraints() ([]string, error) {
+	// PK、UK
+	var puConstraints []string
+	pkInfo, err := t.mysql.GetMySQLTablePrimaryKey(t.cfg.MySQLConfig.SchemaName, t.sourceTableName)
+	if err != nil {
+		return nil, err
+	}
+	for _, pk := range pkInfo {
+		puConstraints = append(puConstraints, pk["COLUMN_LIST"])
+	}
+	ukInfo, err := t.mysql.GetMySQLTableUniqueKey(t.cfg.MySQLConfig.SchemaName, t.sourceTableName)
+	if err != nil {
+		return nil, err
+	}
+	for _, uk := range ukInfo {
+		puConstraints = append(puConstraints, uk["COLUMN_LIST"])
+	}
+
+	// 唯一索引
+	indexInfo, err := t.mysql.GetMySQLTableIndex(t.cfg.MySQLConfig.SchemaName, t.sourceTableName, t.cfg.MySQLConfig.DBType)
+	if err != nil {
+		return nil, err
+	}
+	for _, idx := range indexInfo {
+		if strings.EqualFold(idx["UNIQUENESS"], "UNIQUE") && strings.EqualFold(idx["COLUMN_EXPRESSION"], "") {
+			puConstraints = append(puConstraints, idx["COLUMN_LIST"])
+		}
+	}
+	return puConstraints, nil
+}
+
+func (t *Task) IsPartitionTable() (string, error) {
+	isOK, err := t.mysql.IsMySQLPartitionTable(t.cfg.MySQLConfig.SchemaName, t.sourceTableName)
+	if err != nil {
+		return "", err
+	}
+	if isOK {
+		return "YES", nil
+	}
+	return "NO", nil
+}
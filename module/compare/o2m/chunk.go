@@ -32,25 +32,28 @@ import (
 
 // Chunk 数据对比
 type Chunk struct {
-	Ctx              context.Context `json:"-"`
-	ChunkID          int             `json:"chunk_id"`
-	SourceGlobalSCN  uint64          `json:"source_global_scn"`
-	SourceTable      string          `json:"source_table"`
-	TargetTable      string          `json:"target_table"`
-	IsPartition      string          `json:"is_partition"`
-	SourceColumnInfo string          `json:"source_column_info"`
-	TargetColumnInfo string          `json:"target_column_info"`
-	WhereColumn      string          `json:"where_column"`
-	WhereRange       string          `json:"where_range"` // chunk split need
-	Cfg              *config.Config  `json:"-"`
-	Oracle           *oracle.Oracle  `json:"-"`
-	MySQL            *mysql.MySQL    `json:"-"`
-	MetaDB           *meta.Meta      `json:"-"`
+	Ctx              context.Context   `json:"-"`
+	ChunkID          int               `json:"chunk_id"`
+	SourceGlobalSCN  uint64            `json:"source_global_scn"`
+	SourceTable      string            `json:"source_table"`
+	TargetTable      string            `json:"target_table"`
+	IsPartition      string            `json:"is_partition"`
+	SourceColumnInfo string            `json:"source_column_info"`
+	TargetColumnInfo string            `json:"target_column_info"`
+	WhereColumn      string            `json:"where_column"`
+	WhereRange       string            `json:"where_range"` // chunk split need
+	OracleCollation  bool              `json:"oracle_collation"`
+	KeyColumns       []string          `json:"key_columns"`
+	ColumnNameRule   map[string]string `json:"column_name_rule"`
+	Cfg              *config.Config    `json:"-"`
+	Oracle           *oracle.Oracle    `json:"-"`
+	MySQL            *mysql.MySQL      `json:"-"`
+	MetaDB           *meta.Meta        `json:"-"`
 }
 
 func NewChunk(ctx context.Context, cfg *config.Config, oracle *oracle.Oracle, mysql *mysql.MySQL, metaDB *meta.Meta,
 	chunkID int, sourceGlobalSCN uint64, sourceTable, targetTable string, isPartition string, sourceColumnInfo, targetColumnInfo string,
-	whereColumn string) *Chunk {
+	whereColumn string, oracleCollation bool, keyColumns []string, columnNameRule map[string]string) *Chunk {
 	return &Chunk{
 		Ctx:              ctx,
 		ChunkID:          chunkID,
@@ -61,6 +64,9 @@ func NewChunk(ctx context.Context, cfg *config.Config, oracle *oracle.Oracle, my
 		SourceColumnInfo: sourceColumnInfo,
 		TargetColumnInfo: targetColumnInfo,
 		WhereColumn:      whereColumn,
+		OracleCollation:  oracleCollation,
+		KeyColumns:       keyColumns,
+		ColumnNameRule:   columnNameRule,
 		Oracle:           oracle,
 		MySQL:            mysql,
 		MetaDB:           metaDB,
@@ -68,48 +74,55 @@ func NewChunk(ctx context.Context, cfg *config.Config, oracle *oracle.Oracle, my
 	}
 }
 
-func (c *Chunk) CustomTableConfig() (customColumn string, customRange string, err error) {
+func (c *Chunk) CustomTableConfig() (customColumn string, customKeys []string, customRange string, err error) {
 	// 获取配置文件自定义配置
 	for _, tableCfg := range c.Cfg.DiffConfig.TableConfig {
 		if strings.EqualFold(c.SourceTable, tableCfg.SourceTable) {
-			// 同张表 indexFields vs Range 优先级，indexFields 需要是 number 数据类型字段
+			// 同张表 indexFields vs Range 优先级
 			// 同张表如果同时存在 indexFields 以及 Range，那么 Range 优先级 > indexFields
+			// indexFields 单个 number 数据类型字段按 number 切分，非 number 字段或者多个字段按键值区间切分
 			if tableCfg.IndexFields != "" && tableCfg.Range == "" {
-				isNUMBER, err := c.Oracle.IsNumberColumnTYPE(c.Cfg.OracleConfig.SchemaName, tableCfg.SourceTable, tableCfg.IndexFields)
-				if err != nil || !isNUMBER {
-					zap.L().Warn("compare table config index filed isn't number data type",
-						zap.String("table", tableCfg.SourceTable),
-						zap.String("index filed", tableCfg.IndexFields),
-						zap.String("range", tableCfg.Range))
-					return customColumn, customRange, fmt.Errorf("config file index-filed isn't number type, error: %v", err)
+				indexFields := strings.Split(tableCfg.IndexFields, ",")
+				if len(indexFields) == 1 {
+					isNUMBER, err := c.Oracle.IsNumberColumnTYPE(c.Cfg.OracleConfig.SchemaName, tableCfg.SourceTable, tableCfg.IndexFields)
+					if err != nil {
+						return customColumn, customKeys, customRange, fmt.Errorf("config file index-filed data type get failed, error: %v", err)
+					}
+					if isNUMBER {
+						customColumn = tableCfg.IndexFields
+						return customColumn, customKeys, customRange, nil
+					}
 				}
-				customColumn = tableCfg.IndexFields
-				return customColumn, customRange, nil
+				zap.L().Warn("compare table config index filed isn't single number data type, split chunk by key range",
+					zap.String("table", tableCfg.SourceTable),
+					zap.String("index filed", tableCfg.IndexFields))
+				customKeys = indexFields
+				return customColumn, customKeys, customRange, nil
 			}
 
 			if tableCfg.IndexFields == "" && tableCfg.Range != "" {
 				customRange = tableCfg.Range
-				return customColumn, customRange, nil
+				return customColumn, customKeys, customRange, nil
 			}
 
 			if tableCfg.IndexFields == "" && tableCfg.Range == "" {
-				return customColumn, customRange, nil
+				return customColumn, customKeys, customRange, nil
 			}
 
 			if tableCfg.IndexFields != "" && tableCfg.Range != "" {
 				customRange = tableCfg.Range
-				return customColumn, customRange, nil
+				return customColumn, customKeys, customRange, nil
 			}
 		}
 	}
-	return customColumn, customRange, nil
+	return customColumn, customKeys, customRange, nil
 }
 
 func (c *Chunk) Split() error {
 	startTime := time.Now()
 
 	// 配置文件参数优先级
-	// onlyCheckRows > configRange > configIndexFiled > DBFilter Integer Column
+	// onlyCheckRows > configRange > configIndexFiled > DBFilter Integer Column > PK/UK Key Range
 	// first
 	if c.Cfg.DiffConfig.OnlyCheckRows {
 		// SELECT COUNT(1) FROM TAB WHERE 1=1
@@ -153,7 +166,7 @@ func (c *Chunk) Split() error {
 
 	// second
 	// Range > IndexFields
-	customColumn, customRange, err := c.CustomTableConfig()
+	customColumn, customKeys, customRange, err := c.CustomTableConfig()
 	if err != nil {
 		return err
 	}
@@ -247,10 +260,30 @@ func (c *Chunk) Split() error {
 		zap.Int("rows", tableRowsByStatistics))
 
 	// forth
-	// indexField > 程序已过滤筛选的字段 DB Filter integer column
+	// indexField > 程序已过滤筛选的字段 DB Filter integer column > pk/uk 键值区间
+	if len(customKeys) > 0 {
+		keys, reason, err := c.NewChunkKeys(customKeys)
+		if err != nil {
+			return err
+		}
+		if reason != "" {
+			return c.SplitByFullTable(reason)
+		}
+		return c.SplitByKey(keys, tableRowsByStatistics)
+	}
 	if !strings.EqualFold(customColumn, "") {
 		c.WhereColumn = customColumn
 	}
+	if strings.EqualFold(c.WhereColumn, "") {
+		keys, reason, err := c.NewChunkKeys(c.KeyColumns)
+		if err != nil {
+			return err
+		}
+		if reason != "" {
+			return c.SplitByFullTable(reason)
+		}
+		return c.SplitByKey(keys, tableRowsByStatistics)
+	}
 
 	taskName := common.StringsBuilder(common.StringUPPER(c.Cfg.OracleConfig.SchemaName), `_`, c.SourceTable, `_`, `TASK`, strconv.Itoa(c.ChunkID))
 
@@ -322,6 +355,7 @@ func (c *Chunk) Split() error {
 			ColumnDetailS: c.SourceColumnInfo,
 			ColumnDetailT: c.TargetColumnInfo,
 			WhereRange:    r["CMD"],
+			WhereRangeT:   c.targetWhereRange(r["CMD"]),
 			WhereColumn:   c.WhereColumn,
 			IsPartition:   c.IsPartition,
 			TaskMode:      c.Cfg.TaskMode,
@@ -348,6 +382,7 @@ func (c *Chunk) Split() error {
 			ColumnDetailS: c.SourceColumnInfo,
 			ColumnDetailT: c.TargetColumnInfo,
 			WhereRange:    common.StringsBuilder(c.WhereColumn, " < ", r["START_ID"]),
+			WhereRangeT:   c.targetWhereRange(common.StringsBuilder(c.WhereColumn, " < ", r["START_ID"])),
 			WhereColumn:   c.WhereColumn,
 			IsPartition:   c.IsPartition,
 			TaskMode:      c.Cfg.TaskMode,
@@ -362,6 +397,7 @@ func (c *Chunk) Split() error {
 			ColumnDetailS: c.SourceColumnInfo,
 			ColumnDetailT: c.TargetColumnInfo,
 			WhereRange:    common.StringsBuilder(c.WhereColumn, " > ", res[0]["END_ID"]),
+			WhereRangeT:   c.targetWhereRange(common.StringsBuilder(c.WhereColumn, " > ", res[0]["END_ID"])),
 			WhereColumn:   c.WhereColumn,
 			IsPartition:   c.IsPartition,
 			TaskMode:      c.Cfg.TaskMode,
@@ -398,6 +434,15 @@ func (c *Chunk) Split() error {
 
 }
 
+// targetWhereRange NUMBER 切分字段存在字段名映射规则，目标端 where 条件切分字段替换为目标端字段名
+func (c *Chunk) targetWhereRange(whereRange string) string {
+	columnNameT := common.StringColumnNameRule(c.WhereColumn, c.ColumnNameRule)
+	if strings.EqualFold(columnNameT, c.WhereColumn) || !strings.HasPrefix(whereRange, c.WhereColumn) {
+		return ""
+	}
+	return common.StringsBuilder(columnNameT, strings.TrimPrefix(whereRange, c.WhereColumn))
+}
+
 func (c *Chunk) String() string {
 	jsonByte, _ := json.Marshal(c)
 	return string(jsonByte)
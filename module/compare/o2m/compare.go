@@ -37,6 +37,12 @@ type O2M struct {
 	oracle *oracle.Oracle
 	mysql  *mysql.MySQL
 	metaDB *meta.Meta
+	// checksum 下推是否生效
+	checksumFirst bool
+	// 快照一致性校验 oracle SCN、表级已应用 SCN 以及下游 TiDB 快照读连接
+	snapshotSCN       uint64
+	snapshotTableSCNs map[string]uint64
+	mysqlSnapshot     *mysql.MySQL
 }
 
 func NewCompare(ctx context.Context, cfg *config.Config) (*O2M, error) {
@@ -88,12 +94,21 @@ func (r *O2M) NewCompare() error {
 		return nil
 	}
 
+	// 差异复检
+	if r.cfg.DiffConfig.Recheck {
+		return r.NewRecheck(startTime, oraDBVersion, exporters)
+	}
+
 	// 关于全量断点恢复
 	if !r.cfg.DiffConfig.EnableCheckpoint {
 		err = meta.NewDataCompareMetaModel(r.metaDB).TruncateDataCompareMeta(r.ctx)
 		if err != nil {
 			return err
 		}
+		err = meta.NewDataCompareSummaryModel(r.metaDB).TruncateDataCompareSummary(r.ctx)
+		if err != nil {
+			return err
+		}
 
 		for _, tableName := range exporters {
 			err = meta.NewWaitSyncMetaModel(r.metaDB).DeleteWaitSyncMeta(r.ctx, &meta.WaitSyncMeta{
@@ -284,84 +299,65 @@ func (r *O2M) NewCompare() error {
 	}
 
 	// ORACLE 环境信息
-	beginTime := time.Now()
-	oracleDBCharacterSet, err := r.oracle.GetOracleDBCharacterSet()
+	oracleCollation, err := r.prepareCompareEnv(oraDBVersion, len(exporters))
 	if err != nil {
 		return err
 	}
-	if _, ok := common.OracleDBCharacterSetMap[strings.Split(oracleDBCharacterSet, ".")[1]]; !ok {
-		return fmt.Errorf("oracle db character set [%v] isn't support", oracleDBCharacterSet)
-	}
 
-	// oracle db collation
-	nlsSort, err := r.oracle.GetOracleDBCharacterNLSSortCollation()
-	if err != nil {
-		return err
-	}
-	nlsComp, err := r.oracle.GetOracleDBCharacterNLSCompCollation()
+	// compare 任务列表
+	// 获取表名自定义规则
+	tableNameRules, err := meta.NewTableNameRuleModel(r.metaDB).DetailTableNameRule(r.ctx, &meta.TableNameRule{
+		DBTypeS:     r.cfg.DBTypeS,
+		DBTypeT:     r.cfg.DBTypeT,
+		SchemaNameS: r.cfg.OracleConfig.SchemaName,
+		SchemaNameT: r.cfg.MySQLConfig.SchemaName,
+	})
 	if err != nil {
 		return err
 	}
-	if _, ok := common.OracleCollationMap[strings.ToUpper(nlsSort)]; !ok {
-		return fmt.Errorf("oracle db nls sort [%s] isn't support", nlsSort)
-	}
-	if _, ok := common.OracleCollationMap[strings.ToUpper(nlsComp)]; !ok {
-		return fmt.Errorf("oracle db nls comp [%s] isn't support", nlsComp)
-	}
-	if !strings.EqualFold(nlsSort, nlsComp) {
-		return fmt.Errorf("oracle db nls_sort [%s] and nls_comp [%s] isn't different, need be equal; because mysql db isn't support", nlsSort, nlsComp)
-	}
+	tableNameRuleMap := make(map[string]string)
 
-	// oracle 版本是否存在 collation
-	oracleCollation := false
-	if common.VersionOrdinal(oraDBVersion) >= common.VersionOrdinal(common.OracleTableColumnCollationDBVersion) {
-		oracleCollation = true
+	if len(tableNameRules) > 0 {
+		for _, tr := range tableNameRules {
+			tableNameRuleMap[common.StringUPPER(tr.TableNameS)] = common.StringUPPER(tr.TableNameT)
+		}
 	}
-	finishTime := time.Now()
-	zap.L().Info("get oracle db character and version finished",
-		zap.String("schema", r.cfg.OracleConfig.SchemaName),
-		zap.String("db version", oraDBVersion),
-		zap.String("db character", oracleDBCharacterSet),
-		zap.Int("table totals", len(exporters)),
-		zap.Bool("table collation", oracleCollation),
-		zap.String("cost", finishTime.Sub(beginTime).String()))
 
-	// 判断下游是否存在 ORACLE 表
-	var tables []string
+	// 判断下游是否存在 ORACLE 表，按表名映射规则（含超长标识符截断）转换目标端表名
+	var (
+		tables        []string
+		targetExports []string
+	)
 	for _, t := range exporters {
-		tables = append(tables, common.StringsBuilder("'", t, "'"))
+		targetTable := common.StringUPPER(t)
+		if val, ok := tableNameRuleMap[targetTable]; ok {
+			targetTable = val
+		}
+		targetExports = append(targetExports, targetTable)
+		tables = append(tables, common.StringsBuilder("'", targetTable, "'"))
 	}
 	mysqlTables, err := r.mysql.GetMySQLTableName(r.cfg.MySQLConfig.SchemaName, strings.Join(tables, ","))
 	if err != nil {
 		return err
 	}
 
-	diffItems := common.FilterDifferenceStringItems(exporters, mysqlTables)
+	diffItems := common.FilterDifferenceStringItems(targetExports, mysqlTables)
 	if len(diffItems) != 0 {
 		return fmt.Errorf("table [%v] target db isn't exists, please create table", diffItems)
 	}
 
-	// compare 任务列表
-	// 获取表名自定义规则
-	tableNameRules, err := meta.NewTableNameRuleModel(r.metaDB).DetailTableNameRule(r.ctx, &meta.TableNameRule{
+	// 获取字段名自定义规则
+	columnNameRuleMap, err := meta.NewColumnNameRuleModel(r.metaDB).DetailSchemaColumnNameRule(r.ctx, &meta.ColumnNameRule{
 		DBTypeS:     r.cfg.DBTypeS,
 		DBTypeT:     r.cfg.DBTypeT,
 		SchemaNameS: r.cfg.OracleConfig.SchemaName,
-		SchemaNameT: r.cfg.MySQLConfig.SchemaName,
 	})
 	if err != nil {
 		return err
 	}
-	tableNameRuleMap := make(map[string]string)
 
-	if len(tableNameRules) > 0 {
-		for _, tr := range tableNameRules {
-			tableNameRuleMap[common.StringUPPER(tr.TableNameS)] = common.StringUPPER(tr.TableNameT)
-		}
-	}
-
-	partTableTasks := NewPartCompareTableTask(r.ctx, r.cfg, partSyncTables, r.mysql, r.oracle, tableNameRuleMap)
-	waitTableTasks := NewWaitCompareTableTask(r.ctx, r.cfg, waitSyncTables, oracleCollation, r.mysql, r.oracle, tableNameRuleMap)
+	partTableTasks := NewPartCompareTableTask(r.ctx, r.cfg, partSyncTables, r.mysql, r.oracle, tableNameRuleMap, columnNameRuleMap)
+	waitTableTasks := NewWaitCompareTableTask(r.ctx, r.cfg, waitSyncTables, oracleCollation, r.mysql, r.oracle, tableNameRuleMap, columnNameRuleMap)
 
 	// 数据对比
 	err = common.PathExist(r.cfg.DiffConfig.FixSqlDir)
@@ -384,7 +380,7 @@ func (r *O2M) NewCompare() error {
 		if err != nil {
 			return err
 		}
-		err = r.comparePartTableTasks(f, partTableTasks)
+		err = r.comparePartTableTasks(f, partTableTasks, []string{common.TaskStatusWaiting, common.TaskStatusFailed})
 		if err != nil {
 			return err
 		}
@@ -427,6 +423,15 @@ func (r *O2M) NewCompare() error {
 		return err
 	}
 
+	// 数据校验汇总报告
+	summary, err := compare.GenCompareSummary(r.ctx, r.metaDB, r.cfg.DBTypeS, r.cfg.DBTypeT, r.cfg.OracleConfig.SchemaName, r.cfg.MySQLConfig.SchemaName, r.cfg.TaskMode, startTime, checkFile)
+	if err != nil {
+		return err
+	}
+	if err = compare.WriteCompareSummary(summary, r.cfg.DiffConfig.FixSqlDir); err != nil {
+		return err
+	}
+
 	zap.L().Info("compare", zap.String("fix sql file output", checkFile))
 	if len(failedTotals) == 0 {
 		zap.L().Info("compare table oracle to mysql finished",
@@ -445,7 +450,107 @@ func (r *O2M) NewCompare() error {
 	return nil
 }
 
-func (r *O2M) comparePartTableTasks(f *compare.File, partTableTasks []*Task) error {
+// prepareCompareEnv 校验 oracle 字符集以及排序规则，确定 checksum 下推、快照一致性校验时间点，返回是否存在字段级 collation
+func (r *O2M) prepareCompareEnv(oraDBVersion string, tableTotals int) (bool, error) {
+	beginTime := time.Now()
+	oracleDBCharacterSet, err := r.oracle.GetOracleDBCharacterSet()
+	if err != nil {
+		return false, err
+	}
+	if _, ok := common.OracleDBCharacterSetMap[common.StringOracleCharacterSet(oracleDBCharacterSet)]; !ok {
+		return false, fmt.Errorf("oracle db character set [%v] isn't support", oracleDBCharacterSet)
+	}
+
+	// oracle db collation
+	nlsSort, err := r.oracle.GetOracleDBCharacterNLSSortCollation()
+	if err != nil {
+		return false, err
+	}
+	nlsComp, err := r.oracle.GetOracleDBCharacterNLSCompCollation()
+	if err != nil {
+		return false, err
+	}
+	if _, ok := common.OracleCollationMap[strings.ToUpper(nlsSort)]; !ok {
+		return false, fmt.Errorf("oracle db nls sort [%s] isn't support", nlsSort)
+	}
+	if _, ok := common.OracleCollationMap[strings.ToUpper(nlsComp)]; !ok {
+		return false, fmt.Errorf("oracle db nls comp [%s] isn't support", nlsComp)
+	}
+	if !strings.EqualFold(nlsSort, nlsComp) {
+		return false, fmt.Errorf("oracle db nls_sort [%s] and nls_comp [%s] isn't different, need be equal; because mysql db isn't support", nlsSort, nlsComp)
+	}
+
+	// oracle 版本是否存在 collation
+	oracleCollation := false
+	if common.VersionOrdinal(oraDBVersion) >= common.VersionOrdinal(common.OracleTableColumnCollationDBVersion) {
+		oracleCollation = true
+	}
+	// checksum 下推要求 oracle 12c 及以上 STANDARD_HASH，且数据库字符集与下游 utf8mb4 字节一致
+	if r.cfg.DiffConfig.ChecksumFirst && !r.cfg.DiffConfig.OnlyCheckRows {
+		oraCharset := common.StringOracleCharacterSet(oracleDBCharacterSet)
+		if common.VersionOrdinal(oraDBVersion) >= common.VersionOrdinal(common.OracleStandardHashDBVersion) &&
+			(strings.EqualFold(oraCharset, common.BuildInOracleCharacterSetAL32UTF8) || strings.EqualFold(oraCharset, "UTF8")) {
+			r.checksumFirst = true
+		} else {
+			zap.L().Warn("oracle db version or character set isn't support checksum first, fallback data rows compare",
+				zap.String("db version", oraDBVersion),
+				zap.String("db character", oracleDBCharacterSet))
+		}
+	}
+
+	finishTime := time.Now()
+	zap.L().Info("get oracle db character and version finished",
+		zap.String("schema", r.cfg.OracleConfig.SchemaName),
+		zap.String("db version", oraDBVersion),
+		zap.String("db character", oracleDBCharacterSet),
+		zap.Int("table totals", tableTotals),
+		zap.Bool("table collation", oracleCollation),
+		zap.Bool("checksum first", r.checksumFirst),
+		zap.String("cost", finishTime.Sub(beginTime).String()))
+
+	// 抽样校验依赖 pk/uk 键值探测下游数据行
+	if r.cfg.DiffConfig.SamplePercent != 0 {
+		if r.cfg.DiffConfig.SamplePercent < 0 || r.cfg.DiffConfig.SamplePercent >= 100 {
+			return false, fmt.Errorf("compare config sample-percent [%v] isn't support, need be between 0 and 100", r.cfg.DiffConfig.SamplePercent)
+		}
+		if r.cfg.DiffConfig.OnlyCheckRows {
+			return false, fmt.Errorf("compare config sample-percent and only-check-rows can't be enabled at the same time")
+		}
+	}
+
+	// 快照一致性校验时间点
+	if r.cfg.DiffConfig.SnapshotCompare && !r.cfg.DiffConfig.OnlyCheckRows {
+		if r.cfg.DiffConfig.Repair && !r.cfg.DiffConfig.RepairDryRun {
+			return false, fmt.Errorf("compare config snapshot-compare and repair can't be enabled at the same time, snapshot read connection can't be written")
+		}
+		snapshotSCN, snapshotTableSCNs, snapshotTSO, err := r.genSnapshotPoint()
+		if err != nil {
+			return false, err
+		}
+		r.snapshotSCN, r.snapshotTableSCNs = snapshotSCN, snapshotTableSCNs
+		r.mysqlSnapshot, err = r.newSnapshotMySQL(snapshotTSO)
+		if err != nil {
+			return false, err
+		}
+		zap.L().Info("compare snapshot point",
+			zap.String("schema", r.cfg.OracleConfig.SchemaName),
+			zap.Uint64("oracle scn", snapshotSCN),
+			zap.Int("oracle table scn counts", len(snapshotTableSCNs)),
+			zap.Uint64("tidb tso", snapshotTSO))
+	}
+	return oracleCollation, nil
+}
+
+// tableSnapshotSCN 快照一致性校验表 AS OF SCN，存在表级已应用 SCN 以表级为准
+func (r *O2M) tableSnapshotSCN(tableName string) uint64 {
+	if scn, ok := r.snapshotTableSCNs[common.StringUPPER(tableName)]; ok {
+		return scn
+	}
+	return r.snapshotSCN
+}
+
+// comparePartTableTasks 表 chunk 数据对比，chunkStatus 指定待对比 chunk 状态
+func (r *O2M) comparePartTableTasks(f *compare.File, partTableTasks []*Task, chunkStatus []string) error {
 	for _, task := range partTableTasks {
 		// 获取对比记录
 		diffStartTime := time.Now()
@@ -463,39 +568,82 @@ func (r *O2M) comparePartTableTasks(f *compare.File, partTableTasks []*Task) err
 			return err
 		}
 
-		waitCompareMetas, err := meta.NewDataCompareMetaModel(r.metaDB).DetailDataCompareMeta(r.ctx, &meta.DataCompareMeta{
-			DBTypeS:     r.cfg.DBTypeS,
-			DBTypeT:     r.cfg.DBTypeT,
-			SchemaNameS: r.cfg.OracleConfig.SchemaName,
-			TableNameS:  task.sourceTableName,
-			TaskMode:    r.cfg.TaskMode,
-			TaskStatus:  common.TaskStatusWaiting,
-		})
-		if err != nil {
-			return err
+		var waitCompareMetas []meta.DataCompareMeta
+		for _, status := range chunkStatus {
+			compareMetas, err := meta.NewDataCompareMetaModel(r.metaDB).DetailDataCompareMeta(r.ctx, &meta.DataCompareMeta{
+				DBTypeS:     r.cfg.DBTypeS,
+				DBTypeT:     r.cfg.DBTypeT,
+				SchemaNameS: r.cfg.OracleConfig.SchemaName,
+				TableNameS:  task.sourceTableName,
+				TaskMode:    r.cfg.TaskMode,
+				TaskStatus:  status,
+			})
+			if err != nil {
+				return err
+			}
+			waitCompareMetas = append(waitCompareMetas, compareMetas...)
 		}
-		failedCompareMetas, err := meta.NewDataCompareMetaModel(r.metaDB).DetailDataCompareMeta(r.ctx, &meta.DataCompareMeta{
-			DBTypeS:     r.cfg.DBTypeS,
-			DBTypeT:     r.cfg.DBTypeT,
-			SchemaNameS: r.cfg.OracleConfig.SchemaName,
-			TableNameS:  task.sourceTableName,
-			TaskMode:    r.cfg.TaskMode,
-			TaskStatus:  common.TaskStatusFailed,
-		})
-		if err != nil {
-			return err
+
+		// 数据差异 merge 对比排序键
+		var compareKeys []CompareKey
+		if !r.cfg.DiffConfig.OnlyCheckRows {
+			compareKeys, err = task.FilterDBCompareKey()
+			if err != nil {
+				return err
+			}
+			if len(compareKeys) == 0 {
+				zap.L().Warn("oracle table pk/uk column isn't exist, data rows diff fallback set compare",
+					zap.String("schema", r.cfg.OracleConfig.SchemaName),
+					zap.String("table", task.sourceTableName))
+			}
 		}
 
-		waitCompareMetas = append(waitCompareMetas, failedCompareMetas...)
+		// 修复 SQL 字段值不应用字段校验规则，以原始字段值生成
+		var fixColumnS, fixColumnT string
+		if !r.cfg.DiffConfig.OnlyCheckRows {
+			fixColumnS, fixColumnT, err = task.AdjustDBFixColumn()
+			if err != nil {
+				return err
+			}
+		}
+
+		// 数值字段 numeric-epsilon 容差，存在容差字段数据库端 checksum 无法容差，不使用 checksum 下推
+		var columnEpsilons []float64
+		checksumFirst := r.checksumFirst
+		if !r.cfg.DiffConfig.OnlyCheckRows {
+			columnEpsilons, err = task.FilterDBColumnEpsilon()
+			if err != nil {
+				return err
+			}
+			if checksumFirst && common.CompareEpsilonEnabled(columnEpsilons) {
+				checksumFirst = false
+				zap.L().Warn("oracle table column numeric-epsilon is set, disable checksum first, fallback data rows compare",
+					zap.String("schema", r.cfg.OracleConfig.SchemaName),
+					zap.String("table", task.sourceTableName))
+			}
+		}
 
 		// 设置工作池
 		// 设置 goroutine 数
 		g1 := &errgroup.Group{}
 		g1.SetLimit(r.cfg.DiffConfig.DiffThreads)
 
+		// 快照一致性校验下游快照读
+		reportMySQL := r.mysql
+		if r.mysqlSnapshot != nil {
+			reportMySQL = r.mysqlSnapshot
+		}
+
 		for _, compareMeta := range waitCompareMetas {
-			newReport := NewReport(compareMeta, r.mysql, r.oracle, r.cfg.DiffConfig.OnlyCheckRows)
+			newReport := NewReport(compareMeta, reportMySQL, r.oracle, r.cfg.DiffConfig.OnlyCheckRows, checksumFirst, compareKeys, r.tableSnapshotSCN(task.sourceTableName), SampleOption{
+				Percent:  r.cfg.DiffConfig.SamplePercent,
+				Block:    r.cfg.DiffConfig.SampleBlock,
+				Escalate: r.cfg.DiffConfig.SampleEscalate,
+			}, r.cfg.DiffConfig.FixSqlDir, r.cfg.DiffConfig.Repair)
+			newReport.FixColumnS, newReport.FixColumnT = fixColumnS, fixColumnT
+			newReport.ColumnEpsilons = columnEpsilons
 			g1.Go(func() error {
+				defer newReport.Close()
 				// 数据对比报告
 				report, err := IReport(newReport)
 				if err != nil {
@@ -507,11 +655,11 @@ func (r *O2M) comparePartTableTasks(f *compare.File, partTableTasks []*Task) err
 						TableNameS:  newReport.DataCompareMeta.TableNameS,
 						TaskMode:    newReport.DataCompareMeta.TaskMode,
 						WhereRange:  newReport.DataCompareMeta.WhereRange,
-					}, map[string]interface{}{
+					}, reportUpdates(newReport, map[string]interface{}{
 						"TaskStatus":  common.TaskStatusFailed,
 						"InfoDetail":  newReport.String(),
 						"ErrorDetail": err.Error(),
-					}); err != nil {
+					})); err != nil {
 						return err
 					}
 
@@ -521,11 +669,30 @@ func (r *O2M) comparePartTableTasks(f *compare.File, partTableTasks []*Task) err
 				// 数据对比是否不一致
 				if !strings.EqualFold(report, "") {
 					var errMsg error
-					errMsg = fmt.Errorf("schema table data chunk isn't euqal")
+					errMsg = fmt.Errorf(common.CompareChunkDiffError)
 
-					if _, err := f.CWriteString(report); err != nil {
+					if err := f.CWriteSpool(report, newReport.FixSpool); err != nil {
 						errMsg = fmt.Errorf("fix sql file write failed: %v", err.Error())
 					}
+					updates := map[string]interface{}{
+						"TaskStatus":  common.TaskStatusFailed,
+						"InfoDetail":  newReport.String(),
+						"ErrorDetail": errMsg.Error(),
+					}
+
+					// 差异自动修复，修复后重新校验一致则 chunk 校验成功
+					if r.cfg.DiffConfig.Repair {
+						repairStatus, repairDetail := r.repairChunk(newReport)
+						if repairStatus != "" {
+							updates["RepairStatus"] = repairStatus
+							updates["RepairDetail"] = repairDetail
+						}
+						if strings.EqualFold(repairStatus, common.CompareRepairStatusFixed) {
+							updates["TaskStatus"] = common.TaskStatusSuccess
+							updates["ErrorDetail"] = ""
+						}
+					}
+
 					// error skip, continue
 					if err = meta.NewDataCompareMetaModel(r.metaDB).UpdateDataCompareMeta(r.ctx, &meta.DataCompareMeta{
 						DBTypeS:     newReport.DataCompareMeta.DBTypeS,
@@ -534,11 +701,7 @@ func (r *O2M) comparePartTableTasks(f *compare.File, partTableTasks []*Task) err
 						TableNameS:  newReport.DataCompareMeta.TableNameS,
 						TaskMode:    newReport.DataCompareMeta.TaskMode,
 						WhereRange:  newReport.DataCompareMeta.WhereRange,
-					}, map[string]interface{}{
-						"TaskStatus":  common.TaskStatusFailed,
-						"InfoDetail":  newReport.String(),
-						"ErrorDetail": errMsg.Error(),
-					}); err != nil {
+					}, reportUpdates(newReport, updates)); err != nil {
 						return err
 					}
 
@@ -552,9 +715,12 @@ func (r *O2M) comparePartTableTasks(f *compare.File, partTableTasks []*Task) err
 					TableNameS:  newReport.DataCompareMeta.TableNameS,
 					TaskMode:    newReport.DataCompareMeta.TaskMode,
 					WhereRange:  newReport.DataCompareMeta.WhereRange,
-				}, map[string]interface{}{
+				}, reportUpdates(newReport, map[string]interface{}{
 					"TaskStatus": common.TaskStatusSuccess,
-				})
+					// 数据一致清空历史差异修复状态，复检不再重复筛选
+					"RepairStatus": "",
+					"RepairDetail": "",
+				}))
 				if err != nil {
 					return err
 				}
@@ -592,8 +758,12 @@ func (r *O2M) comparePartTableTasks(f *compare.File, partTableTasks []*Task) err
 			return fmt.Errorf("get meta table [data_compare_meta] counts failed, error: %v", err)
 		}
 
-		// 不存在错误，清理 data_compare_meta 记录, 更新 wait_sync_meta 记录
+		// 不存在错误，汇总 data_compare_meta 记录写入 data_compare_summary，清理 data_compare_meta 记录, 更新 wait_sync_meta 记录
 		if failedTotalErrs == 0 {
+			tableSummary, err := compare.GenCompareTableSummary(r.ctx, r.metaDB, r.cfg.DBTypeS, r.cfg.DBTypeT, r.cfg.OracleConfig.SchemaName, task.sourceTableName, r.cfg.TaskMode)
+			if err != nil {
+				return err
+			}
 			err = meta.NewCommonModel(r.metaDB).DeleteTableDataCompareMetaAndUpdateWaitSyncMeta(r.ctx,
 				&meta.DataCompareMeta{
 					DBTypeS:     r.cfg.DBTypeS,
@@ -601,7 +771,7 @@ func (r *O2M) comparePartTableTasks(f *compare.File, partTableTasks []*Task) err
 					SchemaNameS: r.cfg.OracleConfig.SchemaName,
 					TableNameS:  task.sourceTableName,
 					TaskMode:    r.cfg.TaskMode,
-				}, &meta.WaitSyncMeta{
+				}, tableSummary, &meta.WaitSyncMeta{
 					DBTypeS:          r.cfg.DBTypeS,
 					DBTypeT:          r.cfg.DBTypeT,
 					SchemaNameS:      r.cfg.OracleConfig.SchemaName,
@@ -655,21 +825,59 @@ func (r *O2M) compareWaitTableTasks(f *compare.File, waitTableTasks []*Task) err
 
 	var chunks []*Chunk
 	for cid, task := range waitTableTasks {
+		// 清理历史差异修复保留记录
+		err = meta.NewDataCompareMetaModel(r.metaDB).DeleteDataCompareMetaByTable(r.ctx, &meta.DataCompareMeta{
+			DBTypeS:     r.cfg.DBTypeS,
+			DBTypeT:     r.cfg.DBTypeT,
+			SchemaNameS: r.cfg.OracleConfig.SchemaName,
+			TableNameS:  task.sourceTableName,
+			TaskMode:    r.cfg.TaskMode,
+		})
+		if err != nil {
+			return err
+		}
+		err = meta.NewDataCompareSummaryModel(r.metaDB).DeleteDataCompareSummaryByTable(r.ctx, &meta.DataCompareSummary{
+			DBTypeS:     r.cfg.DBTypeS,
+			DBTypeT:     r.cfg.DBTypeT,
+			SchemaNameS: r.cfg.OracleConfig.SchemaName,
+			TableNameS:  task.sourceTableName,
+			TaskMode:    r.cfg.TaskMode,
+		})
+		if err != nil {
+			return err
+		}
 		sourceColumnInfo, targetColumnInfo, err := task.AdjustDBSelectColumn()
 		if err != nil {
 			return err
 		}
-		whereColumn, err := task.FilterDBWhereColumn()
+		// NUMBER 切分字段不存在，以 pk/uk 键值区间切分
+		compareKeys, err := task.FilterDBCompareKey()
 		if err != nil {
 			return err
 		}
+		var keyColumns []string
+		for _, k := range compareKeys {
+			keyColumns = append(keyColumns, k.ColumnName)
+		}
+		whereColumn, err := task.FilterDBWhereColumn()
+		if err != nil {
+			if len(keyColumns) == 0 {
+				return err
+			}
+			zap.L().Warn("oracle table number column isn't exist, split chunk by pk/uk key range",
+				zap.String("schema", r.cfg.OracleConfig.SchemaName),
+				zap.String("table", task.sourceTableName),
+				zap.Strings("key columns", keyColumns),
+				zap.String("reason", err.Error()))
+			whereColumn = ""
+		}
 		isPartition, err := task.IsPartitionTable()
 		if err != nil {
 			return err
 		}
 		chunks = append(chunks, NewChunk(r.ctx, r.cfg, r.oracle, r.mysql, r.metaDB,
 			cid, globalSCN, task.sourceTableName, task.targetTableName, isPartition, sourceColumnInfo, targetColumnInfo,
-			whereColumn))
+			whereColumn, task.oracleCollation, keyColumns, task.columnNameRule))
 	}
 
 	// chunk split
@@ -690,7 +898,7 @@ func (r *O2M) compareWaitTableTasks(f *compare.File, waitTableTasks []*Task) err
 		return err
 	}
 
-	err = r.comparePartTableTasks(f, waitTableTasks)
+	err = r.comparePartTableTasks(f, waitTableTasks, []string{common.TaskStatusWaiting, common.TaskStatusFailed})
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,356 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package o2m
+
+import (
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/database/meta"
+	"github.com/wentaojin/transferdb/database/oracle"
+	"go.uber.org/zap"
+	"strconv"
+	"strings"
+)
+
+// 键值区间切分每个 chunk 期望抽样键值数，用于估算 oracle SAMPLE 抽样比例
+const keyChunkSamplePerChunk = 10
+
+// ChunkKey 键值区间切分字段
+// 字符字段区间边界按字节序抽样，非字节比较排序规则一侧区间条件转换字节比较，保证上下游切分相同数据行
+type ChunkKey struct {
+	CompareKey
+	Nullable        bool
+	OracleNonBinary bool
+	MySQLNonBinary  bool
+}
+
+// NewChunkKeys 根据上下游字段元数据生成键值区间切分字段，仅支持数字以及字符类型字段，其他类型无法排序返回无法键值区间切分原因
+// 字符字段上下游排序规则按字节比较以原字段区间条件切分并使用索引，非字节比较（例如大小写不敏感）以字节比较区间条件切分
+func (c *Chunk) NewChunkKeys(columns []string) ([]ChunkKey, string, error) {
+	oraColumns, err := c.Oracle.GetOracleSchemaTableColumn(c.Cfg.OracleConfig.SchemaName, c.SourceTable, c.OracleCollation)
+	if err != nil {
+		return nil, "", err
+	}
+	mysqlColumns, err := c.MySQL.GetMySQLTableColumn(c.Cfg.MySQLConfig.SchemaName, c.TargetTable)
+	if err != nil {
+		return nil, "", err
+	}
+	oraColumnMap := make(map[string]map[string]string)
+	for _, col := range oraColumns {
+		oraColumnMap[common.StringUPPER(col["COLUMN_NAME"])] = col
+	}
+	mysqlColumnMap := make(map[string]map[string]string)
+	for _, col := range mysqlColumns {
+		mysqlColumnMap[common.StringUPPER(col["COLUMN_NAME"])] = col
+	}
+
+	var keys []ChunkKey
+	for _, column := range columns {
+		columnName := common.StringUPPER(strings.TrimSpace(column))
+		oraCol, ok := oraColumnMap[columnName]
+		if !ok {
+			return nil, "", fmt.Errorf("oracle schema [%s] table [%s] chunk key column [%s] isn't exist", c.Cfg.OracleConfig.SchemaName, c.SourceTable, columnName)
+		}
+		columnNameT := common.StringUPPER(common.StringColumnNameRule(columnName, c.ColumnNameRule))
+		mysqlCol, ok := mysqlColumnMap[columnNameT]
+		if !ok {
+			return nil, "", fmt.Errorf("mysql schema [%s] table [%s] chunk key column [%s] isn't exist", c.Cfg.MySQLConfig.SchemaName, c.TargetTable, columnNameT)
+		}
+		key := ChunkKey{
+			CompareKey: NewCompareKey(columnName, oraCol["DATA_TYPE"]),
+			Nullable:   strings.EqualFold(oraCol["NULLABLE"], "Y"),
+		}
+		key.ColumnNameT = columnNameT
+		if key.KeyType == compareKeyTypeOther {
+			return nil, fmt.Sprintf("chunk key column [%s] datatype [%s] isn't support key range split", columnName, oraCol["DATA_TYPE"]), nil
+		}
+		if key.KeyType == compareKeyTypeString {
+			// oracle 12.2 以下版本无字段级 collation，以数据库 NLS_COMP 为准，compare 前置要求 NLS_COMP 受支持
+			oraCollation := common.StringUPPER(oraCol["COLLATION"])
+			if oraCollation == "" {
+				oraCollation = "BINARY"
+			}
+			// NLSSORT 或者 CAST AS BINARY 转换字节比较无法使用索引，仅非字节比较一侧转换
+			key.OracleNonBinary = !common.IsOracleBinaryCollation(oraCollation)
+			key.MySQLNonBinary = !common.IsMySQLBinaryCollation(mysqlCol["COLLATION_NAME"])
+			if key.OracleNonBinary || key.MySQLNonBinary {
+				zap.L().Warn("chunk key column collation isn't binary, key range predicate compare by binary and can't use index",
+					zap.String("schema", c.Cfg.OracleConfig.SchemaName),
+					zap.String("table", c.SourceTable),
+					zap.String("column", columnName),
+					zap.String("oracle collation", oraCollation),
+					zap.String("mysql collation", mysqlCol["COLLATION_NAME"]))
+			}
+		}
+		keys = append(keys, key)
+	}
+	return keys, "", nil
+}
+
+func (k ChunkKey) oracleValue(v string) string {
+	if k.KeyType == compareKeyTypeString {
+		return common.StringsBuilder("'", strings.ReplaceAll(v, "'", "''"), "'")
+	}
+	return v
+}
+
+// mysqlValue mysql 字符串反斜杠转义
+func (k ChunkKey) mysqlValue(v string) string {
+	if k.KeyType == compareKeyTypeString {
+		return common.StringsBuilder("'", strings.ReplaceAll(strings.ReplaceAll(v, `\`, `\\`), "'", "''"), "'")
+	}
+	return v
+}
+
+// oracleCond oracle 区间比较条件，非字节比较排序规则字符字段转换字节比较
+func (k ChunkKey) oracleCond(op, v string) string {
+	if k.KeyType == compareKeyTypeString && k.OracleNonBinary {
+		return common.StringsBuilder("NLSSORT(", k.ColumnName, ",'NLS_SORT=BINARY')", op, "NLSSORT(", k.oracleValue(v), ",'NLS_SORT=BINARY')")
+	}
+	return common.StringsBuilder(k.ColumnName, op, k.oracleValue(v))
+}
+
+// mysqlCond mysql 区间比较条件，非字节比较排序规则字符字段转换字节比较
+func (k ChunkKey) mysqlCond(op, v string) string {
+	if k.KeyType == compareKeyTypeString && k.MySQLNonBinary {
+		return common.StringsBuilder("CAST(", k.targetColumn(), " AS BINARY)", op, "CAST(", k.mysqlValue(v), " AS BINARY)")
+	}
+	return common.StringsBuilder(k.targetColumn(), op, k.mysqlValue(v))
+}
+
+// genKeyRangeCond 多字段键值字典序比较条件展开
+// (k1,k2) >= (v1,v2) -> (k1 > v1) OR (k1 = v1 AND k2 >= v2)
+// (k1,k2) < (v1,v2) -> (k1 < v1) OR (k1 = v1 AND k2 < v2)
+func genKeyRangeCond(keys []ChunkKey, values []string, lower bool, isOracle bool) string {
+	strictOp, lastOp := " < ", " < "
+	if lower {
+		strictOp, lastOp = " > ", " >= "
+	}
+	var orCond []string
+	for i := range keys {
+		var andCond []string
+		for j := 0; j <= i; j++ {
+			op := " = "
+			if j == i {
+				op = strictOp
+				if i == len(keys)-1 {
+					op = lastOp
+				}
+			}
+			if isOracle {
+				andCond = append(andCond, keys[j].oracleCond(op, values[j]))
+			} else {
+				andCond = append(andCond, keys[j].mysqlCond(op, values[j]))
+			}
+		}
+		orCond = append(orCond, common.StringsBuilder("(", strings.Join(andCond, " AND "), ")"))
+	}
+	return strings.Join(orCond, " OR ")
+}
+
+// genKeyChunkRange 区间 [lower, upper) 上下游 where 条件，lower/upper 为空表示无边界，可空字段排除 NULL 值
+func genKeyChunkRange(keys []ChunkKey, lower, upper []string, isOracle bool) string {
+	var cond []string
+	for _, k := range keys {
+		if k.Nullable {
+			column := k.targetColumn()
+			if isOracle {
+				column = k.ColumnName
+			}
+			cond = append(cond, common.StringsBuilder(column, " IS NOT NULL"))
+		}
+	}
+	if lower != nil {
+		cond = append(cond, common.StringsBuilder("(", genKeyRangeCond(keys, lower, true, isOracle), ")"))
+	}
+	if upper != nil {
+		cond = append(cond, common.StringsBuilder("(", genKeyRangeCond(keys, upper, false, isOracle), ")"))
+	}
+	if len(cond) == 0 {
+		return "1 = 1"
+	}
+	return strings.Join(cond, " AND ")
+}
+
+// SplitByFullTable 键值区间无法切分，整表单 chunk 对比
+func (c *Chunk) SplitByFullTable(reason string) error {
+	zap.L().Warn("oracle table can't split chunk by key range, fallback full table single chunk",
+		zap.String("schema", c.Cfg.OracleConfig.SchemaName),
+		zap.String("table", c.SourceTable),
+		zap.String("where", "1 = 1"),
+		zap.String("reason", reason))
+
+	c.WhereRange = "1 = 1"
+	c.WhereColumn = ""
+	return meta.NewCommonModel(c.MetaDB).CreateDataCompareMetaAndUpdateWaitSyncMeta(c.Ctx, &meta.DataCompareMeta{
+		DBTypeS:       c.Cfg.DBTypeS,
+		DBTypeT:       c.Cfg.DBTypeT,
+		SchemaNameS:   common.StringUPPER(c.Cfg.OracleConfig.SchemaName),
+		TableNameS:    common.StringUPPER(c.SourceTable),
+		ColumnDetailS: c.SourceColumnInfo,
+		SchemaNameT:   common.StringUPPER(c.Cfg.MySQLConfig.SchemaName),
+		TableNameT:    common.StringUPPER(c.TargetTable),
+		ColumnDetailT: c.TargetColumnInfo,
+		WhereColumn:   c.WhereColumn,
+		WhereRange:    c.WhereRange,
+		TaskMode:      c.Cfg.TaskMode,
+		TaskStatus:    common.TaskStatusWaiting,
+		IsPartition:   c.IsPartition,
+	}, &meta.WaitSyncMeta{
+		DBTypeS:          c.Cfg.DBTypeS,
+		DBTypeT:          c.Cfg.DBTypeT,
+		SchemaNameS:      common.StringUPPER(c.Cfg.OracleConfig.SchemaName),
+		TableNameS:       common.StringUPPER(c.SourceTable),
+		TaskMode:         c.Cfg.TaskMode,
+		GlobalScnS:       c.SourceGlobalSCN,
+		ChunkTotalNums:   1,
+		ChunkSuccessNums: 0,
+		ChunkFailedNums:  0,
+		IsPartition:      c.IsPartition,
+	})
+}
+
+// SampleChunkKeyBoundary oracle SAMPLE 抽样键值按字节序排序，按 chunk 数等距选取区间边界
+// 抽样比例以 chunk 数 * keyChunkSamplePerChunk 估算，比例超过 100 全表扫描键值
+func (c *Chunk) SampleChunkKeyBoundary(keys []ChunkKey, tableRows int) ([][]string, error) {
+	chunkNums := tableRows / c.Cfg.DiffConfig.ChunkSize
+	if tableRows%c.Cfg.DiffConfig.ChunkSize != 0 {
+		chunkNums++
+	}
+	if chunkNums <= 1 {
+		return nil, nil
+	}
+
+	var (
+		columns, notNull, orders []string
+	)
+	for _, k := range keys {
+		columns = append(columns, common.StringsBuilder("T.", k.ColumnName))
+		notNull = append(notNull, common.StringsBuilder("T.", k.ColumnName, " IS NOT NULL"))
+		orders = append(orders, k.OracleOrderBy("T"))
+	}
+
+	tableName := common.StringsBuilder(common.StringUPPER(c.Cfg.OracleConfig.SchemaName), ".", common.StringUPPER(c.SourceTable))
+	samplePercent := float64(chunkNums*keyChunkSamplePerChunk) * 100 / float64(tableRows)
+	if samplePercent < 100 {
+		tableName = common.StringsBuilder(tableName, " SAMPLE (", strconv.FormatFloat(samplePercent, 'f', 6, 64), ")")
+	}
+	querySQL := common.StringsBuilder("SELECT ", strings.Join(columns, ","), " FROM ", tableName, " T WHERE ",
+		strings.Join(notNull, " AND "), " ORDER BY ", strings.Join(orders, ","))
+
+	_, res, err := oracle.Query(c.Ctx, c.Oracle.OracleDB, querySQL)
+	if err != nil {
+		return nil, err
+	}
+	if len(res) < chunkNums {
+		chunkNums = len(res)
+	}
+	if chunkNums <= 1 {
+		return nil, nil
+	}
+
+	var boundaries [][]string
+	step := len(res) / chunkNums
+	for i := step; i < len(res); i += step {
+		var values []string
+		for _, k := range keys {
+			values = append(values, res[i][k.ColumnName])
+		}
+		if len(boundaries) > 0 && strings.Join(boundaries[len(boundaries)-1], ",") == strings.Join(values, ",") {
+			continue
+		}
+		boundaries = append(boundaries, values)
+	}
+	return boundaries, nil
+}
+
+// SplitByKey 单字段或者联合 pk/uk 键值区间切分 chunk，适用于非 NUMBER 字段或者联合键
+// 上下游区间条件不一致（字段名映射或者字符串转义不同）记录目标端 where_range_t
+func (c *Chunk) SplitByKey(keys []ChunkKey, tableRows int) error {
+	boundaries, err := c.SampleChunkKeyBoundary(keys, tableRows)
+	if err != nil {
+		return err
+	}
+
+	var fullMetas []meta.DataCompareMeta
+	appendMeta := func(oracleRange, mysqlRange string) {
+		if strings.EqualFold(oracleRange, mysqlRange) {
+			mysqlRange = ""
+		}
+		fullMetas = append(fullMetas, meta.DataCompareMeta{
+			DBTypeS:       c.Cfg.DBTypeS,
+			DBTypeT:       c.Cfg.DBTypeT,
+			SchemaNameS:   common.StringUPPER(c.Cfg.OracleConfig.SchemaName),
+			TableNameS:    common.StringUPPER(c.SourceTable),
+			SchemaNameT:   common.StringUPPER(c.Cfg.MySQLConfig.SchemaName),
+			TableNameT:    common.StringUPPER(c.TargetTable),
+			ColumnDetailS: c.SourceColumnInfo,
+			ColumnDetailT: c.TargetColumnInfo,
+			WhereRange:    oracleRange,
+			WhereRangeT:   mysqlRange,
+			WhereColumn:   "",
+			IsPartition:   c.IsPartition,
+			TaskMode:      c.Cfg.TaskMode,
+			TaskStatus:    common.TaskStatusWaiting})
+	}
+
+	var lower []string
+	for _, upper := range boundaries {
+		appendMeta(genKeyChunkRange(keys, lower, upper, true), genKeyChunkRange(keys, lower, upper, false))
+		lower = upper
+	}
+	appendMeta(genKeyChunkRange(keys, lower, nil, true), genKeyChunkRange(keys, lower, nil, false))
+
+	// 可空唯一键 NULL 值数据行单独 chunk
+	var nullCond, nullCondT []string
+	for _, k := range keys {
+		if k.Nullable {
+			nullCond = append(nullCond, common.StringsBuilder(k.ColumnName, " IS NULL"))
+			nullCondT = append(nullCondT, common.StringsBuilder(k.targetColumn(), " IS NULL"))
+		}
+	}
+	if len(nullCond) > 0 {
+		appendMeta(strings.Join(nullCond, " OR "), strings.Join(nullCondT, " OR "))
+	}
+
+	err = meta.NewCommonModel(c.MetaDB).BatchCreateDataCompareMetaAndUpdateWaitSyncMeta(c.Ctx,
+		fullMetas, c.Cfg.AppConfig.InsertBatchSize, &meta.WaitSyncMeta{
+			DBTypeS:          c.Cfg.DBTypeS,
+			DBTypeT:          c.Cfg.DBTypeT,
+			SchemaNameS:      common.StringUPPER(c.Cfg.OracleConfig.SchemaName),
+			TableNameS:       common.StringUPPER(c.SourceTable),
+			TaskMode:         c.Cfg.TaskMode,
+			GlobalScnS:       c.SourceGlobalSCN,
+			ChunkTotalNums:   int64(len(fullMetas)),
+			ChunkSuccessNums: 0,
+			ChunkFailedNums:  0,
+			IsPartition:      c.IsPartition,
+		})
+	if err != nil {
+		return fmt.Errorf("create table [%s.%s] data_diff_meta [batch size] failed: %v", common.StringUPPER(c.Cfg.OracleConfig.SchemaName), c.SourceTable, err)
+	}
+
+	var keyColumns []string
+	for _, k := range keys {
+		keyColumns = append(keyColumns, k.ColumnName)
+	}
+	zap.L().Info("pre split oracle and mysql table chunk by key range finished",
+		zap.String("schema", c.Cfg.OracleConfig.SchemaName),
+		zap.String("table", c.SourceTable),
+		zap.Strings("key columns", keyColumns),
+		zap.Int("chunks", len(fullMetas)))
+	return nil
+}
@@ -0,0 +1,69 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package o2m
+
+import (
+	"testing"
+)
+
+func TestGenKeyChunkRange(t *testing.T) {
+	idKey := ChunkKey{CompareKey: CompareKey{ColumnName: "ID", ColumnNameT: "ID", KeyType: compareKeyTypeNumber}}
+	binaryKey := ChunkKey{CompareKey: CompareKey{ColumnName: "NAME", ColumnNameT: "NAME", KeyType: compareKeyTypeString}}
+	ciKey := ChunkKey{CompareKey: CompareKey{ColumnName: "NAME", ColumnNameT: "USER_NAME", KeyType: compareKeyTypeString}, MySQLNonBinary: true, Nullable: true}
+	oracleCIKey := ChunkKey{CompareKey: CompareKey{ColumnName: "NAME", ColumnNameT: "NAME", KeyType: compareKeyTypeString}, OracleNonBinary: true}
+
+	cases := []struct {
+		name       string
+		keys       []ChunkKey
+		lower      []string
+		upper      []string
+		wantOracle string
+		wantMySQL  string
+	}{
+		{
+			name: "unbounded", keys: []ChunkKey{idKey},
+			wantOracle: "1 = 1", wantMySQL: "1 = 1",
+		},
+		{
+			name: "binary collation string key keeps raw predicate", keys: []ChunkKey{binaryKey},
+			lower: []string{"a'b"}, upper: []string{`c\d`},
+			wantOracle: `((NAME >= 'a''b')) AND ((NAME < 'c\d'))`,
+			wantMySQL:  `((NAME >= 'a''b')) AND ((NAME < 'c\\d'))`,
+		},
+		{
+			name: "mysql case insensitive collation compares by binary", keys: []ChunkKey{idKey, ciKey},
+			lower:      []string{"1", "abc"},
+			wantOracle: `NAME IS NOT NULL AND ((ID > 1) OR (ID = 1 AND NAME >= 'abc'))`,
+			wantMySQL:  `USER_NAME IS NOT NULL AND ((ID > 1) OR (ID = 1 AND CAST(USER_NAME AS BINARY) >= CAST('abc' AS BINARY)))`,
+		},
+		{
+			name: "oracle linguistic collation compares by binary", keys: []ChunkKey{oracleCIKey},
+			upper:      []string{"Abc"},
+			wantOracle: `((NLSSORT(NAME,'NLS_SORT=BINARY') < NLSSORT('Abc','NLS_SORT=BINARY')))`,
+			wantMySQL:  `((NAME < 'Abc'))`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := genKeyChunkRange(c.keys, c.lower, c.upper, true); got != c.wantOracle {
+				t.Fatalf("oracle range got %s, want %s", got, c.wantOracle)
+			}
+			if got := genKeyChunkRange(c.keys, c.lower, c.upper, false); got != c.wantMySQL {
+				t.Fatalf("mysql range got %s, want %s", got, c.wantMySQL)
+			}
+		})
+	}
+}
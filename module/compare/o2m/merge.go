@@ -0,0 +1,223 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package o2m
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/shopspring/decimal"
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/module/compare"
+	"strings"
+)
+
+// 数据对比排序键字段类型，决定上下游排序方式以及程序端键值比较方式
+const (
+	compareKeyTypeNumber = "NUMBER"
+	compareKeyTypeString = "STRING"
+	compareKeyTypeOther  = "OTHER"
+)
+
+// errCompareKeyOrder 数据库返回数据行未按排序键严格有序，无法 merge 对比
+var errCompareKeyOrder = errors.New("data rows aren't strictly ordered by compare key")
+
+// CompareKey 数据对比排序键字段
+// ColumnNameT 目标端字段名，上下游查询字段别名以目标端字段名为准
+type CompareKey struct {
+	ColumnName  string `json:"column_name"`
+	ColumnNameT string `json:"column_name_t"`
+	KeyType     string `json:"key_type"`
+}
+
+// NewCompareKey 根据 Oracle 字段数据类型确定排序键类型
+func NewCompareKey(columnName, dataType string) CompareKey {
+	key := CompareKey{ColumnName: columnName, ColumnNameT: columnName}
+	switch common.StringUPPER(dataType) {
+	case "NUMBER", "FLOAT", "BINARY_FLOAT", "BINARY_DOUBLE", "INTEGER", "DECIMAL":
+		key.KeyType = compareKeyTypeNumber
+	case "CHAR", "NCHAR", "VARCHAR2", "NVARCHAR2", "VARCHAR":
+		key.KeyType = compareKeyTypeString
+	default:
+		key.KeyType = compareKeyTypeOther
+	}
+	return key
+}
+
+// OracleOrderBy 字符类型以二进制排序，与程序端字节比较保持一致
+func (k CompareKey) OracleOrderBy(tableAlias string) string {
+	if k.KeyType == compareKeyTypeString {
+		return common.StringsBuilder("NLSSORT(", tableAlias, ".", k.ColumnName, ",'NLS_SORT=BINARY')")
+	}
+	return common.StringsBuilder(tableAlias, ".", k.ColumnName)
+}
+
+// MySQLOrderBy 字符类型以二进制排序，与程序端字节比较保持一致
+func (k CompareKey) MySQLOrderBy(tableAlias string) string {
+	if k.KeyType == compareKeyTypeString {
+		return common.StringsBuilder("CAST(", tableAlias, ".", k.targetColumn(), " AS BINARY)")
+	}
+	return common.StringsBuilder(tableAlias, ".", k.targetColumn())
+}
+
+// targetColumn 目标端字段名，未记录目标端字段名与源端一致
+func (k CompareKey) targetColumn() string {
+	if k.ColumnNameT == "" {
+		return k.ColumnName
+	}
+	return k.ColumnNameT
+}
+
+// compareKeyValues 比较两行排序键值，数字类型按数值比较，其他类型按字节比较
+func compareKeyValues(keys []CompareKey, a, b [][]byte) (int, error) {
+	for i, k := range keys {
+		var cmp int
+		if k.KeyType == compareKeyTypeNumber && len(a[i]) > 0 && len(b[i]) > 0 {
+			da, err := decimal.NewFromString(string(a[i]))
+			if err != nil {
+				return 0, fmt.Errorf("compare key [%s] value [%s] decimal convert failed: %v", k.ColumnName, a[i], err)
+			}
+			db, err := decimal.NewFromString(string(b[i]))
+			if err != nil {
+				return 0, fmt.Errorf("compare key [%s] value [%s] decimal convert failed: %v", k.ColumnName, b[i], err)
+			}
+			cmp = da.Cmp(db)
+		} else {
+			cmp = bytes.Compare(a[i], b[i])
+		}
+		if cmp != 0 {
+			return cmp, nil
+		}
+	}
+	return 0, nil
+}
+
+// rowCursor 数据行游标，按排序键有序逐行读取，仅保留当前行
+// 查询字段追加修复字段时，前半部分为对比字段值 values，后半部分为修复 SQL 字段值 fixValues，否则两者相同
+type rowCursor struct {
+	rows        *sql.Rows
+	cols        []string
+	columnTypes []string
+	keys        []CompareKey
+	keyIndex    []int
+	format      func(columnTypes []string, rawResult [][]byte) ([]string, error)
+	rawResult   [][]byte
+	scans       []interface{}
+	width       int
+	values      []string
+	fixValues   []string
+	keyValues   [][]byte
+	eof         bool
+}
+
+func newRowCursor(rows *sql.Rows, keys []CompareKey, withFix bool, format func(columnTypes []string, rawResult [][]byte) ([]string, error)) (*rowCursor, error) {
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	colTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+
+	width := len(cols)
+	if withFix {
+		width, err = compare.SplitFixColumns(cols)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	c := &rowCursor{
+		rows:      rows,
+		cols:      cols[:width],
+		keys:      keys,
+		format:    format,
+		rawResult: make([][]byte, len(cols)),
+		scans:     make([]interface{}, len(cols)),
+		width:     width,
+	}
+	for _, ct := range colTypes {
+		c.columnTypes = append(c.columnTypes, ct.ScanType().String())
+	}
+	for i := range c.rawResult {
+		c.scans[i] = &c.rawResult[i]
+	}
+
+	for _, k := range keys {
+		idx := -1
+		for i, col := range c.cols {
+			if strings.EqualFold(col, k.targetColumn()) {
+				idx = i
+				break
+			}
+		}
+		if idx == -1 {
+			return nil, fmt.Errorf("compare key [%s] isn't exist in query columns [%v]", k.targetColumn(), cols)
+		}
+		c.keyIndex = append(c.keyIndex, idx)
+	}
+	return c, nil
+}
+
+// next 读取下一行，排序键值需严格递增，否则返回 errCompareKeyOrder
+func (c *rowCursor) next() error {
+	if !c.rows.Next() {
+		c.eof = true
+		return c.rows.Err()
+	}
+	if err := c.rows.Scan(c.scans...); err != nil {
+		return err
+	}
+	values, err := c.format(c.columnTypes, c.rawResult)
+	if err != nil {
+		return err
+	}
+
+	// Scan *[]byte 每次复制新切片，可直接引用
+	keyValues := make([][]byte, len(c.keyIndex))
+	for i, idx := range c.keyIndex {
+		keyValues[i] = c.rawResult[idx]
+	}
+	if c.keyValues != nil {
+		cmp, err := compareKeyValues(c.keys, c.keyValues, keyValues)
+		if err != nil {
+			return err
+		}
+		if cmp >= 0 {
+			return errCompareKeyOrder
+		}
+	}
+	c.values, c.fixValues, c.keyValues = values[:c.width], values[c.width:], keyValues
+	if c.width == len(values) {
+		c.fixValues = c.values
+	}
+	return nil
+}
+
+// genKeyWhereCond 根据排序键格式化值生成 WHERE 条件
+func genKeyWhereCond(cols []string, keyIndex []int, values []string) string {
+	var whereCond []string
+	for _, idx := range keyIndex {
+		if strings.EqualFold(values[idx], "NULL") {
+			whereCond = append(whereCond, common.StringsBuilder(cols[idx], " IS NULL"))
+		} else {
+			whereCond = append(whereCond, common.StringsBuilder(cols[idx], "=", values[idx]))
+		}
+	}
+	return strings.Join(whereCond, " AND ")
+}
@@ -0,0 +1,176 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package o2m
+
+import (
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/database/meta"
+	"github.com/wentaojin/transferdb/module/compare"
+	"go.uber.org/zap"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// NewRecheck 差异复检，仅重新校验 FAILED、已输出差异修复或者上次复检中断的 chunk
+// 复检 chunk 重置为 WAITING 并记录重置前状态以及更新时间，按表重新对比，对比完成按原有逻辑更新 data_compare_meta 以及 wait_sync_meta 状态
+func (r *O2M) NewRecheck(startTime time.Time, oraDBVersion string, exporters []string) error {
+	var recheckBefore time.Time
+	if !strings.EqualFold(r.cfg.DiffConfig.RecheckBefore, "") {
+		t, err := time.ParseInLocation("2006-01-02 15:04:05", r.cfg.DiffConfig.RecheckBefore, time.Local)
+		if err != nil {
+			return fmt.Errorf("compare config recheck-before [%s] parse failed, format need be [2006-01-02 15:04:05]: %v", r.cfg.DiffConfig.RecheckBefore, err)
+		}
+		recheckBefore = t
+	}
+
+	oracleCollation, err := r.prepareCompareEnv(oraDBVersion, len(exporters))
+	if err != nil {
+		return err
+	}
+
+	// 先筛选全部待复检 chunk 再重置状态，复检时间窗口以 chunk 复检重置前更新时间为准，重置状态更新时间变化不影响后续复检筛选
+	var (
+		recheckTables []string
+		recheckMetas  []meta.DataCompareMeta
+	)
+	tableNameRuleMap := make(map[string]string)
+	for _, tableName := range exporters {
+		compareMetas, err := meta.NewDataCompareMetaModel(r.metaDB).DetailDataCompareMeta(r.ctx, &meta.DataCompareMeta{
+			DBTypeS:     r.cfg.DBTypeS,
+			DBTypeT:     r.cfg.DBTypeT,
+			SchemaNameS: common.StringUPPER(r.cfg.OracleConfig.SchemaName),
+			TableNameS:  common.StringUPPER(tableName),
+			TaskMode:    r.cfg.TaskMode,
+		})
+		if err != nil {
+			return err
+		}
+
+		var recheckChunks int
+		for _, cm := range compareMetas {
+			if !isRecheckChunk(cm, recheckBefore) {
+				continue
+			}
+			recheckMetas = append(recheckMetas, cm)
+			recheckChunks++
+			tableNameRuleMap[common.StringUPPER(cm.TableNameS)] = common.StringUPPER(cm.TableNameT)
+		}
+		if recheckChunks > 0 {
+			recheckTables = append(recheckTables, common.StringUPPER(tableName))
+		}
+	}
+	chunkTotals := len(recheckMetas)
+
+	for _, cm := range recheckMetas {
+		updates := map[string]interface{}{
+			"TaskStatus":  common.TaskStatusWaiting,
+			"ErrorDetail": "",
+		}
+		// 上次复检中断的 chunk 保留原始状态以及时间
+		if strings.EqualFold(cm.RecheckStatus, "") {
+			updates["RecheckStatus"] = cm.TaskStatus
+			if cm.BaseModel != nil {
+				updates["RecheckTime"] = cm.UpdatedAt
+			}
+		}
+		err = meta.NewDataCompareMetaModel(r.metaDB).UpdateDataCompareMeta(r.ctx, &meta.DataCompareMeta{
+			DBTypeS:     cm.DBTypeS,
+			DBTypeT:     cm.DBTypeT,
+			SchemaNameS: cm.SchemaNameS,
+			TableNameS:  cm.TableNameS,
+			TaskMode:    cm.TaskMode,
+			WhereRange:  cm.WhereRange,
+		}, updates)
+		if err != nil {
+			return err
+		}
+	}
+
+	zap.L().Info("compare recheck chunks",
+		zap.String("schema", r.cfg.OracleConfig.SchemaName),
+		zap.String("recheck before", r.cfg.DiffConfig.RecheckBefore),
+		zap.Strings("recheck tables", recheckTables),
+		zap.Int("recheck chunks", chunkTotals))
+
+	if len(recheckTables) == 0 {
+		zap.L().Warn("there are no failed or repaired chunks need recheck",
+			zap.String("schema", r.cfg.OracleConfig.SchemaName))
+		return nil
+	}
+
+	err = common.PathExist(r.cfg.DiffConfig.FixSqlDir)
+	if err != nil {
+		return err
+	}
+	checkFile := filepath.Join(r.cfg.DiffConfig.FixSqlDir, fmt.Sprintf("recheck_%s.sql", r.cfg.OracleConfig.SchemaName))
+	f, err := compare.NewWriter(checkFile)
+	if err != nil {
+		return err
+	}
+
+	// 复检排序键按字段名自定义规则生成目标端字段名
+	columnNameRuleMap, err := meta.NewColumnNameRuleModel(r.metaDB).DetailSchemaColumnNameRule(r.ctx, &meta.ColumnNameRule{
+		DBTypeS:     r.cfg.DBTypeS,
+		DBTypeT:     r.cfg.DBTypeT,
+		SchemaNameS: r.cfg.OracleConfig.SchemaName,
+	})
+	if err != nil {
+		return err
+	}
+	recheckTasks := NewWaitCompareTableTask(r.ctx, r.cfg, recheckTables, oracleCollation, r.mysql, r.oracle, tableNameRuleMap, columnNameRuleMap)
+	if err = r.comparePartTableTasks(f, recheckTasks, []string{common.TaskStatusWaiting}); err != nil {
+		return err
+	}
+
+	if err = f.Close(); err != nil {
+		return err
+	}
+
+	summary, err := compare.GenCompareSummary(r.ctx, r.metaDB, r.cfg.DBTypeS, r.cfg.DBTypeT, r.cfg.OracleConfig.SchemaName, r.cfg.MySQLConfig.SchemaName, r.cfg.TaskMode, startTime, checkFile)
+	if err != nil {
+		return err
+	}
+	if err = compare.WriteCompareSummary(summary, r.cfg.DiffConfig.FixSqlDir); err != nil {
+		return err
+	}
+
+	zap.L().Info("compare recheck table oracle to mysql finished",
+		zap.String("fix sql file output", checkFile),
+		zap.Int("recheck tables", len(recheckTables)),
+		zap.Int("recheck chunks", chunkTotals),
+		zap.Int("chunk different", summary.ChunkDifferent),
+		zap.Int("chunk failed", summary.ChunkFailed),
+		zap.String("cost", time.Now().Sub(startTime).String()))
+	return nil
+}
+
+// isRecheckChunk 判断 chunk 是否需要复检
+// 复检时间窗口以复检重置前更新时间为准，上次复检中断（WAITING 且记录复检重置前状态）的 chunk 继续复检
+func isRecheckChunk(cm meta.DataCompareMeta, recheckBefore time.Time) bool {
+	interrupted := strings.EqualFold(cm.TaskStatus, common.TaskStatusWaiting) && !strings.EqualFold(cm.RecheckStatus, "")
+	if !interrupted && !strings.EqualFold(cm.TaskStatus, common.TaskStatusFailed) && strings.EqualFold(cm.RepairStatus, "") {
+		return false
+	}
+	if recheckBefore.IsZero() {
+		return true
+	}
+	if cm.RecheckTime != nil {
+		return cm.RecheckTime.Before(recheckBefore)
+	}
+	return cm.BaseModel == nil || cm.UpdatedAt.Before(recheckBefore)
+}
@@ -0,0 +1,82 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package o2m
+
+import (
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"go.uber.org/zap"
+	"strings"
+)
+
+// repairChunk 差异修复 SQL 自暂存文件按批次读取事务下游执行，执行后重新校验 chunk，返回修复状态以及详情
+// repair-dry-run 仅日志按批次输出待执行修复 SQL，返回空状态不记录
+func (r *O2M) repairChunk(report *Report) (string, string) {
+	fixCounts := report.FixSpool.Counts
+	if fixCounts == 0 {
+		return common.CompareRepairStatusDifferent, "chunk fix sql isn't exist, please check data rows diff"
+	}
+
+	batchSize := r.cfg.DiffConfig.RepairBatchSize
+	if batchSize <= 0 {
+		batchSize = r.cfg.AppConfig.InsertBatchSize
+	}
+
+	if r.cfg.DiffConfig.RepairDryRun {
+		if err := report.FixSpool.ReadSQL(batchSize, func(sqls []string) error {
+			zap.L().Info("compare repair dry run",
+				zap.String("oracle schema", report.DataCompareMeta.SchemaNameS),
+				zap.String("oracle table", report.DataCompareMeta.TableNameS),
+				zap.String("range", report.DataCompareMeta.WhereRange),
+				zap.Int64("fix sql counts", fixCounts),
+				zap.Strings("fix sql", sqls))
+			return nil
+		}); err != nil {
+			zap.L().Warn("compare repair dry run read fix sql failed",
+				zap.String("oracle schema", report.DataCompareMeta.SchemaNameS),
+				zap.String("oracle table", report.DataCompareMeta.TableNameS),
+				zap.String("range", report.DataCompareMeta.WhereRange),
+				zap.Error(err))
+		}
+		return "", ""
+	}
+
+	if err := report.FixSpool.ReadSQL(batchSize, func(sqls []string) error {
+		return r.mysql.ApplyMySQLFixSQL(sqls, len(sqls))
+	}); err != nil {
+		return common.CompareRepairStatusFailed, err.Error()
+	}
+
+	// 重新校验 chunk
+	recheck := NewReport(report.DataCompareMeta, r.mysql, r.oracle, report.OnlyCheckRows, report.ChecksumFirst, report.CompareKeys, report.SnapshotSCN, report.Sample, "", false)
+	recheck.FixColumnS, recheck.FixColumnT = report.FixColumnS, report.FixColumnT
+	recheck.ColumnEpsilons = report.ColumnEpsilons
+	defer recheck.Close()
+	diff, err := IReport(recheck)
+	if err != nil {
+		return common.CompareRepairStatusFailed, fmt.Sprintf("chunk fix sql [%d] applied, recheck failed: %v", fixCounts, err)
+	}
+	if !strings.EqualFold(diff, "") {
+		return common.CompareRepairStatusDifferent, fmt.Sprintf("chunk fix sql [%d] applied, recheck data still isn't equal", fixCounts)
+	}
+
+	zap.L().Info("compare repair chunk fixed",
+		zap.String("oracle schema", report.DataCompareMeta.SchemaNameS),
+		zap.String("oracle table", report.DataCompareMeta.TableNameS),
+		zap.String("range", report.DataCompareMeta.WhereRange),
+		zap.Int64("fix sql counts", fixCounts))
+	return common.CompareRepairStatusFixed, fmt.Sprintf("chunk fix sql [%d] applied, recheck data equal", fixCounts)
+}
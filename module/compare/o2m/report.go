@@ -16,17 +16,22 @@ limitations under the License.
 package o2m
 
 import (
+	"context"
+	"database/sql"
 	"encoding/json"
 	"fmt"
 	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/scylladb/go-set/strset"
-	"github.com/thinkeridea/go-extend/exstrings"
+	"github.com/shopspring/decimal"
 	"github.com/wentaojin/transferdb/common"
 	"github.com/wentaojin/transferdb/database/meta"
 	"github.com/wentaojin/transferdb/database/mysql"
 	"github.com/wentaojin/transferdb/database/oracle"
+	"github.com/wentaojin/transferdb/module/compare"
 	"go.uber.org/zap"
 	"golang.org/x/sync/errgroup"
+	"hash/crc32"
+	"strconv"
 	"strings"
 )
 
@@ -35,6 +40,8 @@ type DBSummary struct {
 	StringSet *strset.Set
 	Crc32Val  uint32
 	Rows      int64
+	// FixRows 对比数据行到修复 SQL 数据行映射，字段校验规则未改变字段值时为空
+	FixRows map[string]string
 }
 
 type Report struct {
@@ -42,32 +49,133 @@ type Report struct {
 	Mysql           *mysql.MySQL         `json:"-"`
 	Oracle          *oracle.Oracle       `json:"-"`
 	OnlyCheckRows   bool                 `json:"only_check_rows"`
+	ChecksumFirst   bool                 `json:"checksum_first"`
+	CompareKeys     []CompareKey         `json:"compare_keys"`
+	SnapshotSCN     uint64               `json:"snapshot_scn"`
+	OracleRows      int64                `json:"oracle_rows"`
+	MySQLRows       int64                `json:"mysql_rows"`
+	Sample          SampleOption         `json:"sample"`
+	SampleRows      int64                `json:"sample_rows"`
+	SampleDiff      int64                `json:"sample_diff"`
+	SampleSummary   string               `json:"sample_summary"`
+	Repair          bool                 `json:"repair"`
+	// 修复 SQL 字段查询，不应用字段校验规则，与对比字段相同时无需额外查询
+	FixColumnS string `json:"-"`
+	FixColumnT string `json:"-"`
+	// 对比字段 numeric-epsilon 容差，与对比字段顺序一致，数据行对比按 ABS(a-b) <= epsilon 判断
+	ColumnEpsilons []float64 `json:"column_epsilons"`
+	// 修复 SQL 逐条写入暂存文件，差异自动修复另行暂存修复 SQL 按批次读取下游执行
+	FixSpool *compare.Spool `json:"-"`
 }
 
-func NewReport(dataCompareMeta meta.DataCompareMeta, mysql *mysql.MySQL, oracle *oracle.Oracle, onlyCheckRows bool) *Report {
+func NewReport(dataCompareMeta meta.DataCompareMeta, mysql *mysql.MySQL, oracle *oracle.Oracle, onlyCheckRows, checksumFirst bool, compareKeys []CompareKey, snapshotSCN uint64, sample SampleOption, fixSqlDir string, repair bool) *Report {
 	return &Report{
 		DataCompareMeta: dataCompareMeta,
 		Mysql:           mysql,
 		Oracle:          oracle,
 		OnlyCheckRows:   onlyCheckRows,
+		ChecksumFirst:   checksumFirst,
+		CompareKeys:     compareKeys,
+		SnapshotSCN:     snapshotSCN,
+		Sample:          sample,
+		Repair:          repair,
+		FixSpool:        compare.NewSpool(fixSqlDir, repair),
 	}
 }
 
+// OracleTableName 快照一致性校验 oracle 表 AS OF SCN 查询
+func (r *Report) OracleTableName() string {
+	if r.SnapshotSCN > 0 {
+		return common.StringsBuilder(r.DataCompareMeta.SchemaNameS, ".", r.DataCompareMeta.TableNameS, " AS OF SCN ", strconv.FormatUint(r.SnapshotSCN, 10))
+	}
+	return common.StringsBuilder(r.DataCompareMeta.SchemaNameS, ".", r.DataCompareMeta.TableNameS)
+}
+
+// MySQLWhereRange 目标端 where 条件，键值区间切分字段比较规则不一致时与源端条件不同
+func (r *Report) MySQLWhereRange() string {
+	if r.DataCompareMeta.WhereRangeT != "" {
+		return r.DataCompareMeta.WhereRangeT
+	}
+	return r.DataCompareMeta.WhereRange
+}
+
 func (r *Report) GenDBQuery() (oracleQuery string, mysqlQuery string) {
+	return r.genDBQuery(r.DataCompareMeta.ColumnDetailS, r.DataCompareMeta.ColumnDetailT)
+}
+
+// GenDBFixQuery 数据行对比查询追加修复字段，用于差异数据行生成修复 SQL
+func (r *Report) GenDBFixQuery() (oracleQuery string, mysqlQuery string) {
+	return r.genDBQuery(r.oracleSelectColumn(), r.mysqlSelectColumn())
+}
+
+func (r *Report) genDBQuery(oracleColumn, mysqlColumn string) (oracleQuery string, mysqlQuery string) {
 	if r.DataCompareMeta.WhereColumn == "" {
 		oracleQuery = common.StringsBuilder(
-			"SELECT ", r.DataCompareMeta.ColumnDetailS, " FROM ", r.DataCompareMeta.SchemaNameS, ".", r.DataCompareMeta.TableNameS, " WHERE ", r.DataCompareMeta.WhereRange)
+			"SELECT ", oracleColumn, " FROM ", r.OracleTableName(), " WHERE ", r.DataCompareMeta.WhereRange)
 
 		mysqlQuery = common.StringsBuilder(
-			"SELECT ", r.DataCompareMeta.ColumnDetailT, " FROM ", r.DataCompareMeta.SchemaNameT, ".", r.DataCompareMeta.TableNameT, " WHERE ", r.DataCompareMeta.WhereRange)
+			"SELECT ", mysqlColumn, " FROM ", r.DataCompareMeta.SchemaNameT, ".", r.DataCompareMeta.TableNameT, " WHERE ", r.MySQLWhereRange())
 	} else {
 		oracleQuery = common.StringsBuilder(
-			"SELECT ", r.DataCompareMeta.ColumnDetailS, " FROM ", r.DataCompareMeta.SchemaNameS, ".", r.DataCompareMeta.TableNameS, " WHERE ", r.DataCompareMeta.WhereRange,
+			"SELECT ", oracleColumn, " FROM ", r.OracleTableName(), " WHERE ", r.DataCompareMeta.WhereRange,
 			" ORDER BY ", r.DataCompareMeta.WhereColumn, " DESC")
 
 		mysqlQuery = common.StringsBuilder(
-			"SELECT ", r.DataCompareMeta.ColumnDetailT, " FROM ", r.DataCompareMeta.SchemaNameT, ".", r.DataCompareMeta.TableNameT, " WHERE ", r.DataCompareMeta.WhereRange, " ORDER BY ", r.DataCompareMeta.WhereColumn, " DESC")
+			"SELECT ", mysqlColumn, " FROM ", r.DataCompareMeta.SchemaNameT, ".", r.DataCompareMeta.TableNameT, " WHERE ", r.MySQLWhereRange(), " ORDER BY ", r.DataCompareMeta.WhereColumn, " DESC")
+	}
+	return
+}
+
+// oracleWithFix 字段校验规则改变源端对比字段值，查询需追加修复字段
+func (r *Report) oracleWithFix() bool {
+	return r.FixColumnS != "" && !strings.EqualFold(r.FixColumnS, r.DataCompareMeta.ColumnDetailS)
+}
+
+// mysqlWithFix 字段校验规则改变目标端对比字段值，查询需追加修复字段
+func (r *Report) mysqlWithFix() bool {
+	return r.FixColumnT != "" && !strings.EqualFold(r.FixColumnT, r.DataCompareMeta.ColumnDetailT)
+}
+
+// oracleSelectColumn 源端对比字段，需要时追加修复字段
+func (r *Report) oracleSelectColumn() string {
+	if r.oracleWithFix() {
+		return common.StringsBuilder(r.DataCompareMeta.ColumnDetailS, ",", r.FixColumnS)
+	}
+	return r.DataCompareMeta.ColumnDetailS
+}
+
+// mysqlSelectColumn 目标端对比字段，需要时追加修复字段
+func (r *Report) mysqlSelectColumn() string {
+	if r.mysqlWithFix() {
+		return common.StringsBuilder(r.DataCompareMeta.ColumnDetailT, ",", r.FixColumnT)
+	}
+	return r.DataCompareMeta.ColumnDetailT
+}
+
+// GenDBChecksumQuery chunk checksum 子查询，无需排序
+func (r *Report) GenDBChecksumQuery() (oracleQuery string, mysqlQuery string) {
+	oracleQuery = common.StringsBuilder(
+		"SELECT ", r.DataCompareMeta.ColumnDetailS, " FROM ", r.OracleTableName(), " WHERE ", r.DataCompareMeta.WhereRange)
+
+	mysqlQuery = common.StringsBuilder(
+		"SELECT ", r.DataCompareMeta.ColumnDetailT, " FROM ", r.DataCompareMeta.SchemaNameT, ".", r.DataCompareMeta.TableNameT, " WHERE ", r.MySQLWhereRange())
+	return
+}
+
+// GenDBMergeQuery 上下游按排序键升序查询，表别名 T 用于区分排序键原始字段与查询字段别名
+func (r *Report) GenDBMergeQuery() (oracleQuery string, mysqlQuery string) {
+	var oracleOrders, mysqlOrders []string
+	for _, k := range r.CompareKeys {
+		oracleOrders = append(oracleOrders, k.OracleOrderBy("T"))
+		mysqlOrders = append(mysqlOrders, k.MySQLOrderBy("T"))
 	}
+	oracleQuery = common.StringsBuilder(
+		"SELECT ", r.oracleSelectColumn(), " FROM ", r.OracleTableName(), " T WHERE ", r.DataCompareMeta.WhereRange,
+		" ORDER BY ", strings.Join(oracleOrders, ","))
+
+	mysqlQuery = common.StringsBuilder(
+		"SELECT ", r.mysqlSelectColumn(), " FROM ", r.DataCompareMeta.SchemaNameT, ".", r.DataCompareMeta.TableNameT, " T WHERE ", r.MySQLWhereRange(),
+		" ORDER BY ", strings.Join(mysqlOrders, ","))
 	return
 }
 
@@ -121,6 +229,7 @@ func (r *Report) ReportCheckRows() (string, error) {
 
 	oracleRows := <-oracleRowsChan
 	mysqlRows := <-mysqlRowsChan
+	r.OracleRows, r.MySQLRows = oracleRows, mysqlRows
 
 	if oracleRows == mysqlRows {
 		zap.L().Info("oracle table chunk diff equal",
@@ -171,9 +280,17 @@ func (r *Report) ReportCheckCRC32() (string, error) {
 	oraChan := make(chan DBSummary, 1)
 	mysqlChan := make(chan DBSummary, 1)
 
-	oracleQuery, mysqlQuery := r.GenDBQuery()
+	oracleQuery, mysqlQuery := r.GenDBFixQuery()
 
 	errORA.Go(func() error {
+		if r.oracleWithFix() {
+			summary, err := queryFixRowStrings(r.Oracle.Ctx, r.Oracle.OracleDB, oracleQuery, oracle.FormatOracleDataRowValues)
+			if err != nil {
+				return fmt.Errorf("get oracle data row strings failed: %v", err)
+			}
+			oraChan <- summary
+			return nil
+		}
 		oraColumns, oraStringSet, oraCrc32Val, err := r.Oracle.GetOracleDataRowStrings(oracleQuery)
 		if err != nil {
 			return fmt.Errorf("get oracle data row strings failed: %v", err)
@@ -187,6 +304,14 @@ func (r *Report) ReportCheckCRC32() (string, error) {
 	})
 
 	errMySQL.Go(func() error {
+		if r.mysqlWithFix() {
+			summary, err := queryFixRowStrings(r.Mysql.Ctx, r.Mysql.MySQLDB, mysqlQuery, mysql.FormatMySQLDataRowValues)
+			if err != nil {
+				return fmt.Errorf("get mysql data row strings failed: %v", err)
+			}
+			mysqlChan <- summary
+			return nil
+		}
 		mysqlColumns, mysqlStringSet, mysqlCrc32Val, err := r.Mysql.GetMySQLDataRowStrings(mysqlQuery)
 		if err != nil {
 			return fmt.Errorf("get mysql data row strings failed: %v", err)
@@ -208,6 +333,7 @@ func (r *Report) ReportCheckCRC32() (string, error) {
 
 	oraReport := <-oraChan
 	mysqlReport := <-mysqlChan
+	r.OracleRows, r.MySQLRows = int64(oraReport.StringSet.Size()), int64(mysqlReport.StringSet.Size())
 
 	// 数据相同
 	if oraReport.Crc32Val == mysqlReport.Crc32Val {
@@ -223,6 +349,23 @@ func (r *Report) ReportCheckCRC32() (string, error) {
 		return "", nil
 	}
 
+	// 数值字段 numeric-epsilon 容差范围内差异数据行成对剔除
+	sourceMore, targetMore := r.filterEpsilonRows(
+		strset.Difference(oraReport.StringSet, mysqlReport.StringSet).List(),
+		strset.Difference(mysqlReport.StringSet, oraReport.StringSet).List())
+	if len(sourceMore) == 0 && len(targetMore) == 0 {
+		zap.L().Info("oracle table chunk diff equal within numeric epsilon",
+			zap.String("oracle schema", r.DataCompareMeta.SchemaNameS),
+			zap.String("mysql schema", r.DataCompareMeta.SchemaNameT),
+			zap.String("oracle table", r.DataCompareMeta.TableNameS),
+			zap.String("mysql table", r.DataCompareMeta.TableNameT),
+			zap.Uint32("oracle crc32 values", oraReport.Crc32Val),
+			zap.Uint32("mysql crc32 values", mysqlReport.Crc32Val),
+			zap.String("oracle sql", oracleQuery),
+			zap.String("mysql sql", mysqlQuery))
+		return "", nil
+	}
+
 	zap.L().Info("oracle table chunk diff isn't equal",
 		zap.String("oracle schema", r.DataCompareMeta.SchemaNameS),
 		zap.String("mysql schema", r.DataCompareMeta.SchemaNameT),
@@ -238,11 +381,13 @@ func (r *Report) ReportCheckCRC32() (string, error) {
 	//上游存在，下游不存在 INSERT 下游
 	//上游不存在，下游存在 DELETE 下游
 
-	var fixSQL strings.Builder
+	if err := r.resetFixSQL(); err != nil {
+		return "", err
+	}
 
 	// 判断下游数据是否多
-	targetMore := strset.Difference(mysqlReport.StringSet, oraReport.StringSet).List()
 	if len(targetMore) > 0 {
+		var fixSQL strings.Builder
 		fixSQL.WriteString("/*\n")
 		fixSQL.WriteString(fmt.Sprintf(" mysql table [%s.%s] chunk [%s] data rows are more \n", r.DataCompareMeta.SchemaNameT, r.DataCompareMeta.TableNameT, r.DataCompareMeta.WhereRange))
 
@@ -251,65 +396,444 @@ func (r *Report) ReportCheckCRC32() (string, error) {
 		sw.AppendHeader(table.Row{"DATABASE", "DATA COUNTS SQL", "CRC32"})
 		sw.AppendRows([]table.Row{
 			{"ORACLE",
-				common.StringsBuilder("SELECT COUNT(1)", " FROM ", r.DataCompareMeta.SchemaNameS, ".", r.DataCompareMeta.TableNameS, " WHERE ", r.DataCompareMeta.WhereRange),
+				common.StringsBuilder("SELECT COUNT(1)", " FROM ", r.OracleTableName(), " WHERE ", r.DataCompareMeta.WhereRange),
 				oraReport.Crc32Val},
 			{"MySQL", common.StringsBuilder(
-				"SELECT COUNT(1)", " FROM ", r.DataCompareMeta.SchemaNameT, ".", r.DataCompareMeta.TableNameS, " WHERE ", r.DataCompareMeta.WhereRange),
+				"SELECT COUNT(1)", " FROM ", r.DataCompareMeta.SchemaNameT, ".", r.DataCompareMeta.TableNameT, " WHERE ", r.MySQLWhereRange()),
 				mysqlReport.Crc32Val},
 		})
 		fixSQL.WriteString(fmt.Sprintf("%v\n", sw.Render()))
 		fixSQL.WriteString("*/\n")
-		deletePrefix := common.StringsBuilder("DELETE FROM ", r.DataCompareMeta.SchemaNameT, ".", r.DataCompareMeta.TableNameS, " WHERE ")
+		if err := r.FixSpool.WriteString(fixSQL.String()); err != nil {
+			return "", err
+		}
+		deletePrefix := common.StringsBuilder("DELETE FROM ", r.DataCompareMeta.SchemaNameT, ".", r.DataCompareMeta.TableNameT, " WHERE ")
+		colIndex := make([]int, len(mysqlReport.Columns))
+		for i := range colIndex {
+			colIndex[i] = i
+		}
 		for _, t := range targetMore {
-			var whereCond []string
-
-			// 计算字段列个数
-			colValues := strings.Split(t, ",")
+			// 计算字段列个数，字符值内逗号已转义，按单引号边界拆分
+			colValues := common.SplitRowValues(mysqlReport.fixRow(t))
 			if len(mysqlReport.Columns) != len(colValues) {
-				return "", fmt.Errorf("mysql schema [%s] table [%s] column counts [%d] isn't match values counts [%d]", r.DataCompareMeta.SchemaNameT, r.DataCompareMeta.TableNameS, len(mysqlReport.Columns), len(colValues))
-			}
-			for i := 0; i < len(mysqlReport.Columns); i++ {
-				whereCond = append(whereCond, common.StringsBuilder(mysqlReport.Columns[i], "=", colValues[i]))
+				return "", fmt.Errorf("mysql schema [%s] table [%s] column counts [%d] isn't match values counts [%d]", r.DataCompareMeta.SchemaNameT, r.DataCompareMeta.TableNameT, len(mysqlReport.Columns), len(colValues))
 			}
 
-			fixSQL.WriteString(fmt.Sprintf("%v;\n", common.StringsBuilder(deletePrefix, exstrings.Join(whereCond, " AND "))))
+			if err := r.writeFixSQL(common.StringsBuilder(deletePrefix, genKeyWhereCond(mysqlReport.Columns, colIndex, colValues), " LIMIT 1")); err != nil {
+				return "", err
+			}
 		}
 	}
 
 	// 判断上游数据是否多
-	sourceMore := strset.Difference(oraReport.StringSet, mysqlReport.StringSet).List()
 	if len(sourceMore) > 0 {
+		var fixSQL strings.Builder
 		fixSQL.WriteString("/*\n")
-		fixSQL.WriteString(fmt.Sprintf(" mysql table [%s.%s] chunk [%s] data rows are less \n", r.DataCompareMeta.SchemaNameT, r.DataCompareMeta.TableNameS, r.DataCompareMeta.WhereRange))
+		fixSQL.WriteString(fmt.Sprintf(" mysql table [%s.%s] chunk [%s] data rows are less \n", r.DataCompareMeta.SchemaNameT, r.DataCompareMeta.TableNameT, r.DataCompareMeta.WhereRange))
 
 		sw := table.NewWriter()
 		sw.SetStyle(table.StyleLight)
 		sw.AppendHeader(table.Row{"DATABASE", "DATA COUNTS SQL", "CRC32"})
 		sw.AppendRows([]table.Row{
 			{"ORACLE",
-				common.StringsBuilder("SELECT COUNT(1)", " FROM ", r.DataCompareMeta.SchemaNameS, ".", r.DataCompareMeta.TableNameS, " WHERE ", r.DataCompareMeta.WhereRange),
+				common.StringsBuilder("SELECT COUNT(1)", " FROM ", r.OracleTableName(), " WHERE ", r.DataCompareMeta.WhereRange),
 				oraReport.Crc32Val},
 			{"MySQL", common.StringsBuilder(
-				"SELECT COUNT(1)", " FROM ", r.DataCompareMeta.SchemaNameT, ".", r.DataCompareMeta.TableNameS, " WHERE ", r.DataCompareMeta.WhereRange),
+				"SELECT COUNT(1)", " FROM ", r.DataCompareMeta.SchemaNameT, ".", r.DataCompareMeta.TableNameT, " WHERE ", r.MySQLWhereRange()),
 				mysqlReport.Crc32Val},
 		})
 		fixSQL.WriteString(fmt.Sprintf("%v\n", sw.Render()))
 		fixSQL.WriteString("*/\n")
-		insertPrefix := common.StringsBuilder("INSERT INTO ", r.DataCompareMeta.SchemaNameT, ".", r.DataCompareMeta.TableNameS, " (", strings.Join(oraReport.Columns, ","), ") VALUES (")
+		if err := r.FixSpool.WriteString(fixSQL.String()); err != nil {
+			return "", err
+		}
+		insertPrefix := common.StringsBuilder("INSERT INTO ", r.DataCompareMeta.SchemaNameT, ".", r.DataCompareMeta.TableNameT, " (", strings.Join(oraReport.Columns, ","), ") VALUES (")
 		for _, s := range sourceMore {
-			fixSQL.WriteString(fmt.Sprintf("%v;\n", common.StringsBuilder(insertPrefix, s, ")")))
+			if err := r.writeFixSQL(common.StringsBuilder(insertPrefix, oraReport.fixRow(s), ")")); err != nil {
+				return "", err
+			}
+		}
+	}
+	return fmt.Sprintf("/*\n mysql table [%s.%s] chunk [%s] data rows aren't equal\n*/\n", r.DataCompareMeta.SchemaNameT, r.DataCompareMeta.TableNameT, r.DataCompareMeta.WhereRange), nil
+}
+
+// columnEpsilon 对比字段 numeric-epsilon 容差，未配置容差字段为 0
+func (r *Report) columnEpsilon(i int) float64 {
+	if i < len(r.ColumnEpsilons) {
+		return r.ColumnEpsilons[i]
+	}
+	return 0
+}
+
+// filterEpsilonRows 无排序键整行集合对比，上下游差异数据行按 numeric-epsilon 容差配对，容差范围内数据行视为一致剔除
+func (r *Report) filterEpsilonRows(sourceMore, targetMore []string) ([]string, []string) {
+	sourceRows := make([][]string, 0, len(sourceMore))
+	for _, s := range sourceMore {
+		sourceRows = append(sourceRows, common.SplitRowValues(s))
+	}
+	targetRows := make([][]string, 0, len(targetMore))
+	for _, t := range targetMore {
+		targetRows = append(targetRows, common.SplitRowValues(t))
+	}
+	sourceIdx, targetIdx := common.FilterCompareEpsilonRows(sourceRows, targetRows, r.ColumnEpsilons)
+
+	sourceRemain := make([]string, 0, len(sourceIdx))
+	for _, i := range sourceIdx {
+		sourceRemain = append(sourceRemain, sourceMore[i])
+	}
+	targetRemain := make([]string, 0, len(targetIdx))
+	for _, i := range targetIdx {
+		targetRemain = append(targetRemain, targetMore[i])
+	}
+	return sourceRemain, targetRemain
+}
+
+// ReportCheckChecksum 上下游数据库端计算 chunk checksum，返回 chunk 数据是否一致
+func (r *Report) ReportCheckChecksum() (bool, error) {
+	oracleQuery, mysqlQuery := r.GenDBChecksumQuery()
+	g1 := &errgroup.Group{}
+	g2 := &errgroup.Group{}
+
+	var (
+		oracleRows, mysqlRows         int64
+		oracleChecksum, mysqlChecksum string
+	)
+	g1.Go(func() error {
+		rows, checksum, err := r.Oracle.GetOracleDataChunkChecksum(oracleQuery)
+		if err != nil {
+			return fmt.Errorf("get oracle data chunk checksum failed: %v", err)
+		}
+		oracleRows, oracleChecksum = rows, checksum
+		return nil
+	})
+
+	g2.Go(func() error {
+		rows, checksum, err := r.Mysql.GetMySQLDataChunkChecksum(mysqlQuery)
+		if err != nil {
+			return fmt.Errorf("get mysql data chunk checksum failed: %v", err)
 		}
+		mysqlRows, mysqlChecksum = rows, checksum
+		return nil
+	})
+
+	if err := g1.Wait(); err != nil {
+		return false, err
+	}
+	if err := g2.Wait(); err != nil {
+		return false, err
 	}
-	return fixSQL.String(), nil
+
+	oraDecimal, err := decimal.NewFromString(oracleChecksum)
+	if err != nil {
+		return false, fmt.Errorf("oracle checksum [%s] decimal convert failed: %v", oracleChecksum, err)
+	}
+	mysqlDecimal, err := decimal.NewFromString(mysqlChecksum)
+	if err != nil {
+		return false, fmt.Errorf("mysql checksum [%s] decimal convert failed: %v", mysqlChecksum, err)
+	}
+
+	isEqual := oracleRows == mysqlRows && oraDecimal.Equal(mysqlDecimal)
+	r.OracleRows, r.MySQLRows = oracleRows, mysqlRows
+
+	zap.L().Info("oracle table chunk checksum diff",
+		zap.String("oracle schema", r.DataCompareMeta.SchemaNameS),
+		zap.String("mysql schema", r.DataCompareMeta.SchemaNameT),
+		zap.String("oracle table", r.DataCompareMeta.TableNameS),
+		zap.String("mysql table", r.DataCompareMeta.TableNameT),
+		zap.String("range", r.DataCompareMeta.WhereRange),
+		zap.Int64("oracle rows count", oracleRows),
+		zap.Int64("mysql rows count", mysqlRows),
+		zap.String("oracle checksum", oracleChecksum),
+		zap.String("mysql checksum", mysqlChecksum),
+		zap.Bool("equal", isEqual))
+	return isEqual, nil
+}
+
+// ReportCheckMerge 上下游按排序键有序流式读取数据行 merge 对比，内存仅保留当前行
+// 上游存在，下游不存在 INSERT 下游
+// 上游不存在，下游存在 DELETE 下游
+// 上下游均存在字段值不一致，UPDATE 下游不一致字段
+func (r *Report) ReportCheckMerge() (string, error) {
+	if err := r.resetFixSQL(); err != nil {
+		return "", err
+	}
+	oracleQuery, mysqlQuery := r.GenDBMergeQuery()
+
+	oraRows, err := r.Oracle.OracleDB.QueryContext(r.Oracle.Ctx, oracleQuery)
+	if err != nil {
+		return "", fmt.Errorf("oracle merge sql [%v] query failed: %v", oracleQuery, err)
+	}
+	defer oraRows.Close()
+
+	mysqlRows, err := r.Mysql.MySQLDB.QueryContext(r.Mysql.Ctx, mysqlQuery)
+	if err != nil {
+		return "", fmt.Errorf("mysql merge sql [%v] query failed: %v", mysqlQuery, err)
+	}
+	defer mysqlRows.Close()
+
+	oraCursor, err := newRowCursor(oraRows, r.CompareKeys, r.oracleWithFix(), oracle.FormatOracleDataRowValues)
+	if err != nil {
+		return "", err
+	}
+	mysqlCursor, err := newRowCursor(mysqlRows, r.CompareKeys, r.mysqlWithFix(), mysql.FormatMySQLDataRowValues)
+	if err != nil {
+		return "", err
+	}
+	if len(oraCursor.cols) != len(mysqlCursor.cols) {
+		return "", fmt.Errorf("oracle column counts [%d] and mysql column counts [%d] aren't equal", len(oraCursor.cols), len(mysqlCursor.cols))
+	}
+
+	if err = oraCursor.next(); err != nil {
+		return "", err
+	}
+	if err = mysqlCursor.next(); err != nil {
+		return "", err
+	}
+
+	var (
+		missingRows, extraRows, changedRows int64
+		oracleCounts, mysqlCounts           int64
+	)
+	targetTable := common.StringsBuilder(r.DataCompareMeta.SchemaNameT, ".", r.DataCompareMeta.TableNameT)
+	insertPrefix := common.StringsBuilder("INSERT INTO ", targetTable, " (", strings.Join(oraCursor.cols, ","), ") VALUES (")
+	deletePrefix := common.StringsBuilder("DELETE FROM ", targetTable, " WHERE ")
+
+	for !oraCursor.eof || !mysqlCursor.eof {
+		var cmp int
+		switch {
+		case oraCursor.eof:
+			cmp = 1
+		case mysqlCursor.eof:
+			cmp = -1
+		default:
+			cmp, err = compareKeyValues(r.CompareKeys, oraCursor.keyValues, mysqlCursor.keyValues)
+			if err != nil {
+				return "", err
+			}
+		}
+
+		switch {
+		case cmp < 0:
+			oracleCounts++
+			missingRows++
+			if err = r.writeFixSQL(common.StringsBuilder(insertPrefix, strings.Join(oraCursor.fixValues, ","), ")")); err != nil {
+				return "", err
+			}
+			if err = oraCursor.next(); err != nil {
+				return "", err
+			}
+		case cmp > 0:
+			mysqlCounts++
+			extraRows++
+			if err = r.writeFixSQL(common.StringsBuilder(deletePrefix, genKeyWhereCond(oraCursor.cols, mysqlCursor.keyIndex, mysqlCursor.fixValues))); err != nil {
+				return "", err
+			}
+			if err = mysqlCursor.next(); err != nil {
+				return "", err
+			}
+		default:
+			oracleCounts++
+			mysqlCounts++
+			var setCond []string
+			// 对比字段值判断是否一致，修复 SQL 以修复字段值为准
+			for i, v := range oraCursor.values {
+				if !common.CompareEpsilonEqual(v, mysqlCursor.values[i], r.columnEpsilon(i)) {
+					setCond = append(setCond, common.StringsBuilder(oraCursor.cols[i], "=", oraCursor.fixValues[i]))
+				}
+			}
+			if len(setCond) > 0 {
+				changedRows++
+				if err = r.writeFixSQL(common.StringsBuilder("UPDATE ", targetTable, " SET ", strings.Join(setCond, ","),
+					" WHERE ", genKeyWhereCond(oraCursor.cols, oraCursor.keyIndex, oraCursor.fixValues))); err != nil {
+					return "", err
+				}
+			}
+			if err = oraCursor.next(); err != nil {
+				return "", err
+			}
+			if err = mysqlCursor.next(); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	r.OracleRows, r.MySQLRows = oracleCounts, mysqlCounts
+
+	if missingRows == 0 && extraRows == 0 && changedRows == 0 {
+		zap.L().Info("oracle table chunk diff equal",
+			zap.String("oracle schema", r.DataCompareMeta.SchemaNameS),
+			zap.String("mysql schema", r.DataCompareMeta.SchemaNameT),
+			zap.String("oracle table", r.DataCompareMeta.TableNameS),
+			zap.String("mysql table", r.DataCompareMeta.TableNameT),
+			zap.String("oracle sql", oracleQuery),
+			zap.String("mysql sql", mysqlQuery))
+		return "", nil
+	}
+
+	zap.L().Info("oracle table chunk diff isn't equal",
+		zap.String("oracle schema", r.DataCompareMeta.SchemaNameS),
+		zap.String("mysql schema", r.DataCompareMeta.SchemaNameT),
+		zap.String("oracle table", r.DataCompareMeta.TableNameS),
+		zap.String("mysql table", r.DataCompareMeta.TableNameT),
+		zap.Int64("missing rows", missingRows),
+		zap.Int64("extra rows", extraRows),
+		zap.Int64("changed rows", changedRows),
+		zap.String("oracle sql", oracleQuery),
+		zap.String("mysql sql", mysqlQuery))
+
+	sw := table.NewWriter()
+	sw.SetStyle(table.StyleLight)
+	sw.AppendHeader(table.Row{"SOURCE TABLE", "TARGET TABLE", "RANGE", "MISSING ROWS", "EXTRA ROWS", "CHANGED ROWS"})
+	sw.AppendRows([]table.Row{
+		{
+			common.StringsBuilder(r.DataCompareMeta.SchemaNameS, ".", r.DataCompareMeta.TableNameS),
+			targetTable,
+			r.DataCompareMeta.WhereRange,
+			missingRows,
+			extraRows,
+			changedRows,
+		},
+	})
+
+	return fmt.Sprintf("/*\n mysql table [%s] chunk [%s] data rows aren't equal\n", targetTable, r.DataCompareMeta.WhereRange) +
+		sw.Render() + "\n*/\n", nil
 }
 
 func (r *Report) Report() (string, error) {
 	if r.OnlyCheckRows {
 		return r.ReportCheckRows()
 	}
+	// 抽样校验一致直接返回，不一致且开启升级则继续全量数据对比
+	if r.Sample.Percent > 0 {
+		report, err := r.ReportCheckSample()
+		if err != nil || strings.EqualFold(report, "") || !r.Sample.Escalate {
+			return report, err
+		}
+		zap.L().Warn("oracle table chunk sample diff isn't equal, escalate full data rows compare",
+			zap.String("oracle schema", r.DataCompareMeta.SchemaNameS),
+			zap.String("oracle table", r.DataCompareMeta.TableNameS),
+			zap.String("range", r.DataCompareMeta.WhereRange),
+			zap.String("sample summary", r.SampleSummary))
+	}
+	// checksum 一致直接返回，不一致或者计算失败拉取数据行对比输出差异
+	if r.ChecksumFirst {
+		isEqual, err := r.ReportCheckChecksum()
+		if err != nil {
+			zap.L().Warn("oracle table chunk checksum failed, fallback data rows compare",
+				zap.String("oracle schema", r.DataCompareMeta.SchemaNameS),
+				zap.String("oracle table", r.DataCompareMeta.TableNameS),
+				zap.String("range", r.DataCompareMeta.WhereRange),
+				zap.Error(err))
+		} else if isEqual {
+			return "", nil
+		}
+	}
+	// 存在排序键 merge 对比，数据行未严格有序或者对比失败回退整行集合对比
+	if len(r.CompareKeys) > 0 {
+		report, err := r.ReportCheckMerge()
+		if err == nil {
+			return report, nil
+		}
+		zap.L().Warn("oracle table chunk merge compare failed, fallback data rows set compare",
+			zap.String("oracle schema", r.DataCompareMeta.SchemaNameS),
+			zap.String("oracle table", r.DataCompareMeta.TableNameS),
+			zap.String("range", r.DataCompareMeta.WhereRange),
+			zap.Error(err))
+	}
 	return r.ReportCheckCRC32()
 }
 
+// writeFixSQL 修复 SQL 逐条写入暂存文件，用于输出修复文件以及差异自动修复下游执行
+func (r *Report) writeFixSQL(s string) error {
+	return r.FixSpool.WriteSQL(s)
+}
+
+// resetFixSQL 清空已记录修复 SQL，用于对比失败回退其他对比方式
+func (r *Report) resetFixSQL() error {
+	return r.FixSpool.Reset()
+}
+
+// Close 清理修复 SQL 暂存文件
+func (r *Report) Close() error {
+	return r.FixSpool.Close()
+}
+
+// reportUpdates chunk 数据行数以及抽样校验统计信息写入 data_compare_meta，chunk 已完成对比清空复检重置前状态
+func reportUpdates(report *Report, updates map[string]interface{}) map[string]interface{} {
+	updates["ChunkRowsS"] = report.OracleRows
+	updates["ChunkRowsT"] = report.MySQLRows
+	updates["RecheckStatus"] = ""
+	updates["RecheckTime"] = nil
+	if report.Sample.Percent > 0 {
+		updates["SampleRows"] = report.SampleRows
+		updates["SampleDiff"] = report.SampleDiff
+		updates["SampleSummary"] = report.SampleSummary
+	}
+	return updates
+}
+
+// fixRow 对比数据行对应修复 SQL 数据行，未记录以对比数据行为准
+func (d DBSummary) fixRow(row string) string {
+	if fix, ok := d.FixRows[row]; ok {
+		return fix
+	}
+	return row
+}
+
+// queryFixRowStrings 对比字段与修复字段同一查询读取，对比字段值计算 CRC32 以及数据行集合，同时记录对比数据行对应修复数据行
+func queryFixRowStrings(ctx context.Context, db *sql.DB, querySQL string, format func(columnTypes []string, rawResult [][]byte) ([]string, error)) (DBSummary, error) {
+	summary := DBSummary{
+		StringSet: strset.New(),
+		FixRows:   make(map[string]string),
+	}
+	rows, err := db.QueryContext(ctx, querySQL)
+	if err != nil {
+		return summary, fmt.Errorf("general sql [%v] query failed: [%v]", querySQL, err.Error())
+	}
+	defer rows.Close()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return summary, fmt.Errorf("general sql [%v] query rows.Columns failed: [%v]", querySQL, err.Error())
+	}
+	width, err := compare.SplitFixColumns(cols)
+	if err != nil {
+		return summary, err
+	}
+	colTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return summary, err
+	}
+	var columnTypes []string
+	for _, ct := range colTypes {
+		columnTypes = append(columnTypes, ct.ScanType().String())
+	}
+	summary.Columns = cols[:width]
+
+	rawResult := make([][]byte, len(cols))
+	scans := make([]interface{}, len(cols))
+	for i := range rawResult {
+		scans[i] = &rawResult[i]
+	}
+	for rows.Next() {
+		if err = rows.Scan(scans...); err != nil {
+			return summary, fmt.Errorf("general sql [%v] query rows.Scan failed: [%v]", querySQL, err.Error())
+		}
+		values, err := format(columnTypes, rawResult)
+		if err != nil {
+			return summary, err
+		}
+		rowS := strings.Join(values[:width], ",")
+		summary.Crc32Val += crc32.ChecksumIEEE([]byte(rowS))
+		summary.StringSet.Add(rowS)
+		summary.FixRows[rowS] = strings.Join(values[width:], ",")
+	}
+	if err = rows.Err(); err != nil {
+		return summary, fmt.Errorf("general sql [%v] query rows.Next failed: [%v]", querySQL, err.Error())
+	}
+	return summary, nil
+}
+
 func (r *Report) String() string {
 	jsonStr, _ := json.Marshal(r)
 	return string(jsonStr)
@@ -0,0 +1,66 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package o2m
+
+import (
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/config"
+	"strconv"
+	"strings"
+)
+
+// 时间字段格式化长度 yyyy-MM-dd HH24:mi:ss
+const compareTimestampSecondLength = 19
+
+// genNumberColumn 数值字段查询格式化，numeric-epsilon 容差不在查询中处理，数据行对比按 ABS(a-b) <= epsilon 判断
+func genNumberColumn(colName string) (string, string) {
+	return common.StringsBuilder("DECODE(SUBSTR(", colName, ",1,1),'.','0' || ", colName, ",", colName, ")"),
+		common.StringsBuilder("CAST(0 + CAST(", colName, " AS CHAR) AS CHAR)")
+}
+
+// genCharacterColumn 字符字段查询格式化，trim 去除首尾空格，case-insensitive 统一大写
+func genCharacterColumn(sourceCol, targetCol string, rule config.ColumnRule) (string, string) {
+	if rule.Trim {
+		sourceCol = common.StringsBuilder("TRIM(", sourceCol, ")")
+		targetCol = common.StringsBuilder("TRIM(", targetCol, ")")
+	}
+	if rule.CaseInsensitive {
+		sourceCol = common.StringsBuilder("UPPER(", sourceCol, ")")
+		targetCol = common.StringsBuilder("UPPER(", targetCol, ")")
+	}
+	return common.StringsBuilder("NVL(", sourceCol, ",'')"), common.StringsBuilder("IFNULL(", targetCol, ",'')")
+}
+
+// genTimestampColumn 时间字段查询格式化，timestamp-precision 上下游截取相同小数秒位数，timestamp-utc 统一转换 UTC 时间
+func genTimestampColumn(colName, dataType string, rule config.ColumnRule) (string, string) {
+	sourceCol, targetCol := colName, colName
+	if rule.TimestampUTC && strings.Contains(dataType, "TIME ZONE") {
+		sourceCol = common.StringsBuilder("SYS_EXTRACT_UTC(", colName, ")")
+		targetCol = common.StringsBuilder("CONVERT_TZ(", colName, ",@@SESSION.TIME_ZONE,'+00:00')")
+	}
+
+	precision := rule.TimestampPrecision
+	if precision > 6 {
+		precision = 6
+	}
+	if precision <= 0 {
+		return common.StringsBuilder("TO_CHAR(", sourceCol, ",'yyyy-MM-dd HH24:mi:ss')"),
+			common.StringsBuilder("FROM_UNIXTIME(UNIX_TIMESTAMP(", targetCol, "),'%Y-%m-%d %H:%i:%s')")
+	}
+	length := strconv.Itoa(compareTimestampSecondLength + 1 + precision)
+	return common.StringsBuilder("SUBSTR(TO_CHAR(", sourceCol, ",'yyyy-MM-dd HH24:mi:ss.FF6'),1,", length, ")"),
+		common.StringsBuilder("LEFT(DATE_FORMAT(", targetCol, ",'%Y-%m-%d %H:%i:%s.%f'),", length, ")")
+}
@@ -0,0 +1,254 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package o2m
+
+import (
+	"fmt"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/database/mysql"
+	"github.com/wentaojin/transferdb/database/oracle"
+	"go.uber.org/zap"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// 抽样数据行下游键值探测单批次行数
+const sampleProbeBatchSize = 500
+
+// SampleOption 抽样校验参数
+type SampleOption struct {
+	Percent  float64 `json:"percent"`
+	Block    bool    `json:"block"`
+	Escalate bool    `json:"escalate"`
+}
+
+// sampleRow 抽样数据行格式化值、修复 SQL 字段值以及排序键原始值
+type sampleRow struct {
+	values    []string
+	fixValues []string
+	keyValues [][]byte
+}
+
+// OracleSampleTableName oracle 表 SAMPLE 子句位于 AS OF SCN 之前
+func (r *Report) OracleSampleTableName() string {
+	var sb strings.Builder
+	sb.WriteString(common.StringsBuilder(r.DataCompareMeta.SchemaNameS, ".", r.DataCompareMeta.TableNameS))
+	if r.Sample.Block {
+		sb.WriteString(" SAMPLE BLOCK (")
+	} else {
+		sb.WriteString(" SAMPLE (")
+	}
+	sb.WriteString(strconv.FormatFloat(r.Sample.Percent, 'f', -1, 64))
+	sb.WriteString(")")
+	if r.SnapshotSCN > 0 {
+		sb.WriteString(common.StringsBuilder(" AS OF SCN ", strconv.FormatUint(r.SnapshotSCN, 10)))
+	}
+	return sb.String()
+}
+
+// ReportCheckSample 上游抽样数据行按排序键有序读取，下游按批次键值探测对比
+// 上游存在，下游不存在 INSERT 下游
+// 上下游均存在字段值不一致，UPDATE 下游不一致字段
+func (r *Report) ReportCheckSample() (string, error) {
+	if len(r.CompareKeys) == 0 {
+		return "", fmt.Errorf("oracle schema [%s] table [%s] pk/uk column isn't exist, sample compare can't be probe mysql data rows", r.DataCompareMeta.SchemaNameS, r.DataCompareMeta.TableNameS)
+	}
+	if err := r.resetFixSQL(); err != nil {
+		return "", err
+	}
+
+	var oracleOrders, mysqlOrders []string
+	for _, k := range r.CompareKeys {
+		oracleOrders = append(oracleOrders, k.OracleOrderBy("T"))
+		mysqlOrders = append(mysqlOrders, k.MySQLOrderBy("T"))
+	}
+	oracleQuery := common.StringsBuilder(
+		"SELECT ", r.oracleSelectColumn(), " FROM ", r.OracleSampleTableName(), " T WHERE ", r.DataCompareMeta.WhereRange,
+		" ORDER BY ", strings.Join(oracleOrders, ","))
+
+	oraRows, err := r.Oracle.OracleDB.QueryContext(r.Oracle.Ctx, oracleQuery)
+	if err != nil {
+		return "", fmt.Errorf("oracle sample sql [%v] query failed: %v", oracleQuery, err)
+	}
+	defer oraRows.Close()
+
+	oraCursor, err := newRowCursor(oraRows, r.CompareKeys, r.oracleWithFix(), oracle.FormatOracleDataRowValues)
+	if err != nil {
+		return "", err
+	}
+
+	var (
+		missingRows, changedRows int64
+		batchRows                []sampleRow
+	)
+	targetTable := common.StringsBuilder(r.DataCompareMeta.SchemaNameT, ".", r.DataCompareMeta.TableNameT)
+	insertPrefix := common.StringsBuilder("INSERT INTO ", targetTable, " (", strings.Join(oraCursor.cols, ","), ") VALUES (")
+
+	// probe 下游按批次键值查询，与抽样批次数据行有序 merge 对比，下游仅返回批次键值范围内数据行
+	probe := func() error {
+		if len(batchRows) == 0 {
+			return nil
+		}
+		var whereCond []string
+		for _, row := range batchRows {
+			whereCond = append(whereCond, common.StringsBuilder("(", genKeyWhereCond(oraCursor.cols, oraCursor.keyIndex, row.fixValues), ")"))
+		}
+		mysqlQuery := common.StringsBuilder(
+			"SELECT ", r.DataCompareMeta.ColumnDetailT, " FROM ", targetTable, " T WHERE ", strings.Join(whereCond, " OR "),
+			" ORDER BY ", strings.Join(mysqlOrders, ","))
+
+		mysqlRows, err := r.Mysql.MySQLDB.QueryContext(r.Mysql.Ctx, mysqlQuery)
+		if err != nil {
+			return fmt.Errorf("mysql sample probe sql [%v] query failed: %v", mysqlQuery, err)
+		}
+		defer mysqlRows.Close()
+
+		mysqlCursor, err := newRowCursor(mysqlRows, r.CompareKeys, false, mysql.FormatMySQLDataRowValues)
+		if err != nil {
+			return err
+		}
+		if len(oraCursor.cols) != len(mysqlCursor.cols) {
+			return fmt.Errorf("oracle column counts [%d] and mysql column counts [%d] aren't equal", len(oraCursor.cols), len(mysqlCursor.cols))
+		}
+		if err = mysqlCursor.next(); err != nil {
+			return err
+		}
+
+		for _, row := range batchRows {
+			cmp := -1
+			for !mysqlCursor.eof {
+				cmp, err = compareKeyValues(r.CompareKeys, row.keyValues, mysqlCursor.keyValues)
+				if err != nil {
+					return err
+				}
+				if cmp <= 0 {
+					break
+				}
+				if err = mysqlCursor.next(); err != nil {
+					return err
+				}
+			}
+			if mysqlCursor.eof || cmp < 0 {
+				missingRows++
+				if err = r.writeFixSQL(common.StringsBuilder(insertPrefix, strings.Join(row.fixValues, ","), ")")); err != nil {
+					return err
+				}
+				continue
+			}
+			var setCond []string
+			for i, v := range row.values {
+				if !common.CompareEpsilonEqual(v, mysqlCursor.values[i], r.columnEpsilon(i)) {
+					setCond = append(setCond, common.StringsBuilder(oraCursor.cols[i], "=", row.fixValues[i]))
+				}
+			}
+			if len(setCond) > 0 {
+				changedRows++
+				if err = r.writeFixSQL(common.StringsBuilder("UPDATE ", targetTable, " SET ", strings.Join(setCond, ","),
+					" WHERE ", genKeyWhereCond(oraCursor.cols, oraCursor.keyIndex, row.fixValues))); err != nil {
+					return err
+				}
+			}
+		}
+		batchRows = batchRows[:0]
+		return nil
+	}
+
+	var sampleRows int64
+	for {
+		if err = oraCursor.next(); err != nil {
+			return "", err
+		}
+		if oraCursor.eof {
+			break
+		}
+		sampleRows++
+		batchRows = append(batchRows, sampleRow{values: oraCursor.values, fixValues: oraCursor.fixValues, keyValues: oraCursor.keyValues})
+		if len(batchRows) >= sampleProbeBatchSize {
+			if err = probe(); err != nil {
+				return "", err
+			}
+		}
+	}
+	if err = probe(); err != nil {
+		return "", err
+	}
+
+	r.SampleRows = sampleRows
+	r.SampleDiff = missingRows + changedRows
+	r.SampleSummary = genSampleSummary(r.Sample.Percent, r.SampleRows, r.SampleDiff)
+
+	if r.SampleDiff == 0 {
+		zap.L().Info("oracle table chunk sample diff equal",
+			zap.String("oracle schema", r.DataCompareMeta.SchemaNameS),
+			zap.String("mysql schema", r.DataCompareMeta.SchemaNameT),
+			zap.String("oracle table", r.DataCompareMeta.TableNameS),
+			zap.String("mysql table", r.DataCompareMeta.TableNameT),
+			zap.String("sample summary", r.SampleSummary),
+			zap.String("oracle sql", oracleQuery))
+		return "", nil
+	}
+
+	zap.L().Info("oracle table chunk sample diff isn't equal",
+		zap.String("oracle schema", r.DataCompareMeta.SchemaNameS),
+		zap.String("mysql schema", r.DataCompareMeta.SchemaNameT),
+		zap.String("oracle table", r.DataCompareMeta.TableNameS),
+		zap.String("mysql table", r.DataCompareMeta.TableNameT),
+		zap.Int64("missing rows", missingRows),
+		zap.Int64("changed rows", changedRows),
+		zap.String("sample summary", r.SampleSummary),
+		zap.String("oracle sql", oracleQuery))
+
+	sw := table.NewWriter()
+	sw.SetStyle(table.StyleLight)
+	sw.AppendHeader(table.Row{"SOURCE TABLE", "TARGET TABLE", "RANGE", "SAMPLE ROWS", "MISSING ROWS", "CHANGED ROWS", "SAMPLE SUMMARY"})
+	sw.AppendRows([]table.Row{
+		{
+			common.StringsBuilder(r.DataCompareMeta.SchemaNameS, ".", r.DataCompareMeta.TableNameS),
+			targetTable,
+			r.DataCompareMeta.WhereRange,
+			sampleRows,
+			missingRows,
+			changedRows,
+			r.SampleSummary,
+		},
+	})
+
+	return fmt.Sprintf("/*\n mysql table [%s] chunk [%s] sample data rows aren't equal\n", targetTable, r.DataCompareMeta.WhereRange) +
+		sw.Render() + "\n*/\n", nil
+}
+
+// genSampleSummary 抽样差异率 95% 置信区间上界估计
+// 无差异行以 rule of three 估计上界 3/n，存在差异行以正态近似估计上界
+func genSampleSummary(percent float64, sampleRows, diffRows int64) string {
+	if sampleRows == 0 {
+		return fmt.Sprintf("sample percent [%v], sample rows [0], diff rate can't be estimated", percent)
+	}
+	n := float64(sampleRows)
+	rate := float64(diffRows) / n
+	var upper float64
+	if diffRows == 0 {
+		upper = 3 / n
+	} else {
+		upper = rate + 1.96*math.Sqrt(rate*(1-rate)/n)
+	}
+	if upper > 1 {
+		upper = 1
+	}
+	return fmt.Sprintf("sample percent [%v], sample rows [%d], diff rows [%d], diff rate [%.6f], 95%% confidence diff rate upper bound [%.6f]",
+		percent, sampleRows, diffRows, rate, upper)
+}
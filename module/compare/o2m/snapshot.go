@@ -0,0 +1,126 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package o2m
+
+import (
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/database/meta"
+	"github.com/wentaojin/transferdb/database/mysql"
+	"go.uber.org/zap"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 快照一致性校验等待增量同步确认暂停应用次数，间隔 1s
+const snapshotFenceRetry = 600
+
+// genSnapshotPoint 获取快照一致性校验时间点，返回 oracle SCN、表级已应用 SCN 以及 TiDB TSO，仅支持下游 TiDB
+// 存在增量同步元数据时设置暂停应用标志，待增量同步于日志文件应用间隙确认暂停后，读取各表已应用 SCN 并获取 TiDB TSO，随后恢复应用
+// 表级已应用 SCN 与暂停期间 TiDB TSO 对应同一数据状态，oracle 按表 AS OF SCN 查询
+func (r *O2M) genSnapshotPoint() (uint64, map[string]uint64, uint64, error) {
+	if !strings.EqualFold(r.cfg.MySQLConfig.DBType, common.DatabaseTypeTiDB) {
+		return 0, nil, 0, fmt.Errorf("compare config snapshot-compare only support target db type [%s], current db type [%s]", common.DatabaseTypeTiDB, r.cfg.MySQLConfig.DBType)
+	}
+
+	incrMeta := &meta.IncrSyncMeta{
+		DBTypeS:     r.cfg.DBTypeS,
+		DBTypeT:     r.cfg.DBTypeT,
+		SchemaNameS: r.cfg.OracleConfig.SchemaName,
+	}
+	incrSyncMetas, err := meta.NewIncrSyncMetaModel(r.metaDB).DetailIncrSyncMetaBySchema(r.ctx, incrMeta)
+	if err != nil {
+		return 0, nil, 0, err
+	}
+
+	// 不存在增量同步，以当前时间点为准
+	if len(incrSyncMetas) == 0 {
+		scn, err := r.oracle.GetOracleCurrentSnapshotSCN()
+		if err != nil {
+			return 0, nil, 0, err
+		}
+		tso, err := r.mysql.GetTiDBCurrentTSO()
+		if err != nil {
+			return 0, nil, 0, err
+		}
+		return scn, nil, tso, nil
+	}
+
+	if err = meta.NewIncrSyncMetaModel(r.metaDB).UpdateIncrSyncMetaFenceBySchema(r.ctx, incrMeta, "", common.MigrateSyncFenceRequest); err != nil {
+		return 0, nil, 0, err
+	}
+	// 恢复增量同步应用
+	defer func() {
+		if err := meta.NewIncrSyncMetaModel(r.metaDB).UpdateIncrSyncMetaFenceBySchema(r.ctx, incrMeta, "", ""); err != nil {
+			zap.L().Error("clear incremental sync fence failed, incremental sync will clear fence after lease expired",
+				zap.String("schema", r.cfg.OracleConfig.SchemaName),
+				zap.String("lease", common.MigrateSyncFenceLease.String()),
+				zap.Error(err))
+		}
+	}()
+
+	for i := 0; i < snapshotFenceRetry; i++ {
+		incrSyncMetas, err = meta.NewIncrSyncMetaModel(r.metaDB).DetailIncrSyncMetaBySchema(r.ctx, incrMeta)
+		if err != nil {
+			return 0, nil, 0, err
+		}
+		paused := true
+		for _, m := range incrSyncMetas {
+			if !strings.EqualFold(m.SyncFence, common.MigrateSyncFencePaused) {
+				paused = false
+				break
+			}
+		}
+		if !paused {
+			if i%10 == 0 {
+				zap.L().Warn("wait incremental sync apply paused",
+					zap.String("schema", r.cfg.OracleConfig.SchemaName),
+					zap.Int("retry", i+1))
+			}
+			time.Sleep(time.Second)
+			continue
+		}
+
+		var globalSCN uint64
+		tableSCNs := make(map[string]uint64)
+		for _, m := range incrSyncMetas {
+			tableSCNs[common.StringUPPER(m.TableNameS)] = m.TableScnS
+			if globalSCN == 0 || m.TableScnS < globalSCN {
+				globalSCN = m.TableScnS
+			}
+		}
+		tso, err := r.mysql.GetTiDBCurrentTSO()
+		if err != nil {
+			return 0, nil, 0, err
+		}
+		return globalSCN, tableSCNs, tso, nil
+	}
+	return 0, nil, 0, fmt.Errorf("incremental sync apply isn't paused within [%d] seconds, can't get consistent snapshot point, please check incremental sync task is running", snapshotFenceRetry)
+}
+
+// newSnapshotMySQL 下游 TiDB 快照读连接，连接参数设置 tidb_snapshot 会话变量，连接池所有连接均为快照读
+func (r *O2M) newSnapshotMySQL(tso uint64) (*mysql.MySQL, error) {
+	mysqlCfg := r.cfg.MySQLConfig
+	snapshotParam := common.StringsBuilder("tidb_snapshot=", url.QueryEscape(common.StringsBuilder("'", strconv.FormatUint(tso, 10), "'")))
+	if strings.EqualFold(mysqlCfg.ConnectParams, "") {
+		mysqlCfg.ConnectParams = snapshotParam
+	} else {
+		mysqlCfg.ConnectParams = common.StringsBuilder(mysqlCfg.ConnectParams, "&", snapshotParam)
+	}
+	return mysql.NewMySQLDBEngine(r.ctx, mysqlCfg)
+}
@@ -36,11 +36,13 @@ type Task struct {
 	sourceTableName string
 	targetTableName string
 	oracleCollation bool
+	columnNameRule  map[string]string
 	mysql           *mysql.MySQL
 	oracle          *oracle.Oracle
 }
 
-func NewPartCompareTableTask(ctx context.Context, cfg *config.Config, compareTables []string, mysql *mysql.MySQL, oracle *oracle.Oracle, tableNameRule map[string]string) []*Task {
+func NewPartCompareTableTask(ctx context.Context, cfg *config.Config, compareTables []string, mysql *mysql.MySQL, oracle *oracle.Oracle, tableNameRule map[string]string,
+	columnNameRule map[string]map[string]string) []*Task {
 	var tasks []*Task
 	for _, table := range compareTables {
 		// 库名、表名规则
@@ -55,6 +57,7 @@ func NewPartCompareTableTask(ctx context.Context, cfg *config.Config, compareTab
 			cfg:             cfg,
 			sourceTableName: table,
 			targetTableName: targetTableName,
+			columnNameRule:  columnNameRule[common.StringUPPER(table)],
 			mysql:           mysql,
 			oracle:          oracle,
 		})
@@ -63,7 +66,7 @@ func NewPartCompareTableTask(ctx context.Context, cfg *config.Config, compareTab
 }
 
 func NewWaitCompareTableTask(ctx context.Context, cfg *config.Config, compareTables []string, oracleCollation bool, mysql *mysql.MySQL, oracle *oracle.Oracle,
-	tableNameRule map[string]string) []*Task {
+	tableNameRule map[string]string, columnNameRule map[string]map[string]string) []*Task {
 	var tasks []*Task
 	for _, table := range compareTables {
 		// 库名、表名规则
@@ -79,6 +82,7 @@ func NewWaitCompareTableTask(ctx context.Context, cfg *config.Config, compareTab
 			sourceTableName: table,
 			targetTableName: targetTableName,
 			oracleCollation: oracleCollation,
+			columnNameRule:  columnNameRule[common.StringUPPER(table)],
 			mysql:           mysql,
 			oracle:          oracle,
 		})
@@ -115,7 +119,7 @@ func PreTableStructCheck(ctx context.Context, cfg *config.Config, metaDB *meta.M
 		})
 
 		if errTotals != 0 || err != nil {
-			return fmt.Errorf("compare schema [%s] mode [%s] table structure task failed: %v, please check log, error: %v", strings.ToUpper(cfg.OracleConfig.SchemaName), cfg.TaskMode, err)
+			return fmt.Errorf("compare schema [%s] mode [%s] table structure task failed: %v, please check log, error: %v", strings.ToUpper(cfg.OracleConfig.SchemaName), cfg.TaskMode, errTotals, err)
 		}
 		endTime := time.Now()
 		zap.L().Info("pre check schema oracle to mysql finished",
@@ -127,62 +131,105 @@ func PreTableStructCheck(ctx context.Context, cfg *config.Config, metaDB *meta.M
 	return nil
 }
 
-// 字段查询以 ORACLE 字段为主
+// 字段查询以 ORACLE 字段为主，上下游查询字段统一以目标端字段名为别名
 // Date/Timestamp 字段类型格式化
 // Interval Year/Day 数据字符 TO_CHAR 格式化
+// 配置文件字段数据校验规则上下游统一处理
 func (t *Task) AdjustDBSelectColumn() (sourceColumnInfo string, targetColumnInfo string, err error) {
+	sourceColumnInfo, targetColumnInfo, _, err = t.genDBSelectColumn(true)
+	return sourceColumnInfo, targetColumnInfo, err
+}
+
+// AdjustDBFixColumn 修复 SQL 字段查询，字段以及顺序与 AdjustDBSelectColumn 一致，不应用 trim/case-insensitive/numeric-epsilon/timestamp 等字段校验规则
+// 字段校验规则仅用于判断数据是否相等，修复 SQL 字段值以原始字段值为准
+func (t *Task) AdjustDBFixColumn() (sourceColumnInfo string, targetColumnInfo string, err error) {
+	sourceColumnInfo, targetColumnInfo, _, err = t.genDBSelectColumn(false)
+	return sourceColumnInfo, targetColumnInfo, err
+}
+
+// FilterDBColumnEpsilon 查询字段 numeric-epsilon 容差，与 AdjustDBSelectColumn 查询字段顺序一致，非数值字段以及未配置容差字段为 0
+func (t *Task) FilterDBColumnEpsilon() ([]float64, error) {
+	_, _, columnEpsilons, err := t.genDBSelectColumn(true)
+	if err != nil {
+		return nil, err
+	}
+	return columnEpsilons, nil
+}
+
+func (t *Task) genDBSelectColumn(applyRule bool) (sourceColumnInfo string, targetColumnInfo string, columnEpsilons []float64, err error) {
 	var (
 		sourceColumnInfos, targetColumnInfos []string
 	)
 	columnInfo, err := t.oracle.GetOracleSchemaTableColumn(t.cfg.OracleConfig.SchemaName, t.sourceTableName, t.oracleCollation)
 	if err != nil {
-		return sourceColumnInfo, targetColumnInfo, err
+		return sourceColumnInfo, targetColumnInfo, columnEpsilons, err
 	}
 
 	for _, colsInfo := range columnInfo {
 		colName := colsInfo["COLUMN_NAME"]
+		rule := t.cfg.DiffConfig.GetColumnRule(t.sourceTableName, colName)
+		if rule.Ignore {
+			continue
+		}
+		if !applyRule {
+			rule = config.ColumnRule{}
+		}
+		colNameT := t.targetColumnName(colName)
+
+		var (
+			sourceCol, targetCol string
+			epsilon              float64
+		)
 		switch strings.ToUpper(colsInfo["DATA_TYPE"]) {
 		// 数字
-		case "NUMBER":
-			sourceColumnInfos = append(sourceColumnInfos, common.StringsBuilder("DECODE(SUBSTR(", colName, ",1,1),'.','0' || ", colName, ",", colName, ") AS ", colName))
-			targetColumnInfos = append(targetColumnInfos, common.StringsBuilder("CAST(0 + CAST(", colName, " AS CHAR) AS CHAR) AS ", colName))
-		case "DECIMAL", "DEC", "DOUBLE PRECISION", "FLOAT", "INTEGER", "INT", "REAL", "NUMERIC", "BINARY_FLOAT", "BINARY_DOUBLE", "SMALLINT":
-			sourceColumnInfos = append(sourceColumnInfos, common.StringsBuilder("DECODE(SUBSTR(", colName, ",1,1),'.','0' || ", colName, ",", colName, ") AS ", colName))
-			targetColumnInfos = append(targetColumnInfos, common.StringsBuilder("CAST(0 + CAST(", colName, " AS CHAR) AS CHAR) AS ", colName))
+		case "NUMBER", "DECIMAL", "DEC", "DOUBLE PRECISION", "FLOAT", "INTEGER", "INT", "REAL", "NUMERIC", "BINARY_FLOAT", "BINARY_DOUBLE", "SMALLINT":
+			sourceCol, _ = genNumberColumn(colName)
+			_, targetCol = genNumberColumn(colNameT)
+			epsilon = rule.NumericEpsilon
 		// 字符
 		case "BFILE", "CHARACTER", "LONG", "NCHAR VARYING", "ROWID", "UROWID", "VARCHAR", "CHAR", "NCHAR", "NVARCHAR2", "NCLOB", "CLOB":
-			sourceColumnInfos = append(sourceColumnInfos, common.StringsBuilder("NVL(", colName, ",'') AS ", colName))
-			targetColumnInfos = append(targetColumnInfos, common.StringsBuilder("IFNULL(", colName, ",'') AS ", colName))
+			sourceCol, targetCol = genCharacterColumn(colName, colNameT, rule)
 		case "XMLTYPE":
-			sourceColumnInfos = append(sourceColumnInfos, common.StringsBuilder("NVL(XMLSERIALIZE(CONTENT ", colName, " AS CLOB),'') AS ", colName))
-			targetColumnInfos = append(targetColumnInfos, common.StringsBuilder("IFNULL(", colName, ",'') AS ", colName))
+			sourceCol, targetCol = genCharacterColumn(common.StringsBuilder("XMLSERIALIZE(CONTENT ", colName, " AS CLOB)"), colNameT, rule)
 		// 二进制
 		case "BLOB", "LONG RAW", "RAW":
-			sourceColumnInfos = append(sourceColumnInfos, colName)
-			targetColumnInfos = append(targetColumnInfos, colName)
+			sourceCol, targetCol = colName, colNameT
 		// 时间
 		case "DATE":
-			sourceColumnInfos = append(sourceColumnInfos, common.StringsBuilder("TO_CHAR(", colName, ",'yyyy-MM-dd HH24:mi:ss') AS ", colName))
-			targetColumnInfos = append(targetColumnInfos, common.StringsBuilder("DATE_FORMAT(", colName, ",'%Y-%m-%d %H:%i:%s') AS ", colName))
+			sourceCol = common.StringsBuilder("TO_CHAR(", colName, ",'yyyy-MM-dd HH24:mi:ss')")
+			targetCol = common.StringsBuilder("DATE_FORMAT(", colNameT, ",'%Y-%m-%d %H:%i:%s')")
 		// 默认其他类型
 		default:
 			if strings.Contains(colsInfo["DATA_TYPE"], "INTERVAL") {
-				sourceColumnInfos = append(sourceColumnInfos, common.StringsBuilder("TO_CHAR(", colName, ") AS ", colName))
-				targetColumnInfos = append(targetColumnInfos, colName)
+				sourceCol, targetCol = common.StringsBuilder("TO_CHAR(", colName, ")"), colNameT
 			} else if strings.Contains(colsInfo["DATA_TYPE"], "TIMESTAMP") {
-				sourceColumnInfos = append(sourceColumnInfos, common.StringsBuilder("TO_CHAR(", colName, ",'yyyy-MM-dd HH24:mi:ss') AS ", colName))
-				targetColumnInfos = append(targetColumnInfos, common.StringsBuilder("FROM_UNIXTIME(UNIX_TIMESTAMP(", colName, "),'%Y-%m-%d %H:%i:%s') AS ", colName))
+				sourceCol, _ = genTimestampColumn(colName, strings.ToUpper(colsInfo["DATA_TYPE"]), rule)
+				_, targetCol = genTimestampColumn(colNameT, strings.ToUpper(colsInfo["DATA_TYPE"]), rule)
 			} else {
-				sourceColumnInfos = append(sourceColumnInfos, colName)
-				targetColumnInfos = append(targetColumnInfos, colName)
+				sourceCol, targetCol = colName, colNameT
 			}
 		}
+		if strings.EqualFold(sourceCol, colNameT) {
+			sourceColumnInfos = append(sourceColumnInfos, colNameT)
+		} else {
+			sourceColumnInfos = append(sourceColumnInfos, common.StringsBuilder(sourceCol, " AS ", colNameT))
+		}
+		if strings.EqualFold(targetCol, colNameT) {
+			targetColumnInfos = append(targetColumnInfos, colNameT)
+		} else {
+			targetColumnInfos = append(targetColumnInfos, common.StringsBuilder(targetCol, " AS ", colNameT))
+		}
+		columnEpsilons = append(columnEpsilons, epsilon)
+	}
+
+	if len(sourceColumnInfos) == 0 {
+		return sourceColumnInfo, targetColumnInfo, columnEpsilons, fmt.Errorf("oracle schema [%s] table [%s] compare columns are all ignored, please check config column-rules", t.cfg.OracleConfig.SchemaName, t.sourceTableName)
 	}
 
 	sourceColumnInfo = strings.Join(sourceColumnInfos, ",")
 	targetColumnInfo = strings.Join(targetColumnInfos, ",")
 
-	return sourceColumnInfo, targetColumnInfo, nil
+	return sourceColumnInfo, targetColumnInfo, columnEpsilons, nil
 }
 
 // 筛选 NUMBER 字段以及判断表是否存在主键/唯一键/唯一索引
@@ -341,6 +388,69 @@ func (t *Task) FilterDBWhereColumn() (string, error) {
 	return "", fmt.Errorf("oracle schema [%s] table [%s] pk/uk/index number datatype column isn't exist, please skip or fixed", t.cfg.OracleConfig.SchemaName, t.sourceTableName)
 }
 
+// FilterDBCompareKey 筛选数据差异 merge 对比排序键
+// 优先主键，其次唯一约束、唯一索引，排序键字段非表字段（例如函数索引）或者不存在返回空，差异对比回退整行集合对比
+func (t *Task) FilterDBCompareKey() ([]CompareKey, error) {
+	columnInfo, err := t.oracle.GetOracleSchemaTableColumn(t.cfg.OracleConfig.SchemaName, t.sourceTableName, t.oracleCollation)
+	if err != nil {
+		return nil, err
+	}
+	columnTypes := make(map[string]string)
+	for _, colsInfo := range columnInfo {
+		columnTypes[strings.ToUpper(colsInfo["COLUMN_NAME"])] = colsInfo["DATA_TYPE"]
+	}
+
+	var columnList string
+	pkInfo, err := t.oracle.GetOracleSchemaTablePrimaryKey(t.cfg.OracleConfig.SchemaName, t.sourceTableName)
+	if err != nil {
+		return nil, err
+	}
+	if len(pkInfo) > 0 {
+		columnList = pkInfo[0]["COLUMN_LIST"]
+	}
+	if columnList == "" {
+		ukInfo, err := t.oracle.GetOracleSchemaTableUniqueKey(t.cfg.OracleConfig.SchemaName, t.sourceTableName)
+		if err != nil {
+			return nil, err
+		}
+		if len(ukInfo) > 0 {
+			columnList = ukInfo[0]["COLUMN_LIST"]
+		}
+	}
+	if columnList == "" {
+		indexInfo, err := t.oracle.GetOracleSchemaTableUniqueIndex(t.cfg.OracleConfig.SchemaName, t.sourceTableName)
+		if err != nil {
+			return nil, err
+		}
+		for _, idx := range indexInfo {
+			if strings.EqualFold(idx["INDEX_TYPE"], "NORMAL") {
+				columnList = idx["COLUMN_LIST"]
+				break
+			}
+		}
+	}
+	if columnList == "" {
+		return nil, nil
+	}
+
+	var keys []CompareKey
+	for _, col := range strings.Split(columnList, ",") {
+		dataType, ok := columnTypes[strings.ToUpper(col)]
+		if !ok {
+			return nil, nil
+		}
+		key := NewCompareKey(strings.ToUpper(col), dataType)
+		key.ColumnNameT = t.targetColumnName(col)
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// targetColumnName 目标端字段名，字段名映射规则不存在与源端一致
+func (t *Task) targetColumnName(columnName string) string {
+	return common.StringColumnNameRule(columnName, t.columnNameRule)
+}
+
 func (t *Task) IsPartitionTable() (string, error) {
 	isOK, err := t.oracle.IsOraclePartitionTable(t.cfg.OracleConfig.SchemaName, t.sourceTableName)
 	if err != nil {
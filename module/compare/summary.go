@@ -0,0 +1,294 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package compare
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/database/meta"
+	"go.uber.org/zap"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+)
+
+//go:embed template
+var fs embed.FS
+
+// CompareSummary 数据校验汇总报告，按表汇总 data_compare_meta chunk 记录
+type CompareSummary struct {
+	SchemaNameS    string                 `json:"schema_name_s"`
+	SchemaNameT    string                 `json:"schema_name_t"`
+	TaskMode       string                 `json:"task_mode"`
+	StartTime      string                 `json:"start_time"`
+	FinishTime     string                 `json:"finish_time"`
+	Cost           string                 `json:"cost"`
+	FixSQLFile     string                 `json:"fix_sql_file"`
+	TableTotals    int                    `json:"table_totals"`
+	TableEqual     int                    `json:"table_equal"`
+	TableNotEqual  int                    `json:"table_not_equal"`
+	ChunkTotals    int                    `json:"chunk_totals"`
+	ChunkEqual     int                    `json:"chunk_equal"`
+	ChunkDifferent int                    `json:"chunk_different"`
+	ChunkFailed    int                    `json:"chunk_failed"`
+	ChunkFixed     int                    `json:"chunk_fixed"`
+	Tables         []*CompareTableSummary `json:"tables"`
+}
+
+// CompareTableSummary 单表数据校验汇总
+// 数据行数为各 chunk 对比时上下游实际读取行数之和，抽样校验以及 checksum 计算失败回退前的 chunk 不计入
+type CompareTableSummary struct {
+	SchemaNameS    string `json:"schema_name_s"`
+	TableNameS     string `json:"table_name_s"`
+	SchemaNameT    string `json:"schema_name_t"`
+	TableNameT     string `json:"table_name_t"`
+	TableStatus    string `json:"table_status"`
+	ChunkTotals    int    `json:"chunk_totals"`
+	ChunkEqual     int    `json:"chunk_equal"`
+	ChunkDifferent int    `json:"chunk_different"`
+	ChunkFailed    int    `json:"chunk_failed"`
+	ChunkFixed     int    `json:"chunk_fixed"`
+	RowsS          int64  `json:"rows_s"`
+	RowsT          int64  `json:"rows_t"`
+	RowsDelta      int64  `json:"rows_delta"`
+	SampleRows     int64  `json:"sample_rows"`
+	SampleDiff     int64  `json:"sample_diff"`
+	Cost           string `json:"cost"`
+	FixSQLFile     string `json:"fix_sql_file"`
+}
+
+// GenCompareSummary 汇总 data_compare_summary 以及 data_compare_meta 记录生成数据校验报告
+// 表数据校验完成 chunk 记录清理前已汇总写入 data_compare_summary，存在汇总记录以汇总记录为准，否则汇总 data_compare_meta chunk 记录
+// chunk 耗时以 chunk 记录最早创建时间至最晚更新时间计算，断点续检跨多次运行
+func GenCompareSummary(ctx context.Context, metaDB *meta.Meta, dbTypeS, dbTypeT, schemaNameS, schemaNameT, taskMode string, startTime time.Time, fixSQLFile string) (*CompareSummary, error) {
+	compareMetas, err := meta.NewDataCompareMetaModel(metaDB).DetailDataCompareMeta(ctx, &meta.DataCompareMeta{
+		DBTypeS:     dbTypeS,
+		DBTypeT:     dbTypeT,
+		SchemaNameS: common.StringUPPER(schemaNameS),
+		TaskMode:    taskMode,
+	})
+	if err != nil {
+		return nil, err
+	}
+	compareSummaries, err := meta.NewDataCompareSummaryModel(metaDB).DetailDataCompareSummary(ctx, &meta.DataCompareSummary{
+		DBTypeS:     dbTypeS,
+		DBTypeT:     dbTypeT,
+		SchemaNameS: common.StringUPPER(schemaNameS),
+		TaskMode:    taskMode,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	finishTime := time.Now()
+	summary := &CompareSummary{
+		SchemaNameS: common.StringUPPER(schemaNameS),
+		SchemaNameT: common.StringUPPER(schemaNameT),
+		TaskMode:    taskMode,
+		StartTime:   startTime.Format("2006-01-02 15:04:05"),
+		FinishTime:  finishTime.Format("2006-01-02 15:04:05"),
+		Cost:        finishTime.Sub(startTime).String(),
+		FixSQLFile:  filepath.Base(fixSQLFile),
+	}
+
+	tableSummary := aggregateCompareMeta(compareMetas)
+	for i := range compareSummaries {
+		tableSummary[compareSummaries[i].TableNameS] = &compareSummaries[i]
+	}
+
+	var tables []string
+	for t := range tableSummary {
+		tables = append(tables, t)
+	}
+	sort.Strings(tables)
+
+	for _, t := range tables {
+		cs := tableSummary[t]
+		ts := &CompareTableSummary{
+			SchemaNameS:    cs.SchemaNameS,
+			TableNameS:     cs.TableNameS,
+			SchemaNameT:    cs.SchemaNameT,
+			TableNameT:     cs.TableNameT,
+			ChunkTotals:    int(cs.ChunkTotals),
+			ChunkEqual:     int(cs.ChunkEqual),
+			ChunkDifferent: int(cs.ChunkDifferent),
+			ChunkFailed:    int(cs.ChunkFailed),
+			ChunkFixed:     int(cs.ChunkFixed),
+			RowsS:          cs.RowsS,
+			RowsT:          cs.RowsT,
+			RowsDelta:      cs.RowsS - cs.RowsT,
+			SampleRows:     cs.SampleRows,
+			SampleDiff:     cs.SampleDiff,
+			Cost:           cs.CompareEnd.Sub(cs.CompareBegin).String(),
+		}
+		if ts.ChunkEqual+ts.ChunkFixed == ts.ChunkTotals {
+			ts.TableStatus = common.TaskStatusSuccess
+			summary.TableEqual++
+		} else {
+			ts.TableStatus = common.TaskStatusFailed
+			summary.TableNotEqual++
+		}
+		if ts.ChunkDifferent > 0 || ts.ChunkFixed > 0 {
+			ts.FixSQLFile = summary.FixSQLFile
+		}
+
+		summary.ChunkTotals += ts.ChunkTotals
+		summary.ChunkEqual += ts.ChunkEqual
+		summary.ChunkDifferent += ts.ChunkDifferent
+		summary.ChunkFailed += ts.ChunkFailed
+		summary.ChunkFixed += ts.ChunkFixed
+		summary.Tables = append(summary.Tables, ts)
+	}
+	summary.TableTotals = len(summary.Tables)
+
+	return summary, nil
+}
+
+// aggregateCompareMeta data_compare_meta chunk 记录按表汇总，用于数据校验报告以及表数据校验完成清理 chunk 记录前写入 data_compare_summary
+func aggregateCompareMeta(compareMetas []meta.DataCompareMeta) map[string]*meta.DataCompareSummary {
+	tableSummary := make(map[string]*meta.DataCompareSummary)
+	for _, cm := range compareMetas {
+		ts, ok := tableSummary[cm.TableNameS]
+		if !ok {
+			ts = &meta.DataCompareSummary{
+				DBTypeS:     cm.DBTypeS,
+				DBTypeT:     cm.DBTypeT,
+				SchemaNameS: cm.SchemaNameS,
+				TableNameS:  cm.TableNameS,
+				SchemaNameT: cm.SchemaNameT,
+				TableNameT:  cm.TableNameT,
+				TaskMode:    cm.TaskMode,
+			}
+			tableSummary[cm.TableNameS] = ts
+		}
+		ts.ChunkTotals++
+		ts.RowsS += cm.ChunkRowsS
+		ts.RowsT += cm.ChunkRowsT
+		ts.SampleRows += cm.SampleRows
+		ts.SampleDiff += cm.SampleDiff
+
+		switch {
+		case strings.EqualFold(cm.TaskStatus, common.TaskStatusSuccess) && strings.EqualFold(cm.RepairStatus, common.CompareRepairStatusFixed):
+			ts.ChunkFixed++
+		case strings.EqualFold(cm.TaskStatus, common.TaskStatusSuccess):
+			ts.ChunkEqual++
+		case strings.EqualFold(cm.TaskStatus, common.TaskStatusFailed) &&
+			(strings.EqualFold(cm.ErrorDetail, common.CompareChunkDiffError) || !strings.EqualFold(cm.RepairStatus, "")):
+			ts.ChunkDifferent++
+		case strings.EqualFold(cm.TaskStatus, common.TaskStatusFailed):
+			ts.ChunkFailed++
+		}
+
+		if cm.BaseModel != nil {
+			if ts.CompareBegin.IsZero() || cm.CreatedAt.Before(ts.CompareBegin) {
+				ts.CompareBegin = cm.CreatedAt
+			}
+			if ts.CompareEnd.IsZero() || cm.UpdatedAt.After(ts.CompareEnd) {
+				ts.CompareEnd = cm.UpdatedAt
+			}
+		}
+	}
+	return tableSummary
+}
+
+// GenCompareTableSummary 表数据校验完成清理 chunk 记录前汇总 data_compare_meta 记录
+func GenCompareTableSummary(ctx context.Context, metaDB *meta.Meta, dbTypeS, dbTypeT, schemaNameS, tableNameS, taskMode string) (*meta.DataCompareSummary, error) {
+	compareMetas, err := meta.NewDataCompareMetaModel(metaDB).DetailDataCompareMeta(ctx, &meta.DataCompareMeta{
+		DBTypeS:     dbTypeS,
+		DBTypeT:     dbTypeT,
+		SchemaNameS: common.StringUPPER(schemaNameS),
+		TableNameS:  tableNameS,
+		TaskMode:    taskMode,
+	})
+	if err != nil {
+		return nil, err
+	}
+	ts, ok := aggregateCompareMeta(compareMetas)[tableNameS]
+	if !ok {
+		return nil, fmt.Errorf("%s schema [%s] table [%s] data_compare_meta record isn't exist, compare summary can't be generated", strings.ToLower(dbTypeS), schemaNameS, tableNameS)
+	}
+	// chunk 记录未包含时间信息，以当前时间为准
+	if ts.CompareBegin.IsZero() {
+		ts.CompareBegin = time.Now()
+	}
+	if ts.CompareEnd.IsZero() {
+		ts.CompareEnd = ts.CompareBegin
+	}
+	return ts, nil
+}
+
+// WriteCompareSummary 数据校验报告输出 JSON 以及 HTML 文件，与差异修复 SQL 文件同目录
+func WriteCompareSummary(summary *CompareSummary, outputDir string) error {
+	reportPrefix := filepath.Join(outputDir, common.StringsBuilder(common.CompareSummaryReportPrefix, summary.SchemaNameS))
+
+	jsonBytes, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return fmt.Errorf("compare summary report json marshal failed: %v", err)
+	}
+	if err = os.WriteFile(reportPrefix+".json", jsonBytes, 0666); err != nil {
+		return fmt.Errorf("compare summary report json file write failed: %v", err)
+	}
+
+	htmlFile, err := os.OpenFile(reportPrefix+".html", os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0666)
+	if err != nil {
+		return err
+	}
+	defer htmlFile.Close()
+
+	if err = GenCompareHTMLReport(summary, htmlFile); err != nil {
+		return err
+	}
+
+	zap.L().Info("compare summary report output",
+		zap.String("json", reportPrefix+".json"),
+		zap.String("html", reportPrefix+".html"))
+	return nil
+}
+
+func GenCompareHTMLReport(summary *CompareSummary, file *os.File) error {
+	tf, err := template.ParseFS(fs, "template/*.html")
+	if err != nil {
+		return fmt.Errorf("template parse FS failed: %v", err)
+	}
+
+	if err = tf.ExecuteTemplate(file, "report_header", nil); err != nil {
+		return fmt.Errorf("template FS Execute [report_header] template HTML failed: %v", err)
+	}
+
+	if err = tf.ExecuteTemplate(file, "report_body", nil); err != nil {
+		return fmt.Errorf("template FS Execute [report_body] template HTML failed: %v", err)
+	}
+
+	if err = tf.ExecuteTemplate(file, "report_summary", summary); err != nil {
+		return fmt.Errorf("template FS Execute [report_summary] template HTML failed: %v", err)
+	}
+
+	if err = tf.ExecuteTemplate(file, "report_detail", summary.Tables); err != nil {
+		return fmt.Errorf("template FS Execute [report_detail] template HTML failed: %v", err)
+	}
+
+	if err = tf.ExecuteTemplate(file, "report_footer", nil); err != nil {
+		return fmt.Errorf("template FS Execute [report_footer] template HTML failed: %v", err)
+	}
+
+	return nil
+}
@@ -18,3 +18,7 @@ package csv
 type CSVer interface {
 	CSV() error
 }
+
+type CSVImporter interface {
+	Import() error
+}
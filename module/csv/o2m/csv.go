@@ -26,7 +26,6 @@ import (
 	"github.com/wentaojin/transferdb/database/oracle"
 	"go.uber.org/zap"
 	"golang.org/x/sync/errgroup"
-	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
@@ -87,10 +86,22 @@ func (r *O2M) CSV() error {
 		return err
 	}
 
+	// TiDB Lightning 目录布局，输出库表结构文件
+	if strings.EqualFold(r.Cfg.CSVConfig.ExportLayout, common.CSVExportLayoutLightning) {
+		err = r.genLightningSchemaFile(exporters, oraDBVersion, oracleCollation)
+		if err != nil {
+			return err
+		}
+	}
+
 	// 关于全量断点恢复
 	//  - 若想断点恢复，设置 enable-checkpoint true,首次一旦运行则 batch 数不能调整，
 	//  - 若不想断点恢复或者重新调整 batch 数，设置 enable-checkpoint false,清理元数据表 [wait_sync_meta],重新运行全量任务
 	if !r.Cfg.CSVConfig.EnableCheckpoint {
+		err = RemoveManifest(r.Cfg.CSVConfig.OutputDir)
+		if err != nil {
+			return err
+		}
 		err = meta.NewFullSyncMetaModel(r.MetaDB).DeleteFullSyncMetaBySchemaSyncMode(r.Ctx, &meta.FullSyncMeta{
 			DBTypeS:     r.Cfg.DBTypeS,
 			DBTypeT:     r.Cfg.DBTypeT,
@@ -345,11 +356,32 @@ func (r *O2M) CSV() error {
 		return err
 	}
 
+	if strings.EqualFold(r.Cfg.CSVConfig.ExportLayout, common.CSVExportLayoutLightning) {
+		err = r.genLightningMetadataFile(startTime, exporters)
+		if err != nil {
+			return err
+		}
+	}
+
+	// 字符集转换替换/丢弃字符数按表记录于 wait_sync_meta，用于审计有损转换
+	var (
+		replacedTables []string
+		replacedChars  int64
+	)
+	for _, m := range append(succTotals, failedTotals...) {
+		if m.ReplacedChars > 0 {
+			replacedTables = append(replacedTables, m.TableNameS)
+			replacedChars += m.ReplacedChars
+		}
+	}
+
 	zap.L().Info("source schema all table data csv finished",
 		zap.String("schema", r.Cfg.OracleConfig.SchemaName),
 		zap.Int("table totals", len(exporters)),
 		zap.Int("table success", len(succTotals)),
 		zap.Int("table failed", len(failedTotals)),
+		zap.Strings("charset replaced tables", replacedTables),
+		zap.Int64("charset replaced chars", replacedChars),
 		zap.String("output", r.Cfg.CSVConfig.OutputDir),
 		zap.String("log detail", "if exist table failed, please see meta table [wait/full_sync_meta]"),
 		zap.String("cost", time.Now().Sub(startTime).String()))
@@ -412,6 +444,39 @@ func (r *O2M) csvPartSyncTable(csvPartTables []string) error {
 
 			waitFullMetas = append(waitFullMetas, failedFullMetas...)
 
+			// 字段名映射规则，导出文件表头以及清单记录目标端字段名
+			columnNameRule, err := meta.NewColumnNameRuleModel(r.MetaDB).DetailTableColumnNameRule(r.Ctx, &meta.ColumnNameRule{
+				DBTypeS:     r.Cfg.DBTypeS,
+				DBTypeT:     r.Cfg.DBTypeT,
+				SchemaNameS: r.Cfg.OracleConfig.SchemaName,
+				TableNameS:  t,
+			})
+			if err != nil {
+				return err
+			}
+
+			// parquet 格式需根据字段元数据生成 schema，与字段 collation 无关
+			var columnsINFO []map[string]string
+			if strings.EqualFold(r.Cfg.CSVConfig.OutputFormat, common.CSVOutputFormatParquet) {
+				columnsINFO, err = r.Oracle.GetOracleSchemaTableColumn(r.Cfg.OracleConfig.SchemaName, t, false)
+				if err != nil {
+					return err
+				}
+				// parquet schema 字段名为映射后字段名
+				for _, c := range columnsINFO {
+					c["COLUMN_NAME"] = common.StringColumnNameRule(c["COLUMN_NAME"], columnNameRule)
+				}
+			}
+
+			// 数据文件输出字符集，记录于导出清单用于 csv-import
+			var csvCharset string
+			if !strings.EqualFold(r.Cfg.CSVConfig.OutputFormat, common.CSVOutputFormatParquet) {
+				csvCharset, err = CSVOutputCharset(r.Cfg.CSVConfig.Charset, oracleDBCharacterSet)
+				if err != nil {
+					return err
+				}
+			}
+
 			g1 := &errgroup.Group{}
 			g1.SetLimit(r.Cfg.CSVConfig.SQLThreads)
 
@@ -475,11 +540,27 @@ func (r *O2M) csvPartSyncTable(csvPartTables []string) error {
 						return nil
 					}
 
+					// 查询字段按字段名映射规则转换目标端字段名，用于文件表头、parquet schema 以及导出清单
+					var targetColumns []string
+					for _, c := range columnFields {
+						targetColumns = append(targetColumns, common.StringColumnNameRule(c, columnNameRule))
+					}
+
 					// 数据输出
-					errW := NewWriter(m.SchemaNameS,
-						m.TableNameS,
-						oracleDBCharacterSet, querySQL, m.CSVFile, columnFields,
-						r.Cfg.CSVConfig, rowsResult).WriteFile()
+					rowCounts, replacedChars, errW := r.writeChunkFile(m, oracleDBCharacterSet, querySQL, targetColumns, columnsINFO, rowsResult)
+					if errW == nil {
+						// 记录导出清单，用于 csv-import 导入字段列表、字符集以及行数校验
+						errW = AppendManifest(r.Cfg.CSVConfig.OutputDir, &Manifest{
+							SchemaNameS: m.SchemaNameS,
+							TableNameS:  m.TableNameS,
+							SchemaNameT: m.SchemaNameT,
+							TableNameT:  m.TableNameT,
+							CSVFile:     m.CSVFile,
+							RowCounts:   rowCounts,
+							Columns:     targetColumns,
+							Charset:     csvCharset,
+						})
+					}
 					if errW != nil {
 						if errf := meta.NewFullSyncMetaModel(r.MetaDB).UpdateFullSyncMetaChunk(r.Ctx, &meta.FullSyncMeta{
 							DBTypeS:      m.DBTypeS,
@@ -504,7 +585,8 @@ func (r *O2M) csvPartSyncTable(csvPartTables []string) error {
 							TaskMode:     m.TaskMode,
 							ChunkDetailS: m.ChunkDetailS,
 						}, map[string]interface{}{
-							"TaskStatus": common.TaskStatusSuccess,
+							"TaskStatus":    common.TaskStatusSuccess,
+							"ReplacedChars": replacedChars,
 						}); errf != nil {
 							return errf
 						}
@@ -543,6 +625,12 @@ func (r *O2M) csvPartSyncTable(csvPartTables []string) error {
 				return err
 			}
 
+			// 表级字符集转换替换/丢弃字符数，以成功 chunk 记录累加，包含断点续传之前已完成 chunk
+			var tableReplacedChars int64
+			for _, sm := range successChunkFullMeta {
+				tableReplacedChars += sm.ReplacedChars
+			}
+
 			// 不存在错误，清理 full_sync_meta 记录, 更新 wait_sync_meta 记录
 			if failedChunkTotalErrs == 0 {
 				err = meta.NewCommonModel(r.MetaDB).DeleteTableFullSyncMetaAndUpdateWaitSyncMeta(r.Ctx,
@@ -561,6 +649,7 @@ func (r *O2M) csvPartSyncTable(csvPartTables []string) error {
 						TaskStatus:       common.TaskStatusSuccess,
 						ChunkSuccessNums: int64(len(successChunkFullMeta)),
 						ChunkFailedNums:  0,
+						ReplacedChars:    tableReplacedChars,
 					})
 				if err != nil {
 					return err
@@ -568,6 +657,7 @@ func (r *O2M) csvPartSyncTable(csvPartTables []string) error {
 				zap.L().Info("csv single table oracle to mysql finished",
 					zap.String("schema", r.Cfg.OracleConfig.SchemaName),
 					zap.String("table", common.StringUPPER(t)),
+					zap.Int64("charset replaced chars", tableReplacedChars),
 					zap.String("cost", time.Now().Sub(taskTime).String()))
 			} else {
 				// 若存在错误，修改表状态，skip 清理，统一忽略，最后显示
@@ -581,6 +671,7 @@ func (r *O2M) csvPartSyncTable(csvPartTables []string) error {
 					"TaskStatus":       common.TaskStatusFailed,
 					"ChunkSuccessNums": int64(len(successChunkFullMeta)),
 					"ChunkFailedNums":  failedChunkTotalErrs,
+					"ReplacedChars":    tableReplacedChars,
 				})
 				if err != nil {
 					return err
@@ -590,6 +681,7 @@ func (r *O2M) csvPartSyncTable(csvPartTables []string) error {
 					zap.String("table", common.StringUPPER(t)),
 					zap.String("mode", r.Cfg.TaskMode),
 					zap.String("updated", "csv table exist error, skip"),
+					zap.Int64("charset replaced chars", tableReplacedChars),
 					zap.String("cost", time.Now().Sub(startTime).String()))
 			}
 			return nil
@@ -652,7 +744,7 @@ func (r *O2M) initWaitSyncTableRowID(csvWaitTables []string, tableNameRule map[s
 				return fmt.Errorf("csv config paramter output-dir can't be null, please configure")
 			}
 
-			sourceColumnInfo, err := r.adjustTableSelectColumn(t, oracleCollation)
+			sourceColumnInfo, err := r.adjustTableSelectColumn(t, targetTableName, oracleCollation)
 			if err != nil {
 				return err
 			}
@@ -692,10 +784,7 @@ func (r *O2M) initWaitSyncTableRowID(csvWaitTables []string, tableNameRule map[s
 					TaskMode:      r.Cfg.TaskMode,
 					TaskStatus:    common.TaskStatusWaiting,
 					IsPartition:   isPartition,
-					CSVFile: filepath.Join(r.Cfg.CSVConfig.OutputDir,
-						common.StringUPPER(r.Cfg.OracleConfig.SchemaName), common.StringUPPER(t),
-						common.StringsBuilder(common.StringUPPER(r.Cfg.MySQLConfig.SchemaName),
-							`.`, common.StringUPPER(targetTableName), `.0.csv`)),
+					CSVFile:       r.genCSVFileName(t, targetTableName, 0),
 				}, &meta.WaitSyncMeta{
 					DBTypeS:          r.Cfg.DBTypeS,
 					DBTypeT:          r.Cfg.DBTypeT,
@@ -756,10 +845,7 @@ func (r *O2M) initWaitSyncTableRowID(csvWaitTables []string, tableNameRule map[s
 					TaskMode:      r.Cfg.TaskMode,
 					TaskStatus:    common.TaskStatusWaiting,
 					IsPartition:   isPartition,
-					CSVFile: filepath.Join(r.Cfg.CSVConfig.OutputDir,
-						common.StringUPPER(r.Cfg.OracleConfig.SchemaName), common.StringUPPER(t),
-						common.StringsBuilder(common.StringUPPER(r.Cfg.MySQLConfig.SchemaName),
-							`.`, common.StringUPPER(targetTableName), `.0.csv`)),
+					CSVFile:       r.genCSVFileName(t, targetTableName, 0),
 				}, &meta.WaitSyncMeta{
 					DBTypeS:          r.Cfg.DBTypeS,
 					DBTypeT:          r.Cfg.DBTypeT,
@@ -781,11 +867,7 @@ func (r *O2M) initWaitSyncTableRowID(csvWaitTables []string, tableNameRule map[s
 
 			var fullMetas []meta.FullSyncMeta
 			for i, res := range chunkRes {
-				var csvFile string
-				csvFile = filepath.Join(r.Cfg.CSVConfig.OutputDir,
-					common.StringUPPER(r.Cfg.OracleConfig.SchemaName), common.StringUPPER(t),
-					common.StringsBuilder(common.StringUPPER(r.Cfg.MySQLConfig.SchemaName), `.`,
-						common.StringUPPER(targetTableName), `.`, strconv.Itoa(i), `.csv`))
+				csvFile := r.genCSVFileName(t, targetTableName, i)
 
 				fullMetas = append(fullMetas, meta.FullSyncMeta{
 					DBTypeS:       r.Cfg.DBTypeS,
@@ -845,7 +927,30 @@ func (r *O2M) initWaitSyncTableRowID(csvWaitTables []string, tableNameRule map[s
 	return nil
 }
 
-func (r *O2M) adjustTableSelectColumn(sourceTable string, oracleCollation bool) (string, error) {
+// writeChunkFile 按输出格式写入 chunk 数据文件，返回写入行数以及字符集转换替换/丢弃字符数
+func (r *O2M) writeChunkFile(m meta.FullSyncMeta, sourceCharset, querySQL string, columnFields []string, columnsINFO []map[string]string, rows *sql.Rows) (int64, int64, error) {
+	if strings.EqualFold(r.Cfg.CSVConfig.OutputFormat, common.CSVOutputFormatParquet) {
+		parquetColumns, err := GenParquetColumns(columnsINFO, columnFields)
+		if err != nil {
+			if errClose := rows.Close(); errClose != nil {
+				return 0, 0, errClose
+			}
+			return 0, 0, err
+		}
+		p := NewParquetWriter(m.SchemaNameS, m.TableNameS, querySQL, m.CSVFile, parquetColumns, rows)
+		if err = p.WriteFile(); err != nil {
+			return p.RowCounts, 0, err
+		}
+		return p.RowCounts, 0, nil
+	}
+	f := NewWriter(m.SchemaNameS, m.TableNameS, sourceCharset, querySQL, m.CSVFile, columnFields, r.Cfg.CSVConfig, rows)
+	if err := f.WriteFile(); err != nil {
+		return f.RowCounts, f.ReplacedChars, err
+	}
+	return f.RowCounts, f.ReplacedChars, nil
+}
+
+func (r *O2M) adjustTableSelectColumn(sourceTable, targetTable string, oracleCollation bool) (string, error) {
 	// Date/Timestamp 字段类型格式化
 	// Interval Year/Day 数据字符 TO_CHAR 格式化
 	columnsINFO, err := r.Oracle.GetOracleSchemaTableColumn(r.Cfg.OracleConfig.SchemaName, sourceTable, oracleCollation)
@@ -853,9 +958,52 @@ func (r *O2M) adjustTableSelectColumn(sourceTable string, oracleCollation bool)
 		return "", err
 	}
 
+	// 字段名映射规则，虚拟列按目标端字段名判断是否 generated column
+	columnNameRule, err := meta.NewColumnNameRuleModel(r.MetaDB).DetailTableColumnNameRule(r.Ctx, &meta.ColumnNameRule{
+		DBTypeS:     r.Cfg.DBTypeS,
+		DBTypeT:     r.Cfg.DBTypeT,
+		SchemaNameS: r.Cfg.OracleConfig.SchemaName,
+		TableNameS:  sourceTable,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	// 虚拟列表达式改写为下游 generated column 由下游计算，不参与数据抽取以及写入
+	// 表达式无法改写下游按普通字段创建，按普通字段抽取数据写入
+	var generatedColumns map[string]struct{}
+	for _, rowCol := range columnsINFO {
+		if !strings.EqualFold(rowCol["VIRTUAL_COLUMN"], "YES") {
+			continue
+		}
+		isExist, columns, err := r.Mysql.GetMySQLTableGeneratedColumn(r.Cfg.MySQLConfig.SchemaName, targetTable)
+		if err != nil {
+			return "", err
+		}
+		if !isExist {
+			return "", fmt.Errorf("oracle schema [%s] table [%s] virtual column [%s] needs target table [%s.%s] to determine whether generated column, please create target table first",
+				r.Cfg.OracleConfig.SchemaName, sourceTable, rowCol["COLUMN_NAME"], r.Cfg.MySQLConfig.SchemaName, targetTable)
+		}
+		generatedColumns = columns
+		break
+	}
+
 	var columnNames []string
 
 	for _, rowCol := range columnsINFO {
+		if strings.EqualFold(rowCol["VIRTUAL_COLUMN"], "YES") {
+			columnNameT := rowCol["COLUMN_NAME"]
+			if val, ok := columnNameRule[common.StringUPPER(rowCol["COLUMN_NAME"])]; ok {
+				columnNameT = val
+			}
+			if _, ok := generatedColumns[common.StringUPPER(columnNameT)]; ok {
+				continue
+			}
+			zap.L().Warn("oracle virtual column isn't generated column in target table, extract as normal column",
+				zap.String("schema", r.Cfg.OracleConfig.SchemaName),
+				zap.String("table", sourceTable),
+				zap.String("column", rowCol["COLUMN_NAME"]))
+		}
 		switch strings.ToUpper(rowCol["DATA_TYPE"]) {
 		// 数字
 		case "NUMBER":
@@ -896,7 +1044,6 @@ func (r *O2M) adjustTableSelectColumn(sourceTable string, oracleCollation bool)
 				columnNames = append(columnNames, rowCol["COLUMN_NAME"])
 			}
 		}
-
 	}
 
 	return strings.Join(columnNames, ","), nil
@@ -0,0 +1,409 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package o2m
+
+import (
+	"context"
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/config"
+	"github.com/wentaojin/transferdb/database/meta"
+	"github.com/wentaojin/transferdb/database/mysql"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Importer struct {
+	Ctx    context.Context
+	Cfg    *config.Config
+	Mysql  *mysql.MySQL
+	MetaDB *meta.Meta
+}
+
+func NewCSVImporter(ctx context.Context, cfg *config.Config) (*Importer, error) {
+	mysqlDB, err := mysql.NewMySQLDBEngine(ctx, cfg.MySQLConfig)
+	if err != nil {
+		return nil, err
+	}
+	metaDB, err := meta.NewMetaDBEngine(ctx, cfg.MetaConfig, cfg.AppConfig.SlowlogThreshold)
+	if err != nil {
+		return nil, err
+	}
+	return &Importer{
+		Ctx:    ctx,
+		Cfg:    cfg,
+		Mysql:  mysqlDB,
+		MetaDB: metaDB,
+	}, nil
+}
+
+func (i *Importer) Import() error {
+	startTime := time.Now()
+	zap.L().Info("target schema csv data import start",
+		zap.String("schema", i.Cfg.MySQLConfig.SchemaName),
+		zap.String("input", i.Cfg.CSVConfig.OutputDir))
+
+	if strings.EqualFold(i.Cfg.CSVConfig.OutputFormat, common.CSVOutputFormatParquet) {
+		return fmt.Errorf("csv import isn't support output-format [%s], only support csv", i.Cfg.CSVConfig.OutputFormat)
+	}
+	if i.Cfg.CSVConfig.LOBSpillThreshold > 0 {
+		return fmt.Errorf("csv import isn't support lob-spill-threshold [%d], lob spill files can't be loaded by load data", i.Cfg.CSVConfig.LOBSpillThreshold)
+	}
+
+	// 读取导出清单
+	manifests, err := ReadManifest(i.Cfg.CSVConfig.OutputDir)
+	if err != nil {
+		return err
+	}
+	if len(manifests) == 0 {
+		zap.L().Warn("there are no csv files in the export manifest",
+			zap.String("input", i.Cfg.CSVConfig.OutputDir))
+		return nil
+	}
+
+	// 关于导入断点恢复
+	//  - 若想断点恢复，设置 enable-checkpoint true，仅导入非 SUCCESS 状态文件，存在 FAILED/RUNNING 状态文件的表清空后重新导入
+	//  - 若不想断点恢复，设置 enable-checkpoint false，清理元数据表 [csv_import_meta]，重新导入全部文件
+	if !i.Cfg.CSVConfig.EnableCheckpoint {
+		err = meta.NewCSVImportMetaModel(i.MetaDB).DeleteCSVImportMetaBySchema(i.Ctx, &meta.CSVImportMeta{
+			DBTypeS:     i.Cfg.DBTypeS,
+			DBTypeT:     i.Cfg.DBTypeT,
+			SchemaNameT: common.StringUPPER(i.Cfg.MySQLConfig.SchemaName),
+			TaskMode:    i.Cfg.TaskMode,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	// 初始化待导入文件列表
+	importMetas, err := meta.NewCSVImportMetaModel(i.MetaDB).DetailCSVImportMeta(i.Ctx, &meta.CSVImportMeta{
+		DBTypeS:     i.Cfg.DBTypeS,
+		DBTypeT:     i.Cfg.DBTypeT,
+		SchemaNameT: common.StringUPPER(i.Cfg.MySQLConfig.SchemaName),
+		TaskMode:    common.StringUPPER(i.Cfg.TaskMode),
+	})
+	if err != nil {
+		return err
+	}
+	importFiles := make(map[string]struct{})
+	for _, m := range importMetas {
+		importFiles[m.CSVFile] = struct{}{}
+	}
+	for _, m := range manifests {
+		if !strings.EqualFold(m.SchemaNameT, i.Cfg.MySQLConfig.SchemaName) {
+			continue
+		}
+		if _, ok := importFiles[m.CSVFile]; ok {
+			continue
+		}
+		err = meta.NewCSVImportMetaModel(i.MetaDB).CreateCSVImportMeta(i.Ctx, &meta.CSVImportMeta{
+			DBTypeS:      i.Cfg.DBTypeS,
+			DBTypeT:      i.Cfg.DBTypeT,
+			SchemaNameS:  common.StringUPPER(m.SchemaNameS),
+			TableNameS:   common.StringUPPER(m.TableNameS),
+			SchemaNameT:  common.StringUPPER(m.SchemaNameT),
+			TableNameT:   common.StringUPPER(m.TableNameT),
+			CSVFile:      m.CSVFile,
+			TaskMode:     common.StringUPPER(i.Cfg.TaskMode),
+			TaskStatus:   common.TaskStatusWaiting,
+			RowsManifest: m.RowCounts,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	importMetas, err = meta.NewCSVImportMetaModel(i.MetaDB).DetailCSVImportMeta(i.Ctx, &meta.CSVImportMeta{
+		DBTypeS:     i.Cfg.DBTypeS,
+		DBTypeT:     i.Cfg.DBTypeT,
+		SchemaNameT: common.StringUPPER(i.Cfg.MySQLConfig.SchemaName),
+		TaskMode:    common.StringUPPER(i.Cfg.TaskMode),
+	})
+	if err != nil {
+		return err
+	}
+
+	// LOAD DATA 失败或者中断的文件可能已部分或全部提交，重复导入存在数据重复或者主键冲突
+	// 存在 FAILED/RUNNING 状态文件的表，清空目标表并重新导入该表全部文件
+	dirtyTables := make(map[string]struct{})
+	for _, m := range importMetas {
+		if m.TaskStatus == common.TaskStatusFailed || m.TaskStatus == common.TaskStatusRunning {
+			dirtyTables[m.TableNameT] = struct{}{}
+		}
+	}
+	for tableName := range dirtyTables {
+		zap.L().Warn("csv import table exist failed or interrupted file, truncate table and reimport all files",
+			zap.String("schema", i.Cfg.MySQLConfig.SchemaName),
+			zap.String("table", tableName))
+		if err = i.Mysql.TruncateMySQLTable(common.StringUPPER(i.Cfg.MySQLConfig.SchemaName), tableName); err != nil {
+			return err
+		}
+	}
+
+	var waitImportMetas []meta.CSVImportMeta
+	for _, m := range importMetas {
+		if _, ok := dirtyTables[m.TableNameT]; ok {
+			if err = meta.NewCSVImportMetaModel(i.MetaDB).UpdateCSVImportMeta(i.Ctx, &m, map[string]interface{}{
+				"TaskStatus":   common.TaskStatusWaiting,
+				"RowsImported": 0,
+			}); err != nil {
+				return err
+			}
+			waitImportMetas = append(waitImportMetas, m)
+			continue
+		}
+		if m.TaskStatus != common.TaskStatusSuccess {
+			waitImportMetas = append(waitImportMetas, m)
+		}
+	}
+
+	// 获取目标表字段，同一张表多个文件共用
+	tableColumns := make(map[string]map[string]string)
+	for _, m := range waitImportMetas {
+		if _, ok := tableColumns[m.TableNameT]; ok {
+			continue
+		}
+		columns, err := i.Mysql.GetMySQLTableColumn(m.SchemaNameT, m.TableNameT)
+		if err != nil {
+			return err
+		}
+		if len(columns) == 0 {
+			return fmt.Errorf("target schema [%s] table [%s] isn't exist, please create table first", m.SchemaNameT, m.TableNameT)
+		}
+		columnTypes := make(map[string]string)
+		for _, c := range columns {
+			columnTypes[common.StringUPPER(c["COLUMN_NAME"])] = c["DATA_TYPE"]
+		}
+		tableColumns[m.TableNameT] = columnTypes
+	}
+
+	// 数据文件字段列表以及字符集以导出清单为准
+	// 目标表存在导出之外的字段（例如 generated column）或者字段顺序不同，按导出字段列表导入，其余字段取默认值或者计算值
+	loadSQLs := make(map[string]string)
+	for _, m := range waitImportMetas {
+		mf, ok := manifests[m.CSVFile]
+		if !ok || len(mf.Columns) == 0 {
+			return fmt.Errorf("csv file [%s] column list isn't recorded in the export manifest, please rerunning csv export", m.CSVFile)
+		}
+		for _, c := range mf.Columns {
+			if _, ok := tableColumns[m.TableNameT][common.StringUPPER(c)]; !ok {
+				return fmt.Errorf("csv file [%s] column [%s] isn't exist in target schema [%s] table [%s]", m.CSVFile, c, m.SchemaNameT, m.TableNameT)
+			}
+		}
+		charset, err := i.loadDataCharset(m.CSVFile, mf.Charset)
+		if err != nil {
+			return err
+		}
+		loadFormat, err := i.genLoadDataFormat(charset)
+		if err != nil {
+			return err
+		}
+		loadSQLs[m.CSVFile] = i.genLoadDataSQL(filepath.Join(i.Cfg.CSVConfig.OutputDir, m.CSVFile), m.SchemaNameT, m.TableNameT, loadFormat, mf.Columns, tableColumns[m.TableNameT])
+	}
+
+	g := &errgroup.Group{}
+	g.SetLimit(i.Cfg.CSVConfig.TableThreads)
+
+	for _, importMeta := range waitImportMetas {
+		m := importMeta
+		g.Go(func() error {
+			csvFile := filepath.Join(i.Cfg.CSVConfig.OutputDir, m.CSVFile)
+			loadSQL := loadSQLs[m.CSVFile]
+
+			// 导入前标记 RUNNING，进程中断后断点恢复识别
+			if err := meta.NewCSVImportMetaModel(i.MetaDB).UpdateCSVImportMeta(i.Ctx, &m, map[string]interface{}{
+				"TaskStatus": common.TaskStatusRunning,
+			}); err != nil {
+				return err
+			}
+
+			rows, err := i.Mysql.LoadMySQLTableData(csvFile, loadSQL)
+			if err == nil && rows != m.RowsManifest {
+				err = fmt.Errorf("csv file [%s] rows imported [%d] isn't equal to manifest rows [%d]", m.CSVFile, rows, m.RowsManifest)
+			}
+			if err != nil {
+				if errf := meta.NewCSVImportMetaModel(i.MetaDB).UpdateCSVImportMeta(i.Ctx, &m, map[string]interface{}{
+					"TaskStatus":   common.TaskStatusFailed,
+					"RowsImported": rows,
+					"InfoDetail":   loadSQL,
+					"ErrorDetail":  err.Error(),
+				}); errf != nil {
+					return fmt.Errorf("csv file [%s] import failed: %v, update meta failed: %v", m.CSVFile, err, errf)
+				}
+				zap.L().Error("csv file import failed",
+					zap.String("schema", m.SchemaNameT),
+					zap.String("table", m.TableNameT),
+					zap.String("file", m.CSVFile),
+					zap.Error(err))
+				return nil
+			}
+
+			if errf := meta.NewCSVImportMetaModel(i.MetaDB).UpdateCSVImportMeta(i.Ctx, &m, map[string]interface{}{
+				"TaskStatus":   common.TaskStatusSuccess,
+				"RowsImported": rows,
+				"InfoDetail":   loadSQL,
+				"ErrorDetail":  "",
+			}); errf != nil {
+				return errf
+			}
+			zap.L().Info("csv file import finished",
+				zap.String("schema", m.SchemaNameT),
+				zap.String("table", m.TableNameT),
+				zap.String("file", m.CSVFile),
+				zap.Int64("rows", rows))
+			return nil
+		})
+	}
+	if err = g.Wait(); err != nil {
+		return err
+	}
+
+	// 任务详情
+	succTotals, err := meta.NewCSVImportMetaModel(i.MetaDB).CountsCSVImportMetaByTaskStatus(i.Ctx, &meta.CSVImportMeta{
+		DBTypeS:     i.Cfg.DBTypeS,
+		DBTypeT:     i.Cfg.DBTypeT,
+		SchemaNameT: common.StringUPPER(i.Cfg.MySQLConfig.SchemaName),
+		TaskMode:    i.Cfg.TaskMode,
+		TaskStatus:  common.TaskStatusSuccess,
+	})
+	if err != nil {
+		return err
+	}
+	failedTotals, err := meta.NewCSVImportMetaModel(i.MetaDB).CountsCSVImportMetaByTaskStatus(i.Ctx, &meta.CSVImportMeta{
+		DBTypeS:     i.Cfg.DBTypeS,
+		DBTypeT:     i.Cfg.DBTypeT,
+		SchemaNameT: common.StringUPPER(i.Cfg.MySQLConfig.SchemaName),
+		TaskMode:    i.Cfg.TaskMode,
+		TaskStatus:  common.TaskStatusFailed,
+	})
+	if err != nil {
+		return err
+	}
+
+	if failedTotals == 0 {
+		zap.L().Info("target schema csv data import finished",
+			zap.String("schema", i.Cfg.MySQLConfig.SchemaName),
+			zap.Int("file totals", len(importMetas)),
+			zap.Int64("file success", succTotals),
+			zap.Int64("file failed", failedTotals),
+			zap.String("cost", time.Now().Sub(startTime).String()))
+	} else {
+		zap.L().Warn("target schema csv data import finished",
+			zap.String("schema", i.Cfg.MySQLConfig.SchemaName),
+			zap.Int("file totals", len(importMetas)),
+			zap.Int64("file success", succTotals),
+			zap.Int64("file failed", failedTotals),
+			zap.String("failed tips", "failed detail, please see meta table [csv_import_meta]"),
+			zap.String("cost", time.Now().Sub(startTime).String()))
+	}
+	return nil
+}
+
+// loadDataCharset 数据文件字符集以导出清单记录为准，导出清单未记录以配置文件 charset 为准
+func (i *Importer) loadDataCharset(csvFile, manifestCharset string) (string, error) {
+	if manifestCharset != "" {
+		if i.Cfg.CSVConfig.Charset != "" && !strings.EqualFold(i.Cfg.CSVConfig.Charset, manifestCharset) {
+			zap.L().Warn("csv import charset isn't equal to the export manifest charset, using manifest charset",
+				zap.String("file", csvFile),
+				zap.String("config charset", i.Cfg.CSVConfig.Charset),
+				zap.String("manifest charset", manifestCharset))
+		}
+		return manifestCharset, nil
+	}
+	if i.Cfg.CSVConfig.Charset != "" {
+		return i.Cfg.CSVConfig.Charset, nil
+	}
+	return "", fmt.Errorf("csv file [%s] charset isn't recorded in the export manifest, please set config [csv] charset same as export", csvFile)
+}
+
+// genLoadDataFormat 根据 csv 导出参数生成 LOAD DATA 字符集以及格式子句
+func (i *Importer) genLoadDataFormat(charset string) (string, error) {
+	mysqlCharset, ok := common.CSVCharacterSetMySQLMap[common.StringUPPER(charset)]
+	if !ok {
+		return "", fmt.Errorf("csv import charset [%s] isn't support", charset)
+	}
+
+	separator := i.Cfg.CSVConfig.Separator
+	if separator == "" {
+		separator = ","
+	}
+	terminator := i.Cfg.CSVConfig.Terminator
+	if terminator == "" {
+		terminator = "\r\n"
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("CHARACTER SET %s FIELDS TERMINATED BY '%s'", mysqlCharset, escapeLoadDataString(separator)))
+	if i.Cfg.CSVConfig.Delimiter != "" {
+		// LOAD DATA ENCLOSED BY 仅支持单字符
+		if len(i.Cfg.CSVConfig.Delimiter) > 1 {
+			return "", fmt.Errorf("csv import delimiter [%s] isn't support, load data enclosed by only support single character", i.Cfg.CSVConfig.Delimiter)
+		}
+		sb.WriteString(fmt.Sprintf(" ENCLOSED BY '%s'", escapeLoadDataString(i.Cfg.CSVConfig.Delimiter)))
+	}
+	if i.Cfg.CSVConfig.EscapeBackslash {
+		sb.WriteString(` ESCAPED BY '\\'`)
+	} else {
+		sb.WriteString(` ESCAPED BY ''`)
+	}
+	sb.WriteString(fmt.Sprintf(" LINES TERMINATED BY '%s'", escapeLoadDataString(terminator)))
+	if i.Cfg.CSVConfig.Header {
+		sb.WriteString(" IGNORE 1 LINES")
+	}
+	return sb.String(), nil
+}
+
+// genLoadDataSQL 生成 LOAD DATA 语句，字段列表以数据文件字段顺序为准，字段经用户变量处理 NULL 标识以及二进制编码
+func (i *Importer) genLoadDataSQL(csvFile, schemaName, tableName, loadFormat string, fileColumns []string, columnTypes map[string]string) string {
+	nullValue := i.Cfg.CSVConfig.NullValue
+	if nullValue == "" {
+		nullValue = common.CSVNullValueDefault
+	}
+
+	var (
+		vars []string
+		sets []string
+	)
+	for idx, c := range fileColumns {
+		v := common.StringsBuilder("@c", strconv.Itoa(idx))
+		vars = append(vars, v)
+
+		expr := fmt.Sprintf("NULLIF(%s, '%s')", v, escapeLoadDataString(nullValue))
+		switch strings.ToUpper(columnTypes[common.StringUPPER(c)]) {
+		case "BINARY", "VARBINARY", "TINYBLOB", "BLOB", "MEDIUMBLOB", "LONGBLOB":
+			switch common.StringUPPER(i.Cfg.CSVConfig.BinaryEncoding) {
+			case common.CSVBinaryEncodingHex:
+				expr = fmt.Sprintf("UNHEX(%s)", expr)
+			case common.CSVBinaryEncodingBase64:
+				expr = fmt.Sprintf("FROM_BASE64(%s)", expr)
+			}
+		}
+		sets = append(sets, fmt.Sprintf("`%s` = %s", c, expr))
+	}
+
+	return fmt.Sprintf("LOAD DATA LOCAL INFILE '%s' INTO TABLE `%s`.`%s` %s (%s) SET %s",
+		escapeLoadDataString(csvFile), schemaName, tableName, loadFormat,
+		strings.Join(vars, ","), strings.Join(sets, ","))
+}
+
+func escapeLoadDataString(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s)
+}
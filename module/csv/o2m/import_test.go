@@ -0,0 +1,131 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package o2m
+
+import (
+	"github.com/wentaojin/transferdb/config"
+	"strings"
+	"testing"
+)
+
+func TestGenLoadDataFormat(t *testing.T) {
+	cases := []struct {
+		name    string
+		csv     config.CSVConfig
+		charset string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "default separator and terminator",
+			charset: "utf8",
+			want:    `CHARACTER SET utf8mb4 FIELDS TERMINATED BY ',' ESCAPED BY '' LINES TERMINATED BY '` + "\r\n" + `'`,
+		},
+		{
+			name:    "delimiter escape backslash and header",
+			csv:     config.CSVConfig{Separator: "|", Terminator: "\n", Delimiter: `'`, EscapeBackslash: true, Header: true},
+			charset: "GBK",
+			want:    `CHARACTER SET gbk FIELDS TERMINATED BY '|' ENCLOSED BY '\'' ESCAPED BY '\\' LINES TERMINATED BY '` + "\n" + `' IGNORE 1 LINES`,
+		},
+		{
+			name:    "backslash separator escaped",
+			csv:     config.CSVConfig{Separator: `\`, Terminator: "\n", Delimiter: `"`},
+			charset: "BIG5",
+			want:    `CHARACTER SET big5 FIELDS TERMINATED BY '\\' ENCLOSED BY '"' ESCAPED BY '' LINES TERMINATED BY '` + "\n" + `'`,
+		},
+		{name: "multi character delimiter", csv: config.CSVConfig{Delimiter: `""`}, charset: "UTF8", wantErr: true},
+		{name: "unsupported charset", charset: "UTF16", wantErr: true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			i := &Importer{Cfg: &config.Config{CSVConfig: c.csv}}
+			got, err := i.genLoadDataFormat(c.charset)
+			if (err != nil) != c.wantErr {
+				t.Fatalf("genLoadDataFormat error got %v, want error %v", err, c.wantErr)
+			}
+			if got != c.want {
+				t.Fatalf("genLoadDataFormat got %q, want %q", got, c.want)
+			}
+		})
+	}
+}
+
+func TestGenLoadDataSQL(t *testing.T) {
+	columnTypes := map[string]string{"ID": "BIGINT", "NAME": "VARCHAR", "PIC": "BLOB"}
+	loadFormat := `CHARACTER SET utf8mb4 FIELDS TERMINATED BY ','`
+	cases := []struct {
+		name string
+		csv  config.CSVConfig
+		file string
+		want string
+	}{
+		{
+			name: "default null marker",
+			file: "/data/MARVIN.T1.0.csv",
+			want: "LOAD DATA LOCAL INFILE '/data/MARVIN.T1.0.csv' INTO TABLE `marvin`.`t1` " + loadFormat +
+				" (@c0,@c1,@c2) SET `ID` = NULLIF(@c0, 'NULL'),`NAME` = NULLIF(@c1, 'NULL'),`PIC` = NULLIF(@c2, 'NULL')",
+		},
+		{
+			name: "custom null marker and hex binary",
+			csv:  config.CSVConfig{NullValue: `\N`, BinaryEncoding: "hex"},
+			file: "/data/it's/MARVIN.T1.0.csv",
+			want: "LOAD DATA LOCAL INFILE '/data/it\\'s/MARVIN.T1.0.csv' INTO TABLE `marvin`.`t1` " + loadFormat +
+				" (@c0,@c1,@c2) SET `ID` = NULLIF(@c0, '\\\\N'),`NAME` = NULLIF(@c1, '\\\\N'),`PIC` = UNHEX(NULLIF(@c2, '\\\\N'))",
+		},
+		{
+			name: "base64 binary",
+			csv:  config.CSVConfig{BinaryEncoding: "BASE64"},
+			file: "/data/MARVIN.T1.0.csv",
+			want: "LOAD DATA LOCAL INFILE '/data/MARVIN.T1.0.csv' INTO TABLE `marvin`.`t1` " + loadFormat +
+				" (@c0,@c1,@c2) SET `ID` = NULLIF(@c0, 'NULL'),`NAME` = NULLIF(@c1, 'NULL'),`PIC` = FROM_BASE64(NULLIF(@c2, 'NULL'))",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			i := &Importer{Cfg: &config.Config{CSVConfig: c.csv}}
+			got := i.genLoadDataSQL(c.file, "marvin", "t1", loadFormat, []string{"ID", "NAME", "PIC"}, columnTypes)
+			if got != c.want {
+				t.Fatalf("genLoadDataSQL got\n%s\nwant\n%s", got, c.want)
+			}
+		})
+	}
+}
+
+func TestLoadDataCharset(t *testing.T) {
+	cases := []struct {
+		name            string
+		configCharset   string
+		manifestCharset string
+		want            string
+		wantErr         bool
+	}{
+		{name: "manifest charset first", configCharset: "UTF8", manifestCharset: "GBK", want: "GBK"},
+		{name: "config charset fallback", configCharset: "GBK", want: "GBK"},
+		{name: "charset unknown", wantErr: true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			i := &Importer{Cfg: &config.Config{CSVConfig: config.CSVConfig{Charset: c.configCharset}}}
+			got, err := i.loadDataCharset("MARVIN.T1.0.csv", c.manifestCharset)
+			if (err != nil) != c.wantErr {
+				t.Fatalf("loadDataCharset error got %v, want error %v", err, c.wantErr)
+			}
+			if !strings.EqualFold(got, c.want) {
+				t.Fatalf("loadDataCharset got %q, want %q", got, c.want)
+			}
+		})
+	}
+}
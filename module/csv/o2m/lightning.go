@@ -0,0 +1,209 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package o2m
+
+import (
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/database/meta"
+	reverseo2m "github.com/wentaojin/transferdb/module/reverse/o2m"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// genCSVFileName 根据导出目录布局以及输出格式生成数据文件名
+// DEFAULT: OutputDir/SCHEMA_S/TABLE_S/SCHEMA_T.TABLE_T.{seq}.{csv|parquet}
+// LIGHTNING: OutputDir/SCHEMA_T.TABLE_T.{seq}.{csv|parquet}，与 TiDB Lightning / Dumpling 文件命名规则保持一致
+func (r *O2M) genCSVFileName(sourceTable, targetTable string, seq int) string {
+	fileExt := `.csv`
+	if strings.EqualFold(r.Cfg.CSVConfig.OutputFormat, common.CSVOutputFormatParquet) {
+		fileExt = `.parquet`
+	}
+	if strings.EqualFold(r.Cfg.CSVConfig.ExportLayout, common.CSVExportLayoutLightning) {
+		return filepath.Join(r.Cfg.CSVConfig.OutputDir,
+			common.StringsBuilder(common.StringUPPER(r.Cfg.MySQLConfig.SchemaName), `.`,
+				common.StringUPPER(targetTable), `.`, fmt.Sprintf("%09d", seq), fileExt))
+	}
+	return filepath.Join(r.Cfg.CSVConfig.OutputDir,
+		common.StringUPPER(r.Cfg.OracleConfig.SchemaName), common.StringUPPER(sourceTable),
+		common.StringsBuilder(common.StringUPPER(r.Cfg.MySQLConfig.SchemaName), `.`,
+			common.StringUPPER(targetTable), `.`, strconv.Itoa(seq), fileExt))
+}
+
+// genLightningSchemaFile 输出 TiDB Lightning 所需库表结构文件
+// {schema}-schema-create.sql 以及 {schema}.{table}-schema.sql，表结构复用 reverse 模式 DDL 生成逻辑
+func (r *O2M) genLightningSchemaFile(exporters []string, oraDBVersion string, oracleCollation bool) error {
+	startTime := time.Now()
+
+	if err := common.PathExist(r.Cfg.CSVConfig.OutputDir); err != nil {
+		return err
+	}
+
+	nlsComp, err := r.Oracle.GetOracleDBCharacterNLSCompCollation()
+	if err != nil {
+		return err
+	}
+	nlsSort, err := r.Oracle.GetOracleDBCharacterNLSSortCollation()
+	if err != nil {
+		return err
+	}
+	if _, ok := common.OracleCollationMap[common.StringUPPER(nlsComp)]; !ok {
+		return fmt.Errorf("oracle db nls comp [%s] , mysql db isn't support", nlsComp)
+	}
+	if _, ok := common.OracleCollationMap[common.StringUPPER(nlsSort)]; !ok {
+		return fmt.Errorf("oracle db nls sort [%s] , mysql db isn't support", nlsSort)
+	}
+	if !strings.EqualFold(nlsSort, nlsComp) {
+		return fmt.Errorf("oracle db nls_sort [%s] and nls_comp [%s] isn't different, need be equal; because mysql db isn't support", nlsSort, nlsComp)
+	}
+
+	// schema create
+	createSchema, err := reverseo2m.GenCreateSchemaSQL(r.Oracle,
+		common.StringUPPER(r.Cfg.OracleConfig.SchemaName), common.StringUPPER(r.Cfg.MySQLConfig.SchemaName), nlsComp)
+	if err != nil {
+		return err
+	}
+	if err = os.WriteFile(filepath.Join(r.Cfg.CSVConfig.OutputDir,
+		common.StringsBuilder(common.StringUPPER(r.Cfg.MySQLConfig.SchemaName), `-schema-create.sql`)),
+		[]byte(createSchema+"\n"), 0644); err != nil {
+		return fmt.Errorf("write lightning schema create file failed: %v", err)
+	}
+
+	rev := &reverseo2m.Reverse{
+		Ctx:    r.Ctx,
+		Cfg:    r.Cfg,
+		Mysql:  r.Mysql,
+		Oracle: r.Oracle,
+		MetaDB: r.MetaDB,
+	}
+
+	tableNameRuleMap, tableColumnRuleMap, tableDefaultRuleMap, tableColumnNameRuleMap, err := reverseo2m.IChanger(&reverseo2m.Change{
+		Ctx:              r.Ctx,
+		DBTypeS:          r.Cfg.DBTypeS,
+		DBTypeT:          r.Cfg.DBTypeT,
+		SourceSchemaName: common.StringUPPER(r.Cfg.OracleConfig.SchemaName),
+		TargetSchemaName: common.StringUPPER(r.Cfg.MySQLConfig.SchemaName),
+		SourceTables:     exporters,
+		OracleCollation:  oracleCollation,
+		Threads:          r.Cfg.CSVConfig.TableThreads,
+		Oracle:           r.Oracle,
+		MetaDB:           r.MetaDB,
+	})
+	if err != nil {
+		return err
+	}
+
+	tables, err := reverseo2m.GenReverseTableTask(rev, tableNameRuleMap, tableColumnRuleMap, tableDefaultRuleMap, tableColumnNameRuleMap, oraDBVersion, oracleCollation, exporters, nlsSort, nlsComp)
+	if err != nil {
+		return err
+	}
+
+	g := &errgroup.Group{}
+	g.SetLimit(r.Cfg.CSVConfig.TableThreads)
+
+	for _, table := range tables {
+		t := table
+		g.Go(func() error {
+			rule, err := reverseo2m.IReader(t)
+			if err != nil {
+				return fmt.Errorf("lightning schema table [%s] reader failed: %v", t.SourceTableName, err)
+			}
+			ddl, err := reverseo2m.IReverse(rule)
+			if err != nil {
+				return fmt.Errorf("lightning schema table [%s] reverse failed: %v", t.SourceTableName, err)
+			}
+			if err = os.WriteFile(filepath.Join(r.Cfg.CSVConfig.OutputDir,
+				common.StringsBuilder(common.StringUPPER(t.TargetSchemaName), `.`, common.StringUPPER(t.TargetTableName), `-schema.sql`)),
+				[]byte(ddl.GenCreateTableSQL()+"\n"), 0644); err != nil {
+				return fmt.Errorf("write lightning schema table [%s] file failed: %v", t.SourceTableName, err)
+			}
+			// 函数索引 generated column 位于表字段末尾，不影响导出字段顺序，记录用于 check 等模式对比字段时忽略
+			return rev.GenIndexGeneratedColumn(ddl)
+		})
+	}
+	if err = g.Wait(); err != nil {
+		return err
+	}
+
+	zap.L().Info("output lightning schema file finished",
+		zap.String("schema", r.Cfg.OracleConfig.SchemaName),
+		zap.Int("table totals", len(tables)),
+		zap.String("output", r.Cfg.CSVConfig.OutputDir),
+		zap.String("cost", time.Now().Sub(startTime).String()))
+	return nil
+}
+
+// genLightningMetadataFile 输出 metadata 文件，记录导出起止时间以及各表快照 SCN
+// 表列表以元数据 wait_sync_meta 已完成表为准，断点续传时包含之前运行已完成的表，导出开始时间取最早完成表记录创建时间
+func (r *O2M) genLightningMetadataFile(startTime time.Time, exporters []string) error {
+	succMetas, err := meta.NewWaitSyncMetaModel(r.MetaDB).DetailWaitSyncMeta(r.Ctx, &meta.WaitSyncMeta{
+		DBTypeS:     r.Cfg.DBTypeS,
+		DBTypeT:     r.Cfg.DBTypeT,
+		SchemaNameS: common.StringUPPER(r.Cfg.OracleConfig.SchemaName),
+		TaskMode:    r.Cfg.TaskMode,
+		TaskStatus:  common.TaskStatusSuccess,
+	})
+	if err != nil {
+		return err
+	}
+
+	var (
+		doneMetas []meta.WaitSyncMeta
+		minSCN    uint64
+		sb        strings.Builder
+	)
+	for _, m := range succMetas {
+		if !common.IsContainString(exporters, common.StringUPPER(m.TableNameS)) {
+			continue
+		}
+		if len(doneMetas) == 0 || m.GlobalScnS < minSCN {
+			minSCN = m.GlobalScnS
+		}
+		if m.BaseModel != nil && !m.CreatedAt.IsZero() && m.CreatedAt.Before(startTime) {
+			startTime = m.CreatedAt
+		}
+		doneMetas = append(doneMetas, m)
+	}
+	sort.Slice(doneMetas, func(i, j int) bool {
+		return doneMetas[i].TableNameS < doneMetas[j].TableNameS
+	})
+
+	sb.WriteString(fmt.Sprintf("Started dump at: %s\n", startTime.Format("2006-01-02 15:04:05")))
+	sb.WriteString("SHOW MASTER STATUS:\n")
+	sb.WriteString(fmt.Sprintf("\tLog: %s\n", common.DatabaseTypeOracle))
+	sb.WriteString(fmt.Sprintf("\tPos: %d\n", minSCN))
+	sb.WriteString("\tGTID:\n\n")
+	sb.WriteString("TABLE SNAPSHOT SCN:\n")
+	for _, m := range doneMetas {
+		sb.WriteString(fmt.Sprintf("\t%s.%s: %d\n", m.SchemaNameS, m.TableNameS, m.GlobalScnS))
+	}
+	sb.WriteString(fmt.Sprintf("Finished dump at: %s\n", time.Now().Format("2006-01-02 15:04:05")))
+
+	if err = os.WriteFile(filepath.Join(r.Cfg.CSVConfig.OutputDir, common.CSVLightningMetadataFile), []byte(sb.String()), 0644); err != nil {
+		return fmt.Errorf("write lightning metadata file failed: %v", err)
+	}
+	zap.L().Info("output lightning metadata file finished",
+		zap.String("schema", r.Cfg.OracleConfig.SchemaName),
+		zap.Int("table totals", len(doneMetas)),
+		zap.Uint64("min scn", minSCN))
+	return nil
+}
@@ -0,0 +1,108 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package o2m
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// 导出清单写入互斥，多表多 chunk 并发追加
+var manifestMutex sync.Mutex
+
+// Manifest 导出清单，每个数据文件一行 JSON 记录，同一文件重复导出以最后一条为准
+// Columns 数据文件字段顺序（目标端字段名），Charset 数据文件输出字符集，csv-import 以此生成 LOAD DATA 字段列表以及字符集
+type Manifest struct {
+	SchemaNameS string   `json:"schema_name_s"`
+	TableNameS  string   `json:"table_name_s"`
+	SchemaNameT string   `json:"schema_name_t"`
+	TableNameT  string   `json:"table_name_t"`
+	CSVFile     string   `json:"csv_file"`
+	RowCounts   int64    `json:"row_counts"`
+	Columns     []string `json:"columns"`
+	Charset     string   `json:"charset"`
+}
+
+// AppendManifest 追加导出清单记录，CSVFile 记录相对 output-dir 路径
+func AppendManifest(outputDir string, m *Manifest) error {
+	relFile, err := filepath.Rel(outputDir, m.CSVFile)
+	if err != nil {
+		return fmt.Errorf("get csv file [%s] relative path failed: %v", m.CSVFile, err)
+	}
+	m.CSVFile = relFile
+
+	jsonBytes, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	manifestMutex.Lock()
+	defer manifestMutex.Unlock()
+
+	if err = common.PathExist(outputDir); err != nil {
+		return err
+	}
+	fileW, err := os.OpenFile(filepath.Join(outputDir, common.CSVManifestFile), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0666)
+	if err != nil {
+		return err
+	}
+	defer fileW.Close()
+
+	if _, err = fileW.Write(append(jsonBytes, '\n')); err != nil {
+		return fmt.Errorf("append csv manifest failed: %v", err)
+	}
+	return nil
+}
+
+// ReadManifest 读取导出清单，返回按 CSVFile 去重后的记录
+func ReadManifest(outputDir string) (map[string]Manifest, error) {
+	manifests := make(map[string]Manifest)
+
+	fileR, err := os.Open(filepath.Join(outputDir, common.CSVManifestFile))
+	if err != nil {
+		return manifests, fmt.Errorf("open csv manifest failed: %v", err)
+	}
+	defer fileR.Close()
+
+	scanner := bufio.NewScanner(fileR)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var m Manifest
+		if err = json.Unmarshal(scanner.Bytes(), &m); err != nil {
+			return manifests, fmt.Errorf("parse csv manifest line [%s] failed: %v", scanner.Text(), err)
+		}
+		manifests[m.CSVFile] = m
+	}
+	if err = scanner.Err(); err != nil {
+		return manifests, fmt.Errorf("read csv manifest failed: %v", err)
+	}
+	return manifests, nil
+}
+
+// RemoveManifest 清理导出清单，用于非断点续传重新导出
+func RemoveManifest(outputDir string) error {
+	if err := os.Remove(filepath.Join(outputDir, common.CSVManifestFile)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("remove csv manifest failed: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package o2m
+
+import (
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/config"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestManifestAppendRead(t *testing.T) {
+	outputDir := t.TempDir()
+	records := []Manifest{
+		{SchemaNameS: "MARVIN", TableNameS: "T1", SchemaNameT: "marvin", TableNameT: "t1", CSVFile: filepath.Join(outputDir, "marvin.t1.000000001.csv"), RowCounts: 10, Columns: []string{"ID", "NAME"}, Charset: "UTF8"},
+		{SchemaNameS: "MARVIN", TableNameS: "T2", SchemaNameT: "marvin", TableNameT: "t2", CSVFile: filepath.Join(outputDir, "MARVIN", "T2", "marvin.t2.0.csv"), RowCounts: 5, Columns: []string{"ID"}, Charset: "GBK"},
+		// 同一文件重复导出以最后一条为准
+		{SchemaNameS: "MARVIN", TableNameS: "T1", SchemaNameT: "marvin", TableNameT: "t1", CSVFile: filepath.Join(outputDir, "marvin.t1.000000001.csv"), RowCounts: 12, Columns: []string{"ID", "NAME"}, Charset: "UTF8"},
+	}
+	for i := range records {
+		m := records[i]
+		if err := AppendManifest(outputDir, &m); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 每个数据文件一行 JSON 记录，数据文件记录相对 output-dir 路径
+	by, err := os.ReadFile(filepath.Join(outputDir, common.CSVManifestFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(by), "\n"), "\n")
+	if len(lines) != len(records) {
+		t.Fatalf("manifest lines got %d, want %d", len(lines), len(records))
+	}
+	wantLine := `{"schema_name_s":"MARVIN","table_name_s":"T2","schema_name_t":"marvin","table_name_t":"t2","csv_file":"` +
+		filepath.Join("MARVIN", "T2", "marvin.t2.0.csv") + `","row_counts":5,"columns":["ID"],"charset":"GBK"}`
+	if lines[1] != wantLine {
+		t.Fatalf("manifest line got %s, want %s", lines[1], wantLine)
+	}
+
+	manifests, err := ReadManifest(outputDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]Manifest{
+		"marvin.t1.000000001.csv":                        {SchemaNameS: "MARVIN", TableNameS: "T1", SchemaNameT: "marvin", TableNameT: "t1", CSVFile: "marvin.t1.000000001.csv", RowCounts: 12, Columns: []string{"ID", "NAME"}, Charset: "UTF8"},
+		filepath.Join("MARVIN", "T2", "marvin.t2.0.csv"): {SchemaNameS: "MARVIN", TableNameS: "T2", SchemaNameT: "marvin", TableNameT: "t2", CSVFile: filepath.Join("MARVIN", "T2", "marvin.t2.0.csv"), RowCounts: 5, Columns: []string{"ID"}, Charset: "GBK"},
+	}
+	if !reflect.DeepEqual(manifests, want) {
+		t.Fatalf("read manifest got %+v, want %+v", manifests, want)
+	}
+
+	if err = RemoveManifest(outputDir); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = ReadManifest(outputDir); err == nil {
+		t.Fatal("read removed manifest should be failed")
+	}
+	// 清单不存在重复清理不报错
+	if err = RemoveManifest(outputDir); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGenCSVFileName(t *testing.T) {
+	cases := []struct {
+		name   string
+		layout string
+		format string
+		seq    int
+		want   string
+	}{
+		{name: "default layout csv", layout: "", format: "", seq: 3, want: filepath.Join("/out", "MARVIN", "T1", "TIDB.T1_NEW.3.csv")},
+		{name: "default layout parquet", layout: common.CSVExportLayoutDefault, format: "parquet", seq: 0, want: filepath.Join("/out", "MARVIN", "T1", "TIDB.T1_NEW.0.parquet")},
+		{name: "lightning layout csv", layout: "lightning", format: "csv", seq: 12, want: filepath.Join("/out", "TIDB.T1_NEW.000000012.csv")},
+		{name: "lightning layout parquet", layout: common.CSVExportLayoutLightning, format: "PARQUET", seq: 1, want: filepath.Join("/out", "TIDB.T1_NEW.000000001.parquet")},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r := &O2M{Cfg: &config.Config{
+				OracleConfig: config.OracleConfig{SchemaName: "marvin"},
+				MySQLConfig:  config.MySQLConfig{SchemaName: "tidb"},
+				CSVConfig:    config.CSVConfig{OutputDir: "/out", ExportLayout: c.layout, OutputFormat: c.format},
+			}}
+			if got := r.genCSVFileName("t1", "t1_new", c.seq); got != c.want {
+				t.Fatalf("genCSVFileName got %s, want %s", got, c.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,290 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package o2m
+
+import (
+	"bufio"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"github.com/shopspring/decimal"
+	"github.com/wentaojin/transferdb/common"
+	"github.com/xitongsys/parquet-go/types"
+	"github.com/xitongsys/parquet-go/writer"
+	"go.uber.org/zap"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// parquet 列值转换类型
+const (
+	parquetColumnString    = "STRING"
+	parquetColumnDecimal   = "DECIMAL"
+	parquetColumnFloat     = "FLOAT"
+	parquetColumnDouble    = "DOUBLE"
+	parquetColumnTimestamp = "TIMESTAMP"
+	parquetColumnBinary    = "BINARY"
+
+	// parquet 写入并发 marshal 数
+	parquetWriterParallel = 4
+)
+
+type ParquetColumn struct {
+	ColumnName string `json:"column_name"`
+	DataType   string `json:"data_type"`
+	ValueType  string `json:"value_type"`
+	Precision  int    `json:"precision"`
+	Scale      int    `json:"scale"`
+	Metadata   string `json:"metadata"`
+}
+
+type Parquet struct {
+	SourceSchema  string           `json:"source_schema"`
+	SourceTable   string           `json:"source_table"`
+	SourceColumns []*ParquetColumn `json:"source_columns"`
+	QuerySQL      string           `json:"query_sql"`
+	FileName      string           `json:"file_name"`
+	RowCounts     int64            `json:"row_counts"`
+	Rows          *sql.Rows        `json:"-"`
+}
+
+func NewParquetWriter(sourceSchema, sourceTable, querySQL, fileName string, sourceColumns []*ParquetColumn, rows *sql.Rows) *Parquet {
+	return &Parquet{
+		SourceSchema:  sourceSchema,
+		SourceTable:   sourceTable,
+		SourceColumns: sourceColumns,
+		QuerySQL:      querySQL,
+		FileName:      fileName,
+		Rows:          rows,
+	}
+}
+
+// GenParquetColumns 根据 Oracle 字段元数据以及查询字段顺序生成 parquet schema
+// NUMBER(p,s) -> DECIMAL，未指定精度 NUMBER 以字符串输出避免精度丢失
+// DATE/TIMESTAMP -> TIMESTAMP(MICROS)，RAW/BLOB -> BINARY，其他类型统一 UTF8 字符串
+func GenParquetColumns(columnsINFO []map[string]string, queryColumns []string) ([]*ParquetColumn, error) {
+	columnMetas := make(map[string]map[string]string)
+	for _, c := range columnsINFO {
+		columnMetas[common.StringUPPER(c["COLUMN_NAME"])] = c
+	}
+
+	var columns []*ParquetColumn
+	for _, qc := range queryColumns {
+		c, ok := columnMetas[common.StringUPPER(qc)]
+		if !ok {
+			return nil, fmt.Errorf("query column [%s] isn't found in oracle column metadata", qc)
+		}
+		dataType := common.StringUPPER(c["DATA_TYPE"])
+
+		pc := &ParquetColumn{
+			ColumnName: qc,
+			DataType:   dataType,
+		}
+
+		switch {
+		case dataType == "NUMBER":
+			precision, err := strconv.Atoi(c["DATA_PRECISION"])
+			if err != nil {
+				return nil, fmt.Errorf("column [%s] data_precision [%s] strconv.Atoi failed: %v", qc, c["DATA_PRECISION"], err)
+			}
+			scale, err := strconv.Atoi(c["DATA_SCALE"])
+			if err != nil {
+				return nil, fmt.Errorf("column [%s] data_scale [%s] strconv.Atoi failed: %v", qc, c["DATA_SCALE"], err)
+			}
+			// 负数 scale 表示整数部分舍入，number(p,-s) 等价于 decimal(p+s,0)
+			if scale < 0 {
+				precision = precision - scale
+				scale = 0
+			}
+			// number / number(*) 查询结果为 number(38,127)，无法确定精度，字符串输出
+			if scale > precision || precision > 38 {
+				pc.ValueType = parquetColumnString
+			} else {
+				pc.ValueType = parquetColumnDecimal
+				pc.Precision = precision
+				pc.Scale = scale
+			}
+		case dataType == "BINARY_FLOAT":
+			pc.ValueType = parquetColumnFloat
+		case dataType == "BINARY_DOUBLE" || dataType == "FLOAT" || dataType == "REAL" || dataType == "DOUBLE PRECISION":
+			pc.ValueType = parquetColumnDouble
+		case dataType == "DATE" || (strings.Contains(dataType, "TIMESTAMP")):
+			pc.ValueType = parquetColumnTimestamp
+		case dataType == "RAW" || dataType == "LONG RAW" || dataType == "BLOB":
+			pc.ValueType = parquetColumnBinary
+		default:
+			pc.ValueType = parquetColumnString
+		}
+
+		switch pc.ValueType {
+		case parquetColumnDecimal:
+			pc.Metadata = fmt.Sprintf("name=%s, type=BYTE_ARRAY, convertedtype=DECIMAL, precision=%d, scale=%d, repetitiontype=OPTIONAL",
+				qc, pc.Precision, pc.Scale)
+		case parquetColumnFloat:
+			pc.Metadata = fmt.Sprintf("name=%s, type=FLOAT, repetitiontype=OPTIONAL", qc)
+		case parquetColumnDouble:
+			pc.Metadata = fmt.Sprintf("name=%s, type=DOUBLE, repetitiontype=OPTIONAL", qc)
+		case parquetColumnTimestamp:
+			// Oracle DATE/TIMESTAMP 为本地时间，不做 UTC 调整
+			pc.Metadata = fmt.Sprintf("name=%s, type=INT64, logicaltype=TIMESTAMP, logicaltype.isadjustedtoutc=false, logicaltype.unit=MICROS, repetitiontype=OPTIONAL", qc)
+		case parquetColumnBinary:
+			pc.Metadata = fmt.Sprintf("name=%s, type=BYTE_ARRAY, repetitiontype=OPTIONAL", qc)
+		default:
+			pc.Metadata = fmt.Sprintf("name=%s, type=BYTE_ARRAY, convertedtype=UTF8, repetitiontype=OPTIONAL", qc)
+		}
+		columns = append(columns, pc)
+	}
+	return columns, nil
+}
+
+func (p *Parquet) WriteFile() error {
+	// 文件目录判断
+	if err := common.PathExist(filepath.Dir(p.FileName)); err != nil {
+		return err
+	}
+
+	fileW, err := os.OpenFile(p.FileName, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0666)
+	if err != nil {
+		return err
+	}
+	defer fileW.Close()
+
+	if err = p.write(fileW); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (p *Parquet) write(fileW *os.File) error {
+	bufW := bufio.NewWriter(fileW)
+
+	var md []string
+	for _, c := range p.SourceColumns {
+		md = append(md, c.Metadata)
+	}
+	pw, err := writer.NewCSVWriterFromWriter(md, bufW, parquetWriterParallel)
+	if err != nil {
+		return fmt.Errorf("failed to create parquet writer: %v", err)
+	}
+
+	// 统计行数
+	var rowCount int
+
+	// 数据 SCAN
+	columnNums := len(p.SourceColumns)
+	rawResult := make([][]byte, columnNums)
+	dest := make([]interface{}, columnNums)
+	for i := range rawResult {
+		dest[i] = &rawResult[i]
+	}
+
+	for p.Rows.Next() {
+		rowCount = rowCount + 1
+
+		err = p.Rows.Scan(dest...)
+		if err != nil {
+			return err
+		}
+
+		results := make([]interface{}, columnNums)
+		for i, raw := range rawResult {
+			// Oracle 空字符串与 NULL 归于一类，统一 NULL 处理
+			if raw == nil || len(raw) == 0 {
+				results[i] = nil
+				continue
+			}
+			val, err := p.SourceColumns[i].convert(raw)
+			if err != nil {
+				return err
+			}
+			results[i] = val
+		}
+
+		if err = pw.Write(results); err != nil {
+			return fmt.Errorf("failed to write data row to parquet: %v", err)
+		}
+	}
+
+	if err = p.Rows.Err(); err != nil {
+		return err
+	}
+
+	if err = pw.WriteStop(); err != nil {
+		return fmt.Errorf("failed to stop parquet writer: %v", err)
+	}
+	if err = bufW.Flush(); err != nil {
+		return fmt.Errorf("failed to flush data row to parquet: %v", err)
+	}
+
+	// Close Rows
+	if err = p.Rows.Close(); err != nil {
+		return err
+	}
+
+	p.RowCounts = int64(rowCount)
+
+	zap.L().Info("oracle schema table rowid data rows",
+		zap.String("schema", p.SourceSchema),
+		zap.String("table", p.SourceTable),
+		zap.Int("rows", rowCount),
+		zap.String("query sql", p.QuerySQL),
+		zap.String("detail", p.String()))
+	return nil
+}
+
+// convert 根据 parquet 列类型转换查询结果原始值
+func (c *ParquetColumn) convert(raw []byte) (interface{}, error) {
+	switch c.ValueType {
+	case parquetColumnDecimal:
+		d, err := decimal.NewFromString(string(raw))
+		if err != nil {
+			return nil, fmt.Errorf("column [%s] decimal convert failed, %v", c.ColumnName, err)
+		}
+		// decimal 以大端补码存储去除小数位后的整数值
+		return types.StrIntToBinary(d.Shift(int32(c.Scale)).Round(0).BigInt().String(), "BigEndian", 0, true), nil
+	case parquetColumnFloat:
+		r, err := common.StrconvFloatBitSize(string(raw), 32)
+		if err != nil {
+			return nil, fmt.Errorf("column [%s] strconv failed, %v", c.ColumnName, err)
+		}
+		return float32(r), nil
+	case parquetColumnDouble:
+		r, err := common.StrconvFloatBitSize(string(raw), 64)
+		if err != nil {
+			return nil, fmt.Errorf("column [%s] strconv failed, %v", c.ColumnName, err)
+		}
+		return r, nil
+	case parquetColumnTimestamp:
+		// 查询字段已 TO_CHAR 格式化为 yyyy-mm-dd hh24:mi:ss[.ff]
+		t, err := time.ParseInLocation("2006-01-02 15:04:05.999999999", string(raw), time.UTC)
+		if err != nil {
+			return nil, fmt.Errorf("column [%s] timestamp parse failed, %v", c.ColumnName, err)
+		}
+		return t.UnixMicro(), nil
+	case parquetColumnBinary:
+		return string(raw), nil
+	default:
+		return string(raw), nil
+	}
+}
+
+func (p *Parquet) String() string {
+	jsonStr, _ := json.Marshal(p)
+	return string(jsonStr)
+}
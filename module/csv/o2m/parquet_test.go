@@ -0,0 +1,227 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package o2m
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"github.com/shopspring/decimal"
+	"github.com/xitongsys/parquet-go-source/local"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/reader"
+	"io"
+	"math/big"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// parquetTestDriver 内存数据行驱动，模拟 oracle 查询结果，所有字段以 []byte 返回，types 为字段数据库类型
+type parquetTestDriver struct {
+	columns []string
+	types   []string
+	rows    [][]driver.Value
+}
+
+func (d *parquetTestDriver) Open(string) (driver.Conn, error) { return &parquetTestConn{d: d}, nil }
+
+type parquetTestConn struct{ d *parquetTestDriver }
+
+func (c *parquetTestConn) Prepare(string) (driver.Stmt, error) { return &parquetTestStmt{d: c.d}, nil }
+func (c *parquetTestConn) Close() error                        { return nil }
+func (c *parquetTestConn) Begin() (driver.Tx, error)           { return nil, driver.ErrSkip }
+
+type parquetTestStmt struct{ d *parquetTestDriver }
+
+func (s *parquetTestStmt) Close() error                               { return nil }
+func (s *parquetTestStmt) NumInput() int                              { return 0 }
+func (s *parquetTestStmt) Exec([]driver.Value) (driver.Result, error) { return nil, driver.ErrSkip }
+func (s *parquetTestStmt) Query([]driver.Value) (driver.Rows, error) {
+	return &parquetTestRows{d: s.d}, nil
+}
+
+type parquetTestRows struct {
+	d   *parquetTestDriver
+	pos int
+}
+
+func (r *parquetTestRows) Columns() []string { return r.d.columns }
+func (r *parquetTestRows) Close() error      { return nil }
+func (r *parquetTestRows) ColumnTypeDatabaseTypeName(i int) string {
+	if i < len(r.d.types) {
+		return r.d.types[i]
+	}
+	return ""
+}
+func (r *parquetTestRows) Next(dest []driver.Value) error {
+	if r.pos >= len(r.d.rows) {
+		return io.EOF
+	}
+	copy(dest, r.d.rows[r.pos])
+	r.pos++
+	return nil
+}
+
+func TestParquetWriteFileRoundTrip(t *testing.T) {
+	columnsINFO := []map[string]string{
+		{"COLUMN_NAME": "ID", "DATA_TYPE": "NUMBER", "DATA_PRECISION": "10", "DATA_SCALE": "0"},
+		{"COLUMN_NAME": "AMOUNT", "DATA_TYPE": "NUMBER", "DATA_PRECISION": "38", "DATA_SCALE": "10"},
+		{"COLUMN_NAME": "RATIO", "DATA_TYPE": "NUMBER", "DATA_PRECISION": "38", "DATA_SCALE": "127"},
+		{"COLUMN_NAME": "SCORE", "DATA_TYPE": "BINARY_DOUBLE", "DATA_PRECISION": "", "DATA_SCALE": ""},
+		{"COLUMN_NAME": "CREATED", "DATA_TYPE": "DATE", "DATA_PRECISION": "", "DATA_SCALE": ""},
+		{"COLUMN_NAME": "DOC", "DATA_TYPE": "CLOB", "DATA_PRECISION": "", "DATA_SCALE": ""},
+		{"COLUMN_NAME": "PIC", "DATA_TYPE": "BLOB", "DATA_PRECISION": "", "DATA_SCALE": ""},
+	}
+	queryColumns := []string{"ID", "AMOUNT", "RATIO", "SCORE", "CREATED", "DOC", "PIC"}
+
+	sourceColumns, err := GenParquetColumns(columnsINFO, queryColumns)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	longText := strings.Repeat("长文本 lob ", 4096)
+	blob := []byte{0x00, 0x01, 0x7f, 0x80, 0xff}
+	d := &parquetTestDriver{
+		columns: queryColumns,
+		rows: [][]driver.Value{
+			{[]byte("1"), []byte("1234567890123456789012345678.1234567891"), []byte("3.14159265358979323846264338327950288"),
+				[]byte("0.5"), []byte("2022-01-02 03:04:05"), []byte(longText), blob},
+			{[]byte("2"), nil, nil, nil, nil, nil, nil},
+			{[]byte("3"), []byte("-0.0000000001"), []byte(""), []byte("-1.25"), []byte("1970-01-01 00:00:00"), []byte(""), nil},
+		},
+	}
+	sql.Register("parquet_round_trip", d)
+	db, err := sql.Open("parquet_round_trip", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	rows, err := db.Query("SELECT")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fileName := filepath.Join(t.TempDir(), "MARVIN", "T1", "MARVIN.T1.0.parquet")
+	p := NewParquetWriter("MARVIN", "T1", "SELECT", fileName, sourceColumns, rows)
+	if err = p.WriteFile(); err != nil {
+		t.Fatal(err)
+	}
+	if p.RowCounts != int64(len(d.rows)) {
+		t.Fatalf("row counts got %d, want %d", p.RowCounts, len(d.rows))
+	}
+
+	fr, err := local.NewLocalFileReader(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fr.Close()
+	pr, err := reader.NewParquetColumnReader(fr, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pr.ReadStop()
+
+	if pr.GetNumRows() != int64(len(d.rows)) {
+		t.Fatalf("parquet rows got %d, want %d", pr.GetNumRows(), len(d.rows))
+	}
+
+	// schema
+	schemas := pr.Footer.Schema[1:]
+	if len(schemas) != len(queryColumns) {
+		t.Fatalf("parquet schema columns got %d, want %d", len(schemas), len(queryColumns))
+	}
+	wantTypes := []parquet.Type{
+		parquet.Type_BYTE_ARRAY, parquet.Type_BYTE_ARRAY, parquet.Type_BYTE_ARRAY, parquet.Type_DOUBLE,
+		parquet.Type_INT64, parquet.Type_BYTE_ARRAY, parquet.Type_BYTE_ARRAY,
+	}
+	for i, se := range schemas {
+		if !strings.EqualFold(se.GetName(), queryColumns[i]) {
+			t.Fatalf("column [%d] name got %s, want %s", i, se.GetName(), queryColumns[i])
+		}
+		if se.GetType() != wantTypes[i] {
+			t.Fatalf("column [%s] type got %v, want %v", queryColumns[i], se.GetType(), wantTypes[i])
+		}
+		if se.GetRepetitionType() != parquet.FieldRepetitionType_OPTIONAL {
+			t.Fatalf("column [%s] repetition type got %v, want OPTIONAL", queryColumns[i], se.GetRepetitionType())
+		}
+	}
+	if schemas[1].GetConvertedType() != parquet.ConvertedType_DECIMAL || schemas[1].GetPrecision() != 38 || schemas[1].GetScale() != 10 {
+		t.Fatalf("column [AMOUNT] decimal got %v(%d,%d), want DECIMAL(38,10)", schemas[1].GetConvertedType(), schemas[1].GetPrecision(), schemas[1].GetScale())
+	}
+	if schemas[2].GetConvertedType() != parquet.ConvertedType_UTF8 {
+		t.Fatalf("column [RATIO] unknown precision number got %v, want UTF8 string", schemas[2].GetConvertedType())
+	}
+	if schemas[6].IsSetConvertedType() {
+		t.Fatalf("column [PIC] binary got converted type %v, want none", schemas[6].GetConvertedType())
+	}
+
+	readColumn := func(i int) []interface{} {
+		values, _, _, err := pr.ReadColumnByIndex(int64(i), int64(len(d.rows)))
+		if err != nil {
+			t.Fatalf("read column [%s] failed: %v", queryColumns[i], err)
+		}
+		if len(values) != len(d.rows) {
+			t.Fatalf("read column [%s] values got %d, want %d", queryColumns[i], len(values), len(d.rows))
+		}
+		return values
+	}
+
+	// decimal 以大端补码存储去除小数位后的整数值
+	decimalValue := func(v interface{}, scale int32) interface{} {
+		if v == nil {
+			return nil
+		}
+		b := []byte(v.(string))
+		n := new(big.Int).SetBytes(b)
+		if len(b) > 0 && b[0]&0x80 != 0 {
+			n.Sub(n, new(big.Int).Lsh(big.NewInt(1), uint(len(b)*8)))
+		}
+		return decimal.NewFromBigInt(n, -scale).String()
+	}
+
+	ids := readColumn(0)
+	for i, want := range []string{"1", "2", "3"} {
+		if got := decimalValue(ids[i], 0); got != want {
+			t.Fatalf("row [%d] column [ID] got %s, want %s", i, got, want)
+		}
+	}
+
+	amounts := readColumn(1)
+	wantAmounts := []interface{}{"1234567890123456789012345678.1234567891", nil, "-0.0000000001"}
+	for i, want := range wantAmounts {
+		if got := decimalValue(amounts[i], schemas[1].GetScale()); got != want {
+			t.Fatalf("row [%d] column [AMOUNT] got %v, want %v", i, got, want)
+		}
+	}
+
+	// 空字符串与 NULL 统一 NULL
+	wantColumns := map[int][]interface{}{
+		2: {"3.14159265358979323846264338327950288", nil, nil},
+		3: {0.5, nil, -1.25},
+		4: {time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC).UnixMicro(), nil, int64(0)},
+		5: {longText, nil, nil},
+		6: {string(blob), nil, nil},
+	}
+	for i := 2; i < len(queryColumns); i++ {
+		values := readColumn(i)
+		for j, want := range wantColumns[i] {
+			if values[j] != want {
+				t.Fatalf("row [%d] column [%s] got %v, want %v", j, queryColumns[i], values[j], want)
+			}
+		}
+	}
+}
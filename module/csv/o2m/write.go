@@ -18,6 +18,8 @@ package o2m
 import (
 	"bufio"
 	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"github.com/shopspring/decimal"
@@ -25,9 +27,11 @@ import (
 	"github.com/wentaojin/transferdb/common"
 	"github.com/wentaojin/transferdb/config"
 	"go.uber.org/zap"
+	"golang.org/x/text/encoding"
 	"io"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 )
 
@@ -38,8 +42,11 @@ type File struct {
 	SourceColumns    []string `json:"source_columns"`
 	QuerySQL         string   `json:"query_sql"`
 	FileName         string   `json:"file_name"`
+	RowCounts        int64    `json:"row_counts"`
+	ReplacedChars    int64    `json:"replaced_chars"`
 	config.CSVConfig `json:"-"`
-	Rows             *sql.Rows `json:"-"`
+	Rows             *sql.Rows         `json:"-"`
+	charsetEncoding  encoding.Encoding `json:"-"`
 }
 
 func NewWriter(sourceSchema, sourceTable, sourceCharSet, querySQL, fileName string, sourceColumns []string, csvConfig config.CSVConfig, rows *sql.Rows) *File {
@@ -60,11 +67,7 @@ func (f *File) WriteFile() error {
 	}
 
 	// 文件目录判断
-	if err := common.PathExist(
-		filepath.Join(
-			f.CSVConfig.OutputDir,
-			strings.ToUpper(f.SourceSchema),
-			strings.ToUpper(f.SourceTable))); err != nil {
+	if err := common.PathExist(filepath.Dir(f.FileName)); err != nil {
 		return err
 	}
 
@@ -87,30 +90,58 @@ func (f *File) adjustCSVConfig() error {
 	if f.Terminator == "" {
 		f.Terminator = "\r\n"
 	}
-	if f.Charset == "" {
-		if val, ok := common.OracleDBCSVCharacterSetMap[strings.ToUpper(f.SourceCharset)]; ok {
-			f.Charset = val
-		} else {
-			return fmt.Errorf("oracle db csv characterset [%v] isn't support", f.SourceCharset)
-		}
+	if f.NullValue == "" {
+		f.NullValue = common.CSVNullValueDefault
 	}
-	isSupport := false
-	if f.Charset != "" {
-		switch strings.ToUpper(f.Charset) {
-		case common.UTF8CharacterSetCSV:
-			isSupport = true
-		case common.GBKCharacterSetCSV:
-			isSupport = true
-		default:
-			isSupport = false
-		}
+	switch strings.ToUpper(f.BinaryEncoding) {
+	case "", common.CSVBinaryEncodingHex, common.CSVBinaryEncodingBase64:
+		f.BinaryEncoding = strings.ToUpper(f.BinaryEncoding)
+	default:
+		return fmt.Errorf("csv binary encoding [%s] isn't support, only support hex/base64", f.BinaryEncoding)
+	}
+	charset, err := CSVOutputCharset(f.Charset, f.SourceCharset)
+	if err != nil {
+		return err
 	}
-	if !isSupport {
+	f.Charset = charset
+	enc, err := common.CSVCharsetEncoding(f.Charset)
+	if err != nil {
 		return fmt.Errorf("target db character is not support: [%s]", f.Charset)
 	}
+	f.charsetEncoding = enc
+
+	switch strings.ToUpper(f.CharsetErrorPolicy) {
+	case "":
+		f.CharsetErrorPolicy = common.CSVCharsetErrorPolicyFail
+	case common.CSVCharsetErrorPolicyFail, common.CSVCharsetErrorPolicyReplace, common.CSVCharsetErrorPolicySkip:
+		f.CharsetErrorPolicy = strings.ToUpper(f.CharsetErrorPolicy)
+	default:
+		return fmt.Errorf("csv charset error policy [%s] isn't support, only support fail/replace/skip", f.CharsetErrorPolicy)
+	}
 	return nil
 }
 
+// CSVOutputCharset csv 输出字符集，未配置以 oracle 数据库字符集对应字符集为准
+func CSVOutputCharset(charset, sourceCharset string) (string, error) {
+	if charset != "" {
+		return charset, nil
+	}
+	if val, ok := common.OracleDBCSVCharacterSetMap[strings.ToUpper(sourceCharset)]; ok {
+		return val, nil
+	}
+	return "", fmt.Errorf("oracle db csv characterset [%v] isn't support", sourceCharset)
+}
+
+// encodeCharset 转换输出字符集，并累计替换/丢弃字符数
+func (f *File) encodeCharset(columnName string, raw []byte) ([]byte, error) {
+	by, replaced, err := common.Utf8ToCharset(raw, f.charsetEncoding, f.CharsetErrorPolicy)
+	if err != nil {
+		return nil, fmt.Errorf("column [%s] convert charset [%s] failed: %v", columnName, f.Charset, err)
+	}
+	f.ReplacedChars = f.ReplacedChars + replaced
+	return by, nil
+}
+
 func (f *File) write(w io.Writer) error {
 	writer := bufio.NewWriter(w)
 	if f.Header {
@@ -123,8 +154,9 @@ func (f *File) write(w io.Writer) error {
 	var rowCount int
 
 	var (
-		columnNames []string
-		columnTypes []string
+		columnNames   []string
+		columnTypes   []string
+		columnDBTypes []string
 	)
 	colTypes, err := f.Rows.ColumnTypes()
 	if err != nil {
@@ -135,6 +167,7 @@ func (f *File) write(w io.Writer) error {
 		columnNames = append(columnNames, ct.Name())
 		// 数据库字段类型 DatabaseTypeName() 映射 go 类型 ScanType()
 		columnTypes = append(columnTypes, ct.ScanType().String())
+		columnDBTypes = append(columnDBTypes, strings.ToUpper(ct.DatabaseTypeName()))
 	}
 
 	// 数据 SCAN
@@ -162,10 +195,25 @@ func (f *File) write(w io.Writer) error {
 			// Mysql 空字符串与 NULL 非一类，NULL 是 NULL，空字符串是空字符串（is null 只查询 NULL 值，空字符串查询只查询到空字符串值）
 			// 按照 Oracle 特性来，转换同步统一转换成 NULL 即可，但需要注意业务逻辑中空字符串得写入，需要变更
 			// Oracle/Mysql 对于 'NULL' 统一字符 NULL 处理，查询出来转成 NULL,所以需要判断处理
+			// NULL 输出标识可配置，keep-empty-string 开启时非 NULL 空值按空字符串输出
 			if raw == nil {
-				results = append(results, "NULL")
+				results = append(results, f.NullValue)
 			} else if string(raw) == "" {
-				results = append(results, "NULL")
+				if f.KeepEmptyString {
+					results = append(results, common.StringsBuilder(f.Delimiter, f.Delimiter))
+				} else {
+					results = append(results, f.NullValue)
+				}
+			} else if f.LOBSpillThreshold > 0 && len(raw) > f.LOBSpillThreshold && isCSVLOBColumn(columnDBTypes[i]) {
+				// LOB 超过阈值溢出写入单独文件，字段记录相对路径
+				lobFile, err := f.spillLOB(columnNames[i], columnDBTypes[i], rowCount, raw)
+				if err != nil {
+					return err
+				}
+				results = append(results, common.StringsBuilder(f.Delimiter, lobFile, f.Delimiter))
+			} else if f.BinaryEncoding != "" && isCSVBinaryColumn(columnDBTypes[i]) {
+				// 二进制字段编码输出，编码结果不含特殊字符无需转义
+				results = append(results, common.StringsBuilder(f.Delimiter, encodeCSVBinary(f.BinaryEncoding, raw), f.Delimiter))
 			} else {
 				switch columnTypes[i] {
 				case "int64":
@@ -210,14 +258,14 @@ func (f *File) write(w io.Writer) error {
 						bs string
 					)
 					// 处理字符集、特殊字符转义、字符串引用定界符
-					if strings.ToUpper(f.Charset) == common.GBKCharacterSetCSV {
-						gbkBytes, err := common.Utf8ToGbk(raw)
+					// 二进制字段不做字符集转换
+					if isCSVBinaryColumn(columnDBTypes[i]) {
+						by = raw
+					} else {
+						by, err = f.encodeCharset(columnNames[i], raw)
 						if err != nil {
 							return err
 						}
-						by = gbkBytes
-					} else {
-						by = raw
 					}
 
 					if f.EscapeBackslash {
@@ -254,16 +302,68 @@ func (f *File) write(w io.Writer) error {
 		return err
 	}
 
+	f.RowCounts = int64(rowCount)
+
 	zap.L().Info("oracle schema table rowid data rows",
 		zap.String("schema", f.SourceSchema),
 		zap.String("table", f.SourceTable),
 		zap.Int("rows", rowCount),
+		zap.Int64("charset replaced chars", f.ReplacedChars),
 		zap.String("query sql", f.QuerySQL),
 		zap.String("detail", f.String()))
 
 	return nil
 }
 
+// spillLOB 写入 LOB 溢出文件，返回相对 CSV 文件所在目录路径
+// 溢出文件：${csv 文件名}_lob/${字段名}_${行号}.lob，CLOB/NCLOB 按输出字符集转换，BLOB 原样写入
+func (f *File) spillLOB(columnName, columnDBType string, rowCount int, raw []byte) (string, error) {
+	lobDir := common.StringsBuilder(strings.TrimSuffix(filepath.Base(f.FileName), filepath.Ext(f.FileName)), common.CSVLOBSpillDirSuffix)
+	if err := common.PathExist(filepath.Join(filepath.Dir(f.FileName), lobDir)); err != nil {
+		return "", err
+	}
+
+	by := raw
+	if !isCSVBinaryColumn(columnDBType) {
+		var err error
+		by, err = f.encodeCharset(columnName, raw)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	lobFile := filepath.Join(lobDir, common.StringsBuilder(columnName, "_", strconv.Itoa(rowCount), common.CSVLOBSpillFileSuffix))
+	if err := os.WriteFile(filepath.Join(filepath.Dir(f.FileName), lobFile), by, 0666); err != nil {
+		return "", fmt.Errorf("column [%s] row [%d] spill lob file failed: %v", columnName, rowCount, err)
+	}
+	return lobFile, nil
+}
+
+func isCSVLOBColumn(columnDBType string) bool {
+	switch columnDBType {
+	case "CLOB", "NCLOB", "BLOB":
+		return true
+	default:
+		return false
+	}
+}
+
+func isCSVBinaryColumn(columnDBType string) bool {
+	switch columnDBType {
+	case "RAW", "LONG RAW", "BLOB":
+		return true
+	default:
+		return false
+	}
+}
+
+func encodeCSVBinary(encoding string, raw []byte) string {
+	if encoding == common.CSVBinaryEncodingBase64 {
+		return base64.StdEncoding.EncodeToString(raw)
+	}
+	return hex.EncodeToString(raw)
+}
+
 func (f *File) String() string {
 	jsonStr, _ := json.Marshal(f)
 	return string(jsonStr)
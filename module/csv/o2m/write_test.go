@@ -0,0 +1,202 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package o2m
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"github.com/wentaojin/transferdb/config"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// queryTestRows 内存驱动构造查询结果，driverName 需唯一
+func queryTestRows(t *testing.T, driverName string, d *parquetTestDriver) *sql.Rows {
+	sql.Register(driverName, d)
+	db, err := sql.Open(driverName, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	rows, err := db.Query("SELECT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rows
+}
+
+func TestFileWriteNullValue(t *testing.T) {
+	cases := []struct {
+		name            string
+		nullValue       string
+		keepEmptyString bool
+		want            string
+	}{
+		{name: "default null marker", want: "'1',NULL,NULL\r\n"},
+		{name: "custom null marker", nullValue: `\N`, want: "'1',\\N,\\N\r\n"},
+		{name: "keep empty string", nullValue: `\N`, keepEmptyString: true, want: "'1',\\N,''\r\n"},
+		{name: "keep empty string with default marker", keepEmptyString: true, want: "'1',NULL,''\r\n"},
+	}
+	for i, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			rows := queryTestRows(t, "csv_null_value_"+string(rune('a'+i)), &parquetTestDriver{
+				columns: []string{"ID", "NAME", "MEMO"},
+				types:   []string{"VARCHAR2", "VARCHAR2", "VARCHAR2"},
+				rows:    [][]driver.Value{{[]byte("1"), nil, []byte("")}},
+			})
+			fileName := filepath.Join(t.TempDir(), "MARVIN.T1.0.csv")
+			f := NewWriter("MARVIN", "T1", "AL32UTF8", "SELECT", fileName, []string{"ID", "NAME", "MEMO"}, config.CSVConfig{
+				Delimiter:       "'",
+				NullValue:       c.nullValue,
+				KeepEmptyString: c.keepEmptyString,
+			}, rows)
+			if err := f.WriteFile(); err != nil {
+				t.Fatal(err)
+			}
+			got, err := os.ReadFile(fileName)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != c.want {
+				t.Fatalf("csv content got %q, want %q", got, c.want)
+			}
+		})
+	}
+}
+
+func TestFileWriteLOBSpill(t *testing.T) {
+	longText := strings.Repeat("lob", 10)
+	cases := []struct {
+		name      string
+		threshold int
+		want      string
+		lobFiles  map[string]string
+	}{
+		{name: "spill disabled", threshold: 0, want: "'1','" + longText + "','abc'\r\n"},
+		{
+			name:      "spill over threshold",
+			threshold: 10,
+			want:      "'1','MARVIN.T1.0_lob/DOC_1.lob','abc'\r\n",
+			lobFiles:  map[string]string{filepath.Join("MARVIN.T1.0_lob", "DOC_1.lob"): longText},
+		},
+		{
+			name:      "all lob columns over threshold",
+			threshold: 2,
+			want:      "'1','MARVIN.T1.0_lob/DOC_1.lob','MARVIN.T1.0_lob/NOTE_1.lob'\r\n",
+			lobFiles: map[string]string{
+				filepath.Join("MARVIN.T1.0_lob", "DOC_1.lob"):  longText,
+				filepath.Join("MARVIN.T1.0_lob", "NOTE_1.lob"): "abc",
+			},
+		},
+	}
+	for i, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			rows := queryTestRows(t, "csv_lob_spill_"+string(rune('a'+i)), &parquetTestDriver{
+				columns: []string{"ID", "DOC", "NOTE"},
+				types:   []string{"VARCHAR2", "CLOB", "CLOB"},
+				rows:    [][]driver.Value{{[]byte("1"), []byte(longText), []byte("abc")}},
+			})
+			dir := t.TempDir()
+			fileName := filepath.Join(dir, "MARVIN.T1.0.csv")
+			f := NewWriter("MARVIN", "T1", "AL32UTF8", "SELECT", fileName, []string{"ID", "DOC", "NOTE"}, config.CSVConfig{
+				Delimiter:         "'",
+				LOBSpillThreshold: c.threshold,
+			}, rows)
+			if err := f.WriteFile(); err != nil {
+				t.Fatal(err)
+			}
+			got, err := os.ReadFile(fileName)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != c.want {
+				t.Fatalf("csv content got %q, want %q", got, c.want)
+			}
+			for lobFile, content := range c.lobFiles {
+				by, err := os.ReadFile(filepath.Join(dir, lobFile))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(by) != content {
+					t.Fatalf("lob file [%s] content got %q, want %q", lobFile, by, content)
+				}
+			}
+			if len(c.lobFiles) == 0 {
+				if _, err := os.Stat(filepath.Join(dir, "MARVIN.T1.0_lob")); !os.IsNotExist(err) {
+					t.Fatalf("lob spill dir shouldn't exist, stat error: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestCSVOutputCharset(t *testing.T) {
+	cases := []struct {
+		charset       string
+		sourceCharset string
+		want          string
+		wantErr       bool
+	}{
+		{charset: "GBK", sourceCharset: "AL32UTF8", want: "GBK"},
+		{sourceCharset: "AL32UTF8", want: "UTF8"},
+		{sourceCharset: "zhs16gbk", want: "GBK"},
+		{sourceCharset: "ZHS32GB18030", want: "GB18030"},
+		{sourceCharset: "WE8ISO8859P1", want: "LATIN1"},
+		{sourceCharset: "JA16SJISTILDE", want: "SJIS"},
+		{sourceCharset: "KO16MSWIN949", want: "EUCKR"},
+		{sourceCharset: "ZHT16MSWIN950", want: "BIG5"},
+		// AL16UTF16 仅用于 NCHAR 字符集，不可能是数据库字符集
+		{sourceCharset: "AL16UTF16", wantErr: true},
+		{sourceCharset: "US7ASCII", wantErr: true},
+	}
+	for _, c := range cases {
+		got, err := CSVOutputCharset(c.charset, c.sourceCharset)
+		if (err != nil) != c.wantErr {
+			t.Fatalf("CSVOutputCharset(%q, %q) error got %v, want error %v", c.charset, c.sourceCharset, err, c.wantErr)
+		}
+		if got != c.want {
+			t.Fatalf("CSVOutputCharset(%q, %q) got %q, want %q", c.charset, c.sourceCharset, got, c.want)
+		}
+	}
+}
+
+func TestFileAdjustCSVConfigCharsetPolicy(t *testing.T) {
+	cases := []struct {
+		policy  string
+		want    string
+		wantErr bool
+	}{
+		{policy: "", want: "FAIL"},
+		{policy: "replace", want: "REPLACE"},
+		{policy: "Skip", want: "SKIP"},
+		{policy: "ignore", wantErr: true},
+	}
+	for _, c := range cases {
+		f := NewWriter("MARVIN", "T1", "ZHS16GBK", "SELECT", "", nil, config.CSVConfig{CharsetErrorPolicy: c.policy}, nil)
+		err := f.adjustCSVConfig()
+		if (err != nil) != c.wantErr {
+			t.Fatalf("charset error policy [%s] error got %v, want error %v", c.policy, err, c.wantErr)
+		}
+		if c.wantErr {
+			continue
+		}
+		if f.CharsetErrorPolicy != c.want || f.Charset != "GBK" || f.charsetEncoding == nil {
+			t.Fatalf("charset error policy [%s] got policy [%s] charset [%s], want policy [%s] charset [GBK]", c.policy, f.CharsetErrorPolicy, f.Charset, c.want)
+		}
+	}
+}
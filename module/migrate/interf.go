@@ -34,3 +34,7 @@ type Fuller interface {
 type Increr interface {
 	Incr() error
 }
+
+type Sequencer interface {
+	Sequence() error
+}
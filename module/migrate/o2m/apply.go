@@ -54,7 +54,7 @@ type IncrResult struct {
 }
 
 // 应用当前日志文件中所有记录
-func applyOracleIncrRecord(metaDB *meta.Meta, mysqlDB *mysql.MySQL, cfg *config.Config, logminerMap map[string][]logminer) error {
+func applyOracleIncrRecord(metaDB *meta.Meta, mysqlDB *mysql.MySQL, cfg *config.Config, logminerMap map[string][]logminer, columnNameRule map[string]map[string]string) error {
 	g := &errgroup.Group{}
 	g.SetLimit(cfg.AllConfig.ApplyThreads)
 
@@ -89,6 +89,7 @@ func applyOracleIncrRecord(metaDB *meta.Meta, mysqlDB *mysql.MySQL, cfg *config.
 						sourceTable,
 						metaDB,
 						mysql,
+						columnNameRule[common.StringUPPER(sourceTable)],
 						rowsResult, taskQueue); err != nil {
 						return
 					}
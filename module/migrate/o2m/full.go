@@ -337,6 +337,13 @@ func (r *Migrate) Full() error {
 		zap.Int("table failed", len(failedTotals)),
 		zap.String("log detail", "if exist table failed, please see meta table [wait/full_sync_meta]"),
 		zap.String("cost", time.Now().Sub(startTime).String()))
+
+	// 全量同步完成，推进下游自增值以及序列值
+	if r.Cfg.FullConfig.SyncSequence && len(failedTotals) == 0 {
+		if err = r.Sequence(); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -388,6 +395,17 @@ func (r *Migrate) FullPartSyncTable(fullPartTables []string) error {
 
 			waitFullMetas = append(waitFullMetas, failedFullMetas...)
 
+			// 字段名映射规则，写入端 INSERT 字段列表使用目标端字段名
+			columnNameRule, err := meta.NewColumnNameRuleModel(r.MetaDB).DetailTableColumnNameRule(r.Ctx, &meta.ColumnNameRule{
+				DBTypeS:     r.Cfg.DBTypeS,
+				DBTypeT:     r.Cfg.DBTypeT,
+				SchemaNameS: r.Cfg.OracleConfig.SchemaName,
+				TableNameS:  common.StringUPPER(t),
+			})
+			if err != nil {
+				return err
+			}
+
 			g1 := &errgroup.Group{}
 			g1.SetLimit(r.Cfg.FullConfig.SQLThreads)
 			for _, fullMeta := range waitFullMetas {
@@ -415,6 +433,8 @@ func (r *Migrate) FullPartSyncTable(fullPartTables []string) error {
 
 						return nil
 					}
+					columnFields = GenTargetColumnFields(columnFields, columnNameRule)
+
 					err = ITranslator(NewChunk(r.Ctx, m, r.Oracle, r.Mysql, r.MetaDB, columnFields, batchResults, r.Cfg.FullConfig.ApplyThreads, r.Cfg.AppConfig.InsertBatchSize, true))
 					if err != nil {
 						// record error, skip error
@@ -612,7 +632,7 @@ func (r *Migrate) InitWaitSyncTableRowID(csvWaitTables []string, oracleCollation
 				targetTableName = common.StringUPPER(t)
 			}
 
-			sourceColumnInfo, err := r.AdjustTableSelectColumn(t, oracleCollation)
+			sourceColumnInfo, err := r.AdjustTableSelectColumn(t, targetTableName, oracleCollation)
 			if err != nil {
 				return err
 			}
@@ -817,7 +837,7 @@ func (r *Migrate) GetTableNameRule() (map[string]string, error) {
 	return tableNameRuleMap, nil
 }
 
-func (r *Migrate) AdjustTableSelectColumn(sourceTable string, oracleCollation bool) (string, error) {
+func (r *Migrate) AdjustTableSelectColumn(sourceTable, targetTable string, oracleCollation bool) (string, error) {
 	// Date/Timestamp 字段类型格式化
 	// Interval Year/Day 数据字符 TO_CHAR 格式化
 	columnsINFO, err := r.Oracle.GetOracleSchemaTableColumn(r.Cfg.OracleConfig.SchemaName, sourceTable, oracleCollation)
@@ -825,9 +845,52 @@ func (r *Migrate) AdjustTableSelectColumn(sourceTable string, oracleCollation bo
 		return "", err
 	}
 
+	// 字段名映射规则，虚拟列按目标端字段名判断是否 generated column
+	columnNameRule, err := meta.NewColumnNameRuleModel(r.MetaDB).DetailTableColumnNameRule(r.Ctx, &meta.ColumnNameRule{
+		DBTypeS:     r.Cfg.DBTypeS,
+		DBTypeT:     r.Cfg.DBTypeT,
+		SchemaNameS: r.Cfg.OracleConfig.SchemaName,
+		TableNameS:  sourceTable,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	// 虚拟列表达式改写为下游 generated column 由下游计算，不参与数据抽取以及写入
+	// 表达式无法改写下游按普通字段创建，按普通字段抽取数据写入
+	var generatedColumns map[string]struct{}
+	for _, rowCol := range columnsINFO {
+		if !strings.EqualFold(rowCol["VIRTUAL_COLUMN"], "YES") {
+			continue
+		}
+		isExist, columns, err := r.Mysql.GetMySQLTableGeneratedColumn(r.Cfg.MySQLConfig.SchemaName, targetTable)
+		if err != nil {
+			return "", err
+		}
+		if !isExist {
+			return "", fmt.Errorf("oracle schema [%s] table [%s] virtual column [%s] needs target table [%s.%s] to determine whether generated column, please create target table first",
+				r.Cfg.OracleConfig.SchemaName, sourceTable, rowCol["COLUMN_NAME"], r.Cfg.MySQLConfig.SchemaName, targetTable)
+		}
+		generatedColumns = columns
+		break
+	}
+
 	var columnNames []string
 
 	for _, rowCol := range columnsINFO {
+		if strings.EqualFold(rowCol["VIRTUAL_COLUMN"], "YES") {
+			columnNameT := rowCol["COLUMN_NAME"]
+			if val, ok := columnNameRule[common.StringUPPER(rowCol["COLUMN_NAME"])]; ok {
+				columnNameT = val
+			}
+			if _, ok := generatedColumns[common.StringUPPER(columnNameT)]; ok {
+				continue
+			}
+			zap.L().Warn("oracle virtual column isn't generated column in target table, extract as normal column",
+				zap.String("schema", r.Cfg.OracleConfig.SchemaName),
+				zap.String("table", sourceTable),
+				zap.String("column", rowCol["COLUMN_NAME"]))
+		}
 		switch strings.ToUpper(rowCol["DATA_TYPE"]) {
 		// 数字
 		case "NUMBER":
@@ -868,7 +931,6 @@ func (r *Migrate) AdjustTableSelectColumn(sourceTable string, oracleCollation bo
 				columnNames = append(columnNames, rowCol["COLUMN_NAME"])
 			}
 		}
-
 	}
 
 	return strings.Join(columnNames, ","), nil
@@ -232,6 +232,16 @@ func (r *Migrate) syncTableIncrRecord() error {
 		return err
 	}
 
+	// 获取自定义字段名规则
+	columnNameRule, err := meta.NewColumnNameRuleModel(r.MetaDB).DetailSchemaColumnNameRule(r.Ctx, &meta.ColumnNameRule{
+		DBTypeS:     r.Cfg.DBTypeS,
+		DBTypeT:     r.Cfg.DBTypeT,
+		SchemaNameS: r.Cfg.OracleConfig.SchemaName,
+	})
+	if err != nil {
+		return err
+	}
+
 	// 获取增量所需得日志文件
 	logFiles, err := r.getTableIncrRecordLogfile()
 	if err != nil {
@@ -273,6 +283,15 @@ func (r *Migrate) syncTableIncrRecord() error {
 			return fmt.Errorf("mysql increment mete table [incr_sync_meta] can't null")
 		}
 
+		// 快照一致性校验暂停应用，日志文件应用间隙确认暂停，待数据校验获取时间点后恢复
+		paused, err := r.fenceIncrApply(incrSyncMetas)
+		if err != nil {
+			return err
+		}
+		if paused {
+			return nil
+		}
+
 		var (
 			transferTableMetaMap map[string]uint64
 			syncSourceTables     []string
@@ -371,7 +390,7 @@ func (r *Migrate) syncTableIncrRecord() error {
 
 				if len(logminerContentMap) > 0 {
 					// 数据应用
-					if err := applyOracleIncrRecord(r.MetaDB, r.Mysql, r.Cfg, logminerContentMap); err != nil {
+					if err := applyOracleIncrRecord(r.MetaDB, r.Mysql, r.Cfg, logminerContentMap, columnNameRule); err != nil {
 						return err
 					}
 					if logFileStartSCN == currentRedoLogFirstChange && log["LOG_FILE"] == currentRedoLogFileName {
@@ -418,7 +437,7 @@ func (r *Migrate) syncTableIncrRecord() error {
 			}
 			if len(logminerContentMap) > 0 {
 				// 数据应用
-				if err := applyOracleIncrRecord(r.MetaDB, r.Mysql, r.Cfg, logminerContentMap); err != nil {
+				if err := applyOracleIncrRecord(r.MetaDB, r.Mysql, r.Cfg, logminerContentMap, columnNameRule); err != nil {
 					return err
 				}
 				// 当前所有日志文件内容应用完毕，直接更新 GLOBAL_SCN 至日志文件结束 SCN
@@ -525,3 +544,47 @@ func (r *Migrate) getTableIncrRecordLogfile() ([]map[string]string, error) {
 	}
 	return logFiles, nil
 }
+
+// fenceIncrApply 判断增量同步是否被快照一致性校验暂停，存在暂停请求确认暂停，返回是否暂停应用
+// 暂停标志超过租约到期时间（数据校验异常退出遗留）清空标志，继续应用
+func (r *Migrate) fenceIncrApply(incrSyncMetas []meta.IncrSyncMeta) (bool, error) {
+	var (
+		fenced bool
+		stale  bool
+	)
+	for _, m := range incrSyncMetas {
+		if m.SyncFence != "" {
+			fenced = true
+			if m.SyncFenceExpire == nil || time.Now().After(*m.SyncFenceExpire) {
+				stale = true
+			}
+		}
+	}
+	if !fenced {
+		return false, nil
+	}
+
+	incrMeta := &meta.IncrSyncMeta{
+		DBTypeS:     r.Cfg.DBTypeS,
+		DBTypeT:     r.Cfg.DBTypeT,
+		SchemaNameS: r.Cfg.OracleConfig.SchemaName,
+	}
+	if stale {
+		if err := meta.NewIncrSyncMetaModel(r.MetaDB).UpdateIncrSyncMetaFenceBySchema(r.Ctx, incrMeta, "", ""); err != nil {
+			return false, err
+		}
+		zap.L().Warn("increment sync apply fence lease expired, clear fence and resume apply",
+			zap.String("schema", r.Cfg.OracleConfig.SchemaName),
+			zap.String("lease", common.MigrateSyncFenceLease.String()))
+		return false, nil
+	}
+
+	err := meta.NewIncrSyncMetaModel(r.MetaDB).UpdateIncrSyncMetaFenceBySchema(r.Ctx, incrMeta, common.MigrateSyncFenceRequest, common.MigrateSyncFencePaused)
+	if err != nil {
+		return false, err
+	}
+	zap.L().Warn("increment sync apply paused by compare snapshot fence",
+		zap.String("schema", r.Cfg.OracleConfig.SchemaName),
+		zap.String("tips", fmt.Sprintf("if compare task exited abnormally, fence will be cleared after lease [%s] expired", common.MigrateSyncFenceLease.String())))
+	return true, nil
+}
@@ -0,0 +1,149 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package o2m
+
+import (
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"go.uber.org/zap"
+	"strings"
+	"time"
+)
+
+// Sequence 推进下游自增值以及 tidb 序列值超过上游 oracle LAST_NUMBER
+// 适用于全量同步完成后以及增量切换 cutover 前重新同步
+func (r *Migrate) Sequence() error {
+	startTime := time.Now()
+	zap.L().Info("source schema sequence sync start",
+		zap.String("schema", r.Cfg.OracleConfig.SchemaName))
+
+	oracleDBVersion, err := r.Oracle.GetOracleDBVersion()
+	if err != nil {
+		return err
+	}
+
+	exporters, err := filterCFGTable(r.Cfg, r.Oracle)
+	if err != nil {
+		return err
+	}
+	tableNameRule, err := r.GetTableNameRule()
+	if err != nil {
+		return err
+	}
+
+	var failedItems []string
+
+	// identity column 推进表自增值
+	if common.VersionOrdinal(oracleDBVersion) >= common.VersionOrdinal(common.OracleIdentityColumnDBVersion) &&
+		!strings.EqualFold(r.Cfg.ReverseConfig.IdentityPolicy, common.ReverseIdentityPolicyNone) {
+		identityColumns, err := r.Oracle.GetOracleSchemaIdentityColumn(r.Cfg.OracleConfig.SchemaName)
+		if err != nil {
+			return err
+		}
+		autoRandom := strings.EqualFold(r.Cfg.ReverseConfig.IdentityPolicy, common.ReverseIdentityPolicyAutoRandom) &&
+			strings.EqualFold(r.Cfg.MySQLConfig.DBType, common.DatabaseTypeTiDB)
+
+		for _, col := range identityColumns {
+			if !common.IsContainString(exporters, col["TABLE_NAME"]) {
+				continue
+			}
+			if strings.HasPrefix(col["INCREMENT_BY"], "-") {
+				zap.L().Warn("skip descending identity column sequence sync",
+					zap.String("schema", r.Cfg.OracleConfig.SchemaName),
+					zap.String("table", col["TABLE_NAME"]),
+					zap.String("column", col["COLUMN_NAME"]),
+					zap.String("increment by", col["INCREMENT_BY"]))
+				continue
+			}
+			targetTable := common.StringUPPER(col["TABLE_NAME"])
+			if val, ok := tableNameRule[common.StringUPPER(col["TABLE_NAME"])]; ok {
+				targetTable = val
+			}
+
+			err = r.Mysql.RebaseMySQLTableAutoIncrement(r.Cfg.MySQLConfig.SchemaName, targetTable, col["LAST_NUMBER"], autoRandom)
+			// auto_random 不满足条件时 reverse 阶段回退 auto_increment
+			if err != nil && autoRandom {
+				zap.L().Warn("rebase tidb table auto_random_base failed, fallback auto_increment",
+					zap.String("schema", r.Cfg.MySQLConfig.SchemaName),
+					zap.String("table", targetTable),
+					zap.Error(err))
+				err = r.Mysql.RebaseMySQLTableAutoIncrement(r.Cfg.MySQLConfig.SchemaName, targetTable, col["LAST_NUMBER"], false)
+			}
+			if err != nil {
+				zap.L().Error("sync identity column sequence failed",
+					zap.String("schema", r.Cfg.MySQLConfig.SchemaName),
+					zap.String("table", targetTable),
+					zap.Error(err))
+				failedItems = append(failedItems, fmt.Sprintf("table %s", targetTable))
+				continue
+			}
+			zap.L().Info("sync identity column sequence",
+				zap.String("schema", r.Cfg.MySQLConfig.SchemaName),
+				zap.String("table", targetTable),
+				zap.String("column", col["COLUMN_NAME"]),
+				zap.String("last number", col["LAST_NUMBER"]))
+		}
+	}
+
+	// tidb 序列推进
+	if strings.EqualFold(r.Cfg.MySQLConfig.DBType, common.DatabaseTypeTiDB) {
+		sequences, err := r.Oracle.GetOracleSchemaSequence(r.Cfg.OracleConfig.SchemaName)
+		if err != nil {
+			return err
+		}
+		targetSequences, err := r.Mysql.GetTiDBSchemaSequence(r.Cfg.MySQLConfig.SchemaName)
+		if err != nil {
+			return err
+		}
+		for _, seq := range sequences {
+			// 序列名称与 reverse 阶段超长标识符处理策略保持一致
+			seq["SEQUENCE_NAME"] = common.StringIdentifierPolicy(seq["SEQUENCE_NAME"], r.Cfg.ReverseConfig.IdentifierPolicy)
+			if !common.IsContainString(targetSequences, common.StringUPPER(seq["SEQUENCE_NAME"])) {
+				zap.L().Warn("skip sequence sync, tidb sequence isn't exist",
+					zap.String("schema", r.Cfg.MySQLConfig.SchemaName),
+					zap.String("sequence", seq["SEQUENCE_NAME"]))
+				continue
+			}
+			if strings.HasPrefix(seq["INCREMENT_BY"], "-") {
+				zap.L().Warn("skip descending sequence sync",
+					zap.String("schema", r.Cfg.MySQLConfig.SchemaName),
+					zap.String("sequence", seq["SEQUENCE_NAME"]),
+					zap.String("increment by", seq["INCREMENT_BY"]))
+				continue
+			}
+			if err = r.Mysql.SetTiDBSequenceValue(r.Cfg.MySQLConfig.SchemaName, seq["SEQUENCE_NAME"], seq["LAST_NUMBER"]); err != nil {
+				zap.L().Error("sync sequence failed",
+					zap.String("schema", r.Cfg.MySQLConfig.SchemaName),
+					zap.String("sequence", seq["SEQUENCE_NAME"]),
+					zap.Error(err))
+				failedItems = append(failedItems, fmt.Sprintf("sequence %s", seq["SEQUENCE_NAME"]))
+				continue
+			}
+			zap.L().Info("sync sequence",
+				zap.String("schema", r.Cfg.MySQLConfig.SchemaName),
+				zap.String("sequence", seq["SEQUENCE_NAME"]),
+				zap.String("last number", seq["LAST_NUMBER"]))
+		}
+	}
+
+	if len(failedItems) > 0 {
+		return fmt.Errorf("sync schema [%s] sequence failed: %v, detail see log", r.Cfg.MySQLConfig.SchemaName, failedItems)
+	}
+	zap.L().Info("source schema sequence sync finished",
+		zap.String("schema", r.Cfg.OracleConfig.SchemaName),
+		zap.String("cost", time.Now().Sub(startTime).String()))
+	return nil
+}
@@ -28,6 +28,7 @@ import (
 	"github.com/pingcap/parser"
 
 	"github.com/pingcap/parser/ast"
+	"github.com/pingcap/parser/model"
 	_ "github.com/pingcap/tidb/types/parser_driver"
 )
 
@@ -41,6 +42,31 @@ func parseSQL(sql string) (*ast.StmtNode, error) {
 	return &stmtNodes[0], nil
 }
 
+// renameColumn 按字段名映射规则改写语句字段名
+func renameColumn(rootNode *ast.StmtNode, columnNameRule map[string]string) {
+	if len(columnNameRule) == 0 {
+		return
+	}
+	(*rootNode).Accept(&columnRename{columnNameRule: columnNameRule})
+}
+
+type columnRename struct {
+	columnNameRule map[string]string
+}
+
+func (c *columnRename) Enter(in ast.Node) (ast.Node, bool) {
+	if node, ok := in.(*ast.ColumnName); ok {
+		if val, ok := c.columnNameRule[strings.ToUpper(node.Name.O)]; ok {
+			node.Name = model.NewCIStr(val)
+		}
+	}
+	return in, false
+}
+
+func (c *columnRename) Leave(in ast.Node) (ast.Node, bool) {
+	return in, true
+}
+
 func extractStmt(rootNode *ast.StmtNode) *Stmt {
 	v := &Stmt{}
 	(*rootNode).Accept(v)
@@ -24,6 +24,7 @@ import (
 	"github.com/wentaojin/transferdb/database/oracle"
 	"go.uber.org/zap"
 	"golang.org/x/sync/errgroup"
+	"strings"
 	"time"
 )
 
@@ -145,3 +146,16 @@ func (t *Chunk) ApplyTableRows() error {
 
 	return nil
 }
+
+// GenTargetColumnFields 查询字段按字段名映射规则转换目标端字段名，用于写入端 INSERT 字段列表
+// 源端查询不使用字段别名，避免 oracle 12.2 以下版本 30 字节标识符长度限制
+func GenTargetColumnFields(columnFields []string, columnNameRule map[string]string) []string {
+	if len(columnNameRule) == 0 {
+		return columnFields
+	}
+	var targetFields []string
+	for _, c := range columnFields {
+		targetFields = append(targetFields, common.StringsBuilder("`", common.StringColumnNameRule(strings.Trim(c, "`"), columnNameRule), "`"))
+	}
+	return targetFields
+}
@@ -145,7 +145,7 @@ func GenMySQLPrepareBindVarStmt(columns, bindVarBatch int) string {
 
 // Oracle SQL 转换
 // ORACLE 数据库同步需要开附加日志且表需要捕获字段列日志，Logminer 内容 UPDATE/DELETE/INSERT 语句会带所有字段信息
-func translateAndAddOracleIncrRecord(dbTypeS, dbTypeT, taskMode, sourceSchema, sourceTable string, metaDB *meta.Meta, mysql *mysql.MySQL, logminers []logminer, taskQueue chan IncrTask) error {
+func translateAndAddOracleIncrRecord(dbTypeS, dbTypeT, taskMode, sourceSchema, sourceTable string, metaDB *meta.Meta, mysql *mysql.MySQL, columnNameRule map[string]string, logminers []logminer, taskQueue chan IncrTask) error {
 
 	startTime := time.Now()
 	zap.L().Info("oracle table increment log apply start",
@@ -181,7 +181,7 @@ func translateAndAddOracleIncrRecord(dbTypeS, dbTypeT, taskMode, sourceSchema, s
 		// 比如：UPDATE MARVIN.MARVIN1 SET ID = 2 , NAME = 'marvin' WHERE ID = 2 AND NAME = 'pty'
 		// 比如: drop table marvin.marvin7
 		// 比如: truncate table marvin.marvin7
-		mysqlRedo, operationType, err := translateOracleToMySQLSQL(rows.SQLRedo, rows.SQLUndo, common.StringUPPER(rows.TargetSchema), common.StringUPPER(rows.TargetTable))
+		mysqlRedo, operationType, err := translateOracleToMySQLSQL(rows.SQLRedo, rows.SQLUndo, common.StringUPPER(rows.TargetSchema), common.StringUPPER(rows.TargetTable), columnNameRule)
 		if err != nil {
 			return err
 		}
@@ -228,7 +228,8 @@ func translateAndAddOracleIncrRecord(dbTypeS, dbTypeT, taskMode, sourceSchema, s
 // Oracle SQL 转换
 // 1、INSERT INTO / REPLACE INTO
 // 2、UPDATE / DELETE、REPLACE INTO
-func translateOracleToMySQLSQL(oracleSQLRedo, oracleSQLUndo, targetSchema, targetTable string) ([]string, string, error) {
+// 3、字段名按字段名映射规则转换
+func translateOracleToMySQLSQL(oracleSQLRedo, oracleSQLUndo, targetSchema, targetTable string, columnNameRule map[string]string) ([]string, string, error) {
 	var (
 		sqls          []string
 		operationType string
@@ -238,6 +239,7 @@ func translateOracleToMySQLSQL(oracleSQLRedo, oracleSQLUndo, targetSchema, targe
 		return []string{}, operationType, fmt.Errorf("parse error: %v\n", err.Error())
 	}
 
+	renameColumn(astNode, columnNameRule)
 	stmt := extractStmt(astNode)
 
 	// 库名、表名转换
@@ -251,6 +253,7 @@ func translateOracleToMySQLSQL(oracleSQLRedo, oracleSQLUndo, targetSchema, targe
 		if err != nil {
 			return []string{}, operationType, fmt.Errorf("parse error: %v\n", err.Error())
 		}
+		renameColumn(astUndoNode, columnNameRule)
 		undoStmt := extractStmt(astUndoNode)
 
 		stmt.Data = undoStmt.Before
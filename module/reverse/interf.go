@@ -19,6 +19,7 @@ type Changer interface {
 	ChangeTableName() (map[string]string, error)
 	ChangeTableColumnDatatype() (map[string]map[string]string, error)
 	ChangeTableColumnDefaultValue() (map[string]map[string]string, error)
+	ChangeTableColumnName() (map[string]map[string]string, error)
 }
 
 type Reader interface {
@@ -56,6 +57,11 @@ type Writer interface {
 	Write(w *Write) error
 }
 
+// Differ 下游已存在表对比表结构，输出收敛 ALTER 语句以及不兼容项
+type Differ interface {
+	Diff(t interface{}) (alterSQL string, compatibleSQL string, err error)
+}
+
 type Reverser interface {
 	Reverse() error
 }
@@ -333,3 +333,22 @@ func (r *Change) ChangeTableColumnDefaultValue() (map[string]map[string]string,
 		zap.String("cost", time.Now().Sub(startTime).String()))
 	return tableDefaultValMap, nil
 }
+
+// 字段名映射规则，KEY 源端表名，VALUE 源端字段名 -> 目标端字段名
+func (r *Change) ChangeTableColumnName() (map[string]map[string]string, error) {
+	startTime := time.Now()
+	tableColumnNameMap, err := meta.NewColumnNameRuleModel(r.MetaDB).DetailSchemaColumnNameRule(r.Ctx, &meta.ColumnNameRule{
+		DBTypeS:     r.DBTypeS,
+		DBTypeT:     r.DBTypeT,
+		SchemaNameS: r.SourceSchemaName,
+	})
+	if err != nil {
+		return tableColumnNameMap, err
+	}
+
+	zap.L().Warn("get source table column name mapping rules",
+		zap.String("schema", r.SourceSchemaName),
+		zap.Int("table counts", len(tableColumnNameMap)),
+		zap.String("cost", time.Now().Sub(startTime).String()))
+	return tableColumnNameMap, nil
+}
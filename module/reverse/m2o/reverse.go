@@ -118,7 +118,7 @@ func (r *Reverse) Reverse() error {
 
 	// 获取规则
 	ruleTime := time.Now()
-	tableNameRuleMap, tableColumnRuleMap, tableDefaultRuleMap, err := IChanger(&Change{
+	tableNameRuleMap, tableColumnRuleMap, tableDefaultRuleMap, tableColumnNameRuleMap, err := IChanger(&Change{
 		Ctx:              r.ctx,
 		DBTypeS:          r.cfg.DBTypeS,
 		DBTypeT:          r.cfg.DBTypeT,
@@ -136,13 +136,13 @@ func (r *Reverse) Reverse() error {
 		zap.String("schema", r.cfg.MySQLConfig.SchemaName),
 		zap.String("cost", time.Now().Sub(ruleTime).String()))
 
-	tables, err := GenReverseTableTask(r, tableNameRuleMap, tableColumnRuleMap, tableDefaultRuleMap, reverseTaskTables, oracleDBVersion, isExtended, tableCharSetMap, tableCollationMap)
+	tables, err := GenReverseTableTask(r, tableNameRuleMap, tableColumnRuleMap, tableDefaultRuleMap, tableColumnNameRuleMap, reverseTaskTables, oracleDBVersion, isExtended, tableCharSetMap, tableCollationMap)
 	if err != nil {
 		return err
 	}
 
 	// file writer
-	f, err := reverse.NewWriter(r.cfg, r.mysql, r.oracle)
+	f, err := reverse.NewWriter(r.cfg, r.mysql, r.oracle, nil)
 	if err != nil {
 		return err
 	}
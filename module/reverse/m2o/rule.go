@@ -148,7 +148,7 @@ func (r *Rule) GenTablePrimaryKey() (primaryKeys []string, err error) {
 		for _, rowUKCol := range r.PrimaryKeyINFO {
 			var uk string
 			if rowUKCol["CONSTRAINT_TYPE"] == "PK" {
-				uk = fmt.Sprintf("PRIMARY KEY (%s)", strings.ToUpper(rowUKCol["COLUMN_LIST"]))
+				uk = fmt.Sprintf("PRIMARY KEY (%s)", strings.ToUpper(common.StringColumnListRule(rowUKCol["COLUMN_LIST"], r.TableColumnNameRule)))
 			} else {
 				return primaryKeys, fmt.Errorf("table json [%v], error on get table primary key: %v", r.String(), err)
 			}
@@ -166,7 +166,7 @@ func (r *Rule) GenTableUniqueKey() (uniqueKeys []string, err error) {
 				return uniqueKeys, fmt.Errorf("table json [%v], error on get table primary key: %v", r.String(), err)
 			} else {
 				uk = fmt.Sprintf("CONSTRAINT %s UNIQUE (%s)",
-					strings.ToUpper(rowUKCol["CONSTRAINT_NAME"]), strings.ToUpper(rowUKCol["COLUMN_LIST"]))
+					strings.ToUpper(rowUKCol["CONSTRAINT_NAME"]), strings.ToUpper(common.StringColumnListRule(rowUKCol["COLUMN_LIST"], r.TableColumnNameRule)))
 			}
 			uniqueKeys = append(uniqueKeys, uk)
 		}
@@ -177,6 +177,11 @@ func (r *Rule) GenTableUniqueKey() (uniqueKeys []string, err error) {
 func (r *Rule) GenTableForeignKey() (foreignKeys []string, err error) {
 	if len(r.ForeignKeyINFO) > 0 {
 		for _, rowFKCol := range r.ForeignKeyINFO {
+			// 字段名映射，同 schema 父表引用字段按父表规则映射
+			if strings.EqualFold(rowFKCol["R_OWNER"], r.SourceSchemaName) {
+				rowFKCol["RCOLUMN_LIST"] = common.StringColumnListRule(rowFKCol["RCOLUMN_LIST"], r.SchemaColumnNameRule[common.StringUPPER(rowFKCol["RTABLE_NAME"])])
+			}
+			rowFKCol["COLUMN_LIST"] = common.StringColumnListRule(rowFKCol["COLUMN_LIST"], r.TableColumnNameRule)
 			if rowFKCol["DELETE_RULE"] == "" || rowFKCol["DELETE_RULE"] == "NO ACTION" || rowFKCol["DELETE_RULE"] == "RESTRICT" {
 				fk := fmt.Sprintf("CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s.%s (%s)",
 					strings.ToUpper(rowFKCol["CONSTRAINT_NAME"]),
@@ -264,14 +269,14 @@ func (r *Rule) GenTableNormalIndex() (normalIndexes []string, compatibilityIndex
 					strings.ToUpper(kv["INDEX_NAME"]),
 					r.TargetSchemaName,
 					r.TargetTableName,
-					strings.ToUpper(kv["COLUMN_LIST"]),
+					strings.ToUpper(common.StringColumnListRule(kv["COLUMN_LIST"], r.TableColumnNameRule)),
 				)
 			} else {
 				idx = fmt.Sprintf("CREATE INDEX %s ON %s.%s (%s);",
 					strings.ToUpper(kv["INDEX_NAME"]),
 					r.TargetSchemaName,
 					r.TargetTableName,
-					strings.ToUpper(kv["COLUMN_LIST"]),
+					strings.ToUpper(common.StringColumnListRule(kv["COLUMN_LIST"], r.TableColumnNameRule)),
 				)
 			}
 			normalIndexes = append(normalIndexes, idx)
@@ -359,6 +364,9 @@ func (r *Rule) GenTableColumn() (columnMetas []string, err error) {
 			return columnMetas, fmt.Errorf("mysql table [%s.%s] column [%s] data type isn't exist", r.SourceSchemaName, r.SourceTableName, columnName)
 		}
 
+		// 字段名映射
+		columnName = common.StringColumnNameRule(columnName, r.TableColumnNameRule)
+
 		if strings.EqualFold(nullable, "NULL") {
 			// M2O
 			switch {
@@ -397,7 +405,7 @@ func (r *Rule) GenTableColumnComment() (columnComments []string, err error) {
 	if len(r.TableColumnINFO) > 0 {
 		for _, rowCol := range r.TableColumnINFO {
 			if rowCol["COMMENTS"] != "" {
-				columnComments = append(columnComments, fmt.Sprintf(`COMMENT ON COLUMN %s.%s.%s IS '%s';`, r.TargetSchemaName, r.TargetTableName, rowCol["COLUMN_NAME"], common.SpecialLettersUsingOracle([]byte(rowCol["COMMENTS"]))))
+				columnComments = append(columnComments, fmt.Sprintf(`COMMENT ON COLUMN %s.%s.%s IS '%s';`, r.TargetSchemaName, r.TargetTableName, common.StringColumnNameRule(rowCol["COLUMN_NAME"], r.TableColumnNameRule), common.SpecialLettersUsingOracle([]byte(rowCol["COMMENTS"]))))
 			}
 		}
 	}
@@ -46,12 +46,14 @@ type Table struct {
 	SourceTableCharacterSet string          `json:"source_table_character_set"`
 	SourceTableCollation    string          `json:"source_table_collation"`
 
-	TableColumnDatatypeRule   map[string]string `json:"table_column_datatype_rule"`
-	TableColumnDefaultValRule map[string]string `json:"table_column_default_val_rule"`
-	Overwrite                 bool              `json:"overwrite"`
-	Oracle                    *oracle.Oracle    `json:"-"`
-	MySQL                     *mysql.MySQL      `json:"-"`
-	MetaDB                    *meta.Meta        `json:"-"`
+	TableColumnDatatypeRule   map[string]string            `json:"table_column_datatype_rule"`
+	TableColumnDefaultValRule map[string]string            `json:"table_column_default_val_rule"`
+	TableColumnNameRule       map[string]string            `json:"table_column_name_rule"`
+	SchemaColumnNameRule      map[string]map[string]string `json:"-"`
+	Overwrite                 bool                         `json:"overwrite"`
+	Oracle                    *oracle.Oracle               `json:"-"`
+	MySQL                     *mysql.MySQL                 `json:"-"`
+	MetaDB                    *meta.Meta                   `json:"-"`
 }
 
 func PreCheckCompatibility(cfg *config.Config, mysql *mysql.MySQL, exporters []string, oracleDBVersion string, isExtended bool) ([]string, map[string][]map[string]string, map[string]string, map[string]string, error) {
@@ -155,7 +157,7 @@ func PreCheckCompatibility(cfg *config.Config, mysql *mysql.MySQL, exporters []s
 	return reverseTaskTables, errCompatibility, tableCharSetMap, tableCollationMap, nil
 }
 
-func GenReverseTableTask(r *Reverse, tableNameRule map[string]string, tableColumnRule, tableDefaultRule map[string]map[string]string, exporters []string, oracleDBVersion string, isExtended bool, tableCharSetMap map[string]string, tableCollationMap map[string]string) ([]*Table, error) {
+func GenReverseTableTask(r *Reverse, tableNameRule map[string]string, tableColumnRule, tableDefaultRule, tableColumnNameRule map[string]map[string]string, exporters []string, oracleDBVersion string, isExtended bool, tableCharSetMap map[string]string, tableCollationMap map[string]string) ([]*Table, error) {
 	var (
 		tables []*Table
 	)
@@ -208,6 +210,8 @@ func GenReverseTableTask(r *Reverse, tableNameRule map[string]string, tableColum
 					SourceTableCollation:      tableCollationMap[ts],
 					TableColumnDatatypeRule:   tableColumnRule[common.StringUPPER(ts)],
 					TableColumnDefaultValRule: tableDefaultRule[common.StringUPPER(ts)],
+					TableColumnNameRule:       tableColumnNameRule[common.StringUPPER(ts)],
+					SchemaColumnNameRule:      tableColumnNameRule,
 					Overwrite:                 r.cfg.MySQLConfig.Overwrite,
 					MySQL:                     r.mysql,
 					Oracle:                    r.oracle,
@@ -37,6 +37,8 @@ type Change struct {
 	OracleCollation  bool            `json:"oracle_collation"`
 	Oracle           *oracle.Oracle  `json:"-"`
 	MetaDB           *meta.Meta      `json:"-"`
+	// 内置数据类型映射规则，为空默认 OracleTableColumnMapRule，O2P 等其他目标端复用 Change 时指定
+	ColumnMapRule func(sourceSchema, sourceTable string, column Column, buildinDatatypes []meta.BuildinDatatypeRule) (string, string, error) `json:"-"`
 }
 
 func (r *Change) ChangeTableName() (map[string]string, error) {
@@ -151,6 +153,11 @@ func (r *Change) ChangeTableColumnDatatype() (map[string]map[string]string, erro
 		return tableDatatypeMap, err
 	}
 
+	columnMapRule := OracleTableColumnMapRule
+	if r.ColumnMapRule != nil {
+		columnMapRule = r.ColumnMapRule
+	}
+
 	wg := &errgroup.Group{}
 	wg.SetLimit(r.Threads)
 
@@ -181,7 +188,7 @@ func (r *Change) ChangeTableColumnDatatype() (map[string]map[string]string, erro
 			tableDatatypeTempMap := make(map[string]map[string]string, 1)
 
 			for _, rowCol := range tableColumnINFO {
-				originColumnType, buildInColumnType, err := OracleTableColumnMapRule(r.SourceSchemaName, sourceTable, Column{
+				originColumnType, buildInColumnType, err := columnMapRule(r.SourceSchemaName, sourceTable, Column{
 					DataType:   rowCol["DATA_TYPE"],
 					CharUsed:   rowCol["CHAR_USED"],
 					CharLength: rowCol["CHAR_LENGTH"],
@@ -315,3 +322,22 @@ func (r *Change) ChangeTableColumnDefaultValue() (map[string]map[string]string,
 		zap.String("cost", time.Now().Sub(startTime).String()))
 	return tableDefaultValMap, nil
 }
+
+// 字段名映射规则，KEY 源端表名，VALUE 源端字段名 -> 目标端字段名
+func (r *Change) ChangeTableColumnName() (map[string]map[string]string, error) {
+	startTime := time.Now()
+	tableColumnNameMap, err := meta.NewColumnNameRuleModel(r.MetaDB).DetailSchemaColumnNameRule(r.Ctx, &meta.ColumnNameRule{
+		DBTypeS:     r.DBTypeS,
+		DBTypeT:     r.DBTypeT,
+		SchemaNameS: r.SourceSchemaName,
+	})
+	if err != nil {
+		return tableColumnNameMap, err
+	}
+
+	zap.L().Warn("get source table column name mapping rules",
+		zap.String("schema", r.SourceSchemaName),
+		zap.Int("table counts", len(tableColumnNameMap)),
+		zap.String("cost", time.Now().Sub(startTime).String()))
+	return tableColumnNameMap, nil
+}
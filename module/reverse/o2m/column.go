@@ -0,0 +1,71 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package o2m
+
+import (
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"strings"
+)
+
+// genVirtualColumn 虚拟列表达式改写 generated column 子句，主键字段 mysql 不支持 VIRTUAL，使用 STORED
+func (r *Rule) genVirtualColumn(rowCol map[string]string) (string, string) {
+	columnTypes := make(map[string]string)
+	for _, col := range r.TableColumnINFO {
+		columnTypes[r.GenColumnName(col["COLUMN_NAME"])] = col["DATA_TYPE"]
+	}
+	expr, err := TranslateOracleExpr(rowCol["DATA_DEFAULT"], r.SourceSchemaName, r.TargetSchemaName, columnTypes, r.TableColumnNameRule)
+	if err != nil {
+		return "", fmt.Sprintf("virtual column expression [%s] %v", strings.TrimSpace(rowCol["DATA_DEFAULT"]), err)
+	}
+
+	storage := "VIRTUAL"
+	for _, pk := range r.PrimaryKeyINFO {
+		if common.IsContainString(strings.Split(pk["COLUMN_LIST"], ","), rowCol["COLUMN_NAME"]) {
+			storage = "STORED"
+		}
+	}
+	return fmt.Sprintf("GENERATED ALWAYS AS (%s) %s", expr, storage), ""
+}
+
+// isSupportInvisibleColumn mysql 8.0.23 及以上支持不可见列，tidb 不支持
+func (r *Rule) isSupportInvisibleColumn() bool {
+	if strings.EqualFold(r.TargetDBType, common.DatabaseTypeTiDB) {
+		return false
+	}
+	return common.VersionOrdinal(r.TargetDBVersion) >= common.VersionOrdinal(common.MySQLInvisibleColumnVersion)
+}
+
+// GenTableColumnCompatible 虚拟列表达式无法改写以及不可见列不支持的不兼容项
+func (r *Rule) GenTableColumnCompatible() []string {
+	var compatibilityDDL []string
+	for _, rowCol := range r.TableColumnINFO {
+		if strings.EqualFold(rowCol["VIRTUAL_COLUMN"], "YES") {
+			if _, reason := r.genVirtualColumn(rowCol); reason != "" {
+				compatibilityDDL = append(compatibilityDDL,
+					fmt.Sprintf("ALTER TABLE `%s`.`%s` MODIFY COLUMN `%s` %s GENERATED ALWAYS AS (%s) VIRTUAL; -- %s",
+						r.GenSchemaName(), r.GenTableName(), r.GenColumnName(rowCol["COLUMN_NAME"]), r.TableColumnDatatypeRule[rowCol["COLUMN_NAME"]],
+						strings.TrimSpace(rowCol["DATA_DEFAULT"]), reason))
+			}
+		}
+		if strings.EqualFold(rowCol["HIDDEN_COLUMN"], "YES") && !r.isSupportInvisibleColumn() {
+			compatibilityDDL = append(compatibilityDDL,
+				fmt.Sprintf("ALTER TABLE `%s`.`%s` ALTER COLUMN `%s` SET INVISIBLE; -- target db isn't support invisible column, convert to visible column",
+					r.GenSchemaName(), r.GenTableName(), r.GenColumnName(rowCol["COLUMN_NAME"])))
+		}
+	}
+	return compatibilityDDL
+}
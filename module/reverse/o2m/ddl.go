@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/database/meta"
 	"github.com/wentaojin/transferdb/module/reverse"
 	"go.uber.org/zap"
 	"strings"
@@ -41,6 +42,12 @@ type DDL struct {
 	TableCheckKeys     []string `json:"table_check_keys""`
 	TableForeignKeys   []string `json:"table_foreign_keys"`
 	TableCompatibleDDL []string `json:"table_compatible_ddl"`
+	TablePartition     string   `json:"table_partition"`
+	PartitionReason    string   `json:"partition_reason"`
+	ForeignKeyPostLoad bool     `json:"foreign_key_post_load"`
+
+	// 函数索引 stored generated column，写入元数据库用于 check 等模式对比字段时忽略
+	IndexGeneratedColumns []meta.IndexGeneratedColumn `json:"index_generated_columns"`
 }
 
 func (d *DDL) Write(w *reverse.Write) error {
@@ -68,23 +75,7 @@ func (d *DDL) Write(w *reverse.Write) error {
 	sqlRev.WriteString(fmt.Sprintf("%v\n", sw.Render()))
 	sqlRev.WriteString("*/\n")
 
-	var reverseDDL string
-	if len(d.TableKeys) > 0 {
-		reverseDDL = fmt.Sprintf("%s (\n%s,\n%s\n)",
-			d.TablePrefix,
-			strings.Join(d.TableColumns, ",\n"),
-			strings.Join(d.TableKeys, ",\n"))
-	} else {
-		reverseDDL = fmt.Sprintf("%s (\n%s\n)",
-			d.TablePrefix,
-			strings.Join(d.TableColumns, ",\n"))
-	}
-
-	if strings.EqualFold(d.TableComment, "") {
-		tableDDL = fmt.Sprintf("%s %s;", reverseDDL, d.TableSuffix)
-	} else {
-		tableDDL = fmt.Sprintf("%s %s %s;", reverseDDL, d.TableSuffix, d.TableComment)
-	}
+	tableDDL = d.GenCreateTableSQL()
 	sqlRev.WriteString(tableDDL + "\n\n")
 
 	// foreign and check key sql ddl
@@ -111,6 +102,20 @@ func (d *DDL) Write(w *reverse.Write) error {
 		}
 	}
 
+	// 分区表不支持转换，按普通表创建
+	if !strings.EqualFold(d.PartitionReason, "") {
+		sqlComp.WriteString("/*\n")
+		sqlComp.WriteString(" oracle partition table maybe mysql has compatibility, will convert to normal table, please manual process\n")
+		pw := table.NewWriter()
+		pw.SetStyle(table.StyleLight)
+		pw.AppendHeader(table.Row{"#", "ORACLE", "MYSQL", "REASON", "SUGGEST"})
+		pw.AppendRows([]table.Row{
+			{"TABLE", fmt.Sprintf("%s.%s", d.SourceSchemaName, d.SourceTableName), fmt.Sprintf("%s.%s", d.TargetSchemaName, d.TargetTableName), d.PartitionReason, "Manual Process Table"}})
+
+		sqlComp.WriteString(fmt.Sprintf("%v\n", pw.Render()))
+		sqlComp.WriteString("*/\n")
+	}
+
 	// 兼容项处理
 	if len(d.TableForeignKeys) > 0 || len(d.TableCheckKeys) > 0 || len(d.TableCompatibleDDL) > 0 {
 		sqlComp.WriteString("/*\n")
@@ -128,8 +133,20 @@ func (d *DDL) Write(w *reverse.Write) error {
 	// 外键约束、检查约束
 	if d.TargetDBType != common.DatabaseTypeTiDB {
 		if len(foreignKeyDDL) > 0 {
-			for _, sql := range foreignKeyDDL {
-				sqlRev.WriteString(sql + "\n")
+			if d.ForeignKeyPostLoad {
+				// 外键延后至全量数据加载后执行
+				var sqlFK strings.Builder
+				sqlFK.WriteString(fmt.Sprintf("-- %s.%s\n", d.TargetSchemaName, d.TargetTableName))
+				for _, sql := range foreignKeyDDL {
+					sqlFK.WriteString(sql + "\n")
+				}
+				if _, err := w.FWriteFile(sqlFK.String()); err != nil {
+					return err
+				}
+			} else {
+				for _, sql := range foreignKeyDDL {
+					sqlRev.WriteString(sql + "\n")
+				}
 			}
 		}
 
@@ -211,6 +228,32 @@ func (d *DDL) Write(w *reverse.Write) error {
 	return nil
 }
 
+// GenCreateTableSQL 生成单条 CREATE TABLE 语句（含分区定义，不含外键、检查约束以及不兼容项）
+func (d *DDL) GenCreateTableSQL() string {
+	var reverseDDL string
+	if len(d.TableKeys) > 0 {
+		reverseDDL = fmt.Sprintf("%s (\n%s,\n%s\n)",
+			d.TablePrefix,
+			strings.Join(d.TableColumns, ",\n"),
+			strings.Join(d.TableKeys, ",\n"))
+	} else {
+		reverseDDL = fmt.Sprintf("%s (\n%s\n)",
+			d.TablePrefix,
+			strings.Join(d.TableColumns, ",\n"))
+	}
+
+	if !strings.EqualFold(d.TableComment, "") {
+		reverseDDL = fmt.Sprintf("%s %s %s", reverseDDL, d.TableSuffix, d.TableComment)
+	} else {
+		reverseDDL = fmt.Sprintf("%s %s", reverseDDL, d.TableSuffix)
+	}
+
+	if strings.EqualFold(d.TablePartition, "") {
+		return fmt.Sprintf("%s;", reverseDDL)
+	}
+	return fmt.Sprintf("%s\n%s;", reverseDDL, d.TablePartition)
+}
+
 func (d *DDL) String() string {
 	jsonBytes, _ := json.Marshal(d)
 	return string(jsonBytes)
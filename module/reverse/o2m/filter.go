@@ -25,7 +25,8 @@ import (
 	"time"
 )
 
-func filterCFGTable(cfg *config.Config, oracle *oracle.Oracle) ([]string, error) {
+// FilterCFGTable 按 include-table/exclude-table 获取 oracle 待处理表，O2M 以及 O2P 共用
+func FilterCFGTable(cfg *config.Config, oracle *oracle.Oracle) ([]string, error) {
 	startTime := time.Now()
 	var (
 		exporterTableSlice []string
@@ -88,7 +89,7 @@ func filterCFGTable(cfg *config.Config, oracle *oracle.Oracle) ([]string, error)
 	}
 
 	endTime := time.Now()
-	zap.L().Info("get oracle to mysql all tables",
+	zap.L().Info("get oracle all tables",
 		zap.String("schema", cfg.OracleConfig.SchemaName),
 		zap.Strings("exporter tables list", exporterTableSlice),
 		zap.Int("include table counts", len(exporterTableSlice)),
@@ -124,7 +125,7 @@ func FilterOracleCompatibleTable(cfg *config.Config, oracle *oracle.Oracle, expo
 		zap.L().Warn("partition tables",
 			zap.String("schema", cfg.OracleConfig.SchemaName),
 			zap.String("partition table list", fmt.Sprintf("%v", partitionTables)),
-			zap.String("suggest", "if necessary, please manually convert and process the tables in the above list"))
+			zap.String("suggest", "partition tables will convert to mysql partition table, unsupported partition table will convert to normal table, detail see compatibility output"))
 	}
 	if len(temporaryTables) != 0 {
 		zap.L().Warn("temporary tables",
@@ -0,0 +1,183 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package o2m
+
+import (
+	"fmt"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/wentaojin/transferdb/module/reverse"
+	"go.uber.org/zap"
+	"sort"
+	"strings"
+)
+
+// sortTableByForeignKey 按外键依赖分层，父表所在层先于子表创建，同层表并发创建
+// 循环依赖以及依赖循环依赖的表统一放置最后一层，外键延后执行，返回循环依赖表集合
+func sortTableByForeignKey(tables []*Table, relations []map[string]string) ([][]*Table, [][]string) {
+	tableMap := make(map[string]*Table)
+	for _, t := range tables {
+		tableMap[t.SourceTableName] = t
+	}
+
+	parents := make(map[string]map[string]bool)
+	for _, rel := range relations {
+		child, parent := rel["TABLE_NAME"], rel["R_TABLE_NAME"]
+		// 自引用外键 ALTER TABLE 于表创建后添加，不影响创建顺序
+		if child == parent {
+			continue
+		}
+		if _, ok := tableMap[child]; !ok {
+			continue
+		}
+		if _, ok := tableMap[parent]; !ok {
+			continue
+		}
+		if _, ok := parents[child]; !ok {
+			parents[child] = make(map[string]bool)
+		}
+		parents[child][parent] = true
+	}
+
+	var (
+		layers [][]*Table
+		done   = make(map[string]bool)
+	)
+	for {
+		var layer []*Table
+		for _, t := range tables {
+			if done[t.SourceTableName] {
+				continue
+			}
+			ready := true
+			for p := range parents[t.SourceTableName] {
+				if !done[p] {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				layer = append(layer, t)
+			}
+		}
+		if len(layer) == 0 {
+			break
+		}
+		for _, t := range layer {
+			done[t.SourceTableName] = true
+		}
+		layers = append(layers, layer)
+	}
+
+	var remains []*Table
+	for _, t := range tables {
+		if !done[t.SourceTableName] {
+			t.ForeignKeyPostLoad = true
+			remains = append(remains, t)
+		}
+	}
+	if len(remains) == 0 {
+		return layers, nil
+	}
+	layers = append(layers, remains)
+	return layers, findForeignKeyCycle(remains, parents)
+}
+
+// findForeignKeyCycle tarjan 强连通分量查找外键循环依赖
+func findForeignKeyCycle(tables []*Table, parents map[string]map[string]bool) [][]string {
+	var (
+		index   int
+		stack   []string
+		indexes = make(map[string]int)
+		lowLink = make(map[string]int)
+		onStack = make(map[string]bool)
+		cycles  [][]string
+		visit   func(node string)
+	)
+	visit = func(node string) {
+		indexes[node] = index
+		lowLink[node] = index
+		index++
+		stack = append(stack, node)
+		onStack[node] = true
+
+		var next []string
+		for p := range parents[node] {
+			next = append(next, p)
+		}
+		sort.Strings(next)
+		for _, p := range next {
+			if _, ok := indexes[p]; !ok {
+				visit(p)
+				if lowLink[p] < lowLink[node] {
+					lowLink[node] = lowLink[p]
+				}
+			} else if onStack[p] && indexes[p] < lowLink[node] {
+				lowLink[node] = indexes[p]
+			}
+		}
+
+		if lowLink[node] == indexes[node] {
+			var component []string
+			for {
+				n := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[n] = false
+				component = append(component, n)
+				if n == node {
+					break
+				}
+			}
+			if len(component) > 1 {
+				sort.Strings(component)
+				cycles = append(cycles, component)
+			}
+		}
+	}
+	for _, t := range tables {
+		if _, ok := indexes[t.SourceTableName]; !ok {
+			visit(t.SourceTableName)
+		}
+	}
+	return cycles
+}
+
+// GenForeignKeyCycle 外键循环依赖输出不兼容项
+func GenForeignKeyCycle(w *reverse.Write, sourceSchema string, cycles [][]string) error {
+	if len(cycles) == 0 {
+		return nil
+	}
+	var sqlComp strings.Builder
+	sqlComp.WriteString("/*\n")
+	sqlComp.WriteString(" oracle table foreign key exist circular dependency, foreign key will output to post-load script, please manual process\n")
+	t := table.NewWriter()
+	t.SetStyle(table.StyleLight)
+	t.AppendHeader(table.Row{"#", "SCHEMA", "CIRCULAR TABLES", "SUGGEST"})
+	for i, c := range cycles {
+		t.AppendRows([]table.Row{
+			{fmt.Sprintf("CYCLE %d", i+1), sourceSchema, strings.Join(c, ", "), "Manual Process Foreign Key"},
+		})
+	}
+	sqlComp.WriteString(t.Render() + "\n")
+	sqlComp.WriteString("*/\n")
+	if _, err := w.CWriteFile(sqlComp.String()); err != nil {
+		return err
+	}
+	zap.L().Warn("reverse oracle table foreign key",
+		zap.String("schema", sourceSchema),
+		zap.Int("circular counts", len(cycles)),
+		zap.String("suggest", "foreign key circular dependency, detail see compatibility output"))
+	return nil
+}
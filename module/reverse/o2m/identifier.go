@@ -0,0 +1,255 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package o2m
+
+import (
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/database/meta"
+	"go.uber.org/zap"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AdjustIdentifier 超长标识符处理以及下游标识符冲突检测
+// 1、identifier-policy shorten 超长表名、字段名截断映射写入元数据库 [table_name_rule]/[column_name_rule]，已存在自定义规则优先
+// 2、下游标识符大小写不敏感，表/视图、表字段、视图字段、表索引、表分区同一命名空间映射相同目标端标识符报错
+func (r *Reverse) AdjustIdentifier(exporters []string) error {
+	startTime := time.Now()
+	sourceSchema := common.StringUPPER(r.Cfg.OracleConfig.SchemaName)
+
+	columns, err := r.Oracle.GetOracleSchemaTableColumnName(sourceSchema)
+	if err != nil {
+		return err
+	}
+	indexes, err := r.Oracle.GetOracleSchemaTableIndexName(sourceSchema)
+	if err != nil {
+		return err
+	}
+	viewColumns, err := r.Oracle.GetOracleSchemaViewColumn(sourceSchema)
+	if err != nil {
+		return err
+	}
+	partitions, err := r.Oracle.GetOracleSchemaTablePartitionName(sourceSchema)
+	if err != nil {
+		return err
+	}
+
+	exporterMap := make(map[string]struct{})
+	for _, t := range exporters {
+		exporterMap[t] = struct{}{}
+	}
+	tableColumns := make(map[string][]string)
+	for _, col := range columns {
+		if _, ok := exporterMap[col["TABLE_NAME"]]; ok {
+			tableColumns[col["TABLE_NAME"]] = append(tableColumns[col["TABLE_NAME"]], col["COLUMN_NAME"])
+		}
+	}
+	tableIndexes := make(map[string][]string)
+	for _, idx := range indexes {
+		if _, ok := exporterMap[idx["TABLE_NAME"]]; ok {
+			tableIndexes[idx["TABLE_NAME"]] = append(tableIndexes[idx["TABLE_NAME"]], idx["INDEX_NAME"])
+		}
+	}
+	tablePartitions := make(map[string][]string)
+	for _, part := range partitions {
+		if _, ok := exporterMap[part["TABLE_NAME"]]; ok {
+			tablePartitions[part["TABLE_NAME"]] = append(tablePartitions[part["TABLE_NAME"]], part["PARTITION_NAME"])
+		}
+	}
+	var views []string
+	viewColumnMap := make(map[string][]string)
+	for _, col := range viewColumns {
+		if _, ok := viewColumnMap[col["TABLE_NAME"]]; !ok {
+			views = append(views, col["TABLE_NAME"])
+		}
+		viewColumnMap[col["TABLE_NAME"]] = append(viewColumnMap[col["TABLE_NAME"]], col["COLUMN_NAME"])
+	}
+
+	if strings.EqualFold(r.Cfg.ReverseConfig.IdentifierPolicy, common.ReverseIdentifierPolicyShorten) {
+		if err = r.genIdentifierRule(exporters, tableColumns); err != nil {
+			return err
+		}
+	}
+
+	// 获取表名、字段名自定义规则（包含截断映射规则）
+	tableNameRules, err := meta.NewTableNameRuleModel(r.MetaDB).DetailTableNameRule(r.Ctx, &meta.TableNameRule{
+		DBTypeS:     r.Cfg.DBTypeS,
+		DBTypeT:     r.Cfg.DBTypeT,
+		SchemaNameS: sourceSchema,
+		SchemaNameT: common.StringUPPER(r.Cfg.MySQLConfig.SchemaName),
+	})
+	if err != nil {
+		return err
+	}
+	tableNameRule := make(map[string]string)
+	for _, tr := range tableNameRules {
+		tableNameRule[common.StringUPPER(tr.TableNameS)] = common.StringUPPER(tr.TableNameT)
+	}
+	columnNameRule, err := meta.NewColumnNameRuleModel(r.MetaDB).DetailSchemaColumnNameRule(r.Ctx, &meta.ColumnNameRule{
+		DBTypeS:     r.Cfg.DBTypeS,
+		DBTypeT:     r.Cfg.DBTypeT,
+		SchemaNameS: sourceSchema,
+	})
+	if err != nil {
+		return err
+	}
+
+	var collisions, overLengths []string
+	objects := make(map[string][]string)
+	for _, t := range exporters {
+		tableT := common.StringUPPER(t)
+		if val, ok := tableNameRule[common.StringUPPER(t)]; ok {
+			tableT = val
+		}
+		objects[tableT] = append(objects[tableT], t)
+	}
+	for _, v := range views {
+		viewT := common.StringIdentifierPolicy(v, r.Cfg.ReverseConfig.IdentifierPolicy)
+		objects[viewT] = append(objects[viewT], v)
+	}
+	c, o := checkIdentifier("table/view", objects)
+	collisions, overLengths = append(collisions, c...), append(overLengths, o...)
+
+	for _, t := range exporters {
+		tableCols := make(map[string][]string)
+		for _, col := range tableColumns[t] {
+			colT := common.StringColumnNameRule(col, columnNameRule[common.StringUPPER(t)])
+			tableCols[colT] = append(tableCols[colT], col)
+		}
+		c, o = checkIdentifier(fmt.Sprintf("table [%s] column", t), tableCols)
+		collisions, overLengths = append(collisions, c...), append(overLengths, o...)
+
+		tableIdx := make(map[string][]string)
+		for _, idx := range tableIndexes[t] {
+			idxT := common.StringIdentifierPolicy(common.StringUPPER(idx), r.Cfg.ReverseConfig.IdentifierPolicy)
+			tableIdx[idxT] = append(tableIdx[idxT], idx)
+		}
+		c, o = checkIdentifier(fmt.Sprintf("table [%s] index", t), tableIdx)
+		collisions, overLengths = append(collisions, c...), append(overLengths, o...)
+
+		tablePart := make(map[string][]string)
+		for _, part := range tablePartitions[t] {
+			partT := common.StringIdentifierPolicy(common.StringUPPER(part), r.Cfg.ReverseConfig.IdentifierPolicy)
+			tablePart[partT] = append(tablePart[partT], part)
+		}
+		c, o = checkIdentifier(fmt.Sprintf("table [%s] partition", t), tablePart)
+		collisions, overLengths = append(collisions, c...), append(overLengths, o...)
+	}
+	for _, v := range views {
+		viewCols := make(map[string][]string)
+		for _, col := range viewColumnMap[v] {
+			colT := common.StringIdentifierPolicy(col, r.Cfg.ReverseConfig.IdentifierPolicy)
+			viewCols[colT] = append(viewCols[colT], col)
+		}
+		c, o = checkIdentifier(fmt.Sprintf("view [%s] column", v), viewCols)
+		collisions, overLengths = append(collisions, c...), append(overLengths, o...)
+	}
+
+	if len(overLengths) > 0 {
+		zap.L().Warn("reverse oracle identifier length over 64",
+			zap.String("schema", sourceSchema),
+			zap.Strings("identifiers", overLengths),
+			zap.String("suggest", "set [reverse] identifier-policy shorten or config name rule"))
+	}
+	if len(collisions) > 0 {
+		return fmt.Errorf("oracle schema [%s] identifier case-insensitive collision %v, please config table [table_name_rule] or [column_name_rule], or rename source object", sourceSchema, collisions)
+	}
+
+	zap.L().Info("adjust oracle identifier finished",
+		zap.String("schema", sourceSchema),
+		zap.String("identifier policy", r.Cfg.ReverseConfig.IdentifierPolicy),
+		zap.String("cost", time.Now().Sub(startTime).String()))
+	return nil
+}
+
+// genIdentifierRule 超长表名、字段名截断映射写入元数据库，写入忽略已存在源端规则
+func (r *Reverse) genIdentifierRule(exporters []string, tableColumns map[string][]string) error {
+	var (
+		tableRules  []meta.TableNameRule
+		columnRules []meta.ColumnNameRule
+	)
+	for _, t := range exporters {
+		if len([]rune(t)) > common.MySQLIdentifierMaxLength {
+			tableRules = append(tableRules, meta.TableNameRule{
+				DBTypeS:     r.Cfg.DBTypeS,
+				DBTypeT:     r.Cfg.DBTypeT,
+				SchemaNameS: common.StringUPPER(r.Cfg.OracleConfig.SchemaName),
+				TableNameS:  common.StringUPPER(t),
+				SchemaNameT: common.StringUPPER(r.Cfg.MySQLConfig.SchemaName),
+				TableNameT:  common.StringShortenIdentifier(common.StringUPPER(t), common.MySQLIdentifierMaxLength),
+			})
+		}
+		for _, col := range tableColumns[t] {
+			if len([]rune(col)) > common.MySQLIdentifierMaxLength {
+				columnRules = append(columnRules, meta.ColumnNameRule{
+					DBTypeS:     r.Cfg.DBTypeS,
+					DBTypeT:     r.Cfg.DBTypeT,
+					SchemaNameS: common.StringUPPER(r.Cfg.OracleConfig.SchemaName),
+					TableNameS:  common.StringUPPER(t),
+					ColumnNameS: common.StringUPPER(col),
+					ColumnNameT: common.StringShortenIdentifier(common.StringUPPER(col), common.MySQLIdentifierMaxLength),
+				})
+			}
+		}
+	}
+
+	if err := meta.NewTableNameRuleModel(r.MetaDB).BatchCreateTableNameRule(r.Ctx, tableRules); err != nil {
+		return err
+	}
+	if err := meta.NewColumnNameRuleModel(r.MetaDB).BatchCreateColumnNameRule(r.Ctx, columnRules); err != nil {
+		return err
+	}
+	if len(tableRules) > 0 || len(columnRules) > 0 {
+		zap.L().Warn("reverse oracle identifier shorten",
+			zap.String("schema", r.Cfg.OracleConfig.SchemaName),
+			zap.Int("table name rules", len(tableRules)),
+			zap.Int("column name rules", len(columnRules)),
+			zap.String("tips", "identifier over 64 shorten rule write table [table_name_rule] and [column_name_rule], existing custom rule prior"))
+	}
+	return nil
+}
+
+// checkIdentifier 目标端标识符 -> 源端标识符，目标端大小写不敏感相同视为冲突，同时返回超长目标端标识符
+func checkIdentifier(scope string, identifiers map[string][]string) ([]string, []string) {
+	groups := make(map[string][]string)
+	var (
+		keys        []string
+		overLengths []string
+	)
+	for target, sources := range identifiers {
+		key := common.StringUPPER(target)
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], sources...)
+		if len([]rune(target)) > common.MySQLIdentifierMaxLength {
+			overLengths = append(overLengths, fmt.Sprintf("%s [%s]", scope, target))
+		}
+	}
+	sort.Strings(keys)
+	sort.Strings(overLengths)
+
+	var collisions []string
+	for _, key := range keys {
+		if len(groups[key]) > 1 {
+			sort.Strings(groups[key])
+			collisions = append(collisions, fmt.Sprintf("%s [%s] -> [%s]", scope, strings.Join(groups[key], ","), key))
+		}
+	}
+	return collisions, overLengths
+}
@@ -0,0 +1,207 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package o2m
+
+import (
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/database/meta"
+	"regexp"
+	"strings"
+)
+
+var (
+	indexPlainColumnRegex   = regexp.MustCompile(`(?i)^"?([^"()\s,']+)"?(?:\s+(ASC|DESC))?$`)
+	indexExprFunctionRegex  = regexp.MustCompile("([A-Z_][A-Z0-9_$]*)\\(")
+	indexExprColumnRegex    = regexp.MustCompile("`([^`]+)`")
+	indexExprDateTypeRegexp = regexp.MustCompile(`^(DATE\(|MAKEDATE\(|CAST\(.*AS DATE\)$)`)
+)
+
+type functionIndex struct {
+	// 索引字段，普通字段、表达式或者 generated column
+	KeyParts []string
+	// stored generated column 字段定义
+	GeneratedColumns []string
+	// stored generated column 字段名以及表达式
+	GeneratedColumnNames []string
+	GeneratedColumnExprs []string
+	// 无法转换原因
+	Reason string
+}
+
+// genFunctionIndex 函数索引表达式改写，表达式改写与视图转换共用
+// mysql 8.0.13 及以上以及 tidb 允许函数范围内使用表达式索引，否则使用 stored generated column + 普通索引
+func (r *Rule) genFunctionIndex(idxMeta map[string]string) *functionIndex {
+	fi := &functionIndex{}
+
+	columnTypes := make(map[string]string)
+	for _, col := range r.TableColumnINFO {
+		columnTypes[r.GenColumnName(col["COLUMN_NAME"])] = col["DATA_TYPE"]
+	}
+
+	descends := strings.Split(idxMeta["DESCEND_LIST"], ",")
+	for i, part := range SplitIndexColumnList(idxMeta["COLUMN_LIST"]) {
+		// 字段排序，DBA_IND_COLUMNS.DESCEND 优先，其次字段自带 ASC/DESC 后缀
+		var order string
+		if i < len(descends) && strings.EqualFold(strings.TrimSpace(descends[i]), "DESC") {
+			order = " DESC"
+		}
+		if matches := indexPlainColumnRegex.FindStringSubmatch(part); matches != nil {
+			// 降序索引字段同样以表达式形式记录，表达式为带引号字段名
+			if matches[2] != "" {
+				order = " " + common.StringUPPER(matches[2])
+			}
+			fi.KeyParts = append(fi.KeyParts, fmt.Sprintf("`%s`%s", r.GenColumnName(matches[1]), order))
+			continue
+		}
+		expr, err := TranslateOracleExpr(part, r.SourceSchemaName, r.TargetSchemaName, columnTypes, r.TableColumnNameRule)
+		if err != nil {
+			fi.Reason = fmt.Sprintf("index expression [%s] %v", part, err)
+			return fi
+		}
+
+		if r.isSupportExpressionIndex(expr) {
+			fi.KeyParts = append(fi.KeyParts, fmt.Sprintf("(%s)%s", expr, order))
+			continue
+		}
+
+		columnName := r.GenIdentifierName(fmt.Sprintf("%s_EXPR%d", idxMeta["INDEX_NAME"], i+1))
+		if len(columnName) > 64 {
+			fi.Reason = fmt.Sprintf("index expression [%s] generated column name [%s] exceeds 64 characters", part, columnName)
+			return fi
+		}
+		columnType, reason := r.inferGeneratedColumnType(expr)
+		if reason != "" {
+			fi.Reason = fmt.Sprintf("index expression [%s] %s", part, reason)
+			return fi
+		}
+		fi.GeneratedColumns = append(fi.GeneratedColumns,
+			fmt.Sprintf("`%s` %s GENERATED ALWAYS AS (%s) STORED", columnName, columnType, expr))
+		fi.GeneratedColumnNames = append(fi.GeneratedColumnNames, columnName)
+		fi.GeneratedColumnExprs = append(fi.GeneratedColumnExprs, expr)
+		fi.KeyParts = append(fi.KeyParts, fmt.Sprintf("`%s`%s", columnName, order))
+	}
+	return fi
+}
+
+func (r *Rule) isSupportExpressionIndex(expr string) bool {
+	if !strings.EqualFold(r.TargetDBType, common.DatabaseTypeTiDB) {
+		return common.VersionOrdinal(r.TargetDBVersion) >= common.VersionOrdinal(common.MySQLFunctionIndexVersion)
+	}
+
+	// tidb 版本例如: 5.7.25-TiDB-v6.5.0
+	if !strings.Contains(r.TargetDBVersion, common.TiDBVersionDelimiter) {
+		return false
+	}
+	tidbVersion := strings.Split(strings.Split(r.TargetDBVersion, common.TiDBVersionDelimiter)[1], common.MySQLVersionDelimiter)[0]
+	if common.VersionOrdinal(tidbVersion) < common.VersionOrdinal(common.TiDBExpressionIndexVersion) {
+		return false
+	}
+	for _, matches := range indexExprFunctionRegex.FindAllStringSubmatch(expr, -1) {
+		if !common.IsContainString(common.TiDBExpressionIndexFunctions, matches[1]) {
+			return false
+		}
+	}
+	return true
+}
+
+// inferGeneratedColumnType 依据表达式引用字段推断 generated column 数据类型，仅支持引用单个字段
+// 表达式字段为映射后字段名，按映射规则还原源端字段名获取数据类型
+func (r *Rule) inferGeneratedColumnType(expr string) (string, string) {
+	if indexExprDateTypeRegexp.MatchString(expr) {
+		return "DATE", ""
+	}
+	sourceColumns := make(map[string]string)
+	for _, col := range r.TableColumnINFO {
+		sourceColumns[r.GenColumnName(col["COLUMN_NAME"])] = col["COLUMN_NAME"]
+	}
+	var columns []string
+	for _, matches := range indexExprColumnRegex.FindAllStringSubmatch(expr, -1) {
+		col, ok := sourceColumns[matches[1]]
+		if !ok {
+			continue
+		}
+		if _, ok = r.TableColumnDatatypeRule[col]; ok && !common.IsContainString(columns, col) {
+			columns = append(columns, col)
+		}
+	}
+	if len(columns) != 1 {
+		return "", fmt.Sprintf("reference [%d] columns, can't infer generated column data type", len(columns))
+	}
+	if strings.HasPrefix(expr, "DATE_FORMAT(") {
+		return "VARCHAR(64)", ""
+	}
+	return r.TableColumnDatatypeRule[columns[0]], ""
+}
+
+// GenTableFunctionIndexColumn 函数索引 stored generated column 字段定义以及字段记录
+// 上游不存在对应字段，字段记录由 reverse 写入元数据库 [index_generated_column]，数据库类型由调用方补充
+func (r *Rule) GenTableFunctionIndexColumn() ([]string, []meta.IndexGeneratedColumn) {
+	var (
+		indexes   []map[string]string
+		columns   []string
+		generated []meta.IndexGeneratedColumn
+	)
+	indexes = append(indexes, r.UniqueIndexINFO...)
+	indexes = append(indexes, r.NormalIndexINFO...)
+	for _, idxMeta := range indexes {
+		if idxMeta["INDEX_TYPE"] != common.BuildInOracleIndexTypeFunctionBasedNormal {
+			continue
+		}
+		fi := r.genFunctionIndex(idxMeta)
+		if fi.Reason != "" {
+			continue
+		}
+		columns = append(columns, fi.GeneratedColumns...)
+		for i, col := range fi.GeneratedColumnNames {
+			generated = append(generated, meta.IndexGeneratedColumn{
+				SchemaNameS: r.SourceSchemaName,
+				TableNameS:  r.SourceTableName,
+				IndexNameS:  idxMeta["INDEX_NAME"],
+				SchemaNameT: r.GenSchemaName(),
+				TableNameT:  r.GenTableName(),
+				ColumnNameT: common.StringUPPER(col),
+				ColumnExpr:  fi.GeneratedColumnExprs[i],
+			})
+		}
+	}
+	return columns, generated
+}
+
+// SplitIndexColumnList 按逗号拆分索引字段列表，忽略括号以及字符串内逗号，O2M 以及 O2P 函数索引共用
+func SplitIndexColumnList(columnList string) []string {
+	var (
+		parts   []string
+		depth   int
+		inQuote bool
+		start   int
+	)
+	for i := 0; i < len(columnList); i++ {
+		switch c := columnList[i]; {
+		case c == '\'':
+			inQuote = !inQuote
+		case inQuote:
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case c == ',' && depth == 0:
+			parts = append(parts, strings.TrimSpace(columnList[start:i]))
+			start = i + 1
+		}
+	}
+	return append(parts, strings.TrimSpace(columnList[start:]))
+}
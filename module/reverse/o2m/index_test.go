@@ -0,0 +1,63 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package o2m
+
+import (
+	"github.com/wentaojin/transferdb/common"
+	"reflect"
+	"testing"
+)
+
+func TestGenFunctionIndexKeyOrder(t *testing.T) {
+	r := &Rule{
+		Table: &Table{
+			SourceSchemaName: "MARVIN",
+			TargetSchemaName: "marvin",
+			TargetDBType:     common.DatabaseTypeMySQL,
+			TargetDBVersion:  "8.0.30",
+		},
+		Info: &Info{
+			TableColumnINFO: []map[string]string{
+				{"COLUMN_NAME": "ID", "DATA_TYPE": "NUMBER"},
+				{"COLUMN_NAME": "NAME", "DATA_TYPE": "VARCHAR2"},
+			},
+		},
+	}
+
+	cases := []struct {
+		name    string
+		idxMeta map[string]string
+		want    []string
+	}{
+		{name: "descend column", idxMeta: map[string]string{"INDEX_NAME": "IDX_1", "COLUMN_LIST": `"ID",UPPER("NAME")`, "DESCEND_LIST": "DESC,ASC"},
+			want: []string{"`ID` DESC", "(UPPER(`NAME`))"}},
+		{name: "descend expression", idxMeta: map[string]string{"INDEX_NAME": "IDX_2", "COLUMN_LIST": `UPPER("NAME"),ID`, "DESCEND_LIST": "DESC,ASC"},
+			want: []string{"(UPPER(`NAME`)) DESC", "`ID`"}},
+		{name: "column order suffix", idxMeta: map[string]string{"INDEX_NAME": "IDX_3", "COLUMN_LIST": `"ID" desc,UPPER("NAME")`},
+			want: []string{"`ID` DESC", "(UPPER(`NAME`))"}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			fi := r.genFunctionIndex(c.idxMeta)
+			if fi.Reason != "" {
+				t.Fatalf("genFunctionIndex() reason = %s", fi.Reason)
+			}
+			if !reflect.DeepEqual(fi.KeyParts, c.want) {
+				t.Fatalf("genFunctionIndex() = %v, want %v", fi.KeyParts, c.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,471 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package o2m
+
+import (
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"go.uber.org/zap"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// mysql/tidb 单表最大分区数
+const partitionMaxCounts = 8192
+
+// RANGE COLUMNS/LIST COLUMNS 分区键支持数据类型
+var partitionColumnsDatatype = []string{"TINYINT", "SMALLINT", "MEDIUMINT", "INT", "INTEGER", "BIGINT", "DATE", "DATETIME", "CHAR", "VARCHAR", "BINARY", "VARBINARY"}
+
+// HASH 分区键支持数据类型，其他数据类型 mysql 转换 KEY 分区
+var partitionHashDatatype = []string{"TINYINT", "SMALLINT", "MEDIUMINT", "INT", "INTEGER", "BIGINT"}
+
+var partitionNumberRegex = regexp.MustCompile(`^-?\d+(\.\d+)?$`)
+
+// INTERVAL 分区间隔，例如 NUMTOYMINTERVAL(1,'MONTH')、NUMTODSINTERVAL(7,'DAY')、INTERVAL '1' DAY 以及整数
+var (
+	partitionYMIntervalRegex      = regexp.MustCompile(`(?i)^NUMTOYMINTERVAL\s*\(\s*(\d+)\s*,\s*'\s*(YEAR|MONTH)\s*'\s*\)$`)
+	partitionDSIntervalRegex      = regexp.MustCompile(`(?i)^NUMTODSINTERVAL\s*\(\s*(\d+)\s*,\s*'\s*(DAY|HOUR|MINUTE|SECOND)\s*'\s*\)$`)
+	partitionLiteralIntervalRegex = regexp.MustCompile(`(?i)^INTERVAL\s*'\s*(\d+)\s*'\s*(YEAR|MONTH|DAY|HOUR|MINUTE|SECOND)$`)
+	partitionIntegerRegex         = regexp.MustCompile(`^-?\d+$`)
+)
+
+// INTERVAL 分区展开生成分区名称前缀以及兜底 MAXVALUE 分区名称，与已存在分区名称冲突追加序号
+const (
+	partitionIntervalPrefix = "P_INTERVAL_"
+	partitionMaxValueName   = "P_MAXVALUE"
+)
+
+// GenTablePartition 生成 RANGE COLUMNS/LIST COLUMNS/HASH 分区定义
+// INTERVAL 分区按当前已创建分区展开为显式 RANGE 分区，并按间隔继续生成 interval-partitions 个分区以及 MAXVALUE 兜底分区
+// 不支持转换返回原因，分区定义为空，按普通表创建
+func (r *Rule) GenTablePartition() (tablePartition string, reason string, err error) {
+	if len(r.PartitionKeyINFO) == 0 {
+		return "", "", nil
+	}
+	if len(r.PartitionINFO) == 0 {
+		return "", "", fmt.Errorf("oracle schema [%s] partition table [%s] partition detail isn't exist", r.SourceSchemaName, r.SourceTableName)
+	}
+	if len(r.PartitionINFO) > partitionMaxCounts {
+		return r.genUnsupportedPartition(fmt.Sprintf("partition counts [%d] over %d", len(r.PartitionINFO), partitionMaxCounts))
+	}
+
+	partType := common.StringUPPER(r.PartitionKeyINFO[0]["PARTITIONING_TYPE"])
+	subPartType := common.StringUPPER(r.PartitionKeyINFO[0]["SUBPARTITIONING_TYPE"])
+	partColumns := strings.Split(r.PartitionKeyINFO[0]["PARTITION_EXPRESS"], ",")
+
+	var subPartColumns []string
+	if !strings.EqualFold(subPartType, "NONE") {
+		subPartColumns = strings.Split(r.PartitionKeyINFO[0]["SUBPARTITION_EXPRESS"], ",")
+	}
+
+	// mysql/tidb 主键、唯一键以及唯一索引需包含全部分区键
+	var keyColumns []string
+	keyColumns = append(keyColumns, partColumns...)
+	keyColumns = append(keyColumns, subPartColumns...)
+	if reason = r.checkPartitionUniqueKey(keyColumns); !strings.EqualFold(reason, "") {
+		return r.genUnsupportedPartition(reason)
+	}
+
+	var subPartition string
+	if !strings.EqualFold(subPartType, "NONE") {
+		subPartition, reason, err = r.genSubPartition(partType, subPartType, subPartColumns)
+		if err != nil {
+			return "", "", err
+		}
+		if !strings.EqualFold(reason, "") {
+			return r.genUnsupportedPartition(reason)
+		}
+	}
+
+	var partitions []string
+	switch partType {
+	case "RANGE":
+		partitions, reason, err = r.genRangePartition(partColumns)
+		interval := r.PartitionKeyINFO[0]["PARTITION_INTERVAL"]
+		if err == nil && strings.EqualFold(reason, "") && !strings.EqualFold(interval, "NONE") {
+			var intervalPartitions []string
+			var partitionNames []string
+			for _, part := range r.PartitionINFO {
+				partitionNames = append(partitionNames, r.GenIdentifierName(part["PARTITION_NAME"]))
+			}
+			intervalPartitions, reason = genIntervalPartition(r.PartitionINFO[len(r.PartitionINFO)-1]["HIGH_VALUE"], interval, r.IntervalPartitions, partitionNames)
+			if len(partitions)+len(intervalPartitions) > partitionMaxCounts {
+				reason = fmt.Sprintf("interval partition counts [%d] over %d", len(partitions)+len(intervalPartitions), partitionMaxCounts)
+			}
+			partitions = append(partitions, intervalPartitions...)
+			zap.L().Warn("reverse oracle interval partition table",
+				zap.String("schema", r.SourceSchemaName),
+				zap.String("table", r.SourceTableName),
+				zap.String("interval", interval),
+				zap.Int("partition counts", len(r.PartitionINFO)),
+				zap.Int("interval partitions", r.IntervalPartitions),
+				zap.String("suggest", "interval partition expand explicit range partition and maxvalue partition, maxvalue partition need be reorganized manually"))
+		}
+	case "LIST":
+		partitions, reason, err = r.genListPartition(partColumns)
+	case "HASH":
+		if len(subPartColumns) > 0 {
+			return r.genUnsupportedPartition(fmt.Sprintf("partition type [%s-%s] isn't support", partType, subPartType))
+		}
+		var hashPartition string
+		hashPartition, reason, err = r.genHashPartition("PARTITION", partColumns, len(r.PartitionINFO))
+		if err != nil {
+			return "", "", err
+		}
+		if !strings.EqualFold(reason, "") {
+			return r.genUnsupportedPartition(reason)
+		}
+		return common.StringsBuilder("PARTITION BY ", hashPartition), "", nil
+	default:
+		return r.genUnsupportedPartition(fmt.Sprintf("partition type [%s] isn't support", partType))
+	}
+	if err != nil {
+		return "", "", err
+	}
+	if !strings.EqualFold(reason, "") {
+		return r.genUnsupportedPartition(reason)
+	}
+
+	var quoteColumns []string
+	for _, col := range partColumns {
+		quoteColumns = append(quoteColumns, fmt.Sprintf("`%s`", r.GenColumnName(col)))
+	}
+	tablePartition = fmt.Sprintf("PARTITION BY %s COLUMNS(%s)", partType, strings.Join(quoteColumns, ","))
+	if !strings.EqualFold(subPartition, "") {
+		tablePartition = common.StringsBuilder(tablePartition, "\n", subPartition)
+	}
+	tablePartition = fmt.Sprintf("%s (\n%s\n)", tablePartition, strings.Join(partitions, ",\n"))
+
+	zap.L().Info("reverse oracle partition table",
+		zap.String("schema", r.SourceSchemaName),
+		zap.String("table", r.SourceTableName),
+		zap.String("partition type", partType),
+		zap.String("subpartition type", subPartType),
+		zap.Int("partition counts", len(partitions)))
+	return tablePartition, "", nil
+}
+
+func (r *Rule) genRangePartition(partColumns []string) ([]string, string, error) {
+	reason, err := r.checkPartitionColumnDatatype(partColumns, partitionColumnsDatatype)
+	if err != nil || !strings.EqualFold(reason, "") {
+		return nil, reason, err
+	}
+	var partitions []string
+	for _, part := range r.PartitionINFO {
+		values, err := convertPartitionHighValue(part["HIGH_VALUE"])
+		if err != nil {
+			return nil, err.Error(), nil
+		}
+		if len(values) != len(partColumns) {
+			return nil, fmt.Sprintf("partition [%s] high value [%s] isn't match partition key [%s]", part["PARTITION_NAME"], part["HIGH_VALUE"], strings.Join(partColumns, ",")), nil
+		}
+		partitions = append(partitions, fmt.Sprintf("PARTITION `%s` VALUES LESS THAN (%s)", r.GenIdentifierName(part["PARTITION_NAME"]), strings.Join(values, ",")))
+	}
+	return partitions, "", nil
+}
+
+func (r *Rule) genListPartition(partColumns []string) ([]string, string, error) {
+	reason, err := r.checkPartitionColumnDatatype(partColumns, partitionColumnsDatatype)
+	if err != nil || !strings.EqualFold(reason, "") {
+		return nil, reason, err
+	}
+	var partitions []string
+	for _, part := range r.PartitionINFO {
+		var values []string
+		for _, item := range splitPartitionHighValue(part["HIGH_VALUE"]) {
+			if strings.EqualFold(item, "DEFAULT") {
+				return nil, fmt.Sprintf("list default partition [%s] isn't support", part["PARTITION_NAME"]), nil
+			}
+			// 多列 LIST 分区边界值格式 ('A', 1), ('B', 2)
+			if len(partColumns) > 1 {
+				tuple, err := convertPartitionHighValue(strings.TrimSuffix(strings.TrimPrefix(item, "("), ")"))
+				if err != nil {
+					return nil, err.Error(), nil
+				}
+				if len(tuple) != len(partColumns) {
+					return nil, fmt.Sprintf("partition [%s] high value [%s] isn't match partition key [%s]", part["PARTITION_NAME"], part["HIGH_VALUE"], strings.Join(partColumns, ",")), nil
+				}
+				values = append(values, fmt.Sprintf("(%s)", strings.Join(tuple, ",")))
+				continue
+			}
+			value, err := convertPartitionHighValueItem(item)
+			if err != nil {
+				return nil, err.Error(), nil
+			}
+			values = append(values, value)
+		}
+		partitions = append(partitions, fmt.Sprintf("PARTITION `%s` VALUES IN (%s)", r.GenIdentifierName(part["PARTITION_NAME"]), strings.Join(values, ",")))
+	}
+	return partitions, "", nil
+}
+
+// genHashPartition 单列整型分区键生成 HASH 分区，否则 mysql 生成 KEY 分区
+func (r *Rule) genHashPartition(keyword string, columns []string, counts int) (string, string, error) {
+	var quoteColumns []string
+	for _, col := range columns {
+		quoteColumns = append(quoteColumns, fmt.Sprintf("`%s`", r.GenColumnName(col)))
+	}
+	reason, err := r.checkPartitionColumnDatatype(columns, partitionHashDatatype)
+	if err != nil {
+		return "", "", err
+	}
+	if len(columns) == 1 && strings.EqualFold(reason, "") {
+		return fmt.Sprintf("HASH(%s) %sS %d", quoteColumns[0], keyword, counts), "", nil
+	}
+	if strings.EqualFold(r.TargetDBType, common.DatabaseTypeTiDB) {
+		return "", fmt.Sprintf("hash %s key [%s] isn't single integer column, tidb isn't support", strings.ToLower(keyword), strings.Join(columns, ",")), nil
+	}
+	return fmt.Sprintf("KEY(%s) %sS %d", strings.Join(quoteColumns, ","), keyword, counts), "", nil
+}
+
+// genSubPartition mysql 仅支持 RANGE/LIST 分区 HASH 子分区且各分区子分区数一致，tidb 不支持子分区
+func (r *Rule) genSubPartition(partType, subPartType string, subPartColumns []string) (string, string, error) {
+	if strings.EqualFold(r.TargetDBType, common.DatabaseTypeTiDB) {
+		return "", fmt.Sprintf("composite partition type [%s-%s] tidb isn't support", partType, subPartType), nil
+	}
+	if !strings.EqualFold(subPartType, "HASH") || (!strings.EqualFold(partType, "RANGE") && !strings.EqualFold(partType, "LIST")) {
+		return "", fmt.Sprintf("composite partition type [%s-%s] isn't support", partType, subPartType), nil
+	}
+
+	subPartCounts := r.PartitionINFO[0]["SUBPARTITION_COUNT"]
+	for _, part := range r.PartitionINFO {
+		if !strings.EqualFold(part["SUBPARTITION_COUNT"], subPartCounts) {
+			return "", fmt.Sprintf("composite partition [%s] subpartition counts [%s] isn't equal other partition [%s]", part["PARTITION_NAME"], part["SUBPARTITION_COUNT"], subPartCounts), nil
+		}
+	}
+	counts, err := strconv.Atoi(subPartCounts)
+	if err != nil {
+		return "", "", fmt.Errorf("oracle schema [%s] partition table [%s] subpartition counts [%s] strconv.Atoi failed: %v", r.SourceSchemaName, r.SourceTableName, subPartCounts, err)
+	}
+	if len(r.PartitionINFO)*counts > partitionMaxCounts {
+		return "", fmt.Sprintf("subpartition counts [%d] over %d", len(r.PartitionINFO)*counts, partitionMaxCounts), nil
+	}
+
+	subPartition, reason, err := r.genHashPartition("SUBPARTITION", subPartColumns, counts)
+	if err != nil || !strings.EqualFold(reason, "") {
+		return "", reason, err
+	}
+	return common.StringsBuilder("SUBPARTITION BY ", subPartition), "", nil
+}
+
+// checkPartitionUniqueKey 主键、唯一键以及唯一索引需包含全部分区键
+func (r *Rule) checkPartitionUniqueKey(partColumns []string) string {
+	uniqueKeys := make(map[string]string)
+	for _, pk := range r.PrimaryKeyINFO {
+		uniqueKeys["PRIMARY KEY"] = pk["COLUMN_LIST"]
+	}
+	for _, uk := range r.UniqueKeyINFO {
+		uniqueKeys[uk["CONSTRAINT_NAME"]] = uk["COLUMN_LIST"]
+	}
+	for _, idx := range r.UniqueIndexINFO {
+		if strings.EqualFold(idx["UNIQUENESS"], "UNIQUE") && strings.EqualFold(idx["INDEX_TYPE"], "NORMAL") {
+			uniqueKeys[idx["INDEX_NAME"]] = idx["COLUMN_LIST"]
+		}
+	}
+	for keyName, keyColumns := range uniqueKeys {
+		columns := strings.Split(common.StringUPPER(keyColumns), ",")
+		for _, col := range partColumns {
+			if !common.IsContainString(columns, common.StringUPPER(col)) {
+				return fmt.Sprintf("partition key [%s] isn't included in unique key [%s] columns [%s]", col, keyName, keyColumns)
+			}
+		}
+	}
+	return ""
+}
+
+// checkPartitionColumnDatatype 以规则转换后 mysql 字段数据类型判断分区键是否支持
+func (r *Rule) checkPartitionColumnDatatype(columns []string, datatypes []string) (string, error) {
+	for _, col := range columns {
+		columnType, ok := r.TableColumnDatatypeRule[col]
+		if !ok {
+			return "", fmt.Errorf("oracle table [%s.%s] partition column [%s] data type isn't exist", r.SourceSchemaName, r.SourceTableName, col)
+		}
+		baseType := common.StringUPPER(strings.Fields(strings.Split(columnType, "(")[0])[0])
+		if !common.IsContainString(datatypes, baseType) {
+			return fmt.Sprintf("partition key [%s] data type [%s] isn't support", col, columnType), nil
+		}
+	}
+	return "", nil
+}
+
+func (r *Rule) genUnsupportedPartition(reason string) (string, string, error) {
+	zap.L().Warn("reverse oracle partition table",
+		zap.String("schema", r.SourceSchemaName),
+		zap.String("table", r.SourceTableName),
+		zap.String("target db type", r.TargetDBType),
+		zap.String("reason", reason),
+		zap.String("suggest", "convert to normal table, please manual process"))
+	return "", reason, nil
+}
+
+// genIntervalPartition INTERVAL 分区以最后已创建分区边界值按间隔生成 counts 个分区，并追加 MAXVALUE 兜底分区
+// 生成分区名称避开已存在分区名称 partitionNames（目标端名称）
+// oracle INTERVAL 分区仅支持单个 NUMBER/DATE/TIMESTAMP 分区键，不支持转换返回原因
+func genIntervalPartition(highValue, interval string, counts int, partitionNames []string) ([]string, string) {
+	value, err := convertPartitionHighValueItem(strings.TrimSpace(highValue))
+	if err != nil {
+		return nil, err.Error()
+	}
+	interval = strings.TrimSpace(interval)
+
+	var bounds []string
+	switch {
+	case partitionIntegerRegex.MatchString(interval):
+		if !partitionIntegerRegex.MatchString(value) {
+			return nil, fmt.Sprintf("interval [%s] partition high value [%s] isn't integer", interval, highValue)
+		}
+		step, err := strconv.ParseInt(interval, 10, 64)
+		if err != nil {
+			return nil, fmt.Sprintf("interval [%s] isn't support: %v", interval, err)
+		}
+		last, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Sprintf("interval partition high value [%s] isn't support: %v", highValue, err)
+		}
+		for i := 1; i <= counts; i++ {
+			bounds = append(bounds, strconv.FormatInt(last+step*int64(i), 10))
+		}
+	default:
+		var (
+			num  int
+			unit string
+		)
+		for _, re := range []*regexp.Regexp{partitionYMIntervalRegex, partitionDSIntervalRegex, partitionLiteralIntervalRegex} {
+			if matches := re.FindStringSubmatch(interval); matches != nil {
+				num, _ = strconv.Atoi(matches[1])
+				unit = common.StringUPPER(matches[2])
+				break
+			}
+		}
+		if strings.EqualFold(unit, "") || num == 0 {
+			return nil, fmt.Sprintf("interval [%s] isn't support", interval)
+		}
+		last, err := time.Parse("2006-01-02 15:04:05", strings.Trim(value, "'"))
+		if err != nil {
+			return nil, fmt.Sprintf("interval [%s] partition high value [%s] isn't datetime", interval, highValue)
+		}
+		for i := 1; i <= counts; i++ {
+			var bound time.Time
+			switch unit {
+			case "YEAR":
+				bound = last.AddDate(num*i, 0, 0)
+			case "MONTH":
+				bound = last.AddDate(0, num*i, 0)
+			case "DAY":
+				bound = last.AddDate(0, 0, num*i)
+			case "HOUR":
+				bound = last.Add(time.Duration(num*i) * time.Hour)
+			case "MINUTE":
+				bound = last.Add(time.Duration(num*i) * time.Minute)
+			default:
+				bound = last.Add(time.Duration(num*i) * time.Second)
+			}
+			bounds = append(bounds, fmt.Sprintf("'%s'", bound.Format("2006-01-02 15:04:05")))
+		}
+	}
+
+	used := make(map[string]struct{})
+	for _, name := range partitionNames {
+		used[common.StringUPPER(name)] = struct{}{}
+	}
+	var partitions []string
+	for i, bound := range bounds {
+		partitions = append(partitions, fmt.Sprintf("PARTITION `%s` VALUES LESS THAN (%s)", genUniquePartitionName(fmt.Sprintf("%s%d", partitionIntervalPrefix, i+1), used), bound))
+	}
+	partitions = append(partitions, fmt.Sprintf("PARTITION `%s` VALUES LESS THAN (MAXVALUE)", genUniquePartitionName(partitionMaxValueName, used)))
+	return partitions, ""
+}
+
+// genUniquePartitionName 生成分区名称与已使用分区名称大小写不敏感冲突时追加序号，并记录为已使用
+func genUniquePartitionName(name string, used map[string]struct{}) string {
+	unique := name
+	for i := 1; ; i++ {
+		if _, ok := used[common.StringUPPER(unique)]; !ok {
+			break
+		}
+		unique = fmt.Sprintf("%s_%d", name, i)
+	}
+	used[common.StringUPPER(unique)] = struct{}{}
+	return unique
+}
+
+// convertPartitionHighValue 转换 oracle 分区 HIGH_VALUE 为 mysql 分区边界值
+// 例如: TO_DATE(' 2020-01-01 00:00:00', 'SYYYY-MM-DD HH24:MI:SS', 'NLS_CALENDAR=GREGORIAN'), 100 -> '2020-01-01 00:00:00',100
+func convertPartitionHighValue(highValue string) ([]string, error) {
+	var values []string
+	for _, item := range splitPartitionHighValue(highValue) {
+		value, err := convertPartitionHighValueItem(item)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+func convertPartitionHighValueItem(item string) (string, error) {
+	upperItem := common.StringUPPER(item)
+	switch {
+	case strings.EqualFold(upperItem, "MAXVALUE") || strings.EqualFold(upperItem, "NULL"):
+		return upperItem, nil
+	case strings.HasPrefix(upperItem, "TO_DATE(") || strings.HasPrefix(upperItem, "TO_TIMESTAMP(") || strings.HasPrefix(upperItem, "TIMESTAMP"):
+		start := strings.Index(item, "'")
+		if start == -1 {
+			return "", fmt.Errorf("partition high value [%s] isn't support", item)
+		}
+		end := strings.Index(item[start+1:], "'")
+		if end == -1 {
+			return "", fmt.Errorf("partition high value [%s] isn't support", item)
+		}
+		return fmt.Sprintf("'%s'", strings.TrimSpace(item[start+1:start+1+end])), nil
+	case strings.HasPrefix(item, "'"):
+		return item, nil
+	case partitionNumberRegex.MatchString(item):
+		return item, nil
+	default:
+		return "", fmt.Errorf("partition high value [%s] isn't support", item)
+	}
+}
+
+// splitPartitionHighValue 按顶层逗号切分 HIGH_VALUE，忽略引号以及括号内逗号
+func splitPartitionHighValue(highValue string) []string {
+	var (
+		items   []string
+		depth   int
+		inQuote bool
+		start   int
+	)
+	for i, c := range highValue {
+		switch {
+		case c == '\'':
+			inQuote = !inQuote
+		case inQuote:
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case c == ',' && depth == 0:
+			items = append(items, strings.TrimSpace(highValue[start:i]))
+			start = i + 1
+		}
+	}
+	if !strings.EqualFold(strings.TrimSpace(highValue[start:]), "") {
+		items = append(items, strings.TrimSpace(highValue[start:]))
+	}
+	return items
+}
@@ -0,0 +1,171 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package o2m
+
+import (
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitPartitionHighValue(t *testing.T) {
+	cases := []struct {
+		name      string
+		highValue string
+		want      []string
+	}{
+		{name: "number", highValue: "100", want: []string{"100"}},
+		{name: "multi column", highValue: "100, 'A', MAXVALUE", want: []string{"100", "'A'", "MAXVALUE"}},
+		{name: "to_date", highValue: "TO_DATE(' 2020-01-01 00:00:00', 'SYYYY-MM-DD HH24:MI:SS', 'NLS_CALENDAR=GREGORIAN'), 100",
+			want: []string{"TO_DATE(' 2020-01-01 00:00:00', 'SYYYY-MM-DD HH24:MI:SS', 'NLS_CALENDAR=GREGORIAN')", "100"}},
+		{name: "quoted comma", highValue: "'A,B', 'C'", want: []string{"'A,B'", "'C'"}},
+		{name: "list tuple", highValue: "('A', 1), ('B', 2)", want: []string{"('A', 1)", "('B', 2)"}},
+		{name: "empty", highValue: "", want: nil},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := splitPartitionHighValue(c.highValue)
+			if !reflect.DeepEqual(got, c.want) {
+				t.Fatalf("split [%s] got %q, want %q", c.highValue, got, c.want)
+			}
+		})
+	}
+}
+
+func TestConvertPartitionHighValueItem(t *testing.T) {
+	cases := []struct {
+		name string
+		item string
+		want string
+		err  bool
+	}{
+		{name: "maxvalue", item: "maxvalue", want: "MAXVALUE"},
+		{name: "null", item: "NULL", want: "NULL"},
+		{name: "integer", item: "-100", want: "-100"},
+		{name: "decimal", item: "10.5", want: "10.5"},
+		{name: "string", item: "'A'", want: "'A'"},
+		{name: "to_date", item: "TO_DATE(' 2020-01-01 00:00:00', 'SYYYY-MM-DD HH24:MI:SS', 'NLS_CALENDAR=GREGORIAN')", want: "'2020-01-01 00:00:00'"},
+		{name: "timestamp", item: "TIMESTAMP' 2020-01-01 00:00:00'", want: "'2020-01-01 00:00:00'"},
+		{name: "to_date without literal", item: "TO_DATE(SYSDATE)", err: true},
+		{name: "function", item: "SYSDATE", err: true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := convertPartitionHighValueItem(c.item)
+			if c.err {
+				if err == nil {
+					t.Fatalf("convert [%s] got %s, want error", c.item, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("convert [%s] failed: %v", c.item, err)
+			}
+			if got != c.want {
+				t.Fatalf("convert [%s] got %s, want %s", c.item, got, c.want)
+			}
+		})
+	}
+}
+
+func TestGenIntervalPartition(t *testing.T) {
+	toDate := "TO_DATE(' 2020-01-31 00:00:00', 'SYYYY-MM-DD HH24:MI:SS', 'NLS_CALENDAR=GREGORIAN')"
+	maxValue := "PARTITION `P_MAXVALUE` VALUES LESS THAN (MAXVALUE)"
+
+	cases := []struct {
+		name      string
+		highValue string
+		interval  string
+		counts    int
+		// 已存在分区目标端名称
+		partitionNames []string
+		want           []string
+		// 非空表示期望不支持转换，原因包含该内容
+		reason string
+	}{
+		{name: "only maxvalue", highValue: "100", interval: "100", counts: 0, want: []string{maxValue}},
+		{name: "number", highValue: "100", interval: "50", counts: 2, want: []string{
+			"PARTITION `P_INTERVAL_1` VALUES LESS THAN (150)",
+			"PARTITION `P_INTERVAL_2` VALUES LESS THAN (200)",
+			maxValue}},
+		{name: "month", highValue: "TO_DATE(' 2020-01-01 00:00:00', 'SYYYY-MM-DD HH24:MI:SS', 'NLS_CALENDAR=GREGORIAN')", interval: "NUMTOYMINTERVAL(1,'MONTH')", counts: 2, want: []string{
+			"PARTITION `P_INTERVAL_1` VALUES LESS THAN ('2020-02-01 00:00:00')",
+			"PARTITION `P_INTERVAL_2` VALUES LESS THAN ('2020-03-01 00:00:00')",
+			maxValue}},
+		{name: "year", highValue: toDate, interval: "NUMTOYMINTERVAL(1, 'YEAR')", counts: 1, want: []string{
+			"PARTITION `P_INTERVAL_1` VALUES LESS THAN ('2021-01-31 00:00:00')",
+			maxValue}},
+		{name: "day", highValue: toDate, interval: "NUMTODSINTERVAL(7,'DAY')", counts: 1, want: []string{
+			"PARTITION `P_INTERVAL_1` VALUES LESS THAN ('2020-02-07 00:00:00')",
+			maxValue}},
+		{name: "interval literal hour", highValue: toDate, interval: "INTERVAL '6' HOUR", counts: 1, want: []string{
+			"PARTITION `P_INTERVAL_1` VALUES LESS THAN ('2020-01-31 06:00:00')",
+			maxValue}},
+		{name: "date interval on number", highValue: "100", interval: "NUMTODSINTERVAL(1,'DAY')", counts: 1, reason: "isn't datetime"},
+		{name: "number interval on date", highValue: toDate, interval: "1", counts: 1, reason: "isn't integer"},
+		{name: "decimal interval", highValue: "100", interval: "0.5", counts: 1, reason: "isn't support"},
+		{name: "generated name collides existing partition", highValue: "100", interval: "50", counts: 2, partitionNames: []string{"p_interval_2", "P_MAXVALUE"}, want: []string{
+			"PARTITION `P_INTERVAL_1` VALUES LESS THAN (150)",
+			"PARTITION `P_INTERVAL_2_1` VALUES LESS THAN (200)",
+			"PARTITION `P_MAXVALUE_1` VALUES LESS THAN (MAXVALUE)"}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, reason := genIntervalPartition(c.highValue, c.interval, c.counts, c.partitionNames)
+			if c.reason != "" {
+				if !strings.Contains(reason, c.reason) {
+					t.Fatalf("interval [%s] reason got %q, want contains %q", c.interval, reason, c.reason)
+				}
+				return
+			}
+			if reason != "" {
+				t.Fatalf("interval [%s] unexpected reason: %s", c.interval, reason)
+			}
+			if !reflect.DeepEqual(got, c.want) {
+				t.Fatalf("interval [%s] got %q, want %q", c.interval, got, c.want)
+			}
+		})
+	}
+}
+
+func TestGenRangePartitionIdentifierPolicy(t *testing.T) {
+	longName := "P_" + strings.Repeat("A", 70)
+	r := &Rule{
+		Table: &Table{
+			IdentifierPolicy:        common.ReverseIdentifierPolicyShorten,
+			TableColumnDatatypeRule: map[string]string{"ID": "BIGINT"},
+		},
+		Info: &Info{
+			PartitionINFO: []map[string]string{
+				{"PARTITION_NAME": "p_1", "HIGH_VALUE": "100"},
+				{"PARTITION_NAME": longName, "HIGH_VALUE": "MAXVALUE"},
+			},
+		},
+	}
+	got, reason, err := r.genRangePartition([]string{"ID"})
+	if err != nil || reason != "" {
+		t.Fatalf("genRangePartition() reason = %q, err = %v", reason, err)
+	}
+	want := []string{
+		"PARTITION `P_1` VALUES LESS THAN (100)",
+		fmt.Sprintf("PARTITION `%s` VALUES LESS THAN (MAXVALUE)", common.StringShortenIdentifier(longName, common.MySQLIdentifierMaxLength)),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("genRangePartition() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,441 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package o2m
+
+import (
+	"fmt"
+	"strings"
+)
+
+// 视图定义引用对象名称改写，按查询块 FROM 子句作用域确定字段所属表或视图
+// 表名、视图名按表名映射规则（含超长标识符截断规则）改写，字段名按所属对象字段名映射规则改写
+
+// viewNameRule 视图定义引用对象名称映射，KEY 源端表名/视图名
+type viewNameRule struct {
+	// 表名、视图名映射，未配置规则对象名称保持不变
+	TableNameRule map[string]string
+	// 表、视图字段，确定未限定字段所属对象
+	TableColumns map[string]map[string]struct{}
+	// 表、视图字段名映射，KEY 源端字段名
+	ColumnNameRule map[string]map[string]string
+}
+
+// nameScope 查询块 FROM 子句对象，KEY 别名（无别名以对象名为准），值为源端对象名
+// 派生表、CTE、表函数以及其他 schema 对象字段未知，值为空
+type nameScope struct {
+	sources map[string]string
+	// 无别名以对象名引用的对象，限定符同样按表名映射规则改写
+	bare map[string]bool
+}
+
+func (s *nameScope) hasUnknown() bool {
+	for _, src := range s.sources {
+		if src == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// tableName 对象目标端名称，未配置规则返回空
+func (r *viewNameRule) tableName(table string) string {
+	if val, ok := r.TableNameRule[table]; ok && !strings.EqualFold(val, table) {
+		return val
+	}
+	return ""
+}
+
+// columnName 对象字段目标端名称，未配置规则返回空
+func (r *viewNameRule) columnName(table, column string) string {
+	if val, ok := r.ColumnNameRule[table][column]; ok && !strings.EqualFold(val, column) {
+		return val
+	}
+	return ""
+}
+
+func (r *viewNameRule) hasColumn(table, column string) bool {
+	_, ok := r.TableColumns[table][column]
+	return ok
+}
+
+func isNameNode(n *sqlNode) bool {
+	return n.isKind(tokenWord) || n.isKind(tokenQuoted)
+}
+
+func nameText(n *sqlNode) string {
+	return strings.ToUpper(n.token.text)
+}
+
+func renameNode(n *sqlNode, name string) {
+	n.token = &sqlToken{kind: tokenQuoted, text: name}
+}
+
+func hasQueryBlock(nodes []*sqlNode) bool {
+	for _, n := range nodes {
+		if n.isWord("SELECT") {
+			return true
+		}
+	}
+	return false
+}
+
+// namePath 从 i 开始以 . 连接的名称节点下标
+func namePath(nodes []*sqlNode, i int) []int {
+	path := []int{i}
+	for j := i; j+2 < len(nodes) && nodes[j+1].isOperator(".") && isNameNode(nodes[j+2]); j += 2 {
+		path = append(path, j+2)
+	}
+	return path
+}
+
+// renameLevel 改写同一括号层级名称，查询语句按 WITH 子句以及集合运算拆分查询块
+// depth 为查询块嵌套层级，子查询单字段查询项字段名改写后保留源端字段名作为别名，外层引用无需改写
+func (t *oracleTranslator) renameLevel(nodes []*sqlNode, scopes []*nameScope, depth int) ([]*sqlNode, error) {
+	if !hasQueryBlock(nodes) {
+		return nodes, t.renameExpr(nodes, scopes, depth)
+	}
+
+	var (
+		result []*sqlNode
+		ctes   = make(map[string]bool)
+	)
+	start := 0
+	if len(nodes) > 0 && nodes[0].isWord("WITH") {
+		for start < len(nodes) && !nodes[start].isWord("SELECT") {
+			n := nodes[start]
+			if isNameNode(n) && start+2 < len(nodes) && nodes[start+1].isWord("AS") && nodes[start+2].isGroup() {
+				ctes[nameText(n)] = true
+			}
+			if n.isGroup() {
+				children, err := t.renameLevel(n.children, scopes, depth+1)
+				if err != nil {
+					return nil, err
+				}
+				n.children = children
+			}
+			start++
+		}
+		result = append(result, nodes[:start]...)
+	}
+
+	var block []*sqlNode
+	for _, n := range nodes[start:] {
+		if n.isWord("UNION", "INTERSECT", "MINUS") {
+			renamed, err := t.renameBlock(block, scopes, ctes, depth)
+			if err != nil {
+				return nil, err
+			}
+			result = append(append(result, renamed...), n)
+			block = nil
+			continue
+		}
+		block = append(block, n)
+	}
+	renamed, err := t.renameBlock(block, scopes, ctes, depth)
+	if err != nil {
+		return nil, err
+	}
+	return append(result, renamed...), nil
+}
+
+// renameBlock 改写单个查询块，FROM 子句对象作用域先于查询项以及条件建立
+func (t *oracleTranslator) renameBlock(nodes []*sqlNode, scopes []*nameScope, ctes map[string]bool, depth int) ([]*sqlNode, error) {
+	selectIdx, fromIdx, fromEnd := -1, -1, len(nodes)
+	for i, n := range nodes {
+		switch {
+		case selectIdx < 0 && n.isWord("SELECT"):
+			selectIdx = i
+		case selectIdx >= 0 && fromIdx < 0 && n.isWord("FROM"):
+			fromIdx = i
+		case fromIdx >= 0 && fromEnd == len(nodes) && n.isWord("WHERE", "GROUP", "HAVING", "ORDER", "CONNECT", "START"):
+			fromEnd = i
+		}
+	}
+	if selectIdx < 0 {
+		return nodes, t.renameExpr(nodes, scopes, depth)
+	}
+	if fromIdx < 0 {
+		fromIdx, fromEnd = len(nodes), len(nodes)
+	}
+
+	scope, err := t.renameFrom(nodes[fromIdx+1:fromEnd], scopes, ctes, depth)
+	if err != nil {
+		return nil, err
+	}
+	blockScopes := append(append([]*nameScope{}, scopes...), scope)
+
+	if err = t.renameExpr(nodes[:selectIdx], scopes, depth); err != nil {
+		return nil, err
+	}
+	items, err := t.renameSelectItems(nodes[selectIdx+1:fromIdx], blockScopes, depth)
+	if err != nil {
+		return nil, err
+	}
+	if err = t.renameExpr(nodes[fromEnd:], blockScopes, depth); err != nil {
+		return nil, err
+	}
+
+	var result []*sqlNode
+	result = append(result, nodes[:selectIdx+1]...)
+	result = append(result, items...)
+	return append(result, nodes[fromIdx:]...), nil
+}
+
+// renameFrom 建立 FROM 子句对象作用域并改写表名、视图名，连接条件以当前查询块作用域改写
+func (t *oracleTranslator) renameFrom(nodes []*sqlNode, scopes []*nameScope, ctes map[string]bool, depth int) (*nameScope, error) {
+	scope := &nameScope{sources: make(map[string]string), bare: make(map[string]bool)}
+	var conds [][]*sqlNode
+	expectTable := true
+	for i := 0; i < len(nodes); {
+		n := nodes[i]
+		switch {
+		case n.isKind(tokenComma) || n.isWord("JOIN"):
+			expectTable = true
+			i++
+		case n.isWord("LEFT", "RIGHT", "FULL", "INNER", "OUTER", "CROSS", "NATURAL"):
+			i++
+		case n.isWord("ON", "USING"):
+			j := i + 1
+			for j < len(nodes) && !nodes[j].isKind(tokenComma) && !nodes[j].isWord("JOIN", "LEFT", "RIGHT", "FULL", "INNER", "CROSS", "NATURAL") {
+				j++
+			}
+			conds = append(conds, nodes[i+1:j])
+			i = j
+		case expectTable:
+			var (
+				name, source string
+				j            = i + 1
+			)
+			if n.isGroup() {
+				// 派生表不可引用同层对象，以外层作用域改写
+				children, err := t.renameLevel(n.children, scopes, depth+1)
+				if err != nil {
+					return nil, err
+				}
+				n.children = children
+			} else if isNameNode(n) {
+				path := namePath(nodes, i)
+				j = path[len(path)-1] + 1
+				table := nodes[path[len(path)-1]]
+				name = nameText(table)
+				switch {
+				case j < len(nodes) && nodes[j].isGroup():
+					// 表函数
+					j++
+				case len(path) > 2 || (len(path) == 2 && !strings.EqualFold(nodes[path[0]].token.text, t.TargetSchema)):
+					// 其他 schema 对象
+				case len(path) == 1 && ctes[name]:
+				default:
+					source = name
+					if target := t.NameRule.tableName(name); target != "" {
+						renameNode(table, target)
+					}
+				}
+			}
+			if j < len(nodes) && nodes[j].isWord("AS") {
+				j++
+			}
+			if j < len(nodes) && isNameNode(nodes[j]) && !isBoundaryKeyword(nodes[j]) &&
+				!nodes[j].isWord("ON", "USING", "NATURAL", "PARTITION", "SAMPLE") {
+				scope.sources[nameText(nodes[j])] = source
+				j++
+			} else if name != "" {
+				scope.sources[name] = source
+				scope.bare[name] = true
+			}
+			expectTable = false
+			i = j
+		default:
+			if n.isGroup() {
+				children, err := t.renameLevel(n.children, scopes, depth+1)
+				if err != nil {
+					return nil, err
+				}
+				n.children = children
+			}
+			i++
+		}
+	}
+
+	blockScopes := append(append([]*nameScope{}, scopes...), scope)
+	for _, cond := range conds {
+		if err := t.renameExpr(cond, blockScopes, depth); err != nil {
+			return nil, err
+		}
+	}
+	return scope, nil
+}
+
+// renameSelectItems 改写查询项，子查询单字段查询项字段名改写后追加源端字段名别名
+func (t *oracleTranslator) renameSelectItems(nodes []*sqlNode, scopes []*nameScope, depth int) ([]*sqlNode, error) {
+	var result []*sqlNode
+	for len(nodes) > 0 && nodes[0].isWord("DISTINCT", "UNIQUE", "ALL") {
+		result = append(result, nodes[0])
+		nodes = nodes[1:]
+	}
+	for idx, item := range splitSQLNodes(nodes, func(n *sqlNode) bool { return n.isKind(tokenComma) }) {
+		if idx > 0 {
+			result = append(result, &sqlNode{token: &sqlToken{kind: tokenComma, text: ","}})
+		}
+		expr, hasAlias := item, false
+		switch {
+		case len(item) >= 2 && item[len(item)-2].isWord("AS"):
+			expr, hasAlias = item[:len(item)-2], true
+		case len(item) >= 2 && isNameNode(item[len(item)-1]) && !isBoundaryKeyword(item[len(item)-1]) &&
+			!item[len(item)-2].isKind(tokenOperator):
+			expr, hasAlias = item[:len(item)-1], true
+		}
+
+		// 子查询 * 查询项字段名改写后外层引用无法对应
+		if depth > 0 && len(expr) > 0 && expr[len(expr)-1].isOperator("*") && (len(expr) == 1 || expr[len(expr)-2].isOperator(".")) {
+			if table := t.renamedStarTable(expr, scopes); table != "" {
+				return nil, fmt.Errorf("unsupported construct [* over object %s with column name rule]", table)
+			}
+		}
+
+		var column string
+		if !hasAlias && depth > 0 && len(expr) > 0 && isNameNode(expr[0]) && 2*len(namePath(expr, 0))-1 == len(expr) {
+			column = expr[len(expr)-1].token.text
+		}
+		if err := t.renameExpr(expr, scopes, depth); err != nil {
+			return nil, err
+		}
+		result = append(result, item...)
+		if column != "" && !strings.EqualFold(expr[len(expr)-1].token.text, column) {
+			result = append(result, &sqlNode{token: &sqlToken{kind: tokenWord, text: "AS"}}, &sqlNode{token: &sqlToken{kind: tokenQuoted, text: column}})
+		}
+	}
+	return result, nil
+}
+
+// renamedStarTable * 查询项涉及对象存在字段名映射规则返回对象名
+func (t *oracleTranslator) renamedStarTable(expr []*sqlNode, scopes []*nameScope) string {
+	scope := scopes[len(scopes)-1]
+	for alias, src := range scope.sources {
+		if len(expr) > 1 && !strings.EqualFold(nameText(expr[len(expr)-3]), alias) {
+			continue
+		}
+		for col := range t.NameRule.ColumnNameRule[src] {
+			if t.NameRule.columnName(src, col) != "" {
+				return src
+			}
+		}
+	}
+	return ""
+}
+
+// renameExpr 改写表达式字段名，子查询以及括号分组递归改写
+func (t *oracleTranslator) renameExpr(nodes []*sqlNode, scopes []*nameScope, depth int) error {
+	for i := 0; i < len(nodes); i++ {
+		n := nodes[i]
+		if n.isGroup() {
+			children, err := t.renameLevel(n.children, scopes, depth+1)
+			if err != nil {
+				return err
+			}
+			n.children = children
+			continue
+		}
+		if !isNameNode(n) || (i > 0 && (nodes[i-1].isOperator(".") || nodes[i-1].isWord("AS"))) {
+			continue
+		}
+		path := namePath(nodes, i)
+		last := path[len(path)-1]
+		i = last
+		// 函数调用
+		if last+1 < len(nodes) && nodes[last+1].isGroup() {
+			continue
+		}
+		switch len(path) {
+		case 1:
+			if isBoundaryKeyword(n) {
+				continue
+			}
+			target, err := t.resolveColumn(nameText(n), scopes)
+			if err != nil {
+				return err
+			}
+			if target != "" {
+				renameNode(n, target)
+			}
+		case 2:
+			t.renameQualifiedColumn(nodes[path[0]], nodes[path[1]], scopes)
+		case 3:
+			if strings.EqualFold(nodes[path[0]].token.text, t.TargetSchema) {
+				t.renameQualifiedColumn(nodes[path[1]], nodes[path[2]], scopes)
+			}
+		}
+	}
+	return nil
+}
+
+// resolveColumn 未限定字段由内向外查找作用域内包含该字段的对象，返回目标端字段名，无需改写返回空
+// 作用域存在字段未知对象且外层对象字段存在映射规则，无法确定字段所属对象返回错误
+func (t *oracleTranslator) resolveColumn(column string, scopes []*nameScope) (string, error) {
+	for s := len(scopes) - 1; s >= 0; s-- {
+		targets := make(map[string]struct{})
+		matched := false
+		for _, src := range scopes[s].sources {
+			if src == "" || !t.NameRule.hasColumn(src, column) {
+				continue
+			}
+			matched = true
+			targets[t.NameRule.columnName(src, column)] = struct{}{}
+		}
+		if matched {
+			if len(targets) > 1 {
+				return "", fmt.Errorf("unsupported construct [column %s is ambiguous with column name rule]", column)
+			}
+			for target := range targets {
+				return target, nil
+			}
+		}
+		if scopes[s].hasUnknown() {
+			for o := s - 1; o >= 0; o-- {
+				for _, src := range scopes[o].sources {
+					if src != "" && t.NameRule.hasColumn(src, column) && t.NameRule.columnName(src, column) != "" {
+						return "", fmt.Errorf("unsupported construct [column %s can't resolve with column name rule]", column)
+					}
+				}
+			}
+			return "", nil
+		}
+	}
+	return "", nil
+}
+
+// renameQualifiedColumn 限定字段按限定符对应对象改写字段名，以对象名限定的限定符同时改写表名
+func (t *oracleTranslator) renameQualifiedColumn(qualifier, column *sqlNode, scopes []*nameScope) {
+	q := nameText(qualifier)
+	for s := len(scopes) - 1; s >= 0; s-- {
+		src, ok := scopes[s].sources[q]
+		if !ok {
+			continue
+		}
+		if src == "" {
+			return
+		}
+		if scopes[s].bare[q] {
+			if target := t.NameRule.tableName(src); target != "" {
+				renameNode(qualifier, target)
+			}
+		}
+		if target := t.NameRule.columnName(src, nameText(column)); target != "" {
+			renameNode(column, target)
+		}
+		return
+	}
+}
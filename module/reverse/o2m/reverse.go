@@ -37,6 +37,8 @@ type Reverse struct {
 	Mysql  *mysql.MySQL
 	Oracle *oracle.Oracle
 	MetaDB *meta.Meta
+	// 下游已存在表结构对比，为空代表下游已存在表按 CREATE TABLE 输出
+	Differ reverse.Differ
 }
 
 func NewReverse(ctx context.Context, cfg *config.Config) (*Reverse, error) {
@@ -74,7 +76,7 @@ func (r *Reverse) Reverse() error {
 		zap.String("schema", r.Cfg.OracleConfig.SchemaName))
 
 	// 获取配置文件待同步表列表
-	exporters, err := filterCFGTable(r.Cfg, r.Oracle)
+	exporters, err := FilterCFGTable(r.Cfg, r.Oracle)
 	if err != nil {
 		return err
 	}
@@ -129,14 +131,19 @@ func (r *Reverse) Reverse() error {
 	}
 
 	// 筛选过滤可能不支持的表类型
-	partitionTables, temporaryTables, clusteredTables, materializedView, exporterTables, err := FilterOracleCompatibleTable(r.Cfg, r.Oracle, exporters)
+	_, temporaryTables, clusteredTables, materializedView, exporterTables, err := FilterOracleCompatibleTable(r.Cfg, r.Oracle, exporters)
 	if err != nil {
 		return err
 	}
 
+	// 超长标识符处理以及标识符冲突检测，截断映射规则需先于获取规则写入
+	if err = r.AdjustIdentifier(exporterTables); err != nil {
+		return err
+	}
+
 	// 获取规则
 	ruleTime := time.Now()
-	tableNameRuleMap, tableColumnRuleMap, tableDefaultRuleMap, err := IChanger(&Change{
+	tableNameRuleMap, tableColumnRuleMap, tableDefaultRuleMap, tableColumnNameRuleMap, err := IChanger(&Change{
 		Ctx:              r.Ctx,
 		DBTypeS:          r.Cfg.DBTypeS,
 		DBTypeT:          r.Cfg.DBTypeT,
@@ -156,13 +163,13 @@ func (r *Reverse) Reverse() error {
 		zap.String("cost", time.Now().Sub(ruleTime).String()))
 
 	// 获取 reverse 表任务列表
-	tables, err := GenReverseTableTask(r, tableNameRuleMap, tableColumnRuleMap, tableDefaultRuleMap, oracleDBVersion, oracleCollation, exporterTables, nlsSort, nlsComp)
+	tables, err := GenReverseTableTask(r, tableNameRuleMap, tableColumnRuleMap, tableDefaultRuleMap, tableColumnNameRuleMap, oracleDBVersion, oracleCollation, exporterTables, nlsSort, nlsComp)
 	if err != nil {
 		return err
 	}
 
 	// file writer
-	f, err := reverse.NewWriter(r.Cfg, r.Mysql, r.Oracle)
+	f, err := reverse.NewWriter(r.Cfg, r.Mysql, r.Oracle, nil)
 	if err != nil {
 		return err
 	}
@@ -174,19 +181,136 @@ func (r *Reverse) Reverse() error {
 		return err
 	}
 
+	// 序列转换
+	err = GenCreateSequence(f, r.Oracle,
+		common.StringUPPER(r.Cfg.OracleConfig.SchemaName), common.StringUPPER(r.Cfg.MySQLConfig.SchemaName), common.StringUPPER(r.Cfg.MySQLConfig.DBType), r.Cfg.ReverseConfig.IdentifierPolicy, r.Cfg.ReverseConfig.DirectWrite)
+	if err != nil {
+		return err
+	}
+
 	// 表类型不兼容项输出
-	err = GenCompatibilityTable(f, common.StringUPPER(r.Cfg.OracleConfig.SchemaName), partitionTables, temporaryTables, clusteredTables, materializedView)
+	err = GenCompatibilityTable(f, common.StringUPPER(r.Cfg.OracleConfig.SchemaName), temporaryTables, clusteredTables, materializedView)
 	if err != nil {
 		return err
 	}
 
+	// 外键依赖分层，父表所在层先于子表创建
+	fkRelations, err := r.Oracle.GetOracleSchemaForeignKeyRelation(common.StringUPPER(r.Cfg.OracleConfig.SchemaName))
+	if err != nil {
+		return err
+	}
+	tableLayers, fkCycles := sortTableByForeignKey(tables, fkRelations)
+	err = GenForeignKeyCycle(f, common.StringUPPER(r.Cfg.OracleConfig.SchemaName), fkCycles)
+	if err != nil {
+		return err
+	}
+
+	// 下游已存在表，diff-existing-table 开启只输出收敛 ALTER 语句
+	existTables := make(map[string]struct{})
+	if r.Differ != nil {
+		mysqlTables, err := r.Mysql.GetMySQLTable(common.StringUPPER(r.Cfg.MySQLConfig.SchemaName))
+		if err != nil {
+			return err
+		}
+		for _, t := range mysqlTables {
+			existTables[common.StringUPPER(t)] = struct{}{}
+		}
+	}
+
 	// 表转换
+	for _, layer := range tableLayers {
+		if err = r.reverseTableLayer(f, layer, existTables); err != nil {
+			return err
+		}
+	}
+
+	// 视图转换，依赖表结构，表转换之后创建
+	err = GenCreateView(f, r.Oracle,
+		common.StringUPPER(r.Cfg.OracleConfig.SchemaName), common.StringUPPER(r.Cfg.MySQLConfig.SchemaName), tableNameRuleMap, tableColumnNameRuleMap, r.Cfg.ReverseConfig.IdentifierPolicy, r.Cfg.ReverseConfig.DirectWrite)
+	if err != nil {
+		return err
+	}
+
+	err = f.Close()
+	if err != nil {
+		return err
+	}
+
+	errTotals, err = meta.NewErrorLogDetailModel(r.MetaDB).CountsErrorLogBySchema(r.Ctx, &meta.ErrorLogDetail{
+		DBTypeS:     r.Cfg.DBTypeS,
+		DBTypeT:     r.Cfg.DBTypeT,
+		SchemaNameS: common.StringUPPER(r.Cfg.OracleConfig.SchemaName),
+		TaskMode:    r.Cfg.TaskMode,
+	})
+	if err != nil {
+		return err
+	}
+
+	endTime := time.Now()
+	if !r.Cfg.ReverseConfig.DirectWrite {
+		zap.L().Info("reverse", zap.String("create table and index output", filepath.Join(r.Cfg.ReverseConfig.DDLReverseDir,
+			fmt.Sprintf("reverse_%s.sql", r.Cfg.OracleConfig.SchemaName))))
+	}
+	zap.L().Info("compatibility", zap.String("maybe exist compatibility output", filepath.Join(r.Cfg.ReverseConfig.DDLCompatibleDir,
+		fmt.Sprintf("compatibility_%s.sql", r.Cfg.OracleConfig.SchemaName))))
+	if f.FFile != nil {
+		zap.L().Info("foreign key", zap.String("post-load foreign key output", filepath.Join(r.Cfg.ReverseConfig.DDLReverseDir,
+			fmt.Sprintf("foreign_key_%s.sql", r.Cfg.OracleConfig.SchemaName))))
+	}
+	if errTotals == 0 {
+		zap.L().Info("reverse table oracle to mysql finished",
+			zap.Int("table totals", len(exporters)),
+			zap.Int("reverse totals", len(tables)),
+			zap.Int("reverse success", len(tables)),
+			zap.Int64("reverse failed", errTotals),
+			zap.String("cost", endTime.Sub(startTime).String()))
+	} else {
+		zap.L().Warn("reverse table oracle to mysql finished",
+			zap.Int("table totals", len(exporters)),
+			zap.Int("reverse totals", len(tables)),
+			zap.Int("reverse success", len(tables)-int(errTotals)),
+			zap.Int64("reverse failed", errTotals),
+			zap.String("failed tips", "failed detail, please see table [error_log_detail]"),
+			zap.String("cost", endTime.Sub(startTime).String()))
+	}
+	return nil
+}
+
+// reverseTableLayer 同层表并发转换，下游已存在表对比表结构输出收敛语句
+func (r *Reverse) reverseTableLayer(f *reverse.Write, tables []*Table, existTables map[string]struct{}) error {
 	g := &errgroup.Group{}
 	g.SetLimit(r.Cfg.ReverseConfig.ReverseThreads)
 
 	for _, table := range tables {
 		t := table
 		g.Go(func() error {
+			if _, ok := existTables[common.StringUPPER(t.TargetTableName)]; ok {
+				err := r.diffTable(f, t)
+				if err != nil {
+					if err = meta.NewErrorLogDetailModel(r.MetaDB).CreateErrorLog(r.Ctx, &meta.ErrorLogDetail{
+						DBTypeS:     r.Cfg.DBTypeS,
+						DBTypeT:     r.Cfg.DBTypeT,
+						SchemaNameS: t.SourceSchemaName,
+						TableNameS:  t.SourceTableName,
+						SchemaNameT: t.TargetSchemaName,
+						TableNameT:  t.TargetTableName,
+						TaskMode:    r.Cfg.TaskMode,
+						TaskStatus:  "Failed",
+						InfoDetail:  t.String(),
+						ErrorDetail: err.Error(),
+					}); err != nil {
+						zap.L().Error("reverse table r.Oracle to mysql failed",
+							zap.String("schema", t.SourceSchemaName),
+							zap.String("table", t.SourceTableName),
+							zap.Error(
+								fmt.Errorf("diff table task failed, detail see [error_log_detail], please rerunning")))
+
+						return fmt.Errorf("diff table task failed, detail see [error_log_detail], please rerunning, error: %v", err)
+					}
+				}
+				return nil
+			}
+
 			rule, err := IReader(t)
 			if err != nil {
 				if err = meta.NewErrorLogDetailModel(r.MetaDB).CreateErrorLog(r.Ctx, &meta.ErrorLogDetail{
@@ -261,51 +385,56 @@ func (r *Reverse) Reverse() error {
 				return nil
 			}
 
-			return nil
+			return r.GenIndexGeneratedColumn(ddl)
 		})
 	}
 
-	if err = g.Wait(); err != nil {
-		return err
-	}
+	return g.Wait()
+}
 
-	err = f.Close()
-	if err != nil {
-		return err
+// GenIndexGeneratedColumn 函数索引 stored generated column 写入元数据库，重新 reverse 以当前记录为准
+func (r *Reverse) GenIndexGeneratedColumn(ddl *DDL) error {
+	var generatedColumns []meta.IndexGeneratedColumn
+	for _, col := range ddl.IndexGeneratedColumns {
+		col.DBTypeS = r.Cfg.DBTypeS
+		col.DBTypeT = r.Cfg.DBTypeT
+		generatedColumns = append(generatedColumns, col)
 	}
-
-	errTotals, err = meta.NewErrorLogDetailModel(r.MetaDB).CountsErrorLogBySchema(r.Ctx, &meta.ErrorLogDetail{
+	return meta.NewIndexGeneratedColumnModel(r.MetaDB).ResetTableIndexGeneratedColumn(r.Ctx, &meta.IndexGeneratedColumn{
 		DBTypeS:     r.Cfg.DBTypeS,
 		DBTypeT:     r.Cfg.DBTypeT,
-		SchemaNameS: common.StringUPPER(r.Cfg.OracleConfig.SchemaName),
-		TaskMode:    r.Cfg.TaskMode,
-	})
+		SchemaNameS: ddl.SourceSchemaName,
+		TableNameS:  ddl.SourceTableName,
+	}, generatedColumns)
+}
+
+// diffTable 下游已存在表，对比上下游表结构，只输出收敛 ALTER 语句，不重建表
+func (r *Reverse) diffTable(f *reverse.Write, t *Table) error {
+	startTime := time.Now()
+	alterSQL, compatibleSQL, err := r.Differ.Diff(t)
 	if err != nil {
 		return err
 	}
-
-	endTime := time.Now()
-	if !r.Cfg.ReverseConfig.DirectWrite {
-		zap.L().Info("reverse", zap.String("create table and index output", filepath.Join(r.Cfg.ReverseConfig.DDLReverseDir,
-			fmt.Sprintf("reverse_%s.sql", r.Cfg.OracleConfig.SchemaName))))
+	if alterSQL != "" {
+		if r.Cfg.ReverseConfig.DirectWrite {
+			if err = f.RWriteDB(alterSQL); err != nil {
+				return err
+			}
+		} else {
+			if _, err = f.RWriteFile(alterSQL + "\n"); err != nil {
+				return err
+			}
+		}
 	}
-	zap.L().Info("compatibility", zap.String("maybe exist compatibility output", filepath.Join(r.Cfg.ReverseConfig.DDLCompatibleDir,
-		fmt.Sprintf("compatibility_%s.sql", r.Cfg.OracleConfig.SchemaName))))
-	if errTotals == 0 {
-		zap.L().Info("reverse table oracle to mysql finished",
-			zap.Int("table totals", len(exporters)),
-			zap.Int("reverse totals", len(tables)),
-			zap.Int("reverse success", len(tables)),
-			zap.Int64("reverse failed", errTotals),
-			zap.String("cost", endTime.Sub(startTime).String()))
-	} else {
-		zap.L().Warn("reverse table oracle to mysql finished",
-			zap.Int("table totals", len(exporters)),
-			zap.Int("reverse totals", len(tables)),
-			zap.Int("reverse success", len(tables)-int(errTotals)),
-			zap.Int64("reverse failed", errTotals),
-			zap.String("failed tips", "failed detail, please see table [error_log_detail]"),
-			zap.String("cost", endTime.Sub(startTime).String()))
+	if compatibleSQL != "" {
+		if _, err = f.CWriteFile(compatibleSQL + "\n"); err != nil {
+			return err
+		}
 	}
+	zap.L().Info("diff oracle table and mysql exist table finished",
+		zap.String("oracle table", fmt.Sprintf("%s.%s", t.SourceSchemaName, t.SourceTableName)),
+		zap.String("mysql table", fmt.Sprintf("%s.%s", t.TargetSchemaName, t.TargetTableName)),
+		zap.Bool("table converged", alterSQL == ""),
+		zap.String("cost", time.Now().Sub(startTime).String()))
 	return nil
 }
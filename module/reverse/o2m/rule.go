@@ -41,6 +41,9 @@ type Info struct {
 	TableCommentINFO  []map[string]string `json:"table_comment_info"`
 	TableColumnINFO   []map[string]string `json:"table_column_info"`
 	ColumnCommentINFO []map[string]string `json:"column_comment_info"`
+	PartitionKeyINFO  []map[string]string `json:"partition_key_info"`
+	PartitionINFO     []map[string]string `json:"partition_info"`
+	IdentityINFO      []map[string]string `json:"identity_info"`
 }
 
 func (r *Rule) GenCreateTableDDL() (interface{}, error) {
@@ -59,11 +62,15 @@ func (r *Rule) GenCreateTableDDL() (interface{}, error) {
 	if err != nil {
 		return nil, err
 	}
+	// 函数索引 stored generated column
+	indexColumns, indexGeneratedColumns := r.GenTableFunctionIndexColumn()
+	tableColumns = append(tableColumns, indexColumns...)
 
 	tableKeys, compatibleDDL, err = r.GenTableKeys()
 	if err != nil {
 		return nil, err
 	}
+	compatibleDDL = append(compatibleDDL, r.GenTableColumnCompatible()...)
 
 	tablePrefix = fmt.Sprintf("CREATE TABLE `%s`.`%s`", targetSchema, targetTable)
 
@@ -82,6 +89,11 @@ func (r *Rule) GenCreateTableDDL() (interface{}, error) {
 		return nil, err
 	}
 
+	tablePartition, partitionReason, err := r.GenTablePartition()
+	if err != nil {
+		return nil, err
+	}
+
 	return &DDL{
 		SourceSchemaName:   r.SourceSchemaName,
 		SourceTableName:    r.SourceTableName,
@@ -98,6 +110,11 @@ func (r *Rule) GenCreateTableDDL() (interface{}, error) {
 		TableCheckKeys:     checkKeys,
 		TableForeignKeys:   foreignKeys,
 		TableCompatibleDDL: compatibleDDL,
+		TablePartition:     tablePartition,
+		PartitionReason:    partitionReason,
+		ForeignKeyPostLoad: r.ForeignKeyPostLoad,
+
+		IndexGeneratedColumns: indexGeneratedColumns,
 	}, nil
 }
 
@@ -169,7 +186,7 @@ func (r *Rule) GenTableSuffix() (string, error) {
 
 	if len(r.PrimaryKeyINFO) > 0 {
 		for _, col := range strings.Split(r.PrimaryKeyINFO[0]["COLUMN_LIST"], ",") {
-			primaryColumns = append(primaryColumns, fmt.Sprintf("`%s`", col))
+			primaryColumns = append(primaryColumns, fmt.Sprintf("`%s`", r.GenColumnName(col)))
 		}
 	}
 
@@ -348,7 +365,7 @@ func (r *Rule) GenTablePrimaryKey() (primaryKeys []string, err error) {
 	if len(r.PrimaryKeyINFO) > 0 {
 		var primaryColumns []string
 		for _, col := range strings.Split(r.PrimaryKeyINFO[0]["COLUMN_LIST"], ",") {
-			primaryColumns = append(primaryColumns, fmt.Sprintf("`%s`", col))
+			primaryColumns = append(primaryColumns, fmt.Sprintf("`%s`", r.GenColumnName(col)))
 		}
 		pk := fmt.Sprintf("PRIMARY KEY (%s)", strings.ToUpper(strings.Join(primaryColumns, ",")))
 		primaryKeys = append(primaryKeys, pk)
@@ -362,10 +379,10 @@ func (r *Rule) GenTableUniqueKey() (uniqueKeys []string, err error) {
 		for _, rowUKCol := range r.UniqueKeyINFO {
 			var ukArr []string
 			for _, col := range strings.Split(rowUKCol["COLUMN_LIST"], ",") {
-				ukArr = append(ukArr, fmt.Sprintf("`%s`", col))
+				ukArr = append(ukArr, fmt.Sprintf("`%s`", r.GenColumnName(col)))
 			}
 			uk := fmt.Sprintf("UNIQUE KEY `%s` (%s)",
-				strings.ToUpper(rowUKCol["CONSTRAINT_NAME"]), strings.ToUpper(strings.Join(ukArr, ",")))
+				r.GenIdentifierName(rowUKCol["CONSTRAINT_NAME"]), strings.ToUpper(strings.Join(ukArr, ",")))
 
 			uniqueKeys = append(uniqueKeys, uk)
 		}
@@ -376,9 +393,18 @@ func (r *Rule) GenTableUniqueKey() (uniqueKeys []string, err error) {
 func (r *Rule) GenTableForeignKey() (foreignKeys []string, err error) {
 	if len(r.ForeignKeyINFO) > 0 {
 		for _, rowFKCol := range r.ForeignKeyINFO {
+			// 同 schema 父表引用目标端 schema
+			if strings.EqualFold(rowFKCol["R_OWNER"], r.SourceSchemaName) {
+				rowFKCol["R_OWNER"] = r.GenSchemaName()
+				rowFKCol["RCOLUMN_LIST"] = common.StringColumnListRule(rowFKCol["RCOLUMN_LIST"], r.SchemaColumnNameRule[common.StringUPPER(rowFKCol["RTABLE_NAME"])])
+				if val, ok := r.SchemaTableNameRule[common.StringUPPER(rowFKCol["RTABLE_NAME"])]; ok {
+					rowFKCol["RTABLE_NAME"] = val
+				}
+			}
+			rowFKCol["COLUMN_LIST"] = common.StringColumnListRule(rowFKCol["COLUMN_LIST"], r.TableColumnNameRule)
 			if rowFKCol["DELETE_RULE"] == "" || rowFKCol["DELETE_RULE"] == "NO ACTION" {
 				fk := fmt.Sprintf("CONSTRAINT `%s` FOREIGN KEY (%s) REFERENCES `%s`.`%s` (%s)",
-					strings.ToUpper(rowFKCol["CONSTRAINT_NAME"]),
+					r.GenIdentifierName(rowFKCol["CONSTRAINT_NAME"]),
 					strings.ToUpper(rowFKCol["COLUMN_LIST"]),
 					strings.ToUpper(rowFKCol["R_OWNER"]),
 					strings.ToUpper(rowFKCol["RTABLE_NAME"]),
@@ -387,7 +413,7 @@ func (r *Rule) GenTableForeignKey() (foreignKeys []string, err error) {
 			}
 			if rowFKCol["DELETE_RULE"] == "CASCADE" {
 				fk := fmt.Sprintf("CONSTRAINT `%s` FOREIGN KEY (%s) REFERENCES `%s`.`%s`(%s) ON DELETE CASCADE",
-					strings.ToUpper(rowFKCol["CONSTRAINT_NAME"]),
+					r.GenIdentifierName(rowFKCol["CONSTRAINT_NAME"]),
 					strings.ToUpper(rowFKCol["COLUMN_LIST"]),
 					strings.ToUpper(rowFKCol["R_OWNER"]),
 					strings.ToUpper(rowFKCol["RTABLE_NAME"]),
@@ -396,7 +422,7 @@ func (r *Rule) GenTableForeignKey() (foreignKeys []string, err error) {
 			}
 			if rowFKCol["DELETE_RULE"] == "SET NULL" {
 				fk := fmt.Sprintf("CONSTRAINT `%s` FOREIGN KEY(%s) REFERENCES `%s`.`%s`(%s) ON DELETE SET NULL",
-					strings.ToUpper(rowFKCol["CONSTRAINT_NAME"]),
+					r.GenIdentifierName(rowFKCol["CONSTRAINT_NAME"]),
 					strings.ToUpper(rowFKCol["COLUMN_LIST"]),
 					strings.ToUpper(rowFKCol["R_OWNER"]),
 					strings.ToUpper(rowFKCol["RTABLE_NAME"]),
@@ -429,13 +455,18 @@ func (r *Rule) GenTableCheckKey() (checkKeys []string, err error) {
 		}
 
 		for _, rowCKCol := range r.CheckKeyINFO {
+			// 字段名映射
+			rowCKCol["SEARCH_CONDITION"], err = RenameOracleExprColumn(rowCKCol["SEARCH_CONDITION"], r.TableColumnNameRule)
+			if err != nil {
+				return checkKeys, fmt.Errorf("oracle table [%s.%s] check constraint [%s] column name rule failed: %v", r.SourceSchemaName, r.SourceTableName, rowCKCol["CONSTRAINT_NAME"], err)
+			}
 			// 排除非空约束检查
 			s := strings.TrimSpace(rowCKCol["SEARCH_CONDITION"])
 
 			if !reg.MatchString(s) {
 				if !matchRex.MatchString(s) {
 					checkKeys = append(checkKeys, fmt.Sprintf("CONSTRAINT `%s` CHECK (%s)",
-						strings.ToUpper(rowCKCol["CONSTRAINT_NAME"]),
+						r.GenIdentifierName(rowCKCol["CONSTRAINT_NAME"]),
 						rowCKCol["SEARCH_CONDITION"]))
 				}
 			} else {
@@ -481,7 +512,7 @@ func (r *Rule) GenTableCheckKey() (checkKeys []string, err error) {
 				}
 
 				checkKeys = append(checkKeys, fmt.Sprintf("CONSTRAINT `%s` CHECK (%s)",
-					strings.ToUpper(rowCKCol["CONSTRAINT_NAME"]),
+					r.GenIdentifierName(rowCKCol["CONSTRAINT_NAME"]),
 					strings.Join(d, " ")))
 			}
 		}
@@ -498,10 +529,10 @@ func (r *Rule) GenTableUniqueIndex() (uniqueIndexes []string, compatibilityIndex
 				case "NORMAL":
 					var uniqueIndex []string
 					for _, col := range strings.Split(idxMeta["COLUMN_LIST"], ",") {
-						uniqueIndex = append(uniqueIndex, fmt.Sprintf("`%s`", col))
+						uniqueIndex = append(uniqueIndex, fmt.Sprintf("`%s`", r.GenColumnName(col)))
 					}
 
-					uniqueIDX := fmt.Sprintf("UNIQUE INDEX `%s` (%s)", strings.ToUpper(idxMeta["INDEX_NAME"]), strings.Join(uniqueIndex, ","))
+					uniqueIDX := fmt.Sprintf("UNIQUE INDEX `%s` (%s)", r.GenIdentifierName(idxMeta["INDEX_NAME"]), strings.Join(uniqueIndex, ","))
 
 					uniqueIndexes = append(uniqueIndexes, uniqueIDX)
 
@@ -516,9 +547,27 @@ func (r *Rule) GenTableUniqueIndex() (uniqueIndexes []string, compatibilityIndex
 					continue
 
 				case "FUNCTION-BASED NORMAL":
-					sql := fmt.Sprintf("CREATE UNIQUE INDEX `%s` ON `%s`.`%s` (%s);",
-						strings.ToUpper(idxMeta["INDEX_NAME"]), r.TargetSchemaName, r.TargetTableName,
-						idxMeta["COLUMN_LIST"])
+					fi := r.genFunctionIndex(idxMeta)
+					if fi.Reason == "" {
+						uniqueIDX := fmt.Sprintf("UNIQUE INDEX `%s` (%s)", r.GenIdentifierName(idxMeta["INDEX_NAME"]), strings.Join(fi.KeyParts, ","))
+
+						uniqueIndexes = append(uniqueIndexes, uniqueIDX)
+
+						zap.L().Info("reverse unique index",
+							zap.String("schema", r.SourceSchemaName),
+							zap.String("table", idxMeta["TABLE_NAME"]),
+							zap.String("index name", idxMeta["INDEX_NAME"]),
+							zap.String("index type", idxMeta["INDEX_TYPE"]),
+							zap.String("index column list", idxMeta["COLUMN_LIST"]),
+							zap.Strings("generated columns", fi.GeneratedColumns),
+							zap.String("unique index info", uniqueIDX))
+
+						continue
+					}
+
+					sql := fmt.Sprintf("CREATE UNIQUE INDEX `%s` ON `%s`.`%s` (%s); -- %s",
+						r.GenIdentifierName(idxMeta["INDEX_NAME"]), r.TargetSchemaName, r.TargetTableName,
+						idxMeta["COLUMN_LIST"], fi.Reason)
 
 					compatibilityIndexSQL = append(compatibilityIndexSQL, sql)
 
@@ -529,6 +578,7 @@ func (r *Rule) GenTableUniqueIndex() (uniqueIndexes []string, compatibilityIndex
 						zap.String("index type", idxMeta["INDEX_TYPE"]),
 						zap.String("index column list", idxMeta["COLUMN_LIST"]),
 						zap.String("create unique index sql", sql),
+						zap.String("reason", fi.Reason),
 						zap.String("warn", "mysql not support"))
 
 					continue
@@ -568,10 +618,10 @@ func (r *Rule) GenTableNormalIndex() (normalIndexes []string, compatibilityIndex
 				case "NORMAL":
 					var normalIndex []string
 					for _, col := range strings.Split(idxMeta["COLUMN_LIST"], ",") {
-						normalIndex = append(normalIndex, fmt.Sprintf("`%s`", col))
+						normalIndex = append(normalIndex, fmt.Sprintf("`%s`", r.GenColumnName(col)))
 					}
 
-					keyIndex := fmt.Sprintf("KEY `%s` (%s)", strings.ToUpper(idxMeta["INDEX_NAME"]), strings.Join(normalIndex, ","))
+					keyIndex := fmt.Sprintf("KEY `%s` (%s)", r.GenIdentifierName(idxMeta["INDEX_NAME"]), strings.Join(normalIndex, ","))
 
 					normalIndexes = append(normalIndexes, keyIndex)
 
@@ -586,9 +636,27 @@ func (r *Rule) GenTableNormalIndex() (normalIndexes []string, compatibilityIndex
 					continue
 
 				case "FUNCTION-BASED NORMAL":
-					sql := fmt.Sprintf("CREATE INDEX %s ON %s.%s (%s);",
-						strings.ToUpper(idxMeta["INDEX_NAME"]), r.TargetSchemaName, r.TargetTableName,
-						idxMeta["COLUMN_LIST"])
+					fi := r.genFunctionIndex(idxMeta)
+					if fi.Reason == "" {
+						keyIndex := fmt.Sprintf("KEY `%s` (%s)", r.GenIdentifierName(idxMeta["INDEX_NAME"]), strings.Join(fi.KeyParts, ","))
+
+						normalIndexes = append(normalIndexes, keyIndex)
+
+						zap.L().Info("reverse normal index",
+							zap.String("schema", r.SourceSchemaName),
+							zap.String("table", idxMeta["TABLE_NAME"]),
+							zap.String("index name", idxMeta["INDEX_NAME"]),
+							zap.String("index type", idxMeta["INDEX_TYPE"]),
+							zap.String("index column list", idxMeta["COLUMN_LIST"]),
+							zap.Strings("generated columns", fi.GeneratedColumns),
+							zap.String("key index info", keyIndex))
+
+						continue
+					}
+
+					sql := fmt.Sprintf("CREATE INDEX %s ON %s.%s (%s); -- %s",
+						r.GenIdentifierName(idxMeta["INDEX_NAME"]), r.TargetSchemaName, r.TargetTableName,
+						idxMeta["COLUMN_LIST"], fi.Reason)
 
 					compatibilityIndexSQL = append(compatibilityIndexSQL, sql)
 
@@ -599,13 +667,14 @@ func (r *Rule) GenTableNormalIndex() (normalIndexes []string, compatibilityIndex
 						zap.String("index type", idxMeta["INDEX_TYPE"]),
 						zap.String("index column list", idxMeta["COLUMN_LIST"]),
 						zap.String("create normal index sql", sql),
+						zap.String("reason", fi.Reason),
 						zap.String("warn", "mysql not support"))
 					continue
 
 				case "BITMAP":
 					sql := fmt.Sprintf("CREATE BITMAP INDEX %s ON %s.%s (%s);",
-						strings.ToUpper(idxMeta["INDEX_NAME"]), r.TargetSchemaName, r.TargetTableName,
-						idxMeta["COLUMN_LIST"])
+						r.GenIdentifierName(idxMeta["INDEX_NAME"]), r.TargetSchemaName, r.TargetTableName,
+						common.StringColumnListRule(idxMeta["COLUMN_LIST"], r.TableColumnNameRule))
 
 					compatibilityIndexSQL = append(compatibilityIndexSQL, sql)
 
@@ -621,8 +690,8 @@ func (r *Rule) GenTableNormalIndex() (normalIndexes []string, compatibilityIndex
 
 				case "FUNCTION-BASED BITMAP":
 					sql := fmt.Sprintf("CREATE BITMAP INDEX %s ON %s.%s (%s);",
-						strings.ToUpper(idxMeta["INDEX_NAME"]), r.TargetSchemaName, r.TargetTableName,
-						idxMeta["COLUMN_LIST"])
+						r.GenIdentifierName(idxMeta["INDEX_NAME"]), r.TargetSchemaName, r.TargetTableName,
+						common.StringColumnListRule(idxMeta["COLUMN_LIST"], r.TableColumnNameRule))
 
 					compatibilityIndexSQL = append(compatibilityIndexSQL, sql)
 
@@ -638,8 +707,8 @@ func (r *Rule) GenTableNormalIndex() (normalIndexes []string, compatibilityIndex
 
 				case "DOMAIN":
 					sql := fmt.Sprintf("CREATE INDEX %s ON %s.%s (%s) INDEXTYPE IS %s.%s PARAMETERS ('%s');",
-						strings.ToUpper(idxMeta["INDEX_NAME"]), r.TargetSchemaName, r.TargetTableName,
-						idxMeta["COLUMN_LIST"],
+						r.GenIdentifierName(idxMeta["INDEX_NAME"]), r.TargetSchemaName, r.TargetTableName,
+						common.StringColumnListRule(idxMeta["COLUMN_LIST"], r.TableColumnNameRule),
 						strings.ToUpper(idxMeta["ITYP_OWNER"]),
 						strings.ToUpper(idxMeta["ITYP_NAME"]),
 						idxMeta["PARAMETERS"])
@@ -707,6 +776,7 @@ func (r *Rule) GenTableColumn() (tableColumns []string, err error) {
 			dataDefault     string
 			columnType      string
 		)
+		columnName := r.GenColumnName(rowCol["COLUMN_NAME"])
 		if r.OracleCollation {
 			// 字段排序规则检查
 			if collationMapVal, ok := common.OracleCollationMap[strings.ToUpper(rowCol["COLLATION"])]; ok {
@@ -747,24 +817,52 @@ func (r *Rule) GenTableColumn() (tableColumns []string, err error) {
 			return tableColumns, fmt.Errorf("oracle table [%s.%s] column [%s] default value isn't exist", r.SourceSchemaName, r.SourceTableName, rowCol["COLUMN_NAME"])
 		}
 
+		// identity column 默认值为系统序列 nextval，mysql 不兼容，按策略转换自增属性
+		if identity, isIdentity := r.GenColumnIdentity(rowCol["COLUMN_NAME"], columnType); isIdentity {
+			dataDefault = ""
+			if !strings.EqualFold(identity, "") {
+				columnType = fmt.Sprintf("%s %s", columnType, identity)
+			}
+		}
+
+		// 虚拟列 DATA_DEFAULT 为列表达式，转换 generated column；表达式无法改写按普通可空字段创建并输出不兼容项，全量/csv 按普通字段抽取数据
+		if strings.EqualFold(rowCol["VIRTUAL_COLUMN"], "YES") {
+			dataDefault = ""
+			if generated, reason := r.genVirtualColumn(rowCol); reason == "" {
+				if columnCollation != "" {
+					columnType = fmt.Sprintf("%s COLLATE %s", columnType, columnCollation)
+					columnCollation = ""
+				}
+				columnType = fmt.Sprintf("%s %s", columnType, generated)
+			} else {
+				nullable = "NULL"
+				zap.L().Warn("reverse oracle virtual column",
+					zap.String("schema", r.SourceSchemaName),
+					zap.String("table", r.SourceTableName),
+					zap.String("column", rowCol["COLUMN_NAME"]),
+					zap.String("reason", reason),
+					zap.String("suggest", "convert to normal column, full and csv extract data as normal column"))
+			}
+		}
+
 		if nullable == "NULL" {
 			switch {
 			case columnCollation != "" && comment != "" && dataDefault != "":
-				tableColumns = append(tableColumns, fmt.Sprintf("`%s` %s COLLATE %s DEFAULT %s COMMENT %s", rowCol["COLUMN_NAME"], columnType, columnCollation, dataDefault, comment))
+				tableColumns = append(tableColumns, fmt.Sprintf("`%s` %s COLLATE %s DEFAULT %s COMMENT %s", columnName, columnType, columnCollation, dataDefault, comment))
 			case columnCollation != "" && comment == "" && dataDefault != "":
-				tableColumns = append(tableColumns, fmt.Sprintf("`%s` %s COLLATE %s DEFAULT %s", rowCol["COLUMN_NAME"], columnType, columnCollation, dataDefault))
+				tableColumns = append(tableColumns, fmt.Sprintf("`%s` %s COLLATE %s DEFAULT %s", columnName, columnType, columnCollation, dataDefault))
 			case columnCollation != "" && comment == "" && dataDefault == "":
-				tableColumns = append(tableColumns, fmt.Sprintf("`%s` %s COLLATE %s", rowCol["COLUMN_NAME"], columnType, columnCollation))
+				tableColumns = append(tableColumns, fmt.Sprintf("`%s` %s COLLATE %s", columnName, columnType, columnCollation))
 			case columnCollation != "" && comment != "" && dataDefault == "":
-				tableColumns = append(tableColumns, fmt.Sprintf("`%s` %s COLLATE %s COMMENT %s", rowCol["COLUMN_NAME"], columnType, columnCollation, comment))
+				tableColumns = append(tableColumns, fmt.Sprintf("`%s` %s COLLATE %s COMMENT %s", columnName, columnType, columnCollation, comment))
 			case columnCollation == "" && comment != "" && dataDefault != "":
-				tableColumns = append(tableColumns, fmt.Sprintf("`%s` %s DEFAULT %s COMMENT %s", rowCol["COLUMN_NAME"], columnType, dataDefault, comment))
+				tableColumns = append(tableColumns, fmt.Sprintf("`%s` %s DEFAULT %s COMMENT %s", columnName, columnType, dataDefault, comment))
 			case columnCollation == "" && comment == "" && dataDefault != "":
-				tableColumns = append(tableColumns, fmt.Sprintf("`%s` %s DEFAULT %s", rowCol["COLUMN_NAME"], columnType, dataDefault))
+				tableColumns = append(tableColumns, fmt.Sprintf("`%s` %s DEFAULT %s", columnName, columnType, dataDefault))
 			case columnCollation == "" && comment == "" && dataDefault == "":
-				tableColumns = append(tableColumns, fmt.Sprintf("`%s` %s", rowCol["COLUMN_NAME"], columnType))
+				tableColumns = append(tableColumns, fmt.Sprintf("`%s` %s", columnName, columnType))
 			case columnCollation == "" && comment != "" && dataDefault == "":
-				tableColumns = append(tableColumns, fmt.Sprintf("`%s` %s COMMENT %s", rowCol["COLUMN_NAME"], columnType, comment))
+				tableColumns = append(tableColumns, fmt.Sprintf("`%s` %s COMMENT %s", columnName, columnType, comment))
 			default:
 				return tableColumns, fmt.Errorf("error on gen oracle schema table column meta with nullable, rule: %v", r.String())
 			}
@@ -772,25 +870,40 @@ func (r *Rule) GenTableColumn() (tableColumns []string, err error) {
 			switch {
 			case columnCollation != "" && comment != "" && dataDefault != "":
 				tableColumns = append(tableColumns, fmt.Sprintf("`%s` %s COLLATE %s %s DEFAULT %s COMMENT %s",
-					rowCol["COLUMN_NAME"], columnType, columnCollation, nullable, dataDefault, comment))
+					columnName, columnType, columnCollation, nullable, dataDefault, comment))
 			case columnCollation != "" && comment != "" && dataDefault == "":
-				tableColumns = append(tableColumns, fmt.Sprintf("`%s` %s COLLATE %s %s COMMENT %s", rowCol["COLUMN_NAME"], columnType, columnCollation, nullable, comment))
+				tableColumns = append(tableColumns, fmt.Sprintf("`%s` %s COLLATE %s %s COMMENT %s", columnName, columnType, columnCollation, nullable, comment))
 			case columnCollation != "" && comment == "" && dataDefault != "":
-				tableColumns = append(tableColumns, fmt.Sprintf("`%s` %s COLLATE %s %s DEFAULT %s", rowCol["COLUMN_NAME"], columnType, columnCollation, nullable, dataDefault))
+				tableColumns = append(tableColumns, fmt.Sprintf("`%s` %s COLLATE %s %s DEFAULT %s", columnName, columnType, columnCollation, nullable, dataDefault))
 			case columnCollation != "" && comment == "" && dataDefault == "":
-				tableColumns = append(tableColumns, fmt.Sprintf("`%s` %s COLLATE %s %s", rowCol["COLUMN_NAME"], columnType, columnCollation, nullable))
+				tableColumns = append(tableColumns, fmt.Sprintf("`%s` %s COLLATE %s %s", columnName, columnType, columnCollation, nullable))
 			case columnCollation == "" && comment != "" && dataDefault != "":
-				tableColumns = append(tableColumns, fmt.Sprintf("`%s` %s %s DEFAULT %s COMMENT %s", rowCol["COLUMN_NAME"], columnType, nullable, dataDefault, comment))
+				tableColumns = append(tableColumns, fmt.Sprintf("`%s` %s %s DEFAULT %s COMMENT %s", columnName, columnType, nullable, dataDefault, comment))
 			case columnCollation == "" && comment != "" && dataDefault == "":
-				tableColumns = append(tableColumns, fmt.Sprintf("`%s` %s %s COMMENT %s", rowCol["COLUMN_NAME"], columnType, nullable, comment))
+				tableColumns = append(tableColumns, fmt.Sprintf("`%s` %s %s COMMENT %s", columnName, columnType, nullable, comment))
 			case columnCollation == "" && comment == "" && dataDefault != "":
-				tableColumns = append(tableColumns, fmt.Sprintf("`%s` %s %s DEFAULT %s", rowCol["COLUMN_NAME"], columnType, nullable, dataDefault))
+				tableColumns = append(tableColumns, fmt.Sprintf("`%s` %s %s DEFAULT %s", columnName, columnType, nullable, dataDefault))
 			case columnCollation == "" && comment == "" && dataDefault == "":
-				tableColumns = append(tableColumns, fmt.Sprintf("`%s` %s %s", rowCol["COLUMN_NAME"], columnType, nullable))
+				tableColumns = append(tableColumns, fmt.Sprintf("`%s` %s %s", columnName, columnType, nullable))
 			default:
 				return tableColumns, fmt.Errorf("error on gen oracle schema table column meta without nullable, rule: %v", r.String())
 			}
 		}
+
+		// 不可见列
+		if strings.EqualFold(rowCol["HIDDEN_COLUMN"], "YES") {
+			if r.isSupportInvisibleColumn() {
+				tableColumns[len(tableColumns)-1] = fmt.Sprintf("%s INVISIBLE", tableColumns[len(tableColumns)-1])
+			} else {
+				zap.L().Warn("reverse oracle invisible column",
+					zap.String("schema", r.SourceSchemaName),
+					zap.String("table", r.SourceTableName),
+					zap.String("column", rowCol["COLUMN_NAME"]),
+					zap.String("target db type", r.TargetDBType),
+					zap.String("target db version", r.TargetDBVersion),
+					zap.String("suggest", "target db isn't support invisible column, convert to visible column"))
+			}
+		}
 	}
 
 	return tableColumns, nil
@@ -811,6 +924,16 @@ func (r *Rule) GenSchemaName() string {
 	return r.SourceSchemaName
 }
 
+// GenColumnName 字段名映射规则，不存在规则返回源端字段名
+func (r *Rule) GenColumnName(columnName string) string {
+	return common.StringColumnNameRule(columnName, r.TableColumnNameRule)
+}
+
+// GenIdentifierName 索引、约束等标识符名称，超长标识符按策略截断
+func (r *Rule) GenIdentifierName(name string) string {
+	return common.StringIdentifierPolicy(strings.ToUpper(name), r.IdentifierPolicy)
+}
+
 func (r *Rule) GenTableName() string {
 	if r.TargetTableName == "" {
 		return r.SourceTableName
@@ -0,0 +1,203 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package o2m
+
+import (
+	"fmt"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/database/oracle"
+	"github.com/wentaojin/transferdb/module/reverse"
+	"go.uber.org/zap"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// AUTO_INCREMENT 字段支持数据类型
+var identityDatatype = []string{"TINYINT", "SMALLINT", "MEDIUMINT", "INT", "INTEGER", "BIGINT"}
+
+// GenColumnIdentity 按 identity-policy 生成 identity column 自增属性
+// 返回自增属性以及是否 identity column，不满足转换条件返回空，按普通字段创建
+// AUTO-INCREMENT 要求整型字段且为某个键/索引引导列
+// AUTO-RANDOM 要求 tidb 且为单列 BIGINT 主键，否则回退 AUTO-INCREMENT
+func (r *Rule) GenColumnIdentity(columnName, columnType string) (string, bool) {
+	isIdentity := false
+	for _, col := range r.IdentityINFO {
+		if strings.EqualFold(col["COLUMN_NAME"], columnName) {
+			isIdentity = true
+			break
+		}
+	}
+	if !isIdentity || strings.EqualFold(r.IdentityPolicy, common.ReverseIdentityPolicyNone) {
+		return "", isIdentity
+	}
+
+	baseType := common.StringUPPER(strings.Fields(strings.Split(columnType, "(")[0])[0])
+	if !common.IsContainString(identityDatatype, baseType) {
+		zap.L().Warn("reverse oracle identity column",
+			zap.String("schema", r.SourceSchemaName),
+			zap.String("table", r.SourceTableName),
+			zap.String("column", columnName),
+			zap.String("column type", columnType),
+			zap.String("suggest", "identity column data type isn't integer, convert to normal column, please manual process"))
+		return "", isIdentity
+	}
+
+	if strings.EqualFold(r.IdentityPolicy, common.ReverseIdentityPolicyAutoRandom) {
+		if strings.EqualFold(r.TargetDBType, common.DatabaseTypeTiDB) && strings.EqualFold(baseType, "BIGINT") &&
+			len(r.PrimaryKeyINFO) > 0 && strings.EqualFold(r.PrimaryKeyINFO[0]["COLUMN_LIST"], columnName) {
+			return "AUTO_RANDOM", isIdentity
+		}
+		zap.L().Warn("reverse oracle identity column",
+			zap.String("schema", r.SourceSchemaName),
+			zap.String("table", r.SourceTableName),
+			zap.String("column", columnName),
+			zap.String("identity policy", r.IdentityPolicy),
+			zap.String("suggest", "auto_random require tidb single bigint primary key, fallback auto_increment"))
+	}
+
+	var keyColumns []string
+	for _, key := range r.PrimaryKeyINFO {
+		keyColumns = append(keyColumns, key["COLUMN_LIST"])
+	}
+	for _, key := range r.UniqueKeyINFO {
+		keyColumns = append(keyColumns, key["COLUMN_LIST"])
+	}
+	for _, key := range r.UniqueIndexINFO {
+		keyColumns = append(keyColumns, key["COLUMN_LIST"])
+	}
+	for _, key := range r.NormalIndexINFO {
+		keyColumns = append(keyColumns, key["COLUMN_LIST"])
+	}
+	for _, cols := range keyColumns {
+		if strings.EqualFold(strings.Split(cols, ",")[0], columnName) {
+			return "AUTO_INCREMENT", isIdentity
+		}
+	}
+	zap.L().Warn("reverse oracle identity column",
+		zap.String("schema", r.SourceSchemaName),
+		zap.String("table", r.SourceTableName),
+		zap.String("column", columnName),
+		zap.String("suggest", "identity column isn't leading column of any key or index, convert to normal column, please manual process"))
+	return "", isIdentity
+}
+
+// GenCreateSequence tidb 生成 CREATE SEQUENCE，mysql 不支持序列输出不兼容项
+// 序列起始值取 oracle LAST_NUMBER，超出 tidb 取值范围按 tidb 边界值处理，序列名称按超长标识符处理策略转换
+func GenCreateSequence(w *reverse.Write, oracle *oracle.Oracle, sourceSchema, targetSchema, targetDBType, identifierPolicy string, directWrite bool) error {
+	startTime := time.Now()
+	sequences, err := oracle.GetOracleSchemaSequence(sourceSchema)
+	if err != nil {
+		return err
+	}
+	if len(sequences) == 0 {
+		return nil
+	}
+
+	if !strings.EqualFold(targetDBType, common.DatabaseTypeTiDB) {
+		var sqlComp strings.Builder
+		sqlComp.WriteString("/*\n")
+		sqlComp.WriteString(" oracle sequence maybe mysql has compatibility, will skip convert to reverse, please manual process\n")
+		t := table.NewWriter()
+		t.SetStyle(table.StyleLight)
+		t.AppendHeader(table.Row{"SCHEMA", "SEQUENCE NAME", "LAST NUMBER", "SUGGEST"})
+		for _, seq := range sequences {
+			t.AppendRows([]table.Row{
+				{sourceSchema, seq["SEQUENCE_NAME"], seq["LAST_NUMBER"], "Manual Process Sequence"},
+			})
+		}
+		sqlComp.WriteString(t.Render() + "\n")
+		sqlComp.WriteString("*/\n")
+		if _, err = w.CWriteFile(sqlComp.String()); err != nil {
+			return err
+		}
+		zap.L().Warn("reverse oracle sequence",
+			zap.String("schema", sourceSchema),
+			zap.Int("sequence counts", len(sequences)),
+			zap.String("suggest", "mysql isn't support sequence, detail see compatibility output"))
+		return nil
+	}
+
+	var sqlRev strings.Builder
+	sqlRev.WriteString("/*\n")
+	sqlRev.WriteString(" oracle sequence reverse tidb sequence\n")
+	t := table.NewWriter()
+	t.SetStyle(table.StyleLight)
+	t.AppendHeader(table.Row{"#", "ORACLE", "TIDB", "SUGGEST"})
+	for _, seq := range sequences {
+		t.AppendRows([]table.Row{
+			{"SEQUENCE", fmt.Sprintf("%s.%s", sourceSchema, seq["SEQUENCE_NAME"]), fmt.Sprintf("%s.%s", targetSchema, common.StringIdentifierPolicy(seq["SEQUENCE_NAME"], identifierPolicy)), "Create Sequence"},
+		})
+	}
+	sqlRev.WriteString(t.Render() + "\n")
+	sqlRev.WriteString("*/\n")
+	for _, seq := range sequences {
+		seqSQL, err := genTiDBSequenceSQL(targetSchema, common.StringIdentifierPolicy(seq["SEQUENCE_NAME"], identifierPolicy), seq)
+		if err != nil {
+			return err
+		}
+		sqlRev.WriteString(seqSQL + "\n")
+	}
+	sqlRev.WriteString("\n")
+
+	if directWrite {
+		if err = w.RWriteDB(sqlRev.String()); err != nil {
+			return err
+		}
+	} else {
+		if _, err = w.RWriteFile(sqlRev.String()); err != nil {
+			return err
+		}
+	}
+	zap.L().Info("output oracle to tidb sequence create sql",
+		zap.String("schema", sourceSchema),
+		zap.Int("sequence counts", len(sequences)),
+		zap.String("cost", time.Now().Sub(startTime).String()))
+	return nil
+}
+
+func genTiDBSequenceSQL(targetSchema, sequenceName string, seq map[string]string) (string, error) {
+	tidbMax, _ := new(big.Int).SetString(common.TiDBSequenceMaxValue, 10)
+	tidbMin, _ := new(big.Int).SetString(common.TiDBSequenceMinValue, 10)
+
+	var values []*big.Int
+	for _, col := range []string{"MIN_VALUE", "MAX_VALUE", "INCREMENT_BY", "CACHE_SIZE", "LAST_NUMBER"} {
+		v, ok := new(big.Int).SetString(seq[col], 10)
+		if !ok {
+			return "", fmt.Errorf("oracle sequence [%s] column [%s] value [%s] isn't integer", seq["SEQUENCE_NAME"], col, seq[col])
+		}
+		if v.Cmp(tidbMax) > 0 {
+			v = tidbMax
+		}
+		if v.Cmp(tidbMin) < 0 {
+			v = tidbMin
+		}
+		values = append(values, v)
+	}
+	minValue, maxValue, incrementBy, cacheSize, lastNumber := values[0], values[1], values[2], values[3], values[4]
+
+	cache := "NOCACHE"
+	if cacheSize.Sign() > 0 {
+		cache = fmt.Sprintf("CACHE %s", cacheSize.String())
+	}
+	cycle := "NOCYCLE"
+	if strings.EqualFold(seq["CYCLE_FLAG"], "Y") {
+		cycle = "CYCLE"
+	}
+	return fmt.Sprintf("CREATE SEQUENCE IF NOT EXISTS `%s`.`%s` START WITH %s INCREMENT BY %s MINVALUE %s MAXVALUE %s %s %s;",
+		targetSchema, sequenceName, lastNumber.String(), incrementBy.String(), minValue.String(), maxValue.String(), cache, cycle), nil
+}
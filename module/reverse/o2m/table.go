@@ -43,19 +43,28 @@ type Table struct {
 	OracleCollation       bool            `json:"oracle_collation"`
 	SourceSchemaCollation string          `json:"source_schema_collation"` // 可为空
 	SourceTableCollation  string          `json:"source_table_collation"`  // 可为空
+	SourceDBCharacterSet  string          `json:"sourcedb_character_set"`
 	SourceDBNLSSort       string          `json:"sourcedb_nlssort"`
 	SourceDBNLSComp       string          `json:"sourcedb_nlscomp"`
 	SourceTableType       string          `json:"source_table_type"`
-
-	TableColumnDatatypeRule   map[string]string `json:"table_column_datatype_rule"`
-	TableColumnDefaultValRule map[string]string `json:"table_column_default_val_rule"`
-	Overwrite                 bool              `json:"overwrite"`
-	Oracle                    *oracle.Oracle    `json:"-"`
-	MySQL                     *mysql.MySQL      `json:"-"`
-	MetaDB                    *meta.Meta        `json:"-"`
+	OracleIdentity        bool            `json:"oracle_identity"`
+	IdentityPolicy        string          `json:"identity_policy"`
+	IdentifierPolicy      string          `json:"identifier_policy"`
+	ForeignKeyPostLoad    bool            `json:"foreign_key_post_load"`
+	IntervalPartitions    int             `json:"interval_partitions"`
+
+	TableColumnDatatypeRule   map[string]string            `json:"table_column_datatype_rule"`
+	TableColumnDefaultValRule map[string]string            `json:"table_column_default_val_rule"`
+	TableColumnNameRule       map[string]string            `json:"table_column_name_rule"`
+	SchemaColumnNameRule      map[string]map[string]string `json:"-"`
+	SchemaTableNameRule       map[string]string            `json:"-"`
+	Overwrite                 bool                         `json:"overwrite"`
+	Oracle                    *oracle.Oracle               `json:"-"`
+	MySQL                     *mysql.MySQL                 `json:"-"`
+	MetaDB                    *meta.Meta                   `json:"-"`
 }
 
-func GenReverseTableTask(r *Reverse, tableNameRule map[string]string, tableColumnRule, tableDefaultRule map[string]map[string]string, oracleDBVersion string, oracleCollation bool, exporters []string, nlsSort, nlsComp string) ([]*Table, error) {
+func GenReverseTableTask(r *Reverse, tableNameRule map[string]string, tableColumnRule, tableDefaultRule, tableColumnNameRule map[string]map[string]string, oracleDBVersion string, oracleCollation bool, exporters []string, nlsSort, nlsComp string) ([]*Table, error) {
 	var tables []*Table
 
 	beginTime := time.Now()
@@ -126,6 +135,12 @@ func GenReverseTableTask(r *Reverse, tableNameRule map[string]string, tableColum
 		zap.Bool("table collation", oracleCollation),
 		zap.String("cost", endTime.Sub(startTime).String()))
 
+	// oracle 12c 及以上存在 identity column
+	oracleIdentity := false
+	if common.VersionOrdinal(oracleDBVersion) >= common.VersionOrdinal(common.OracleIdentityColumnDBVersion) {
+		oracleIdentity = true
+	}
+
 	// 获取 MySQL 版本
 	mysqlVersion, err := r.Mysql.GetMySQLDBVersion()
 	if err != nil {
@@ -172,10 +187,19 @@ func GenReverseTableTask(r *Reverse, tableNameRule map[string]string, tableColum
 					TargetTableName:           targetTableName,
 					TargetTableOption:         common.StringUPPER(r.Cfg.MySQLConfig.TableOption),
 					SourceTableType:           tablesMap[t],
+					OracleIdentity:            oracleIdentity,
+					IdentityPolicy:            r.Cfg.ReverseConfig.IdentityPolicy,
+					IdentifierPolicy:          r.Cfg.ReverseConfig.IdentifierPolicy,
+					ForeignKeyPostLoad:        r.Cfg.ReverseConfig.ForeignKeyPostLoad,
+					IntervalPartitions:        r.Cfg.ReverseConfig.IntervalPartitions,
+					SourceDBCharacterSet:      characterSet,
 					SourceDBNLSSort:           nlsSort,
 					SourceDBNLSComp:           nlsComp,
 					TableColumnDatatypeRule:   tableColumnRule[common.StringUPPER(t)],
 					TableColumnDefaultValRule: tableDefaultRule[common.StringUPPER(t)],
+					TableColumnNameRule:       tableColumnNameRule[common.StringUPPER(t)],
+					SchemaColumnNameRule:      tableColumnNameRule,
+					SchemaTableNameRule:       tableNameRule,
 					Overwrite:                 r.Cfg.MySQLConfig.Overwrite,
 					Oracle:                    r.Oracle,
 					MySQL:                     r.Mysql,
@@ -259,6 +283,21 @@ func (t *Table) GetTableColumnComment() ([]map[string]string, error) {
 	return t.Oracle.GetOracleSchemaTableColumnComment(t.SourceSchemaName, t.SourceTableName)
 }
 
+func (t *Table) GetTablePartitionKey() ([]map[string]string, error) {
+	// 分区类型、分区键以及子分区键
+	return t.Oracle.GetOracleSchemaTablePartitionKey(t.SourceSchemaName, t.SourceTableName)
+}
+
+func (t *Table) GetTablePartitionDetail() ([]map[string]string, error) {
+	// 分区名称以及分区边界值
+	return t.Oracle.GetOracleSchemaTablePartitionDetail(t.SourceSchemaName, t.SourceTableName)
+}
+
+func (t *Table) GetTableIdentityColumn() ([]map[string]string, error) {
+	// 自增列 identity column，oracle 12c 及以上
+	return t.Oracle.GetOracleSchemaTableIdentityColumn(t.SourceSchemaName, t.SourceTableName)
+}
+
 func (t *Table) GetTableInfo() (interface{}, error) {
 	primaryKey, err := t.GetTablePrimaryKey()
 	if err != nil {
@@ -299,6 +338,26 @@ func (t *Table) GetTableInfo() (interface{}, error) {
 		return nil, err
 	}
 
+	var identityColumn []map[string]string
+	if t.OracleIdentity {
+		identityColumn, err = t.GetTableIdentityColumn()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var partitionKey, partitionDetail []map[string]string
+	if strings.EqualFold(t.SourceTableType, "PARTITIONED") {
+		partitionKey, err = t.GetTablePartitionKey()
+		if err != nil {
+			return nil, err
+		}
+		partitionDetail, err = t.GetTablePartitionDetail()
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return &Info{
 		PrimaryKeyINFO:    primaryKey,
 		UniqueKeyINFO:     uniqueKey,
@@ -309,6 +368,9 @@ func (t *Table) GetTableInfo() (interface{}, error) {
 		TableCommentINFO:  tableComment,
 		TableColumnINFO:   columnMeta,
 		ColumnCommentINFO: columnComment,
+		PartitionKeyINFO:  partitionKey,
+		PartitionINFO:     partitionDetail,
+		IdentityINFO:      identityColumn,
 	}, nil
 }
 
@@ -319,27 +381,13 @@ func (t *Table) String() string {
 
 func GenCreateSchema(w *reverse.Write, sourceSchema, targetSchema, nlsComp string, directWrite bool) error {
 	startTime := time.Now()
-	var (
-		sqlRev          strings.Builder
-		schemaCollation string
-	)
+	var sqlRev strings.Builder
 
-	oraDBVersion, err := w.Oracle.GetOracleDBVersion()
+	createSchema, err := GenCreateSchemaSQL(w.Oracle, sourceSchema, targetSchema, nlsComp)
 	if err != nil {
 		return err
 	}
 
-	oraCollation := false
-	if common.VersionOrdinal(oraDBVersion) >= common.VersionOrdinal(common.OracleTableColumnCollationDBVersion) {
-		oraCollation = true
-	}
-	if oraCollation {
-		schemaCollation, err = w.Oracle.GetOracleSchemaCollation(sourceSchema)
-		if err != nil {
-			return err
-		}
-	}
-
 	sqlRev.WriteString("/*\n")
 	sqlRev.WriteString(" oracle schema reverse mysql database\n")
 	t := table.NewWriter()
@@ -350,18 +398,7 @@ func GenCreateSchema(w *reverse.Write, sourceSchema, targetSchema, nlsComp strin
 	})
 	sqlRev.WriteString(t.Render() + "\n")
 	sqlRev.WriteString("*/\n")
-
-	if oraCollation {
-		if _, ok := common.OracleCollationMap[common.StringUPPER(schemaCollation)]; !ok {
-			return fmt.Errorf("oracle schema collation [%s] isn't support", schemaCollation)
-		}
-		sqlRev.WriteString(fmt.Sprintf("CREATE DATABASE IF NOT EXISTS %s DEFAULT CHARACTER SET %s COLLATE %s;\n\n", common.StringUPPER(targetSchema), strings.ToLower(common.MySQLCharacterSet), common.OracleCollationMap[common.StringUPPER(schemaCollation)]))
-	} else {
-		if _, ok := common.OracleCollationMap[common.StringUPPER(nlsComp)]; !ok {
-			return fmt.Errorf("oracle db nls_comp collation [%s] isn't support", nlsComp)
-		}
-		sqlRev.WriteString(fmt.Sprintf("CREATE DATABASE IF NOT EXISTS %s DEFAULT CHARACTER SET %s COLLATE %s;\n\n", common.StringUPPER(targetSchema), strings.ToLower(common.MySQLCharacterSet), common.OracleCollationMap[common.StringUPPER(nlsComp)]))
-	}
+	sqlRev.WriteString(createSchema + "\n\n")
 
 	if directWrite {
 		err = w.RWriteDB(sqlRev.String())
@@ -381,10 +418,10 @@ func GenCreateSchema(w *reverse.Write, sourceSchema, targetSchema, nlsComp strin
 	return nil
 }
 
-func GenCompatibilityTable(f *reverse.Write, sourceSchema string, partitionTables, temporaryTables, clusteredTables []string, materializedViews []string) error {
+func GenCompatibilityTable(f *reverse.Write, sourceSchema string, temporaryTables, clusteredTables []string, materializedViews []string) error {
 	startTime := time.Now()
 	// 兼容提示
-	if len(partitionTables) > 0 || len(temporaryTables) > 0 || len(clusteredTables) > 0 || len(materializedViews) > 0 {
+	if len(temporaryTables) > 0 || len(clusteredTables) > 0 || len(materializedViews) > 0 {
 		var sqlComp strings.Builder
 
 		sqlComp.WriteString("/*\n")
@@ -393,13 +430,6 @@ func GenCompatibilityTable(f *reverse.Write, sourceSchema string, partitionTable
 		t.SetStyle(table.StyleLight)
 		t.AppendHeader(table.Row{"SCHEMA", "TABLE NAME", "ORACLE TABLE TYPE", "SUGGEST"})
 
-		if len(partitionTables) > 0 {
-			for _, part := range partitionTables {
-				t.AppendRows([]table.Row{
-					{sourceSchema, part, "Partition", "Manual Process Table"},
-				})
-			}
-		}
 		if len(temporaryTables) > 0 {
 			for _, temp := range temporaryTables {
 				t.AppendRows([]table.Row{
@@ -450,3 +480,27 @@ func GenCompatibilityTable(f *reverse.Write, sourceSchema string, partitionTable
 
 	return nil
 }
+
+// GenCreateSchemaSQL 根据 oracle schema/db 排序规则生成下游 CREATE DATABASE 语句
+func GenCreateSchemaSQL(oracle *oracle.Oracle, sourceSchema, targetSchema, nlsComp string) (string, error) {
+	oraDBVersion, err := oracle.GetOracleDBVersion()
+	if err != nil {
+		return "", err
+	}
+
+	if common.VersionOrdinal(oraDBVersion) >= common.VersionOrdinal(common.OracleTableColumnCollationDBVersion) {
+		schemaCollation, err := oracle.GetOracleSchemaCollation(sourceSchema)
+		if err != nil {
+			return "", err
+		}
+		if _, ok := common.OracleCollationMap[common.StringUPPER(schemaCollation)]; !ok {
+			return "", fmt.Errorf("oracle schema collation [%s] isn't support", schemaCollation)
+		}
+		return fmt.Sprintf("CREATE DATABASE IF NOT EXISTS %s DEFAULT CHARACTER SET %s COLLATE %s;", common.StringUPPER(targetSchema), strings.ToLower(common.MySQLCharacterSet), common.OracleCollationMap[common.StringUPPER(schemaCollation)]), nil
+	}
+
+	if _, ok := common.OracleCollationMap[common.StringUPPER(nlsComp)]; !ok {
+		return "", fmt.Errorf("oracle db nls_comp collation [%s] isn't support", nlsComp)
+	}
+	return fmt.Sprintf("CREATE DATABASE IF NOT EXISTS %s DEFAULT CHARACTER SET %s COLLATE %s;", common.StringUPPER(targetSchema), strings.ToLower(common.MySQLCharacterSet), common.OracleCollationMap[common.StringUPPER(nlsComp)]), nil
+}
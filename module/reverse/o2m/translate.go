@@ -0,0 +1,1354 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package o2m
+
+import (
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"strconv"
+	"strings"
+)
+
+// oracle SQL 改写 mysql/tidb SQL，视图定义以及函数索引表达式共用
+// 基于 token 以及括号层级改写，无法改写的语法返回 unsupported construct 错误
+
+const (
+	tokenWord = iota
+	tokenQuoted
+	tokenString
+	tokenNumber
+	tokenOperator
+	tokenComma
+	tokenRaw
+	tokenOuterJoin
+)
+
+type sqlToken struct {
+	kind int
+	text string
+}
+
+// sqlNode token 为空表示括号分组
+type sqlNode struct {
+	token    *sqlToken
+	children []*sqlNode
+	// 改写后日期时间表达式类型，日期加减运算依据
+	temporal string
+}
+
+// 日期时间表达式类型，temporalDay 不含时分秒
+const (
+	temporalDay       = "DAY"
+	temporalDate      = "DATE"
+	temporalTimestamp = "TIMESTAMP"
+)
+
+func (n *sqlNode) isGroup() bool {
+	return n.token == nil
+}
+
+func (n *sqlNode) isKind(kind int) bool {
+	return !n.isGroup() && n.token.kind == kind
+}
+
+func (n *sqlNode) isWord(words ...string) bool {
+	if !n.isKind(tokenWord) {
+		return false
+	}
+	for _, w := range words {
+		if strings.EqualFold(n.token.text, w) {
+			return true
+		}
+	}
+	return false
+}
+
+func (n *sqlNode) isOperator(ops ...string) bool {
+	if !n.isKind(tokenOperator) {
+		return false
+	}
+	for _, op := range ops {
+		if n.token.text == op {
+			return true
+		}
+	}
+	return false
+}
+
+func newRawNode(text string) *sqlNode {
+	return &sqlNode{token: &sqlToken{kind: tokenRaw, text: text}}
+}
+
+func newTemporalNode(text, temporal string) *sqlNode {
+	return &sqlNode{token: &sqlToken{kind: tokenRaw, text: text}, temporal: temporal}
+}
+
+// 非表达式关键字，|| 操作数边界
+var sqlBoundaryKeywords = []string{
+	"SELECT", "FROM", "WHERE", "AND", "OR", "NOT", "AS", "ON", "THEN", "ELSE", "WHEN", "CASE", "END",
+	"GROUP", "ORDER", "BY", "HAVING", "UNION", "ALL", "DISTINCT", "IN", "IS", "LIKE", "BETWEEN", "ASC", "DESC",
+	"JOIN", "LEFT", "RIGHT", "INNER", "OUTER", "FULL", "CROSS", "WITH", "UNIQUE", "EXISTS", "ESCAPE", "PRIOR",
+	"CONNECT", "START", "MINUS", "INTERSECT", "LIMIT", "USING", "NULLS", "FIRST", "LAST",
+}
+
+// oracle 特有且 mysql/tidb 无对应实现的函数
+var oracleUnsupportedFunctions = []string{
+	"ADD_MONTHS", "MONTHS_BETWEEN", "TO_NUMBER", "TO_TIMESTAMP", "TO_CLOB", "LISTAGG", "WM_CONCAT",
+	"SYS_GUID", "SYS_CONNECT_BY_PATH", "SYS_CONTEXT", "RATIO_TO_REPORT", "USERENV", "ROWIDTOCHAR",
+}
+
+func isBoundaryKeyword(n *sqlNode) bool {
+	return n.isWord(sqlBoundaryKeywords...)
+}
+
+type oracleTranslator struct {
+	SourceSchema string
+	TargetSchema string
+	// 字段 oracle 数据类型，函数索引表达式依据字段类型改写 TRUNC 等函数
+	ColumnTypes map[string]string
+	// 字段名映射规则，KEY 源端字段名
+	ColumnNameRule map[string]string
+	// 视图定义引用对象名称映射规则
+	NameRule *viewNameRule
+}
+
+// TranslateOracleSQL 改写 oracle SQL 文本（查询语句或表达式）为 mysql/tidb 语法
+func TranslateOracleSQL(sql, sourceSchema, targetSchema string) (string, error) {
+	return TranslateOracleExpr(sql, sourceSchema, targetSchema, nil, nil)
+}
+
+// translateOracleView 改写 oracle 视图定义，引用表名、视图名以及字段名按所属对象名称映射规则改写
+func translateOracleView(sql, sourceSchema, targetSchema string, nameRule *viewNameRule) (string, error) {
+	t := &oracleTranslator{
+		SourceSchema: sourceSchema,
+		TargetSchema: targetSchema,
+		NameRule:     nameRule,
+	}
+	return t.translate(sql)
+}
+
+// TranslateOracleExpr 改写 oracle 表达式，columnTypes 为表达式所属表字段（映射后字段名）oracle 数据类型
+// columnNameRule 为表达式所属表字段名映射规则
+func TranslateOracleExpr(expr, sourceSchema, targetSchema string, columnTypes, columnNameRule map[string]string) (string, error) {
+	t := &oracleTranslator{
+		SourceSchema:   sourceSchema,
+		TargetSchema:   targetSchema,
+		ColumnTypes:    columnTypes,
+		ColumnNameRule: columnNameRule,
+	}
+	return t.translate(expr)
+}
+
+func (t *oracleTranslator) translate(sql string) (string, error) {
+	tokens, err := t.tokenize(sql)
+	if err != nil {
+		return "", err
+	}
+	nodes, err := buildSQLTree(tokens)
+	if err != nil {
+		return "", err
+	}
+	if t.NameRule != nil {
+		if nodes, err = t.renameLevel(nodes, nil, 0); err != nil {
+			return "", err
+		}
+	}
+	nodes, err = t.translateLevel(nodes)
+	if err != nil {
+		return "", err
+	}
+	return renderSQLNodes(nodes), nil
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || c == '$' || c == '#' || c >= 0x80 ||
+		(c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+func isDigitByte(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func (t *oracleTranslator) tokenize(sql string) ([]sqlToken, error) {
+	var tokens []sqlToken
+	i := 0
+	for i < len(sql) {
+		c := sql[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == 0:
+			i++
+		case strings.HasPrefix(sql[i:], "--"):
+			end := strings.Index(sql[i:], "\n")
+			if end < 0 {
+				i = len(sql)
+			} else {
+				i += end + 1
+			}
+		case strings.HasPrefix(sql[i:], "/*"):
+			// 注释以及 hint 直接丢弃
+			end := strings.Index(sql[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated comment")
+			}
+			i += end + 4
+		case c == '\'':
+			j := i + 1
+			for {
+				if j >= len(sql) {
+					return nil, fmt.Errorf("unterminated string literal")
+				}
+				if sql[j] == '\'' {
+					if j+1 < len(sql) && sql[j+1] == '\'' {
+						j += 2
+						continue
+					}
+					break
+				}
+				j++
+			}
+			tokens = append(tokens, sqlToken{kind: tokenString, text: sql[i : j+1]})
+			i = j + 1
+		case c == '"':
+			end := strings.Index(sql[i+1:], `"`)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated quoted identifier")
+			}
+			tokens = append(tokens, sqlToken{kind: tokenQuoted, text: sql[i+1 : i+1+end]})
+			i += end + 2
+		case isDigitByte(c) || (c == '.' && i+1 < len(sql) && isDigitByte(sql[i+1])):
+			j := i
+			for j < len(sql) && (isDigitByte(sql[j]) || sql[j] == '.') {
+				j++
+			}
+			if j < len(sql) && (sql[j] == 'e' || sql[j] == 'E') {
+				k := j + 1
+				if k < len(sql) && (sql[k] == '+' || sql[k] == '-') {
+					k++
+				}
+				if k < len(sql) && isDigitByte(sql[k]) {
+					j = k
+					for j < len(sql) && isDigitByte(sql[j]) {
+						j++
+					}
+				}
+			}
+			tokens = append(tokens, sqlToken{kind: tokenNumber, text: sql[i:j]})
+			i = j
+		case isWordByte(c):
+			j := i
+			for j < len(sql) && isWordByte(sql[j]) {
+				j++
+			}
+			tokens = append(tokens, sqlToken{kind: tokenWord, text: strings.ToUpper(sql[i:j])})
+			i = j
+		case c == ',':
+			tokens = append(tokens, sqlToken{kind: tokenComma, text: ","})
+			i++
+		default:
+			op := string(c)
+			if i+1 < len(sql) {
+				switch sql[i : i+2] {
+				case "||", "<=", ">=", "<>", "!=", "^=", "=>", ":=":
+					op = sql[i : i+2]
+				}
+			}
+			if op == "^=" {
+				tokens = append(tokens, sqlToken{kind: tokenOperator, text: "<>"})
+			} else {
+				tokens = append(tokens, sqlToken{kind: tokenOperator, text: op})
+			}
+			i += len(op)
+		}
+	}
+
+	// 源端 schema 限定符替换为目标端 schema
+	for i := range tokens {
+		if i+1 < len(tokens) && tokens[i+1].kind == tokenOperator && tokens[i+1].text == "." &&
+			(tokens[i].kind == tokenWord || tokens[i].kind == tokenQuoted) &&
+			strings.EqualFold(tokens[i].text, t.SourceSchema) && (i == 0 || tokens[i-1].text != ".") {
+			tokens[i] = sqlToken{kind: tokenQuoted, text: t.TargetSchema}
+		}
+	}
+
+	// 字段名映射，排除函数名以及限定符
+	if len(t.ColumnNameRule) > 0 {
+		for i := range tokens {
+			if tokens[i].kind != tokenWord && tokens[i].kind != tokenQuoted {
+				continue
+			}
+			if i+1 < len(tokens) && tokens[i+1].kind == tokenOperator && (tokens[i+1].text == "(" || tokens[i+1].text == ".") {
+				continue
+			}
+			if val, ok := t.ColumnNameRule[strings.ToUpper(tokens[i].text)]; ok {
+				tokens[i] = sqlToken{kind: tokenQuoted, text: val}
+			}
+		}
+	}
+	return tokens, nil
+}
+
+// RenameOracleExprColumn 仅按字段名映射规则改写 oracle 表达式字段名，不做语法改写
+func RenameOracleExprColumn(expr string, columnNameRule map[string]string) (string, error) {
+	if len(columnNameRule) == 0 {
+		return expr, nil
+	}
+	t := &oracleTranslator{ColumnNameRule: columnNameRule}
+	tokens, err := t.tokenize(expr)
+	if err != nil {
+		return "", err
+	}
+	nodes, err := buildSQLTree(tokens)
+	if err != nil {
+		return "", err
+	}
+	return renderSQLNodes(nodes), nil
+}
+
+func buildSQLTree(tokens []sqlToken) ([]*sqlNode, error) {
+	root := &sqlNode{}
+	stack := []*sqlNode{root}
+	for i := range tokens {
+		tok := tokens[i]
+		cur := stack[len(stack)-1]
+		switch {
+		case tok.kind == tokenOperator && tok.text == "(":
+			group := &sqlNode{}
+			cur.children = append(cur.children, group)
+			stack = append(stack, group)
+		case tok.kind == tokenOperator && tok.text == ")":
+			if len(stack) == 1 {
+				return nil, fmt.Errorf("unbalanced parentheses")
+			}
+			stack = stack[:len(stack)-1]
+		case tok.kind == tokenOperator && tok.text == ";":
+			// 语句结束符丢弃
+		default:
+			cur.children = append(cur.children, &sqlNode{token: &tok})
+		}
+	}
+	if len(stack) != 1 {
+		return nil, fmt.Errorf("unbalanced parentheses")
+	}
+	return root.children, nil
+}
+
+func (n *sqlNode) render() string {
+	if n.isGroup() {
+		return "(" + renderSQLNodes(n.children) + ")"
+	}
+	switch n.token.kind {
+	case tokenQuoted:
+		return "`" + n.token.text + "`"
+	case tokenString:
+		// mysql 字符串反斜杠为转义符，oracle 反斜杠为普通字符
+		return strings.ReplaceAll(n.token.text, `\`, `\\`)
+	case tokenWord:
+		if strings.Contains(n.token.text, "#") {
+			return "`" + n.token.text + "`"
+		}
+		return n.token.text
+	default:
+		return n.token.text
+	}
+}
+
+func renderSQLNodes(nodes []*sqlNode) string {
+	var b strings.Builder
+	for i, n := range nodes {
+		if i > 0 {
+			prev := nodes[i-1]
+			noSpace := prev.isOperator(".") || n.isOperator(".") || n.isKind(tokenComma) || n.isKind(tokenOuterJoin) ||
+				(n.isGroup() && (prev.isKind(tokenWord) || prev.isKind(tokenQuoted)) && !isBoundaryKeyword(prev))
+			if !noSpace {
+				b.WriteString(" ")
+			}
+		}
+		b.WriteString(n.render())
+	}
+	return b.String()
+}
+
+func splitSQLNodes(nodes []*sqlNode, isSep func(*sqlNode) bool) [][]*sqlNode {
+	var (
+		parts [][]*sqlNode
+		cur   []*sqlNode
+	)
+	for _, n := range nodes {
+		if isSep(n) {
+			parts = append(parts, cur)
+			cur = nil
+			continue
+		}
+		cur = append(cur, n)
+	}
+	return append(parts, cur)
+}
+
+func (t *oracleTranslator) translateLevel(nodes []*sqlNode) ([]*sqlNode, error) {
+	var err error
+	// 聚合判断先于子节点改写，函数改写后聚合函数可能嵌套于改写结果
+	aggregated := isAggregateLevel(nodes)
+	for i, n := range nodes {
+		if !n.isGroup() {
+			continue
+		}
+		// (+) 外连接标识
+		if len(n.children) == 1 && n.children[0].isOperator("+") {
+			nodes[i] = &sqlNode{token: &sqlToken{kind: tokenOuterJoin, text: "(+)"}}
+			continue
+		}
+		n.children, err = t.translateLevel(n.children)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if nodes, err = t.translateFunction(nodes); err != nil {
+		return nil, err
+	}
+	if nodes, err = t.translateDateArith(nodes); err != nil {
+		return nil, err
+	}
+	if nodes, err = translateConcat(nodes); err != nil {
+		return nil, err
+	}
+	if nodes, err = translateRownum(nodes, aggregated); err != nil {
+		return nil, err
+	}
+	if nodes, err = translateOuterJoin(nodes); err != nil {
+		return nil, err
+	}
+	return nodes, checkUnsupported(nodes)
+}
+
+func checkUnsupported(nodes []*sqlNode) error {
+	// 层次查询优先提示，LEVEL、PRIOR 等伪列依附于层次查询
+	for i, n := range nodes {
+		if n.isWord("CONNECT", "START") && i+1 < len(nodes) && nodes[i+1].isWord("BY", "WITH") {
+			return fmt.Errorf("unsupported construct [CONNECT BY hierarchical query]")
+		}
+	}
+	for _, n := range nodes {
+		switch {
+		case n.isWord("PRIOR", "MINUS", "PIVOT", "UNPIVOT", "ROWID", "ROWNUM", "LEVEL"):
+			return fmt.Errorf("unsupported construct [%s]", n.token.text)
+		case n.isKind(tokenOuterJoin):
+			return fmt.Errorf("unsupported construct [(+) outer join]")
+		case n.isOperator("=>", ":="):
+			return fmt.Errorf("unsupported construct [%s]", n.token.text)
+		}
+	}
+	return nil
+}
+
+// translateFunction 函数以及伪列改写
+func (t *oracleTranslator) translateFunction(nodes []*sqlNode) ([]*sqlNode, error) {
+	var result []*sqlNode
+	for i := 0; i < len(nodes); i++ {
+		n := nodes[i]
+		isCall := n.isKind(tokenWord) && i+1 < len(nodes) && nodes[i+1].isGroup() && !isBoundaryKeyword(n) &&
+			(i == 0 || !nodes[i-1].isOperator("."))
+		if !isCall {
+			switch {
+			case n.isWord("SYSDATE"):
+				result = append(result, newTemporalNode("NOW()", temporalDate))
+			case n.isWord("SYSTIMESTAMP"):
+				result = append(result, newTemporalNode("NOW(6)", temporalTimestamp))
+			default:
+				result = append(result, n)
+			}
+			continue
+		}
+
+		name := n.token.text
+		args := splitSQLNodes(nodes[i+1].children, func(n *sqlNode) bool { return n.isKind(tokenComma) })
+		var argStrs []string
+		for _, a := range args {
+			argStrs = append(argStrs, renderSQLNodes(a))
+		}
+
+		var (
+			raw string
+			err error
+		)
+		switch name {
+		case "NVL":
+			if len(args) != 2 {
+				return nil, fmt.Errorf("unsupported construct [NVL with %d arguments]", len(args))
+			}
+			raw = fmt.Sprintf("IFNULL(%s, %s)", argStrs[0], argStrs[1])
+		case "NVL2":
+			if len(args) != 3 {
+				return nil, fmt.Errorf("unsupported construct [NVL2 with %d arguments]", len(args))
+			}
+			raw = fmt.Sprintf("CASE WHEN %s IS NOT NULL THEN %s ELSE %s END", argStrs[0], argStrs[1], argStrs[2])
+		case "DECODE":
+			// DECODE NULL 与 NULL 视为相等，使用 <=> 比较
+			if len(args) < 3 {
+				return nil, fmt.Errorf("unsupported construct [DECODE with %d arguments]", len(args))
+			}
+			var b strings.Builder
+			b.WriteString("CASE")
+			j := 1
+			for ; j+1 < len(args); j += 2 {
+				b.WriteString(fmt.Sprintf(" WHEN (%s) <=> (%s) THEN %s", argStrs[0], argStrs[j], argStrs[j+1]))
+			}
+			if j < len(args) {
+				b.WriteString(fmt.Sprintf(" ELSE %s", argStrs[j]))
+			}
+			b.WriteString(" END")
+			raw = b.String()
+		case "TO_CHAR":
+			raw, err = translateToChar(args, argStrs)
+		case "TO_DATE":
+			raw, err = translateToDate(args, argStrs)
+		case "TRUNC":
+			raw, err = t.translateTrunc(args, argStrs)
+		case "SUBSTR":
+			// oracle 起始位置 0 等同 1，mysql 返回空串
+			if len(args) >= 2 && len(args[1]) == 1 && args[1][0].isKind(tokenNumber) && args[1][0].token.text == "0" {
+				argStrs[1] = "1"
+				raw = fmt.Sprintf("SUBSTR(%s)", strings.Join(argStrs, ", "))
+			}
+		default:
+			for _, f := range oracleUnsupportedFunctions {
+				if strings.EqualFold(f, name) {
+					return nil, fmt.Errorf("unsupported construct [function %s]", name)
+				}
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		if raw == "" {
+			result = append(result, n)
+			continue
+		}
+		switch {
+		case name == "TO_DATE":
+			result = append(result, newTemporalNode(raw, temporalDate))
+		case name == "TRUNC" && !strings.HasPrefix(raw, "TRUNCATE("):
+			result = append(result, newTemporalNode(raw, temporalDay))
+		default:
+			result = append(result, newRawNode(raw))
+		}
+		i++
+	}
+	return result, nil
+}
+
+// translateTrunc TRUNC 按参数或字段数据类型区分日期截断与数值截断
+func (t *oracleTranslator) translateTrunc(args [][]*sqlNode, argStrs []string) (string, error) {
+	switch {
+	case len(args) == 2 && len(args[1]) == 1 && args[1][0].isKind(tokenNumber):
+		return fmt.Sprintf("TRUNCATE(%s, %s)", argStrs[0], argStrs[1]), nil
+	case len(args) == 2 && isStringArg(args[1]):
+		switch strings.ToUpper(strings.Trim(args[1][0].token.text, "'")) {
+		case "DD", "DDD", "J":
+			return fmt.Sprintf("DATE(%s)", argStrs[0]), nil
+		case "MM", "MON", "MONTH":
+			return fmt.Sprintf("CAST(DATE_FORMAT(%s, '%%Y-%%m-01') AS DATE)", argStrs[0]), nil
+		case "YYYY", "YEAR", "YY":
+			return fmt.Sprintf("MAKEDATE(YEAR(%s), 1)", argStrs[0]), nil
+		default:
+			return "", fmt.Errorf("unsupported construct [TRUNC format %s]", args[1][0].token.text)
+		}
+	case len(args) == 1:
+		dataType := ""
+		if len(args[0]) == 1 && (args[0][0].isKind(tokenWord) || args[0][0].isKind(tokenQuoted)) {
+			dataType = t.columnType(args[0][0].token.text)
+		}
+		switch {
+		case t.temporalType(args[0]) != "" || strings.EqualFold(dataType, "DATE") || strings.HasPrefix(dataType, "TIMESTAMP"):
+			return fmt.Sprintf("DATE(%s)", argStrs[0]), nil
+		case strings.EqualFold(dataType, "NUMBER") || strings.EqualFold(dataType, "FLOAT"):
+			return fmt.Sprintf("TRUNCATE(%s, 0)", argStrs[0]), nil
+		default:
+			return "", fmt.Errorf("unsupported construct [TRUNC with unknown argument data type]")
+		}
+	default:
+		return "", fmt.Errorf("unsupported construct [TRUNC with %d arguments]", len(args))
+	}
+}
+
+func isStringArg(arg []*sqlNode) bool {
+	return len(arg) == 1 && arg[0].isKind(tokenString)
+}
+
+func translateToChar(args [][]*sqlNode, argStrs []string) (string, error) {
+	switch len(args) {
+	case 1:
+		return fmt.Sprintf("CAST(%s AS CHAR)", argStrs[0]), nil
+	case 2:
+		if !isStringArg(args[1]) {
+			return "", fmt.Errorf("unsupported construct [TO_CHAR with non-literal format]")
+		}
+		format, err := convertOracleDateFormat(args[1][0].token.text)
+		if err != nil {
+			return "", fmt.Errorf("unsupported construct [TO_CHAR format %s: %v]", args[1][0].token.text, err)
+		}
+		return fmt.Sprintf("DATE_FORMAT(%s, %s)", argStrs[0], format), nil
+	default:
+		return "", fmt.Errorf("unsupported construct [TO_CHAR with nls parameter]")
+	}
+}
+
+func translateToDate(args [][]*sqlNode, argStrs []string) (string, error) {
+	switch len(args) {
+	case 1:
+		return fmt.Sprintf("CAST(%s AS DATETIME)", argStrs[0]), nil
+	case 2:
+		if !isStringArg(args[1]) {
+			return "", fmt.Errorf("unsupported construct [TO_DATE with non-literal format]")
+		}
+		format, err := convertOracleDateFormat(args[1][0].token.text)
+		if err != nil {
+			return "", fmt.Errorf("unsupported construct [TO_DATE format %s: %v]", args[1][0].token.text, err)
+		}
+		return fmt.Sprintf("STR_TO_DATE(%s, %s)", argStrs[0], format), nil
+	default:
+		return "", fmt.Errorf("unsupported construct [TO_DATE with nls parameter]")
+	}
+}
+
+// oracle 日期格式元素对应 mysql 格式，按最长匹配
+var oracleDateFormatElements = []struct {
+	oracle string
+	mysql  string
+	fmMode string
+}{
+	{"YYYY", "%Y", "%Y"}, {"RRRR", "%Y", "%Y"}, {"MONTH", "%M", "%M"}, {"MON", "%b", "%b"},
+	{"HH24", "%H", "%k"}, {"HH12", "%h", "%l"}, {"HH", "%h", "%l"}, {"MI", "%i", "%i"}, {"SS", "%s", "%s"},
+	{"DDD", "%j", "%j"}, {"DAY", "%W", "%W"}, {"DD", "%d", "%e"}, {"DY", "%a", "%a"}, {"MM", "%m", "%c"},
+	{"YY", "%y", "%y"}, {"RR", "%y", "%y"}, {"AM", "%p", "%p"}, {"PM", "%p", "%p"},
+	{"FF9", "%f", "%f"}, {"FF6", "%f", "%f"}, {"FF3", "%f", "%f"}, {"FF", "%f", "%f"},
+}
+
+func convertOracleDateFormat(literal string) (string, error) {
+	format := strings.ReplaceAll(literal[1:len(literal)-1], "''", "'")
+	upper := strings.ToUpper(format)
+
+	var (
+		b      strings.Builder
+		fmMode bool
+	)
+	for i := 0; i < len(upper); {
+		c := upper[i]
+		switch {
+		case strings.HasPrefix(upper[i:], "FM"):
+			fmMode = !fmMode
+			i += 2
+			continue
+		case c == '"':
+			end := strings.Index(format[i+1:], `"`)
+			if end < 0 {
+				return "", fmt.Errorf("unterminated quoted text")
+			}
+			b.WriteString(strings.ReplaceAll(format[i+1:i+1+end], "%", "%%"))
+			i += end + 2
+			continue
+		case strings.ContainsRune(" -/,.;:", rune(c)):
+			b.WriteByte(format[i])
+			i++
+			continue
+		case c == '%':
+			b.WriteString("%%")
+			i++
+			continue
+		}
+
+		matched := false
+		for _, e := range oracleDateFormatElements {
+			if strings.HasPrefix(upper[i:], e.oracle) {
+				if fmMode {
+					b.WriteString(e.fmMode)
+				} else {
+					b.WriteString(e.mysql)
+				}
+				i += len(e.oracle)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return "", fmt.Errorf("format element [%s] isn't support", upper[i:])
+		}
+	}
+	return "'" + strings.ReplaceAll(strings.ReplaceAll(b.String(), "'", "''"), `\`, `\\`) + "'", nil
+}
+
+// scanAtomForward 返回从 i 开始的单个操作数结束位置（不含），无操作数返回 i
+func scanAtomForward(nodes []*sqlNode, i int) int {
+	if i >= len(nodes) {
+		return i
+	}
+	n := nodes[i]
+	switch {
+	case n.isGroup(), n.isKind(tokenString), n.isKind(tokenNumber), n.isKind(tokenRaw):
+		return i + 1
+	case n.isWord("CASE"):
+		depth := 0
+		for j := i; j < len(nodes); j++ {
+			if nodes[j].isWord("CASE") {
+				depth++
+			} else if nodes[j].isWord("END") {
+				depth--
+				if depth == 0 {
+					return j + 1
+				}
+			}
+		}
+		return i
+	case n.isWord("DATE", "TIMESTAMP") && i+1 < len(nodes) && nodes[i+1].isKind(tokenString):
+		return i + 2
+	case (n.isKind(tokenWord) && !isBoundaryKeyword(n)) || n.isKind(tokenQuoted):
+		j := i + 1
+		for j+1 < len(nodes) && nodes[j].isOperator(".") && (nodes[j+1].isKind(tokenWord) || nodes[j+1].isKind(tokenQuoted)) {
+			j += 2
+		}
+		if j < len(nodes) && nodes[j].isGroup() {
+			j++
+		}
+		return j
+	}
+	return i
+}
+
+// scanAtomBackward 返回到 i 结束的单个操作数起始位置，无操作数返回 i+1
+func scanAtomBackward(nodes []*sqlNode, i int) int {
+	if i < 0 {
+		return i + 1
+	}
+	n := nodes[i]
+	qualified := func(start int) int {
+		for start-2 >= 0 && nodes[start-1].isOperator(".") && (nodes[start-2].isKind(tokenWord) || nodes[start-2].isKind(tokenQuoted)) {
+			start -= 2
+		}
+		return start
+	}
+	switch {
+	case n.isGroup():
+		if i-1 >= 0 && (nodes[i-1].isKind(tokenWord) || nodes[i-1].isKind(tokenQuoted)) && !isBoundaryKeyword(nodes[i-1]) {
+			return qualified(i - 1)
+		}
+		return i
+	case n.isKind(tokenString):
+		if i-1 >= 0 && nodes[i-1].isWord("DATE", "TIMESTAMP") {
+			return i - 1
+		}
+		return i
+	case n.isKind(tokenNumber), n.isKind(tokenRaw):
+		return i
+	case n.isWord("END"):
+		depth := 0
+		for j := i; j >= 0; j-- {
+			if nodes[j].isWord("END") {
+				depth++
+			} else if nodes[j].isWord("CASE") {
+				depth--
+				if depth == 0 {
+					return j
+				}
+			}
+		}
+		return i + 1
+	case (n.isKind(tokenWord) && !isBoundaryKeyword(n)) || n.isKind(tokenQuoted):
+		return qualified(i)
+	}
+	return i + 1
+}
+
+func isArithmetic(n *sqlNode) bool {
+	return n.isOperator("+", "-", "*", "/")
+}
+
+func scanOperandForward(nodes []*sqlNode, i int) int {
+	j := i
+	for {
+		k := j
+		if k < len(nodes) && nodes[k].isOperator("+", "-") {
+			k++
+		}
+		end := scanAtomForward(nodes, k)
+		if end == k {
+			return j
+		}
+		j = end
+		if j < len(nodes) && isArithmetic(nodes[j]) {
+			if next := scanAtomForward(nodes, j+1); next > j+1 || (j+1 < len(nodes) && nodes[j+1].isOperator("+", "-")) {
+				j++
+				continue
+			}
+		}
+		return j
+	}
+}
+
+func scanOperandBackward(nodes []*sqlNode, i int) int {
+	start := i + 1
+	j := i
+	for {
+		s := scanAtomBackward(nodes, j)
+		if s > j {
+			return start
+		}
+		start = s
+		if s-1 >= 0 && isArithmetic(nodes[s-1]) {
+			// 一元运算符
+			if nodes[s-1].isOperator("+", "-") && (s-2 < 0 || scanAtomBackward(nodes, s-2) > s-2) {
+				return s - 1
+			}
+			j = s - 2
+			continue
+		}
+		return start
+	}
+}
+
+// columnType 字段 oracle 数据类型，字段名大小写可能经 case-field-rule 转换
+func (t *oracleTranslator) columnType(name string) string {
+	if dataType, ok := t.ColumnTypes[name]; ok {
+		return common.StringUPPER(dataType)
+	}
+	for k, v := range t.ColumnTypes {
+		if strings.EqualFold(k, name) {
+			return common.StringUPPER(v)
+		}
+	}
+	return ""
+}
+
+// temporalType 单个操作数日期时间类型，非日期时间或者无法识别返回空
+// 识别 SYSDATE、SYSTIMESTAMP、TO_DATE、TRUNC 日期截断、日期字面量以及已知数据类型字段，视图字段数据类型未知
+func (t *oracleTranslator) temporalType(operand []*sqlNode) string {
+	switch {
+	case len(operand) == 0:
+		return ""
+	case len(operand) == 1 && operand[0].isGroup():
+		return t.temporalType(operand[0].children)
+	case len(operand) == 1 && operand[0].temporal != "":
+		return operand[0].temporal
+	case len(operand) == 2 && operand[0].isWord("DATE") && operand[1].isKind(tokenString):
+		return temporalDay
+	case len(operand) == 2 && operand[0].isWord("TIMESTAMP") && operand[1].isKind(tokenString):
+		return temporalTimestamp
+	}
+	for _, n := range operand {
+		if !n.isKind(tokenWord) && !n.isKind(tokenQuoted) && !n.isOperator(".") {
+			return ""
+		}
+	}
+	last := operand[len(operand)-1]
+	if last.isOperator(".") {
+		return ""
+	}
+	dataType := t.columnType(last.token.text)
+	switch {
+	case dataType == "DATE":
+		return temporalDate
+	case strings.HasPrefix(dataType, "TIMESTAMP"):
+		return temporalTimestamp
+	}
+	return ""
+}
+
+// translateDateArith oracle 日期加减运算改写，日期 ± 数值（天）改写 DATE_ADD/DATE_SUB，日期相减改写 DATEDIFF
+// 日期与数值相减、日期相加、TIMESTAMP 相减（oracle 返回 INTERVAL）以及日期参与乘除运算不支持改写
+// 加减运算字段数据类型未知（视图定义）无法确认是否日期运算，同样不支持改写
+// 日期 ± INTERVAL 字面量 mysql 原生支持，不做改写
+func (t *oracleTranslator) translateDateArith(nodes []*sqlNode) ([]*sqlNode, error) {
+	unsupported := func(start, end int) error {
+		return fmt.Errorf("unsupported construct [date arithmetic %s]", renderSQLNodes(nodes[start:end]))
+	}
+	for k := 0; k < len(nodes); k++ {
+		if !nodes[k].isOperator("+", "-") {
+			continue
+		}
+		ls := scanAtomBackward(nodes, k-1)
+		if ls > k-1 {
+			// 一元运算符
+			continue
+		}
+		rs := k + 1
+		if rs < len(nodes) && nodes[rs].isOperator("+", "-") {
+			rs++
+		}
+		if rs < len(nodes) && nodes[rs].isWord("INTERVAL") {
+			continue
+		}
+		re := scanAtomForward(nodes, rs)
+		if re == rs {
+			continue
+		}
+		lt, rt := t.temporalType(nodes[ls:k]), t.temporalType(nodes[rs:re])
+		// 字段数据类型未知无法区分日期运算与数值运算，参与乘除运算的字段为数值
+		unknown := (t.isUnknownColumn(nodes[ls:k]) && (ls-1 < 0 || !nodes[ls-1].isOperator("*", "/"))) ||
+			(t.isUnknownColumn(nodes[rs:re]) && (re >= len(nodes) || !nodes[re].isOperator("*", "/")))
+		if rt != "" && rs > k+1 {
+			return nil, unsupported(ls, re)
+		}
+		// 乘除运算优先级高于加减，右操作数延伸至乘除运算结束
+		for re < len(nodes) && nodes[re].isOperator("*", "/") {
+			next := scanAtomForward(nodes, re+1)
+			if next == re+1 {
+				break
+			}
+			if rt != "" || t.temporalType(nodes[re+1:next]) != "" {
+				return nil, unsupported(ls, next)
+			}
+			re = next
+		}
+		if lt == "" && rt == "" {
+			if unknown {
+				return nil, fmt.Errorf("unsupported construct [date arithmetic with unknown column data type %s]", renderSQLNodes(nodes[ls:re]))
+			}
+			continue
+		}
+		if lt != "" && ls-1 >= 0 && nodes[ls-1].isOperator("*", "/") {
+			return nil, unsupported(ls-1, re)
+		}
+
+		var (
+			start, end = ls, re
+			node       *sqlNode
+		)
+		switch {
+		case lt != "" && rt == "":
+			fn := "DATE_ADD"
+			if nodes[k].isOperator("-") {
+				fn = "DATE_SUB"
+			}
+			node = newTemporalNode(fmt.Sprintf("%s(%s, %s)", fn, renderSQLNodes(nodes[ls:k]), genDayInterval(nodes[k+1:re])), lt)
+			if lt == temporalDay && !isIntegerLiteral(nodes[k+1:re]) {
+				node.temporal = temporalDate
+			}
+		case lt == "" && rt != "" && nodes[k].isOperator("+"):
+			// 数值 + 日期，左操作数为加减运算整体
+			start = scanOperandBackward(nodes, k-1)
+			if rt == temporalDay && !isIntegerLiteral(nodes[start:k]) {
+				rt = temporalDate
+			}
+			node = newTemporalNode(fmt.Sprintf("DATE_ADD(%s, %s)", renderSQLNodes(nodes[rs:re]), genDayInterval(nodes[start:k])), rt)
+		case lt != "" && rt != "" && nodes[k].isOperator("-") && lt != temporalTimestamp && rt != temporalTimestamp:
+			if lt == temporalDay && rt == temporalDay {
+				node = newRawNode(fmt.Sprintf("DATEDIFF(%s, %s)", renderSQLNodes(nodes[ls:k]), renderSQLNodes(nodes[rs:re])))
+			} else {
+				// oracle 日期相减返回带小数天数，DATEDIFF 忽略时分秒
+				node = newRawNode(fmt.Sprintf("(TIMESTAMPDIFF(SECOND, %s, %s) / 86400)", renderSQLNodes(nodes[rs:re]), renderSQLNodes(nodes[ls:k])))
+			}
+		default:
+			return nil, unsupported(ls, re)
+		}
+
+		var result []*sqlNode
+		result = append(result, nodes[:start]...)
+		result = append(result, node)
+		result = append(result, nodes[end:]...)
+		nodes = result
+		k = start
+	}
+	return nodes, nil
+}
+
+// isUnknownColumn 操作数是否为数据类型未知的字段
+func (t *oracleTranslator) isUnknownColumn(operand []*sqlNode) bool {
+	if len(operand) == 0 || operand[len(operand)-1].isOperator(".") {
+		return false
+	}
+	for _, n := range operand {
+		if !n.isKind(tokenWord) && !n.isKind(tokenQuoted) && !n.isOperator(".") {
+			return false
+		}
+	}
+	return t.columnType(operand[len(operand)-1].token.text) == ""
+}
+
+func isIntegerLiteral(nodes []*sqlNode) bool {
+	return len(nodes) == 1 && nodes[0].isKind(tokenNumber) && !strings.ContainsAny(nodes[0].token.text, ".eE")
+}
+
+// genDayInterval oracle 日期加减数值单位为天，非整数天按秒计算且与 oracle DATE 一致精确到秒
+func genDayInterval(days []*sqlNode) string {
+	if isIntegerLiteral(days) {
+		return fmt.Sprintf("INTERVAL %s DAY", days[0].token.text)
+	}
+	return fmt.Sprintf("INTERVAL ROUND((%s) * 86400) SECOND", renderSQLNodes(days))
+}
+
+// translateConcat || 改写 CONCAT_WS，与 oracle 一致忽略 NULL 操作数
+func translateConcat(nodes []*sqlNode) ([]*sqlNode, error) {
+	for {
+		k := -1
+		for i, n := range nodes {
+			if n.isOperator("||") {
+				k = i
+				break
+			}
+		}
+		if k < 0 {
+			return nodes, nil
+		}
+		start := scanOperandBackward(nodes, k-1)
+		if start > k-1 {
+			return nil, fmt.Errorf("unsupported construct [|| concatenation]")
+		}
+		operands := []string{renderSQLNodes(nodes[start:k])}
+		pos := k
+		for pos < len(nodes) && nodes[pos].isOperator("||") {
+			end := scanOperandForward(nodes, pos+1)
+			if end == pos+1 {
+				return nil, fmt.Errorf("unsupported construct [|| concatenation]")
+			}
+			operands = append(operands, renderSQLNodes(nodes[pos+1:end]))
+			pos = end
+		}
+		var result []*sqlNode
+		result = append(result, nodes[:start]...)
+		result = append(result, newRawNode(fmt.Sprintf("CONCAT_WS('', %s)", strings.Join(operands, ", "))))
+		result = append(result, nodes[pos:]...)
+		nodes = result
+	}
+}
+
+// 聚合函数以及分析函数，ROWNUM 先于聚合、分析函数生效
+var oracleAggregateFunctions = []string{
+	"COUNT", "SUM", "AVG", "MIN", "MAX", "STDDEV", "VARIANCE", "MEDIAN", "LISTAGG", "WM_CONCAT", "COLLECT",
+}
+
+// isAggregateLevel 同层查询块是否存在聚合函数、分析函数或者 DISTINCT，子查询不计入
+func isAggregateLevel(nodes []*sqlNode) bool {
+	for i, n := range nodes {
+		switch {
+		case n.isWord("DISTINCT", "UNIQUE", "OVER"):
+			return true
+		case n.isWord(oracleAggregateFunctions...) && i+1 < len(nodes) && nodes[i+1].isGroup():
+			return true
+		case n.isGroup() && !hasQueryBlock(n.children) && isAggregateLevel(n.children):
+			return true
+		}
+	}
+	return false
+}
+
+// translateRownum WHERE ROWNUM <= N 改写 LIMIT N
+// ROWNUM 先于 ORDER BY、GROUP BY、DISTINCT 以及聚合函数生效，与 LIMIT 语义不一致，不支持改写
+func translateRownum(nodes []*sqlNode, aggregated bool) ([]*sqlNode, error) {
+	idx := -1
+	for i, n := range nodes {
+		if n.isWord("ROWNUM") {
+			if idx >= 0 {
+				return nil, fmt.Errorf("unsupported construct [multiple ROWNUM]")
+			}
+			idx = i
+		}
+	}
+	if idx < 0 {
+		return nodes, nil
+	}
+	if idx < 1 || idx+2 >= len(nodes) || !nodes[idx-1].isWord("WHERE", "AND") ||
+		!nodes[idx+1].isOperator("<=", "<", "=") || !nodes[idx+2].isKind(tokenNumber) {
+		return nil, fmt.Errorf("unsupported construct [ROWNUM]")
+	}
+	next := idx + 3
+	if next < len(nodes) && !nodes[next].isWord("AND") {
+		return nil, fmt.Errorf("unsupported construct [ROWNUM]")
+	}
+	for _, n := range nodes {
+		if n.isWord("ORDER", "GROUP", "HAVING", "UNION", "INTERSECT", "MINUS", "OR") {
+			return nil, fmt.Errorf("unsupported construct [ROWNUM with %s]", n.token.text)
+		}
+	}
+	if aggregated {
+		return nil, fmt.Errorf("unsupported construct [ROWNUM with aggregate or DISTINCT]")
+	}
+
+	limit, err := strconv.Atoi(nodes[idx+2].token.text)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported construct [ROWNUM %s %s]", nodes[idx+1].token.text, nodes[idx+2].token.text)
+	}
+	switch nodes[idx+1].token.text {
+	case "<":
+		limit--
+	case "=":
+		if limit != 1 {
+			return nil, fmt.Errorf("unsupported construct [ROWNUM = %d]", limit)
+		}
+	}
+	if limit < 0 {
+		limit = 0
+	}
+
+	// FROM (SELECT ... ORDER BY ...) WHERE ROWNUM <= N，ROWNUM 按子查询排序结果生效
+	// mysql 忽略派生表无 LIMIT 的 ORDER BY，LIMIT 下推至子查询，其余条件先于 ROWNUM 过滤，不支持下推
+	subquery, ordered := orderedFromSubquery(nodes)
+	if ordered {
+		if subquery == nil || !nodes[idx-1].isWord("WHERE") || next < len(nodes) {
+			return nil, fmt.Errorf("unsupported construct [ROWNUM over ordered subquery]")
+		}
+		subquery.children = append(subquery.children, newRawNode(fmt.Sprintf("LIMIT %d", limit)))
+		return nodes[:idx-1], nil
+	}
+
+	var result []*sqlNode
+	if nodes[idx-1].isWord("WHERE") && next < len(nodes) {
+		// WHERE ROWNUM <= N AND ...
+		result = append(result, nodes[:idx]...)
+		result = append(result, nodes[next+1:]...)
+	} else {
+		// WHERE ROWNUM <= N 或者 ... AND ROWNUM <= N
+		result = append(result, nodes[:idx-1]...)
+		result = append(result, nodes[next:]...)
+	}
+	return append(result, newRawNode(fmt.Sprintf("LIMIT %d", limit))), nil
+}
+
+// orderedFromSubquery FROM 子句是否存在带 ORDER BY 的子查询，FROM 仅单个子查询时返回该子查询
+func orderedFromSubquery(nodes []*sqlNode) (*sqlNode, bool) {
+	fromIdx, whereIdx := -1, len(nodes)
+	for i, n := range nodes {
+		if fromIdx < 0 && n.isWord("FROM") {
+			fromIdx = i
+		} else if fromIdx >= 0 && n.isWord("WHERE") {
+			whereIdx = i
+			break
+		}
+	}
+	if fromIdx < 0 {
+		return nil, false
+	}
+	var groups []*sqlNode
+	ordered := false
+	for _, n := range nodes[fromIdx+1 : whereIdx] {
+		if !n.isGroup() {
+			continue
+		}
+		groups = append(groups, n)
+		for i, c := range n.children {
+			if c.isWord("ORDER") && i+1 < len(n.children) && n.children[i+1].isWord("BY") {
+				ordered = true
+			}
+		}
+	}
+	if !ordered {
+		return nil, false
+	}
+	// (subquery) [AS] [alias]
+	from := nodes[fromIdx+1 : whereIdx]
+	if len(groups) == 1 && from[0] == groups[0] && len(from) <= 3 && len(groups[0].children) > 0 && groups[0].children[0].isWord("SELECT") {
+		for _, n := range from[1:] {
+			if !n.isWord("AS") && (isBoundaryKeyword(n) || (!n.isKind(tokenWord) && !n.isKind(tokenQuoted))) {
+				return nil, true
+			}
+		}
+		return groups[0], true
+	}
+	return nil, true
+}
+
+// translateOuterJoin (+) 外连接改写 LEFT JOIN，仅支持同层 FROM 逗号连接且 WHERE 条件以 AND 连接
+func translateOuterJoin(nodes []*sqlNode) ([]*sqlNode, error) {
+	hasMarker := false
+	for _, n := range nodes {
+		if n.isKind(tokenOuterJoin) {
+			hasMarker = true
+			break
+		}
+	}
+	if !hasMarker {
+		return nodes, nil
+	}
+	unsupported := func(reason string) error {
+		return fmt.Errorf("unsupported construct [(+) outer join %s]", reason)
+	}
+	if len(nodes) == 0 || !nodes[0].isWord("SELECT") {
+		return nil, unsupported("outside query block")
+	}
+
+	fromIdx, whereIdx, whereEnd := -1, -1, len(nodes)
+	for i, n := range nodes {
+		switch {
+		case fromIdx < 0 && n.isWord("FROM"):
+			fromIdx = i
+		case fromIdx >= 0 && whereIdx < 0 && n.isWord("WHERE"):
+			whereIdx = i
+		case whereIdx >= 0 && (n.isWord("GROUP", "ORDER", "HAVING", "UNION", "INTERSECT", "MINUS", "CONNECT", "START") ||
+			(n.isKind(tokenRaw) && strings.HasPrefix(n.token.text, "LIMIT"))):
+			if whereEnd == len(nodes) {
+				whereEnd = i
+			}
+		}
+	}
+	if fromIdx < 0 || whereIdx < 0 {
+		return nil, unsupported("without where clause")
+	}
+	for _, n := range nodes[whereEnd:] {
+		if n.isKind(tokenOuterJoin) {
+			return nil, unsupported("outside where clause")
+		}
+	}
+
+	type fromItem struct {
+		key   string
+		nodes []*sqlNode
+	}
+	var items []fromItem
+	for _, part := range splitSQLNodes(nodes[fromIdx+1:whereIdx], func(n *sqlNode) bool { return n.isKind(tokenComma) }) {
+		if len(part) == 0 {
+			return nil, unsupported("with empty from item")
+		}
+		for _, n := range part {
+			if n.isWord("JOIN", "ON", "USING") {
+				return nil, unsupported("mixed with ansi join")
+			}
+		}
+		last := part[len(part)-1]
+		if !last.isKind(tokenWord) && !last.isKind(tokenQuoted) {
+			return nil, unsupported("with unnamed from item")
+		}
+		items = append(items, fromItem{key: last.token.text, nodes: part})
+	}
+
+	// WHERE 条件按 AND 拆分，BETWEEN ... AND ... 不拆分
+	var (
+		conjuncts [][]*sqlNode
+		cur       []*sqlNode
+		between   bool
+	)
+	for _, n := range nodes[whereIdx+1 : whereEnd] {
+		if n.isWord("OR") {
+			return nil, unsupported("with OR condition")
+		}
+		if n.isWord("BETWEEN") {
+			between = true
+		}
+		if n.isWord("AND") {
+			if between {
+				between = false
+			} else {
+				conjuncts = append(conjuncts, cur)
+				cur = nil
+				continue
+			}
+		}
+		cur = append(cur, n)
+	}
+	conjuncts = append(conjuncts, cur)
+
+	var (
+		optional  []string
+		joinConds = make(map[string][]string)
+		joinDeps  = make(map[string]map[string]bool)
+		remains   []string
+	)
+	for _, conj := range conjuncts {
+		var (
+			marked   = make(map[string]bool)
+			deps     = make(map[string]bool)
+			stripped []*sqlNode
+		)
+		for k, n := range conj {
+			if n.isKind(tokenOuterJoin) {
+				if k < 3 || !conj[k-2].isOperator(".") {
+					return nil, unsupported("column without table qualifier")
+				}
+				marked[conj[k-3].token.text] = true
+				continue
+			}
+			stripped = append(stripped, n)
+			if (n.isKind(tokenWord) || n.isKind(tokenQuoted)) && k+2 < len(conj) && conj[k+1].isOperator(".") &&
+				(k == 0 || !conj[k-1].isOperator(".")) {
+				deps[n.token.text] = true
+			}
+		}
+		if len(marked) == 0 {
+			remains = append(remains, renderSQLNodes(conj))
+			continue
+		}
+		if len(marked) > 1 {
+			return nil, unsupported("with multiple outer tables in one condition")
+		}
+		var outer string
+		for k := range marked {
+			outer = k
+		}
+		if _, ok := joinConds[outer]; !ok {
+			optional = append(optional, outer)
+			joinDeps[outer] = make(map[string]bool)
+		}
+		joinConds[outer] = append(joinConds[outer], renderSQLNodes(stripped))
+		for d := range deps {
+			if d != outer {
+				joinDeps[outer][d] = true
+			}
+		}
+	}
+
+	var (
+		fromSQL  strings.Builder
+		emitted  = make(map[string]bool)
+		pending  []fromItem
+		required int
+	)
+	for _, item := range items {
+		if _, ok := joinConds[item.key]; ok {
+			pending = append(pending, item)
+			continue
+		}
+		if required > 0 {
+			fromSQL.WriteString(" CROSS JOIN ")
+		}
+		fromSQL.WriteString(renderSQLNodes(item.nodes))
+		emitted[item.key] = true
+		required++
+	}
+	if required == 0 {
+		return nil, unsupported("without inner table")
+	}
+	if len(pending) != len(optional) {
+		return nil, unsupported("with unknown table qualifier")
+	}
+	for len(pending) > 0 {
+		progress := false
+		var rest []fromItem
+		for _, item := range pending {
+			ready := true
+			for d := range joinDeps[item.key] {
+				if !emitted[d] {
+					ready = false
+					break
+				}
+			}
+			if !ready {
+				rest = append(rest, item)
+				continue
+			}
+			fromSQL.WriteString(fmt.Sprintf(" LEFT JOIN %s ON %s", renderSQLNodes(item.nodes), strings.Join(joinConds[item.key], " AND ")))
+			emitted[item.key] = true
+			progress = true
+		}
+		if !progress {
+			return nil, unsupported("with circular or unknown table dependency")
+		}
+		pending = rest
+	}
+
+	var result []*sqlNode
+	result = append(result, nodes[:fromIdx+1]...)
+	result = append(result, newRawNode(fromSQL.String()))
+	if len(remains) > 0 {
+		result = append(result, nodes[whereIdx], newRawNode(strings.Join(remains, " AND ")))
+	}
+	return append(result, nodes[whereEnd:]...), nil
+}
@@ -0,0 +1,196 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package o2m
+
+import (
+	"github.com/wentaojin/transferdb/common"
+	"strings"
+	"testing"
+)
+
+func TestTranslateOracleExpr(t *testing.T) {
+	columnTypes := map[string]string{"HIRE_DATE": "DATE", "END_DATE": "DATE", "TS": "TIMESTAMP(6)", "SAL": "NUMBER", "BONUS": "NUMBER"}
+
+	cases := []struct {
+		name string
+		expr string
+		want string
+		// 非空表示期望改写失败，错误信息包含该内容
+		err string
+	}{
+		{name: "nvl", expr: "NVL(a, 0)", want: "IFNULL(A, 0)"},
+		{name: "nvl with one argument", expr: "NVL(a)", err: "NVL with 1 arguments"},
+		{name: "decode with default", expr: "DECODE(a, 1, 'x', NULL, 'n', 'y')",
+			want: "CASE WHEN (A) <=> (1) THEN 'x' WHEN (A) <=> (NULL) THEN 'n' ELSE 'y' END"},
+		{name: "decode without default", expr: "DECODE(a, 1, 'x')", want: "CASE WHEN (A) <=> (1) THEN 'x' END"},
+		{name: "concat", expr: "a || 'b' || c", want: "CONCAT_WS('', A, 'b', C)"},
+		{name: "concat arithmetic operand", expr: "a || sal + 1", want: "CONCAT_WS('', A, SAL + 1)"},
+		{name: "rownum", expr: "SELECT * FROM t WHERE ROWNUM <= 10", want: "SELECT * FROM T LIMIT 10"},
+		{name: "rownum less than with condition", expr: "SELECT * FROM t WHERE ROWNUM < 10 AND a = 1", want: "SELECT * FROM T WHERE A = 1 LIMIT 9"},
+		{name: "rownum after condition", expr: "SELECT * FROM t WHERE a = 1 AND ROWNUM <= 5", want: "SELECT * FROM T WHERE A = 1 LIMIT 5"},
+		{name: "rownum with order by", expr: "SELECT * FROM t WHERE ROWNUM <= 10 ORDER BY a", err: "ROWNUM"},
+		{name: "rownum with aggregate", expr: "SELECT MAX(a) FROM t WHERE ROWNUM = 1", err: "ROWNUM with aggregate"},
+		{name: "rownum with nested aggregate", expr: "SELECT NVL(MAX(a), 0) FROM t WHERE ROWNUM <= 1", err: "ROWNUM with aggregate"},
+		{name: "rownum with distinct", expr: "SELECT DISTINCT a FROM t WHERE ROWNUM <= 5", err: "ROWNUM with aggregate"},
+		{name: "rownum with analytic", expr: "SELECT a, COUNT(*) OVER () FROM t WHERE ROWNUM <= 5", err: "ROWNUM with aggregate"},
+		{name: "rownum in aggregate subquery", expr: "SELECT (SELECT MAX(a) FROM t WHERE ROWNUM = 1) FROM u", err: "ROWNUM with aggregate"},
+		{name: "rownum subquery under aggregate", expr: "SELECT MAX(x) FROM u WHERE y IN (SELECT a FROM t WHERE ROWNUM <= 5)",
+			want: "SELECT MAX(X) FROM U WHERE Y IN (SELECT A FROM T LIMIT 5)"},
+		{name: "rownum over ordered subquery", expr: "SELECT * FROM (SELECT a FROM t ORDER BY a DESC) WHERE ROWNUM <= 10",
+			want: "SELECT * FROM (SELECT A FROM T ORDER BY A DESC LIMIT 10)"},
+		{name: "rownum over ordered subquery alias", expr: "SELECT * FROM (SELECT a FROM t ORDER BY a DESC) v WHERE ROWNUM <= 10",
+			want: "SELECT * FROM (SELECT A FROM T ORDER BY A DESC LIMIT 10) V"},
+		{name: "rownum over ordered subquery with condition", expr: "SELECT * FROM (SELECT a FROM t ORDER BY a DESC) WHERE a > 1 AND ROWNUM <= 10",
+			err: "ROWNUM over ordered subquery"},
+		{name: "rownum over ordered subquery join", expr: "SELECT * FROM (SELECT a FROM t ORDER BY a) x, u WHERE ROWNUM <= 10",
+			err: "ROWNUM over ordered subquery"},
+		{name: "outer join", expr: "SELECT a.id, b.name FROM a, b WHERE a.id = b.id(+)",
+			want: "SELECT A.ID, B.NAME FROM A LEFT JOIN B ON A.ID = B.ID"},
+		{name: "outer join with remaining condition", expr: "SELECT a.id FROM a, b WHERE a.id = b.id(+) AND a.x = 1",
+			want: "SELECT A.ID FROM A LEFT JOIN B ON A.ID = B.ID WHERE A.X = 1"},
+		{name: "outer join with or", expr: "SELECT a.id FROM a, b WHERE a.id = b.id(+) OR a.x = 1", err: "(+) outer join with OR condition"},
+		{name: "sysdate minus days", expr: "SYSDATE - 1", want: "DATE_SUB(NOW(), INTERVAL 1 DAY)"},
+		{name: "sysdate plus fractional days", expr: "SYSDATE + 1/24", want: "DATE_ADD(NOW(), INTERVAL ROUND((1 / 24) * 86400) SECOND)"},
+		{name: "days plus sysdate", expr: "1 + SYSDATE", want: "DATE_ADD(NOW(), INTERVAL 1 DAY)"},
+		{name: "date column plus days", expr: "HIRE_DATE + 30", want: "DATE_ADD(HIRE_DATE, INTERVAL 30 DAY)"},
+		{name: "date column minus date column", expr: "END_DATE - HIRE_DATE", want: "(TIMESTAMPDIFF(SECOND, HIRE_DATE, END_DATE) / 86400)"},
+		{name: "truncated date minus truncated date", expr: "TRUNC(SYSDATE) - TRUNC(HIRE_DATE)", want: "DATEDIFF(DATE(NOW()), DATE(HIRE_DATE))"},
+		{name: "date literal minus date literal", expr: "DATE '2020-01-01' - DATE '2019-01-01'", want: "DATEDIFF(DATE '2020-01-01', DATE '2019-01-01')"},
+		{name: "chained date arithmetic", expr: "SYSDATE - 1 + 2", want: "DATE_ADD(DATE_SUB(NOW(), INTERVAL 1 DAY), INTERVAL 2 DAY)"},
+		{name: "arithmetic days plus sysdate", expr: "sal - bonus + SYSDATE", want: "DATE_ADD(NOW(), INTERVAL ROUND((SAL - BONUS) * 86400) SECOND)"},
+		{name: "to_date plus days", expr: "TO_DATE('2020-01-01', 'YYYY-MM-DD') + 7", want: "DATE_ADD(STR_TO_DATE('2020-01-01', '%Y-%m-%d'), INTERVAL 7 DAY)"},
+		{name: "date arithmetic in function", expr: "TO_CHAR(SYSDATE - 1, 'YYYY-MM-DD')", want: "DATE_FORMAT(DATE_SUB(NOW(), INTERVAL 1 DAY), '%Y-%m-%d')"},
+		{name: "date arithmetic in condition", expr: "x > SYSDATE - 1 AND y = 2", want: "X > DATE_SUB(NOW(), INTERVAL 1 DAY) AND Y = 2"},
+		{name: "date minus interval", expr: "SYSDATE - INTERVAL '1' DAY", want: "NOW() - INTERVAL '1' DAY"},
+		{name: "number column plus number", expr: "SAL + 1", want: "SAL + 1"},
+		{name: "days minus date", expr: "1 - SYSDATE", err: "date arithmetic"},
+		{name: "date plus date", expr: "SYSDATE + HIRE_DATE", err: "date arithmetic"},
+		{name: "timestamp minus timestamp", expr: "TS - TS", err: "date arithmetic"},
+		{name: "date multiplied", expr: "2 * SYSDATE - 1", err: "date arithmetic"},
+		{name: "string literal backslash", expr: `'a\b'`, want: `'a\\b'`},
+		{name: "function argument backslash", expr: `REPLACE(a, '\', '/')`, want: `REPLACE(A, '\\', '/')`},
+		{name: "date format backslash", expr: `TO_CHAR(d, 'YYYY"\"MM')`, want: `DATE_FORMAT(D, '%Y\\%m')`},
+	}
+
+	for _, c := range cases {
+		got, err := TranslateOracleExpr(c.expr, "S", "T", columnTypes, nil)
+		if c.err != "" {
+			if err == nil || !strings.Contains(err.Error(), c.err) {
+				t.Errorf("case [%s] translate [%s] error [%v], want error contains [%s]", c.name, c.expr, err, c.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("case [%s] translate [%s] failed: %v", c.name, c.expr, err)
+			continue
+		}
+		if got != c.want {
+			t.Errorf("case [%s] translate [%s] got [%s], want [%s]", c.name, c.expr, got, c.want)
+		}
+	}
+}
+
+func TestTranslateOracleSQLUnknownColumnType(t *testing.T) {
+	// 视图字段数据类型未知，字段加减运算无法确认是否日期运算
+	cases := []struct {
+		name string
+		sql  string
+		want string
+		err  string
+	}{
+		{name: "sysdate arithmetic", sql: "SELECT id, SYSDATE - 1 FROM s.emp", want: "SELECT ID, DATE_SUB(NOW(), INTERVAL 1 DAY) FROM `T`.EMP"},
+		{name: "multiplied column", sql: "SELECT sal * 12 + 100 FROM s.emp", want: "SELECT SAL * 12 + 100 FROM `T`.EMP"},
+		{name: "column plus number", sql: "SELECT hire_date + 1 FROM s.emp", err: "date arithmetic with unknown column data type HIRE_DATE + 1"},
+		{name: "column minus column", sql: "SELECT e.end_date - e.hire_date FROM s.emp e", err: "date arithmetic with unknown column data type"},
+	}
+	for _, c := range cases {
+		got, err := TranslateOracleSQL(c.sql, "S", "T")
+		if c.err != "" {
+			if err == nil || !strings.Contains(err.Error(), c.err) {
+				t.Errorf("case [%s] translate [%s] error [%v], want error contains [%s]", c.name, c.sql, err, c.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("case [%s] translate [%s] failed: %v", c.name, c.sql, err)
+			continue
+		}
+		if got != c.want {
+			t.Errorf("case [%s] translate [%s] got [%s], want [%s]", c.name, c.sql, got, c.want)
+		}
+	}
+}
+
+func TestTranslateOracleView(t *testing.T) {
+	longView := strings.Repeat("V", 70)
+	nameRule := newViewNameRule(
+		map[string]string{"EMP": "EMPLOYEE", "DEPT": "DEPT"},
+		map[string]map[string]string{"EMP": {"ENAME": "EMP_NAME"}},
+		[]map[string]string{
+			{"TABLE_NAME": "EMP", "COLUMN_NAME": "ID"},
+			{"TABLE_NAME": "EMP", "COLUMN_NAME": "ENAME"},
+			{"TABLE_NAME": "EMP", "COLUMN_NAME": "DEPTNO"},
+			{"TABLE_NAME": "DEPT", "COLUMN_NAME": "DEPTNO"},
+			{"TABLE_NAME": "DEPT", "COLUMN_NAME": "DNAME"},
+		},
+		[]map[string]string{
+			{"TABLE_NAME": longView, "COLUMN_NAME": "ID"},
+		},
+		common.ReverseIdentifierPolicyShorten)
+	shortView := common.StringShortenIdentifier(longView, common.MySQLIdentifierMaxLength)
+
+	cases := []struct {
+		name string
+		sql  string
+		want string
+		err  string
+	}{
+		{name: "unqualified column", sql: "SELECT id, ename FROM s.emp",
+			want: "SELECT ID, `EMP_NAME` FROM `T`.`EMPLOYEE`"},
+		{name: "alias join", sql: "SELECT e.ename, d.dname FROM s.emp e JOIN dept d ON e.deptno = d.deptno WHERE e.ename LIKE 'A%'",
+			want: "SELECT E.`EMP_NAME`, D.DNAME FROM `T`.`EMPLOYEE` E JOIN DEPT D ON E.DEPTNO = D.DEPTNO WHERE E.`EMP_NAME` LIKE 'A%'"},
+		{name: "table name qualifier", sql: "SELECT emp.ename FROM emp",
+			want: "SELECT `EMPLOYEE`.`EMP_NAME` FROM `EMPLOYEE`"},
+		{name: "schema table column", sql: "SELECT s.emp.ename FROM s.emp",
+			want: "SELECT `T`.`EMPLOYEE`.`EMP_NAME` FROM `T`.`EMPLOYEE`"},
+		{name: "derived table keeps source column name", sql: "SELECT x.ename FROM (SELECT ename FROM emp) x",
+			want: "SELECT X.ENAME FROM (SELECT `EMP_NAME` AS `ENAME` FROM `EMPLOYEE`) X"},
+		{name: "correlated subquery", sql: "SELECT d.dname FROM dept d WHERE EXISTS (SELECT 1 FROM emp e WHERE e.deptno = d.deptno AND ename = 'X')",
+			want: "SELECT D.DNAME FROM DEPT D WHERE EXISTS (SELECT 1 FROM `EMPLOYEE` E WHERE E.DEPTNO = D.DEPTNO AND `EMP_NAME` = 'X')"},
+		{name: "select alias", sql: "SELECT deptno AS ename FROM dept", want: "SELECT DEPTNO AS ENAME FROM DEPT"},
+		{name: "other schema", sql: "SELECT ename FROM hr.emp", want: "SELECT ENAME FROM HR.EMP"},
+		{name: "shortened view name", sql: "SELECT id FROM " + longView, want: "SELECT ID FROM `" + shortView + "`"},
+		{name: "star over renamed columns in subquery", sql: "SELECT * FROM (SELECT * FROM emp)", err: "* over object EMP"},
+		{name: "unresolved column", sql: "SELECT (SELECT MAX(a) FROM (SELECT 1 a FROM dual) x WHERE a = ename) FROM emp",
+			err: "column ENAME can't resolve"},
+	}
+	for _, c := range cases {
+		got, err := translateOracleView(c.sql, "S", "T", nameRule)
+		if c.err != "" {
+			if err == nil || !strings.Contains(err.Error(), c.err) {
+				t.Errorf("case [%s] translate [%s] error [%v], want error contains [%s]", c.name, c.sql, err, c.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("case [%s] translate [%s] failed: %v", c.name, c.sql, err)
+			continue
+		}
+		if got != c.want {
+			t.Errorf("case [%s] translate [%s] got [%s], want [%s]", c.name, c.sql, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,264 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package o2m
+
+import (
+	"fmt"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/pingcap/parser"
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/database/oracle"
+	"github.com/wentaojin/transferdb/module/reverse"
+	"go.uber.org/zap"
+	"sort"
+	"strings"
+	"time"
+
+	_ "github.com/pingcap/tidb/types/parser_driver"
+)
+
+type viewDDL struct {
+	ViewName       string
+	TargetViewName string
+	SQL            string
+	Reason         string
+}
+
+// GenCreateView 视图定义改写 mysql/tidb 语法并经 parser 校验，按视图依赖顺序输出
+// 改写或校验失败以及依赖失败视图的视图输出不兼容项
+// 视图引用表名、视图名以及字段名按所属对象名称映射规则改写，无法确定字段所属对象输出不兼容项
+// 视图名称以及视图字段名称按超长标识符处理策略转换
+func GenCreateView(w *reverse.Write, oracle *oracle.Oracle, sourceSchema, targetSchema string, tableNameRule map[string]string, columnNameRule map[string]map[string]string, identifierPolicy string, directWrite bool) error {
+	startTime := time.Now()
+	views, err := oracle.GetOracleSchemaView(sourceSchema)
+	if err != nil {
+		return err
+	}
+	if len(views) == 0 {
+		return nil
+	}
+	viewColumns, err := oracle.GetOracleSchemaViewColumn(sourceSchema)
+	if err != nil {
+		return err
+	}
+	tableColumns, err := oracle.GetOracleSchemaTableColumnName(sourceSchema)
+	if err != nil {
+		return err
+	}
+	dependencies, err := oracle.GetOracleSchemaViewDependency(sourceSchema)
+	if err != nil {
+		return err
+	}
+
+	nameRule := newViewNameRule(tableNameRule, columnNameRule, tableColumns, viewColumns, identifierPolicy)
+
+	columnMap := make(map[string][]string)
+	for _, col := range viewColumns {
+		columnMap[col["TABLE_NAME"]] = append(columnMap[col["TABLE_NAME"]], fmt.Sprintf("`%s`", common.StringIdentifierPolicy(col["COLUMN_NAME"], identifierPolicy)))
+	}
+
+	viewMap := make(map[string]*viewDDL)
+	var viewNames []string
+	for _, v := range views {
+		ddl := &viewDDL{ViewName: v["VIEW_NAME"], TargetViewName: common.StringIdentifierPolicy(v["VIEW_NAME"], identifierPolicy)}
+		ddl.SQL, ddl.Reason = genViewSQL(sourceSchema, targetSchema, ddl.TargetViewName, v["TEXT"], columnMap[v["VIEW_NAME"]], nameRule)
+		viewMap[ddl.ViewName] = ddl
+		viewNames = append(viewNames, ddl.ViewName)
+	}
+
+	depMap := make(map[string][]string)
+	for _, dep := range dependencies {
+		if _, ok := viewMap[dep["REFERENCED_NAME"]]; !ok || dep["NAME"] == dep["REFERENCED_NAME"] {
+			continue
+		}
+		depMap[dep["NAME"]] = append(depMap[dep["NAME"]], dep["REFERENCED_NAME"])
+	}
+
+	ordered, failed := sortViewByDependency(viewNames, viewMap, depMap)
+
+	if len(ordered) > 0 {
+		var sqlRev strings.Builder
+		sqlRev.WriteString("/*\n")
+		sqlRev.WriteString(" oracle view reverse sql \n")
+		t := table.NewWriter()
+		t.SetStyle(table.StyleLight)
+		t.AppendHeader(table.Row{"#", "ORACLE", "MYSQL", "SUGGEST"})
+		for _, v := range ordered {
+			t.AppendRows([]table.Row{
+				{"VIEW", fmt.Sprintf("%s.%s", sourceSchema, v.ViewName), fmt.Sprintf("%s.%s", targetSchema, v.TargetViewName), "Create View"},
+			})
+		}
+		sqlRev.WriteString(t.Render() + "\n")
+		sqlRev.WriteString("*/\n")
+		for _, v := range ordered {
+			sqlRev.WriteString(v.SQL + "\n")
+		}
+		sqlRev.WriteString("\n")
+
+		if directWrite {
+			if err = w.RWriteDB(sqlRev.String()); err != nil {
+				return err
+			}
+		} else {
+			if _, err = w.RWriteFile(sqlRev.String()); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(failed) > 0 {
+		var sqlComp strings.Builder
+		sqlComp.WriteString("/*\n")
+		sqlComp.WriteString(" oracle view maybe mysql has compatibility, will skip convert to reverse, please manual process\n")
+		t := table.NewWriter()
+		t.SetStyle(table.StyleLight)
+		t.AppendHeader(table.Row{"#", "ORACLE", "MYSQL", "REASON", "SUGGEST"})
+		for _, v := range failed {
+			t.AppendRows([]table.Row{
+				{"VIEW", fmt.Sprintf("%s.%s", sourceSchema, v.ViewName), fmt.Sprintf("%s.%s", targetSchema, v.TargetViewName), v.Reason, "Manual Process View"},
+			})
+		}
+		sqlComp.WriteString(t.Render() + "\n")
+		sqlComp.WriteString("*/\n")
+		if _, err = w.CWriteFile(sqlComp.String()); err != nil {
+			return err
+		}
+		zap.L().Warn("reverse oracle view",
+			zap.String("schema", sourceSchema),
+			zap.Int("incompatible view counts", len(failed)),
+			zap.String("suggest", "view definition can't convert, detail see compatibility output"))
+	}
+
+	zap.L().Info("output oracle to mysql view create sql",
+		zap.String("schema", sourceSchema),
+		zap.Int("view counts", len(ordered)),
+		zap.String("cost", time.Now().Sub(startTime).String()))
+	return nil
+}
+
+// newViewNameRule 视图定义引用对象名称映射规则
+// 表名、表字段名以表名、字段名映射规则（含超长标识符截断规则）为准，视图名、视图字段名与视图创建一致按超长标识符处理策略转换
+func newViewNameRule(tableNameRule map[string]string, columnNameRule map[string]map[string]string, tableColumns, viewColumns []map[string]string, identifierPolicy string) *viewNameRule {
+	nameRule := &viewNameRule{
+		TableNameRule:  make(map[string]string),
+		TableColumns:   make(map[string]map[string]struct{}),
+		ColumnNameRule: make(map[string]map[string]string),
+	}
+	for tableS, tableT := range tableNameRule {
+		nameRule.TableNameRule[common.StringUPPER(tableS)] = tableT
+	}
+	for tableS, rule := range columnNameRule {
+		nameRule.ColumnNameRule[common.StringUPPER(tableS)] = rule
+	}
+	for _, col := range tableColumns {
+		table := common.StringUPPER(col["TABLE_NAME"])
+		if _, ok := nameRule.TableColumns[table]; !ok {
+			nameRule.TableColumns[table] = make(map[string]struct{})
+		}
+		nameRule.TableColumns[table][common.StringUPPER(col["COLUMN_NAME"])] = struct{}{}
+	}
+	for _, col := range viewColumns {
+		view, column := common.StringUPPER(col["TABLE_NAME"]), common.StringUPPER(col["COLUMN_NAME"])
+		if _, ok := nameRule.TableColumns[view]; !ok {
+			nameRule.TableColumns[view] = make(map[string]struct{})
+			nameRule.ColumnNameRule[view] = make(map[string]string)
+			nameRule.TableNameRule[view] = common.StringIdentifierPolicy(view, identifierPolicy)
+		}
+		nameRule.TableColumns[view][column] = struct{}{}
+		nameRule.ColumnNameRule[view][column] = common.StringIdentifierPolicy(column, identifierPolicy)
+	}
+	return nameRule
+}
+
+func genViewSQL(sourceSchema, targetSchema, viewName, viewText string, columns []string, nameRule *viewNameRule) (string, string) {
+	querySQL, err := translateOracleView(viewText, sourceSchema, targetSchema, nameRule)
+	if err != nil {
+		return "", err.Error()
+	}
+	var viewSQL string
+	if len(columns) > 0 {
+		viewSQL = fmt.Sprintf("CREATE OR REPLACE VIEW `%s`.`%s` (%s) AS %s;", targetSchema, viewName, strings.Join(columns, ","), querySQL)
+	} else {
+		viewSQL = fmt.Sprintf("CREATE OR REPLACE VIEW `%s`.`%s` AS %s;", targetSchema, viewName, querySQL)
+	}
+	if _, _, err = parser.New().Parse(viewSQL, "", ""); err != nil {
+		zap.L().Warn("reverse oracle view parse failed",
+			zap.String("schema", sourceSchema),
+			zap.String("view", viewName),
+			zap.String("sql", viewSQL),
+			zap.Error(err))
+		return "", fmt.Sprintf("parse failed after rewrite: %v", err)
+	}
+	return viewSQL, ""
+}
+
+// sortViewByDependency 按依赖拓扑排序，被依赖视图优先创建
+// 依赖失败视图或者循环依赖的视图同样视为失败
+func sortViewByDependency(viewNames []string, viewMap map[string]*viewDDL, depMap map[string][]string) ([]*viewDDL, []*viewDDL) {
+	var (
+		ordered []*viewDDL
+		failed  []*viewDDL
+		done    = make(map[string]bool)
+	)
+	sort.Strings(viewNames)
+	for _, name := range viewNames {
+		if viewMap[name].Reason != "" {
+			failed = append(failed, viewMap[name])
+			done[name] = true
+		}
+	}
+
+	for {
+		progress := false
+		for _, name := range viewNames {
+			if done[name] {
+				continue
+			}
+			ready := true
+			for _, dep := range depMap[name] {
+				if !done[dep] {
+					ready = false
+					break
+				}
+				if viewMap[dep].Reason != "" {
+					viewMap[name].Reason = fmt.Sprintf("depend on incompatible view [%s]", dep)
+					break
+				}
+			}
+			if !ready {
+				continue
+			}
+			done[name] = true
+			progress = true
+			if viewMap[name].Reason != "" {
+				failed = append(failed, viewMap[name])
+			} else {
+				ordered = append(ordered, viewMap[name])
+			}
+		}
+		if !progress {
+			break
+		}
+	}
+
+	for _, name := range viewNames {
+		if !done[name] {
+			viewMap[name].Reason = "circular view dependency"
+			failed = append(failed, viewMap[name])
+		}
+	}
+	return ordered, failed
+}
@@ -0,0 +1,172 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package o2p
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/wentaojin/transferdb/module/reverse"
+	"go.uber.org/zap"
+	"strings"
+	"time"
+)
+
+type DDL struct {
+	SourceSchemaName   string   `json:"source_schema"`
+	SourceTableName    string   `json:"source_table_name"`
+	SourceTableType    string   `json:"source_table_type"`
+	TargetSchemaName   string   `json:"target_schema"`
+	TargetTableName    string   `json:"target_table_name"`
+	TargetDBVersion    string   `json:"target_db_version"`
+	TablePrefix        string   `json:"table_prefix"`
+	TableColumns       []string `json:"table_columns"`
+	TableKeys          []string `json:"table_keys"`
+	TableIndexes       []string `json:"table_indexes"`
+	TableComment       string   `json:"table_comment"`
+	ColumnComments     []string `json:"column_comments"`
+	TableCheckKeys     []string `json:"table_check_keys"`
+	TableForeignKeys   []string `json:"table_foreign_keys"`
+	TableCompatibleDDL []string `json:"table_compatible_ddl"`
+}
+
+// Write 输出建表、索引、检查约束以及注释语句，外键由 GenForeignKey 在所有表创建之后统一输出
+func (d *DDL) Write(w *reverse.Write) error {
+	var (
+		sqlRev  strings.Builder
+		sqlComp strings.Builder
+	)
+
+	zap.L().Info("reverse oracle table struct", zap.String("table", d.String()))
+
+	sqlRev.WriteString("/*\n")
+	sqlRev.WriteString(" oracle table reverse sql \n")
+
+	sw := table.NewWriter()
+	sw.SetStyle(table.StyleLight)
+	sw.AppendHeader(table.Row{"#", "ORACLE TABLE TYPE", "ORACLE", "POSTGRES", "SUGGEST"})
+	sw.AppendRows([]table.Row{
+		{"TABLE", d.SourceTableType, fmt.Sprintf("%s.%s", d.SourceSchemaName, d.SourceTableName), fmt.Sprintf("%s.%s", d.TargetSchemaName, d.TargetTableName), "Create Table"},
+	})
+	sqlRev.WriteString(fmt.Sprintf("%v\n", sw.Render()))
+	sqlRev.WriteString("*/\n")
+
+	sqlRev.WriteString(d.GenCreateTableSQL() + "\n")
+	for _, sql := range d.TableIndexes {
+		sqlRev.WriteString(sql + "\n")
+	}
+	for _, sql := range d.TableCheckKeys {
+		sqlRev.WriteString(sql + "\n")
+	}
+	if d.TableComment != "" {
+		sqlRev.WriteString(d.TableComment + "\n")
+	}
+	for _, sql := range d.ColumnComments {
+		sqlRev.WriteString(sql + "\n")
+	}
+	sqlRev.WriteString("\n")
+
+	// 兼容项处理
+	if len(d.TableCompatibleDDL) > 0 {
+		sqlComp.WriteString("/*\n")
+		sqlComp.WriteString(" oracle table index or consrtaint maybe postgres has compatibility, skip\n")
+		tw := table.NewWriter()
+		tw.SetStyle(table.StyleLight)
+		tw.AppendHeader(table.Row{"#", "ORACLE", "POSTGRES", "SUGGEST"})
+		tw.AppendRows([]table.Row{
+			{"TABLE", fmt.Sprintf("%s.%s", d.SourceSchemaName, d.SourceTableName), fmt.Sprintf("%s.%s", d.TargetSchemaName, d.TargetTableName), "Create Index Or Constraints"}})
+
+		sqlComp.WriteString(fmt.Sprintf("%v\n", tw.Render()))
+		sqlComp.WriteString("*/\n")
+		for _, sql := range d.TableCompatibleDDL {
+			sqlComp.WriteString(sql + "\n")
+		}
+	}
+
+	// 文件写入
+	if w.Cfg.ReverseConfig.DirectWrite {
+		if err := w.RWriteDB(sqlRev.String()); err != nil {
+			return err
+		}
+	} else {
+		if _, err := w.RWriteFile(sqlRev.String()); err != nil {
+			return err
+		}
+	}
+	if sqlComp.String() != "" {
+		if _, err := w.CWriteFile(sqlComp.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GenCreateTableSQL 生成单条 CREATE TABLE 语句（不含索引、外键、检查约束以及注释）
+func (d *DDL) GenCreateTableSQL() string {
+	if len(d.TableKeys) > 0 {
+		return fmt.Sprintf("%s (\n%s,\n%s\n);",
+			d.TablePrefix,
+			strings.Join(d.TableColumns, ",\n"),
+			strings.Join(d.TableKeys, ",\n"))
+	}
+	return fmt.Sprintf("%s (\n%s\n);",
+		d.TablePrefix,
+		strings.Join(d.TableColumns, ",\n"))
+}
+
+func (d *DDL) String() string {
+	jsonBytes, _ := json.Marshal(d)
+	return string(jsonBytes)
+}
+
+// GenForeignKey 外键依赖父表，所有表创建之后统一输出
+// foreign-key-post-load 开启时输出外键延后执行脚本，全量数据加载之后执行
+func GenForeignKey(w *reverse.Write, sourceSchema string, foreignKeys []string, postLoad, directWrite bool) error {
+	if len(foreignKeys) == 0 {
+		return nil
+	}
+	startTime := time.Now()
+
+	var sqlFK strings.Builder
+	sqlFK.WriteString("/*\n")
+	sqlFK.WriteString(" oracle table foreign key reverse sql \n")
+	sqlFK.WriteString("*/\n")
+	for _, sql := range foreignKeys {
+		sqlFK.WriteString(sql + "\n")
+	}
+	sqlFK.WriteString("\n")
+
+	switch {
+	case postLoad:
+		if _, err := w.FWriteFile(sqlFK.String()); err != nil {
+			return err
+		}
+	case directWrite:
+		if err := w.RWriteDB(sqlFK.String()); err != nil {
+			return err
+		}
+	default:
+		if _, err := w.RWriteFile(sqlFK.String()); err != nil {
+			return err
+		}
+	}
+	zap.L().Info("output oracle to postgres foreign key sql",
+		zap.String("schema", sourceSchema),
+		zap.Int("foreign key counts", len(foreignKeys)),
+		zap.Bool("post load", postLoad),
+		zap.String("cost", time.Now().Sub(startTime).String()))
+	return nil
+}
@@ -0,0 +1,135 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package o2p
+
+import (
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"strings"
+)
+
+// postgres 同名同语义函数，NVL 改写 COALESCE
+var postgresExprFunctions = map[string]string{
+	"UPPER":    "UPPER",
+	"LOWER":    "LOWER",
+	"TRIM":     "TRIM",
+	"LTRIM":    "LTRIM",
+	"RTRIM":    "RTRIM",
+	"LENGTH":   "LENGTH",
+	"SUBSTR":   "SUBSTR",
+	"REPLACE":  "REPLACE",
+	"LPAD":     "LPAD",
+	"RPAD":     "RPAD",
+	"ABS":      "ABS",
+	"CEIL":     "CEIL",
+	"FLOOR":    "FLOOR",
+	"ROUND":    "ROUND",
+	"MOD":      "MOD",
+	"COALESCE": "COALESCE",
+	"NVL":      "COALESCE",
+}
+
+// postgres 表达式允许关键字
+var postgresExprKeywords = []string{"AND", "OR", "NOT", "IN", "IS", "NULL", "BETWEEN", "LIKE", "ESCAPE",
+	"CASE", "WHEN", "THEN", "ELSE", "END", "TRUE", "FALSE"}
+
+// genExpr oracle 检查约束、函数索引以及虚拟列表达式改写 postgres 表达式
+// 字段名按字段名映射规则转换并统一小写双引号引用，字符串常量保持不变
+// 仅支持 postgres 同语义函数以及基础关键字，其余标识符返回不兼容原因
+func (r *Rule) genExpr(expr string) (string, string) {
+	columns := make(map[string]struct{})
+	for _, col := range r.TableColumnINFO {
+		columns[common.StringUPPER(col["COLUMN_NAME"])] = struct{}{}
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(expr); {
+		c := expr[i]
+		switch {
+		case c == '\'':
+			// 字符串常量，'' 为转义单引号
+			j := i + 1
+			for j < len(expr) {
+				if expr[j] == '\'' {
+					if j+1 < len(expr) && expr[j+1] == '\'' {
+						j += 2
+						continue
+					}
+					break
+				}
+				j++
+			}
+			if j >= len(expr) {
+				return "", fmt.Sprintf("expression [%s] string literal isn't closed", expr)
+			}
+			sb.WriteString(expr[i : j+1])
+			i = j + 1
+		case c == '"':
+			j := strings.IndexByte(expr[i+1:], '"')
+			if j < 0 {
+				return "", fmt.Sprintf("expression [%s] quoted identifier isn't closed", expr)
+			}
+			ident := expr[i+1 : i+1+j]
+			if _, ok := columns[common.StringUPPER(ident)]; !ok {
+				return "", fmt.Sprintf("expression [%s] identifier [%s] isn't table column", expr, ident)
+			}
+			sb.WriteString(r.GenColumnName(ident))
+			i = i + j + 2
+		case isExprWordByte(c) && !(c >= '0' && c <= '9'):
+			j := i
+			for j < len(expr) && isExprWordByte(expr[j]) {
+				j++
+			}
+			word := common.StringUPPER(expr[i:j])
+			k := j
+			for k < len(expr) && expr[k] == ' ' {
+				k++
+			}
+			switch {
+			case k < len(expr) && expr[k] == '(':
+				fn, ok := postgresExprFunctions[word]
+				if !ok {
+					return "", fmt.Sprintf("expression [%s] function [%s] postgres isn't support", expr, word)
+				}
+				sb.WriteString(fn)
+			case common.IsContainString(postgresExprKeywords, word):
+				sb.WriteString(word)
+			default:
+				if _, ok := columns[word]; !ok {
+					return "", fmt.Sprintf("expression [%s] identifier [%s] postgres isn't support", expr, word)
+				}
+				sb.WriteString(r.GenColumnName(word))
+			}
+			i = j
+		case isExprWordByte(c):
+			// 数值常量
+			j := i
+			for j < len(expr) && (isExprWordByte(expr[j]) || expr[j] == '.') {
+				j++
+			}
+			sb.WriteString(expr[i:j])
+			i = j
+		default:
+			sb.WriteByte(c)
+			i++
+		}
+	}
+	return strings.TrimSpace(sb.String()), ""
+}
+
+func isExprWordByte(c byte) bool {
+	return c == '_' || c == '$' || c == '#' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
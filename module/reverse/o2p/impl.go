@@ -0,0 +1,40 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package o2p
+
+import (
+	"github.com/wentaojin/transferdb/module/reverse"
+	reverseO2M "github.com/wentaojin/transferdb/module/reverse/o2m"
+)
+
+func IReader(r reverse.Reader) (*Rule, error) {
+	i, err := r.GetTableInfo()
+	if err != nil {
+		return nil, err
+	}
+	return &Rule{
+		Table: r.(*Table),
+		Info:  i.(*reverseO2M.Info),
+	}, nil
+}
+
+func IReverse(s reverse.Generator) (*DDL, error) {
+	d, err := s.GenCreateTableDDL()
+	if err != nil {
+		return nil, err
+	}
+	return d.(*DDL), nil
+}
@@ -0,0 +1,217 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package o2p
+
+import (
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/database/meta"
+	reverseO2M "github.com/wentaojin/transferdb/module/reverse/o2m"
+	"strconv"
+	"strings"
+)
+
+// OracleTableColumnMapPostgresRule oracle 字段类型映射 postgres 字段类型
+// 原始字段类型格式与 O2M 保持一致，自定义 schema/table/column 数据类型规则同样适用
+func OracleTableColumnMapPostgresRule(sourceSchema, sourceTable string, column reverseO2M.Column, buildinDatatypes []meta.BuildinDatatypeRule) (string, string, error) {
+	var (
+		// oracle 表原始字段类型
+		originColumnType string
+		// 内置字段类型转换规则
+		buildInColumnType string
+	)
+
+	dataLength, err := strconv.Atoi(column.DataLength)
+	if err != nil {
+		return originColumnType, buildInColumnType, fmt.Errorf("oracle schema [%s] table [%s] reverser column data_length string to int failed: %v", sourceSchema, sourceTable, err)
+	}
+	dataPrecision, err := strconv.Atoi(column.DataPrecision)
+	if err != nil {
+		return originColumnType, buildInColumnType, fmt.Errorf("oracle schema [%s] table [%s] reverser column data_precision string to int failed: %v", sourceSchema, sourceTable, err)
+	}
+	dataScale, err := strconv.Atoi(column.DataScale)
+	if err != nil {
+		return originColumnType, buildInColumnType, fmt.Errorf("oracle schema [%s] table [%s] reverser column data_scale string to int failed: %v", sourceSchema, sourceTable, err)
+	}
+
+	// 内置数据类型转换
+	buildinDatatypeMap := make(map[string]string)
+	numberDatatypeMap := make(map[string]struct{})
+
+	for _, b := range buildinDatatypes {
+		buildinDatatypeMap[common.StringUPPER(b.DatatypeNameS)] = b.DatatypeNameT
+
+		if strings.EqualFold(common.StringUPPER(b.DatatypeNameS), common.BuildInOracleDatatypeNumber) {
+			for _, c := range strings.Split(b.DatatypeNameT, "/") {
+				numberDatatypeMap[common.StringUPPER(c)] = struct{}{}
+			}
+		}
+	}
+
+	dataType := common.StringUPPER(column.DataType)
+	ruleNotExist := func(datatype string) error {
+		return fmt.Errorf("oracle table column type [%s] map postgres column type rule isn't exist, please checkin", datatype)
+	}
+
+	switch dataType {
+	case common.BuildInOracleDatatypeNumber:
+		originColumnType = fmt.Sprintf("%s(%d,%d)", common.BuildInOracleDatatypeNumber, dataPrecision, dataScale)
+		if _, ok := buildinDatatypeMap[common.BuildInOracleDatatypeNumber]; !ok {
+			return originColumnType, buildInColumnType, ruleNotExist(common.BuildInOracleDatatypeNumber)
+		}
+		// number 以及 number(*) -> number(38,127)，精度以及标度不确定，numeric 不限定精度
+		var numberType string
+		switch {
+		case dataPrecision == 38 && dataScale == 127:
+			numberType, buildInColumnType = "NUMERIC", "NUMERIC"
+		case dataScale > 0:
+			numberType, buildInColumnType = "NUMERIC", fmt.Sprintf("NUMERIC(%d,%d)", dataPrecision, dataScale)
+		case dataPrecision < 5:
+			numberType, buildInColumnType = "SMALLINT", "SMALLINT"
+		case dataPrecision < 10:
+			numberType, buildInColumnType = "INTEGER", "INTEGER"
+		case dataPrecision < 19:
+			numberType, buildInColumnType = "BIGINT", "BIGINT"
+		default:
+			numberType, buildInColumnType = "NUMERIC", fmt.Sprintf("NUMERIC(%d)", dataPrecision)
+		}
+		if _, ok := numberDatatypeMap[numberType]; !ok {
+			return originColumnType, buildInColumnType, fmt.Errorf("oracle table column type [%s] map postgres column type rule isn't exist, please checkin mapping data type [%s]", originColumnType, numberType)
+		}
+		return originColumnType, buildInColumnType, nil
+
+	case common.BuildInOracleDatatypeChar, common.BuildInOracleDatatypeCharacter, common.BuildInOracleDatatypeNchar,
+		common.BuildInOracleDatatypeNcharVarying, common.BuildInOracleDatatypeNvarchar2,
+		common.BuildInOracleDatatypeVarchar2, common.BuildInOracleDatatypeVarchar:
+		val, ok := buildinDatatypeMap[dataType]
+		if !ok {
+			return originColumnType, buildInColumnType, ruleNotExist(dataType)
+		}
+		// postgres 字符类型长度单位为字符
+		if strings.EqualFold(column.CharUsed, "C") {
+			originColumnType = fmt.Sprintf("%s(%s)", dataType, column.CharLength)
+			buildInColumnType = fmt.Sprintf("%s(%s)", common.StringUPPER(val), column.CharLength)
+		} else {
+			originColumnType = fmt.Sprintf("%s(%d)", dataType, dataLength)
+			buildInColumnType = fmt.Sprintf("%s(%d)", common.StringUPPER(val), dataLength)
+		}
+		return originColumnType, buildInColumnType, nil
+
+	case common.BuildInOracleDatatypeDecimal, common.BuildInOracleDatatypeDec, common.BuildInOracleDatatypeNumeric:
+		if dataType == common.BuildInOracleDatatypeNumeric {
+			originColumnType = fmt.Sprintf("%s(%d,%d)", common.BuildInOracleDatatypeNumeric, dataPrecision, dataScale)
+		} else {
+			originColumnType = fmt.Sprintf("%s(%d,%d)", common.BuildInOracleDatatypeDecimal, dataPrecision, dataScale)
+		}
+		val, ok := buildinDatatypeMap[dataType]
+		if !ok {
+			return originColumnType, buildInColumnType, ruleNotExist(dataType)
+		}
+		buildInColumnType = fmt.Sprintf("%s(%d,%d)", common.StringUPPER(val), dataPrecision, dataScale)
+		return originColumnType, buildInColumnType, nil
+
+	case common.BuildInOracleDatatypeInteger, common.BuildInOracleDatatypeInt, common.BuildInOracleDatatypeSmallint:
+		// oracle integer/int/smallint 实际为 number(38)
+		originColumnType = dataType
+		if dataType == common.BuildInOracleDatatypeInt {
+			originColumnType = common.BuildInOracleDatatypeInteger
+		}
+		val, ok := buildinDatatypeMap[dataType]
+		if !ok {
+			return originColumnType, buildInColumnType, ruleNotExist(dataType)
+		}
+		buildInColumnType = common.StringUPPER(val)
+		if strings.EqualFold(buildInColumnType, "NUMERIC") {
+			buildInColumnType = "NUMERIC(38)"
+		}
+		return originColumnType, buildInColumnType, nil
+
+	case common.BuildInOracleDatatypeRaw:
+		originColumnType = fmt.Sprintf("%s(%d)", common.BuildInOracleDatatypeRaw, dataLength)
+		val, ok := buildinDatatypeMap[dataType]
+		if !ok {
+			return originColumnType, buildInColumnType, ruleNotExist(dataType)
+		}
+		// bytea 不限定长度
+		buildInColumnType = common.StringUPPER(val)
+		return originColumnType, buildInColumnType, nil
+
+	case common.BuildInOracleDatatypeBfile, common.BuildInOracleDatatypeRowid, common.BuildInOracleDatatypeUrowid:
+		originColumnType = dataType
+		val, ok := buildinDatatypeMap[dataType]
+		if !ok {
+			return originColumnType, buildInColumnType, ruleNotExist(dataType)
+		}
+		// bfile 仅保存文件定位符，rowid 扩展格式 18 位
+		switch dataType {
+		case common.BuildInOracleDatatypeBfile:
+			buildInColumnType = fmt.Sprintf("%s(255)", common.StringUPPER(val))
+		case common.BuildInOracleDatatypeRowid:
+			buildInColumnType = fmt.Sprintf("%s(18)", common.StringUPPER(val))
+		default:
+			buildInColumnType = fmt.Sprintf("%s(%d)", common.StringUPPER(val), dataLength)
+		}
+		return originColumnType, buildInColumnType, nil
+
+	case common.BuildInOracleDatatypeClob, common.BuildInOracleDatatypeBlob, common.BuildInOracleDatatypeDate,
+		common.BuildInOracleDatatypeDoublePrecision, common.BuildInOracleDatatypeFloat,
+		common.BuildInOracleDatatypeLong, common.BuildInOracleDatatypeLongRAW,
+		common.BuildInOracleDatatypeBinaryFloat, common.BuildInOracleDatatypeBinaryDouble,
+		common.BuildInOracleDatatypeNclob, common.BuildInOracleDatatypeReal, common.BuildInOracleDatatypeXmltype:
+		originColumnType = dataType
+		val, ok := buildinDatatypeMap[dataType]
+		if !ok {
+			return originColumnType, buildInColumnType, ruleNotExist(dataType)
+		}
+		buildInColumnType = common.StringUPPER(val)
+		return originColumnType, buildInColumnType, nil
+
+	default:
+		originColumnType = column.DataType
+		switch {
+		case strings.Contains(dataType, "INTERVAL YEAR"):
+			val, ok := buildinDatatypeMap[dataType]
+			if !ok {
+				return originColumnType, buildInColumnType, ruleNotExist(dataType)
+			}
+			buildInColumnType = common.StringUPPER(val)
+		case strings.Contains(dataType, "INTERVAL DAY"):
+			val, ok := buildinDatatypeMap[common.BuildInOracleDatatypeIntervalDay]
+			if !ok {
+				return originColumnType, buildInColumnType, ruleNotExist(dataType)
+			}
+			// postgres 秒精度最大 6
+			buildInColumnType = fmt.Sprintf("%s(%d)", common.StringUPPER(val), minPrecision(dataScale))
+		case strings.Contains(dataType, "TIMESTAMP"):
+			val, ok := buildinDatatypeMap[dataType]
+			if !ok {
+				return originColumnType, buildInColumnType, ruleNotExist(dataType)
+			}
+			buildInColumnType = fmt.Sprintf("%s(%d)", common.StringUPPER(val), minPrecision(dataScale))
+		default:
+			buildInColumnType = "TEXT"
+		}
+		return originColumnType, buildInColumnType, nil
+	}
+}
+
+// minPrecision postgres 时间类型精度最大 6
+func minPrecision(scale int) int {
+	if scale > 6 {
+		return 6
+	}
+	return scale
+}
@@ -0,0 +1,114 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package o2p
+
+import (
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/database/meta"
+	reverseO2M "github.com/wentaojin/transferdb/module/reverse/o2m"
+	"testing"
+)
+
+func buildinO2PDatatypes() []meta.BuildinDatatypeRule {
+	var rules []meta.BuildinDatatypeRule
+	for datatypeS, datatypeT := range common.BuildInOracleO2PDatatypeNameMap {
+		rules = append(rules, meta.BuildinDatatypeRule{
+			DBTypeS:       common.DatabaseTypeOracle,
+			DBTypeT:       common.DatabaseTypePostgres,
+			DatatypeNameS: datatypeS,
+			DatatypeNameT: datatypeT,
+		})
+	}
+	return rules
+}
+
+func genColumn(dataType, dataLength, dataPrecision, dataScale, charUsed, charLength string) reverseO2M.Column {
+	return reverseO2M.Column{
+		DataType:   dataType,
+		CharUsed:   charUsed,
+		CharLength: charLength,
+		ColumnInfo: reverseO2M.ColumnInfo{
+			DataLength:    dataLength,
+			DataPrecision: dataPrecision,
+			DataScale:     dataScale,
+		},
+	}
+}
+
+func TestOracleTableColumnMapPostgresRule(t *testing.T) {
+	cases := []struct {
+		name       string
+		column     reverseO2M.Column
+		wantOrigin string
+		wantBuild  string
+	}{
+		{name: "number", column: genColumn("NUMBER", "22", "38", "127", "", "0"), wantOrigin: "NUMBER(38,127)", wantBuild: "NUMERIC"},
+		{name: "number scale", column: genColumn("NUMBER", "22", "10", "2", "", "0"), wantOrigin: "NUMBER(10,2)", wantBuild: "NUMERIC(10,2)"},
+		{name: "number smallint", column: genColumn("NUMBER", "22", "4", "0", "", "0"), wantOrigin: "NUMBER(4,0)", wantBuild: "SMALLINT"},
+		{name: "number integer", column: genColumn("NUMBER", "22", "9", "0", "", "0"), wantOrigin: "NUMBER(9,0)", wantBuild: "INTEGER"},
+		{name: "number bigint", column: genColumn("NUMBER", "22", "18", "0", "", "0"), wantOrigin: "NUMBER(18,0)", wantBuild: "BIGINT"},
+		{name: "number numeric", column: genColumn("NUMBER", "22", "20", "0", "", "0"), wantOrigin: "NUMBER(20,0)", wantBuild: "NUMERIC(20)"},
+		{name: "varchar2 byte", column: genColumn("VARCHAR2", "40", "0", "0", "B", "40"), wantOrigin: "VARCHAR2(40)", wantBuild: "VARCHAR(40)"},
+		{name: "varchar2 char", column: genColumn("VARCHAR2", "160", "0", "0", "C", "40"), wantOrigin: "VARCHAR2(40)", wantBuild: "VARCHAR(40)"},
+		{name: "char", column: genColumn("CHAR", "10", "0", "0", "B", "10"), wantOrigin: "CHAR(10)", wantBuild: "CHAR(10)"},
+		{name: "decimal", column: genColumn("DECIMAL", "22", "12", "4", "", "0"), wantOrigin: "DECIMAL(12,4)", wantBuild: "NUMERIC(12,4)"},
+		{name: "integer", column: genColumn("INTEGER", "22", "0", "0", "", "0"), wantOrigin: "INTEGER", wantBuild: "NUMERIC(38)"},
+		{name: "raw", column: genColumn("RAW", "16", "0", "0", "", "0"), wantOrigin: "RAW(16)", wantBuild: "BYTEA"},
+		{name: "rowid", column: genColumn("ROWID", "10", "0", "0", "", "0"), wantOrigin: "ROWID", wantBuild: "VARCHAR(18)"},
+		{name: "bfile", column: genColumn("BFILE", "530", "0", "0", "", "0"), wantOrigin: "BFILE", wantBuild: "VARCHAR(255)"},
+		{name: "clob", column: genColumn("CLOB", "4000", "0", "0", "", "0"), wantOrigin: "CLOB", wantBuild: "TEXT"},
+		{name: "date", column: genColumn("DATE", "7", "0", "0", "", "0"), wantOrigin: "DATE", wantBuild: "TIMESTAMP(0)"},
+		{name: "binary float", column: genColumn("BINARY_FLOAT", "4", "0", "0", "", "0"), wantOrigin: "BINARY_FLOAT", wantBuild: "REAL"},
+		{name: "timestamp", column: genColumn("TIMESTAMP(6)", "11", "0", "6", "", "0"), wantOrigin: "TIMESTAMP(6)", wantBuild: "TIMESTAMP(6)"},
+		{name: "timestamp max precision", column: genColumn("TIMESTAMP(9)", "11", "0", "9", "", "0"), wantOrigin: "TIMESTAMP(9)", wantBuild: "TIMESTAMP(6)"},
+		{name: "timestamp with time zone", column: genColumn("TIMESTAMP(3) WITH TIME ZONE", "13", "0", "3", "", "0"), wantOrigin: "TIMESTAMP(3) WITH TIME ZONE", wantBuild: "TIMESTAMPTZ(3)"},
+		{name: "interval day", column: genColumn("INTERVAL DAY(2) TO SECOND(6)", "11", "2", "6", "", "0"), wantOrigin: "INTERVAL DAY(2) TO SECOND(6)", wantBuild: "INTERVAL DAY TO SECOND(6)"},
+		{name: "interval year", column: genColumn("INTERVAL YEAR(2) TO MONTH", "5", "2", "0", "", "0"), wantOrigin: "INTERVAL YEAR(2) TO MONTH", wantBuild: "INTERVAL YEAR TO MONTH"},
+		{name: "unknown", column: genColumn("SDO_GEOMETRY", "1", "0", "0", "", "0"), wantOrigin: "SDO_GEOMETRY", wantBuild: "TEXT"},
+	}
+	buildinDatatypes := buildinO2PDatatypes()
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			origin, buildIn, err := OracleTableColumnMapPostgresRule("MARVIN", "T", c.column, buildinDatatypes)
+			if err != nil {
+				t.Fatalf("map column [%s] failed: %v", c.column.DataType, err)
+			}
+			if origin != c.wantOrigin || buildIn != c.wantBuild {
+				t.Fatalf("map column [%s] got (%s, %s), want (%s, %s)", c.column.DataType, origin, buildIn, c.wantOrigin, c.wantBuild)
+			}
+		})
+	}
+}
+
+func TestOracleTableColumnMapPostgresRuleError(t *testing.T) {
+	cases := []struct {
+		name             string
+		column           reverseO2M.Column
+		buildinDatatypes []meta.BuildinDatatypeRule
+	}{
+		{name: "invalid data length", column: genColumn("NUMBER", "", "10", "0", "", "0"), buildinDatatypes: buildinO2PDatatypes()},
+		{name: "rule not exist", column: genColumn("CLOB", "4000", "0", "0", "", "0"), buildinDatatypes: nil},
+		{name: "number mapping type not exist", column: genColumn("NUMBER", "22", "4", "0", "", "0"),
+			buildinDatatypes: []meta.BuildinDatatypeRule{{DatatypeNameS: common.BuildInOracleDatatypeNumber, DatatypeNameT: "BIGINT/NUMERIC"}}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if _, _, err := OracleTableColumnMapPostgresRule("MARVIN", "T", c.column, c.buildinDatatypes); err == nil {
+				t.Fatalf("map column [%s] expect error, got nil", c.column.DataType)
+			}
+		})
+	}
+}
@@ -0,0 +1,285 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package o2p
+
+import (
+	"context"
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/config"
+	"github.com/wentaojin/transferdb/database/meta"
+	"github.com/wentaojin/transferdb/database/oracle"
+	"github.com/wentaojin/transferdb/database/postgres"
+	"github.com/wentaojin/transferdb/module/reverse"
+	reverseO2M "github.com/wentaojin/transferdb/module/reverse/o2m"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+type Reverse struct {
+	Ctx      context.Context
+	Cfg      *config.Config
+	Oracle   *oracle.Oracle
+	Postgres *postgres.Postgres
+	MetaDB   *meta.Meta
+}
+
+func NewReverse(ctx context.Context, cfg *config.Config) (*Reverse, error) {
+	oracleDB, err := oracle.NewOracleDBEngine(ctx, cfg.OracleConfig)
+	if err != nil {
+		return nil, err
+	}
+	pgDB, err := postgres.NewPostgresDBEngine(ctx, cfg.PostgresConfig)
+	if err != nil {
+		return nil, err
+	}
+	metaDB, err := meta.NewMetaDBEngine(ctx, cfg.MetaConfig, cfg.AppConfig.SlowlogThreshold)
+	if err != nil {
+		return nil, err
+	}
+	return &Reverse{
+		Ctx:      ctx,
+		Cfg:      cfg,
+		Oracle:   oracleDB,
+		Postgres: pgDB,
+		MetaDB:   metaDB,
+	}, nil
+}
+
+func (r *Reverse) Reverse() error {
+	startTime := time.Now()
+	zap.L().Info("reverse table oracle to postgres start",
+		zap.String("schema", r.Cfg.OracleConfig.SchemaName))
+
+	// 获取配置文件待同步表列表
+	exporters, err := reverseO2M.FilterCFGTable(r.Cfg, r.Oracle)
+	if err != nil {
+		return err
+	}
+
+	if len(exporters) == 0 {
+		zap.L().Warn("there are no table objects in the oracle schema",
+			zap.String("schema", r.Cfg.OracleConfig.SchemaName))
+		return nil
+	}
+
+	// 判断 error_log_detail 是否存在错误记录，是否可进行 reverse
+	errTotals, err := meta.NewErrorLogDetailModel(r.MetaDB).CountsErrorLogBySchema(r.Ctx, &meta.ErrorLogDetail{
+		DBTypeS:     r.Cfg.DBTypeS,
+		DBTypeT:     r.Cfg.DBTypeT,
+		SchemaNameS: common.StringUPPER(r.Cfg.OracleConfig.SchemaName),
+		TaskMode:    r.Cfg.TaskMode,
+	})
+	if errTotals > 0 || err != nil {
+		return fmt.Errorf("reverse schema [%s] table mode [%s] task failed: %v, table [error_log_detail] exist failed error, please clear and rerunning", r.Cfg.OracleConfig.SchemaName, r.Cfg.TaskMode, err)
+	}
+
+	oracleDBVersion, err := r.Oracle.GetOracleDBVersion()
+	if err != nil {
+		return err
+	}
+	pgDBVersion, err := r.Postgres.GetPostgresDBVersion()
+	if err != nil {
+		return err
+	}
+
+	// 筛选过滤可能不支持的表类型，分区表按普通表创建
+	partitionTables, temporaryTables, clusteredTables, materializedView, exporterTables, err := reverseO2M.FilterOracleCompatibleTable(r.Cfg, r.Oracle, exporters)
+	if err != nil {
+		return err
+	}
+
+	// 获取规则
+	ruleTime := time.Now()
+	tableNameRuleMap, tableColumnRuleMap, tableDefaultRuleMap, tableColumnNameRuleMap, err := reverseO2M.IChanger(&reverseO2M.Change{
+		Ctx:              r.Ctx,
+		DBTypeS:          r.Cfg.DBTypeS,
+		DBTypeT:          r.Cfg.DBTypeT,
+		SourceSchemaName: common.StringUPPER(r.Cfg.OracleConfig.SchemaName),
+		TargetSchemaName: r.Cfg.PostgresConfig.SchemaName,
+		SourceTables:     exporterTables,
+		OracleCollation:  false,
+		Threads:          r.Cfg.ReverseConfig.ReverseThreads,
+		Oracle:           r.Oracle,
+		MetaDB:           r.MetaDB,
+		ColumnMapRule:    OracleTableColumnMapPostgresRule,
+	})
+	if err != nil {
+		return err
+	}
+	zap.L().Warn("get all rules",
+		zap.String("schema", r.Cfg.OracleConfig.SchemaName),
+		zap.String("cost", time.Now().Sub(ruleTime).String()))
+
+	// 获取 reverse 表任务列表
+	tables, err := GenReverseTableTask(r, tableNameRuleMap, tableColumnRuleMap, tableDefaultRuleMap, tableColumnNameRuleMap, oracleDBVersion, pgDBVersion, exporterTables)
+	if err != nil {
+		return err
+	}
+
+	// file writer
+	f, err := reverse.NewWriter(r.Cfg, nil, r.Oracle, r.Postgres)
+	if err != nil {
+		return err
+	}
+
+	// schema create
+	err = GenCreateSchema(f,
+		common.StringUPPER(r.Cfg.OracleConfig.SchemaName), r.Cfg.PostgresConfig.SchemaName, r.Cfg.ReverseConfig.IdentifierPolicy, r.Cfg.ReverseConfig.DirectWrite)
+	if err != nil {
+		return err
+	}
+
+	// 序列转换
+	err = GenCreateSequence(f, r.Oracle,
+		common.StringUPPER(r.Cfg.OracleConfig.SchemaName), r.Cfg.PostgresConfig.SchemaName, r.Cfg.ReverseConfig.IdentifierPolicy, r.Cfg.ReverseConfig.DirectWrite)
+	if err != nil {
+		return err
+	}
+
+	// 表类型不兼容项输出
+	err = GenCompatibilityTable(f, common.StringUPPER(r.Cfg.OracleConfig.SchemaName), partitionTables, temporaryTables, clusteredTables, materializedView)
+	if err != nil {
+		return err
+	}
+
+	// 表转换，外键依赖父表，所有表创建之后统一输出
+	foreignKeys, err := r.reverseTable(f, tables)
+	if err != nil {
+		return err
+	}
+	err = GenForeignKey(f, common.StringUPPER(r.Cfg.OracleConfig.SchemaName), foreignKeys, r.Cfg.ReverseConfig.ForeignKeyPostLoad, r.Cfg.ReverseConfig.DirectWrite)
+	if err != nil {
+		return err
+	}
+
+	// 视图输出不兼容项
+	err = GenCreateView(f, r.Oracle, common.StringUPPER(r.Cfg.OracleConfig.SchemaName), r.Cfg.PostgresConfig.SchemaName)
+	if err != nil {
+		return err
+	}
+
+	err = f.Close()
+	if err != nil {
+		return err
+	}
+
+	errTotals, err = meta.NewErrorLogDetailModel(r.MetaDB).CountsErrorLogBySchema(r.Ctx, &meta.ErrorLogDetail{
+		DBTypeS:     r.Cfg.DBTypeS,
+		DBTypeT:     r.Cfg.DBTypeT,
+		SchemaNameS: common.StringUPPER(r.Cfg.OracleConfig.SchemaName),
+		TaskMode:    r.Cfg.TaskMode,
+	})
+	if err != nil {
+		return err
+	}
+
+	endTime := time.Now()
+	if !r.Cfg.ReverseConfig.DirectWrite {
+		zap.L().Info("reverse", zap.String("create table and index output", filepath.Join(r.Cfg.ReverseConfig.DDLReverseDir,
+			fmt.Sprintf("reverse_%s.sql", r.Cfg.OracleConfig.SchemaName))))
+	}
+	zap.L().Info("compatibility", zap.String("maybe exist compatibility output", filepath.Join(r.Cfg.ReverseConfig.DDLCompatibleDir,
+		fmt.Sprintf("compatibility_%s.sql", r.Cfg.OracleConfig.SchemaName))))
+	if f.FFile != nil {
+		zap.L().Info("foreign key", zap.String("post-load foreign key output", filepath.Join(r.Cfg.ReverseConfig.DDLReverseDir,
+			fmt.Sprintf("foreign_key_%s.sql", r.Cfg.OracleConfig.SchemaName))))
+	}
+	if errTotals == 0 {
+		zap.L().Info("reverse table oracle to postgres finished",
+			zap.Int("table totals", len(exporters)),
+			zap.Int("reverse totals", len(tables)),
+			zap.Int("reverse success", len(tables)),
+			zap.Int64("reverse failed", errTotals),
+			zap.String("cost", endTime.Sub(startTime).String()))
+	} else {
+		zap.L().Warn("reverse table oracle to postgres finished",
+			zap.Int("table totals", len(exporters)),
+			zap.Int("reverse totals", len(tables)),
+			zap.Int("reverse success", len(tables)-int(errTotals)),
+			zap.Int64("reverse failed", errTotals),
+			zap.String("failed tips", "failed detail, please see table [error_log_detail]"),
+			zap.String("cost", endTime.Sub(startTime).String()))
+	}
+	return nil
+}
+
+// reverseTable 表并发转换，返回转换成功表的外键语句
+func (r *Reverse) reverseTable(f *reverse.Write, tables []*Table) ([]string, error) {
+	var (
+		foreignKeys []string
+		mu          sync.Mutex
+	)
+
+	g := &errgroup.Group{}
+	g.SetLimit(r.Cfg.ReverseConfig.ReverseThreads)
+
+	for _, table := range tables {
+		t := table
+		g.Go(func() error {
+			rule, err := IReader(t)
+			if err != nil {
+				return r.recordError(t, "reader", err)
+			}
+			ddl, err := IReverse(rule)
+			if err != nil {
+				return r.recordError(t, "reverse", err)
+			}
+			err = reverseO2M.IWriter(f, ddl)
+			if err != nil {
+				return r.recordError(t, "writer", err)
+			}
+
+			mu.Lock()
+			foreignKeys = append(foreignKeys, ddl.TableForeignKeys...)
+			mu.Unlock()
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	return foreignKeys, nil
+}
+
+// recordError 单表转换失败记录 error_log_detail，不中断其余表转换，记录失败返回错误
+func (r *Reverse) recordError(t *Table, stage string, taskErr error) error {
+	if err := meta.NewErrorLogDetailModel(r.MetaDB).CreateErrorLog(r.Ctx, &meta.ErrorLogDetail{
+		DBTypeS:     r.Cfg.DBTypeS,
+		DBTypeT:     r.Cfg.DBTypeT,
+		SchemaNameS: t.SourceSchemaName,
+		TableNameS:  t.SourceTableName,
+		SchemaNameT: t.TargetSchemaName,
+		TableNameT:  t.TargetTableName,
+		TaskMode:    r.Cfg.TaskMode,
+		TaskStatus:  "Failed",
+		InfoDetail:  t.String(),
+		ErrorDetail: taskErr.Error(),
+	}); err != nil {
+		zap.L().Error("reverse table oracle to postgres failed",
+			zap.String("schema", t.SourceSchemaName),
+			zap.String("table", t.SourceTableName),
+			zap.Error(
+				fmt.Errorf("%s table task failed, detail see [error_log_detail], please rerunning", stage)))
+
+		return fmt.Errorf("%s table task failed, detail see [error_log_detail], please rerunning, error: %v", stage, taskErr)
+	}
+	return nil
+}
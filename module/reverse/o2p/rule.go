@@ -0,0 +1,578 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package o2p
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	reverseO2M "github.com/wentaojin/transferdb/module/reverse/o2m"
+	"go.uber.org/zap"
+	"regexp"
+	"strings"
+)
+
+// Rule 表元信息同 O2M，仅 postgres DDL 生成规则不同
+type Rule struct {
+	*Table
+	*reverseO2M.Info
+}
+
+// 单字段非空检查约束，postgres 以字段 NOT NULL 表示
+var notNullCheckRegexp = regexp.MustCompile(`^"?[\w$#]+"?\s+(?i:IS\s+NOT\s+NULL)$`)
+
+// postgres identity column 支持数据类型
+var identityDatatype = []string{"SMALLINT", "INTEGER", "BIGINT"}
+
+func (r *Rule) GenCreateTableDDL() (interface{}, error) {
+	targetSchema, targetTable := r.GenTablePrefix()
+
+	tableColumns, err := r.GenTableColumn()
+	if err != nil {
+		return nil, err
+	}
+
+	tableKeys, compatibleDDL, err := r.GenTableKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	uniqueIndexes, uniqueIndexCompSQL, err := r.GenTableUniqueIndex()
+	if err != nil {
+		return nil, fmt.Errorf("table json [%v], oracle db reverse table unique index failed: %v", r.String(), err)
+	}
+	normalIndexes, normalIndexCompSQL, err := r.GenTableNormalIndex()
+	if err != nil {
+		return nil, fmt.Errorf("table json [%v], oracle db reverse table normal index failed: %v", r.String(), err)
+	}
+	compatibleDDL = append(compatibleDDL, uniqueIndexCompSQL...)
+	compatibleDDL = append(compatibleDDL, normalIndexCompSQL...)
+	compatibleDDL = append(compatibleDDL, r.GenTableColumnCompatible()...)
+
+	checkKeys, err := r.GenTableCheckKey()
+	if err != nil {
+		return nil, err
+	}
+	compatibleDDL = append(compatibleDDL, r.GenTableCheckKeyCompatible()...)
+
+	foreignKeys, err := r.GenTableForeignKey()
+	if err != nil {
+		return nil, err
+	}
+
+	tableComment, err := r.GenTableComment()
+	if err != nil {
+		return nil, err
+	}
+	columnComments, err := r.GenTableColumnComment()
+	if err != nil {
+		return nil, err
+	}
+
+	return &DDL{
+		SourceSchemaName:   r.SourceSchemaName,
+		SourceTableName:    r.SourceTableName,
+		SourceTableType:    r.SourceTableType,
+		TargetSchemaName:   r.GenSchemaName(), // change schema name
+		TargetTableName:    r.GenTableName(),  // change table name
+		TargetDBVersion:    r.TargetDBVersion,
+		TablePrefix:        fmt.Sprintf("CREATE TABLE %s.%s", targetSchema, targetTable),
+		TableColumns:       tableColumns,
+		TableKeys:          tableKeys,
+		TableIndexes:       append(uniqueIndexes, normalIndexes...),
+		TableComment:       tableComment,
+		ColumnComments:     columnComments,
+		TableCheckKeys:     checkKeys,
+		TableForeignKeys:   foreignKeys,
+		TableCompatibleDDL: compatibleDDL,
+	}, nil
+}
+
+// GenTableKeys postgres 表内仅定义主键以及唯一约束，索引单独 CREATE INDEX
+func (r *Rule) GenTableKeys() (tableKeys []string, compatibilityIndexSQL []string, err error) {
+	primaryKeys, err := r.GenTablePrimaryKey()
+	if err != nil {
+		return tableKeys, compatibilityIndexSQL, err
+	}
+	uniqueKeys, err := r.GenTableUniqueKey()
+	if err != nil {
+		return tableKeys, compatibilityIndexSQL, fmt.Errorf("table json [%v], oracle db reverse table unique constraint failed: %v", r.String(), err)
+	}
+	tableKeys = append(tableKeys, primaryKeys...)
+	tableKeys = append(tableKeys, uniqueKeys...)
+	return tableKeys, compatibilityIndexSQL, nil
+}
+
+// GenTablePrefix 返回引用后的目标端 schema 以及表名
+func (r *Rule) GenTablePrefix() (string, string) {
+	return genIdentifier(r.GenSchemaName(), r.IdentifierPolicy), genIdentifier(r.GenTableName(), r.IdentifierPolicy)
+}
+
+// GenTableSuffix postgres 不涉及表选项
+func (r *Rule) GenTableSuffix() (string, error) {
+	return "", nil
+}
+
+func (r *Rule) GenTablePrimaryKey() (primaryKeys []string, err error) {
+	if len(r.PrimaryKeyINFO) > 1 {
+		return primaryKeys, fmt.Errorf("oracle schema [%s] table [%s] primary key exist multiple values: [%v]", r.SourceSchemaName, r.SourceTableName, r.PrimaryKeyINFO)
+	}
+	if len(r.PrimaryKeyINFO) > 0 {
+		primaryKeys = append(primaryKeys, fmt.Sprintf("CONSTRAINT %s PRIMARY KEY (%s)",
+			r.GenIdentifierName(r.PrimaryKeyINFO[0]["CONSTRAINT_NAME"]), r.genColumnList(r.PrimaryKeyINFO[0]["COLUMN_LIST"])))
+	}
+	return primaryKeys, nil
+}
+
+func (r *Rule) GenTableUniqueKey() (uniqueKeys []string, err error) {
+	for _, rowUKCol := range r.UniqueKeyINFO {
+		uniqueKeys = append(uniqueKeys, fmt.Sprintf("CONSTRAINT %s UNIQUE (%s)",
+			r.GenIdentifierName(rowUKCol["CONSTRAINT_NAME"]), r.genColumnList(rowUKCol["COLUMN_LIST"])))
+	}
+	return uniqueKeys, nil
+}
+
+// GenTableForeignKey 外键统一 ALTER TABLE 输出，由 reverse 在所有表创建之后写入
+func (r *Rule) GenTableForeignKey() (foreignKeys []string, err error) {
+	targetSchema, targetTable := r.GenTablePrefix()
+	for _, rowFKCol := range r.ForeignKeyINFO {
+		refSchema, refTable, refColumns := rowFKCol["R_OWNER"], rowFKCol["RTABLE_NAME"], rowFKCol["RCOLUMN_LIST"]
+		// 同 schema 父表引用目标端 schema
+		if strings.EqualFold(refSchema, r.SourceSchemaName) {
+			refSchema = r.GenSchemaName()
+			refColumns = common.StringColumnListRule(refColumns, r.SchemaColumnNameRule[common.StringUPPER(refTable)])
+			if val, ok := r.SchemaTableNameRule[common.StringUPPER(refTable)]; ok {
+				refTable = val
+			}
+		}
+		var refColumnList []string
+		for _, col := range strings.Split(refColumns, ",") {
+			refColumnList = append(refColumnList, genIdentifier(strings.TrimSpace(col), r.IdentifierPolicy))
+		}
+
+		fk := fmt.Sprintf("ALTER TABLE %s.%s ADD CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s.%s (%s)",
+			targetSchema, targetTable,
+			r.GenIdentifierName(rowFKCol["CONSTRAINT_NAME"]),
+			r.genColumnList(rowFKCol["COLUMN_LIST"]),
+			genIdentifier(refSchema, r.IdentifierPolicy),
+			genIdentifier(refTable, r.IdentifierPolicy),
+			strings.Join(refColumnList, ","))
+		switch rowFKCol["DELETE_RULE"] {
+		case "CASCADE":
+			fk = fmt.Sprintf("%s ON DELETE CASCADE", fk)
+		case "SET NULL":
+			fk = fmt.Sprintf("%s ON DELETE SET NULL", fk)
+		}
+		foreignKeys = append(foreignKeys, fk+";")
+	}
+	return foreignKeys, nil
+}
+
+// GenTableCheckKey 排除单字段非空检查约束，表达式字段名按映射规则改写，无法改写的检查约束跳过
+func (r *Rule) GenTableCheckKey() (checkKeys []string, err error) {
+	targetSchema, targetTable := r.GenTablePrefix()
+	for _, rowCKCol := range r.CheckKeyINFO {
+		s := strings.TrimSpace(rowCKCol["SEARCH_CONDITION"])
+		if notNullCheckRegexp.MatchString(s) {
+			continue
+		}
+		if expr, reason := r.genExpr(s); reason == "" {
+			checkKeys = append(checkKeys, fmt.Sprintf("ALTER TABLE %s.%s ADD CONSTRAINT %s CHECK (%s);",
+				targetSchema, targetTable, r.GenIdentifierName(rowCKCol["CONSTRAINT_NAME"]), expr))
+		}
+	}
+	return checkKeys, nil
+}
+
+// GenTableCheckKeyCompatible 表达式无法改写的检查约束不兼容项
+func (r *Rule) GenTableCheckKeyCompatible() []string {
+	var compatibilityDDL []string
+	targetSchema, targetTable := r.GenTablePrefix()
+	for _, rowCKCol := range r.CheckKeyINFO {
+		s := strings.TrimSpace(rowCKCol["SEARCH_CONDITION"])
+		if notNullCheckRegexp.MatchString(s) {
+			continue
+		}
+		if _, reason := r.genExpr(s); reason != "" {
+			ckSQL := fmt.Sprintf("ALTER TABLE %s.%s ADD CONSTRAINT %s CHECK (%s); -- %s",
+				targetSchema, targetTable, r.GenIdentifierName(rowCKCol["CONSTRAINT_NAME"]), s, reason)
+			compatibilityDDL = append(compatibilityDDL, ckSQL)
+			zap.L().Warn("reverse check key",
+				zap.String("schema", r.SourceSchemaName),
+				zap.String("table", r.SourceTableName),
+				zap.String("check key sql", ckSQL),
+				zap.String("warn", "postgres not support"))
+		}
+	}
+	return compatibilityDDL
+}
+
+func (r *Rule) GenTableUniqueIndex() (uniqueIndexes []string, compatibilityIndexSQL []string, err error) {
+	for _, idxMeta := range r.UniqueIndexINFO {
+		if idxMeta["TABLE_NAME"] == "" || strings.ToUpper(idxMeta["UNIQUENESS"]) != "UNIQUE" {
+			return uniqueIndexes, compatibilityIndexSQL,
+				fmt.Errorf("[NON-UNIQUE] oracle schema [%s] table [%s] panic, error: %v", r.SourceSchemaName, r.SourceTableName, idxMeta)
+		}
+		idxSQL, compSQL, err := r.genIndex("UNIQUE INDEX", idxMeta)
+		if err != nil {
+			return uniqueIndexes, compatibilityIndexSQL, err
+		}
+		if idxSQL != "" {
+			uniqueIndexes = append(uniqueIndexes, idxSQL)
+		}
+		if compSQL != "" {
+			compatibilityIndexSQL = append(compatibilityIndexSQL, compSQL)
+		}
+	}
+	return uniqueIndexes, compatibilityIndexSQL, nil
+}
+
+func (r *Rule) GenTableNormalIndex() (normalIndexes []string, compatibilityIndexSQL []string, err error) {
+	// 普通索引【普通索引、函数索引、位图索引、DOMAIN 索引】
+	for _, idxMeta := range r.NormalIndexINFO {
+		if idxMeta["TABLE_NAME"] == "" || strings.ToUpper(idxMeta["UNIQUENESS"]) != "NONUNIQUE" {
+			return normalIndexes, compatibilityIndexSQL,
+				fmt.Errorf("[NON-NORMAL] oracle schema [%s] table [%s] reverse normal index panic, error: %v", r.SourceSchemaName, r.SourceTableName, idxMeta)
+		}
+		idxSQL, compSQL, err := r.genIndex("INDEX", idxMeta)
+		if err != nil {
+			return normalIndexes, compatibilityIndexSQL, err
+		}
+		if idxSQL != "" {
+			normalIndexes = append(normalIndexes, idxSQL)
+		}
+		if compSQL != "" {
+			compatibilityIndexSQL = append(compatibilityIndexSQL, compSQL)
+		}
+	}
+	return normalIndexes, compatibilityIndexSQL, nil
+}
+
+// genIndex 普通索引直接转换，函数索引转换表达式索引，位图索引以及 DOMAIN 索引 postgres 不支持输出不兼容项
+func (r *Rule) genIndex(indexKeyword string, idxMeta map[string]string) (string, string, error) {
+	targetSchema, targetTable := r.GenTablePrefix()
+	indexName := r.GenIdentifierName(idxMeta["INDEX_NAME"])
+
+	switch idxMeta["INDEX_TYPE"] {
+	case "NORMAL":
+		idxSQL := fmt.Sprintf("CREATE %s %s ON %s.%s (%s);", indexKeyword, indexName, targetSchema, targetTable, r.genColumnList(idxMeta["COLUMN_LIST"]))
+		zap.L().Info("reverse index",
+			zap.String("schema", r.SourceSchemaName),
+			zap.String("table", idxMeta["TABLE_NAME"]),
+			zap.String("index name", idxMeta["INDEX_NAME"]),
+			zap.String("index type", idxMeta["INDEX_TYPE"]),
+			zap.String("index column list", idxMeta["COLUMN_LIST"]),
+			zap.String("index info", idxSQL))
+		return idxSQL, "", nil
+
+	case "FUNCTION-BASED NORMAL":
+		var (
+			keyParts []string
+			reason   string
+		)
+		descends := strings.Split(idxMeta["DESCEND_LIST"], ",")
+		for i, part := range reverseO2M.SplitIndexColumnList(idxMeta["COLUMN_LIST"]) {
+			expr, why := r.genExpr(part)
+			if why != "" {
+				reason = why
+				break
+			}
+			// 单字段 (降序索引等) 无需括号，其余表达式需括号
+			if !strings.HasPrefix(expr, `"`) || strings.Count(expr, `"`) != 2 || !strings.HasSuffix(expr, `"`) {
+				expr = fmt.Sprintf("(%s)", expr)
+			}
+			if i < len(descends) && strings.EqualFold(strings.TrimSpace(descends[i]), "DESC") {
+				expr = fmt.Sprintf("%s DESC", expr)
+			}
+			keyParts = append(keyParts, expr)
+		}
+		if reason == "" {
+			idxSQL := fmt.Sprintf("CREATE %s %s ON %s.%s (%s);", indexKeyword, indexName, targetSchema, targetTable, strings.Join(keyParts, ","))
+			zap.L().Info("reverse index",
+				zap.String("schema", r.SourceSchemaName),
+				zap.String("table", idxMeta["TABLE_NAME"]),
+				zap.String("index name", idxMeta["INDEX_NAME"]),
+				zap.String("index type", idxMeta["INDEX_TYPE"]),
+				zap.String("index column list", idxMeta["COLUMN_LIST"]),
+				zap.String("index info", idxSQL))
+			return idxSQL, "", nil
+		}
+		compSQL := fmt.Sprintf("CREATE %s %s ON %s.%s (%s); -- %s", indexKeyword, indexName, targetSchema, targetTable, idxMeta["COLUMN_LIST"], reason)
+		zap.L().Warn("reverse index",
+			zap.String("schema", r.SourceSchemaName),
+			zap.String("table", idxMeta["TABLE_NAME"]),
+			zap.String("index name", idxMeta["INDEX_NAME"]),
+			zap.String("index type", idxMeta["INDEX_TYPE"]),
+			zap.String("index column list", idxMeta["COLUMN_LIST"]),
+			zap.String("create index sql", compSQL),
+			zap.String("reason", reason),
+			zap.String("warn", "postgres not support"))
+		return "", compSQL, nil
+
+	case "BITMAP", "FUNCTION-BASED BITMAP":
+		compSQL := fmt.Sprintf("CREATE BITMAP INDEX %s ON %s.%s (%s);", indexName, targetSchema, targetTable,
+			common.StringColumnListRule(idxMeta["COLUMN_LIST"], r.TableColumnNameRule))
+		zap.L().Warn("reverse index",
+			zap.String("schema", r.SourceSchemaName),
+			zap.String("table", idxMeta["TABLE_NAME"]),
+			zap.String("index name", idxMeta["INDEX_NAME"]),
+			zap.String("index type", idxMeta["INDEX_TYPE"]),
+			zap.String("index column list", idxMeta["COLUMN_LIST"]),
+			zap.String("create index sql", compSQL),
+			zap.String("warn", "postgres not support"))
+		return "", compSQL, nil
+
+	case "DOMAIN":
+		compSQL := fmt.Sprintf("CREATE INDEX %s ON %s.%s (%s) INDEXTYPE IS %s.%s PARAMETERS ('%s');", indexName, targetSchema, targetTable,
+			common.StringColumnListRule(idxMeta["COLUMN_LIST"], r.TableColumnNameRule),
+			strings.ToUpper(idxMeta["ITYP_OWNER"]),
+			strings.ToUpper(idxMeta["ITYP_NAME"]),
+			idxMeta["PARAMETERS"])
+		zap.L().Warn("reverse index",
+			zap.String("schema", r.SourceSchemaName),
+			zap.String("table", idxMeta["TABLE_NAME"]),
+			zap.String("index name", idxMeta["INDEX_NAME"]),
+			zap.String("index type", idxMeta["INDEX_TYPE"]),
+			zap.String("index column list", idxMeta["COLUMN_LIST"]),
+			zap.String("create index sql", compSQL),
+			zap.String("warn", "postgres not support"))
+		return "", compSQL, nil
+
+	default:
+		zap.L().Error("reverse index",
+			zap.String("schema", r.SourceSchemaName),
+			zap.String("table", idxMeta["TABLE_NAME"]),
+			zap.String("index name", idxMeta["INDEX_NAME"]),
+			zap.String("index type", idxMeta["INDEX_TYPE"]),
+			zap.String("index column list", idxMeta["COLUMN_LIST"]),
+			zap.String("error", "postgres not support"))
+		return "", "", fmt.Errorf("oracle schema [%s] table [%s] reverse index panic, error: %v", r.SourceSchemaName, r.SourceTableName, idxMeta)
+	}
+}
+
+func (r *Rule) GenTableComment() (tableComment string, err error) {
+	if len(r.TableCommentINFO) > 0 && r.TableCommentINFO[0]["COMMENTS"] != "" {
+		targetSchema, targetTable := r.GenTablePrefix()
+		tableComment = fmt.Sprintf("COMMENT ON TABLE %s.%s IS %s;", targetSchema, targetTable, genLiteral(r.TableCommentINFO[0]["COMMENTS"]))
+	}
+	return tableComment, err
+}
+
+func (r *Rule) GenTableColumn() (tableColumns []string, err error) {
+	for _, rowCol := range r.TableColumnINFO {
+		var (
+			columnType  string
+			dataDefault string
+			generated   string
+		)
+		columnName := r.GenColumnName(rowCol["COLUMN_NAME"])
+
+		if val, ok := r.TableColumnDatatypeRule[rowCol["COLUMN_NAME"]]; ok {
+			columnType = val
+		} else {
+			return tableColumns, fmt.Errorf("oracle table [%s.%s] column [%s] data type isn't exist", r.SourceSchemaName, r.SourceTableName, rowCol["COLUMN_NAME"])
+		}
+
+		if val, ok := r.TableColumnDefaultValRule[rowCol["COLUMN_NAME"]]; ok {
+			dataDefault = strings.TrimSpace(val)
+		} else {
+			return tableColumns, fmt.Errorf("oracle table [%s.%s] column [%s] default value isn't exist", r.SourceSchemaName, r.SourceTableName, rowCol["COLUMN_NAME"])
+		}
+
+		nullable := "NOT NULL"
+		if strings.EqualFold(rowCol["NULLABLE"], "Y") {
+			nullable = ""
+		}
+
+		// identity column 默认值为系统序列 nextval，postgres 不兼容，按策略转换 identity 属性
+		if identity, isIdentity := r.GenColumnIdentity(rowCol["COLUMN_NAME"], columnType); isIdentity {
+			dataDefault = ""
+			if identity != "" {
+				if !common.IsContainString(identityDatatype, common.StringUPPER(columnType)) {
+					zap.L().Warn("reverse oracle identity column",
+						zap.String("schema", r.SourceSchemaName),
+						zap.String("table", r.SourceTableName),
+						zap.String("column", rowCol["COLUMN_NAME"]),
+						zap.String("column type", columnType),
+						zap.String("suggest", "postgres identity column require integer data type, convert to bigint"))
+					columnType = "BIGINT"
+				}
+				generated = identity
+			}
+		}
+
+		// 虚拟列转换 STORED generated column，表达式无法改写或版本不支持按普通可空字段创建并输出不兼容项
+		if strings.EqualFold(rowCol["VIRTUAL_COLUMN"], "YES") {
+			dataDefault = ""
+			if expr, reason := r.genVirtualColumn(rowCol); reason == "" {
+				generated = fmt.Sprintf("GENERATED ALWAYS AS (%s) STORED", expr)
+			} else {
+				nullable = ""
+				zap.L().Warn("reverse oracle virtual column",
+					zap.String("schema", r.SourceSchemaName),
+					zap.String("table", r.SourceTableName),
+					zap.String("column", rowCol["COLUMN_NAME"]),
+					zap.String("reason", reason),
+					zap.String("suggest", "convert to normal column, please manual process"))
+			}
+		}
+
+		column := []string{columnName, columnType}
+		if generated != "" {
+			column = append(column, generated)
+		}
+		if dataDefault != "" {
+			column = append(column, "DEFAULT", dataDefault)
+		}
+		if nullable != "" {
+			column = append(column, nullable)
+		}
+		tableColumns = append(tableColumns, strings.Join(column, " "))
+
+		// 不可见列 postgres 不支持，转换可见列
+		if strings.EqualFold(rowCol["HIDDEN_COLUMN"], "YES") {
+			zap.L().Warn("reverse oracle invisible column",
+				zap.String("schema", r.SourceSchemaName),
+				zap.String("table", r.SourceTableName),
+				zap.String("column", rowCol["COLUMN_NAME"]),
+				zap.String("suggest", "postgres isn't support invisible column, convert to visible column"))
+		}
+	}
+
+	return tableColumns, nil
+}
+
+// GenColumnIdentity 返回 identity 属性以及是否 identity column
+// identity-policy 为 NONE 或者 postgres 10 以下版本返回空，按普通字段创建
+func (r *Rule) GenColumnIdentity(columnName, columnType string) (string, bool) {
+	for _, col := range r.IdentityINFO {
+		if !strings.EqualFold(col["COLUMN_NAME"], columnName) {
+			continue
+		}
+		if strings.EqualFold(r.IdentityPolicy, common.ReverseIdentityPolicyNone) {
+			return "", true
+		}
+		if common.VersionOrdinal(r.TargetDBVersion) < common.VersionOrdinal(common.PostgresIdentityColumnVersion) {
+			zap.L().Warn("reverse oracle identity column",
+				zap.String("schema", r.SourceSchemaName),
+				zap.String("table", r.SourceTableName),
+				zap.String("column", columnName),
+				zap.String("target db version", r.TargetDBVersion),
+				zap.String("suggest", "postgres version isn't support identity column, convert to normal column, please manual process"))
+			return "", true
+		}
+		if strings.EqualFold(col["GENERATION_TYPE"], "ALWAYS") {
+			return "GENERATED ALWAYS AS IDENTITY", true
+		}
+		return "GENERATED BY DEFAULT AS IDENTITY", true
+	}
+	return "", false
+}
+
+// genVirtualColumn 虚拟列表达式改写，postgres 12 及以上支持 STORED generated column
+func (r *Rule) genVirtualColumn(rowCol map[string]string) (string, string) {
+	if common.VersionOrdinal(r.TargetDBVersion) < common.VersionOrdinal(common.PostgresGeneratedColumnVersion) {
+		return "", fmt.Sprintf("postgres version [%s] isn't support generated column", r.TargetDBVersion)
+	}
+	return r.genExpr(strings.TrimSpace(rowCol["DATA_DEFAULT"]))
+}
+
+// GenTableColumnCompatible 虚拟列表达式无法改写以及不可见列不支持的不兼容项
+func (r *Rule) GenTableColumnCompatible() []string {
+	var compatibilityDDL []string
+	targetSchema, targetTable := r.GenTablePrefix()
+	for _, rowCol := range r.TableColumnINFO {
+		if strings.EqualFold(rowCol["VIRTUAL_COLUMN"], "YES") {
+			if _, reason := r.genVirtualColumn(rowCol); reason != "" {
+				compatibilityDDL = append(compatibilityDDL,
+					fmt.Sprintf("ALTER TABLE %s.%s ADD COLUMN %s %s GENERATED ALWAYS AS (%s) STORED; -- %s",
+						targetSchema, targetTable, r.GenColumnName(rowCol["COLUMN_NAME"]), r.TableColumnDatatypeRule[rowCol["COLUMN_NAME"]],
+						strings.TrimSpace(rowCol["DATA_DEFAULT"]), reason))
+			}
+		}
+		if strings.EqualFold(rowCol["HIDDEN_COLUMN"], "YES") {
+			compatibilityDDL = append(compatibilityDDL,
+				fmt.Sprintf("-- %s.%s column %s invisible, postgres isn't support invisible column, convert to visible column",
+					targetSchema, targetTable, r.GenColumnName(rowCol["COLUMN_NAME"])))
+		}
+	}
+	return compatibilityDDL
+}
+
+func (r *Rule) GenTableColumnComment() (columnComments []string, err error) {
+	targetSchema, targetTable := r.GenTablePrefix()
+	for _, rowCol := range r.ColumnCommentINFO {
+		if rowCol["COMMENTS"] == "" {
+			continue
+		}
+		columnComments = append(columnComments, fmt.Sprintf("COMMENT ON COLUMN %s.%s.%s IS %s;",
+			targetSchema, targetTable, r.GenColumnName(rowCol["COLUMN_NAME"]), genLiteral(rowCol["COMMENTS"])))
+	}
+	return columnComments, nil
+}
+
+func (r *Rule) GenSchemaName() string {
+	if r.TargetSchemaName == "" {
+		return r.SourceSchemaName
+	}
+	return r.TargetSchemaName
+}
+
+func (r *Rule) GenTableName() string {
+	if r.TargetTableName == "" {
+		return r.SourceTableName
+	}
+	return r.TargetTableName
+}
+
+// GenColumnName 字段名映射规则，不存在规则使用源端字段名，返回引用后的字段名
+func (r *Rule) GenColumnName(columnName string) string {
+	return genIdentifier(common.StringColumnNameRule(columnName, r.TableColumnNameRule), r.IdentifierPolicy)
+}
+
+// GenIdentifierName 索引、约束等标识符名称，返回引用后的标识符
+func (r *Rule) GenIdentifierName(name string) string {
+	return genIdentifier(name, r.IdentifierPolicy)
+}
+
+// genColumnList 逗号分隔字段列表转换引用后的字段列表
+func (r *Rule) genColumnList(columnList string) string {
+	var columns []string
+	for _, col := range strings.Split(columnList, ",") {
+		columns = append(columns, r.GenColumnName(strings.TrimSpace(col)))
+	}
+	return strings.Join(columns, ",")
+}
+
+func (r *Rule) String() string {
+	jsonStr, _ := json.Marshal(r)
+	return string(jsonStr)
+}
+
+// genIdentifier postgres 未引用标识符折叠小写，目标端标识符统一小写并以双引号引用
+// 超长标识符 postgres 静默截断，按 identifier-policy 截断避免截断后冲突
+func genIdentifier(name, identifierPolicy string) string {
+	ident := strings.ToLower(name)
+	if strings.EqualFold(identifierPolicy, common.ReverseIdentifierPolicyShorten) {
+		ident = common.StringShortenIdentifier(ident, common.PostgresIdentifierMaxLength)
+	}
+	return fmt.Sprintf(`"%s"`, strings.ReplaceAll(ident, `"`, `""`))
+}
+
+// genLiteral 字符串常量，单引号转义
+func genLiteral(s string) string {
+	return fmt.Sprintf("'%s'", strings.ReplaceAll(s, "'", "''"))
+}
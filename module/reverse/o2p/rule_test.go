@@ -0,0 +1,244 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package o2p
+
+import (
+	"github.com/wentaojin/transferdb/common"
+	reverseO2M "github.com/wentaojin/transferdb/module/reverse/o2m"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGenIdentifier(t *testing.T) {
+	longName := strings.Repeat("A", 70)
+	shortened := common.StringShortenIdentifier(strings.ToLower(longName), common.PostgresIdentifierMaxLength)
+	cases := []struct {
+		name   string
+		ident  string
+		policy string
+		want   string
+	}{
+		{name: "lower", ident: "T_ORDER", policy: common.ReverseIdentifierPolicyNone, want: `"t_order"`},
+		{name: "reserved word", ident: "USER", policy: common.ReverseIdentifierPolicyNone, want: `"user"`},
+		{name: "double quote", ident: `A"B`, policy: common.ReverseIdentifierPolicyNone, want: `"a""b"`},
+		{name: "long none", ident: longName, policy: common.ReverseIdentifierPolicyNone, want: `"` + strings.ToLower(longName) + `"`},
+		{name: "long shorten", ident: longName, policy: common.ReverseIdentifierPolicyShorten, want: `"` + shortened + `"`},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := genIdentifier(c.ident, c.policy); got != c.want {
+				t.Fatalf("identifier [%s] policy [%s] got %s, want %s", c.ident, c.policy, got, c.want)
+			}
+		})
+	}
+	if len([]rune(shortened)) != common.PostgresIdentifierMaxLength {
+		t.Fatalf("shorten identifier length got %d, want %d", len([]rune(shortened)), common.PostgresIdentifierMaxLength)
+	}
+}
+
+func TestGenExpr(t *testing.T) {
+	r := &Rule{
+		Table: &Table{Table: &reverseO2M.Table{TableColumnNameRule: map[string]string{"NAME": "ORDER_NAME"}}},
+		Info: &reverseO2M.Info{TableColumnINFO: []map[string]string{
+			{"COLUMN_NAME": "NAME"},
+			{"COLUMN_NAME": "PRICE"},
+		}},
+	}
+	cases := []struct {
+		name       string
+		expr       string
+		want       string
+		wantReason bool
+	}{
+		{name: "column", expr: "PRICE > 0", want: `"price" > 0`},
+		{name: "quoted column rule", expr: `UPPER("NAME")`, want: `UPPER("order_name")`},
+		{name: "nvl", expr: "NVL(NAME, 'N/A') <> 'it''s'", want: `COALESCE("order_name", 'N/A') <> 'it''s'`},
+		{name: "keyword", expr: "PRICE IS NOT NULL AND PRICE BETWEEN 1 AND 10.5", want: `"price" IS NOT NULL AND "price" BETWEEN 1 AND 10.5`},
+		{name: "unsupported function", expr: "TO_CHAR(PRICE)", wantReason: true},
+		{name: "unknown identifier", expr: "AMOUNT > 0", wantReason: true},
+		{name: "unclosed literal", expr: "NAME = 'A", wantReason: true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, reason := r.genExpr(c.expr)
+			if c.wantReason {
+				if reason == "" {
+					t.Fatalf("expr [%s] expect incompatible reason, got %s", c.expr, got)
+				}
+				return
+			}
+			if reason != "" || got != c.want {
+				t.Fatalf("expr [%s] got (%s, %s), want %s", c.expr, got, reason, c.want)
+			}
+		})
+	}
+}
+
+func genTestRule(targetSchema, targetDBVersion string) *Rule {
+	return &Rule{
+		Table: &Table{
+			Table: &reverseO2M.Table{
+				SourceSchemaName: "MARVIN",
+				TargetSchemaName: targetSchema,
+				SourceTableName:  "T_ORDER",
+				TargetDBVersion:  targetDBVersion,
+				IdentifierPolicy: common.ReverseIdentifierPolicyNone,
+				TableColumnDatatypeRule: map[string]string{
+					"ID": "BIGINT", "NAME": "VARCHAR(30)", "PRICE": "NUMERIC(10,2)", "TOTAL": "NUMERIC(10,2)",
+				},
+				TableColumnDefaultValRule: map[string]string{
+					"ID": "", "NAME": "'N/A'", "PRICE": "", "TOTAL": "",
+				},
+				TableColumnNameRule: map[string]string{"NAME": "ORDER_NAME"},
+			},
+		},
+		Info: &reverseO2M.Info{
+			PrimaryKeyINFO: []map[string]string{{"CONSTRAINT_NAME": "PK_T_ORDER", "COLUMN_LIST": "ID"}},
+			UniqueKeyINFO:  []map[string]string{{"CONSTRAINT_NAME": "UK_T_ORDER_NAME", "COLUMN_LIST": "NAME,PRICE"}},
+			CheckKeyINFO: []map[string]string{
+				{"CONSTRAINT_NAME": "CK_PRICE", "SEARCH_CONDITION": "PRICE > 0"},
+				{"CONSTRAINT_NAME": "CK_NAME_NN", "SEARCH_CONDITION": `"NAME" IS NOT NULL`},
+			},
+			NormalIndexINFO: []map[string]string{
+				{"TABLE_NAME": "T_ORDER", "UNIQUENESS": "NONUNIQUE", "INDEX_TYPE": "NORMAL", "INDEX_NAME": "IDX_PRICE", "COLUMN_LIST": "PRICE"},
+				{"TABLE_NAME": "T_ORDER", "UNIQUENESS": "NONUNIQUE", "INDEX_TYPE": "FUNCTION-BASED NORMAL", "INDEX_NAME": "IDX_UPPER_NAME", "COLUMN_LIST": `UPPER("NAME")`},
+				{"TABLE_NAME": "T_ORDER", "UNIQUENESS": "NONUNIQUE", "INDEX_TYPE": "FUNCTION-BASED NORMAL", "INDEX_NAME": "IDX_PRICE_DESC", "COLUMN_LIST": `"PRICE"`, "DESCEND_LIST": "DESC"},
+			},
+			TableCommentINFO: []map[string]string{{"COMMENTS": "order's table"}},
+			TableColumnINFO: []map[string]string{
+				{"COLUMN_NAME": "ID", "NULLABLE": "N"},
+				{"COLUMN_NAME": "NAME", "NULLABLE": "Y"},
+				{"COLUMN_NAME": "PRICE", "NULLABLE": "Y"},
+				{"COLUMN_NAME": "TOTAL", "NULLABLE": "Y", "VIRTUAL_COLUMN": "YES", "DATA_DEFAULT": "PRICE*2"},
+			},
+			ColumnCommentINFO: []map[string]string{{"COLUMN_NAME": "NAME", "COMMENTS": "name"}},
+			IdentityINFO:      []map[string]string{{"COLUMN_NAME": "ID", "GENERATION_TYPE": "ALWAYS"}},
+		},
+	}
+}
+
+func TestGenCreateTableDDL(t *testing.T) {
+	cases := []struct {
+		name            string
+		targetDBVersion string
+		wantColumns     []string
+		wantCompatible  []string
+	}{
+		{
+			name:            "generated column",
+			targetDBVersion: "14",
+			wantColumns: []string{
+				`"id" BIGINT GENERATED ALWAYS AS IDENTITY NOT NULL`,
+				`"order_name" VARCHAR(30) DEFAULT 'N/A'`,
+				`"price" NUMERIC(10,2)`,
+				`"total" NUMERIC(10,2) GENERATED ALWAYS AS ("price"*2) STORED`,
+			},
+		},
+		{
+			name:            "generated column unsupported version",
+			targetDBVersion: "11",
+			wantColumns: []string{
+				`"id" BIGINT GENERATED ALWAYS AS IDENTITY NOT NULL`,
+				`"order_name" VARCHAR(30) DEFAULT 'N/A'`,
+				`"price" NUMERIC(10,2)`,
+				`"total" NUMERIC(10,2)`,
+			},
+			wantCompatible: []string{
+				`ALTER TABLE "marvin"."t_order" ADD COLUMN "total" NUMERIC(10,2) GENERATED ALWAYS AS (PRICE*2) STORED; -- postgres version [11] isn't support generated column`,
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ddl, err := genTestRule("", c.targetDBVersion).GenCreateTableDDL()
+			if err != nil {
+				t.Fatalf("gen create table ddl failed: %v", err)
+			}
+			d := ddl.(*DDL)
+			if !reflect.DeepEqual(d.TableColumns, c.wantColumns) {
+				t.Fatalf("table columns got %q, want %q", d.TableColumns, c.wantColumns)
+			}
+			if !reflect.DeepEqual(d.TableCompatibleDDL, c.wantCompatible) {
+				t.Fatalf("table compatible ddl got %q, want %q", d.TableCompatibleDDL, c.wantCompatible)
+			}
+
+			wantKeys := []string{
+				`CONSTRAINT "pk_t_order" PRIMARY KEY ("id")`,
+				`CONSTRAINT "uk_t_order_name" UNIQUE ("order_name","price")`,
+			}
+			if !reflect.DeepEqual(d.TableKeys, wantKeys) {
+				t.Fatalf("table keys got %q, want %q", d.TableKeys, wantKeys)
+			}
+			wantIndexes := []string{
+				`CREATE INDEX "idx_price" ON "marvin"."t_order" ("price");`,
+				`CREATE INDEX "idx_upper_name" ON "marvin"."t_order" ((UPPER("order_name")));`,
+				`CREATE INDEX "idx_price_desc" ON "marvin"."t_order" ("price" DESC);`,
+			}
+			if !reflect.DeepEqual(d.TableIndexes, wantIndexes) {
+				t.Fatalf("table indexes got %q, want %q", d.TableIndexes, wantIndexes)
+			}
+			wantCheckKeys := []string{`ALTER TABLE "marvin"."t_order" ADD CONSTRAINT "ck_price" CHECK ("price" > 0);`}
+			if !reflect.DeepEqual(d.TableCheckKeys, wantCheckKeys) {
+				t.Fatalf("table check keys got %q, want %q", d.TableCheckKeys, wantCheckKeys)
+			}
+			if want := `COMMENT ON TABLE "marvin"."t_order" IS 'order''s table';`; d.TableComment != want {
+				t.Fatalf("table comment got %s, want %s", d.TableComment, want)
+			}
+			wantColumnComments := []string{`COMMENT ON COLUMN "marvin"."t_order"."order_name" IS 'name';`}
+			if !reflect.DeepEqual(d.ColumnComments, wantColumnComments) {
+				t.Fatalf("column comments got %q, want %q", d.ColumnComments, wantColumnComments)
+			}
+
+			wantSQL := `CREATE TABLE "marvin"."t_order" (` + "\n" +
+				strings.Join(c.wantColumns, ",\n") + ",\n" +
+				strings.Join(wantKeys, ",\n") + "\n);"
+			if got := d.GenCreateTableSQL(); got != wantSQL {
+				t.Fatalf("create table sql got %s, want %s", got, wantSQL)
+			}
+		})
+	}
+}
+
+func TestGenColumnIdentity(t *testing.T) {
+	cases := []struct {
+		name            string
+		identityPolicy  string
+		targetDBVersion string
+		generationType  string
+		want            string
+	}{
+		{name: "always", targetDBVersion: "14", generationType: "ALWAYS", want: "GENERATED ALWAYS AS IDENTITY"},
+		{name: "by default", targetDBVersion: "14", generationType: "BY DEFAULT", want: "GENERATED BY DEFAULT AS IDENTITY"},
+		{name: "policy none", identityPolicy: common.ReverseIdentityPolicyNone, targetDBVersion: "14", generationType: "ALWAYS", want: ""},
+		{name: "unsupported version", targetDBVersion: "9.6", generationType: "ALWAYS", want: ""},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r := &Rule{
+				Table: &Table{Table: &reverseO2M.Table{IdentityPolicy: c.identityPolicy, TargetDBVersion: c.targetDBVersion}},
+				Info:  &reverseO2M.Info{IdentityINFO: []map[string]string{{"COLUMN_NAME": "ID", "GENERATION_TYPE": c.generationType}}},
+			}
+			got, isIdentity := r.GenColumnIdentity("ID", "BIGINT")
+			if !isIdentity || got != c.want {
+				t.Fatalf("column identity got (%s, %v), want (%s, true)", got, isIdentity, c.want)
+			}
+			if _, isIdentity = r.GenColumnIdentity("NAME", "VARCHAR(30)"); isIdentity {
+				t.Fatalf("column [NAME] isn't identity column")
+			}
+		})
+	}
+}
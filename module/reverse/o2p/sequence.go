@@ -0,0 +1,152 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package o2p
+
+import (
+	"fmt"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/database/oracle"
+	"github.com/wentaojin/transferdb/module/reverse"
+	"go.uber.org/zap"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// GenCreateSequence 生成 postgres CREATE SEQUENCE
+// 序列起始值取 oracle LAST_NUMBER，超出 bigint 取值范围按边界值处理，序列名称按超长标识符处理策略转换
+func GenCreateSequence(w *reverse.Write, oracle *oracle.Oracle, sourceSchema, targetSchema, identifierPolicy string, directWrite bool) error {
+	startTime := time.Now()
+	sequences, err := oracle.GetOracleSchemaSequence(sourceSchema)
+	if err != nil {
+		return err
+	}
+	if len(sequences) == 0 {
+		return nil
+	}
+
+	var sqlRev strings.Builder
+	sqlRev.WriteString("/*\n")
+	sqlRev.WriteString(" oracle sequence reverse postgres sequence\n")
+	t := table.NewWriter()
+	t.SetStyle(table.StyleLight)
+	t.AppendHeader(table.Row{"#", "ORACLE", "POSTGRES", "SUGGEST"})
+	for _, seq := range sequences {
+		t.AppendRows([]table.Row{
+			{"SEQUENCE", fmt.Sprintf("%s.%s", sourceSchema, seq["SEQUENCE_NAME"]), fmt.Sprintf("%s.%s", targetSchema, seq["SEQUENCE_NAME"]), "Create Sequence"},
+		})
+	}
+	sqlRev.WriteString(t.Render() + "\n")
+	sqlRev.WriteString("*/\n")
+	for _, seq := range sequences {
+		seqSQL, err := genPostgresSequenceSQL(genIdentifier(targetSchema, identifierPolicy), genIdentifier(seq["SEQUENCE_NAME"], identifierPolicy), seq)
+		if err != nil {
+			return err
+		}
+		sqlRev.WriteString(seqSQL + "\n")
+	}
+	sqlRev.WriteString("\n")
+
+	if directWrite {
+		if err = w.RWriteDB(sqlRev.String()); err != nil {
+			return err
+		}
+	} else {
+		if _, err = w.RWriteFile(sqlRev.String()); err != nil {
+			return err
+		}
+	}
+	zap.L().Info("output oracle to postgres sequence create sql",
+		zap.String("schema", sourceSchema),
+		zap.Int("sequence counts", len(sequences)),
+		zap.String("cost", time.Now().Sub(startTime).String()))
+	return nil
+}
+
+func genPostgresSequenceSQL(targetSchema, sequenceName string, seq map[string]string) (string, error) {
+	pgMax, _ := new(big.Int).SetString(common.PostgresSequenceMaxValue, 10)
+	pgMin, _ := new(big.Int).SetString(common.PostgresSequenceMinValue, 10)
+
+	var values []*big.Int
+	for _, col := range []string{"MIN_VALUE", "MAX_VALUE", "INCREMENT_BY", "CACHE_SIZE", "LAST_NUMBER"} {
+		v, ok := new(big.Int).SetString(seq[col], 10)
+		if !ok {
+			return "", fmt.Errorf("oracle sequence [%s] column [%s] value [%s] isn't integer", seq["SEQUENCE_NAME"], col, seq[col])
+		}
+		if v.Cmp(pgMax) > 0 {
+			v = pgMax
+		}
+		if v.Cmp(pgMin) < 0 {
+			v = pgMin
+		}
+		values = append(values, v)
+	}
+	minValue, maxValue, incrementBy, cacheSize, lastNumber := values[0], values[1], values[2], values[3], values[4]
+
+	// postgres CACHE 最小值为 1，oracle NOCACHE 对应 CACHE 1
+	if cacheSize.Sign() <= 0 {
+		cacheSize = big.NewInt(1)
+	}
+	cycle := "NO CYCLE"
+	if strings.EqualFold(seq["CYCLE_FLAG"], "Y") {
+		cycle = "CYCLE"
+	}
+	return fmt.Sprintf("CREATE SEQUENCE IF NOT EXISTS %s.%s INCREMENT BY %s MINVALUE %s MAXVALUE %s START WITH %s CACHE %s %s;",
+		targetSchema, sequenceName, incrementBy.String(), minValue.String(), maxValue.String(), lastNumber.String(), cacheSize.String(), cycle), nil
+}
+
+// GenCreateView oracle 视图定义 postgres 不做改写，统一输出不兼容项，需人工处理
+func GenCreateView(w *reverse.Write, oracle *oracle.Oracle, sourceSchema, targetSchema string) error {
+	startTime := time.Now()
+	views, err := oracle.GetOracleSchemaView(sourceSchema)
+	if err != nil {
+		return err
+	}
+	if len(views) == 0 {
+		return nil
+	}
+
+	var sqlComp strings.Builder
+	sqlComp.WriteString("/*\n")
+	sqlComp.WriteString(" oracle view maybe postgres has compatibility, will skip convert to reverse, please manual process\n")
+	t := table.NewWriter()
+	t.SetStyle(table.StyleLight)
+	t.AppendHeader(table.Row{"#", "ORACLE", "POSTGRES", "SUGGEST"})
+	for _, v := range views {
+		t.AppendRows([]table.Row{
+			{"VIEW", fmt.Sprintf("%s.%s", sourceSchema, v["VIEW_NAME"]), fmt.Sprintf("%s.%s", targetSchema, v["VIEW_NAME"]), "Manual Process View"},
+		})
+	}
+	sqlComp.WriteString(t.Render() + "\n")
+	sqlComp.WriteString("*/\n")
+	for _, v := range views {
+		sqlComp.WriteString(fmt.Sprintf("-- CREATE VIEW %s.%s AS %s;\n", targetSchema, v["VIEW_NAME"], strings.ReplaceAll(strings.TrimSpace(v["TEXT"]), "\n", "\n-- ")))
+	}
+	sqlComp.WriteString("\n")
+	if _, err = w.CWriteFile(sqlComp.String()); err != nil {
+		return err
+	}
+
+	zap.L().Warn("reverse oracle view",
+		zap.String("schema", sourceSchema),
+		zap.Int("view counts", len(views)),
+		zap.String("suggest", "postgres view definition isn't convert, detail see compatibility output"))
+	zap.L().Info("output oracle to postgres view compatibility",
+		zap.String("schema", sourceSchema),
+		zap.String("cost", time.Now().Sub(startTime).String()))
+	return nil
+}
@@ -0,0 +1,61 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package o2p
+
+import (
+	"testing"
+)
+
+func TestGenPostgresSequenceSQL(t *testing.T) {
+	cases := []struct {
+		name    string
+		seq     map[string]string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "nocache max value overflow",
+			seq: map[string]string{"SEQUENCE_NAME": "S1", "MIN_VALUE": "1", "MAX_VALUE": "9999999999999999999999999999",
+				"INCREMENT_BY": "1", "CACHE_SIZE": "0", "LAST_NUMBER": "21", "CYCLE_FLAG": "N"},
+			want: `CREATE SEQUENCE IF NOT EXISTS "marvin"."s1" INCREMENT BY 1 MINVALUE 1 MAXVALUE 9223372036854775807 START WITH 21 CACHE 1 NO CYCLE;`,
+		},
+		{
+			name: "descending cycle",
+			seq: map[string]string{"SEQUENCE_NAME": "S2", "MIN_VALUE": "-99999999999999999999999999", "MAX_VALUE": "-1",
+				"INCREMENT_BY": "-1", "CACHE_SIZE": "20", "LAST_NUMBER": "-1", "CYCLE_FLAG": "Y"},
+			want: `CREATE SEQUENCE IF NOT EXISTS "marvin"."s2" INCREMENT BY -1 MINVALUE -9223372036854775808 MAXVALUE -1 START WITH -1 CACHE 20 CYCLE;`,
+		},
+		{
+			name:    "invalid value",
+			seq:     map[string]string{"SEQUENCE_NAME": "S3", "MIN_VALUE": "1", "MAX_VALUE": "", "INCREMENT_BY": "1", "CACHE_SIZE": "20", "LAST_NUMBER": "1"},
+			wantErr: true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := genPostgresSequenceSQL(genIdentifier("MARVIN", ""), genIdentifier(c.seq["SEQUENCE_NAME"], ""), c.seq)
+			if c.wantErr {
+				if err == nil {
+					t.Fatalf("sequence [%s] expect error, got nil", c.seq["SEQUENCE_NAME"])
+				}
+				return
+			}
+			if err != nil || got != c.want {
+				t.Fatalf("sequence [%s] got (%s, %v), want %s", c.seq["SEQUENCE_NAME"], got, err, c.want)
+			}
+		})
+	}
+}
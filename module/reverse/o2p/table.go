@@ -0,0 +1,192 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package o2p
+
+import (
+	"fmt"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/module/reverse"
+	reverseO2M "github.com/wentaojin/transferdb/module/reverse/o2m"
+	"go.uber.org/zap"
+	"strings"
+	"time"
+)
+
+// Table 表元信息获取同 O2M，postgres 不涉及字段排序规则，分区表按普通表创建
+type Table struct {
+	*reverseO2M.Table
+}
+
+func GenReverseTableTask(r *Reverse, tableNameRule map[string]string, tableColumnRule, tableDefaultRule, tableColumnNameRule map[string]map[string]string, oracleDBVersion, pgDBVersion string, exporters []string) ([]*Table, error) {
+	var tables []*Table
+
+	startTime := time.Now()
+	characterSet, err := r.Oracle.GetOracleDBCharacterSet()
+	if err != nil {
+		return tables, err
+	}
+	if _, ok := common.OracleDBCharacterSetMap[strings.Split(characterSet, ".")[1]]; !ok {
+		return tables, fmt.Errorf("oracle db character set [%v] isn't support", characterSet)
+	}
+
+	tablesMap, err := r.Oracle.GetOracleSchemaTableType(common.StringUPPER(r.Cfg.OracleConfig.SchemaName))
+	if err != nil {
+		return tables, err
+	}
+
+	// oracle 12c 及以上存在 identity column
+	oracleIdentity := false
+	if common.VersionOrdinal(oracleDBVersion) >= common.VersionOrdinal(common.OracleIdentityColumnDBVersion) {
+		oracleIdentity = true
+	}
+
+	for _, exporter := range exporters {
+		// 库名、表名规则
+		targetTableName := common.StringUPPER(exporter)
+		if val, ok := tableNameRule[common.StringUPPER(exporter)]; ok {
+			targetTableName = val
+		}
+
+		tables = append(tables, &Table{
+			Table: &reverseO2M.Table{
+				Ctx:                       r.Ctx,
+				SourceSchemaName:          common.StringUPPER(r.Cfg.OracleConfig.SchemaName),
+				TargetSchemaName:          r.Cfg.PostgresConfig.SchemaName,
+				SourceTableName:           common.StringUPPER(exporter),
+				TargetDBType:              common.DatabaseTypePostgres,
+				TargetDBVersion:           pgDBVersion,
+				TargetTableName:           targetTableName,
+				SourceTableType:           tablesMap[exporter],
+				OracleIdentity:            oracleIdentity,
+				IdentityPolicy:            r.Cfg.ReverseConfig.IdentityPolicy,
+				IdentifierPolicy:          r.Cfg.ReverseConfig.IdentifierPolicy,
+				ForeignKeyPostLoad:        r.Cfg.ReverseConfig.ForeignKeyPostLoad,
+				TableColumnDatatypeRule:   tableColumnRule[common.StringUPPER(exporter)],
+				TableColumnDefaultValRule: tableDefaultRule[common.StringUPPER(exporter)],
+				TableColumnNameRule:       tableColumnNameRule[common.StringUPPER(exporter)],
+				SchemaColumnNameRule:      tableColumnNameRule,
+				SchemaTableNameRule:       tableNameRule,
+				Oracle:                    r.Oracle,
+				MetaDB:                    r.MetaDB,
+			},
+		})
+	}
+
+	zap.L().Info("gen oracle slice table finished",
+		zap.String("schema", r.Cfg.OracleConfig.SchemaName),
+		zap.String("db version", oracleDBVersion),
+		zap.String("db character", characterSet),
+		zap.String("postgres version", pgDBVersion),
+		zap.Int("table totals", len(exporters)),
+		zap.Int("table gens", len(tables)),
+		zap.String("cost", time.Now().Sub(startTime).String()))
+
+	return tables, nil
+}
+
+func GenCreateSchema(w *reverse.Write, sourceSchema, targetSchema, identifierPolicy string, directWrite bool) error {
+	startTime := time.Now()
+	var sqlRev strings.Builder
+
+	sqlRev.WriteString("/*\n")
+	sqlRev.WriteString(" oracle schema reverse postgres schema\n")
+	t := table.NewWriter()
+	t.SetStyle(table.StyleLight)
+	t.AppendHeader(table.Row{"#", "ORACLE", "POSTGRES", "SUGGEST"})
+	t.AppendRows([]table.Row{
+		{"Schema", sourceSchema, targetSchema, "Create Schema"},
+	})
+	sqlRev.WriteString(t.Render() + "\n")
+	sqlRev.WriteString("*/\n")
+	sqlRev.WriteString(fmt.Sprintf("CREATE SCHEMA IF NOT EXISTS %s;\n\n", genIdentifier(targetSchema, identifierPolicy)))
+
+	if directWrite {
+		if err := w.RWriteDB(sqlRev.String()); err != nil {
+			return err
+		}
+	} else {
+		if _, err := w.RWriteFile(sqlRev.String()); err != nil {
+			return err
+		}
+	}
+	zap.L().Info("output oracle to postgres schema create sql",
+		zap.String("schema", sourceSchema),
+		zap.String("cost", time.Now().Sub(startTime).String()))
+
+	return nil
+}
+
+func GenCompatibilityTable(f *reverse.Write, sourceSchema string, partitionTables, temporaryTables, clusteredTables, materializedViews []string) error {
+	startTime := time.Now()
+	// 兼容提示
+	if len(partitionTables) > 0 || len(temporaryTables) > 0 || len(clusteredTables) > 0 {
+		var sqlComp strings.Builder
+
+		sqlComp.WriteString("/*\n")
+		sqlComp.WriteString(" oracle table maybe postgres has compatibility, will convert to normal table, please manual process\n")
+		t := table.NewWriter()
+		t.SetStyle(table.StyleLight)
+		t.AppendHeader(table.Row{"SCHEMA", "TABLE NAME", "ORACLE TABLE TYPE", "SUGGEST"})
+		for _, part := range partitionTables {
+			t.AppendRows([]table.Row{
+				{sourceSchema, part, "Partition", "Manual Process Table"},
+			})
+		}
+		for _, temp := range temporaryTables {
+			t.AppendRows([]table.Row{
+				{sourceSchema, temp, "Temporary", "Manual Process Table"},
+			})
+		}
+		for _, cd := range clusteredTables {
+			t.AppendRows([]table.Row{
+				{sourceSchema, cd, "Clustered", "Manual Process Table"},
+			})
+		}
+		sqlComp.WriteString(t.Render() + "\n")
+		sqlComp.WriteString("*/\n")
+
+		if _, err := f.CWriteFile(sqlComp.String()); err != nil {
+			return err
+		}
+	}
+
+	if len(materializedViews) > 0 {
+		var mviewComp strings.Builder
+
+		mviewComp.WriteString("/*\n")
+		mviewComp.WriteString(" oracle materialized view maybe postgres has compatibility, will skip convert to reverse, please manual process\n")
+		t := table.NewWriter()
+		t.SetStyle(table.StyleLight)
+		t.AppendHeader(table.Row{"SCHEMA", "MVIEW NAME", "ORACLE TABLE TYPE", "SUGGEST"})
+		for _, cd := range materializedViews {
+			t.AppendRows([]table.Row{
+				{sourceSchema, cd, "Materialized View", "Manual Process Table"},
+			})
+		}
+		mviewComp.WriteString(t.Render() + "\n")
+		mviewComp.WriteString("*/\n")
+
+		if _, err := f.CWriteFile(mviewComp.String()); err != nil {
+			return err
+		}
+	}
+	zap.L().Info("output oracle to postgres compatibility tips",
+		zap.String("schema", sourceSchema),
+		zap.String("cost", time.Now().Sub(startTime).String()))
+
+	return nil
+}
@@ -22,6 +22,7 @@ import (
 	"github.com/wentaojin/transferdb/config"
 	"github.com/wentaojin/transferdb/database/mysql"
 	"github.com/wentaojin/transferdb/database/oracle"
+	"github.com/wentaojin/transferdb/database/postgres"
 	"os"
 	"path/filepath"
 	"strings"
@@ -32,15 +33,18 @@ type Write struct {
 	Cfg     *config.Config
 	RFile   *os.File
 	CFile   *os.File
+	FFile   *os.File
 	RWriter *bufio.Writer
 	CWriter *bufio.Writer
+	FWriter *bufio.Writer
 	Mutex   *sync.Mutex
 
-	MySQL  *mysql.MySQL
-	Oracle *oracle.Oracle
+	MySQL    *mysql.MySQL
+	Oracle   *oracle.Oracle
+	Postgres *postgres.Postgres
 }
 
-func NewWriter(cfg *config.Config, mysql *mysql.MySQL, oracle *oracle.Oracle) (*Write, error) {
+func NewWriter(cfg *config.Config, mysql *mysql.MySQL, oracle *oracle.Oracle, postgres *postgres.Postgres) (*Write, error) {
 	w := &Write{}
 
 	if !cfg.ReverseConfig.DirectWrite {
@@ -69,6 +73,7 @@ func NewWriter(cfg *config.Config, mysql *mysql.MySQL, oracle *oracle.Oracle) (*
 	w.Cfg = cfg
 	w.MySQL = mysql
 	w.Oracle = oracle
+	w.Postgres = postgres
 	return w, nil
 }
 
@@ -90,6 +95,11 @@ func (w *Write) RWriteDB(s string) error {
 		if err != nil {
 			return err
 		}
+	case strings.EqualFold(w.Cfg.DBTypeS, common.DatabaseTypeOracle) && strings.EqualFold(w.Cfg.DBTypeT, common.DatabaseTypePostgres):
+		err := w.Postgres.WritePostgresTable(s)
+		if err != nil {
+			return err
+		}
 	}
 	return nil
 }
@@ -100,6 +110,25 @@ func (w *Write) CWriteFile(s string) (nn int, err error) {
 	return w.CWriter.WriteString(s)
 }
 
+// FWriteFile 外键延后执行脚本，首次写入时创建，忽略 direct-write 参数统一以文件形式输出
+func (w *Write) FWriteFile(s string) (nn int, err error) {
+	w.Mutex.Lock()
+	defer w.Mutex.Unlock()
+	if w.FWriter == nil {
+		err = common.PathExist(w.Cfg.ReverseConfig.DDLReverseDir)
+		if err != nil {
+			return 0, err
+		}
+		fkFile := filepath.Join(w.Cfg.ReverseConfig.DDLReverseDir, fmt.Sprintf("foreign_key_%s.sql", w.Cfg.OracleConfig.SchemaName))
+		outFKFile, err := os.OpenFile(fkFile, os.O_WRONLY|os.O_CREATE|os.O_APPEND|os.O_TRUNC, 0666)
+		if err != nil {
+			return 0, err
+		}
+		w.FWriter, w.FFile = bufio.NewWriter(outFKFile), outFKFile
+	}
+	return w.FWriter.WriteString(s)
+}
+
 func (w *Write) initOutReverseFile(reverseFile string) error {
 	outReverseFile, err := os.OpenFile(reverseFile, os.O_WRONLY|os.O_CREATE|os.O_APPEND|os.O_TRUNC, 0666)
 	if err != nil {
@@ -139,5 +168,15 @@ func (w *Write) Close() error {
 			return err
 		}
 	}
+	if w.FFile != nil {
+		err := w.FWriter.Flush()
+		if err != nil {
+			return err
+		}
+		err = w.FFile.Close()
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
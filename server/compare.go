@@ -20,6 +20,7 @@ import (
 	"github.com/wentaojin/transferdb/common"
 	"github.com/wentaojin/transferdb/config"
 	"github.com/wentaojin/transferdb/module/compare"
+	"github.com/wentaojin/transferdb/module/compare/m2o"
 	"github.com/wentaojin/transferdb/module/compare/o2m"
 	"strings"
 )
@@ -35,6 +36,11 @@ func ICompare(ctx context.Context, cfg *config.Config) error {
 		if err != nil {
 			return err
 		}
+	case strings.EqualFold(cfg.DBTypeS, common.DatabaseTypeMySQL) && strings.EqualFold(cfg.DBTypeT, common.DatabaseTypeOracle):
+		c, err = m2o.NewCompare(ctx, cfg)
+		if err != nil {
+			return err
+		}
 	}
 	err = c.NewCompare()
 	if err != nil {
@@ -42,3 +42,22 @@ func ICSVer(ctx context.Context, cfg *config.Config) error {
 	}
 	return err
 }
+
+func ICSVImporter(ctx context.Context, cfg *config.Config) error {
+	var (
+		c   csv.CSVImporter
+		err error
+	)
+	switch {
+	case strings.EqualFold(cfg.DBTypeS, common.DatabaseTypeOracle) && strings.EqualFold(cfg.DBTypeT, common.DatabaseTypeMySQL):
+		c, err = o2m.NewCSVImporter(ctx, cfg)
+		if err != nil {
+			return err
+		}
+	}
+	err = c.Import()
+	if err != nil {
+		return err
+	}
+	return err
+}
@@ -61,3 +61,22 @@ func IMigrateIncr(ctx context.Context, cfg *config.Config) error {
 	}
 	return nil
 }
+
+func IMigrateSequence(ctx context.Context, cfg *config.Config) error {
+	var (
+		s   migrate.Sequencer
+		err error
+	)
+	switch {
+	case strings.EqualFold(cfg.DBTypeS, common.DatabaseTypeOracle) && strings.EqualFold(cfg.DBTypeT, common.DatabaseTypeMySQL):
+		s, err = o2m.NewFuller(ctx, cfg)
+		if err != nil {
+			return err
+		}
+	}
+	err = s.Sequence()
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -17,11 +17,14 @@ package server
 
 import (
 	"context"
+	"fmt"
 	"github.com/wentaojin/transferdb/common"
 	"github.com/wentaojin/transferdb/config"
+	checkO2M "github.com/wentaojin/transferdb/module/check/o2m"
 	"github.com/wentaojin/transferdb/module/reverse"
 	"github.com/wentaojin/transferdb/module/reverse/m2o"
 	"github.com/wentaojin/transferdb/module/reverse/o2m"
+	"github.com/wentaojin/transferdb/module/reverse/o2p"
 	"strings"
 )
 
@@ -32,15 +35,27 @@ func IReverse(ctx context.Context, cfg *config.Config) error {
 	)
 	switch {
 	case strings.EqualFold(cfg.DBTypeS, common.DatabaseTypeOracle) && strings.EqualFold(cfg.DBTypeT, common.DatabaseTypeMySQL):
-		r, err = o2m.NewReverse(ctx, cfg)
+		o2mReverse, err := o2m.NewReverse(ctx, cfg)
 		if err != nil {
 			return err
 		}
+		// 下游已存在表复用 check 表结构对比，只输出收敛 ALTER 语句
+		if cfg.ReverseConfig.DiffExistingTable {
+			o2mReverse.Differ = checkO2M.NewReverseDiffer(ctx, cfg.DBTypeS, cfg.DBTypeT, o2mReverse.MetaDB)
+		}
+		r = o2mReverse
 	case strings.EqualFold(cfg.DBTypeS, common.DatabaseTypeMySQL) && strings.EqualFold(cfg.DBTypeT, common.DatabaseTypeOracle):
 		r, err = m2o.NewReverse(ctx, cfg)
 		if err != nil {
 			return err
 		}
+	case strings.EqualFold(cfg.DBTypeS, common.DatabaseTypeOracle) && strings.EqualFold(cfg.DBTypeT, common.DatabaseTypePostgres):
+		r, err = o2p.NewReverse(ctx, cfg)
+		if err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("reverse source db type [%s] target db type [%s] isn't support", cfg.DBTypeS, cfg.DBTypeT)
 	}
 
 	err = r.Reverse()
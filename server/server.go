@@ -63,6 +63,12 @@ func Run(ctx context.Context, cfg *config.Config) error {
 		if err != nil {
 			return err
 		}
+	case common.TaskModeCSVImport:
+		// csv 全量数据导入 - 读取 csv 模式导出目录
+		err := ICSVImporter(ctx, cfg)
+		if err != nil {
+			return err
+		}
 	case common.TaskModeFull:
 		// 全量数据 ETL 非一致性（基于某个时间点，而是直接基于现有 SCN）抽取，离线环境提供与原库一致性
 		err := IMigrateFull(ctx, cfg)
@@ -75,6 +81,12 @@ func Run(ctx context.Context, cfg *config.Config) error {
 		if err != nil {
 			return err
 		}
+	case common.TaskModeSequence:
+		// 自增值以及序列值同步 - 全量完成或者增量切换 cutover 前
+		err := IMigrateSequence(ctx, cfg)
+		if err != nil {
+			return err
+		}
 	default:
 		return fmt.Errorf("flag [mode] can not null or value configure error")
 	}